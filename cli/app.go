@@ -37,6 +37,8 @@ const (
 	logsFlagErrors     = "errors"
 	logsFlagTail       = "tail"
 
+	jobsFlagService = "jobs-service"
+
 	runFlagData      = "data"
 	runFlagStream    = "stream"
 	runFlagComponent = "component"
@@ -135,10 +137,14 @@ const (
 	dataFlagDatabasePassword               = "password"
 	dataFlagFilterTags                     = "filter-tags"
 	dataFlagTimeout                        = "timeout"
+	dataFlagLimit                          = "limit"
+	dataFlagExportFormat                   = "format"
 	dataFlagCollectionType                 = "collection-type"
 	dataFlagPipelineName                   = "pipeline-name"
 	dataFlagIndexName                      = "index-name"
 	dataFlagIndexSpecFile                  = "index-path"
+	dataFlagKeyFile                        = "key-file"
+	dataFlagPrivateKeyFile                 = "private-key-file"
 
 	datapipelineFlagSchedule       = "schedule"
 	datapipelineFlagMQL            = "mql"
@@ -178,6 +184,18 @@ const (
 	xacroFlagCollapseFixedJnts = "collapse-fixed-joints"
 	xacroFlagInstallPackages   = "install-packages"
 	xacroFlagROSDistro         = "ros-distro"
+
+	calibrateFlagMode           = "mode"
+	calibrateFlagFrame          = "frame"
+	calibrateFlagParent         = "parent"
+	calibrateFlagBoardType      = "board-type"
+	calibrateFlagCols           = "cols"
+	calibrateFlagRows           = "rows"
+	calibrateFlagSquareSize     = "square-size"
+	calibrateFlagMarkerSize     = "marker-size"
+	calibrateFlagDictionary     = "dictionary"
+	calibrateFlagDictionaryFile = "dictionary-file"
+	calibrateFlagOutput         = "output"
 )
 
 var commonPartFlags = []cli.Flag{
@@ -1403,6 +1421,125 @@ Note: There is no progress meter while copying is in progress.
 						},
 					},
 				},
+				{
+					Name:            "local",
+					Usage:           "work with capture files on this computer, such as a capture directory copied off of a machine",
+					UsageText:       createUsageText("data local", nil, false, true),
+					HideHelpCommand: true,
+					Commands: []*cli.Command{
+						{
+							Name: "query",
+							Usage: "print the captures in a capture directory which match the filters as JSON lines, " +
+								"optionally writing binary captures to a destination directory",
+							UsageText: createUsageText("data local query", []string{generalFlagPath}, true, false),
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:      generalFlagPath,
+									Required:  true,
+									Usage:     "path to the capture directory",
+									TakesFile: true,
+								},
+								&cli.StringFlag{
+									Name:  generalFlagResourceName,
+									Usage: "resource name filter",
+								},
+								&cli.StringFlag{
+									Name:  generalFlagMethod,
+									Usage: "method filter",
+								},
+								&cli.StringFlag{
+									Name:  generalFlagStart,
+									Usage: "ISO-8601 timestamp in RFC3339 format indicating the start of the interval filter",
+								},
+								&cli.StringFlag{
+									Name:  generalFlagEnd,
+									Usage: "ISO-8601 timestamp in RFC3339 format indicating the end of the interval filter",
+								},
+								&cli.StringSliceFlag{
+									Name:  generalFlagTags,
+									Usage: "tags filter, matching captures with any of the tags",
+								},
+								&cli.UintFlag{
+									Name:  dataFlagLimit,
+									Usage: "maximum number of captures to print",
+								},
+								&cli.StringFlag{
+									Name:      generalFlagDestination,
+									Usage:     "output directory for binary captures",
+									TakesFile: true,
+								},
+								&cli.StringFlag{
+									Name:      dataFlagKeyFile,
+									Usage:     "file holding the symmetric capture key which decrypts encrypted capture files",
+									TakesFile: true,
+								},
+								&cli.StringFlag{
+									Name:      dataFlagPrivateKeyFile,
+									Usage:     "PEM file holding the RSA private capture key which decrypts encrypted capture files",
+									TakesFile: true,
+								},
+							},
+							Action: createActionCommandWithT[dataLocalQueryArgs](DataLocalQueryAction),
+						},
+						{
+							Name: "export",
+							Usage: "export the captures in a capture directory which match the filters to MCAP, " +
+								"or to CSV or Parquet files for each resource and method",
+							UsageText: createUsageText("data local export",
+								[]string{generalFlagPath, generalFlagDestination, dataFlagExportFormat}, true, false),
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:      generalFlagPath,
+									Required:  true,
+									Usage:     "path to the capture directory",
+									TakesFile: true,
+								},
+								&cli.StringFlag{
+									Name:      generalFlagDestination,
+									Required:  true,
+									Usage:     "output directory for the exported files",
+									TakesFile: true,
+								},
+								&cli.StringFlag{
+									Name:     dataFlagExportFormat,
+									Required: true,
+									Usage:    formatAcceptedValues("export format", exportFormats()...),
+								},
+								&cli.StringFlag{
+									Name:  generalFlagResourceName,
+									Usage: "resource name filter",
+								},
+								&cli.StringFlag{
+									Name:  generalFlagMethod,
+									Usage: "method filter",
+								},
+								&cli.StringFlag{
+									Name:  generalFlagStart,
+									Usage: "ISO-8601 timestamp in RFC3339 format indicating the start of the interval filter",
+								},
+								&cli.StringFlag{
+									Name:  generalFlagEnd,
+									Usage: "ISO-8601 timestamp in RFC3339 format indicating the end of the interval filter",
+								},
+								&cli.StringSliceFlag{
+									Name:  generalFlagTags,
+									Usage: "tags filter, matching captures with any of the tags",
+								},
+								&cli.StringFlag{
+									Name:      dataFlagKeyFile,
+									Usage:     "file holding the symmetric capture key which decrypts encrypted capture files",
+									TakesFile: true,
+								},
+								&cli.StringFlag{
+									Name:      dataFlagPrivateKeyFile,
+									Usage:     "PEM file holding the RSA private capture key which decrypts encrypted capture files",
+									TakesFile: true,
+								},
+							},
+							Action: createActionCommandWithT[dataLocalExportArgs](DataLocalExportAction),
+						},
+					},
+				},
 				{
 					Name:            "delete",
 					Usage:           "delete data from Viam cloud",
@@ -3078,6 +3215,19 @@ Note: There is no progress meter while copying is in progress.
 							}...),
 							Action: createActionCommandWithT[robotsPartTunnelArgs](RobotsPartTunnelAction),
 						},
+						{
+							Name:      "jobs",
+							Usage:     "print the recent runs of the jobs of a machine part",
+							UsageText: createUsageText("machines part jobs", []string{generalFlagPart}, true, false),
+							Flags: append(commonPartFlags, []cli.Flag{
+								&cli.StringFlag{
+									Name: jobsFlagService,
+									Usage: "name of a jobs generic service (model rdk:builtin:jobs) on the part, " +
+										"which reports the error, attempts and response of each run",
+								},
+							}...),
+							Action: createActionCommandWithT[machinesPartJobsArgs](MachinesPartJobsAction),
+						},
 						{
 							Name: "motion",
 							Commands: []*cli.Command{
@@ -3316,6 +3466,103 @@ Examples:
 				},
 			},
 		},
+		{
+			Name:            "calibrate",
+			Usage:           "calibrate cameras from data collected on a machine or images",
+			UsageText:       createUsageText("calibrate", nil, false, true),
+			HideHelpCommand: true,
+			Commands: []*cli.Command{
+				{
+					Name: "hand-eye",
+					Usage: "solve for the frame of a camera relative to an arm from samples recorded by a hand_eye_calibration " +
+						"service, and print the frame, the pose of the target and the residuals as JSON",
+					UsageText: createUsageText("calibrate hand-eye",
+						[]string{generalFlagPath, calibrateFlagMode, calibrateFlagFrame}, true, false),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:      generalFlagPath,
+							Required:  true,
+							Usage:     "path to a JSON file of samples, such as the output of the get_samples command",
+							TakesFile: true,
+						},
+						&cli.StringFlag{
+							Name:     calibrateFlagMode,
+							Required: true,
+							Usage:    formatAcceptedValues("where the camera is mounted", handEyeModes()...),
+						},
+						&cli.StringFlag{
+							Name:     calibrateFlagFrame,
+							Required: true,
+							Usage:    "name of the camera frame",
+						},
+						&cli.StringFlag{
+							Name: calibrateFlagParent,
+							Usage: "parent of the camera frame. the name of the arm for eye_in_hand, " +
+								"or the frame the base of the arm is at the origin of for eye_to_hand (default world)",
+						},
+					},
+					Action: createActionCommandWithT[calibrateHandEyeArgs](CalibrateHandEyeAction),
+				},
+				{
+					Name: "intrinsics",
+					Usage: "solve for the intrinsic_parameters and distortion_parameters of a camera from images of a " +
+						"calibration board taken by it, and print them as JSON",
+					UsageText: createUsageText("calibrate intrinsics",
+						[]string{generalFlagPath, calibrateFlagBoardType, calibrateFlagCols, calibrateFlagRows, calibrateFlagSquareSize},
+						true, false),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:      generalFlagPath,
+							Required:  true,
+							Usage:     "path to a directory of PNG or JPEG images of the board, seen from different angles",
+							TakesFile: true,
+						},
+						&cli.StringFlag{
+							Name:     calibrateFlagBoardType,
+							Required: true,
+							Usage:    formatAcceptedValues("pattern of the board", boardTypes()...),
+						},
+						&cli.IntFlag{
+							Name:     calibrateFlagCols,
+							Required: true,
+							Usage:    "number of squares across the board",
+						},
+						&cli.IntFlag{
+							Name:     calibrateFlagRows,
+							Required: true,
+							Usage:    "number of squares down the board",
+						},
+						&cli.FloatFlag{
+							Name:     calibrateFlagSquareSize,
+							Required: true,
+							Usage:    "length of the sides of the squares in mm",
+						},
+						&cli.FloatFlag{
+							Name:  calibrateFlagMarkerSize,
+							Usage: "length of the sides of the markers in mm, for a charuco board",
+						},
+						&cli.StringFlag{
+							Name: calibrateFlagDictionary,
+							Usage: formatAcceptedValues("dictionary of the markers of a charuco board, aruco_original "+
+								"(OpenCV's DICT_ARUCO_ORIGINAL) by default. Boards from other OpenCV dictionaries, such as "+
+								"DICT_4X4_50, give a dictionary file instead", charucoDictionaries()...),
+						},
+						&cli.StringFlag{
+							Name: calibrateFlagDictionaryFile,
+							Usage: "path to a dictionary of the markers of a charuco board written by OpenCV's " +
+								"Dictionary.writeDictionary, such as DICT_4X4_50, instead of a built in dictionary",
+							TakesFile: true,
+						},
+						&cli.StringFlag{
+							Name:      calibrateFlagOutput,
+							Usage:     "path to write the calibration to, instead of printing it",
+							TakesFile: true,
+						},
+					},
+					Action: createActionCommandWithT[calibrateIntrinsicsArgs](CalibrateIntrinsicsAction),
+				},
+			},
+		},
 		{
 			Name:            "xacro",
 			Usage:           "tools for working with xacro files",
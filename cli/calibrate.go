@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v3"
+	commonpb "go.viam.com/api/common/v1"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/vision/calibration"
+)
+
+type calibrateHandEyeArgs struct {
+	Path   string
+	Mode   string
+	Frame  string
+	Parent string
+}
+
+type handEyeOutput struct {
+	Frame     *referenceframe.LinkConfig `json:"frame"`
+	Target    *commonpb.Pose             `json:"target"`
+	Residuals *calibration.Residuals     `json:"residuals"`
+}
+
+// CalibrateHandEyeAction is the corresponding action for 'calibrate hand-eye'.
+func CalibrateHandEyeAction(ctx context.Context, cmd *cli.Command, args calibrateHandEyeArgs) error {
+	return calibrateHandEye(cmd.Root().Writer, args)
+}
+
+// calibrateHandEye solves a hand-eye calibration from a file of samples, and prints the frame of the camera, the
+// pose of the target and the residuals as JSON.
+func calibrateHandEye(w io.Writer, args calibrateHandEyeArgs) error {
+	//nolint:gosec
+	contents, err := os.ReadFile(args.Path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read samples file %s", args.Path)
+	}
+	// the file may be the response of the get_samples command of a hand-eye calibration service, or just its samples
+	var samples []calibration.Sample
+	if trimmed := bytes.TrimSpace(contents); len(trimmed) > 0 && trimmed[0] == '{' {
+		var response struct {
+			Samples []calibration.Sample `json:"samples"`
+		}
+		err = json.Unmarshal(contents, &response)
+		samples = response.Samples
+	} else {
+		err = json.Unmarshal(contents, &samples)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse samples file %s", args.Path)
+	}
+
+	mode := calibration.HandEyeMode(args.Mode)
+	parent := args.Parent
+	if parent == "" {
+		if mode == calibration.EyeInHand {
+			return errors.Errorf("%s must be the name of the arm the camera is on", calibrateFlagParent)
+		}
+		parent = referenceframe.World
+	}
+	cal, err := calibration.SolveHandEye(mode, samples)
+	if err != nil {
+		return err
+	}
+	link, err := cal.LinkConfig(args.Frame, parent)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(handEyeOutput{
+		Frame:     link,
+		Target:    spatialmath.PoseToProtobuf(cal.Target),
+		Residuals: cal.Residuals,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	printf(w, "%s", out)
+	return nil
+}
+
+func handEyeModes() []string {
+	return []string{string(calibration.EyeInHand), string(calibration.EyeToHand)}
+}
+
+type calibrateIntrinsicsArgs struct {
+	Path           string
+	BoardType      string
+	Cols           int
+	Rows           int
+	SquareSize     float64
+	MarkerSize     float64
+	Dictionary     string
+	DictionaryFile string
+	Output         string
+}
+
+// CalibrateIntrinsicsAction is the corresponding action for 'calibrate intrinsics'.
+func CalibrateIntrinsicsAction(ctx context.Context, cmd *cli.Command, args calibrateIntrinsicsArgs) error {
+	return calibrateIntrinsics(cmd.Root().Writer, cmd.Root().ErrWriter, args)
+}
+
+// calibrateIntrinsics finds a calibration board in each of the images in a directory, and solves for the
+// intrinsics and distortion of the camera that took them. The calibration is printed as JSON, or written to the
+// output file. Images the board is not found in are skipped, with a warning written to errW.
+func calibrateIntrinsics(w, errW io.Writer, args calibrateIntrinsicsArgs) error {
+	board := &calibration.Board{
+		Type:           calibration.BoardType(args.BoardType),
+		Cols:           args.Cols,
+		Rows:           args.Rows,
+		SquareSize:     args.SquareSize,
+		MarkerSize:     args.MarkerSize,
+		Dictionary:     args.Dictionary,
+		DictionaryFile: args.DictionaryFile,
+	}
+	if err := board.Validate(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(args.Path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read image directory %s", args.Path)
+	}
+	var paths []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".png", ".jpg", ".jpeg":
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(args.Path, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	var views []*calibration.View
+	var width, height int
+	for _, path := range paths {
+		img, err := rimage.ReadImageFromFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read image %s", path)
+		}
+		if len(views) == 0 {
+			width, height = img.Bounds().Dx(), img.Bounds().Dy()
+		} else if img.Bounds().Dx() != width || img.Bounds().Dy() != height {
+			return errors.Errorf("image %s is %dx%d, but the images before it are %dx%d",
+				path, img.Bounds().Dx(), img.Bounds().Dy(), width, height)
+		}
+		view, err := board.FindCorners(img)
+		if err != nil {
+			warningf(errW, "skipping %s: %v", path, err)
+			continue
+		}
+		views = append(views, view)
+	}
+	if len(views) < calibration.MinIntrinsicViews {
+		return errors.Errorf("found the board in %d of %d images in %s, need at least %d",
+			len(views), len(paths), args.Path, calibration.MinIntrinsicViews)
+	}
+	cal, err := calibration.CalibrateIntrinsics(board, views, width, height)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(cal, "", "  ")
+	if err != nil {
+		return err
+	}
+	if args.Output == "" {
+		printf(w, "%s", out)
+		return nil
+	}
+	if err := os.WriteFile(args.Output, out, 0o600); err != nil {
+		return errors.Wrapf(err, "failed to write calibration to %s", args.Output)
+	}
+	printf(w, "calibrated from %d images with a reprojection error of %.3f px, written to %s",
+		len(views), cal.ReprojectionError, args.Output)
+	return nil
+}
+
+func boardTypes() []string {
+	return []string{string(calibration.Checkerboard), string(calibration.Charuco)}
+}
+
+func charucoDictionaries() []string {
+	return calibration.CharucoDictionaries()
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/vision/calibration"
+)
+
+func TestCalibrateHandEye(t *testing.T) {
+	// a camera fixed above the arm, looking down at a target on the end effector
+	camera := spatialmath.NewPose(r3.Vector{X: 500, Z: 1000}, &spatialmath.OrientationVectorDegrees{OZ: -1, Theta: 90})
+	target := spatialmath.NewPoseFromPoint(r3.Vector{Z: 20})
+	var samples []calibration.Sample
+	for _, o := range []spatialmath.Orientation{
+		&spatialmath.OrientationVectorDegrees{OZ: -1},
+		&spatialmath.OrientationVectorDegrees{OX: 0.2, OZ: -1, Theta: 30},
+		&spatialmath.OrientationVectorDegrees{OY: 0.3, OZ: -1, Theta: -20},
+		&spatialmath.OrientationVectorDegrees{OX: -0.1, OY: -0.2, OZ: -1, Theta: 60},
+	} {
+		arm := spatialmath.NewPose(r3.Vector{X: 450, Y: 30, Z: 300}, o)
+		samples = append(samples, calibration.Sample{
+			ArmPose:    arm,
+			TargetPose: spatialmath.PoseBetween(camera, spatialmath.Compose(arm, target)),
+		})
+	}
+	dir := t.TempDir()
+	samplesPath := filepath.Join(dir, "samples.json")
+	contents, err := json.Marshal(samples)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, os.WriteFile(samplesPath, contents, 0o600), test.ShouldBeNil)
+	responsePath := filepath.Join(dir, "response.json")
+	contents, err = json.Marshal(map[string]interface{}{"samples": samples})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, os.WriteFile(responsePath, contents, 0o600), test.ShouldBeNil)
+
+	for _, path := range []string{samplesPath, responsePath} {
+		var out bytes.Buffer
+		args := calibrateHandEyeArgs{Path: path, Mode: string(calibration.EyeToHand), Frame: "overhead"}
+		test.That(t, calibrateHandEye(&out, args), test.ShouldBeNil)
+		var printed struct {
+			Frame     referenceframe.LinkConfig `json:"frame"`
+			Residuals calibration.Residuals     `json:"residuals"`
+		}
+		test.That(t, json.Unmarshal(out.Bytes(), &printed), test.ShouldBeNil)
+		test.That(t, printed.Frame.ID, test.ShouldEqual, "overhead")
+		test.That(t, printed.Frame.Parent, test.ShouldEqual, referenceframe.World)
+		pose, err := printed.Frame.Pose()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, spatialmath.PoseAlmostEqualEps(pose, camera, 1e-3), test.ShouldBeTrue)
+		test.That(t, printed.Residuals.Translation, test.ShouldHaveLength, 4)
+		test.That(t, printed.Residuals.TranslationMax, test.ShouldBeLessThan, 1e-3)
+	}
+
+	err = calibrateHandEye(&bytes.Buffer{}, calibrateHandEyeArgs{Path: samplesPath, Mode: string(calibration.EyeInHand), Frame: "cam"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, calibrateFlagParent)
+	err = calibrateHandEye(&bytes.Buffer{}, calibrateHandEyeArgs{Path: filepath.Join(dir, "missing.json"), Mode: "eye_to_hand"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestCalibrateIntrinsics(t *testing.T) {
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 480, Height: 360, Fx: 400, Fy: 400, Ppx: 242, Ppy: 178}
+	board := &calibration.Board{Type: calibration.Checkerboard, Cols: 7, Rows: 5, SquareSize: 30}
+	const squarePixels = 60
+	texture, err := calibration.RenderBoard(board, squarePixels)
+	test.That(t, err, test.ShouldBeNil)
+	dir := t.TempDir()
+	for i, o := range []spatialmath.Orientation{
+		&spatialmath.OrientationVectorDegrees{OZ: 1},
+		&spatialmath.OrientationVectorDegrees{OX: 0.6, OZ: 1, Theta: 10},
+		&spatialmath.OrientationVectorDegrees{OY: -0.7, OZ: 1, Theta: -5},
+		&spatialmath.OrientationVectorDegrees{OX: -0.4, OY: 0.5, OZ: 1, Theta: 30},
+	} {
+		// ray trace the board, which is a plane at z = 0 in its own frame, centered in front of the camera
+		center := spatialmath.NewPoseFromPoint(r3.Vector{X: -3.5 * board.SquareSize, Y: -2.5 * board.SquareSize})
+		inv := spatialmath.PoseInverse(spatialmath.Compose(spatialmath.NewPose(r3.Vector{Z: 250}, o), center))
+		img := image.NewGray(image.Rect(0, 0, intrinsics.Width, intrinsics.Height))
+		for y := 0; y < intrinsics.Height; y++ {
+			for x := 0; x < intrinsics.Width; x++ {
+				// average four rays through each pixel, so that the edges of the squares are smooth
+				sum := 0
+				for _, d := range [][2]float64{{0.25, 0.25}, {0.75, 0.25}, {0.25, 0.75}, {0.75, 0.75}} {
+					dir := r3.Vector{X: (float64(x) + d[0] - intrinsics.Ppx) / intrinsics.Fx, Y: (float64(y) + d[1] - intrinsics.Ppy) / intrinsics.Fy, Z: 1}
+					origin := inv.Point()
+					ray := spatialmath.Compose(inv, spatialmath.NewPoseFromPoint(dir)).Point().Sub(origin)
+					hit := origin.Add(ray.Mul(-origin.Z / ray.Z))
+					pt := image.Pt(int((hit.X/board.SquareSize+1)*squarePixels), int((hit.Y/board.SquareSize+1)*squarePixels))
+					if pt.In(texture.Bounds()) {
+						sum += int(texture.GrayAt(pt.X, pt.Y).Y)
+					} else {
+						sum += 128
+					}
+				}
+				img.SetGray(x, y, color.Gray{Y: uint8(sum / 4)})
+			}
+		}
+		test.That(t, rimage.WriteImageToFile(filepath.Join(dir, fmt.Sprintf("view%d.png", i)), img), test.ShouldBeNil)
+	}
+	// an image without the board is skipped
+	test.That(t, rimage.WriteImageToFile(filepath.Join(dir, "blank.png"), image.NewGray(image.Rect(0, 0, 480, 360))), test.ShouldBeNil)
+
+	args := calibrateIntrinsicsArgs{Path: dir, BoardType: string(calibration.Checkerboard), Cols: 7, Rows: 5, SquareSize: 30}
+	var out, errOut bytes.Buffer
+	test.That(t, calibrateIntrinsics(&out, &errOut, args), test.ShouldBeNil)
+	test.That(t, errOut.String(), test.ShouldContainSubstring, "blank.png")
+	var cal calibration.IntrinsicCalibration
+	test.That(t, json.Unmarshal(out.Bytes(), &cal), test.ShouldBeNil)
+	test.That(t, cal.Intrinsics.Width, test.ShouldEqual, 480)
+	test.That(t, cal.Intrinsics.Fx, test.ShouldAlmostEqual, intrinsics.Fx, 0.02*intrinsics.Fx)
+	test.That(t, cal.Intrinsics.Ppx, test.ShouldAlmostEqual, intrinsics.Ppx, 5)
+	test.That(t, cal.ViewErrors, test.ShouldHaveLength, 4)
+
+	args.Output = filepath.Join(t.TempDir(), "calibration.json")
+	test.That(t, calibrateIntrinsics(&bytes.Buffer{}, &bytes.Buffer{}, args), test.ShouldBeNil)
+	contents, err := os.ReadFile(args.Output)
+	test.That(t, err, test.ShouldBeNil)
+	var written map[string]interface{}
+	test.That(t, json.Unmarshal(contents, &written), test.ShouldBeNil)
+	test.That(t, written, test.ShouldContainKey, "intrinsic_parameters")
+	test.That(t, written, test.ShouldContainKey, "distortion_parameters")
+
+	args.Cols = 9
+	test.That(t, calibrateIntrinsics(&bytes.Buffer{}, &bytes.Buffer{}, args), test.ShouldNotBeNil)
+	args.BoardType = "circles"
+	test.That(t, calibrateIntrinsics(&bytes.Buffer{}, &bytes.Buffer{}, args), test.ShouldNotBeNil)
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v3"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/data/export"
+)
+
+type dataLocalQueryArgs struct {
+	Path           string
+	ResourceName   string
+	Method         string
+	Start          string
+	End            string
+	Tags           []string
+	Limit          uint
+	Destination    string
+	KeyFile        string
+	PrivateKeyFile string
+}
+
+// DataLocalQueryAction is the corresponding action for 'data local query'. It works offline on a capture directory,
+// such as one copied off of a machine.
+func DataLocalQueryAction(ctx context.Context, cmd *cli.Command, args dataLocalQueryArgs) error {
+	return dataLocalQuery(cmd.Root().Writer, args)
+}
+
+// dataLocalQuery prints the captures in the capture directory which match the filters as JSON lines, and writes the
+// binary captures to the destination directory, if any.
+func dataLocalQuery(w io.Writer, args dataLocalQueryArgs) error {
+	query, err := localCaptureQuery(args.ResourceName, args.Method, args.Start, args.End, args.Tags)
+	if err != nil {
+		return err
+	}
+	query.Limit = int(args.Limit)
+	// the payloads of binary captures are only needed to write them to the destination
+	query.ExcludeBinary = args.Destination == ""
+	if err := registerLocalCaptureKey(args.KeyFile, args.PrivateKeyFile); err != nil {
+		return err
+	}
+	rows, err := data.QueryCaptureDir(args.Path, query)
+	if err != nil {
+		return errors.Wrapf(withCaptureKeyHint(err), "failed to query capture directory %s", args.Path)
+	}
+	if args.Destination != "" {
+		if err := os.MkdirAll(args.Destination, 0o700); err != nil {
+			return errors.Wrapf(err, "failed to create destination directory %s", args.Destination)
+		}
+	}
+
+	for _, row := range rows {
+		m := row.ToMap(false)
+		if args.Destination != "" && data.IsBinary(row.SensorData) {
+			path, err := writeLocalBinaryCapture(args.Destination, row)
+			if err != nil {
+				return err
+			}
+			m["exported_to"] = path
+		}
+		line, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		printf(w, "%s", line)
+	}
+	return nil
+}
+
+type dataLocalExportArgs struct {
+	Path           string
+	Destination    string
+	Format         string
+	ResourceName   string
+	Method         string
+	Start          string
+	End            string
+	Tags           []string
+	KeyFile        string
+	PrivateKeyFile string
+}
+
+// DataLocalExportAction is the corresponding action for 'data local export'.
+func DataLocalExportAction(ctx context.Context, cmd *cli.Command, args dataLocalExportArgs) error {
+	return dataLocalExport(cmd.Root().Writer, args)
+}
+
+// dataLocalExport exports the captures in the capture directory which match the filters and prints the paths of the
+// files it wrote.
+func dataLocalExport(w io.Writer, args dataLocalExportArgs) error {
+	format := export.Format(args.Format)
+	if !slices.Contains(export.Formats, format) {
+		return errors.Errorf("%s must be one of %s, got %q", dataFlagExportFormat, strings.Join(exportFormats(), ", "), args.Format)
+	}
+	query, err := localCaptureQuery(args.ResourceName, args.Method, args.Start, args.End, args.Tags)
+	if err != nil {
+		return err
+	}
+	if err := registerLocalCaptureKey(args.KeyFile, args.PrivateKeyFile); err != nil {
+		return err
+	}
+	paths, err := export.CaptureDir(args.Path, args.Destination, format, query)
+	if err != nil {
+		return errors.Wrapf(withCaptureKeyHint(err), "failed to export capture directory %s", args.Path)
+	}
+	if len(paths) == 0 {
+		printf(w, "No captures to export")
+		return nil
+	}
+	for _, path := range paths {
+		printf(w, "Exported %s", path)
+	}
+	return nil
+}
+
+func exportFormats() []string {
+	formats := make([]string, 0, len(export.Formats))
+	for _, format := range export.Formats {
+		formats = append(formats, string(format))
+	}
+	return formats
+}
+
+// registerLocalCaptureKey registers the capture key in the key file or private key file, if any, so that encrypted
+// capture files can be read.
+func registerLocalCaptureKey(keyFile, privateKeyFile string) error {
+	var key data.CaptureKey
+	var err error
+	switch {
+	case keyFile != "" && privateKeyFile != "":
+		return errors.Errorf("only one of %s and %s can be set", dataFlagKeyFile, dataFlagPrivateKeyFile)
+	case keyFile != "":
+		key, err = data.LoadCaptureKeyFile(keyFile)
+	case privateKeyFile != "":
+		key, err = data.LoadCaptureKeyPair("", privateKeyFile)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data.RegisterCaptureKey(key)
+	return nil
+}
+
+// withCaptureKeyHint adds how to pass a capture key to an error reading an encrypted capture file without one.
+func withCaptureKeyHint(err error) error {
+	if !errors.Is(err, data.ErrCaptureKeyMissing) {
+		return err
+	}
+	return errors.Wrapf(err, "pass the capture key with --%s or --%s", dataFlagKeyFile, dataFlagPrivateKeyFile)
+}
+
+func localCaptureQuery(resourceName, method, start, end string, tags []string) (data.CaptureQuery, error) {
+	query := data.CaptureQuery{ResourceName: resourceName, Method: method, Tags: tags}
+	if start != "" {
+		startTime, err := parseTimeString(start)
+		if err != nil {
+			return query, err
+		}
+		query.Start = startTime.AsTime()
+	}
+	if end != "" {
+		endTime, err := parseTimeString(end)
+		if err != nil {
+			return query, err
+		}
+		query.End = endTime.AsTime()
+	}
+	return query, nil
+}
+
+// writeLocalBinaryCapture writes the binary capture to a file named after its resource, method and time of capture.
+func writeLocalBinaryCapture(dir string, row data.CaptureRow) (string, error) {
+	timeRequested := row.SensorData.GetMetadata().GetTimeRequested().AsTime().UTC().Format("2006-01-02T15_04_05.000000000Z")
+	name := fmt.Sprintf("%s_%s_%s%s",
+		row.Metadata.GetComponentName(), row.Metadata.GetMethodName(), timeRequested, row.Metadata.GetFileExtension())
+	path := filepath.Join(dir, strings.ReplaceAll(name, string(filepath.Separator), "_"))
+	if err := os.WriteFile(path, row.SensorData.GetBinary(), 0o600); err != nil {
+		return "", errors.Wrapf(err, "failed to write binary capture to %s", path)
+	}
+	return path, nil
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/data"
+)
+
+func TestDataLocalQuery(t *testing.T) {
+	captureDir := t.TempDir()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	// writeCaptures writes a capture file with a capture every minute from the offset after start.
+	writeCaptures := func(dir string, offset time.Duration, md *v1.DataCaptureMetadata, items ...*v1.SensorData) {
+		test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+		f, err := data.NewCaptureFile(dir, md)
+		test.That(t, err, test.ShouldBeNil)
+		for i, item := range items {
+			at := timestamppb.New(start.Add(offset + time.Duration(i)*time.Minute))
+			item.Metadata = &v1.SensorMetadata{TimeRequested: at, TimeReceived: at}
+			test.That(t, f.WriteNext(item), test.ShouldBeNil)
+		}
+		test.That(t, f.Close(), test.ShouldBeNil)
+	}
+	readings, err := structpb.NewStruct(map[string]interface{}{"readings": map[string]interface{}{"watts": 5}})
+	test.That(t, err, test.ShouldBeNil)
+	writeCaptures(filepath.Join(captureDir, "power"), 0,
+		&v1.DataCaptureMetadata{ComponentName: "power", MethodName: "Readings", Type: v1.DataType_DATA_TYPE_TABULAR_SENSOR},
+		&v1.SensorData{Data: &v1.SensorData_Struct{Struct: readings}},
+		&v1.SensorData{Data: &v1.SensorData_Struct{Struct: readings}},
+	)
+	writeCaptures(filepath.Join(captureDir, "cam"), 10*time.Second,
+		&v1.DataCaptureMetadata{
+			ComponentName: "cam", MethodName: "ReadImage", Type: v1.DataType_DATA_TYPE_BINARY_SENSOR, FileExtension: ".jpeg",
+		},
+		&v1.SensorData{Data: &v1.SensorData_Binary{Binary: []byte("image")}},
+	)
+
+	// printedRows returns the JSON lines printed by the query.
+	printedRows := func(args dataLocalQueryArgs) []map[string]interface{} {
+		t.Helper()
+		var out bytes.Buffer
+		test.That(t, dataLocalQuery(&out, args), test.ShouldBeNil)
+		var rows []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var row map[string]interface{}
+			test.That(t, json.Unmarshal([]byte(line), &row), test.ShouldBeNil)
+			rows = append(rows, row)
+		}
+		return rows
+	}
+
+	rows := printedRows(dataLocalQueryArgs{Path: captureDir})
+	test.That(t, rows, test.ShouldHaveLength, 3)
+	test.That(t, rows[0]["component_name"], test.ShouldEqual, "power")
+	test.That(t, rows[0]["data"], test.ShouldResemble, map[string]interface{}{"readings": map[string]interface{}{"watts": 5.0}})
+	test.That(t, rows[1]["component_name"], test.ShouldEqual, "cam")
+	test.That(t, rows[1], test.ShouldNotContainKey, "binary")
+
+	rows = printedRows(dataLocalQueryArgs{Path: captureDir, ResourceName: "power", Start: "2024-05-01T12:00:30Z"})
+	test.That(t, rows, test.ShouldHaveLength, 1)
+	rows = printedRows(dataLocalQueryArgs{Path: captureDir, Limit: 2})
+	test.That(t, rows, test.ShouldHaveLength, 2)
+
+	destination := filepath.Join(t.TempDir(), "export")
+	rows = printedRows(dataLocalQueryArgs{Path: captureDir, Method: "ReadImage", Destination: destination})
+	test.That(t, rows, test.ShouldHaveLength, 1)
+	exported := rows[0]["exported_to"].(string)
+	test.That(t, filepath.Dir(exported), test.ShouldEqual, destination)
+	test.That(t, filepath.Base(exported), test.ShouldEqual, "cam_ReadImage_2024-05-01T12_00_10.000000000Z.jpeg")
+	contents, err := os.ReadFile(exported)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(contents), test.ShouldEqual, "image")
+
+	test.That(t, dataLocalQuery(&bytes.Buffer{}, dataLocalQueryArgs{Path: captureDir, End: "noon"}), test.ShouldNotBeNil)
+	test.That(t, dataLocalQuery(&bytes.Buffer{}, dataLocalQueryArgs{Path: filepath.Join(captureDir, "missing")}), test.ShouldNotBeNil)
+}
+
+func TestDataLocalExport(t *testing.T) {
+	captureDir := t.TempDir()
+	f, err := data.NewCaptureFile(captureDir, &v1.DataCaptureMetadata{
+		ComponentName: "power", MethodName: "Readings", Type: v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	readings, err := structpb.NewStruct(map[string]interface{}{"readings": map[string]interface{}{"watts": 5}})
+	test.That(t, err, test.ShouldBeNil)
+	at := timestamppb.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	test.That(t, f.WriteNext(&v1.SensorData{
+		Metadata: &v1.SensorMetadata{TimeRequested: at, TimeReceived: at},
+		Data:     &v1.SensorData_Struct{Struct: readings},
+	}), test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+
+	destination := t.TempDir()
+	for _, format := range []string{"mcap", "csv", "parquet"} {
+		var out bytes.Buffer
+		test.That(t, dataLocalExport(&out, dataLocalExportArgs{Path: captureDir, Destination: destination, Format: format}),
+			test.ShouldBeNil)
+		test.That(t, out.String(), test.ShouldStartWith, "Exported "+destination)
+	}
+	entries, err := os.ReadDir(destination)
+	test.That(t, err, test.ShouldBeNil)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	test.That(t, names, test.ShouldResemble, []string{"captures.mcap", "power_Readings.csv", "power_Readings.parquet"})
+
+	var out bytes.Buffer
+	test.That(t, dataLocalExport(&out, dataLocalExportArgs{
+		Path: captureDir, Destination: destination, Format: "csv", ResourceName: "missing",
+	}), test.ShouldBeNil)
+	test.That(t, out.String(), test.ShouldContainSubstring, "No captures to export")
+
+	err = dataLocalExport(&bytes.Buffer{}, dataLocalExportArgs{Path: captureDir, Destination: destination, Format: "xlsx"})
+	test.That(t, err, test.ShouldBeError, `format must be one of mcap, csv, parquet, got "xlsx"`)
+}
+
+func TestDataLocalEncryptedCaptures(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "capture.key")
+	dataKey := make([]byte, 32)
+	_, err := rand.Read(dataKey)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, os.WriteFile(keyFile, []byte(hex.EncodeToString(dataKey)), 0o600), test.ShouldBeNil)
+	key, err := data.LoadCaptureKeyFile(keyFile)
+	test.That(t, err, test.ShouldBeNil)
+
+	captureDir := t.TempDir()
+	f, err := data.NewEncryptedCaptureFile(captureDir, &v1.DataCaptureMetadata{
+		ComponentName: "cam", MethodName: "ReadImage", Type: v1.DataType_DATA_TYPE_BINARY_SENSOR, FileExtension: ".jpeg",
+	}, key)
+	test.That(t, err, test.ShouldBeNil)
+	at := timestamppb.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	test.That(t, f.WriteNext(&v1.SensorData{
+		Metadata: &v1.SensorMetadata{TimeRequested: at, TimeReceived: at},
+		Data:     &v1.SensorData_Binary{Binary: []byte("image")},
+	}), test.ShouldBeNil)
+	test.That(t, f.Close(), test.ShouldBeNil)
+
+	// without the key the captures can't be read, and the error says how to pass it
+	err = dataLocalQuery(&bytes.Buffer{}, dataLocalQueryArgs{Path: captureDir})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "--key-file")
+	err = dataLocalExport(&bytes.Buffer{}, dataLocalExportArgs{Path: captureDir, Destination: t.TempDir(), Format: "mcap"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "--key-file")
+
+	err = dataLocalQuery(&bytes.Buffer{}, dataLocalQueryArgs{Path: captureDir, KeyFile: keyFile, PrivateKeyFile: keyFile})
+	test.That(t, err, test.ShouldBeError, "only one of key-file and private-key-file can be set")
+
+	var out bytes.Buffer
+	test.That(t, dataLocalQuery(&out, dataLocalQueryArgs{Path: captureDir, KeyFile: keyFile}), test.ShouldBeNil)
+	test.That(t, out.String(), test.ShouldContainSubstring, `"component_name":"cam"`)
+	destination := t.TempDir()
+	out.Reset()
+	test.That(t, dataLocalExport(&out, dataLocalExportArgs{
+		Path: captureDir, Destination: destination, Format: "mcap", KeyFile: keyFile,
+	}), test.ShouldBeNil)
+	test.That(t, out.String(), test.ShouldStartWith, "Exported "+destination)
+}
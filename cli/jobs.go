@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v3"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/services/generic/jobs"
+)
+
+type machinesPartJobsArgs struct {
+	Organization string
+	Location     string
+	Machine      string
+	Part         string
+	JobsService  string
+}
+
+// MachinesPartJobsAction prints the recent runs of every job of a machine part.
+func MachinesPartJobsAction(ctx context.Context, cmd *cli.Command, args machinesPartJobsArgs) error {
+	client, err := newViamClient(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	globalArgs, err := getGlobalArgs(cmd)
+	if err != nil {
+		return err
+	}
+
+	dialCtx, fqdn, rpcOpts, err := client.prepareDial(ctx, args.Organization, args.Location, args.Machine, args.Part, globalArgs.Debug)
+	if err != nil {
+		return err
+	}
+
+	logger := globalArgs.createLogger()
+
+	robotClient, err := client.connectToRobot(dialCtx, fqdn, rpcOpts, globalArgs.Debug, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(ctx))
+	}()
+
+	if args.JobsService == "" {
+		status, err := robotClient.MachineStatus(ctx)
+		if err != nil {
+			return err
+		}
+		printJobStatuses(cmd.Root().Writer, status.JobStatuses)
+		return nil
+	}
+
+	// the machine status API only carries the times of runs, the rest of each run comes from the jobs service
+	svc, err := generic.FromProvider(robotClient, args.JobsService)
+	if err != nil {
+		return err
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{jobs.GetJobStatusesCommand: true})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the job statuses from %s", args.JobsService)
+	}
+	statuses, err := jobs.JobStatusesFromResponse(resp)
+	if err != nil {
+		return err
+	}
+	printJobStatuses(cmd.Root().Writer, statuses)
+	return nil
+}
+
+// printJobStatuses prints the job statuses sorted by job name.
+func printJobStatuses(w io.Writer, statuses map[string]robot.JobStatus) {
+	if len(statuses) == 0 {
+		printf(w, "No jobs have run")
+		return
+	}
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		status := statuses[name]
+		printf(w, "%s:", name)
+		printf(w, "\t%d recent successful runs%s", len(status.RecentSuccessfulRuns), lastRunSuffix(status.RecentSuccessfulRuns))
+		printf(w, "\t%d recent failed runs%s", len(status.RecentFailedRuns), lastRunSuffix(status.RecentFailedRuns))
+		for _, run := range status.Runs {
+			line := fmt.Sprintf("\t%s took %v over %d attempt(s)", run.Start.Format(time.RFC3339), run.End.Sub(run.Start), run.Attempts)
+			if run.Error != "" {
+				line += ", failed: " + run.Error
+			} else if run.Response != "" {
+				line += ", responded: " + run.Response
+			}
+			printf(w, "%s", line)
+		}
+	}
+}
+
+func lastRunSuffix(runs []time.Time) string {
+	if len(runs) == 0 {
+		return ""
+	}
+	return ", the last at " + slices.MaxFunc(runs, time.Time.Compare).Format(time.RFC3339)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/robot"
+)
+
+func TestPrintJobStatuses(t *testing.T) {
+	var out bytes.Buffer
+	printJobStatuses(&out, nil)
+	test.That(t, out.String(), test.ShouldEqual, "No jobs have run\n")
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	out.Reset()
+	printJobStatuses(&out, map[string]robot.JobStatus{
+		"b job": {RecentFailedRuns: []time.Time{start, start.Add(time.Minute)}},
+		"a job": {
+			RecentSuccessfulRuns: []time.Time{start},
+			Runs: []robot.JobRun{
+				{Start: start, End: start.Add(time.Second), Attempts: 2, Response: `{"ok":true}`},
+			},
+		},
+	})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	test.That(t, lines, test.ShouldResemble, []string{
+		"a job:",
+		"\t1 recent successful runs, the last at 2024-01-02T03:04:05Z",
+		"\t0 recent failed runs",
+		`	2024-01-02T03:04:05Z took 1s over 2 attempt(s), responded: {"ok":true}`,
+		"b job:",
+		"\t0 recent successful runs",
+		"\t2 recent failed runs, the last at 2024-01-02T03:05:05Z",
+	})
+}
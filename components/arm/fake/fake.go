@@ -89,7 +89,7 @@ func NewArm(ctx context.Context, deps resource.Dependencies, conf resource.Confi
 	return a, nil
 }
 
-func buildModel(cfg resource.Config, newConf *Config) (referenceframe.Model, error) {
+func buildModel(cfg resource.Config, newConf *Config, logger logging.Logger) (referenceframe.Model, error) {
 	var (
 		model referenceframe.Model
 		err   error
@@ -103,7 +103,7 @@ func buildModel(cfg resource.Config, newConf *Config) (referenceframe.Model, err
 	case armModel != "":
 		model, err = modelFromName(armModel, cfg.Name)
 	case modelPath != "":
-		model, err = referenceframe.KinematicModelFromFile(modelPath, cfg.Name)
+		model, err = referenceframe.KinematicModelFromFileWithLogger(modelPath, cfg.Name, logger)
 	default:
 		// if no arm model is specified, we return a fake arm with 1 dof and 0 spatial transformation
 		model, err = modelFromName(Model.Name, cfg.Name)
@@ -133,7 +133,7 @@ func (a *Arm) Reconfigure(ctx context.Context, deps resource.Dependencies, conf
 		return err
 	}
 
-	model, err := buildModel(conf, newConf)
+	model, err := buildModel(conf, newConf, a.logger)
 	if err != nil {
 		return err
 	}
@@ -9,6 +9,7 @@ import (
 	commonpb "go.viam.com/api/common/v1"
 
 	models3d "go.viam.com/rdk/components/arm/fake/3d_models"
+	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/spatialmath"
 )
@@ -58,7 +59,7 @@ func modelFromName(model, name string) (referenceframe.Model, error) {
 	}
 }
 
-func buildModel(resName string, conf *Config) (referenceframe.Model, error) {
+func buildModel(resName string, conf *Config, logger logging.Logger) (referenceframe.Model, error) {
 	armModel := conf.Model
 	modelPath := conf.ModelFilePath
 
@@ -68,7 +69,7 @@ func buildModel(resName string, conf *Config) (referenceframe.Model, error) {
 	case armModel != "":
 		return modelFromName(armModel, resName)
 	case modelPath != "":
-		return referenceframe.KinematicModelFromFile(modelPath, resName)
+		return referenceframe.KinematicModelFromFileWithLogger(modelPath, resName, logger)
 	default:
 		return nil, errors.New("a model must be defined for a simulated arm")
 	}
@@ -14,6 +14,7 @@ import (
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/spatialmath"
@@ -24,11 +25,17 @@ import (
 // 2. Operation started -> targetInputs != nil, done == false, stopped == false
 // 3. Operation successful -> done == true
 // 4. Operation failed -> stopped == true
+//
+// A timed operation follows `timedInputs` from `start` rather than moving at the arm's speed, and its
+// `targetInputs` are the last of them.
 type operation struct {
 	// targetInputs is in radians.
 	targetInputs []float64
 	done         bool
 	stopped      bool
+
+	timedInputs []motionplan.TimedInputs
+	start       time.Time
 }
 
 func (op operation) isMoving() bool {
@@ -122,7 +129,7 @@ func NewArm(ctx context.Context, deps resource.Dependencies, resConf resource.Co
 		speed = armConf.Speed
 	}
 
-	model, err := buildModel(resConf.Name, armConf)
+	model, err := buildModel(resConf.Name, armConf, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +198,11 @@ func (sa *simulatedArm) updateForTime(now time.Time) {
 	timeSinceLastUpdate := now.Sub(sa.lastUpdated)
 	sa.lastUpdated = now
 
+	if sa.operation.timedInputs != nil {
+		sa.updateTimedForTime(now)
+		return
+	}
+
 	// Find the maximum travel distance. Given all joints (right now) move at the same speed, we can
 	// map this to how long the whole movement will take.
 	var maxDist float64
@@ -256,6 +268,31 @@ func (sa *simulatedArm) updateForTime(now time.Time) {
 	}
 }
 
+// updateTimedForTime sets the joints to where the timed operation has them at `now`, interpolating
+// between the timed inputs around it. Must hold the lock.
+func (sa *simulatedArm) updateTimedForTime(now time.Time) {
+	elapsed := now.Sub(sa.operation.start)
+	timed := sa.operation.timedInputs
+	last := timed[len(timed)-1]
+	if elapsed >= last.Time {
+		copy(sa.currInputs, last.Inputs)
+		sa.operation.done = true
+		return
+	}
+	next := 1
+	for next < len(timed)-1 && timed[next].Time <= elapsed {
+		next++
+	}
+	prev := timed[next-1]
+	frac := 0.0
+	if span := timed[next].Time - prev.Time; span > 0 {
+		frac = math.Max(0, float64(elapsed-prev.Time)/float64(span))
+	}
+	for jointIdx := range sa.currInputs {
+		sa.currInputs[jointIdx] = prev.Inputs[jointIdx] + frac*(timed[next].Inputs[jointIdx]-prev.Inputs[jointIdx])
+	}
+}
+
 func (sa *simulatedArm) EndPosition(
 	ctx context.Context, extra map[string]interface{},
 ) (spatialmath.Pose, error) {
@@ -304,6 +341,37 @@ func (sa *simulatedArm) MoveToJointPositions(
 
 	// An operation was "started". `MoveToJointPositions` blocks until the movement completes or is
 	// canceled.
+	return sa.waitForOperation(ctx)
+}
+
+// GoToTimedInputs moves the joints through the timed inputs, reaching each at its time after the
+// call. It blocks until the last inputs are reached or the movement is canceled.
+func (sa *simulatedArm) GoToTimedInputs(ctx context.Context, inputs []motionplan.TimedInputs) error {
+	if len(inputs) == 0 {
+		return errors.New("no timed inputs to move through")
+	}
+	for _, timed := range inputs {
+		if len(timed.Inputs) != len(sa.currInputs) {
+			return errors.New("timed inputs do not match the degrees of freedom of the arm")
+		}
+		if err := arm.CheckDesiredJointPositions(ctx, sa, timed.Inputs); err != nil {
+			return err
+		}
+	}
+
+	sa.mu.Lock()
+	sa.operation = operation{
+		targetInputs: inputs[len(inputs)-1].Inputs,
+		timedInputs:  inputs,
+		start:        sa.lastUpdated,
+	}
+	sa.mu.Unlock()
+
+	return sa.waitForOperation(ctx)
+}
+
+// waitForOperation polls until the operation in flight completes, is stopped or is canceled.
+func (sa *simulatedArm) waitForOperation(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -10,7 +10,9 @@ import (
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
 )
 
 func TestBasic(t *testing.T) {
@@ -181,3 +183,59 @@ func TestTimeSimulation(t *testing.T) {
 	err = simArm.MoveToJointPositions(ctx, []float64{1, -2, 0, 0, 0, 0}, nil)
 	test.That(t, err, test.ShouldBeNil)
 }
+
+func TestTimedInputs(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	resConf := resource.Config{
+		Name:                "arm",
+		API:                 arm.API,
+		Model:               Model,
+		ConvertedAttributes: &Config{Model: "lite6"},
+	}
+	simArmI, err := NewArm(ctx, nil, resConf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	simArm := simArmI.(*simulatedArm)
+	var _ framesystem.TimedInputEnabled = simArm
+
+	// The timed inputs are followed regardless of the speed of the arm.
+	timed := []motionplan.TimedInputs{
+		{Time: 0, Inputs: []float64{0, 0, 0, 0, 0, 0}},
+		{Time: 500 * time.Millisecond, Inputs: []float64{1, 0, 0, 0, 0, 0}},
+		{Time: 1500 * time.Millisecond, Inputs: []float64{1, 2, 0, 0, 0, 0}},
+	}
+	moveFuture := make(chan error)
+	go func() {
+		moveFuture <- simArm.GoToTimedInputs(ctx, timed)
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		isMoving, err := simArm.IsMoving(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, isMoving, test.ShouldBeTrue)
+	})
+
+	clock := simArm.lastUpdated
+	clock = clock.Add(250 * time.Millisecond)
+	simArm.updateForTime(clock)
+	currInputs, err := simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs, test.ShouldResemble, []float64{0.5, 0, 0, 0, 0, 0})
+
+	clock = clock.Add(750 * time.Millisecond)
+	simArm.updateForTime(clock)
+	currInputs, err = simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs, test.ShouldResemble, []float64{1, 1, 0, 0, 0, 0})
+	test.That(t, simArm.operation.isMoving(), test.ShouldBeTrue)
+
+	clock = clock.Add(time.Second)
+	simArm.updateForTime(clock)
+	test.That(t, <-moveFuture, test.ShouldBeNil)
+	currInputs, err = simArm.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, currInputs, test.ShouldResemble, []float64{1, 2, 0, 0, 0, 0})
+
+	test.That(t, simArm.GoToTimedInputs(ctx, nil), test.ShouldNotBeNil)
+	test.That(t, simArm.Close(ctx), test.ShouldBeNil)
+}
@@ -76,7 +76,7 @@ func (wrapper *Arm) Reconfigure(ctx context.Context, deps resource.Dependencies,
 	if err != nil {
 		return err
 	}
-	model, err := referenceframe.KinematicModelFromFile(newConf.ModelFilePath, conf.Name)
+	model, err := referenceframe.KinematicModelFromFileWithLogger(newConf.ModelFilePath, conf.Name, wrapper.logger)
 	if err != nil {
 		return err
 	}
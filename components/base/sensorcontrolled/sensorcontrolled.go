@@ -28,6 +28,7 @@ const (
 	typeAngVel         = "angular_velocity"
 	defaultControlFreq = 10 // Hz
 	getPID             = "get_tuned_pid"
+	autotune           = "autotune"
 )
 
 var (
@@ -273,10 +274,17 @@ func (sb *sensorBase) Geometries(ctx context.Context, extra map[string]interface
 func (sb *sensorBase) DoCommand(ctx context.Context, req map[string]interface{}) (map[string]interface{}, error) {
 	resp := make(map[string]interface{})
 
+	if tuneReq, ok := req[autotune]; ok {
+		result, err := sb.autotune(ctx, tuneReq)
+		if err != nil {
+			return nil, err
+		}
+		resp[autotune] = result.Map()
+	}
+
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
-	ok := req[getPID].(bool)
-	if ok {
+	if ok, _ := req[getPID].(bool); ok {
 		var respStr string
 		for _, pidConf := range *sb.tunedVals {
 			if !pidConf.NeedsAutoTuning() {
@@ -549,3 +549,54 @@ func TestSensorBaseDoCommand(t *testing.T) {
 	test.That(t, resp, test.ShouldResemble, emptyMap)
 	test.That(t, b.Close(ctx), test.ShouldBeNil)
 }
+
+func TestSensorBaseAutotune(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	deps, cfg := msDependencies(t, []string{"setvel1"})
+
+	// the angular velocity of the base follows 100 degs per second per unit of power with a time constant of 50ms
+	var mu sync.Mutex
+	var angularPower, angularVelocity float64
+	var last time.Time
+	injectBase := deps[base.Named("test_base")].(*inject.Base)
+	injectBase.SetPowerFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		angularPower = angular.Z
+		return nil
+	}
+	ms := deps[movementsensor.Named("setvel1")].(*inject.MovementSensor)
+	ms.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		if !last.IsZero() {
+			angularVelocity += now.Sub(last).Seconds() * (100*angularPower - angularVelocity) / 0.05
+		}
+		last = now
+		return spatialmath.AngularVelocity{Z: angularVelocity}, nil
+	}
+
+	b, err := createSensorBase(ctx, deps, cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, b.Close(ctx), test.ShouldBeNil)
+	}()
+
+	resp, err := b.DoCommand(ctx, map[string]interface{}{
+		"autotune": map[string]interface{}{"method": "step_response", "signal": 1, "frequency": 200.0, "duration_sec": 5.0},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	result, ok := resp["autotune"].(map[string]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	model := result["model"].(map[string]interface{})
+	test.That(t, model["gain"], test.ShouldAlmostEqual, 100*0.00392157, 0.05)
+	test.That(t, result["gains"].(map[string]interface{})["p"], test.ShouldBeGreaterThan, 0)
+	mu.Lock()
+	test.That(t, angularPower, test.ShouldEqual, 0)
+	mu.Unlock()
+
+	_, err = b.DoCommand(ctx, map[string]interface{}{"autotune": map[string]interface{}{"method": "relay", "signal": 2}})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -5,8 +5,10 @@ import (
 	"math"
 
 	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
 
 	"go.viam.com/rdk/control"
+	"go.viam.com/rdk/resource"
 )
 
 // SetVelocity commands a base to move at the requested linear and angular velocites.
@@ -150,3 +152,44 @@ func (sb *sensorBase) State(ctx context.Context) ([]float64, error) {
 	}
 	return []float64{linvel.Y, angvel.Z}, nil
 }
+
+// autotune runs the autotuning experiment configured by the request against the linear velocity of the base, or the
+// angular velocity when the signal is 1, and returns the identified model and proposed gains without applying them.
+func (sb *sensorBase) autotune(ctx context.Context, req interface{}) (control.TuningResult, error) {
+	attrs, ok := req.(map[string]interface{})
+	if !ok {
+		return control.TuningResult{}, errors.Errorf("%s expects the tuner config, got %v", autotune, req)
+	}
+	cfg, err := resource.TransformAttributeMap[control.TunerConfig](attrs)
+	if err != nil {
+		return control.TuningResult{}, err
+	}
+	if sb.velocities == nil {
+		return control.TuningResult{}, errors.New("autotuning needs a movement sensor which reports linear and angular velocities")
+	}
+
+	if sb.loop != nil && sb.loop.GetTuning(ctx) {
+		return control.TuningResult{}, control.TuningInProgressErr(sb.Name().ShortName())
+	}
+	sb.opMgr.CancelRunning(ctx)
+	ctx, done := sb.opMgr.New(ctx)
+	defer done()
+	if sb.loop != nil {
+		sb.loop.Pause()
+	}
+	return control.Autotune(ctx, &baseTuningTarget{sb}, cfg)
+}
+
+// baseTuningTarget drives the base directly during autotuning, bypassing its paused control loop.
+type baseTuningTarget struct {
+	sb *sensorBase
+}
+
+func (t *baseTuningTarget) SetState(ctx context.Context, state []*control.Signal) error {
+	return t.sb.controlledBase.SetPower(ctx,
+		r3.Vector{Y: state[0].GetSignalValueAt(0)}, r3.Vector{Z: state[1].GetSignalValueAt(0)}, nil)
+}
+
+func (t *baseTuningTarget) State(ctx context.Context) ([]float64, error) {
+	return t.sb.State(ctx)
+}
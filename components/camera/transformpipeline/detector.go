@@ -22,6 +22,9 @@ type detectorConfig struct {
 	DetectorName        string   `json:"detector_name"`
 	ConfidenceThreshold float64  `json:"confidence_threshold"`
 	ValidLabels         []string `json:"valid_labels"`
+	// MaskOpacity is the opacity, from 0 to 1, of the masks of detections that have them. It defaults to
+	// objectdetection.DefaultMaskOpacity.
+	MaskOpacity *float64 `json:"mask_opacity,omitempty"`
 }
 
 // detectorSource takes an image from the camera, and overlays the detections from the detector.
@@ -30,6 +33,7 @@ type detectorSource struct {
 	detectorName string
 	labelFilter  objectdetection.Postprocessor // must build from ValidLabels
 	confFilter   objectdetection.Postprocessor
+	maskOpacity  float64
 	r            robot.Robot
 }
 
@@ -54,6 +58,13 @@ func newDetectionsTransform(
 	if props.DistortionParams != nil {
 		cameraModel.Distortion = props.DistortionParams
 	}
+	maskOpacity := objectdetection.DefaultMaskOpacity
+	if conf.MaskOpacity != nil {
+		if *conf.MaskOpacity < 0 || *conf.MaskOpacity > 1 {
+			return nil, camera.UnspecifiedStream, fmt.Errorf("mask_opacity must be between 0 and 1, got %v", *conf.MaskOpacity)
+		}
+		maskOpacity = *conf.MaskOpacity
+	}
 	confFilter := objectdetection.NewScoreFilter(conf.ConfidenceThreshold)
 	validLabels := make(map[string]interface{})
 	for _, l := range conf.ValidLabels {
@@ -65,6 +76,7 @@ func newDetectionsTransform(
 		conf.DetectorName,
 		labelFilter,
 		confFilter,
+		maskOpacity,
 		r,
 	}
 	src, err := camera.NewVideoSourceFromReader(ctx, detector, &cameraModel, camera.ColorStream)
@@ -74,7 +86,7 @@ func newDetectionsTransform(
 	return src, camera.ColorStream, err
 }
 
-// Read returns the image overlaid with the detection bounding boxes.
+// Read returns the image overlaid with the detection bounding boxes and masks.
 func (ds *detectorSource) Read(ctx context.Context) (image.Image, func(), error) {
 	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::detector::Read")
 	defer span.End()
@@ -96,7 +108,7 @@ func (ds *detectorSource) Read(ctx context.Context) (image.Image, func(), error)
 	dets = ds.confFilter(dets)
 	dets = ds.labelFilter(dets)
 
-	res, err := objectdetection.Overlay(img, dets)
+	res, err := objectdetection.OverlayWithMaskOpacity(img, dets, ds.maskOpacity)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not overlay bounding boxes: %w", err)
 	}
@@ -3,6 +3,8 @@ package transformpipeline
 import (
 	"context"
 	"encoding/json"
+	"image"
+	"image/color"
 	"os"
 	"testing"
 
@@ -10,6 +12,7 @@ import (
 	"go.viam.com/utils/artifact"
 
 	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/fake"
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
@@ -19,7 +22,9 @@ import (
 	_ "go.viam.com/rdk/services/mlmodel/register"
 	"go.viam.com/rdk/services/vision"
 	_ "go.viam.com/rdk/services/vision/register"
+	"go.viam.com/rdk/testutils/inject"
 	rutils "go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/objectdetection"
 )
 
 func writeTempConfig(cfg *config.Config) (string, error) {
@@ -152,3 +157,49 @@ func BenchmarkColorDetectionSource(b *testing.B) {
 	}
 	test.That(b, detector.Close(context.Background()), test.ShouldBeNil)
 }
+
+func TestDetectionsTransformMasks(t *testing.T) {
+	ctx := context.Background()
+	img := image.NewGray(image.Rect(0, 0, 40, 40))
+	box := image.Rect(10, 10, 30, 30)
+	mask := image.NewAlpha(box)
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		for x := box.Min.X; x < 20; x++ {
+			mask.SetAlpha(x, y, color.Alpha{A: 255})
+		}
+	}
+	srv := inject.NewVisionService("detector")
+	srv.DetectionsFunc = func(
+		ctx context.Context, img image.Image, extra map[string]interface{},
+	) ([]objectdetection.Detection, error) {
+		return []objectdetection.Detection{objectdetection.NewMaskedDetection(img.Bounds(), box, mask, 0.9, "A")}, nil
+	}
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		return srv, nil
+	}
+	source, err := camera.NewVideoSourceFromReader(ctx, &fake.StaticSource{ColorImg: img}, nil, camera.UnspecifiedStream)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, _, err = newDetectionsTransform(ctx, source, r, rutils.AttributeMap{"detector_name": "detector", "mask_opacity": 1.5})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	for _, tc := range []struct {
+		attrs  rutils.AttributeMap
+		filled bool
+	}{
+		{rutils.AttributeMap{"detector_name": "detector"}, true},
+		{rutils.AttributeMap{"detector_name": "detector", "mask_opacity": 0}, false},
+	} {
+		detSource, _, err := newDetectionsTransform(ctx, source, r, tc.attrs)
+		test.That(t, err, test.ShouldBeNil)
+		out, _, err := camera.ReadImage(ctx, detSource)
+		test.That(t, err, test.ShouldBeNil)
+		// inside the mask, and inside the bounding box but outside the mask
+		red, _, _, _ := out.At(15, 20).RGBA()
+		test.That(t, red > 0, test.ShouldEqual, tc.filled)
+		red, _, _, _ = out.At(25, 20).RGBA()
+		test.That(t, red, test.ShouldEqual, 0)
+		test.That(t, detSource.Close(ctx), test.ShouldBeNil)
+	}
+}
@@ -51,7 +51,7 @@ var registeredTransformConfigs = map[transformType]*transformRegistration{
 	transformTypeDetections: {
 		string(transformTypeDetections),
 		&detectorConfig{},
-		"Overlays object detections on the image. Can use any detector registered in the vision service.",
+		"Overlays object detections and their masks on the image. Can use any detector registered in the vision service.",
 	},
 	transformTypeClassifications: {
 		string(transformTypeClassifications),
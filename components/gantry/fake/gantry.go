@@ -31,7 +31,7 @@ func (conf *Config) Validate(path string) ([]string, []string, error) {
 	return nil, nil, err
 }
 
-func makeGantryModel(cfg resource.Config, newConf *Config) (referenceframe.Model, error) {
+func makeGantryModel(cfg resource.Config, newConf *Config, logger logging.Logger) (referenceframe.Model, error) {
 	var (
 		model referenceframe.Model
 		err   error
@@ -40,7 +40,7 @@ func makeGantryModel(cfg resource.Config, newConf *Config) (referenceframe.Model
 
 	switch {
 	case modelPath != "":
-		model, err = referenceframe.KinematicModelFromFile(modelPath, cfg.Name)
+		model, err = referenceframe.KinematicModelFromFileWithLogger(modelPath, cfg.Name, logger)
 	default:
 		// if no gantry model is specified, we return a default one.
 		model, err = referenceframe.UnmarshalModelJSON(gantryModelJSON, cfg.Name)
@@ -76,7 +76,7 @@ func NewGantry(conf resource.Config, logger logging.Logger) (gantry.Gantry, erro
 		return nil, err
 	}
 
-	m, err := makeGantryModel(conf, newConf)
+	m, err := makeGantryModel(conf, newConf, logger)
 	if err != nil {
 		return nil, err
 	}
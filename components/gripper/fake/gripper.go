@@ -80,7 +80,7 @@ func (g *Gripper) Reconfigure(_ context.Context, _ resource.Dependencies, conf r
 	}
 
 	if newConf.ModelFilePath != "" {
-		model, err := referenceframe.KinematicModelFromFile(newConf.ModelFilePath, g.Name().ShortName())
+		model, err := referenceframe.KinematicModelFromFileWithLogger(newConf.ModelFilePath, g.Name().ShortName(), g.logger)
 		if err != nil {
 			return err
 		}
@@ -18,7 +18,10 @@ import (
 	rdkutils "go.viam.com/rdk/utils"
 )
 
-const getPID = "get_tuned_pid"
+const (
+	getPID   = "get_tuned_pid"
+	autotune = "autotune"
+)
 
 // SetState sets the state of the motor for the built-in control loop.
 func (cm *controlledMotor) SetState(ctx context.Context, state []*control.Signal) error {
@@ -61,10 +64,17 @@ func (cm *controlledMotor) setupControlLoop(conf *Config) error {
 
 	// convert the motor config ControlParameters to the control.PIDConfig structure for use in setup_control.go
 	cm.configPIDVals = []control.PIDConfig{{
-		Type: "",
-		P:    conf.ControlParameters.P,
-		I:    conf.ControlParameters.I,
-		D:    conf.ControlParameters.D,
+		Type:                "",
+		P:                   conf.ControlParameters.P,
+		I:                   conf.ControlParameters.I,
+		D:                   conf.ControlParameters.D,
+		KS:                  conf.ControlParameters.KS,
+		KV:                  conf.ControlParameters.KV,
+		KA:                  conf.ControlParameters.KA,
+		MaxRate:             conf.ControlParameters.MaxRate,
+		AntiWindup:          conf.ControlParameters.AntiWindup,
+		BackCalculationGain: conf.ControlParameters.BackCalculationGain,
+		GainSchedule:        conf.ControlParameters.GainSchedule,
 	}}
 
 	// auto tune motor if all ControlParameters are 0
@@ -396,10 +406,17 @@ func (cm *controlledMotor) GoFor(ctx context.Context, rpm, revolutions float64,
 func (cm *controlledMotor) DoCommand(ctx context.Context, req map[string]interface{}) (map[string]interface{}, error) {
 	resp := make(map[string]interface{})
 
+	if tuneReq, ok := req[autotune]; ok {
+		result, err := cm.autotune(ctx, tuneReq)
+		if err != nil {
+			return nil, err
+		}
+		resp[autotune] = result.Map()
+	}
+
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
-	ok := req[getPID].(bool)
-	if ok {
+	if ok, _ := req[getPID].(bool); ok {
 		var respStr string
 		if !(*cm.tunedVals)[0].NeedsAutoTuning() {
 			respStr += (*cm.tunedVals)[0].String()
@@ -410,6 +427,45 @@ func (cm *controlledMotor) DoCommand(ctx context.Context, req map[string]interfa
 	return resp, nil
 }
 
+// autotune runs the autotuning experiment configured by the request against the velocity of the motor, and returns the
+// identified model and proposed gains without applying them.
+func (cm *controlledMotor) autotune(ctx context.Context, req interface{}) (control.TuningResult, error) {
+	attrs, ok := req.(map[string]interface{})
+	if !ok {
+		return control.TuningResult{}, errors.Errorf("%s expects the tuner config, got %v", autotune, req)
+	}
+	cfg, err := resource.TransformAttributeMap[control.TunerConfig](attrs)
+	if err != nil {
+		return control.TuningResult{}, err
+	}
+	// the state of the motor is its position, whereas the control loop controls its velocity
+	cfg.Derivative = true
+
+	if cm.loop != nil && cm.loop.GetTuning(ctx) {
+		return control.TuningResult{}, control.TuningInProgressErr(cm.Name().ShortName())
+	}
+	cm.opMgr.CancelRunning(ctx)
+	ctx, done := cm.opMgr.New(ctx)
+	defer done()
+	if cm.loop != nil {
+		cm.loop.Pause()
+	}
+	return control.Autotune(ctx, &motorTuningTarget{cm}, cfg)
+}
+
+// motorTuningTarget drives the motor directly during autotuning, bypassing its paused control loop.
+type motorTuningTarget struct {
+	cm *controlledMotor
+}
+
+func (t *motorTuningTarget) SetState(ctx context.Context, state []*control.Signal) error {
+	return t.cm.real.SetPower(ctx, state[0].GetSignalValueAt(0), nil)
+}
+
+func (t *motorTuningTarget) State(ctx context.Context) ([]float64, error) {
+	return t.cm.State(ctx)
+}
+
 // if loop is tuning, return an error
 // if loop has been tuned but the values haven't been added to the config, error with tuned values.
 func (cm *controlledMotor) checkTuningStatus() error {
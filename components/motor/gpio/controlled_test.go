@@ -2,7 +2,9 @@ package gpio
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"go.viam.com/test"
 	"go.viam.com/utils/testutils"
@@ -13,6 +15,7 @@ import (
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
 )
 
 func TestEncodedMotorControls(t *testing.T) {
@@ -119,3 +122,75 @@ func TestControlledMotorCreation(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, resp, test.ShouldResemble, emptyMap)
 }
+
+func TestControlledMotorAutotune(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	// the velocity of the motor follows 1000 ticks per second per unit of power with a time constant of 50ms
+	var mu sync.Mutex
+	var power, velocity, position float64
+	var last time.Time
+	fakeMotor := inject.NewMotor(motorName)
+	fakeMotor.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		power = powerPct
+		return nil
+	}
+	fakeMotor.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		return fakeMotor.SetPowerFunc(ctx, 0, extra)
+	}
+	enc := inject.NewEncoder(encoderName)
+	enc.PositionFunc = func(ctx context.Context,
+		positionType encoder.PositionType,
+		extra map[string]interface{},
+	) (float64, encoder.PositionType, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		if !last.IsZero() {
+			dt := now.Sub(last).Seconds()
+			velocity += dt * (1000*power - velocity) / 0.05
+			position += dt * velocity
+		}
+		last = now
+		return position, encoder.PositionTypeTicks, nil
+	}
+
+	conf := resource.Config{
+		Name: motorName,
+		ConvertedAttributes: &Config{
+			Encoder:           encoderName,
+			TicksPerRotation:  1,
+			ControlParameters: &motorPIDConfig{P: 1, I: 2},
+		},
+	}
+	m, err := setupMotorWithControls(context.Background(), fakeMotor, enc, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, m.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	resp, err := m.DoCommand(context.Background(), map[string]interface{}{
+		"autotune": map[string]interface{}{"method": "fopdt", "frequency": 200.0, "duration_sec": 5.0},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	result, ok := resp["autotune"].(map[string]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, result["method"], test.ShouldEqual, "fopdt")
+	model := result["model"].(map[string]interface{})
+	test.That(t, model["gain"], test.ShouldAlmostEqual, 1000*0.00392157, 0.5)
+	gains := result["gains"].(map[string]interface{})
+	test.That(t, gains["p"], test.ShouldBeGreaterThan, 0)
+	test.That(t, gains["i"], test.ShouldBeGreaterThan, 0)
+
+	// the motor is stopped once the experiment is done, and the gains are not applied
+	mu.Lock()
+	test.That(t, power, test.ShouldEqual, 0)
+	mu.Unlock()
+	cm := m.(*controlledMotor)
+	test.That(t, cm.configPIDVals[0], test.ShouldResemble, control.PIDConfig{P: 1, I: 2})
+
+	_, err = m.DoCommand(context.Background(), map[string]interface{}{"autotune": map[string]interface{}{"method": "guess"}})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -8,6 +8,7 @@ import (
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/components/encoder"
 	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/control"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 )
@@ -109,6 +110,15 @@ type motorPIDConfig struct {
 	P float64 `json:"p"`
 	I float64 `json:"i"`
 	D float64 `json:"d"`
+
+	// the feedforward, rate limit, anti-windup and gain schedule of the control loop, as in control.PIDConfig
+	KS                  float64                     `json:"ks,omitempty"`
+	KV                  float64                     `json:"kv,omitempty"`
+	KA                  float64                     `json:"ka,omitempty"`
+	MaxRate             float64                     `json:"max_rate,omitempty"`
+	AntiWindup          string                      `json:"anti_windup,omitempty"`
+	BackCalculationGain float64                     `json:"back_calculation_gain,omitempty"`
+	GainSchedule        []control.GainSchedulePoint `json:"gain_schedule,omitempty"`
 }
 
 // Config describes the configuration of a motor.
@@ -0,0 +1,131 @@
+// Package fiducial implements a pose tracker that reports the poses of AprilTag and ArUco fiducial markers
+// seen by a camera.
+package fiducial
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/posetracker"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+// Model is the model of the fiducial marker pose tracker.
+var Model = resource.DefaultModelFamily.WithModel("fiducial")
+
+func init() {
+	resource.RegisterComponent(posetracker.API, Model, resource.Registration[posetracker.PoseTracker, *Config]{
+		Constructor: NewPoseTracker,
+	})
+}
+
+// Config specifies the camera to find markers with, and the size of the markers.
+type Config struct {
+	CameraName string `json:"camera_name"`
+	// TagSizeMM is the length of the sides of the black border of the markers, in mm.
+	TagSizeMM float64 `json:"tag_size_mm"`
+	// Families are the marker families to detect. All built in and loaded families are detected if none are
+	// given. Of the built in tag36h11 family, only tags 0 to 30 are detected, so the whole family must be loaded
+	// to detect tags with higher IDs.
+	Families []string `json:"families,omitempty"`
+	// FamilyFiles are files of marker families to load, such as the tag36h11.c source of the AprilTag library or
+	// a dictionary written by OpenCV, as read by fiducial.LoadFamily.
+	FamilyFiles []string `json:"family_files,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and returns the camera as a dependency.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.CameraName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "camera_name")
+	}
+	if conf.TagSizeMM <= 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("tag_size_mm must be positive"))
+	}
+	if _, err := fiducial.ConfiguredFamilies(conf.Families, conf.FamilyFiles); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+	return []string{conf.CameraName}, nil, nil
+}
+
+type poseTracker struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	logger     logging.Logger
+	cam        camera.Camera
+	cameraName string
+	tagSize    float64
+	families   []*fiducial.Family
+}
+
+// NewPoseTracker returns a pose tracker that reports the pose of each marker in the image of a camera, in the
+// frame of that camera. Markers are named by their family and ID, such as tag36h11_3.
+func NewPoseTracker(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (posetracker.PoseTracker, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	families, err := fiducial.ConfiguredFamilies(newConf.Families, newConf.FamilyFiles)
+	if err != nil {
+		return nil, err
+	}
+	cam, err := camera.FromProvider(deps, newConf.CameraName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find camera %q", newConf.CameraName)
+	}
+	return &poseTracker{
+		Named:      conf.ResourceName().AsNamed(),
+		logger:     logger,
+		cam:        cam,
+		cameraName: newConf.CameraName,
+		tagSize:    newConf.TagSizeMM,
+		families:   families,
+	}, nil
+}
+
+// Poses returns the poses of the markers in the current image of the camera. If bodyNames is not empty, only
+// the poses of the named markers are returned.
+func (pt *poseTracker) Poses(
+	ctx context.Context, bodyNames []string, extra map[string]interface{},
+) (referenceframe.FrameSystemPoses, error) {
+	props, err := pt.cam.Properties(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get the properties of camera %q", pt.cameraName)
+	}
+	img, err := camera.DecodeImageFromCamera(ctx, pt.cam, nil, extra)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(bodyNames))
+	for _, name := range bodyNames {
+		wanted[name] = true
+	}
+	poses := referenceframe.FrameSystemPoses{}
+	for _, marker := range fiducial.Detect(img, pt.families) {
+		name := marker.Name()
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		if _, ok := poses[name]; ok {
+			pt.logger.CDebugf(ctx, "marker %s seen more than once, using the first", name)
+			continue
+		}
+		pose, err := fiducial.EstimatePose(marker, pt.tagSize, props.IntrinsicParams)
+		if err != nil {
+			return nil, err
+		}
+		poses[name] = referenceframe.NewPoseInFrame(pt.cameraName, pose)
+	}
+	return poses, nil
+}
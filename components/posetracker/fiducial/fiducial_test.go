@@ -0,0 +1,111 @@
+package fiducial
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/posetracker"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+func TestConfigValidate(t *testing.T) {
+	_, _, err := (&Config{TagSizeMM: 50}).Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "camera_name"))
+
+	_, _, err = (&Config{CameraName: "cam"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "tag_size_mm")
+
+	_, _, err = (&Config{CameraName: "cam", TagSizeMM: 50, Families: []string{"tag16h5"}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = (&Config{CameraName: "cam", TagSizeMM: 50, FamilyFiles: []string{filepath.Join(t.TempDir(), "DICT_4X4_50.yml")}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	deps, _, err := (&Config{CameraName: "cam", TagSizeMM: 50, Families: []string{"aruco_original"}}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam"})
+}
+
+func TestPoses(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 600, Fy: 600, Ppx: 320, Ppy: 240}
+
+	// two markers facing the camera, whose borders are 160 pixels wide, so 80mm markers are 300mm away
+	img := image.NewGray(image.Rect(0, 0, intrinsics.Width, intrinsics.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 180}), image.Point{}, draw.Src)
+	tag, err := fiducial.Render(fiducial.AprilTag36h11, 2, 20)
+	test.That(t, err, test.ShouldBeNil)
+	draw.Draw(img, tag.Bounds().Add(image.Pt(20, 40)), tag, image.Point{}, draw.Src)
+	aruco, err := fiducial.Render(fiducial.ArucoOriginal, 500, 160/7)
+	test.That(t, err, test.ShouldBeNil)
+	draw.Draw(img, aruco.Bounds().Add(image.Pt(400, 200)), aruco, image.Point{}, draw.Src)
+
+	cam := inject.NewCamera("cam")
+	cam.ImagesFunc = func(
+		ctx context.Context, filterSourceNames []string, extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		namedImg, err := camera.NamedImageFromImage(img, "color", utils.MimeTypePNG, data.Annotations{})
+		return []camera.NamedImage{namedImg}, resource.ResponseMetadata{}, err
+	}
+	cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		return camera.Properties{IntrinsicParams: intrinsics}, nil
+	}
+	deps := resource.Dependencies{camera.Named("cam"): cam}
+	conf := resource.Config{
+		Name:                "tags",
+		API:                 posetracker.API,
+		ConvertedAttributes: &Config{CameraName: "cam", TagSizeMM: 80, Families: []string{"tag36h11"}},
+	}
+	pt, err := NewPoseTracker(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	poses, err := pt.Poses(ctx, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, poses, test.ShouldHaveLength, 1)
+	pose := poses["tag36h11_2"]
+	test.That(t, pose, test.ShouldNotBeNil)
+	test.That(t, pose.Parent(), test.ShouldEqual, "cam")
+	// the center of the marker is at pixel (120, 140)
+	expected := r3.Vector{X: (120 - 320) * 300. / 600, Y: (140 - 240) * 300. / 600, Z: 300}
+	test.That(t, pose.Pose().Point().Sub(expected).Norm(), test.ShouldBeLessThan, 3)
+	test.That(t, spatialmath.OrientationAlmostEqualEps(pose.Pose().Orientation(), spatialmath.NewZeroOrientation(), 0.05),
+		test.ShouldBeTrue)
+
+	// the aruco marker is smaller, but the pose tracker assumes every marker is the configured size
+	conf.ConvertedAttributes = &Config{CameraName: "cam", TagSizeMM: 80}
+	pt, err = NewPoseTracker(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	poses, err = pt.Poses(ctx, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, poses, test.ShouldHaveLength, 2)
+	test.That(t, poses["aruco_original_500"], test.ShouldNotBeNil)
+
+	poses, err = pt.Poses(ctx, []string{"aruco_original_500", "tag36h11_9"}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, poses, test.ShouldHaveLength, 1)
+	test.That(t, poses["aruco_original_500"], test.ShouldNotBeNil)
+
+	cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		return camera.Properties{}, nil
+	}
+	_, err = pt.Poses(ctx, nil, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "intrinsics")
+}
@@ -0,0 +1,8 @@
+// Package register registers all relevant pose trackers and also API specific functions
+package register
+
+import (
+	// for pose trackers.
+	_ "go.viam.com/rdk/components/posetracker"
+	_ "go.viam.com/rdk/components/posetracker/fiducial"
+)
@@ -182,6 +182,19 @@ func (c *Config) Ensure(fromCloud bool, logger logging.Logger) error {
 		}
 		seenJobs[c.Jobs[idx].Name] = struct{}{}
 	}
+	for idx := range len(c.Jobs) {
+		for _, dep := range c.Jobs[idx].After {
+			if _, exists := seenJobs[dep.Job]; !exists {
+				logger.Errorf("Job %s runs after job %s which is not in the robot config", c.Jobs[idx].Name, dep.Job)
+			}
+		}
+	}
+	// Jobs which run after each other in a cycle would trigger each other forever, so the job manager does not
+	// trigger them.
+	if inCycles := JobsInDependencyCycles(c.Jobs); len(inCycles) > 0 {
+		logger.Errorf("Jobs %s run after each other in a cycle and will not be triggered by the jobs they run after",
+			strings.Join(inCycles, ", "))
+	}
 	seenModules := make(map[string]struct{})
 	for idx := range len(c.Modules) {
 		if _, exists := seenModules[c.Modules[idx].Name]; exists {
@@ -1286,8 +1299,86 @@ type JobConfigData struct {
 	Method           string              `json:"method"`
 	Command          map[string]any      `json:"command,omitempty"`
 	LogConfiguration *resource.LogConfig `json:"log_configuration,omitempty"`
+	// Timeout is a Golang duration string bounding how long each attempt of the job may run for.
+	Timeout string `json:"timeout,omitempty"`
+	// MaxRetries is how many times a failed attempt of the job is retried before the run is considered failed.
+	MaxRetries int `json:"max_retries,omitempty"`
+	// RetryBackoff is a Golang duration string for how long to wait before the first retry, which doubles with
+	// every subsequent retry. Defaults to DefaultJobRetryBackoff.
+	RetryBackoff string `json:"retry_backoff,omitempty"`
+	// OverlapPolicy decides what happens when the job is due while a previous run is still in progress.
+	OverlapPolicy JobOverlapPolicy `json:"overlap_policy,omitempty"`
+	// After lists the jobs whose runs trigger a run of this job. A job with dependencies does not need a schedule.
+	After []JobDependency `json:"after,omitempty"`
+	// Condition is checked before every run of the job, which is skipped unless the condition is met.
+	Condition *JobCondition `json:"condition,omitempty"`
+}
+
+// JobTrigger is the outcome of a job run which triggers the jobs that depend on it.
+type JobTrigger string
+
+const (
+	// JobTriggerSuccess triggers dependent jobs when the job succeeds.
+	JobTriggerSuccess JobTrigger = "success"
+	// JobTriggerFailure triggers dependent jobs when the job fails.
+	JobTriggerFailure JobTrigger = "failure"
+	// JobTriggerAny triggers dependent jobs whenever the job finishes.
+	JobTriggerAny JobTrigger = "any"
+)
+
+// JobDependency describes a job whose runs trigger another job.
+type JobDependency struct {
+	Job string `json:"job"`
+	// On is the outcome of Job which triggers the dependent job. Defaults to JobTriggerSuccess.
+	On JobTrigger `json:"on,omitempty"`
 }
 
+// Triggers returns whether a run of the job which succeeded, or not, triggers the dependent job.
+func (jd JobDependency) Triggers(succeeded bool) bool {
+	switch jd.On {
+	case JobTriggerAny:
+		return true
+	case JobTriggerFailure:
+		return !succeeded
+	default:
+		return succeeded
+	}
+}
+
+// JobCondition compares a field of the readings of a resource against a threshold.
+type JobCondition struct {
+	Resource string `json:"resource"`
+	// Field is the key of the reading to compare. Nested readings are separated by dots, like "position.x".
+	Field string `json:"field"`
+	// Operator is one of ">", ">=", "<", "<=", "==" or "!=".
+	Operator string  `json:"operator"`
+	Value    float64 `json:"value"`
+	// Crossing only meets the condition when the comparison becomes true, rather than whenever it is true, so a job
+	// runs once each time the reading crosses the threshold.
+	Crossing bool `json:"crossing,omitempty"`
+}
+
+// Compare returns whether the reading satisfies the comparison of the condition.
+func (jc *JobCondition) Compare(reading float64) bool {
+	met, err := rutils.CompareReading(reading, jc.Operator, jc.Value)
+	return err == nil && met
+}
+
+// JobOverlapPolicy decides what happens when a job is due while a previous run of it is still in progress.
+type JobOverlapPolicy string
+
+const (
+	// JobOverlapPolicySkip skips the run which is due.
+	JobOverlapPolicySkip JobOverlapPolicy = "skip"
+	// JobOverlapPolicyQueue starts the run which is due once the previous one has finished.
+	JobOverlapPolicyQueue JobOverlapPolicy = "queue"
+	// JobOverlapPolicyAllow starts the run which is due immediately, alongside the previous one.
+	JobOverlapPolicyAllow JobOverlapPolicy = "allow"
+)
+
+// DefaultJobRetryBackoff is how long to wait before retrying a failed job when no retry_backoff is configured.
+const DefaultJobRetryBackoff = time.Second
+
 // MarshalJSON marshals out this config.
 func (jc JobConfig) MarshalJSON() ([]byte, error) {
 	return json.Marshal(jc.JobConfigData)
@@ -1317,15 +1408,109 @@ func (jc *JobConfig) Validate(path string) error {
 	if jc.Resource == "" {
 		return resource.NewConfigValidationFieldRequiredError(path, "resource")
 	}
-	if jc.Schedule == "" {
+	if jc.Schedule == "" && len(jc.After) == 0 {
 		return resource.NewConfigValidationFieldRequiredError(path, "schedule")
 	}
+	for idx, dep := range jc.After {
+		depPath := fmt.Sprintf("%s.after.%d", path, idx)
+		if dep.Job == "" {
+			return resource.NewConfigValidationFieldRequiredError(depPath, "job")
+		}
+		if dep.Job == jc.Name {
+			return resource.NewConfigValidationError(depPath, errors.New("a job cannot run after itself"))
+		}
+		switch dep.On {
+		case "", JobTriggerSuccess, JobTriggerFailure, JobTriggerAny:
+		default:
+			return resource.NewConfigValidationError(depPath, errors.Errorf("unknown trigger %q", dep.On))
+		}
+	}
+	if jc.Condition != nil {
+		condPath := path + ".condition"
+		if jc.Condition.Resource == "" {
+			return resource.NewConfigValidationFieldRequiredError(condPath, "resource")
+		}
+		if jc.Condition.Field == "" {
+			return resource.NewConfigValidationFieldRequiredError(condPath, "field")
+		}
+		if !slices.Contains(rutils.ComparisonOperators, jc.Condition.Operator) {
+			return resource.NewConfigValidationError(condPath, errors.Errorf("unknown operator %q", jc.Condition.Operator))
+		}
+	}
+	if jc.Timeout != "" {
+		if timeout, err := time.ParseDuration(jc.Timeout); err != nil || timeout <= 0 {
+			return resource.NewConfigValidationError(path, errors.Errorf("timeout %q must be a positive duration", jc.Timeout))
+		}
+	}
+	if jc.MaxRetries < 0 {
+		return resource.NewConfigValidationError(path, errors.New("max_retries must be non-negative"))
+	}
+	if jc.RetryBackoff != "" {
+		if backoff, err := time.ParseDuration(jc.RetryBackoff); err != nil || backoff < 0 {
+			return resource.NewConfigValidationError(path,
+				errors.Errorf("retry_backoff %q must be a non-negative duration", jc.RetryBackoff))
+		}
+	}
+	switch jc.OverlapPolicy {
+	case "", JobOverlapPolicySkip, JobOverlapPolicyQueue, JobOverlapPolicyAllow:
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown overlap_policy %q", jc.OverlapPolicy))
+	}
 	// At this point, the schedule could still be invalid (not a golang duration string or a
 	// cron expression). Such errors will be caught later, when the job manager will try to
 	// schedule the job and parse this field. The error will be displayed to the user.
 	return nil
 }
 
+// JobsInDependencyCycles returns the sorted names of the jobs which run after themselves through the jobs they run after.
+// Dependencies on jobs which are not in the list are ignored.
+func JobsInDependencyCycles(jobs []JobConfig) []string {
+	after := make(map[string][]string, len(jobs))
+	for _, jc := range jobs {
+		for _, dep := range jc.After {
+			after[jc.Name] = append(after[jc.Name], dep.Job)
+		}
+	}
+
+	var inCycles []string
+	for name := range after {
+		seen := map[string]bool{}
+		stack := slices.Clone(after[name])
+		for len(stack) > 0 {
+			dep := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if dep == name {
+				inCycles = append(inCycles, name)
+				break
+			}
+			if !seen[dep] {
+				seen[dep] = true
+				stack = append(stack, after[dep]...)
+			}
+		}
+	}
+	slices.Sort(inCycles)
+	return inCycles
+}
+
+// TimeoutDuration returns the parsed Timeout, or 0 if the job has no timeout.
+func (jc *JobConfig) TimeoutDuration() time.Duration {
+	timeout, err := time.ParseDuration(jc.Timeout)
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+// RetryBackoffDuration returns the parsed RetryBackoff, or DefaultJobRetryBackoff if it is unset.
+func (jc *JobConfig) RetryBackoffDuration() time.Duration {
+	backoff, err := time.ParseDuration(jc.RetryBackoff)
+	if err != nil {
+		return DefaultJobRetryBackoff
+	}
+	return backoff
+}
+
 // Equals checks if the two configs are deeply equal to each other.
 func (jc JobConfig) Equals(other JobConfig) bool {
 	return reflect.DeepEqual(jc, other)
@@ -866,6 +866,33 @@ func TestValidateUniqueNames(t *testing.T) {
 	}
 }
 
+func TestJobDependencyCycle(t *testing.T) {
+	job := func(name string, after ...string) config.JobConfig {
+		jc := config.JobConfig{config.JobConfigData{Name: name, Resource: "sensor", Method: "GetReadings"}}
+		for _, dep := range after {
+			jc.After = append(jc.After, config.JobDependency{Job: dep})
+		}
+		if len(after) == 0 {
+			jc.Schedule = "1s"
+		}
+		return jc
+	}
+	logger, logs := logging.NewObservedTestLogger(t)
+
+	chain := config.Config{Jobs: []config.JobConfig{job("a"), job("b", "a"), job("c", "a", "b")}}
+	test.That(t, config.JobsInDependencyCycles(chain.Jobs), test.ShouldBeEmpty)
+	test.That(t, chain.Ensure(false, logger), test.ShouldBeNil)
+	test.That(t, logs.FilterMessageSnippet("cycle").Len(), test.ShouldEqual, 0)
+
+	// a cycle only affects the jobs in it, and does not fail the rest of the config
+	cycle := config.Config{Jobs: []config.JobConfig{
+		job("start"), job("b", "a"), job("a", "start", "b"), job("c", "a"), job("d", "d"),
+	}}
+	test.That(t, config.JobsInDependencyCycles(cycle.Jobs), test.ShouldResemble, []string{"a", "b", "d"})
+	test.That(t, cycle.Ensure(false, logger), test.ShouldBeNil)
+	test.That(t, logs.FilterMessageSnippet("Jobs a, b, d run after each other in a cycle").Len(), test.ShouldEqual, 1)
+}
+
 func keysetToAttributeMap(t *testing.T, keyset jwks.KeySet) rutils.AttributeMap {
 	t.Helper()
 
@@ -1373,3 +1400,73 @@ func TestConfigJSONMarshalRoundtrip(t *testing.T) {
 		})
 	}
 }
+
+func TestJobConfigValidate(t *testing.T) {
+	valid := config.JobConfigData{
+		Name:          "job",
+		Schedule:      "1s",
+		Resource:      "sensor",
+		Method:        "GetReadings",
+		Timeout:       "500ms",
+		MaxRetries:    3,
+		RetryBackoff:  "100ms",
+		OverlapPolicy: config.JobOverlapPolicyAllow,
+	}
+	jc := config.JobConfig{valid}
+	test.That(t, jc.Validate("jobs.0"), test.ShouldBeNil)
+	test.That(t, jc.TimeoutDuration(), test.ShouldEqual, 500*time.Millisecond)
+	test.That(t, jc.RetryBackoffDuration(), test.ShouldEqual, 100*time.Millisecond)
+
+	unset := config.JobConfig{config.JobConfigData{Name: "job", Schedule: "1s", Resource: "sensor", Method: "GetReadings"}}
+	test.That(t, unset.Validate("jobs.0"), test.ShouldBeNil)
+	test.That(t, unset.TimeoutDuration(), test.ShouldEqual, time.Duration(0))
+	test.That(t, unset.RetryBackoffDuration(), test.ShouldEqual, config.DefaultJobRetryBackoff)
+
+	dependent := config.JobConfig{config.JobConfigData{
+		Name:      "dependent",
+		Resource:  "sensor",
+		Method:    "GetReadings",
+		After:     []config.JobDependency{{Job: "job"}, {Job: "other", On: config.JobTriggerFailure}},
+		Condition: &config.JobCondition{Resource: "sensor", Field: "battery.level", Operator: "<", Value: 20, Crossing: true},
+	}}
+	test.That(t, dependent.Validate("jobs.1"), test.ShouldBeNil)
+	test.That(t, dependent.After[0].Triggers(true), test.ShouldBeTrue)
+	test.That(t, dependent.After[0].Triggers(false), test.ShouldBeFalse)
+	test.That(t, dependent.After[1].Triggers(true), test.ShouldBeFalse)
+	test.That(t, dependent.After[1].Triggers(false), test.ShouldBeTrue)
+	test.That(t, config.JobDependency{Job: "job", On: config.JobTriggerAny}.Triggers(false), test.ShouldBeTrue)
+	test.That(t, dependent.Condition.Compare(19), test.ShouldBeTrue)
+	test.That(t, dependent.Condition.Compare(20), test.ShouldBeFalse)
+
+	for _, tc := range []struct {
+		description string
+		modify      func(jc *config.JobConfigData)
+		expectedErr string
+	}{
+		{"unparsable timeout", func(jc *config.JobConfigData) { jc.Timeout = "soon" }, "timeout"},
+		{"non-positive timeout", func(jc *config.JobConfigData) { jc.Timeout = "0s" }, "timeout"},
+		{"negative max_retries", func(jc *config.JobConfigData) { jc.MaxRetries = -1 }, "max_retries"},
+		{"negative retry_backoff", func(jc *config.JobConfigData) { jc.RetryBackoff = "-1s" }, "retry_backoff"},
+		{"unknown overlap_policy", func(jc *config.JobConfigData) { jc.OverlapPolicy = "sometimes" }, "overlap_policy"},
+		{"after itself", func(jc *config.JobConfigData) { jc.After = []config.JobDependency{{Job: "job"}} }, "itself"},
+		{"after without job", func(jc *config.JobConfigData) { jc.After = []config.JobDependency{{On: config.JobTriggerAny}} }, "job"},
+		{"unknown trigger", func(jc *config.JobConfigData) {
+			jc.After = []config.JobDependency{{Job: "other", On: "sometimes"}}
+		}, "trigger"},
+		{"condition without field", func(jc *config.JobConfigData) {
+			jc.Condition = &config.JobCondition{Resource: "sensor", Operator: ">"}
+		}, "field"},
+		{"unknown condition operator", func(jc *config.JobConfigData) {
+			jc.Condition = &config.JobCondition{Resource: "sensor", Field: "temp", Operator: "=>"}
+		}, "operator"},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			data := valid
+			tc.modify(&data)
+			jc := config.JobConfig{data}
+			err := jc.Validate("jobs.0")
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,622 @@
+package control
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// TuneMethod is a method of identifying the plant of a control loop and proposing gains for it.
+type TuneMethod string
+
+const (
+	// TuneMethodRelay makes the plant oscillate under relay feedback to find its ultimate gain and period
+	// (Åström–Hägglund), which the tuning rule turns into gains.
+	TuneMethodRelay TuneMethod = "relay"
+	// TuneMethodStepResponse finds the dead time and time constant of the plant from the tangent at the steepest point of
+	// its response to a step, and proposes Ziegler–Nichols or Cohen–Coon gains for them.
+	TuneMethodStepResponse TuneMethod = "step_response"
+	// TuneMethodFOPDT fits a first order plus dead time model to the response of the plant to a step, and proposes
+	// SIMC gains for it.
+	TuneMethodFOPDT TuneMethod = "fopdt"
+)
+
+var (
+	// ultimateRules are the tuning rules which propose gains from the ultimate gain and period found by the relay method.
+	ultimateRules = []tuneCalcMethod{
+		tuneMethodZiegerNicholsPI,
+		tuneMethodZiegerNicholsPID,
+		tuneMethodZiegerNicholsPD,
+		tuneMethodZiegerNicholsSomeOvershoot,
+		tuneMethodZiegerNicholsNoOvershoot,
+		tuneMethodTyreusLuybenPI,
+		tuneMethodTyreusLuybenPID,
+	}
+	// reactionCurveRules are the tuning rules which propose gains from the dead time and time constant found by the step
+	// response method.
+	reactionCurveRules = []tuneCalcMethod{
+		tuneMethodZiegerNicholsPI,
+		tuneMethodZiegerNicholsPID,
+		tuneMethodCohenCoonsPI,
+		tuneMethodCohenCoonsPID,
+	}
+)
+
+const (
+	defaultTuneFrequency = 50.0
+	defaultTuneDuration  = 10 * time.Second
+	defaultTuneStepPct   = 0.35
+	defaultTuneLimit     = 255.0
+	// defaultRelayHysteresisPct is the hysteresis of the relay as a fraction of the response to the initial step, which
+	// keeps noise from switching the relay.
+	defaultRelayHysteresisPct = 0.02
+	// relayCycles is the number of oscillations averaged to find the ultimate gain and period, after the first.
+	relayCycles = 4
+	// settledTolerance is how much the second half of a step response may vary, as a fraction of the total change, for
+	// the response to have settled.
+	settledTolerance  = 0.05
+	minSettledSamples = 20
+)
+
+// TunerConfig configures an autotuning experiment.
+type TunerConfig struct {
+	Method TuneMethod `json:"method"`
+	// Signal is the index of the state of the controllable which is tuned, such as 1 for the angular velocity of a base.
+	Signal int `json:"signal,omitempty"`
+	// Rule is the tuning rule, such as "ziegerNicholsPID", which turns the results of the relay and step response
+	// methods into gains. Defaults to the Ziegler–Nichols PI rule. The relay method takes the Ziegler–Nichols and
+	// Tyreus–Luyben rules, the step response method the Ziegler–Nichols PI and PID and the Cohen–Coon rules, and the
+	// FOPDT method takes no rule.
+	Rule string `json:"rule,omitempty"`
+	// StepPct is the size of the step applied to the plant as a fraction of the limit.
+	StepPct float64 `json:"step_pct,omitempty"`
+	// Limit is the largest output of the PID block being tuned.
+	Limit float64 `json:"limit,omitempty"`
+	// OutputGain scales the output of the tuner before it is applied to the controllable, like the gain block following
+	// the PID block of a control loop.
+	OutputGain float64 `json:"output_gain,omitempty"`
+	// Hysteresis is how far the state must cross the setpoint before the relay switches.
+	Hysteresis float64 `json:"hysteresis,omitempty"`
+	// Frequency is how often the state is sampled, in Hz.
+	Frequency float64 `json:"frequency,omitempty"`
+	// DurationSec is the longest the experiment may run for, in seconds.
+	DurationSec float64 `json:"duration_sec,omitempty"`
+	// Derivative tunes the rate of change of the state, such as the velocity of a motor whose state is its position.
+	Derivative bool `json:"derivative,omitempty"`
+}
+
+// Validate returns an error if the experiment cannot be run.
+func (cfg TunerConfig) Validate() error {
+	switch cfg.Method {
+	case TuneMethodRelay, TuneMethodStepResponse, TuneMethodFOPDT:
+	default:
+		return errors.Errorf("unknown tuning method %q", cfg.Method)
+	}
+	if cfg.Rule != "" {
+		rule := tuneCalcMethod(cfg.Rule)
+		if !slices.Contains(tuneCalcMethods, rule) {
+			return errors.Errorf("unknown tuning rule %q", cfg.Rule)
+		}
+		switch {
+		case cfg.Method == TuneMethodFOPDT:
+			return errors.Errorf("the %s method proposes SIMC gains and takes no tuning rule", cfg.Method)
+		case cfg.Method == TuneMethodRelay && !slices.Contains(ultimateRules, rule),
+			cfg.Method == TuneMethodStepResponse && !slices.Contains(reactionCurveRules, rule):
+			return errors.Errorf("the %s method cannot propose gains with the tuning rule %q", cfg.Method, cfg.Rule)
+		}
+	}
+	if cfg.Signal < 0 {
+		return errors.New("signal must be non-negative")
+	}
+	if cfg.StepPct < 0 || cfg.StepPct > 1 {
+		return errors.New("step_pct should be between 0-1")
+	}
+	if cfg.Limit < 0 || cfg.Hysteresis < 0 || cfg.Frequency < 0 || cfg.DurationSec < 0 {
+		return errors.New("limit, hysteresis, frequency and duration_sec must be non-negative")
+	}
+	return nil
+}
+
+// withDefaults returns the config with every unset value replaced by its default.
+func (cfg TunerConfig) withDefaults() TunerConfig {
+	if cfg.Rule == "" {
+		cfg.Rule = string(tuneMethodZiegerNicholsPI)
+	}
+	if cfg.StepPct == 0 {
+		cfg.StepPct = defaultTuneStepPct
+	}
+	if cfg.Limit == 0 {
+		cfg.Limit = defaultTuneLimit
+	}
+	if cfg.OutputGain == 0 {
+		cfg.OutputGain = rPiGain
+	}
+	if cfg.Frequency == 0 {
+		cfg.Frequency = defaultTuneFrequency
+	}
+	if cfg.DurationSec == 0 {
+		cfg.DurationSec = defaultTuneDuration.Seconds()
+	}
+	return cfg
+}
+
+func (cfg TunerConfig) duration() time.Duration {
+	return time.Duration(cfg.DurationSec * float64(time.Second))
+}
+
+// PlantModel is a model of a plant identified by an autotuning experiment. Values which the method does not identify
+// are zero.
+type PlantModel struct {
+	// Gain is the steady state change of the state per unit of output of the PID block.
+	Gain           float64
+	TimeConstant   time.Duration
+	DeadTime       time.Duration
+	UltimateGain   float64
+	UltimatePeriod time.Duration
+}
+
+// TuningResult is the outcome of an autotuning experiment. The gains are only proposed, they are not applied to any
+// control loop.
+type TuningResult struct {
+	Method TuneMethod
+	Model  PlantModel
+	Gains  PIDConfig
+}
+
+// Map returns the result in a form which can be returned from DoCommand.
+func (r TuningResult) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"method": string(r.Method),
+		"model": map[string]interface{}{
+			"gain":                r.Model.Gain,
+			"time_constant_sec":   r.Model.TimeConstant.Seconds(),
+			"dead_time_sec":       r.Model.DeadTime.Seconds(),
+			"ultimate_gain":       r.Model.UltimateGain,
+			"ultimate_period_sec": r.Model.UltimatePeriod.Seconds(),
+		},
+		"gains": map[string]interface{}{"p": r.Gains.P, "i": r.Gains.I, "d": r.Gains.D},
+	}
+}
+
+// Tuner runs an autotuning experiment one sample at a time.
+type Tuner interface {
+	// Next returns the output to apply to the plant given the latest measurement of its state, and whether the
+	// experiment is done.
+	Next(pv float64, t time.Time) (float64, bool)
+	// Result returns the identified model and the proposed gains once the experiment is done.
+	Result() (TuningResult, error)
+}
+
+// NewTuner returns a Tuner running the experiment of the configured method.
+func NewTuner(cfg TunerConfig) (Tuner, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg = cfg.withDefaults()
+	step := cfg.StepPct * cfg.Limit
+	switch cfg.Method {
+	case TuneMethodRelay:
+		return &relayTuner{cfg: cfg, settle: stepResponse{step: step, duration: cfg.duration()}}, nil
+	case TuneMethodStepResponse, TuneMethodFOPDT:
+		return &stepTuner{cfg: cfg, response: stepResponse{step: step, duration: cfg.duration()}}, nil
+	default:
+		return nil, errors.Errorf("unknown tuning method %q", cfg.Method)
+	}
+}
+
+// Autotune runs the configured experiment against the controllable and returns the identified model and proposed
+// gains. The controllable is stopped once the experiment is done.
+func Autotune(ctx context.Context, c Controllable, cfg TunerConfig) (TuningResult, error) {
+	tuner, err := NewTuner(cfg)
+	if err != nil {
+		return TuningResult{}, err
+	}
+	return RunTuner(ctx, c, tuner, cfg)
+}
+
+// RunTuner runs the experiment of the tuner against the signal of the controllable given in the config, sampling it at
+// the configured frequency. The controllable is stopped once the experiment is done.
+func RunTuner(ctx context.Context, c Controllable, tuner Tuner, cfg TunerConfig) (result TuningResult, err error) {
+	cfg = cfg.withDefaults()
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.Frequency))
+	defer ticker.Stop()
+
+	var numSignals int
+	setOutput := func(ctx context.Context, out float64) error {
+		state := make([]*Signal, numSignals)
+		for i := range state {
+			state[i] = makeSignal("tuner", blockPID)
+		}
+		state[cfg.Signal].SetSignalValueAt(0, out*cfg.OutputGain)
+		return c.SetState(ctx, state)
+	}
+	defer func() {
+		if numSignals > 0 {
+			err = multierr.Combine(err, setOutput(context.WithoutCancel(ctx), 0))
+		}
+	}()
+
+	var prevState float64
+	var prevT time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return TuningResult{}, ctx.Err()
+		case <-ticker.C:
+		}
+		state, err := c.State(ctx)
+		if err != nil {
+			return TuningResult{}, err
+		}
+		if cfg.Signal >= len(state) {
+			return TuningResult{}, errors.Errorf("cannot tune signal %d of a controllable with %d signals", cfg.Signal, len(state))
+		}
+		numSignals = len(state)
+
+		now := time.Now()
+		pv := state[cfg.Signal]
+		if cfg.Derivative {
+			if prevT.IsZero() {
+				prevState, prevT = pv, now
+				continue
+			}
+			pv, prevState, prevT = (pv-prevState)/now.Sub(prevT).Seconds(), pv, now
+		}
+
+		out, done := tuner.Next(pv, now)
+		if done {
+			return tuner.Result()
+		}
+		if err := setOutput(ctx, out); err != nil {
+			return TuningResult{}, err
+		}
+	}
+}
+
+// stepResponse records the response of a plant to a step of its input, starting from the first sample.
+type stepResponse struct {
+	step     float64
+	duration time.Duration
+
+	start time.Time
+	// seconds since the step
+	times  []float64
+	values []float64
+}
+
+// next records a sample and returns whether the response has settled, or an error if it has not settled in time.
+func (s *stepResponse) next(pv float64, t time.Time) (bool, error) {
+	if len(s.values) == 0 {
+		s.start = t
+	}
+	s.times = append(s.times, t.Sub(s.start).Seconds())
+	s.values = append(s.values, pv)
+	if s.settled() {
+		return true, nil
+	}
+	if t.Sub(s.start) > s.duration {
+		return false, errors.New("the response of the plant to a step did not settle")
+	}
+	return false, nil
+}
+
+// settled returns whether the second half of the response varies by less than the tolerance of the total change.
+func (s *stepResponse) settled() bool {
+	if len(s.values) < minSettledSamples {
+		return false
+	}
+	tail := s.values[len(s.values)/2:]
+	change := math.Abs(s.final() - s.initial())
+	return change > 0 && slices.Max(tail)-slices.Min(tail) <= settledTolerance*change
+}
+
+func (s *stepResponse) initial() float64 {
+	return s.values[0]
+}
+
+// final returns the average of the second half of the response.
+func (s *stepResponse) final() float64 {
+	tail := s.values[len(s.values)/2:]
+	var sum float64
+	for _, v := range tail {
+		sum += v
+	}
+	return sum / float64(len(tail))
+}
+
+func (s *stepResponse) gain() float64 {
+	return (s.final() - s.initial()) / s.step
+}
+
+// samplePeriod returns the average time between samples in seconds.
+func (s *stepResponse) samplePeriod() float64 {
+	return s.times[len(s.times)-1] / float64(len(s.times)-1)
+}
+
+// tangent returns the dead time and time constant in seconds found where the tangent at the steepest point of the
+// response crosses its initial and final values.
+func (s *stepResponse) tangent() (deadTime, timeConstant float64) {
+	change := s.final() - s.initial()
+	steepest, slope := 0, 0.0
+	// central differences over two samples either side smooth out some of the noise
+	for i := 2; i < len(s.values)-2; i++ {
+		if sl := (s.values[i+2] - s.values[i-2]) / (s.times[i+2] - s.times[i-2]); math.Abs(sl) > math.Abs(slope) &&
+			math.Signbit(sl) == math.Signbit(change) {
+			steepest, slope = i, sl
+		}
+	}
+	if slope == 0 {
+		return s.samplePeriod(), s.times[len(s.times)-1]
+	}
+	deadTime = s.times[steepest] - (s.values[steepest]-s.initial())/slope
+	return math.Max(deadTime, s.samplePeriod()), change / slope
+}
+
+// fitFOPDT returns the gain, and the dead time and time constant in seconds, of the first order plus dead time model
+// which best fits the response in the least squares sense. The gain is fit rather than taken from the final value, since
+// the response may not have quite settled.
+func (s *stepResponse) fitFOPDT() (gain, deadTime, timeConstant float64) {
+	y0 := s.initial()
+	// sse returns the error of the best fitting model with the dead time and time constant, and the gain of that model,
+	// which is linear in the shape of the response.
+	sse := func(deadTime, timeConstant float64) (float64, float64) {
+		var syg, sgg float64
+		shape := make([]float64, len(s.times))
+		for i, t := range s.times {
+			if t > deadTime {
+				shape[i] = 1 - math.Exp(-(t-deadTime)/timeConstant)
+			}
+			syg += (s.values[i] - y0) * shape[i]
+			sgg += shape[i] * shape[i]
+		}
+		if sgg == 0 {
+			return math.Inf(1), 0
+		}
+		k := syg / sgg
+		var sum float64
+		for i := range s.times {
+			sum += math.Pow(s.values[i]-y0-k*shape[i], 2)
+		}
+		return sum, k / s.step
+	}
+
+	bestErr := math.Inf(1)
+	end := s.times[len(s.times)-1]
+	for _, candidate := range s.times {
+		if candidate > end/2 {
+			break
+		}
+		// the error is unimodal in the log of the time constant, which is found by a golden section search
+		lo, hi := math.Log(s.samplePeriod()), math.Log(10*end)
+		for range 50 {
+			a, b := hi-(hi-lo)/math.Phi, lo+(hi-lo)/math.Phi
+			errA, _ := sse(candidate, math.Exp(a))
+			errB, _ := sse(candidate, math.Exp(b))
+			if errA < errB {
+				hi = b
+			} else {
+				lo = a
+			}
+		}
+		tc := math.Exp((lo + hi) / 2)
+		if err, k := sse(candidate, tc); err < bestErr {
+			bestErr, gain, deadTime, timeConstant = err, k, candidate, tc
+		}
+	}
+	return gain, math.Max(deadTime, s.samplePeriod()/2), timeConstant
+}
+
+// stepTuner identifies a first order plus dead time model of the plant from its response to a step.
+type stepTuner struct {
+	cfg      TunerConfig
+	response stepResponse
+	done     bool
+	err      error
+}
+
+func (st *stepTuner) Next(pv float64, t time.Time) (float64, bool) {
+	if st.done {
+		return 0, true
+	}
+	settled, err := st.response.next(pv, t)
+	if settled || err != nil {
+		st.done, st.err = true, err
+		return 0, true
+	}
+	return st.response.step, false
+}
+
+func (st *stepTuner) Result() (TuningResult, error) {
+	if !st.done {
+		return TuningResult{}, errors.New("tuning is still in progress")
+	}
+	if st.err != nil {
+		return TuningResult{}, st.err
+	}
+	k := st.response.gain()
+	if k == 0 {
+		return TuningResult{}, errors.New("the plant did not respond to a step")
+	}
+
+	result := TuningResult{Method: st.cfg.Method}
+	var deadTime, timeConstant float64
+	if st.cfg.Method == TuneMethodFOPDT {
+		k, deadTime, timeConstant = st.response.fitFOPDT()
+		if k == 0 || timeConstant <= 0 {
+			return TuningResult{}, errors.New("could not fit a first order plus dead time model to the response")
+		}
+		result.Gains = simcGains(k, deadTime, timeConstant)
+	} else {
+		deadTime, timeConstant = st.response.tangent()
+		result.Gains = reactionCurveGains(tuneCalcMethod(st.cfg.Rule), k, deadTime, timeConstant)
+	}
+	result.Model = PlantModel{
+		Gain:         k,
+		DeadTime:     time.Duration(deadTime * float64(time.Second)),
+		TimeConstant: time.Duration(timeConstant * float64(time.Second)),
+	}
+	return result, nil
+}
+
+// reactionCurveGains returns the gains proposed by the tuning rule, one of reactionCurveRules, for a plant with the gain
+// k, and the dead time and time constant in seconds.
+// references: https://en.wikipedia.org/wiki/Ziegler%E2%80%93Nichols_method and Cohen and Coon, "Theoretical
+// consideration of retarded control", 1953.
+func reactionCurveGains(rule tuneCalcMethod, k, deadTime, timeConstant float64) PIDConfig {
+	r := deadTime / timeConstant
+	switch rule {
+	case tuneMethodZiegerNicholsPID:
+		kP := 1.2 * timeConstant / (k * deadTime)
+		return PIDConfig{P: kP, I: kP / (2 * deadTime), D: kP * 0.5 * deadTime}
+	case tuneMethodCohenCoonsPI:
+		kP := (0.9 + r/12) / (k * r)
+		integralTime := deadTime * (30 + 3*r) / (9 + 20*r)
+		return PIDConfig{P: kP, I: kP / integralTime}
+	case tuneMethodCohenCoonsPID:
+		kP := (4.0/3.0 + r/4) / (k * r)
+		integralTime := deadTime * (32 + 6*r) / (13 + 8*r)
+		derivativeTime := deadTime * 4 / (11 + 2*r)
+		return PIDConfig{P: kP, I: kP / integralTime, D: kP * derivativeTime}
+	default: // ziegler nichols PI is the default
+		kP := 0.9 * timeConstant / (k * deadTime)
+		return PIDConfig{P: kP, I: kP / (deadTime / 0.3)}
+	}
+}
+
+// simcGains returns the SIMC PI gains for a plant with the gain k, and the dead time and time constant in seconds. The
+// desired closed loop time constant is the dead time, but no faster than a tenth of the time constant of the plant.
+// reference: Skogestad, "Simple analytic rules for model reduction and PID controller tuning", 2003.
+func simcGains(k, deadTime, timeConstant float64) PIDConfig {
+	tauC := math.Max(deadTime, timeConstant/10)
+	kP := timeConstant / (k * (tauC + deadTime))
+	integralTime := math.Min(timeConstant, 4*(tauC+deadTime))
+	return PIDConfig{P: kP, I: kP / integralTime}
+}
+
+// relayTuner settles the plant with a step and then switches the output between above and below that step whenever the
+// state crosses the value it settled at, which makes the plant oscillate at its ultimate period.
+type relayTuner struct {
+	cfg    TunerConfig
+	settle stepResponse
+
+	settled    bool
+	setpoint   float64
+	amplitude  float64
+	hysteresis float64
+	high       bool
+	start      time.Time
+	// the extreme state since the relay last switched
+	peak        float64
+	peaksHigh   []float64
+	peaksLow    []float64
+	switchDowns []time.Time
+
+	done   bool
+	err    error
+	result TuningResult
+}
+
+func (rt *relayTuner) Next(pv float64, t time.Time) (float64, bool) {
+	if rt.done {
+		return 0, true
+	}
+	if !rt.settled {
+		settled, err := rt.settle.next(pv, t)
+		if err != nil {
+			rt.done, rt.err = true, err
+			return 0, true
+		}
+		if !settled {
+			return rt.settle.step, false
+		}
+		rt.settled = true
+		rt.setpoint = rt.settle.final()
+		rt.amplitude = 0.5 * rt.settle.step
+		rt.hysteresis = rt.cfg.Hysteresis
+		if rt.hysteresis == 0 {
+			rt.hysteresis = defaultRelayHysteresisPct * math.Abs(rt.setpoint-rt.settle.initial())
+		}
+		rt.high, rt.peak, rt.start = true, pv, t
+		return rt.output(), false
+	}
+
+	if t.Sub(rt.start) > rt.cfg.duration() {
+		rt.done, rt.err = true, errors.New("the relay did not make the plant oscillate")
+		return 0, true
+	}
+	switch {
+	case rt.high && pv > rt.setpoint+rt.hysteresis:
+		// the lowest state while the output was high is only a peak of the oscillation once the relay has switched up
+		if len(rt.switchDowns) > 0 {
+			rt.peaksLow = append(rt.peaksLow, rt.peak)
+		}
+		rt.switchDowns = append(rt.switchDowns, t)
+		rt.high, rt.peak = false, pv
+	case !rt.high && pv < rt.setpoint-rt.hysteresis:
+		rt.peaksHigh = append(rt.peaksHigh, rt.peak)
+		rt.high, rt.peak = true, pv
+	case rt.high:
+		rt.peak = math.Min(rt.peak, pv)
+	default:
+		rt.peak = math.Max(rt.peak, pv)
+	}
+	// the first oscillation is skipped since the plant is still leaving the settled state
+	if len(rt.switchDowns) < relayCycles+2 {
+		return rt.output(), false
+	}
+	rt.done = true
+	rt.computeResult()
+	return 0, true
+}
+
+func (rt *relayTuner) output() float64 {
+	if rt.high {
+		return rt.settle.step + rt.amplitude
+	}
+	return rt.settle.step - rt.amplitude
+}
+
+// computeResult finds the ultimate gain and period from the oscillations using the describing function of the relay.
+func (rt *relayTuner) computeResult() {
+	cycles := rt.switchDowns[len(rt.switchDowns)-relayCycles-1:]
+	period := cycles[len(cycles)-1].Sub(cycles[0]) / time.Duration(relayCycles)
+
+	mean := func(vals []float64) float64 {
+		vals = vals[max(0, len(vals)-relayCycles):]
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		return sum / float64(len(vals))
+	}
+	a := (mean(rt.peaksHigh) - mean(rt.peaksLow)) / 2
+	if a <= 0 {
+		rt.err = errors.New("the relay did not make the plant oscillate")
+		return
+	}
+	if a > rt.hysteresis {
+		a = math.Sqrt(a*a - rt.hysteresis*rt.hysteresis)
+	}
+	kU := 4 * rt.amplitude / (math.Pi * a)
+
+	rt.result = TuningResult{
+		Method: TuneMethodRelay,
+		Model: PlantModel{
+			Gain:           rt.settle.gain(),
+			UltimateGain:   kU,
+			UltimatePeriod: period,
+		},
+		Gains: ultimateGains(tuneCalcMethod(rt.cfg.Rule), kU, period.Seconds()),
+	}
+}
+
+func (rt *relayTuner) Result() (TuningResult, error) {
+	if !rt.done {
+		return TuningResult{}, errors.New("tuning is still in progress")
+	}
+	return rt.result, rt.err
+}
@@ -0,0 +1,251 @@
+package control
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+// fopdtPlant simulates a first order plus dead time plant in discrete time.
+type fopdtPlant struct {
+	gain, timeConstant float64
+	// the inputs not yet seen by the plant because of its dead time
+	delayed []float64
+	state   float64
+}
+
+func newFOPDTPlant(gain, timeConstant float64, deadTime, dt time.Duration) *fopdtPlant {
+	return &fopdtPlant{gain: gain, timeConstant: timeConstant, delayed: make([]float64, int(deadTime/dt))}
+}
+
+func (p *fopdtPlant) step(u float64, dt time.Duration) float64 {
+	p.delayed = append(p.delayed, u)
+	delayedU := p.delayed[0]
+	p.delayed = p.delayed[1:]
+	p.state += dt.Seconds() * (p.gain*delayedU - p.state) / p.timeConstant
+	return p.state
+}
+
+// simulateTuner runs the tuner against the plant in simulated time and returns its result.
+func simulateTuner(t *testing.T, tuner Tuner, plant *fopdtPlant, dt time.Duration) (TuningResult, error) {
+	t.Helper()
+	now := time.Now()
+	var out float64
+	for i := 0; i < 100000; i++ {
+		pv := plant.step(out, dt)
+		var done bool
+		if out, done = tuner.Next(pv, now); done {
+			return tuner.Result()
+		}
+		now = now.Add(dt)
+	}
+	t.Fatal("tuning did not finish")
+	return TuningResult{}, nil
+}
+
+// closedLoopError returns the largest tracking error over the last second of following a setpoint with the gains.
+func closedLoopError(gains PIDConfig, plant *fopdtPlant, setpoint float64, dt time.Duration) float64 {
+	var integral, prevErr, out, worst float64
+	steps := int(10 * time.Second / dt)
+	for i := 0; i < steps; i++ {
+		pv := plant.step(out, dt)
+		err := setpoint - pv
+		integral += gains.I * err * dt.Seconds()
+		out = gains.P*err + integral + gains.D*(err-prevErr)/dt.Seconds()
+		prevErr = err
+		if i > steps-int(time.Second/dt) {
+			worst = math.Max(worst, math.Abs(err))
+		}
+	}
+	return worst
+}
+
+func TestTuners(t *testing.T) {
+	const (
+		gain         = 2.0
+		timeConstant = 0.5
+		deadTime     = 100 * time.Millisecond
+		dt           = 10 * time.Millisecond
+		setpoint     = 100.0
+	)
+
+	for _, method := range []TuneMethod{TuneMethodRelay, TuneMethodStepResponse, TuneMethodFOPDT} {
+		t.Run(string(method), func(t *testing.T) {
+			tuner, err := NewTuner(TunerConfig{Method: method})
+			test.That(t, err, test.ShouldBeNil)
+			_, err = tuner.Result()
+			test.That(t, err.Error(), test.ShouldContainSubstring, "in progress")
+
+			result, err := simulateTuner(t, tuner, newFOPDTPlant(gain, timeConstant, deadTime, dt), dt)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, result.Method, test.ShouldEqual, method)
+			test.That(t, result.Model.Gain, test.ShouldAlmostEqual, gain, 0.05)
+
+			switch method {
+			case TuneMethodRelay:
+				// the ultimate period of this plant is 0.37s and its ultimate gain is 4.2, which the describing function of
+				// the relay only approximates, in particular with the hysteresis of the relay
+				test.That(t, result.Model.UltimatePeriod.Seconds(), test.ShouldBeBetween, 0.35, 0.5)
+				test.That(t, result.Model.UltimateGain, test.ShouldBeBetween, 2.5, 4.5)
+			case TuneMethodStepResponse:
+				test.That(t, result.Model.DeadTime.Seconds(), test.ShouldAlmostEqual, deadTime.Seconds(), 0.03)
+				test.That(t, result.Model.TimeConstant.Seconds(), test.ShouldAlmostEqual, timeConstant, 0.05)
+			case TuneMethodFOPDT:
+				test.That(t, result.Model.DeadTime.Seconds(), test.ShouldAlmostEqual, deadTime.Seconds(), 0.02)
+				test.That(t, result.Model.TimeConstant.Seconds(), test.ShouldAlmostEqual, timeConstant, 0.03)
+			}
+
+			// the proposed gains make the plant follow a setpoint
+			test.That(t, result.Gains.P, test.ShouldBeGreaterThan, 0)
+			test.That(t, result.Gains.I, test.ShouldBeGreaterThan, 0)
+			test.That(t, closedLoopError(result.Gains, newFOPDTPlant(gain, timeConstant, deadTime, dt), setpoint, dt),
+				test.ShouldBeLessThan, 0.01*setpoint)
+		})
+	}
+
+	t.Run("a plant which does not respond fails", func(t *testing.T) {
+		tuner, err := NewTuner(TunerConfig{Method: TuneMethodFOPDT, DurationSec: 1})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = simulateTuner(t, tuner, newFOPDTPlant(0, timeConstant, deadTime, dt), dt)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("invalid configs", func(t *testing.T) {
+		for _, cfg := range []TunerConfig{
+			{Method: "guess"},
+			{Method: TuneMethodRelay, Rule: "guess"},
+			{Method: TuneMethodRelay, StepPct: 2},
+			{Method: TuneMethodRelay, Signal: -1},
+			{Method: TuneMethodRelay, DurationSec: -1},
+			{Method: TuneMethodRelay, Rule: string(tuneMethodCohenCoonsPI)},
+			{Method: TuneMethodStepResponse, Rule: string(tuneMethodTyreusLuybenPID)},
+			{Method: TuneMethodStepResponse, Rule: string(tuneMethodZiegerNicholsNoOvershoot)},
+			{Method: TuneMethodFOPDT, Rule: string(tuneMethodZiegerNicholsPI)},
+		} {
+			_, err := NewTuner(cfg)
+			test.That(t, err, test.ShouldNotBeNil)
+		}
+	})
+}
+
+func TestTuningRules(t *testing.T) {
+	t.Run("reaction curve", func(t *testing.T) {
+		// a plant with a gain of 2, a dead time of 0.1s and a time constant of 0.5s
+		for _, tc := range []struct {
+			rule  tuneCalcMethod
+			gains PIDConfig
+		}{
+			{tuneMethodZiegerNicholsPI, PIDConfig{P: 2.25, I: 6.75}},
+			{tuneMethodZiegerNicholsPID, PIDConfig{P: 3, I: 15, D: 0.15}},
+			{tuneMethodCohenCoonsPI, PIDConfig{P: 2.2917, I: 9.7358}},
+			{tuneMethodCohenCoonsPID, PIDConfig{P: 3.4583, I: 15.2083, D: 0.1213}},
+		} {
+			t.Run(string(tc.rule), func(t *testing.T) {
+				gains := reactionCurveGains(tc.rule, 2, 0.1, 0.5)
+				test.That(t, gains.P, test.ShouldAlmostEqual, tc.gains.P, 1e-4)
+				test.That(t, gains.I, test.ShouldAlmostEqual, tc.gains.I, 1e-4)
+				test.That(t, gains.D, test.ShouldAlmostEqual, tc.gains.D, 1e-4)
+			})
+		}
+		test.That(t, len(reactionCurveRules), test.ShouldEqual, 4)
+	})
+
+	t.Run("ultimate", func(t *testing.T) {
+		// a plant with an ultimate gain of 4 and an ultimate period of 0.4s
+		for _, tc := range []struct {
+			rule  tuneCalcMethod
+			gains PIDConfig
+		}{
+			{tuneMethodZiegerNicholsPI, PIDConfig{P: 1.818, I: 5.454}},
+			{tuneMethodZiegerNicholsPID, PIDConfig{P: 2.4, I: 12, D: 0.12}},
+			{tuneMethodZiegerNicholsPD, PIDConfig{P: 3.2, D: 0.16}},
+			{tuneMethodZiegerNicholsSomeOvershoot, PIDConfig{P: 1.332, I: 6.6666, D: 0.17776}},
+			{tuneMethodZiegerNicholsNoOvershoot, PIDConfig{P: 0.8, I: 4, D: 0.10656}},
+			{tuneMethodTyreusLuybenPI, PIDConfig{P: 1.286, I: 1.42}},
+			{tuneMethodTyreusLuybenPID, PIDConfig{P: 1.818, I: 2.066, D: 0.11536}},
+		} {
+			t.Run(string(tc.rule), func(t *testing.T) {
+				gains := ultimateGains(tc.rule, 4, 0.4)
+				test.That(t, gains.P, test.ShouldAlmostEqual, tc.gains.P, 1e-4)
+				test.That(t, gains.I, test.ShouldAlmostEqual, tc.gains.I, 1e-4)
+				test.That(t, gains.D, test.ShouldAlmostEqual, tc.gains.D, 1e-4)
+			})
+		}
+		test.That(t, len(ultimateRules), test.ShouldEqual, 7)
+	})
+
+	t.Run("the step response method proposes gains with the rule", func(t *testing.T) {
+		const dt = 10 * time.Millisecond
+		tuner, err := NewTuner(TunerConfig{Method: TuneMethodStepResponse, Rule: string(tuneMethodCohenCoonsPID)})
+		test.That(t, err, test.ShouldBeNil)
+		result, err := simulateTuner(t, tuner, newFOPDTPlant(2, 0.5, 100*time.Millisecond, dt), dt)
+		test.That(t, err, test.ShouldBeNil)
+		// the model reports its times as durations, which round them to the nanosecond
+		gains := reactionCurveGains(tuneMethodCohenCoonsPID, result.Model.Gain, result.Model.DeadTime.Seconds(),
+			result.Model.TimeConstant.Seconds())
+		test.That(t, result.Gains.P, test.ShouldAlmostEqual, gains.P, 1e-6)
+		test.That(t, result.Gains.I, test.ShouldAlmostEqual, gains.I, 1e-6)
+		test.That(t, result.Gains.D, test.ShouldAlmostEqual, gains.D, 1e-6)
+		test.That(t, result.Gains.D, test.ShouldBeGreaterThan, 0)
+	})
+}
+
+// positionControllable is a motor whose position integrates a velocity which follows its power with a time constant.
+type positionControllable struct {
+	mu       sync.Mutex
+	last     time.Time
+	power    float64
+	velocity float64
+	position float64
+	setCalls int
+}
+
+func (c *positionControllable) SetState(ctx context.Context, state []*Signal) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.power = state[0].GetSignalValueAt(0)
+	c.setCalls++
+	return nil
+}
+
+func (c *positionControllable) State(ctx context.Context) ([]float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if !c.last.IsZero() {
+		dt := now.Sub(c.last).Seconds()
+		c.velocity += dt * (1000*c.power - c.velocity) / 0.05
+		c.position += dt * c.velocity
+	}
+	c.last = now
+	return []float64{c.position}, nil
+}
+
+func TestAutotune(t *testing.T) {
+	c := &positionControllable{}
+	result, err := Autotune(context.Background(), c, TunerConfig{
+		Method:      TuneMethodFOPDT,
+		Derivative:  true,
+		Frequency:   200,
+		DurationSec: 5,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	// the power is the output of the tuner scaled by the default gain, and the velocity is 1000 times the power
+	test.That(t, result.Model.Gain, test.ShouldAlmostEqual, 1000*rPiGain, 0.5)
+	test.That(t, result.Model.TimeConstant.Seconds(), test.ShouldAlmostEqual, 0.05, 0.03)
+	// the controllable is stopped afterwards
+	test.That(t, c.setCalls, test.ShouldBeGreaterThan, 1)
+	test.That(t, c.power, test.ShouldEqual, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Autotune(ctx, c, TunerConfig{Method: TuneMethodRelay})
+	test.That(t, err, test.ShouldBeError, context.Canceled)
+
+	_, err = Autotune(context.Background(), c, TunerConfig{Method: TuneMethodRelay, Signal: 1})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot tune signal 1")
+}
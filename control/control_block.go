@@ -22,6 +22,9 @@ const (
 	blockEncoderToRPM               controlBlockType = "encoderToRpm"
 	blockEndpoint                   controlBlockType = "endpoint"
 	blockFilter                     controlBlockType = "filter"
+	blockFeedforward                controlBlockType = "feedforward"
+	blockSaturation                 controlBlockType = "saturation"
+	blockGainScheduledPID           controlBlockType = "gainScheduledPID"
 )
 
 // BlockConfig configuration of a given block.
@@ -32,6 +35,19 @@ type BlockConfig struct {
 	DependsOn []string           `json:"depends_on"` // List of blocks needed for calling Next
 }
 
+// floatAttribute returns the number attribute of the block with the given name, or def if it isn't set. kind is the kind
+// of block named in the error returned when the attribute isn't a number.
+func (cfg BlockConfig) floatAttribute(kind, name string, def float64) (float64, error) {
+	if !cfg.Attribute.Has(name) {
+		return def, nil
+	}
+	v, ok := cfg.Attribute[name].(float64)
+	if !ok {
+		return 0, errors.Errorf("%s block %s should have a number %s got %T", kind, cfg.Name, name, cfg.Attribute[name])
+	}
+	return v, nil
+}
+
 // Block interface for a control block.
 type Block interface {
 	// Reset will reset the control block to initial state. Returns an error on failure
@@ -107,6 +123,24 @@ func (l *Loop) createBlock(cfg BlockConfig, logger logging.Logger) (Block, error
 			return nil, err
 		}
 		return b, nil
+	case blockFeedforward:
+		b, err := newFeedforward(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	case blockSaturation:
+		b, err := newSaturation(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	case blockGainScheduledPID:
+		b, err := newGainScheduledPID(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
 	}
 	return nil, errors.Errorf("unsupported block type %s", t)
 }
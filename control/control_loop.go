@@ -119,7 +119,9 @@ func createLoop(logger logging.Logger, cfg Config, m Controllable) (*Loop, error
 				for {
 					sw := []*Signal{}
 					s := []*Signal{}
-					for _, c := range b.ins {
+					// firstInput is the number of signals of the first input
+					firstInput := 0
+					for i, c := range b.ins {
 						r, ok := <-c
 						if !ok {
 							b.mu.Lock()
@@ -136,13 +138,19 @@ func createLoop(logger logging.Logger, cfg Config, m Controllable) (*Loop, error
 							}
 						}
 						// TODO(npmenard) do we want to support multidimentional signals?
+						if i == 0 {
+							firstInput = len(sw)
+						}
 					}
 					if strings.Contains(b.blk.Config(l.cancelCtx).Name, "PID") {
+						// PID blocks control one signal of their first input, and take any other inputs as they are,
+						// such as the scheduling signal of a gain scheduled PID
 						if strings.Contains(b.blk.Config(l.cancelCtx).Name, "ang") {
 							s = append(s, sw[1])
 						} else {
 							s = append(s, sw[0])
 						}
+						s = append(s, sw[firstInput:]...)
 					} else {
 						s = sw
 					}
@@ -0,0 +1,102 @@
+package control
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+// feedforward outputs the effort needed to follow a velocity reference from a model of the plant, which is added to the
+// output of a feedback controller so that the feedback only has to correct the error of the model.
+type feedforward struct {
+	mu     sync.Mutex
+	cfg    BlockConfig
+	y      []*Signal
+	logger logging.Logger
+
+	// kS is the static friction, kV the gain on the velocity and kA the gain on the acceleration of the reference
+	kS float64
+	kV float64
+	kA float64
+
+	prevRef    float64
+	hasPrevRef bool
+}
+
+func newFeedforward(config BlockConfig, logger logging.Logger) (Block, error) {
+	f := &feedforward{cfg: config, logger: logger}
+	if err := f.reset(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *feedforward) Next(ctx context.Context, x []*Signal, dt time.Duration) ([]*Signal, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(x) != 1 {
+		return f.y, false
+	}
+	ref := x[0].GetSignalValueAt(0)
+	var accel float64
+	if f.hasPrevRef && dt > 0 {
+		accel = (ref - f.prevRef) / dt.Seconds()
+	}
+	f.prevRef, f.hasPrevRef = ref, true
+
+	var static float64
+	if ref != 0 {
+		static = math.Copysign(f.kS, ref)
+	}
+	f.y[0].SetSignalValueAt(0, static+f.kV*ref+f.kA*accel)
+	return f.y, true
+}
+
+func (f *feedforward) reset() error {
+	if !f.cfg.Attribute.Has("kv") && !f.cfg.Attribute.Has("ka") {
+		return errors.Errorf("feedforward block %s should have a kv or ka field", f.cfg.Name)
+	}
+	if len(f.cfg.DependsOn) != 1 {
+		return errors.Errorf("invalid number of inputs for feedforward block %s expected 1 got %d", f.cfg.Name, len(f.cfg.DependsOn))
+	}
+	var err error
+	if f.kS, err = f.cfg.floatAttribute("feedforward", "ks", 0); err != nil {
+		return err
+	}
+	if f.kV, err = f.cfg.floatAttribute("feedforward", "kv", 0); err != nil {
+		return err
+	}
+	if f.kA, err = f.cfg.floatAttribute("feedforward", "ka", 0); err != nil {
+		return err
+	}
+	f.prevRef, f.hasPrevRef = 0, false
+	f.y = make([]*Signal, 1)
+	f.y[0] = makeSignal(f.cfg.Name, f.cfg.Type)
+	return nil
+}
+
+func (f *feedforward) Reset(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reset()
+}
+
+func (f *feedforward) UpdateConfig(ctx context.Context, config BlockConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cfg = config
+	return f.reset()
+}
+
+func (f *feedforward) Output(ctx context.Context) []*Signal {
+	return f.y
+}
+
+func (f *feedforward) Config(ctx context.Context) BlockConfig {
+	return f.cfg
+}
@@ -0,0 +1,99 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+func TestFeedforwardConfig(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	for _, c := range []struct {
+		conf BlockConfig
+		err  string
+	}{
+		{
+			BlockConfig{
+				Name:      "FF1",
+				Type:      "feedforward",
+				Attribute: utils.AttributeMap{"kv": 0.5, "ka": 0.1, "ks": 2.0},
+				DependsOn: []string{"A"},
+			},
+			"",
+		},
+		{
+			BlockConfig{
+				Name:      "FF1",
+				Type:      "feedforward",
+				Attribute: utils.AttributeMap{"ks": 2.0},
+				DependsOn: []string{"A"},
+			},
+			"feedforward block FF1 should have a kv or ka field",
+		},
+		{
+			BlockConfig{
+				Name:      "FF1",
+				Type:      "feedforward",
+				Attribute: utils.AttributeMap{"kv": 0.5},
+				DependsOn: []string{"A", "B"},
+			},
+			"invalid number of inputs for feedforward block FF1 expected 1 got 2",
+		},
+		{
+			BlockConfig{
+				Name:      "FF1",
+				Type:      "feedforward",
+				Attribute: utils.AttributeMap{"kv": "0.5"},
+				DependsOn: []string{"A"},
+			},
+			"feedforward block FF1 should have a number kv got string",
+		},
+	} {
+		_, err := newFeedforward(c.conf, logger)
+		if c.err == "" {
+			test.That(t, err, test.ShouldBeNil)
+		} else {
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldResemble, c.err)
+		}
+	}
+}
+
+func TestFeedforwardNext(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	b, err := newFeedforward(BlockConfig{
+		Name:      "FF1",
+		Type:      "feedforward",
+		Attribute: utils.AttributeMap{"kv": 0.5, "ka": 0.1, "ks": 2.0},
+		DependsOn: []string{"A"},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	ref := []*Signal{makeSignal("A", blockConstant)}
+	dt := 100 * time.Millisecond
+	for _, tc := range []struct {
+		ref, expected float64
+	}{
+		// the acceleration is unknown until the second reference
+		{10, 2 + 0.5*10},
+		// accelerating at 100/s²
+		{20, 2 + 0.5*20 + 0.1*100},
+		// holding a velocity
+		{20, 2 + 0.5*20},
+		// decelerating to a stop only leaves the acceleration term
+		{0, 0.1 * -200},
+		// static friction opposes the direction of motion
+		{-10, -2 + 0.5*-10 + 0.1*-100},
+	} {
+		ref[0].SetSignalValueAt(0, tc.ref)
+		out, ok := b.Next(ctx, ref, dt)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, tc.expected)
+	}
+}
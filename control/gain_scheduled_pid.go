@@ -0,0 +1,135 @@
+package control
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+// GainSchedulePoint is the PID gains of a gain scheduled PID block at a value of its scheduling signal.
+type GainSchedulePoint struct {
+	At float64 `json:"at"`
+	P  float64 `json:"p"`
+	I  float64 `json:"i"`
+	D  float64 `json:"d"`
+}
+
+// gainScheduledPID is a PID controller whose gains are linearly interpolated from a table keyed by a second input, such
+// as the speed of a base. The integral accumulates the integral gain times the error, so changing gains do not bump the
+// output.
+type gainScheduledPID struct {
+	mu       sync.Mutex
+	cfg      BlockConfig
+	logger   logging.Logger
+	schedule []GainSchedulePoint
+	pid      *basicPID
+}
+
+func newGainScheduledPID(config BlockConfig, logger logging.Logger) (Block, error) {
+	g := &gainScheduledPID{cfg: config, logger: logger}
+	if err := g.reset(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Next takes the error as its first input and the scheduling signal as its second.
+func (g *gainScheduledPID) Next(ctx context.Context, x []*Signal, dt time.Duration) ([]*Signal, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(x) != 2 {
+		return g.pid.y, false
+	}
+	gains := g.gainsAt(x[1].GetSignalValueAt(0))
+	g.pid.PIDSets[0].P = gains.P
+	g.pid.PIDSets[0].I = gains.I
+	g.pid.PIDSets[0].D = gains.D
+	g.pid.y[0].SetSignalValueAt(0, calculateSignalValue(g.pid, x[:1], dt, 0))
+	return g.pid.y, true
+}
+
+// gainsAt interpolates the gains at the value of the scheduling signal, holding the gains of the first and last points
+// outside of the table.
+func (g *gainScheduledPID) gainsAt(at float64) GainSchedulePoint {
+	idx, _ := slices.BinarySearchFunc(g.schedule, at, func(pt GainSchedulePoint, at float64) int {
+		switch {
+		case pt.At < at:
+			return -1
+		case pt.At > at:
+			return 1
+		default:
+			return 0
+		}
+	})
+	switch {
+	case idx == 0:
+		return g.schedule[0]
+	case idx == len(g.schedule):
+		return g.schedule[len(g.schedule)-1]
+	}
+	lo, hi := g.schedule[idx-1], g.schedule[idx]
+	frac := (at - lo.At) / (hi.At - lo.At)
+	lerp := func(a, b float64) float64 { return a + frac*(b-a) }
+	return GainSchedulePoint{At: at, P: lerp(lo.P, hi.P), I: lerp(lo.I, hi.I), D: lerp(lo.D, hi.D)}
+}
+
+func (g *gainScheduledPID) reset() error {
+	schedule, ok := g.cfg.Attribute["gain_schedule"].([]GainSchedulePoint)
+	if !ok || len(schedule) == 0 {
+		return errors.Errorf("gain scheduled pid block %s should have a gain_schedule", g.cfg.Name)
+	}
+	if len(g.cfg.DependsOn) != 2 {
+		return errors.Errorf("gain scheduled pid block %s should have 2 inputs got %d", g.cfg.Name, len(g.cfg.DependsOn))
+	}
+	g.schedule = slices.Clone(schedule)
+	slices.SortFunc(g.schedule, func(a, b GainSchedulePoint) int {
+		switch {
+		case a.At < b.At:
+			return -1
+		case a.At > b.At:
+			return 1
+		default:
+			return 0
+		}
+	})
+	for i := 1; i < len(g.schedule); i++ {
+		if g.schedule[i].At == g.schedule[i-1].At {
+			return errors.Errorf("gain scheduled pid block %s has two gains at %v", g.cfg.Name, g.schedule[i].At)
+		}
+	}
+
+	// the limits and anti windup of the underlying PID are configured by the same attributes as a PID block
+	pidCfg := g.cfg
+	pidCfg.Attribute = maps.Clone(g.cfg.Attribute)
+	pidCfg.Attribute["PIDSets"] = []*PIDConfig{{P: g.schedule[0].P, I: g.schedule[0].I, D: g.schedule[0].D}}
+	pidCfg.DependsOn = g.cfg.DependsOn[:1]
+	g.pid = &basicPID{cfg: pidCfg, logger: g.logger}
+	return g.pid.reset()
+}
+
+func (g *gainScheduledPID) Reset(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.reset()
+}
+
+func (g *gainScheduledPID) UpdateConfig(ctx context.Context, config BlockConfig) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cfg = config
+	return g.reset()
+}
+
+func (g *gainScheduledPID) Output(ctx context.Context) []*Signal {
+	return g.pid.y
+}
+
+func (g *gainScheduledPID) Config(ctx context.Context) BlockConfig {
+	return g.cfg
+}
@@ -0,0 +1,103 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+func TestGainScheduledPIDConfig(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	schedule := []GainSchedulePoint{{At: 0, P: 1}, {At: 10, P: 2}}
+	for _, c := range []struct {
+		conf BlockConfig
+		err  string
+	}{
+		{
+			BlockConfig{
+				Name:      "GS1",
+				Type:      "gainScheduledPID",
+				Attribute: utils.AttributeMap{"gain_schedule": schedule},
+				DependsOn: []string{"error", "speed"},
+			},
+			"",
+		},
+		{
+			BlockConfig{
+				Name:      "GS1",
+				Type:      "gainScheduledPID",
+				Attribute: utils.AttributeMap{"gain_schedule": []GainSchedulePoint{}},
+				DependsOn: []string{"error", "speed"},
+			},
+			"gain scheduled pid block GS1 should have a gain_schedule",
+		},
+		{
+			BlockConfig{
+				Name:      "GS1",
+				Type:      "gainScheduledPID",
+				Attribute: utils.AttributeMap{"gain_schedule": schedule},
+				DependsOn: []string{"error"},
+			},
+			"gain scheduled pid block GS1 should have 2 inputs got 1",
+		},
+		{
+			BlockConfig{
+				Name:      "GS1",
+				Type:      "gainScheduledPID",
+				Attribute: utils.AttributeMap{"gain_schedule": []GainSchedulePoint{{At: 1, P: 1}, {At: 1, P: 2}}},
+				DependsOn: []string{"error", "speed"},
+			},
+			"gain scheduled pid block GS1 has two gains at 1",
+		},
+	} {
+		_, err := newGainScheduledPID(c.conf, logger)
+		if c.err == "" {
+			test.That(t, err, test.ShouldBeNil)
+		} else {
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldResemble, c.err)
+		}
+	}
+}
+
+func TestGainScheduledPIDNext(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	b, err := newGainScheduledPID(BlockConfig{
+		Name: "GS1",
+		Type: "gainScheduledPID",
+		Attribute: utils.AttributeMap{
+			// out of order, which the block sorts
+			"gain_schedule": []GainSchedulePoint{{At: 100, P: 3, I: 1}, {At: 0, P: 1}, {At: 50, P: 2}},
+			"limit_lo":      -255.0,
+		},
+		DependsOn: []string{"error", "speed"},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	in := []*Signal{makeSignal("error", blockSum), makeSignal("speed", blockEndpoint)}
+	in[0].SetSignalValueAt(0, 1)
+	dt := time.Second
+	for _, tc := range []struct{ speed, expected float64 }{
+		// held below and above the table
+		{-10, 1},
+		{0, 1},
+		{25, 1.5},
+		{50, 2},
+		{75, 2.5 + 0.5},
+		{200, 3 + 0.5 + 1},
+	} {
+		in[1].SetSignalValueAt(0, tc.speed)
+		out, ok := b.Next(ctx, in, dt)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, tc.expected)
+	}
+
+	_, ok := b.Next(ctx, in[:1], dt)
+	test.That(t, ok, test.ShouldBeFalse)
+}
@@ -36,8 +36,22 @@ type basicPID struct {
 	limUp    float64 `default:"255.0"`
 	satLimLo float64
 	limLo    float64
+
+	antiWindup antiWindupStrategy
+	// backCalcGain is the rate at which back calculation unwinds the integral, zero to derive it from the gains
+	backCalcGain float64
 }
 
+type antiWindupStrategy string
+
+const (
+	// antiWindupClamping clamps the integral between int_sat_lim_lo and int_sat_lim_up.
+	antiWindupClamping antiWindupStrategy = "clamping"
+	// antiWindupBackCalculation feeds the amount by which the output saturates back into the integral, which unwinds
+	// the integral as soon as the output saturates.
+	antiWindupBackCalculation antiWindupStrategy = "back_calculation"
+)
+
 // GetTuning returns whether the PID block is currently tuning any signals.
 func (p *basicPID) GetTuning() bool {
 	// using locks to prevent reading from tuners while the object is being modified
@@ -98,6 +112,9 @@ func (p *basicPID) Next(ctx context.Context, x []*Signal, dt time.Duration) ([]*
 
 // For a given signal, compute new signal value based on current signal value, & its respective error.
 func calculateSignalValue(p *basicPID, x []*Signal, dt time.Duration, sIndex int) float64 {
+	if p.antiWindup == antiWindupBackCalculation {
+		return calculateSignalValueBackCalculation(p, x, dt, sIndex)
+	}
 	dtS := dt.Seconds()
 	pvError := x[0].GetSignalValueAt(sIndex)
 	p.PIDSets[sIndex].int += p.PIDSets[sIndex].I * pvError * dtS
@@ -121,8 +138,30 @@ func calculateSignalValue(p *basicPID, x []*Signal, dt time.Duration, sIndex int
 	return output
 }
 
+// calculateSignalValueBackCalculation computes the new signal value like calculateSignalValue, but rather than clamping
+// the integral it reduces the integral by the amount the output saturates, scaled by the back calculation gain.
+// reference: Åström and Murray, "Feedback Systems", section 10.4.
+func calculateSignalValueBackCalculation(p *basicPID, x []*Signal, dt time.Duration, sIndex int) float64 {
+	dtS := dt.Seconds()
+	pid := p.PIDSets[sIndex]
+	pvError := x[0].GetSignalValueAt(sIndex)
+	deriv := (pvError - pid.signalErr) / dtS
+	unsaturated := pid.P*pvError + pid.int + pid.D*deriv
+	output := math.Max(math.Min(unsaturated, p.limUp), p.limLo)
+
+	backCalcGain := p.backCalcGain
+	if backCalcGain == 0 && pid.P != 0 {
+		// the reciprocal of the integral time
+		backCalcGain = pid.I / pid.P
+	}
+	pid.int += (pid.I*pvError + backCalcGain*(output-unsaturated)) * dtS
+	pid.signalErr = pvError
+	return output
+}
+
 func (p *basicPID) reset() error {
 	var ok bool
+	var err error
 
 	// Each PIDSet is taken from the config, if the attribute exists (it's optional).
 	// If PID Sets was given as an attribute, we know we're in 'multi' mode. For each
@@ -171,6 +210,23 @@ func (p *basicPID) reset() error {
 		p.limLo = p.cfg.Attribute["limit_lo"].(float64)
 	}
 
+	p.antiWindup = antiWindupClamping
+	if p.cfg.Attribute.Has("anti_windup") {
+		antiWindup, ok := p.cfg.Attribute["anti_windup"].(string)
+		if !ok {
+			return errors.Errorf("pid block %s should have a string anti_windup got %T", p.cfg.Name, p.cfg.Attribute["anti_windup"])
+		}
+		p.antiWindup = antiWindupStrategy(antiWindup)
+	}
+	switch p.antiWindup {
+	case antiWindupClamping, antiWindupBackCalculation:
+	default:
+		return errors.Errorf("pid block %s has an unknown anti_windup strategy %s", p.cfg.Name, p.antiWindup)
+	}
+	if p.backCalcGain, err = p.cfg.floatAttribute("pid", "back_calculation_gain", 0); err != nil {
+		return err
+	}
+
 	for i := 0; i < len(p.PIDSets); i++ {
 		// Create a Tuner object for our PID set. Across all Tuner objects, they share global
 		// values (limUp, limLo, ssR, tuneMethod, stepPct). The only values that differ are P,I,D.
@@ -255,6 +311,18 @@ const (
 	tuneMethodTyreusLuybenPID            tuneCalcMethod = "tyreusLuybenPID"
 )
 
+var tuneCalcMethods = []tuneCalcMethod{
+	tuneMethodZiegerNicholsPI,
+	tuneMethodZiegerNicholsPID,
+	tuneMethodZiegerNicholsPD,
+	tuneMethodZiegerNicholsSomeOvershoot,
+	tuneMethodZiegerNicholsNoOvershoot,
+	tuneMethodCohenCoonsPI,
+	tuneMethodCohenCoonsPID,
+	tuneMethodTyreusLuybenPI,
+	tuneMethodTyreusLuybenPID,
+}
+
 const (
 	begin = iota
 	step
@@ -304,34 +372,6 @@ func (p *pidTuner) computeGains() {
 	kU := (4 * d) / (math.Pi * a)
 	pU := (p.tC * 2.0).Seconds()
 	switch p.tuneMethod {
-	case tuneMethodZiegerNicholsPI:
-		p.kP = 0.4545 * kU
-		p.kI = 0.5454 * (kU / pU)
-		p.kD = 0.0
-	case tuneMethodZiegerNicholsPD:
-		p.kP = 0.8 * kU
-		p.kI = 0.0
-		p.kD = 0.10 * kU * pU
-	case tuneMethodZiegerNicholsPID:
-		p.kP = 0.6 * kU
-		p.kI = 1.2 * (kU / pU)
-		p.kD = 0.075 * kU * pU
-	case tuneMethodZiegerNicholsSomeOvershoot:
-		p.kP = 0.333 * kU
-		p.kI = 0.66666 * (kU / pU)
-		p.kD = 0.1111 * kU * pU
-	case tuneMethodZiegerNicholsNoOvershoot:
-		p.kP = 0.2 * kU
-		p.kI = 0.4 * (kU / pU)
-		p.kD = 0.0666 * kU * pU
-	case tuneMethodTyreusLuybenPI:
-		p.kP = 0.3215 * kU
-		p.kI = 0.1420 * (kU / pU)
-		p.kD = 0.0
-	case tuneMethodTyreusLuybenPID:
-		p.kP = 0.4545 * kU
-		p.kI = 0.2066 * (kU / pU)
-		p.kD = 0.0721 * kU * pU
 	case tuneMethodCohenCoonsPI:
 		t1 := (p.ccT2.Seconds() - math.Log(2.0)*p.ccT3.Seconds()) / (1.0 - math.Log(2.0))
 		tau := p.ccT3.Seconds() - t1
@@ -349,10 +389,34 @@ func (p *pidTuner) computeGains() {
 		p.kP = (1.0 / (K * r)) * (4.0/3.0 + r/4)
 		p.kI = p.kP / (tauD) * (32 + 6*r) / (13 + 8*r)
 		p.kD = p.kP / (4 * tauD / (11 + 2*r))
+	default:
+		gains := ultimateGains(p.tuneMethod, kU, pU)
+		p.kP = gains.P
+		p.kI = gains.I
+		p.kD = gains.D
+	}
+}
+
+// ultimateGains returns the gains proposed by the tuning rule for a plant with the ultimate gain kU and the ultimate
+// period pU in seconds.
+func ultimateGains(method tuneCalcMethod, kU, pU float64) PIDConfig {
+	switch method {
+	case tuneMethodZiegerNicholsPI:
+		return PIDConfig{P: 0.4545 * kU, I: 0.5454 * (kU / pU)}
+	case tuneMethodZiegerNicholsPD:
+		return PIDConfig{P: 0.8 * kU, D: 0.10 * kU * pU}
+	case tuneMethodZiegerNicholsPID:
+		return PIDConfig{P: 0.6 * kU, I: 1.2 * (kU / pU), D: 0.075 * kU * pU}
+	case tuneMethodZiegerNicholsSomeOvershoot:
+		return PIDConfig{P: 0.333 * kU, I: 0.66666 * (kU / pU), D: 0.1111 * kU * pU}
+	case tuneMethodZiegerNicholsNoOvershoot:
+		return PIDConfig{P: 0.2 * kU, I: 0.4 * (kU / pU), D: 0.0666 * kU * pU}
+	case tuneMethodTyreusLuybenPI:
+		return PIDConfig{P: 0.3215 * kU, I: 0.1420 * (kU / pU)}
+	case tuneMethodTyreusLuybenPID:
+		return PIDConfig{P: 0.4545 * kU, I: 0.2066 * (kU / pU), D: 0.0721 * kU * pU}
 	default: // ziegler nichols PI is the default
-		p.kP = 0.4545 * kU
-		p.kI = 0.5454 * (kU / pU)
-		p.kD = 0.0
+		return PIDConfig{P: 0.4545 * kU, I: 0.5454 * (kU / pU)}
 	}
 }
 
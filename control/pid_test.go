@@ -143,6 +143,68 @@ func TestPIDMultiIntegralWindup(t *testing.T) {
 	test.That(t, pid.PIDSets[1].D, test.ShouldEqual, .10)
 }
 
+func TestPIDAntiWindup(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	newPIDWithAntiWindup := func(strategy, backCalcGain interface{}) (*basicPID, error) {
+		attributes := utils.AttributeMap{
+			"PIDSets":        []*PIDConfig{{P: 1, I: 1}},
+			"limit_up":       10.0,
+			"limit_lo":       -10.0,
+			"int_sat_lim_up": 1000.0,
+			"int_sat_lim_lo": -1000.0,
+			"anti_windup":    strategy,
+		}
+		if backCalcGain != nil {
+			attributes["back_calculation_gain"] = backCalcGain
+		}
+		b, err := loop.newPID(BlockConfig{
+			Name:      "PID1",
+			Attribute: attributes,
+			Type:      "PID",
+			DependsOn: []string{"A"},
+		}, logger)
+		if err != nil {
+			return nil, err
+		}
+		return b.(*basicPID), nil
+	}
+
+	// saturates the output for a while and then returns the output once the error reverses
+	outputAfterSaturating := func(pid *basicPID) float64 {
+		s := []*Signal{makeSignal("A", blockSum)}
+		s[0].SetSignalValueAt(0, 20)
+		for i := 0; i < 5; i++ {
+			out, ok := pid.Next(ctx, s, time.Second)
+			test.That(t, ok, test.ShouldBeTrue)
+			test.That(t, out[0].GetSignalValueAt(0), test.ShouldEqual, 10)
+		}
+		s[0].SetSignalValueAt(0, -1)
+		out, ok := pid.Next(ctx, s, time.Second)
+		test.That(t, ok, test.ShouldBeTrue)
+		return out[0].GetSignalValueAt(0)
+	}
+
+	clamping, err := newPIDWithAntiWindup("clamping", nil)
+	test.That(t, err, test.ShouldBeNil)
+	// the integral wound up to 100 while saturated, which keeps the output saturated
+	test.That(t, outputAfterSaturating(clamping), test.ShouldEqual, 10)
+
+	backCalculation, err := newPIDWithAntiWindup("back_calculation", nil)
+	test.That(t, err, test.ShouldBeNil)
+	// the integral unwound to keep the output at the limit, so the output leaves the limit as soon as the error reverses
+	test.That(t, outputAfterSaturating(backCalculation), test.ShouldEqual, 9)
+
+	_, err = newPIDWithAntiWindup("hope", nil)
+	test.That(t, err.Error(), test.ShouldEqual, "pid block PID1 has an unknown anti_windup strategy hope")
+
+	// attributes of the wrong type are config errors rather than panics
+	_, err = newPIDWithAntiWindup(1.0, nil)
+	test.That(t, err.Error(), test.ShouldEqual, "pid block PID1 should have a string anti_windup got float64")
+	_, err = newPIDWithAntiWindup("back_calculation", "fast")
+	test.That(t, err.Error(), test.ShouldEqual, "pid block PID1 should have a number back_calculation_gain got string")
+}
+
 func TestPIDMultiTuner(t *testing.T) {
 	ctx := context.Background()
 	logger := logging.NewTestLogger(t)
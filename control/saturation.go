@@ -0,0 +1,101 @@
+package control
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+// saturation limits its input to a range, and optionally limits how quickly its output may change.
+type saturation struct {
+	mu     sync.Mutex
+	cfg    BlockConfig
+	y      []*Signal
+	logger logging.Logger
+
+	limLo float64
+	limUp float64
+	// maxRate is the largest change of the output per second, zero for no limit
+	maxRate float64
+}
+
+func newSaturation(config BlockConfig, logger logging.Logger) (Block, error) {
+	s := &saturation{cfg: config, logger: logger}
+	if err := s.reset(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *saturation) Next(ctx context.Context, x []*Signal, dt time.Duration) ([]*Signal, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// only the first signal of the input is limited, so that a saturation can follow a sum block, whose outputs
+	// after its sums are not used
+	if len(x) == 0 {
+		return s.y, false
+	}
+	out := math.Max(math.Min(x[0].GetSignalValueAt(0), s.limUp), s.limLo)
+	if s.maxRate > 0 {
+		// the output ramps from its previous value, which starts at zero
+		maxChange := s.maxRate * dt.Seconds()
+		prev := s.y[0].GetSignalValueAt(0)
+		out = math.Max(math.Min(out, prev+maxChange), prev-maxChange)
+	}
+	s.y[0].SetSignalValueAt(0, out)
+	return s.y, true
+}
+
+func (s *saturation) reset() error {
+	if !s.cfg.Attribute.Has("limit_lo") && !s.cfg.Attribute.Has("limit_up") && !s.cfg.Attribute.Has("max_rate") {
+		return errors.Errorf("saturation block %s should have a limit_lo, limit_up or max_rate field", s.cfg.Name)
+	}
+	if len(s.cfg.DependsOn) != 1 {
+		return errors.Errorf("invalid number of inputs for saturation block %s expected 1 got %d", s.cfg.Name, len(s.cfg.DependsOn))
+	}
+	var err error
+	if s.limLo, err = s.cfg.floatAttribute("saturation", "limit_lo", math.Inf(-1)); err != nil {
+		return err
+	}
+	if s.limUp, err = s.cfg.floatAttribute("saturation", "limit_up", math.Inf(1)); err != nil {
+		return err
+	}
+	if s.maxRate, err = s.cfg.floatAttribute("saturation", "max_rate", 0); err != nil {
+		return err
+	}
+	if s.limLo > s.limUp {
+		return errors.Errorf("saturation block %s has a limit_lo greater than its limit_up", s.cfg.Name)
+	}
+	if s.maxRate < 0 {
+		return errors.Errorf("saturation block %s should have a non-negative max_rate", s.cfg.Name)
+	}
+	s.y = make([]*Signal, 1)
+	s.y[0] = makeSignal(s.cfg.Name, s.cfg.Type)
+	return nil
+}
+
+func (s *saturation) Reset(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reset()
+}
+
+func (s *saturation) UpdateConfig(ctx context.Context, config BlockConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = config
+	return s.reset()
+}
+
+func (s *saturation) Output(ctx context.Context) []*Signal {
+	return s.y
+}
+
+func (s *saturation) Config(ctx context.Context) BlockConfig {
+	return s.cfg
+}
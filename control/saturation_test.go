@@ -0,0 +1,154 @@
+package control
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+func TestSaturationConfig(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	for _, c := range []struct {
+		conf BlockConfig
+		err  string
+	}{
+		{
+			BlockConfig{
+				Name:      "Sat1",
+				Type:      "saturation",
+				Attribute: utils.AttributeMap{"limit_lo": -1.0, "limit_up": 1.0, "max_rate": 2.0},
+				DependsOn: []string{"A"},
+			},
+			"",
+		},
+		{
+			BlockConfig{
+				Name:      "Sat1",
+				Type:      "saturation",
+				Attribute: utils.AttributeMap{},
+				DependsOn: []string{"A"},
+			},
+			"saturation block Sat1 should have a limit_lo, limit_up or max_rate field",
+		},
+		{
+			BlockConfig{
+				Name:      "Sat1",
+				Type:      "saturation",
+				Attribute: utils.AttributeMap{"limit_lo": 1.0, "limit_up": -1.0},
+				DependsOn: []string{"A"},
+			},
+			"saturation block Sat1 has a limit_lo greater than its limit_up",
+		},
+		{
+			BlockConfig{
+				Name:      "Sat1",
+				Type:      "saturation",
+				Attribute: utils.AttributeMap{"max_rate": -1.0},
+				DependsOn: []string{"A"},
+			},
+			"saturation block Sat1 should have a non-negative max_rate",
+		},
+		{
+			BlockConfig{
+				Name:      "Sat1",
+				Type:      "saturation",
+				Attribute: utils.AttributeMap{"limit_up": 1.0},
+				DependsOn: []string{},
+			},
+			"invalid number of inputs for saturation block Sat1 expected 1 got 0",
+		},
+		{
+			BlockConfig{
+				Name:      "Sat1",
+				Type:      "saturation",
+				Attribute: utils.AttributeMap{"limit_up": 1},
+				DependsOn: []string{"A"},
+			},
+			"saturation block Sat1 should have a number limit_up got int",
+		},
+	} {
+		_, err := newSaturation(c.conf, logger)
+		if c.err == "" {
+			test.That(t, err, test.ShouldBeNil)
+		} else {
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldResemble, c.err)
+		}
+	}
+}
+
+func TestSaturationNext(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	in := []*Signal{makeSignal("A", blockConstant)}
+	dt := 100 * time.Millisecond
+
+	t.Run("limits", func(t *testing.T) {
+		b, err := newSaturation(BlockConfig{
+			Name:      "Sat1",
+			Type:      "saturation",
+			Attribute: utils.AttributeMap{"limit_lo": -1.0, "limit_up": 1.0},
+			DependsOn: []string{"A"},
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		for _, tc := range []struct{ in, expected float64 }{{0.5, 0.5}, {5, 1}, {-5, -1}} {
+			in[0].SetSignalValueAt(0, tc.in)
+			out, ok := b.Next(ctx, in, dt)
+			test.That(t, ok, test.ShouldBeTrue)
+			test.That(t, out[0].GetSignalValueAt(0), test.ShouldEqual, tc.expected)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		b, err := newSaturation(BlockConfig{
+			Name:      "Sat1",
+			Type:      "saturation",
+			Attribute: utils.AttributeMap{"limit_up": 1.0, "max_rate": 2.0},
+			DependsOn: []string{"A"},
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		// ramps up by 0.2 per step until the limit, then ramps down
+		for _, tc := range []struct{ in, expected float64 }{
+			{5, 0.2}, {5, 0.4}, {5, 0.6}, {5, 0.8}, {5, 1}, {5, 1}, {0.9, 0.9}, {-5, 0.7},
+		} {
+			in[0].SetSignalValueAt(0, tc.in)
+			out, ok := b.Next(ctx, in, dt)
+			test.That(t, ok, test.ShouldBeTrue)
+			test.That(t, out[0].GetSignalValueAt(0), test.ShouldAlmostEqual, tc.expected)
+		}
+		test.That(t, b.Reset(ctx), test.ShouldBeNil)
+		test.That(t, b.Output(ctx)[0].GetSignalValueAt(0), test.ShouldEqual, 0)
+	})
+
+	t.Run("follows a sum", func(t *testing.T) {
+		sum, err := newSum(BlockConfig{
+			Name:      "Sum1",
+			Type:      "sum",
+			Attribute: utils.AttributeMap{"sum_string": "++"},
+			DependsOn: []string{"A", "B"},
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		b, err := newSaturation(BlockConfig{
+			Name:      "Sat1",
+			Type:      "saturation",
+			Attribute: utils.AttributeMap{"limit_up": 1.0},
+			DependsOn: []string{"Sum1"},
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		sumIn := []*Signal{makeSignal("A", blockConstant), makeSignal("B", blockConstant)}
+		sumIn[0].SetSignalValueAt(0, 0.25)
+		sumIn[1].SetSignalValueAt(0, 0.5)
+		sumOut, ok := sum.Next(ctx, sumIn, dt)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, sumOut, test.ShouldHaveLength, 2)
+		out, ok := b.Next(ctx, sumOut, dt)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, out[0].GetSignalValueAt(0), test.ShouldEqual, 0.75)
+	})
+}
@@ -53,6 +53,20 @@ type PIDConfig struct {
 	I    float64 `json:"i"`
 	D    float64 `json:"d"`
 
+	// KS, KV and KA are the static, velocity and acceleration gains of a feedforward on the set point, whose output is
+	// added to the output of the PID.
+	KS float64 `json:"ks,omitempty"`
+	KV float64 `json:"kv,omitempty"`
+	KA float64 `json:"ka,omitempty"`
+	// MaxRate limits how quickly the output of the PID and feedforward may change, per second.
+	MaxRate float64 `json:"max_rate,omitempty"`
+	// AntiWindup is how the PID keeps its integral from winding up, clamping (the default) or back_calculation, and
+	// BackCalculationGain is the rate at which back calculation unwinds the integral, derived from the gains if zero.
+	AntiWindup          string  `json:"anti_windup,omitempty"`
+	BackCalculationGain float64 `json:"back_calculation_gain,omitempty"`
+	// GainSchedule interpolates the gains of the PID from a table keyed by the set point, in place of P, I and D.
+	GainSchedule []GainSchedulePoint `json:"gain_schedule,omitempty"`
+
 	// PID block specific values
 	// these are integral sum and signalErr for the pid signal
 	int       float64
@@ -61,7 +75,16 @@ type PIDConfig struct {
 
 // NeedsAutoTuning checks if the PIDConfig values require auto tuning.
 func (conf *PIDConfig) NeedsAutoTuning() bool {
-	return (conf.P == 0.0 && conf.I == 0.0 && conf.D == 0.0)
+	return (conf.P == 0.0 && conf.I == 0.0 && conf.D == 0.0) && len(conf.GainSchedule) == 0
+}
+
+func (conf *PIDConfig) hasFeedforward() bool {
+	return conf.KS != 0 || conf.KV != 0 || conf.KA != 0
+}
+
+// hasExtraBlocks checks if the PIDConfig values add a feedforward, saturation or gain schedule to the control loop.
+func (conf *PIDConfig) hasExtraBlocks() bool {
+	return conf.hasFeedforward() || conf.MaxRate != 0 || len(conf.GainSchedule) != 0
 }
 
 // Options contains values used for a control loop.
@@ -123,7 +146,22 @@ func SetupPIDControlConfig(
 			}
 		}
 	} else {
+		if options.NeedsAutoTuning {
+			for _, pidConf := range pidVals {
+				if pidConf.hasExtraBlocks() {
+					return nil, errors.New(
+						"cannot auto-tune a control loop with a feedforward, max_rate or gain_schedule, configure the p, i and d of every PID")
+				}
+			}
+		}
 		pidLoop.createControlLoopConfig(pidVals, componentName)
+		// create the blocks of the config so that mistakes in it are found now rather than when the loop starts
+		validationLoop := &Loop{}
+		for _, b := range pidLoop.ControlConf.Blocks {
+			if _, err := validationLoop.createBlock(b, logger); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// auto tune the control loop if needed
@@ -251,6 +289,15 @@ func (p *PIDLoop) createControlLoopConfig(pidVals []PIDConfig, componentName str
 		p.addSensorFeedbackVelocityControl(pidVals[1])
 	}
 
+	// add the anti-windup, gain schedule, feedforward and rate limit of each PID
+	if p.Options.SensorFeedback2DVelocityControl {
+		p.addPIDOptions(linearPIDIndex, "linear_", "linear_set_point", pidVals[0])
+		p.addPIDOptions(angularPIDIndex, "angular_", "angular_set_point", pidVals[1])
+	} else {
+		// the PID follows the first input of the sum, the velocity profile for position control
+		p.addPIDOptions(linearPIDIndex, "", p.ControlConf.Blocks[sumIndex].DependsOn[0], pidVals[0])
+	}
+
 	// assign block names
 	p.BlockNames = make(map[string][]string, len(p.ControlConf.Blocks))
 	for _, b := range p.ControlConf.Blocks {
@@ -421,6 +468,67 @@ func (p *PIDLoop) addSensorFeedbackVelocityControl(angularPIDVals PIDConfig) {
 	p.ControlConf.Blocks[4].DependsOn = []string{"linear_gain", "angular_gain"}
 }
 
+// addPIDOptions adds the options of the PID values to the PID block at pidIndex, which is followed by its gain block.
+// A gain schedule makes it a gain scheduled PID keyed by the reference, the signal the PID follows. A feedforward on
+// the reference is added to the output of the PID, which is then limited like the output of the PID and to the max
+// rate. The added blocks are named with the prefix.
+func (p *PIDLoop) addPIDOptions(pidIndex int, prefix, reference string, pidVals PIDConfig) {
+	pid := &p.ControlConf.Blocks[pidIndex]
+	if pidVals.AntiWindup != "" {
+		pid.Attribute["anti_windup"] = pidVals.AntiWindup
+	}
+	if pidVals.BackCalculationGain != 0 {
+		pid.Attribute["back_calculation_gain"] = pidVals.BackCalculationGain
+	}
+	if len(pidVals.GainSchedule) != 0 {
+		pid.Type = blockGainScheduledPID
+		pid.Attribute["gain_schedule"] = pidVals.GainSchedule
+		pid.DependsOn = append(pid.DependsOn, reference)
+	}
+	output := pid.Name
+	limitLo, limitUp := pid.Attribute["limit_lo"], pid.Attribute["limit_up"]
+
+	if pidVals.hasFeedforward() {
+		feedforward := BlockConfig{
+			Name: prefix + "feedforward",
+			Type: blockFeedforward,
+			Attribute: rdkutils.AttributeMap{
+				"ks": pidVals.KS,
+				"kv": pidVals.KV,
+				"ka": pidVals.KA,
+			},
+			DependsOn: []string{reference},
+		}
+		feedforwardSum := BlockConfig{
+			Name: prefix + "feedforward_sum",
+			Type: blockSum,
+			Attribute: rdkutils.AttributeMap{
+				"sum_string": "++",
+			},
+			DependsOn: []string{output, feedforward.Name},
+		}
+		p.ControlConf.Blocks = append(p.ControlConf.Blocks, feedforward, feedforwardSum)
+		output = feedforwardSum.Name
+	}
+
+	if pidVals.hasFeedforward() || pidVals.MaxRate != 0 {
+		saturation := BlockConfig{
+			Name: prefix + "saturation",
+			Type: blockSaturation,
+			Attribute: rdkutils.AttributeMap{
+				"limit_lo": limitLo,
+				"limit_up": limitUp,
+				"max_rate": pidVals.MaxRate,
+			},
+			DependsOn: []string{output},
+		}
+		p.ControlConf.Blocks = append(p.ControlConf.Blocks, saturation)
+		output = saturation.Name
+	}
+
+	p.ControlConf.Blocks[pidIndex+1].DependsOn = []string{output}
+}
+
 // StartControlLoop starts a PID control loop.
 func (p *PIDLoop) StartControlLoop() error {
 	loop, err := NewLoop(p.logger, *p.ControlConf, p.Controllable)
@@ -0,0 +1,133 @@
+package control
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/logging"
+)
+
+func blockByName(t *testing.T, cfg *Config, name string) BlockConfig {
+	t.Helper()
+	for _, b := range cfg.Blocks {
+		if b.Name == name {
+			return b
+		}
+	}
+	t.Fatalf("no block named %s", name)
+	return BlockConfig{}
+}
+
+func TestSetupPIDControlConfigOptions(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	schedule := []GainSchedulePoint{{At: 0, P: 1, I: 0.5}, {At: 100, P: 2, I: 1}}
+
+	t.Run("motor", func(t *testing.T) {
+		pl, err := SetupPIDControlConfig(
+			[]PIDConfig{{GainSchedule: schedule, KV: 0.5, MaxRate: 1000, AntiWindup: "back_calculation"}},
+			"motor", Options{PositionControlUsingTrapz: true}, nil, logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		// the gain schedule and feedforward follow the velocity profile
+		pid := blockByName(t, pl.ControlConf, "PID")
+		test.That(t, pid.Type, test.ShouldEqual, blockGainScheduledPID)
+		test.That(t, pid.DependsOn, test.ShouldResemble, []string{"sum", "trapz"})
+		test.That(t, pid.Attribute["anti_windup"], test.ShouldEqual, "back_calculation")
+		test.That(t, blockByName(t, pl.ControlConf, "feedforward").DependsOn, test.ShouldResemble, []string{"trapz"})
+		test.That(t, blockByName(t, pl.ControlConf, "feedforward_sum").DependsOn, test.ShouldResemble,
+			[]string{"PID", "feedforward"})
+		saturation := blockByName(t, pl.ControlConf, "saturation")
+		test.That(t, saturation.DependsOn, test.ShouldResemble, []string{"feedforward_sum"})
+		test.That(t, saturation.Attribute["max_rate"], test.ShouldEqual, 1000.0)
+		test.That(t, blockByName(t, pl.ControlConf, "gain").DependsOn, test.ShouldResemble, []string{"saturation"})
+	})
+
+	t.Run("base", func(t *testing.T) {
+		pl, err := SetupPIDControlConfig(
+			[]PIDConfig{{P: 1, I: 1, KV: 0.5}, {GainSchedule: schedule}},
+			"base", Options{SensorFeedback2DVelocityControl: true, ControllableType: "base_name"}, nil, logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		test.That(t, blockByName(t, pl.ControlConf, "linear_PID").Type, test.ShouldEqual, blockPID)
+		test.That(t, blockByName(t, pl.ControlConf, "linear_feedforward").DependsOn, test.ShouldResemble,
+			[]string{"linear_set_point"})
+		test.That(t, blockByName(t, pl.ControlConf, "linear_gain").DependsOn, test.ShouldResemble,
+			[]string{"linear_saturation"})
+
+		angularPID := blockByName(t, pl.ControlConf, "angular_PID")
+		test.That(t, angularPID.Type, test.ShouldEqual, blockGainScheduledPID)
+		test.That(t, angularPID.DependsOn, test.ShouldResemble, []string{"sum", "angular_set_point"})
+		test.That(t, blockByName(t, pl.ControlConf, "angular_gain").DependsOn, test.ShouldResemble, []string{"angular_PID"})
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := SetupPIDControlConfig([]PIDConfig{{KV: 0.5}}, "motor",
+			Options{PositionControlUsingTrapz: true, NeedsAutoTuning: true}, nil, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "cannot auto-tune")
+
+		_, err = SetupPIDControlConfig([]PIDConfig{{P: 1, AntiWindup: "hope"}}, "motor",
+			Options{PositionControlUsingTrapz: true}, nil, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldEqual, "pid block PID has an unknown anti_windup strategy hope")
+	})
+}
+
+// stateRecorder is a base which records the last state set by a control loop.
+type stateRecorder struct {
+	mu    sync.Mutex
+	state []float64
+}
+
+func (s *stateRecorder) SetState(ctx context.Context, state []*Signal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = s.state[:0]
+	for _, signal := range state {
+		s.state = append(s.state, signal.GetSignalValueAt(0))
+	}
+	return nil
+}
+
+func (s *stateRecorder) State(ctx context.Context) ([]float64, error) {
+	return []float64{0, 0}, nil
+}
+
+func (s *stateRecorder) lastState() []float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]float64{}, s.state...)
+}
+
+func TestSetupPIDControlConfigFeedforwardLoop(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	base := &stateRecorder{}
+	pl, err := SetupPIDControlConfig(
+		[]PIDConfig{{P: 0.001, KV: 100}, {GainSchedule: []GainSchedulePoint{{At: 0, P: 0.01}, {At: 20, P: 0.03}}}},
+		"base", Options{SensorFeedback2DVelocityControl: true, ControllableType: "base_name"}, base, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, pl.StartControlLoop(), test.ShouldBeNil)
+	defer pl.ControlLoop.Stop()
+	test.That(t, UpdateConstantBlock(ctx, "linear_set_point", 1, pl.ControlLoop), test.ShouldBeNil)
+	test.That(t, UpdateConstantBlock(ctx, "angular_set_point", 10, pl.ControlLoop), test.ShouldBeNil)
+
+	// the linear power is the feedforward on the set point plus the PID of the error, and the angular power is from
+	// the gains halfway through the schedule
+	testutils.WaitForAssertionWithSleep(t, 10*time.Millisecond, 500, func(tb testing.TB) {
+		tb.Helper()
+		state := base.lastState()
+		if len(state) != 2 {
+			tb.Errorf("expected a linear and angular power, got %v", state)
+			return
+		}
+		test.That(tb, state[0], test.ShouldAlmostEqual, (100+0.001)*rPiGain, 1e-9)
+		test.That(tb, state[1], test.ShouldAlmostEqual, 10*0.02*rPiGain, 1e-9)
+	})
+}
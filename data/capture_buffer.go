@@ -22,14 +22,22 @@ type CaptureBuffer struct {
 	nextFile           *CaptureFile
 	lock               sync.Mutex
 	maxCaptureFileSize int64
+	encryptionKey      CaptureKey
 }
 
 // NewCaptureBuffer returns a new Buffer.
 func NewCaptureBuffer(dir string, md *v1.DataCaptureMetadata, maxCaptureFileSize int64) *CaptureBuffer {
+	return NewEncryptedCaptureBuffer(dir, md, maxCaptureFileSize, nil)
+}
+
+// NewEncryptedCaptureBuffer returns a new Buffer whose capture files are encrypted with data keys wrapped by key, or
+// not encrypted if key is nil.
+func NewEncryptedCaptureBuffer(dir string, md *v1.DataCaptureMetadata, maxCaptureFileSize int64, key CaptureKey) *CaptureBuffer {
 	return &CaptureBuffer{
 		Directory:          dir,
 		MetaData:           md,
 		maxCaptureFileSize: maxCaptureFileSize,
+		encryptionKey:      key,
 	}
 }
 
@@ -55,7 +63,7 @@ func (b *CaptureBuffer) WriteBinary(item *v1.SensorData, mimeType string) error
 
 	// assign mime type to DataCaptureMetadata
 	b.MetaData.MimeType = mimeType
-	binFile, err := NewCaptureFile(b.Directory, b.MetaData)
+	binFile, err := NewEncryptedCaptureFile(b.Directory, b.MetaData, b.encryptionKey)
 	if err != nil {
 		return err
 	}
@@ -85,7 +93,7 @@ func (b *CaptureBuffer) WriteTabular(item *v1.SensorData) error {
 	}
 
 	if b.nextFile == nil {
-		nextFile, err := NewCaptureFile(b.Directory, b.MetaData)
+		nextFile, err := NewEncryptedCaptureFile(b.Directory, b.MetaData, b.encryptionKey)
 		if err != nil {
 			return err
 		}
@@ -94,7 +102,7 @@ func (b *CaptureBuffer) WriteTabular(item *v1.SensorData) error {
 		if err := b.nextFile.Close(); err != nil {
 			return err
 		}
-		nextFile, err := NewCaptureFile(b.Directory, b.MetaData)
+		nextFile, err := NewEncryptedCaptureFile(b.Directory, b.MetaData, b.encryptionKey)
 		if err != nil {
 			return err
 		}
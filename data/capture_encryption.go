@@ -0,0 +1,434 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Encrypted capture files use envelope encryption: each file has a random AES-256-GCM data key, which is stored in
+// the file wrapped by a CaptureKey. An encrypted capture file starts with encryptedCaptureFileMagic, followed by the
+// length delimited ID of the key which wrapped the data key, the length delimited wrapped data key and the length
+// delimited DataCaptureMetadata. The metadata stays in plaintext so that sync and retention can schedule and delete
+// files without a key which can decrypt them. Each SensorData after the metadata is length delimited and sealed with
+// the data key, prefixed by its random nonce. The key ID, the metadata and the index of the SensorData in the file are
+// authenticated as the additional data of each seal, so that neither can be changed, nor captures reordered or moved
+// between files, without failing decryption.
+
+// encryptedCaptureFileMagic starts every encrypted capture file. A plaintext capture file starts with the length of its
+// DataCaptureMetadata instead, which is never empty.
+var encryptedCaptureFileMagic = []byte("\x00VIAMENC\x01")
+
+const (
+	// captureDataKeySize is the size of the AES-256 data key of an encrypted capture file.
+	captureDataKeySize = 32
+	// maxCaptureKeyIDSize and maxWrappedDataKeySize bound the header fields of an encrypted capture file, so that a
+	// corrupt length can't allocate more than a few kilobytes.
+	maxCaptureKeyIDSize   = 256
+	maxWrappedDataKeySize = 4096
+)
+
+// ErrCaptureKeyMissing is returned when reading the captures of an encrypted capture file whose data key can't be
+// unwrapped by any registered CaptureKey.
+var ErrCaptureKeyMissing = errors.New("no registered capture key can decrypt the capture file")
+
+// CaptureKey wraps the data keys of encrypted capture files, and unwraps them to read the files.
+type CaptureKey interface {
+	// ID identifies the key without revealing it, so that files can be matched with the key which wrapped their data
+	// key.
+	ID() string
+	// WrapKey encrypts the data key of a capture file.
+	WrapKey(dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key encrypted with WrapKey. It returns an error wrapping ErrCaptureKeyMissing if the
+	// key can only wrap, such as a public key without its private key.
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+// captureKeys are the keys with which ReadCaptureFile decrypts encrypted capture files, by their ID.
+var captureKeys = struct {
+	sync.RWMutex
+	byID map[string]CaptureKey
+}{byID: map[string]CaptureKey{}}
+
+// RegisterCaptureKey makes ReadCaptureFile decrypt the capture files whose data key was wrapped by the key. Keys stay
+// registered, so that files written with a key which was since rotated out can still be read.
+func RegisterCaptureKey(key CaptureKey) {
+	captureKeys.Lock()
+	defer captureKeys.Unlock()
+	captureKeys.byID[key.ID()] = key
+}
+
+func lookupCaptureKey(id string) (CaptureKey, bool) {
+	captureKeys.RLock()
+	defer captureKeys.RUnlock()
+	key, ok := captureKeys.byID[id]
+	return key, ok
+}
+
+// LoadCaptureKeyFile loads a symmetric capture key from a file holding a 32 byte AES-256 key, either raw, or hex or
+// base64 encoded.
+func LoadCaptureKeyFile(path string) (CaptureKey, error) {
+	//nolint:gosec
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read capture key file")
+	}
+	key := contents
+	if len(key) != captureDataKeySize {
+		text := string(bytes.TrimSpace(contents))
+		if decoded, err := hex.DecodeString(text); err == nil {
+			key = decoded
+		} else if decoded, err := base64.StdEncoding.DecodeString(text); err == nil {
+			key = decoded
+		}
+	}
+	if len(key) != captureDataKeySize {
+		return nil, errors.Errorf("capture key file %s must hold a %d byte key", path, captureDataKeySize)
+	}
+	return NewSymmetricCaptureKey(key)
+}
+
+// NewSymmetricCaptureKey returns a capture key which wraps data keys with AES-256-GCM.
+func NewSymmetricCaptureKey(key []byte) (CaptureKey, error) {
+	aead, err := newCaptureAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &symmetricCaptureKey{id: "aes:" + hex.EncodeToString(sum[:8]), aead: aead}, nil
+}
+
+type symmetricCaptureKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+func (k *symmetricCaptureKey) ID() string {
+	return k.id
+}
+
+func (k *symmetricCaptureKey) WrapKey(dataKey []byte) ([]byte, error) {
+	return seal(k.aead, dataKey, nil)
+}
+
+func (k *symmetricCaptureKey) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return open(k.aead, wrapped, nil)
+}
+
+// LoadCaptureKeyPair loads an RSA capture key from PEM files. The public key wraps data keys with RSA-OAEP, so a
+// machine which is only given the public key can write encrypted capture files which it can't read. The private key
+// is needed to read them, and the public key may be omitted when it is given.
+func LoadCaptureKeyPair(publicKeyPath, privateKeyPath string) (CaptureKey, error) {
+	var key rsaCaptureKey
+	if privateKeyPath != "" {
+		block, err := readPEM(privateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse capture private key %s", privateKeyPath)
+		}
+		var ok bool
+		if key.private, ok = parsed.(*rsa.PrivateKey); !ok {
+			return nil, errors.Errorf("capture private key %s must be an RSA key, got %T", privateKeyPath, parsed)
+		}
+		key.public = &key.private.PublicKey
+	}
+	if publicKeyPath != "" {
+		block, err := readPEM(publicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse capture public key %s", publicKeyPath)
+		}
+		public, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.Errorf("capture public key %s must be an RSA key, got %T", publicKeyPath, parsed)
+		}
+		if key.private != nil && !key.private.PublicKey.Equal(public) {
+			return nil, errors.Errorf("capture public key %s does not match private key %s", publicKeyPath, privateKeyPath)
+		}
+		key.public = public
+	}
+	if key.public == nil {
+		return nil, errors.New("a capture public key or private key is required")
+	}
+	var err error
+	if key.id, err = rsaCaptureKeyID(key.public); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// NewRSACaptureKey returns a capture key which wraps data keys with the public key of the private key using RSA-OAEP.
+func NewRSACaptureKey(private *rsa.PrivateKey) (CaptureKey, error) {
+	id, err := rsaCaptureKeyID(&private.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &rsaCaptureKey{id: id, public: &private.PublicKey, private: private}, nil
+}
+
+func rsaCaptureKeyID(public *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return "rsa:" + hex.EncodeToString(sum[:8]), nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	//nolint:gosec
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read capture key")
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, errors.Errorf("capture key %s is not PEM encoded", path)
+	}
+	return block, nil
+}
+
+type rsaCaptureKey struct {
+	id      string
+	public  *rsa.PublicKey
+	private *rsa.PrivateKey
+}
+
+func (k *rsaCaptureKey) ID() string {
+	return k.id
+}
+
+func (k *rsaCaptureKey) WrapKey(dataKey []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, k.public, dataKey, nil)
+}
+
+func (k *rsaCaptureKey) UnwrapKey(wrapped []byte) ([]byte, error) {
+	if k.private == nil {
+		return nil, errors.Wrapf(ErrCaptureKeyMissing, "capture key %s is a public key, the private key is needed to decrypt", k.id)
+	}
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, k.private, wrapped, nil)
+}
+
+func newCaptureAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext and authenticates the additional data with a random nonce, which prefixes the ciphertext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted capture is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// captureEncryption is the state of an encrypted capture file.
+type captureEncryption struct {
+	keyID string
+	// header is the length delimited key ID and metadata of the file, which the additional data of each capture starts
+	// with.
+	header []byte
+	// aead seals and opens the captures of the file. It is nil when the data key could not be unwrapped.
+	aead cipher.AEAD
+	// err is why the data key could not be unwrapped.
+	err error
+}
+
+// writeEncryptedCaptureFileHeader generates a data key, and writes the header of an encrypted capture file with the
+// data key wrapped by the key, followed by the metadata.
+func writeEncryptedCaptureFileHeader(w io.Writer, key CaptureKey, md *v1.DataCaptureMetadata) (*captureEncryption, int, error) {
+	dataKey := make([]byte, captureDataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, 0, err
+	}
+	wrapped, err := key.WrapKey(dataKey)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to wrap capture data key")
+	}
+	aead, err := newCaptureAEAD(dataKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	mdBytes, err := proto.Marshal(md)
+	if err != nil {
+		return nil, 0, err
+	}
+	header := append([]byte{}, encryptedCaptureFileMagic...)
+	header = appendDelimited(header, []byte(key.ID()))
+	header = appendDelimited(header, wrapped)
+	header = appendDelimited(header, mdBytes)
+	n, err := w.Write(header)
+	if err != nil {
+		return nil, n, err
+	}
+	return &captureEncryption{keyID: key.ID(), header: authenticatedHeader(key.ID(), mdBytes), aead: aead}, n, nil
+}
+
+// readEncryptedCaptureFileHeader reads the header and metadata of a capture file of the size, and unwraps its data key
+// with the registered keys. It returns nil for plaintext capture files, leaving r at their metadata.
+func readEncryptedCaptureFileHeader(
+	r *bufio.Reader, path string, size int64,
+) (*captureEncryption, *v1.DataCaptureMetadata, int, error) {
+	magic, err := r.Peek(len(encryptedCaptureFileMagic))
+	if err != nil || !bytes.Equal(magic, encryptedCaptureFileMagic) {
+		// too short to be encrypted, so it's up to the metadata read to fail
+		return nil, nil, 0, nil
+	}
+	n, err := r.Discard(len(encryptedCaptureFileMagic))
+	if err != nil {
+		return nil, nil, n, err
+	}
+	keyID, read, err := readDelimited(r, maxCaptureKeyIDSize)
+	n += read
+	if err != nil {
+		return nil, nil, n, errors.Wrapf(err, "failed to read the key ID of encrypted capture file %s", path)
+	}
+	wrapped, read, err := readDelimited(r, maxWrappedDataKeySize)
+	n += read
+	if err != nil {
+		return nil, nil, n, errors.Wrapf(err, "failed to read the data key of encrypted capture file %s", path)
+	}
+	mdBytes, read, err := readDelimited(r, uint64(max(size-int64(n), 0)))
+	n += read
+	if err != nil {
+		return nil, nil, n, errors.Wrapf(err, "failed to read DataCaptureMetadata from %s", path)
+	}
+	md := &v1.DataCaptureMetadata{}
+	if err := proto.Unmarshal(mdBytes, md); err != nil {
+		return nil, nil, n, errors.Wrapf(err, "failed to read DataCaptureMetadata from %s", path)
+	}
+
+	enc := &captureEncryption{keyID: string(keyID), header: authenticatedHeader(string(keyID), mdBytes)}
+	key, ok := lookupCaptureKey(enc.keyID)
+	if !ok {
+		enc.err = errors.Wrapf(ErrCaptureKeyMissing, "capture file %s is encrypted with capture key %s, which is not configured",
+			path, enc.keyID)
+		return enc, md, n, nil
+	}
+	dataKey, err := key.UnwrapKey(wrapped)
+	if err != nil {
+		enc.err = errors.Wrapf(err, "failed to decrypt the data key of capture file %s", path)
+		return enc, md, n, nil
+	}
+	if enc.aead, err = newCaptureAEAD(dataKey); err != nil {
+		enc.err = err
+	}
+	return enc, md, n, nil
+}
+
+func authenticatedHeader(keyID string, md []byte) []byte {
+	return appendDelimited(appendDelimited(nil, []byte(keyID)), md)
+}
+
+// additionalData returns the data authenticated with the capture at the index of the file.
+func (enc *captureEncryption) additionalData(index uint64) []byte {
+	return binary.AppendUvarint(append([]byte{}, enc.header...), index)
+}
+
+// writeMessage writes the message at the index of the file length delimited, sealed with the data key of the file.
+func (enc *captureEncryption) writeMessage(w io.Writer, m proto.Message, index uint64) (int, error) {
+	plaintext, err := proto.Marshal(m)
+	if err != nil {
+		return 0, err
+	}
+	sealed, err := seal(enc.aead, plaintext, enc.additionalData(index))
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(appendDelimited(nil, sealed))
+}
+
+// readMessage reads the message at the index of the file written by writeMessage, which is no longer than limit. Like
+// pbutil.ReadDelimited, it returns io.EOF when there are no more messages.
+func (enc *captureEncryption) readMessage(r io.Reader, m proto.Message, index, limit uint64) (int, error) {
+	if enc.err != nil {
+		return 0, enc.err
+	}
+	sealed, n, err := readDelimited(r, limit)
+	if err != nil {
+		return n, err
+	}
+	plaintext, err := open(enc.aead, sealed, enc.additionalData(index))
+	if err != nil {
+		return n, errors.Wrap(err, "failed to decrypt capture")
+	}
+	return n, proto.Unmarshal(plaintext, m)
+}
+
+func appendDelimited(b, message []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(message)))
+	return append(b, message...)
+}
+
+// readDelimited reads a length delimited message no longer than limit. It returns io.EOF if r is empty, and
+// io.ErrUnexpectedEOF if the message is truncated or its length is over the limit, which keeps a corrupt length from
+// allocating more than the file could hold.
+func readDelimited(r io.Reader, limit uint64) ([]byte, int, error) {
+	var header [binary.MaxVarintLen64]byte
+	var n int
+	for ; n < len(header); n++ {
+		if _, err := io.ReadFull(r, header[n:n+1]); err != nil {
+			if n > 0 && errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, n, err
+		}
+		if header[n] < 0x80 {
+			n++
+			break
+		}
+	}
+	length, read := binary.Uvarint(header[:n])
+	if read <= 0 {
+		return nil, n, errors.New("invalid message length")
+	}
+	if length > limit {
+		return nil, n, errors.Wrapf(io.ErrUnexpectedEOF, "message length %d is over the limit of %d bytes", length, limit)
+	}
+	message := make([]byte, length)
+	read, err := io.ReadFull(r, message)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return message, n + read, err
+}
@@ -0,0 +1,226 @@
+package data
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+)
+
+func TestEncryptedCaptureFile(t *testing.T) {
+	md := &v1.DataCaptureMetadata{
+		ComponentName: "cam",
+		MethodName:    "ReadImage",
+		Type:          v1.DataType_DATA_TYPE_BINARY_SENSOR,
+		FileExtension: ".jpeg",
+	}
+	image := []byte("a secret image")
+	// writeFile writes an encrypted capture file with the image and returns its path.
+	writeFile := func(t *testing.T, key CaptureKey) string {
+		t.Helper()
+		dir := t.TempDir()
+		buf := NewEncryptedCaptureBuffer(dir, md, 256*1024, key)
+		test.That(t, buf.WriteBinary(&v1.SensorData{Data: &v1.SensorData_Binary{Binary: image}}, ""), test.ShouldBeNil)
+		paths, err := filepath.Glob(filepath.Join(dir, "*"+CompletedCaptureFileExt))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, paths, test.ShouldHaveLength, 1)
+		contents, err := os.ReadFile(paths[0])
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, bytes.Contains(contents, image), test.ShouldBeFalse)
+		return paths[0]
+	}
+	// readFile reads the capture file, and returns its metadata and captures.
+	readFile := func(t *testing.T, path string) (*CaptureFile, []*v1.SensorData, error) {
+		t.Helper()
+		//nolint:gosec
+		f, err := os.Open(path)
+		test.That(t, err, test.ShouldBeNil)
+		t.Cleanup(func() { test.That(t, f.Close(), test.ShouldBeNil) })
+		captureFile, err := ReadCaptureFile(f)
+		test.That(t, err, test.ShouldBeNil)
+		sensorData, err := SensorDataFromCaptureFile(captureFile)
+		return captureFile, sensorData, err
+	}
+
+	t.Run("symmetric key", func(t *testing.T) {
+		dataKey := make([]byte, 32)
+		_, err := rand.Read(dataKey)
+		test.That(t, err, test.ShouldBeNil)
+		key, err := NewSymmetricCaptureKey(dataKey)
+		test.That(t, err, test.ShouldBeNil)
+		path := writeFile(t, key)
+
+		// the metadata can be read without the key, but the captures can't
+		captureFile, _, err := readFile(t, path)
+		test.That(t, captureFile.Encrypted(), test.ShouldBeTrue)
+		test.That(t, captureFile.ReadMetadata().GetComponentName(), test.ShouldEqual, "cam")
+		test.That(t, errors.Is(captureFile.CanDecrypt(), ErrCaptureKeyMissing), test.ShouldBeTrue)
+		test.That(t, errors.Is(err, ErrCaptureKeyMissing), test.ShouldBeTrue)
+		test.That(t, err.Error(), test.ShouldContainSubstring, key.ID())
+
+		RegisterCaptureKey(key)
+		captureFile, sensorData, err := readFile(t, path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, captureFile.CanDecrypt(), test.ShouldBeNil)
+		test.That(t, sensorData, test.ShouldHaveLength, 1)
+		test.That(t, sensorData[0].GetBinary(), test.ShouldResemble, image)
+	})
+
+	t.Run("public key", func(t *testing.T) {
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		test.That(t, err, test.ShouldBeNil)
+		keyDir := t.TempDir()
+		publicPath := filepath.Join(keyDir, "capture.pub")
+		privatePath := filepath.Join(keyDir, "capture.pem")
+		publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600),
+			test.ShouldBeNil)
+		test.That(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{
+			Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private),
+		}), 0o600), test.ShouldBeNil)
+
+		publicKey, err := LoadCaptureKeyPair(publicPath, "")
+		test.That(t, err, test.ShouldBeNil)
+		path := writeFile(t, publicKey)
+
+		// a machine with only the public key can't read the files it wrote
+		RegisterCaptureKey(publicKey)
+		_, _, err = readFile(t, path)
+		test.That(t, errors.Is(err, ErrCaptureKeyMissing), test.ShouldBeTrue)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "private key is needed")
+
+		keyPair, err := LoadCaptureKeyPair(publicPath, privatePath)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, keyPair.ID(), test.ShouldEqual, publicKey.ID())
+		RegisterCaptureKey(keyPair)
+		_, sensorData, err := readFile(t, path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, sensorData[0].GetBinary(), test.ShouldResemble, image)
+
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		test.That(t, err, test.ShouldBeNil)
+		otherPath := filepath.Join(keyDir, "other.pem")
+		test.That(t, os.WriteFile(otherPath, pem.EncodeToMemory(&pem.Block{
+			Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(other),
+		}), 0o600), test.ShouldBeNil)
+		_, err = LoadCaptureKeyPair(publicPath, otherPath)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "does not match")
+	})
+
+	t.Run("tampering", func(t *testing.T) {
+		dataKey := make([]byte, 32)
+		_, err := rand.Read(dataKey)
+		test.That(t, err, test.ShouldBeNil)
+		key, err := NewSymmetricCaptureKey(dataKey)
+		test.That(t, err, test.ShouldBeNil)
+		RegisterCaptureKey(key)
+
+		dir := t.TempDir()
+		f, err := NewEncryptedCaptureFile(dir, md, key)
+		test.That(t, err, test.ShouldBeNil)
+		for _, b := range []string{"first", "second"} {
+			test.That(t, f.WriteNext(&v1.SensorData{Data: &v1.SensorData_Binary{Binary: []byte(b)}}), test.ShouldBeNil)
+		}
+		headerSize := f.initialReadOffset
+		test.That(t, f.Close(), test.ShouldBeNil)
+		paths, err := filepath.Glob(filepath.Join(dir, "*"+CompletedCaptureFileExt))
+		test.That(t, err, test.ShouldBeNil)
+		contents, err := os.ReadFile(paths[0])
+		test.That(t, err, test.ShouldBeNil)
+		_, sensorData, err := readFile(t, paths[0])
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, sensorData, test.ShouldHaveLength, 2)
+
+		// rewrite writes the contents to a new capture file and returns the error of reading its captures
+		rewrite := func(t *testing.T, contents []byte) error {
+			t.Helper()
+			path := filepath.Join(t.TempDir(), "tampered"+CompletedCaptureFileExt)
+			test.That(t, os.WriteFile(path, contents, 0o600), test.ShouldBeNil)
+			_, _, err := readFile(t, path)
+			return err
+		}
+
+		// the metadata is authenticated
+		// the metadata ends the header, after the random wrapped data key
+		tampered := append([]byte{}, contents...)
+		i := bytes.LastIndex(tampered[:headerSize], []byte("cam"))
+		test.That(t, i, test.ShouldBeGreaterThan, 0)
+		tampered[i+2] = 'r'
+		err = rewrite(t, tampered)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "failed to decrypt capture")
+
+		// and so is the order of the captures
+		first, n, err := readDelimited(bytes.NewReader(contents[headerSize:]), uint64(len(contents)))
+		test.That(t, err, test.ShouldBeNil)
+		second := contents[headerSize+int64(n):]
+		reordered := append(append([]byte{}, contents[:headerSize]...), second...)
+		reordered = appendDelimited(reordered, first)
+		test.That(t, reordered, test.ShouldHaveLength, len(contents))
+		err = rewrite(t, reordered)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "failed to decrypt capture")
+
+		// a corrupt length reads as a truncated file rather than allocating it
+		corrupt := append(append([]byte{}, contents[:headerSize]...), binary.AppendUvarint(nil, 1<<60)...)
+		_, _, err = readDelimited(bytes.NewReader(corrupt[headerSize:]), uint64(len(contents)))
+		test.That(t, errors.Is(err, io.ErrUnexpectedEOF), test.ShouldBeTrue)
+		test.That(t, rewrite(t, corrupt), test.ShouldBeNil)
+	})
+
+	t.Run("plaintext", func(t *testing.T) {
+		dir := t.TempDir()
+		f, err := NewCaptureFile(dir, md)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.WriteNext(&v1.SensorData{Data: &v1.SensorData_Binary{Binary: image}}), test.ShouldBeNil)
+		test.That(t, f.Close(), test.ShouldBeNil)
+		paths, err := filepath.Glob(filepath.Join(dir, "*"+CompletedCaptureFileExt))
+		test.That(t, err, test.ShouldBeNil)
+		captureFile, sensorData, err := readFile(t, paths[0])
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, captureFile.Encrypted(), test.ShouldBeFalse)
+		test.That(t, sensorData[0].GetBinary(), test.ShouldResemble, image)
+	})
+}
+
+func TestLoadCaptureKeyFile(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	test.That(t, err, test.ShouldBeNil)
+	expected, err := NewSymmetricCaptureKey(key)
+	test.That(t, err, test.ShouldBeNil)
+
+	dir := t.TempDir()
+	for name, contents := range map[string][]byte{
+		"raw":    key,
+		"hex":    []byte(hex.EncodeToString(key) + "\n"),
+		"base64": []byte(base64.StdEncoding.EncodeToString(key) + "\n"),
+	} {
+		path := filepath.Join(dir, name)
+		test.That(t, os.WriteFile(path, contents, 0o600), test.ShouldBeNil)
+		loaded, err := LoadCaptureKeyFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, loaded.ID(), test.ShouldEqual, expected.ID())
+	}
+
+	path := filepath.Join(dir, "short")
+	test.That(t, os.WriteFile(path, []byte("too short"), 0o600), test.ShouldBeNil)
+	_, err = LoadCaptureKeyFile(path)
+	test.That(t, err, test.ShouldBeError, "capture key file "+path+" must hold a 32 byte key")
+	_, err = LoadCaptureKeyFile(filepath.Join(dir, "missing"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
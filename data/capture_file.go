@@ -11,6 +11,7 @@ import (
 
 	"github.com/matttproud/golang_protobuf_extensions/pbutil"
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	v1 "go.viam.com/api/app/datasync/v1"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -40,7 +41,8 @@ const (
 
 // CaptureFile is the data structure containing data captured by collectors. It is backed by a file on disk containing
 // length delimited protobuf messages, where the first message is the CaptureMetadata for the file, and ensuing
-// messages contain the captured data.
+// messages contain the captured data. The captured data of encrypted capture files is sealed with the data key of the
+// file, see CaptureKey.
 type CaptureFile struct {
 	path     string
 	lock     sync.Mutex
@@ -48,13 +50,22 @@ type CaptureFile struct {
 	writer   *bufio.Writer
 	size     int64
 	metadata *v1.DataCaptureMetadata
+	// encryption is nil for plaintext capture files.
+	encryption *captureEncryption
 
 	initialReadOffset int64
 	readOffset        int64
 	writeOffset       int64
+	// readIndex and writeIndex are the indexes of the next captures read and written, which encrypted capture files
+	// authenticate.
+	readIndex  uint64
+	writeIndex uint64
 }
 
 // ReadCaptureFile creates a File struct from a passed os.File previously constructed using NewFile.
+// The captures of encrypted capture files are decrypted with the keys registered with RegisterCaptureKey. If none of
+// them can decrypt the file, its metadata can still be read, but CanDecrypt and reading the captures return an error
+// wrapping ErrCaptureKeyMissing.
 func ReadCaptureFile(f *os.File) (*CaptureFile, error) {
 	if !IsDataCaptureFile(f) {
 		return nil, errors.Errorf("%s is not a data capture file", f.Name())
@@ -64,10 +75,16 @@ func ReadCaptureFile(f *os.File) (*CaptureFile, error) {
 		return nil, err
 	}
 
-	md := &v1.DataCaptureMetadata{}
-	initOffset, err := pbutil.ReadDelimited(f, md)
+	r := bufio.NewReader(f)
+	encryption, md, initOffset, err := readEncryptedCaptureFileHeader(r, f.Name(), finfo.Size())
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read DataCaptureMetadata from %s", f.Name())
+		return nil, err
+	}
+	if encryption == nil {
+		md = &v1.DataCaptureMetadata{}
+		if initOffset, err = pbutil.ReadDelimited(r, md); err != nil {
+			return nil, errors.Wrapf(err, "failed to read DataCaptureMetadata from %s", f.Name())
+		}
 	}
 
 	ret := CaptureFile{
@@ -76,6 +93,7 @@ func ReadCaptureFile(f *os.File) (*CaptureFile, error) {
 		writer:            bufio.NewWriter(f),
 		size:              finfo.Size(),
 		metadata:          md,
+		encryption:        encryption,
 		initialReadOffset: int64(initOffset),
 		readOffset:        int64(initOffset),
 		writeOffset:       int64(initOffset),
@@ -86,6 +104,12 @@ func ReadCaptureFile(f *os.File) (*CaptureFile, error) {
 
 // NewCaptureFile creates a new *CaptureFile with the specified md in the specified directory.
 func NewCaptureFile(dir string, md *v1.DataCaptureMetadata) (*CaptureFile, error) {
+	return NewEncryptedCaptureFile(dir, md, nil)
+}
+
+// NewEncryptedCaptureFile creates a new *CaptureFile with the specified md in the specified directory, whose captures
+// are encrypted with a new data key wrapped by key. The file is not encrypted if key is nil.
+func NewEncryptedCaptureFile(dir string, md *v1.DataCaptureMetadata, key CaptureKey) (*CaptureFile, error) {
 	fileName := CaptureFilePathWithReplacedReservedChars(
 		filepath.Join(dir, getFileTimestampName()) + InProgressCaptureFileExt)
 	//nolint:gosec
@@ -94,9 +118,15 @@ func NewCaptureFile(dir string, md *v1.DataCaptureMetadata) (*CaptureFile, error
 		return nil, err
 	}
 
-	// Then write first metadata message to the file.
-	n, err := pbutil.WriteDelimited(f, md)
-	if err != nil {
+	var encryption *captureEncryption
+	var n int
+	if key != nil {
+		// the header of an encrypted capture file includes the metadata
+		if encryption, n, err = writeEncryptedCaptureFileHeader(f, key, md); err != nil {
+			return nil, multierr.Combine(err, f.Close(), os.Remove(fileName))
+		}
+	} else if n, err = pbutil.WriteDelimited(f, md); err != nil {
+		// Then write first metadata message to the file.
 		return nil, err
 	}
 	return &CaptureFile{
@@ -104,6 +134,7 @@ func NewCaptureFile(dir string, md *v1.DataCaptureMetadata) (*CaptureFile, error
 		writer:            bufio.NewWriter(f),
 		file:              f,
 		size:              int64(n),
+		encryption:        encryption,
 		initialReadOffset: int64(n),
 		readOffset:        int64(n),
 		writeOffset:       int64(n),
@@ -115,6 +146,20 @@ func (f *CaptureFile) ReadMetadata() *v1.DataCaptureMetadata {
 	return f.metadata
 }
 
+// Encrypted returns whether the captures of f are encrypted.
+func (f *CaptureFile) Encrypted() bool {
+	return f.encryption != nil
+}
+
+// CanDecrypt returns an error wrapping ErrCaptureKeyMissing if the captures of f are encrypted, and none of the
+// registered keys can decrypt them.
+func (f *CaptureFile) CanDecrypt() error {
+	if f.encryption == nil {
+		return nil
+	}
+	return f.encryption.err
+}
+
 // ReadNext returns the next SensorData reading.
 func (f *CaptureFile) ReadNext() (*v1.SensorData, error) {
 	f.lock.Lock()
@@ -128,11 +173,18 @@ func (f *CaptureFile) ReadNext() (*v1.SensorData, error) {
 		return nil, err
 	}
 	r := v1.SensorData{}
-	read, err := pbutil.ReadDelimited(f.file, &r)
+	var read int
+	var err error
+	if f.encryption != nil {
+		read, err = f.encryption.readMessage(f.file, &r, f.readIndex, uint64(max(f.size-f.readOffset, 0)))
+	} else {
+		read, err = pbutil.ReadDelimited(f.file, &r)
+	}
 	if err != nil {
 		return nil, err
 	}
 	f.readOffset += int64(read)
+	f.readIndex++
 
 	return &r, nil
 }
@@ -145,12 +197,19 @@ func (f *CaptureFile) WriteNext(data *v1.SensorData) error {
 	if _, err := f.file.Seek(f.writeOffset, 0); err != nil {
 		return err
 	}
-	n, err := pbutil.WriteDelimited(f.writer, data)
+	var n int
+	var err error
+	if f.encryption != nil {
+		n, err = f.encryption.writeMessage(f.writer, data, f.writeIndex)
+	} else {
+		n, err = pbutil.WriteDelimited(f.writer, data)
+	}
 	if err != nil {
 		return err
 	}
 	f.size += int64(n)
 	f.writeOffset += int64(n)
+	f.writeIndex++
 	return nil
 }
 
@@ -166,6 +225,7 @@ func (f *CaptureFile) Reset() {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 	f.readOffset = f.initialReadOffset
+	f.readIndex = 0
 }
 
 // Size returns the size of the file.
@@ -0,0 +1,124 @@
+package data
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+
+	rutils "go.viam.com/rdk/utils"
+)
+
+// Deadband is the band around the last written value of a numeric field within which a new value does not count as a
+// change. The band is the larger of Absolute and Relative times the magnitude of the last written value, so a zero
+// Deadband counts every change.
+type Deadband struct {
+	Absolute float64
+	Relative float64
+}
+
+func (d Deadband) contains(last, value float64) bool {
+	return math.Abs(value-last) <= math.Max(d.Absolute, d.Relative*math.Abs(last))
+}
+
+// DeadbandBuffer is a CaptureBufferedWriter which only writes a tabular capture to its target when one of its fields
+// changed since the last capture it wrote. Numeric fields change when they leave their Deadband, and all other fields
+// whenever they differ. A capture is also written when MaxSilence has passed since the last written one, so that
+// slowly varying data still has a heartbeat. Binary captures are always written.
+type DeadbandBuffer struct {
+	target CaptureBufferedWriter
+	// fields are the deadbands of fields by their path in the payload, like "readings.power"
+	fields          map[string]Deadband
+	defaultDeadband Deadband
+	maxSilence      time.Duration
+
+	mu          sync.Mutex
+	last        map[string]interface{}
+	lastWritten time.Time
+}
+
+// NewDeadbandBuffer returns a DeadbandBuffer which writes the changed tabular captures to target. The fields map the
+// path of a field in the payload, with nested keys and list indexes separated by dots, to its deadband. Fields which
+// are not in the map use defaultDeadband. A zero maxSilence disables the heartbeat.
+func NewDeadbandBuffer(
+	target CaptureBufferedWriter,
+	fields map[string]Deadband,
+	defaultDeadband Deadband,
+	maxSilence time.Duration,
+) *DeadbandBuffer {
+	return &DeadbandBuffer{
+		target:          target,
+		fields:          fields,
+		defaultDeadband: defaultDeadband,
+		maxSilence:      maxSilence,
+	}
+}
+
+// WriteBinary writes the item to the target.
+func (b *DeadbandBuffer) WriteBinary(item *v1.SensorData, mimeType string) error {
+	return b.target.WriteBinary(item, mimeType)
+}
+
+// WriteTabular writes the item to the target if it changed since the last written item or if MaxSilence has passed
+// since then, and otherwise drops it.
+func (b *DeadbandBuffer) WriteTabular(item *v1.SensorData) error {
+	if IsBinary(item) {
+		return errInvalidTabularSensorData
+	}
+	values := rutils.FlattenReadings(item.GetStruct().AsMap())
+	capturedAt := item.GetMetadata().GetTimeReceived().AsTime()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	silent := b.maxSilence > 0 && capturedAt.Sub(b.lastWritten) >= b.maxSilence
+	if b.last != nil && !silent && !b.changedLocked(values) {
+		return nil
+	}
+	if err := b.target.WriteTabular(item); err != nil {
+		return err
+	}
+	b.last = values
+	b.lastWritten = capturedAt
+	return nil
+}
+
+// changedLocked returns whether any of the values changed since the last written ones.
+func (b *DeadbandBuffer) changedLocked(values map[string]interface{}) bool {
+	if len(values) != len(b.last) {
+		return true
+	}
+	for path, value := range values {
+		last, ok := b.last[path]
+		if !ok {
+			return true
+		}
+		// the leaves of a struct are numbers, strings, bools or nil, which are all comparable
+		lastNumber, lastIsNumber := last.(float64)
+		number, isNumber := value.(float64)
+		if !lastIsNumber || !isNumber {
+			if last != value {
+				return true
+			}
+			continue
+		}
+		deadband, ok := b.fields[path]
+		if !ok {
+			deadband = b.defaultDeadband
+		}
+		if !deadband.contains(lastNumber, number) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush flushes the captures which have been written to the target.
+func (b *DeadbandBuffer) Flush() error {
+	return b.target.Flush()
+}
+
+// Path returns the path of the target.
+func (b *DeadbandBuffer) Path() string {
+	return b.target.Path()
+}
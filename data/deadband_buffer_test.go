@@ -0,0 +1,69 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestDeadbandBuffer(t *testing.T) {
+	target := &recordingBuffer{}
+	buf := NewDeadbandBuffer(target, map[string]Deadband{
+		"readings.power": {Absolute: 1},
+		"readings.pos.1": {Relative: 0.1},
+	}, Deadband{}, 10*time.Second)
+
+	start := time.Now()
+	// write writes a reading captured at the given second and returns whether it reached the target.
+	write := func(sec int, readings map[string]interface{}) bool {
+		t.Helper()
+		payload, err := structpb.NewStruct(map[string]interface{}{"readings": readings})
+		test.That(t, err, test.ShouldBeNil)
+		item := &v1.SensorData{
+			Metadata: &v1.SensorMetadata{TimeReceived: timestamppb.New(start.Add(time.Duration(sec) * time.Second))},
+			Data:     &v1.SensorData_Struct{Struct: payload},
+		}
+		written := len(target.written)
+		test.That(t, buf.WriteTabular(item), test.ShouldBeNil)
+		return len(target.written) > written
+	}
+	reading := func(power, pos float64, state string) map[string]interface{} {
+		return map[string]interface{}{"power": power, "pos": []interface{}{0, pos}, "state": state}
+	}
+
+	// the first capture is always written
+	test.That(t, write(0, reading(100, 10, "on")), test.ShouldBeTrue)
+	// within the deadbands
+	test.That(t, write(1, reading(100, 10, "on")), test.ShouldBeFalse)
+	test.That(t, write(2, reading(100.9, 10.9, "on")), test.ShouldBeFalse)
+	// the deadband is around the last written value rather than the last captured one
+	test.That(t, write(3, reading(101.5, 10, "on")), test.ShouldBeTrue)
+	test.That(t, write(4, reading(100.9, 10, "on")), test.ShouldBeFalse)
+	// the relative deadband of pos.1 is a tenth of 10
+	test.That(t, write(5, reading(101.5, 11.1, "on")), test.ShouldBeTrue)
+	// the default deadband counts any change
+	test.That(t, write(6, map[string]interface{}{"power": 101.5, "pos": []interface{}{0.001, 11.1}, "state": "on"}), test.ShouldBeTrue)
+	test.That(t, write(7, map[string]interface{}{"power": 101.5, "pos": []interface{}{0.001, 11.1}, "state": "off"}), test.ShouldBeTrue)
+	// a field which appears or disappears is a change
+	test.That(t, write(8, map[string]interface{}{"power": 101.5, "pos": []interface{}{0.001, 11.1}}), test.ShouldBeTrue)
+	test.That(t, write(9, map[string]interface{}{"power": 101.5, "pos": []interface{}{0.001, 11.1}}), test.ShouldBeFalse)
+	// heartbeat after ten seconds of silence
+	test.That(t, write(17, map[string]interface{}{"power": 101.5, "pos": []interface{}{0.001, 11.1}}), test.ShouldBeFalse)
+	test.That(t, write(18, map[string]interface{}{"power": 101.5, "pos": []interface{}{0.001, 11.1}}), test.ShouldBeTrue)
+	test.That(t, len(target.written), test.ShouldEqual, 7)
+
+	// binary captures are always written
+	binary := &v1.SensorData{Metadata: &v1.SensorMetadata{}, Data: &v1.SensorData_Binary{Binary: []byte{1}}}
+	test.That(t, buf.WriteBinary(binary, "image/jpeg"), test.ShouldBeNil)
+	test.That(t, buf.WriteBinary(binary, "image/jpeg"), test.ShouldBeNil)
+	test.That(t, len(target.written), test.ShouldEqual, 9)
+	test.That(t, buf.WriteTabular(binary), test.ShouldBeError, errInvalidTabularSensorData)
+
+	test.That(t, buf.Flush(), test.ShouldBeNil)
+	test.That(t, target.flushes, test.ShouldEqual, 1)
+	test.That(t, buf.Path(), test.ShouldEqual, "recording")
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/data"
+)
+
+// csvTimeColumns are the leading columns of every exported CSV file.
+var csvTimeColumns = []string{"time_requested", "time_received", "tags"}
+
+// writeCSVGroup writes the captures of a resource and method to a CSV file in dir and returns its path, or "" if the
+// group has no captures.
+//
+// Each reading of a tabular capture is a column named by its dotted path. Binary captures are written to a directory
+// named like the CSV file, and the "file" column references them relative to the CSV file.
+func writeCSVGroup(dir string, group rowGroup) (string, error) {
+	path := filepath.Join(dir, group.baseName()+".csv")
+	var written int
+	err := writeFile(path, func(f *os.File) error {
+		w := csv.NewWriter(f)
+		var err error
+		if group.binary {
+			written, err = writeBinaryCSV(w, filepath.Join(dir, group.baseName()), group)
+		} else {
+			written, err = writeTabularCSV(w, group)
+		}
+		if err != nil {
+			return err
+		}
+		w.Flush()
+		return w.Error()
+	})
+	if err != nil {
+		return "", err
+	}
+	if written == 0 {
+		return "", removeFile(path)
+	}
+	return path, nil
+}
+
+// writeTabularCSV reads the captures of the group twice, first for the columns and then for the records, and returns
+// the number of records it wrote.
+func writeTabularCSV(w *csv.Writer, group rowGroup) (int, error) {
+	columns, count, err := readingColumns(group)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	header := slices.Clone(csvTimeColumns)
+	for _, column := range columns {
+		header = append(header, column.name)
+	}
+	if err := w.Write(header); err != nil {
+		return 0, err
+	}
+	var written int
+	err = group.each(func(rows []data.CaptureRow) error {
+		for _, row := range rows {
+			leaves := flattenReadings(row)
+			record := csvTimeRecord(row)
+			for _, column := range columns {
+				record = append(record, formatValue(leaves[column.name]))
+			}
+			if err := w.Write(record); err != nil {
+				return err
+			}
+			written++
+		}
+		return nil
+	})
+	return written, err
+}
+
+// writeBinaryCSV writes the binary captures of the group to binaryDir and returns the number of records it wrote.
+func writeBinaryCSV(w *csv.Writer, binaryDir string, group rowGroup) (int, error) {
+	var written int
+	err := group.each(func(rows []data.CaptureRow) error {
+		if written == 0 {
+			if err := os.MkdirAll(binaryDir, 0o700); err != nil {
+				return errors.Wrapf(err, "failed to create directory %s", binaryDir)
+			}
+			if err := w.Write(append(slices.Clone(csvTimeColumns), "file")); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			name := timeRequested(row).UTC().Format("2006-01-02T15_04_05.000000000Z") + row.Metadata.GetFileExtension()
+			path := filepath.Join(binaryDir, name)
+			if err := os.WriteFile(path, row.SensorData.GetBinary(), 0o600); err != nil {
+				return errors.Wrapf(err, "failed to write binary capture to %s", path)
+			}
+			if err := w.Write(append(csvTimeRecord(row), filepath.Join(filepath.Base(binaryDir), name))); err != nil {
+				return err
+			}
+			written++
+		}
+		return nil
+	})
+	return written, err
+}
+
+func csvTimeRecord(row data.CaptureRow) []string {
+	return []string{
+		timeRequested(row).UTC().Format(time.RFC3339Nano),
+		timeReceived(row).UTC().Format(time.RFC3339Nano),
+		strings.Join(row.Metadata.GetTags(), ","),
+	}
+}
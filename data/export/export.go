@@ -0,0 +1,318 @@
+// Package export converts the captures in a capture directory to formats which analysis tools understand: MCAP for
+// Foxglove, and CSV and Parquet for pandas and the like.
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/utils"
+)
+
+// Format is a format to which captures can be exported.
+type Format string
+
+const (
+	// FormatMCAP exports every capture into a single MCAP file with a channel per resource and method. Images are
+	// foxglove.CompressedImage protobuf messages and tabular readings are JSON messages with a JSON schema.
+	FormatMCAP Format = "mcap"
+	// FormatCSV exports the captures of each resource and method into a CSV file. Binary captures are written to a
+	// directory next to the CSV file, which references them.
+	FormatCSV Format = "csv"
+	// FormatParquet exports the tabular captures of each resource and method into a Parquet file. Binary captures are
+	// skipped.
+	FormatParquet Format = "parquet"
+)
+
+// Formats are the formats to which captures can be exported.
+var Formats = []Format{FormatMCAP, FormatCSV, FormatParquet}
+
+// CaptureDir exports the captures in the capture directory which match the query to the destination directory in the
+// format, and returns the paths of the files it wrote. Without a limit, captures are read one capture file at a time,
+// so that exporting doesn't hold every capture in memory.
+func CaptureDir(captureDir, destination string, format Format, query data.CaptureQuery) ([]string, error) {
+	if !slices.Contains(Formats, format) {
+		return nil, errors.Errorf("unknown export format %q", format)
+	}
+	idx, err := data.NewCaptureIndex(captureDir)
+	if err != nil {
+		return nil, err
+	}
+	var groups []rowGroup
+	if query.Limit > 0 {
+		// the first captures of the whole directory may be in any of its files, so they are queried up front
+		rows, err := idx.Query(query)
+		if err != nil {
+			return nil, err
+		}
+		groups = groupRows(rows)
+	} else {
+		groups = groupFiles(idx.Matching(query), query)
+	}
+	if err := os.MkdirAll(destination, 0o700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create destination directory %s", destination)
+	}
+	switch format {
+	case FormatMCAP:
+		path := filepath.Join(destination, "captures.mcap")
+		var empty bool
+		err := writeFile(path, func(f *os.File) error {
+			var err error
+			empty, err = writeMCAP(f, groups)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			return nil, removeFile(path)
+		}
+		return []string{path}, nil
+	case FormatCSV:
+		return writeGroups(groups, func(group rowGroup) (string, error) {
+			return writeCSVGroup(destination, group)
+		})
+	default:
+		return writeGroups(groups, func(group rowGroup) (string, error) {
+			if group.binary {
+				return "", nil
+			}
+			return writeParquetGroup(destination, group)
+		})
+	}
+}
+
+func writeFile(path string, write func(f *os.File) error) (err error) {
+	//nolint:gosec
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", path)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return write(f)
+}
+
+func removeFile(path string) error {
+	return errors.Wrapf(os.Remove(path), "failed to remove %s", path)
+}
+
+func writeGroups(groups []rowGroup, write func(group rowGroup) (string, error)) ([]string, error) {
+	var paths []string
+	for _, group := range groups {
+		path, err := write(group)
+		if err != nil {
+			return paths, err
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// rowGroup is the captures of a resource and method. They are either read from the capture files of the group, which
+// are ordered by when they were last written, or held in rows.
+type rowGroup struct {
+	componentType string
+	componentName string
+	method        string
+	fileExtension string
+	binary        bool
+	files         []data.CaptureIndexEntry
+	query         data.CaptureQuery
+	rows          []data.CaptureRow
+}
+
+// baseName is the name of the files which the group is exported to, without an extension.
+func (g rowGroup) baseName() string {
+	return sanitizeFileName(g.componentName + "_" + g.method)
+}
+
+func (g rowGroup) topic() string {
+	return "/" + g.componentName + "/" + g.method
+}
+
+// tags returns the sorted tags of the capture files of the group.
+func (g rowGroup) tags() []string {
+	var tags []string
+	add := func(md *v1.DataCaptureMetadata) {
+		for _, tag := range md.GetTags() {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	for _, file := range g.files {
+		add(file.Metadata)
+	}
+	for _, row := range g.rows {
+		add(row.Metadata)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// each calls fn with the captures of the group, a capture file at a time. It skips the files without matching
+// captures.
+func (g rowGroup) each(fn func(rows []data.CaptureRow) error) error {
+	if g.files == nil {
+		return fn(g.rows)
+	}
+	for _, file := range g.files {
+		rows, err := file.Captures(g.query)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupFiles groups the capture files by their resource and method, ordered by their topic.
+func groupFiles(files []data.CaptureIndexEntry, query data.CaptureQuery) []rowGroup {
+	files = slices.Clone(files)
+	sort.SliceStable(files, func(i, j int) bool { return files[i].ModTime.Before(files[j].ModTime) })
+	return groupBy(files, func(file data.CaptureIndexEntry) *v1.DataCaptureMetadata { return file.Metadata },
+		func(group *rowGroup, file data.CaptureIndexEntry) {
+			group.query = query
+			group.files = append(group.files, file)
+		})
+}
+
+// groupRows groups the rows by their resource and method, ordered by their topic.
+func groupRows(rows []data.CaptureRow) []rowGroup {
+	return groupBy(rows, func(row data.CaptureRow) *v1.DataCaptureMetadata { return row.Metadata },
+		func(group *rowGroup, row data.CaptureRow) { group.rows = append(group.rows, row) })
+}
+
+func groupBy[T any](items []T, metadata func(T) *v1.DataCaptureMetadata, add func(group *rowGroup, item T)) []rowGroup {
+	var groups []rowGroup
+	indexes := map[string]int{}
+	for _, item := range items {
+		md := metadata(item)
+		key := md.GetComponentType() + "/" + md.GetComponentName() + "/" + md.GetMethodName()
+		i, ok := indexes[key]
+		if !ok {
+			i = len(groups)
+			indexes[key] = i
+			groups = append(groups, rowGroup{
+				componentType: md.GetComponentType(),
+				componentName: md.GetComponentName(),
+				method:        md.GetMethodName(),
+				fileExtension: md.GetFileExtension(),
+				binary:        md.GetType() == v1.DataType_DATA_TYPE_BINARY_SENSOR,
+			})
+		}
+		add(&groups[i], item)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].topic() != groups[j].topic() {
+			return groups[i].topic() < groups[j].topic()
+		}
+		return groups[i].componentType < groups[j].componentType
+	})
+	return groups
+}
+
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func timeRequested(row data.CaptureRow) time.Time {
+	return row.SensorData.GetMetadata().GetTimeRequested().AsTime()
+}
+
+func timeReceived(row data.CaptureRow) time.Time {
+	return row.SensorData.GetMetadata().GetTimeReceived().AsTime()
+}
+
+// flattenReadings returns the leaf values of the tabular data of a capture by their dotted path.
+func flattenReadings(row data.CaptureRow) map[string]interface{} {
+	return utils.FlattenReadings(row.SensorData.GetStruct().AsMap())
+}
+
+// readingColumn is a reading of the tabular captures of a group, named by its dotted path.
+type readingColumn struct {
+	name string
+	// numbers and bools are whether every non-null value of the reading is a number or a boolean
+	numbers bool
+	bools   bool
+}
+
+// readingColumns reads the tabular captures of the group and returns their readings, sorted by name, along with the
+// number of captures.
+func readingColumns(group rowGroup) ([]readingColumn, int, error) {
+	byName := map[string]*readingColumn{}
+	var count int
+	err := group.each(func(rows []data.CaptureRow) error {
+		for _, row := range rows {
+			count++
+			for name, value := range flattenReadings(row) {
+				column, ok := byName[name]
+				if !ok {
+					column = &readingColumn{name: name, numbers: true, bools: true}
+					byName[name] = column
+				}
+				switch value.(type) {
+				case nil:
+				case float64:
+					column.bools = false
+				case bool:
+					column.numbers = false
+				default:
+					column.numbers, column.bools = false, false
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	columns := make([]readingColumn, 0, len(byName))
+	for _, column := range byName {
+		columns = append(columns, *column)
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].name < columns[j].name })
+	return columns, count, nil
+}
+
+// formatValue formats a leaf value of a reading for a CSV cell or a Parquet string column.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,360 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/foxglove/mcap/go/mcap"
+	"github.com/parquet-go/parquet-go"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/data"
+)
+
+var testStart = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// writeTestCaptureDir writes a capture directory with three power sensor readings, in two capture files, and two
+// camera images.
+func writeTestCaptureDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(md *v1.DataCaptureMetadata, offset time.Duration, items ...*v1.SensorData) {
+		sub := filepath.Join(dir, md.GetComponentName(), md.GetMethodName())
+		test.That(t, os.MkdirAll(sub, 0o700), test.ShouldBeNil)
+		f, err := data.NewCaptureFile(sub, md)
+		test.That(t, err, test.ShouldBeNil)
+		var requested time.Time
+		for i, item := range items {
+			requested = testStart.Add(offset + time.Duration(i)*time.Second)
+			item.Metadata = &v1.SensorMetadata{
+				TimeRequested: timestamppb.New(requested),
+				TimeReceived:  timestamppb.New(requested.Add(10 * time.Millisecond)),
+			}
+			test.That(t, f.WriteNext(item), test.ShouldBeNil)
+		}
+		test.That(t, f.Close(), test.ShouldBeNil)
+		// the file was last written when its last capture was received
+		path := strings.TrimSuffix(f.GetPath(), data.InProgressCaptureFileExt) + data.CompletedCaptureFileExt
+		received := requested.Add(10 * time.Millisecond)
+		test.That(t, os.Chtimes(path, received, received), test.ShouldBeNil)
+	}
+	reading := func(readings map[string]interface{}) *v1.SensorData {
+		payload, err := structpb.NewStruct(map[string]interface{}{"readings": readings})
+		test.That(t, err, test.ShouldBeNil)
+		return &v1.SensorData{Data: &v1.SensorData_Struct{Struct: payload}}
+	}
+
+	power := &v1.DataCaptureMetadata{
+		ComponentType: "rdk:component:sensor",
+		ComponentName: "power",
+		MethodName:    "Readings",
+		Type:          v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+		Tags:          []string{"lab", "bench"},
+	}
+	write(power, 0,
+		reading(map[string]interface{}{"watts": 1.5, "on": true, "state": "idle"}),
+		reading(map[string]interface{}{"watts": 2, "on": false}),
+	)
+	write(power, 2*time.Second,
+		reading(map[string]interface{}{"watts": 3, "on": true, "state": "busy"}),
+	)
+	write(&v1.DataCaptureMetadata{
+		ComponentType: "rdk:component:camera",
+		ComponentName: "cam",
+		MethodName:    "ReadImage",
+		Type:          v1.DataType_DATA_TYPE_BINARY_SENSOR,
+		FileExtension: ".jpeg",
+	}, 500*time.Millisecond,
+		&v1.SensorData{Data: &v1.SensorData_Binary{Binary: []byte("image1")}},
+		&v1.SensorData{Data: &v1.SensorData_Binary{Binary: []byte("image2")}},
+	)
+	return dir
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	//nolint:gosec
+	f, err := os.Open(path)
+	test.That(t, err, test.ShouldBeNil)
+	//nolint:errcheck
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	test.That(t, err, test.ShouldBeNil)
+	return records
+}
+
+func TestCaptureDirCSV(t *testing.T) {
+	dir := writeTestCaptureDir(t)
+	destination := t.TempDir()
+	paths, err := CaptureDir(dir, destination, FormatCSV, data.CaptureQuery{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, paths, test.ShouldResemble, []string{
+		filepath.Join(destination, "cam_ReadImage.csv"),
+		filepath.Join(destination, "power_Readings.csv"),
+	})
+
+	test.That(t, readCSV(t, paths[1]), test.ShouldResemble, [][]string{
+		{"time_requested", "time_received", "tags", "readings.on", "readings.state", "readings.watts"},
+		{"2024-05-01T12:00:00Z", "2024-05-01T12:00:00.01Z", "lab,bench", "true", "idle", "1.5"},
+		{"2024-05-01T12:00:01Z", "2024-05-01T12:00:01.01Z", "lab,bench", "false", "", "2"},
+		{"2024-05-01T12:00:02Z", "2024-05-01T12:00:02.01Z", "lab,bench", "true", "busy", "3"},
+	})
+
+	images := readCSV(t, paths[0])
+	test.That(t, images, test.ShouldHaveLength, 3)
+	test.That(t, images[0], test.ShouldResemble, []string{"time_requested", "time_received", "tags", "file"})
+	test.That(t, images[2][0], test.ShouldEqual, "2024-05-01T12:00:01.5Z")
+	image, err := os.ReadFile(filepath.Join(destination, images[2][3]))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(image), test.ShouldEqual, "image2")
+
+	t.Run("groups without captures in the time range are not exported", func(t *testing.T) {
+		destination := t.TempDir()
+		paths, err := CaptureDir(dir, destination, FormatCSV, data.CaptureQuery{Start: testStart.Add(1600 * time.Millisecond)})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, paths, test.ShouldResemble, []string{filepath.Join(destination, "power_Readings.csv")})
+		test.That(t, readCSV(t, paths[0]), test.ShouldHaveLength, 2)
+		entries, err := os.ReadDir(destination)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, entries, test.ShouldHaveLength, 1)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		paths, err := CaptureDir(dir, t.TempDir(), FormatCSV, data.CaptureQuery{Limit: 2})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, paths, test.ShouldHaveLength, 2)
+		test.That(t, readCSV(t, paths[0]), test.ShouldHaveLength, 2)
+		test.That(t, readCSV(t, paths[1]), test.ShouldHaveLength, 2)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := CaptureDir(dir, t.TempDir(), "xlsx", data.CaptureQuery{})
+		test.That(t, err, test.ShouldBeError, `unknown export format "xlsx"`)
+	})
+}
+
+// mcapMessage is a message of an MCAP file as read by the reference reader.
+type mcapMessage struct {
+	schema  *mcap.Schema
+	channel *mcap.Channel
+	message *mcap.Message
+}
+
+func readMCAP(t *testing.T, path string, useIndex bool) (*mcap.Header, []mcapMessage) {
+	t.Helper()
+	//nolint:gosec
+	f, err := os.Open(path)
+	test.That(t, err, test.ShouldBeNil)
+	//nolint:errcheck
+	defer f.Close()
+	reader, err := mcap.NewReader(f)
+	test.That(t, err, test.ShouldBeNil)
+	defer reader.Close()
+	it, err := reader.Messages(mcap.UsingIndex(useIndex))
+	test.That(t, err, test.ShouldBeNil)
+	var messages []mcapMessage
+	for {
+		schema, channel, message, err := it.Next(nil)
+		if errors.Is(err, io.EOF) {
+			return reader.Header(), messages
+		}
+		test.That(t, err, test.ShouldBeNil)
+		messages = append(messages, mcapMessage{schema: schema, channel: channel, message: message})
+	}
+}
+
+func TestCaptureDirMCAP(t *testing.T) {
+	dir := writeTestCaptureDir(t)
+	paths, err := CaptureDir(dir, t.TempDir(), FormatMCAP, data.CaptureQuery{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, paths, test.ShouldHaveLength, 1)
+
+	header, messages := readMCAP(t, paths[0], false)
+	test.That(t, header.Library, test.ShouldEqual, mcapLibrary)
+	test.That(t, messages, test.ShouldHaveLength, 5)
+	var topics []string
+	var sequences []uint32
+	for _, m := range messages {
+		topics = append(topics, m.channel.Topic)
+		sequences = append(sequences, m.message.Sequence)
+	}
+	test.That(t, topics, test.ShouldResemble, []string{
+		"/cam/ReadImage", "/cam/ReadImage", "/power/Readings", "/power/Readings", "/power/Readings",
+	})
+	test.That(t, sequences, test.ShouldResemble, []uint32{0, 1, 0, 1, 2})
+
+	// the messages are in compressed chunks, which readers that look for an index find by the chunk indexes
+	_, indexed := readMCAP(t, paths[0], true)
+	test.That(t, indexed, test.ShouldHaveLength, 5)
+	//nolint:gosec
+	f, err := os.Open(paths[0])
+	test.That(t, err, test.ShouldBeNil)
+	//nolint:errcheck
+	defer f.Close()
+	reader, err := mcap.NewReader(f)
+	test.That(t, err, test.ShouldBeNil)
+	info, err := reader.Info()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, info.Statistics.MessageCount, test.ShouldEqual, 5)
+	test.That(t, info.Statistics.ChannelMessageCounts, test.ShouldResemble, map[uint16]uint64{1: 2, 2: 3})
+	test.That(t, info.Statistics.MessageStartTime, test.ShouldEqual, testStart.Add(10*time.Millisecond).UnixNano())
+	test.That(t, info.Channels, test.ShouldHaveLength, 2)
+	test.That(t, info.Schemas, test.ShouldHaveLength, 2)
+	test.That(t, info.ChunkIndexes, test.ShouldNotBeEmpty)
+	for _, chunk := range info.ChunkIndexes {
+		test.That(t, chunk.Compression, test.ShouldEqual, mcap.CompressionZSTD)
+	}
+
+	t.Run("tabular", func(t *testing.T) {
+		m := messages[3]
+		test.That(t, m.schema.Name, test.ShouldEqual, "/power/Readings")
+		test.That(t, m.schema.Encoding, test.ShouldEqual, "jsonschema")
+		var jsonSchema map[string]interface{}
+		test.That(t, json.Unmarshal(m.schema.Data, &jsonSchema), test.ShouldBeNil)
+		readings := jsonSchema["properties"].(map[string]interface{})["readings"].(map[string]interface{})
+		test.That(t, readings["properties"], test.ShouldResemble, map[string]interface{}{
+			"watts": map[string]interface{}{"type": "number"},
+			"on":    map[string]interface{}{"type": "boolean"},
+			"state": map[string]interface{}{"type": "string"},
+		})
+
+		test.That(t, m.channel.MessageEncoding, test.ShouldEqual, "json")
+		test.That(t, m.channel.Metadata["tags"], test.ShouldEqual, "bench,lab")
+		test.That(t, m.channel.Metadata["component_type"], test.ShouldEqual, "rdk:component:sensor")
+
+		requested := testStart.Add(time.Second)
+		test.That(t, m.message.LogTime, test.ShouldEqual, requested.Add(10*time.Millisecond).UnixNano())
+		test.That(t, m.message.PublishTime, test.ShouldEqual, requested.UnixNano())
+		test.That(t, string(m.message.Data), test.ShouldEqual, `{"readings":{"on":false,"watts":2}}`)
+		test.That(t, string(messages[4].message.Data), test.ShouldEqual, `{"readings":{"on":true,"state":"busy","watts":3}}`)
+	})
+
+	t.Run("images", func(t *testing.T) {
+		m := messages[1]
+		test.That(t, m.schema.Name, test.ShouldEqual, compressedImageSchema)
+		test.That(t, m.schema.Encoding, test.ShouldEqual, "protobuf")
+		test.That(t, m.channel.MessageEncoding, test.ShouldEqual, "protobuf")
+		test.That(t, m.channel.Metadata["file_extension"], test.ShouldEqual, ".jpeg")
+
+		// the messages must decode with the descriptors in the schema
+		var set descriptorpb.FileDescriptorSet
+		test.That(t, proto.Unmarshal(m.schema.Data, &set), test.ShouldBeNil)
+		files, err := protodesc.NewFiles(&set)
+		test.That(t, err, test.ShouldBeNil)
+		desc, err := files.FindDescriptorByName(compressedImageSchema)
+		test.That(t, err, test.ShouldBeNil)
+		msgDesc := desc.(protoreflect.MessageDescriptor)
+
+		message := dynamicpb.NewMessage(msgDesc)
+		test.That(t, proto.Unmarshal(m.message.Data, message), test.ShouldBeNil)
+		get := func(name protoreflect.Name) protoreflect.Value {
+			return message.Get(msgDesc.Fields().ByName(name))
+		}
+		test.That(t, string(get("data").Bytes()), test.ShouldEqual, "image2")
+		test.That(t, get("format").String(), test.ShouldEqual, "jpeg")
+		test.That(t, get("frame_id").String(), test.ShouldEqual, "cam")
+		timestamp := get("timestamp").Message()
+		test.That(t, timestamp.Get(timestamp.Descriptor().Fields().ByName("nanos")).Int(), test.ShouldEqual, 5e8)
+	})
+
+	t.Run("no captures", func(t *testing.T) {
+		destination := t.TempDir()
+		paths, err := CaptureDir(dir, destination, FormatMCAP, data.CaptureQuery{ResourceName: "arm"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, paths, test.ShouldBeEmpty)
+		entries, err := os.ReadDir(destination)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, entries, test.ShouldBeEmpty)
+	})
+}
+
+func TestCaptureDirParquet(t *testing.T) {
+	paths, err := CaptureDir(writeTestCaptureDir(t), t.TempDir(), FormatParquet, data.CaptureQuery{})
+	test.That(t, err, test.ShouldBeNil)
+	// binary captures are not exported to Parquet
+	test.That(t, paths, test.ShouldHaveLength, 1)
+	test.That(t, filepath.Base(paths[0]), test.ShouldEqual, "power_Readings.parquet")
+
+	//nolint:gosec
+	f, err := os.Open(paths[0])
+	test.That(t, err, test.ShouldBeNil)
+	//nolint:errcheck
+	defer f.Close()
+	info, err := f.Stat()
+	test.That(t, err, test.ShouldBeNil)
+	file, err := parquet.OpenFile(f, info.Size())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, file.Metadata().CreatedBy, test.ShouldEqual, parquetCreatedBy)
+	test.That(t, file.NumRows(), test.ShouldEqual, 3)
+	// a row group per capture file
+	test.That(t, file.RowGroups(), test.ShouldHaveLength, 2)
+
+	var names []string
+	kinds := map[string]parquet.Kind{}
+	for _, column := range file.Schema().Columns() {
+		leaf, ok := file.Schema().Lookup(column...)
+		test.That(t, ok, test.ShouldBeTrue)
+		names = append(names, column[0])
+		kinds[column[0]] = leaf.Node.Type().Kind()
+	}
+	test.That(t, names, test.ShouldResemble, []string{
+		"time_requested", "time_received", "tags", "readings.on", "readings.state", "readings.watts",
+	})
+	test.That(t, kinds["time_requested"], test.ShouldEqual, parquet.Int64)
+	test.That(t, kinds["readings.on"], test.ShouldEqual, parquet.Boolean)
+	test.That(t, kinds["readings.state"], test.ShouldEqual, parquet.ByteArray)
+	test.That(t, kinds["readings.watts"], test.ShouldEqual, parquet.Double)
+
+	reader := parquet.NewReader(file)
+	//nolint:errcheck
+	defer reader.Close()
+	rows := make([]parquet.Row, 4)
+	n, err := reader.ReadRows(rows)
+	test.That(t, err, test.ShouldBeError, io.EOF)
+	test.That(t, n, test.ShouldEqual, 3)
+	// values returns the values of the row by column name, which are nil where they are null
+	values := func(row parquet.Row) map[string]interface{} {
+		m := map[string]interface{}{}
+		for _, value := range row {
+			name := names[value.Column()]
+			switch {
+			case value.IsNull():
+				m[name] = nil
+			case value.Kind() == parquet.ByteArray:
+				m[name] = string(value.ByteArray())
+			case value.Kind() == parquet.Int64:
+				m[name] = value.Int64()
+			case value.Kind() == parquet.Double:
+				m[name] = value.Double()
+			default:
+				m[name] = value.Boolean()
+			}
+		}
+		return m
+	}
+	test.That(t, values(rows[1]), test.ShouldResemble, map[string]interface{}{
+		"time_requested": testStart.Add(time.Second).UnixMicro(),
+		"time_received":  testStart.Add(time.Second + 10*time.Millisecond).UnixMicro(),
+		"tags":           "lab,bench",
+		"readings.on":    false,
+		"readings.state": nil,
+		"readings.watts": 2.0,
+	})
+	test.That(t, values(rows[2])["readings.state"], test.ShouldEqual, "busy")
+	test.That(t, values(rows[0])["readings.watts"], test.ShouldEqual, 1.5)
+}
@@ -0,0 +1,334 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/foxglove/mcap/go/mcap"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/data"
+)
+
+const (
+	mcapProfile = ""
+	mcapLibrary = "viam-rdk"
+	// compressedImageSchema is the Foxglove schema which image captures are written with, so that Foxglove displays
+	// them.
+	compressedImageSchema = "foxglove.CompressedImage"
+)
+
+// writeMCAP writes the captures of the groups to w as an indexed MCAP file of zstd compressed chunks with a channel
+// per group, and returns whether there were no captures to write. Each message is logged at the time its capture was
+// received and published at the time it was requested, and the tags of the captures are in the metadata of their
+// channel.
+//
+// Captures of image MIME types are foxglove.CompressedImage protobuf messages, other binary captures are raw messages
+// without a schema, and tabular captures are JSON messages whose JSON schema is inferred from their readings, for
+// which the captures of tabular groups are read twice.
+func writeMCAP(w io.Writer, groups []rowGroup) (bool, error) {
+	bw := bufio.NewWriter(w)
+	mw, err := mcap.NewWriter(bw, &mcap.WriterOptions{
+		IncludeCRC:      true,
+		Chunked:         true,
+		Compression:     mcap.CompressionZSTD,
+		OverrideLibrary: true,
+	})
+	if err != nil {
+		return false, err
+	}
+	if err := mw.WriteHeader(&mcap.Header{Profile: mcapProfile, Library: mcapLibrary}); err != nil {
+		return false, err
+	}
+
+	// schema IDs start at one, as zero is reserved for channels without a schema
+	var imageSchemaID, schemaID, channelID uint16
+	for _, group := range groups {
+		image := group.binary && imageFormat(group.fileExtension) != ""
+		var jsonSchema []byte
+		if !group.binary {
+			schema, count, err := inferJSONSchema(group)
+			if err != nil {
+				return false, err
+			}
+			if count == 0 {
+				continue
+			}
+			if jsonSchema, err = json.Marshal(schema); err != nil {
+				return false, err
+			}
+		}
+
+		// the schema and channel are written before the first message, once the group is known to have captures
+		var sequence uint32
+		err := group.each(func(rows []data.CaptureRow) error {
+			if sequence == 0 {
+				channel := &mcap.Channel{Topic: group.topic(), MessageEncoding: "application/octet-stream"}
+				switch {
+				case image:
+					if imageSchemaID == 0 {
+						descriptors, err := compressedImageDescriptors()
+						if err != nil {
+							return err
+						}
+						schemaID++
+						imageSchemaID = schemaID
+						if err := mw.WriteSchema(&mcap.Schema{
+							ID: imageSchemaID, Name: compressedImageSchema, Encoding: "protobuf", Data: descriptors,
+						}); err != nil {
+							return err
+						}
+					}
+					channel.SchemaID, channel.MessageEncoding = imageSchemaID, "protobuf"
+				case !group.binary:
+					schemaID++
+					if err := mw.WriteSchema(&mcap.Schema{
+						ID: schemaID, Name: group.topic(), Encoding: "jsonschema", Data: jsonSchema,
+					}); err != nil {
+						return err
+					}
+					channel.SchemaID, channel.MessageEncoding = schemaID, "json"
+				}
+				channelID++
+				channel.ID = channelID
+				channel.Metadata = channelMetadata(group)
+				if err := mw.WriteChannel(channel); err != nil {
+					return err
+				}
+			}
+			for _, row := range rows {
+				var payload []byte
+				switch {
+				case image:
+					payload = compressedImageMessage(row, group.componentName)
+				case group.binary:
+					payload = row.SensorData.GetBinary()
+				default:
+					var err error
+					if payload, err = json.Marshal(row.SensorData.GetStruct().AsMap()); err != nil {
+						return err
+					}
+				}
+				if err := mw.WriteMessage(&mcap.Message{
+					ChannelID:   channelID,
+					Sequence:    sequence,
+					LogTime:     unixNanos(timeReceived(row).UnixNano()),
+					PublishTime: unixNanos(timeRequested(row).UnixNano()),
+					Data:        payload,
+				}); err != nil {
+					return err
+				}
+				sequence++
+			}
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return false, err
+	}
+	return channelID == 0, bw.Flush()
+}
+
+// channelMetadata returns the metadata of the channel of the group.
+func channelMetadata(group rowGroup) map[string]string {
+	metadata := map[string]string{
+		"component_type": group.componentType,
+		"component_name": group.componentName,
+		"method_name":    group.method,
+		"tags":           strings.Join(group.tags(), ","),
+	}
+	if group.binary {
+		metadata["file_extension"] = group.fileExtension
+	}
+	return metadata
+}
+
+func unixNanos(ns int64) uint64 {
+	if ns < 0 {
+		return 0
+	}
+	return uint64(ns)
+}
+
+// imageFormat returns the foxglove.CompressedImage format of a file extension, or "" if it isn't an image.
+func imageFormat(ext string) string {
+	switch strings.ToLower(strings.TrimPrefix(ext, ".")) {
+	case "jpeg", "jpg":
+		return "jpeg"
+	case "png":
+		return "png"
+	case "webp":
+		return "webp"
+	default:
+		return ""
+	}
+}
+
+// compressedImageMessage encodes the binary capture as a foxglove.CompressedImage protobuf message:
+//
+//	message CompressedImage {
+//	  google.protobuf.Timestamp timestamp = 1;
+//	  string frame_id = 4;
+//	  bytes data = 2;
+//	  string format = 3;
+//	}
+func compressedImageMessage(row data.CaptureRow, frameID string) []byte {
+	requested := timeRequested(row)
+	var timestamp []byte
+	timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+	timestamp = protowire.AppendVarint(timestamp, uint64(requested.Unix()))
+	timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+	timestamp = protowire.AppendVarint(timestamp, uint64(requested.Nanosecond()))
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, timestamp)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, row.SensorData.GetBinary())
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendString(b, imageFormat(row.Metadata.GetFileExtension()))
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	return protowire.AppendString(b, frameID)
+}
+
+// compressedImageDescriptors returns the serialized FileDescriptorSet of foxglove.CompressedImage, which is the schema
+// of protobuf channels in MCAP.
+func compressedImageDescriptors() ([]byte, error) {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	timestampFile := protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto)
+	imageFile := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("foxglove/CompressedImage.proto"),
+		Package:    proto.String("foxglove"),
+		Dependency: []string{timestampFile.GetName()},
+		Syntax:     proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("CompressedImage"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("timestamp", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+				field("frame_id", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("data", 2, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				field("format", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+			},
+		}},
+	}
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{timestampFile, imageFile}}
+	// validate the descriptors so that a mistake fails here rather than in Foxglove
+	if _, err := protodesc.NewFiles(set); err != nil {
+		return nil, errors.Wrap(err, "invalid foxglove.CompressedImage descriptor")
+	}
+	return proto.Marshal(set)
+}
+
+// inferJSONSchema reads the tabular captures of the group and returns a JSON schema which the readings of all of them
+// conform to, along with the number of captures.
+func inferJSONSchema(group rowGroup) (map[string]interface{}, int, error) {
+	var schema map[string]interface{}
+	var count int
+	err := group.each(func(rows []data.CaptureRow) error {
+		for _, row := range rows {
+			schema = mergeJSONSchema(schema, jsonSchemaOf(row.SensorData.GetStruct().AsMap()))
+			count++
+		}
+		return nil
+	})
+	if schema == nil {
+		schema = map[string]interface{}{"type": "object"}
+	}
+	return schema, count, err
+}
+
+func jsonSchemaOf(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties := map[string]interface{}{}
+		for key, child := range v {
+			properties[key] = jsonSchemaOf(child)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	case []interface{}:
+		var items map[string]interface{}
+		for _, child := range v {
+			items = mergeJSONSchema(items, jsonSchemaOf(child))
+		}
+		if items == nil {
+			return map[string]interface{}{"type": "array"}
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	case float64:
+		return map[string]interface{}{"type": "number"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	case string:
+		return map[string]interface{}{"type": "string"}
+	default:
+		return map[string]interface{}{"type": "null"}
+	}
+}
+
+// mergeJSONSchema returns a schema which values of either schema conform to. Schemas of different types are merged
+// into a schema without a type, which any value conforms to.
+func mergeJSONSchema(a, b map[string]interface{}) map[string]interface{} {
+	if a == nil {
+		return b
+	}
+	if a["type"] != b["type"] {
+		return map[string]interface{}{}
+	}
+	switch a["type"] {
+	case "object":
+		properties := map[string]interface{}{}
+		aProps, _ := a["properties"].(map[string]interface{})
+		bProps, _ := b["properties"].(map[string]interface{})
+		for key, prop := range aProps {
+			properties[key] = prop
+		}
+		for key, prop := range bProps {
+			if existing, ok := properties[key].(map[string]interface{}); ok {
+				properties[key] = mergeJSONSchema(existing, prop.(map[string]interface{}))
+			} else {
+				properties[key] = prop
+			}
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	case "array":
+		aItems, _ := a["items"].(map[string]interface{})
+		bItems, _ := b["items"].(map[string]interface{})
+		var items map[string]interface{}
+		switch {
+		case aItems == nil:
+			items = bItems
+		case bItems == nil:
+			items = aItems
+		default:
+			items = mergeJSONSchema(aItems, bItems)
+		}
+		if items == nil {
+			return map[string]interface{}{"type": "array"}
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	default:
+		return a
+	}
+}
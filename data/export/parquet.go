@@ -0,0 +1,149 @@
+package export
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+	"github.com/parquet-go/parquet-go/encoding"
+
+	"go.viam.com/rdk/data"
+)
+
+// parquetCreatedBy is the application which Parquet files record as having written them.
+const parquetCreatedBy = "viam-rdk data export"
+
+// writeParquetGroup writes the tabular captures of the group to a Parquet file in dir and returns its path, or "" if
+// the group has no captures. The captures are read twice, first for the columns and then for their values.
+func writeParquetGroup(dir string, group rowGroup) (string, error) {
+	readings, count, err := readingColumns(group)
+	if err != nil || count == 0 {
+		return "", err
+	}
+	path := filepath.Join(dir, group.baseName()+".parquet")
+	return path, writeFile(path, func(f *os.File) error { return writeParquet(f, readings, group) })
+}
+
+// writeParquet writes the readings of the tabular captures of the group to w as a zstd compressed Parquet file with a
+// row group per capture file. The file has a time_requested and time_received timestamp column, a tags column of the
+// comma separated tags, and a column for each reading, named by its dotted path. Readings which are numbers or
+// booleans in every capture are DOUBLE or BOOLEAN columns, and other readings are string columns. Every column is
+// optional.
+func writeParquet(w io.Writer, readings []readingColumn, group rowGroup) error {
+	columns := parquetGroup{
+		{name: "time_requested", Node: parquet.Optional(parquet.Timestamp(parquet.Microsecond))},
+		{name: "time_received", Node: parquet.Optional(parquet.Timestamp(parquet.Microsecond))},
+		{name: "tags", Node: parquet.Optional(parquet.String())},
+	}
+	for _, reading := range readings {
+		node := parquet.String()
+		switch {
+		case reading.numbers:
+			node = parquet.Leaf(parquet.DoubleType)
+		case reading.bools:
+			node = parquet.Leaf(parquet.BooleanType)
+		}
+		columns = append(columns, parquetField{name: reading.name, Node: parquet.Optional(node)})
+	}
+	pw := parquet.NewWriter(w, &parquet.WriterConfig{
+		CreatedBy:   parquetCreatedBy,
+		Compression: &parquet.Zstd,
+		Schema:      parquet.NewSchema("captures", columns),
+	})
+
+	err := group.each(func(rows []data.CaptureRow) error {
+		parquetRows := make([]parquet.Row, 0, len(rows))
+		for _, row := range rows {
+			parquetRows = append(parquetRows, parquetRow(readings, row))
+		}
+		if _, err := pw.WriteRows(parquetRows); err != nil {
+			return err
+		}
+		// each capture file is a row group
+		return pw.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	return pw.Close()
+}
+
+// parquetRow returns the values of the columns of writeParquet for a capture.
+func parquetRow(readings []readingColumn, row data.CaptureRow) parquet.Row {
+	values := make(parquet.Row, 0, 3+len(readings))
+	add := func(value parquet.Value) {
+		// the values of an optional column are defined unless they are null
+		definitionLevel := 1
+		if value.IsNull() {
+			definitionLevel = 0
+		}
+		values = append(values, value.Level(0, definitionLevel, len(values)))
+	}
+	add(parquet.Int64Value(timeRequested(row).UnixMicro()))
+	add(parquet.Int64Value(timeReceived(row).UnixMicro()))
+	add(parquet.ByteArrayValue([]byte(strings.Join(row.Metadata.GetTags(), ","))))
+	leaves := flattenReadings(row)
+	for _, reading := range readings {
+		value, ok := leaves[reading.name]
+		switch {
+		case !ok || value == nil:
+			add(parquet.NullValue())
+		case reading.numbers:
+			add(parquet.DoubleValue(value.(float64)))
+		case reading.bools:
+			add(parquet.BooleanValue(value.(bool)))
+		default:
+			add(parquet.ByteArrayValue([]byte(formatValue(value))))
+		}
+	}
+	return values
+}
+
+// parquetGroup is the root of the schema of writeParquet. Unlike parquet.Group, it keeps the columns in the order
+// they are given rather than sorting them by name.
+type parquetGroup []parquetField
+
+func (g parquetGroup) ID() int                     { return 0 }
+func (g parquetGroup) String() string              { return g.group().String() }
+func (g parquetGroup) Type() parquet.Type          { return g.group().Type() }
+func (g parquetGroup) Optional() bool              { return false }
+func (g parquetGroup) Repeated() bool              { return false }
+func (g parquetGroup) Required() bool              { return true }
+func (g parquetGroup) Leaf() bool                  { return false }
+func (g parquetGroup) Encoding() encoding.Encoding { return nil }
+func (g parquetGroup) Compression() compress.Codec { return nil }
+func (g parquetGroup) GoType() reflect.Type        { return g.group().GoType() }
+
+// group returns the columns as a parquet.Group, which describes them in name order.
+func (g parquetGroup) group() parquet.Group {
+	group := make(parquet.Group, len(g))
+	for _, field := range g {
+		group[field.name] = field.Node
+	}
+	return group
+}
+
+func (g parquetGroup) Fields() []parquet.Field {
+	fields := make([]parquet.Field, len(g))
+	for i := range g {
+		fields[i] = g[i]
+	}
+	return fields
+}
+
+// parquetField is a column of a parquetGroup.
+type parquetField struct {
+	parquet.Node
+	name string
+}
+
+func (f parquetField) Name() string { return f.name }
+
+// Value is only used to write Go values, rather than rows, with a schema.
+func (f parquetField) Value(base reflect.Value) reflect.Value {
+	return base.MapIndex(reflect.ValueOf(f.name))
+}
@@ -0,0 +1,247 @@
+package data
+
+import (
+	"encoding/base64"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+)
+
+// CaptureQuery filters the captures in a capture directory. Fields which are left empty match every capture.
+type CaptureQuery struct {
+	ResourceName string
+	Method       string
+	// Start and End bound the time at which captures were requested. Start is inclusive and End exclusive.
+	Start time.Time
+	End   time.Time
+	// Tags matches the captures with any of the tags.
+	Tags []string
+	// Limit, when positive, returns only the first Limit captures.
+	Limit int
+	// ExcludeBinary leaves the payloads of binary captures out of the returned rows, which keep only their size.
+	ExcludeBinary bool
+}
+
+func (q CaptureQuery) matchesMetadata(md *v1.DataCaptureMetadata) bool {
+	if q.ResourceName != "" && md.GetComponentName() != q.ResourceName {
+		return false
+	}
+	if q.Method != "" && md.GetMethodName() != q.Method {
+		return false
+	}
+	if len(q.Tags) == 0 {
+		return true
+	}
+	for _, tag := range md.GetTags() {
+		if slices.Contains(q.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q CaptureQuery) matchesTime(t time.Time) bool {
+	return (q.Start.IsZero() || !t.Before(q.Start)) && (q.End.IsZero() || t.Before(q.End))
+}
+
+// CaptureIndexEntry describes a capture file.
+type CaptureIndexEntry struct {
+	Path     string
+	Metadata *v1.DataCaptureMetadata
+	Size     int64
+	ModTime  time.Time
+}
+
+// CaptureIndex indexes the completed and in progress capture files of a capture directory by their metadata, so
+// that queries only read the captures of the files which match.
+type CaptureIndex struct {
+	entries []CaptureIndexEntry
+}
+
+// NewCaptureIndex indexes the capture files in dir and its subdirectories. Files whose metadata can't be read are
+// skipped.
+func NewCaptureIndex(dir string) (*CaptureIndex, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	var entries []CaptureIndexEntry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			// keep indexing the rest of the directory
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != CompletedCaptureFileExt && ext != InProgressCaptureFileExt {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		md, err := readCaptureMetadata(path)
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, CaptureIndexEntry{Path: path, Metadata: md, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CaptureIndex{entries: entries}, nil
+}
+
+func readCaptureMetadata(path string) (*v1.DataCaptureMetadata, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer f.Close()
+	captureFile, err := ReadCaptureFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return captureFile.ReadMetadata(), nil
+}
+
+func readCaptureSensorData(path string) ([]*v1.SensorData, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer f.Close()
+	captureFile, err := ReadCaptureFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return SensorDataFromCaptureFile(captureFile)
+}
+
+// Entries returns the indexed capture files.
+func (idx *CaptureIndex) Entries() []CaptureIndexEntry {
+	return idx.entries
+}
+
+// CaptureRow is a capture which matched a query, along with the metadata of its capture file.
+type CaptureRow struct {
+	Path       string
+	Metadata   *v1.DataCaptureMetadata
+	SensorData *v1.SensorData
+	// BinarySize is the size of the payload of a binary capture, which is set even if the payload was excluded.
+	BinarySize int
+}
+
+// Matching returns the indexed capture files which may hold captures that match the query.
+func (idx *CaptureIndex) Matching(q CaptureQuery) []CaptureIndexEntry {
+	var entries []CaptureIndexEntry
+	for _, entry := range idx.entries {
+		if !q.matchesMetadata(entry.Metadata) {
+			continue
+		}
+		// a file which was last written before the start only holds captures from before the start
+		if !q.Start.IsZero() && entry.ModTime.Before(q.Start) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Captures reads the captures of the capture file which were requested within the time range of the query, in the
+// order in which they were written. The limit of the query does not apply. A file which was synced or deleted since
+// it was indexed has no captures.
+func (e CaptureIndexEntry) Captures(q CaptureQuery) ([]CaptureRow, error) {
+	sensorData, err := readCaptureSensorData(e.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read capture file %s", e.Path)
+	}
+	var rows []CaptureRow
+	for _, sd := range sensorData {
+		if !q.matchesTime(sd.GetMetadata().GetTimeRequested().AsTime()) {
+			continue
+		}
+		row := CaptureRow{Path: e.Path, Metadata: e.Metadata, SensorData: sd, BinarySize: len(sd.GetBinary())}
+		if q.ExcludeBinary && IsBinary(sd) {
+			row.SensorData = &v1.SensorData{Metadata: sd.GetMetadata(), Data: &v1.SensorData_Binary{}}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Query returns the captures which match the query, ordered by the time at which they were requested. With a limit,
+// it holds no more than the limit and the captures of a single file in memory.
+func (idx *CaptureIndex) Query(q CaptureQuery) ([]CaptureRow, error) {
+	var rows []CaptureRow
+	for _, entry := range idx.Matching(q) {
+		captures, err := entry.Captures(q)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, captures...)
+		if q.Limit > 0 && len(rows) > q.Limit {
+			sortCaptureRows(rows)
+			rows = rows[:q.Limit]
+		}
+	}
+	sortCaptureRows(rows)
+	return rows, nil
+}
+
+func sortCaptureRows(rows []CaptureRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].SensorData.GetMetadata().GetTimeRequested().AsTime().Before(
+			rows[j].SensorData.GetMetadata().GetTimeRequested().AsTime())
+	})
+}
+
+// QueryCaptureDir indexes the capture directory and returns the captures which match the query.
+func QueryCaptureDir(dir string, q CaptureQuery) ([]CaptureRow, error) {
+	idx, err := NewCaptureIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Query(q)
+}
+
+// ToMap returns the row as a map which can be marshalled to JSON or to a protobuf struct. Tabular data is under
+// "data", while binary data is described by "binary_size" and "file_extension", and included base64 encoded under
+// "binary" when includeBinary is true and the payload wasn't excluded by the query.
+func (r CaptureRow) ToMap(includeBinary bool) map[string]interface{} {
+	tags := make([]interface{}, 0, len(r.Metadata.GetTags()))
+	for _, tag := range r.Metadata.GetTags() {
+		tags = append(tags, tag)
+	}
+	m := map[string]interface{}{
+		"file":           r.Path,
+		"component_type": r.Metadata.GetComponentType(),
+		"component_name": r.Metadata.GetComponentName(),
+		"method_name":    r.Metadata.GetMethodName(),
+		"tags":           tags,
+		"time_requested": r.SensorData.GetMetadata().GetTimeRequested().AsTime().Format(time.RFC3339Nano),
+		"time_received":  r.SensorData.GetMetadata().GetTimeReceived().AsTime().Format(time.RFC3339Nano),
+	}
+	if IsBinary(r.SensorData) {
+		m["binary_size"] = r.BinarySize
+		m["file_extension"] = r.Metadata.GetFileExtension()
+		if includeBinary && len(r.SensorData.GetBinary()) == r.BinarySize {
+			m["binary"] = base64.StdEncoding.EncodeToString(r.SensorData.GetBinary())
+		}
+	} else {
+		m["data"] = r.SensorData.GetStruct().AsMap()
+	}
+	return m
+}
@@ -0,0 +1,120 @@
+package data
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// writeTestCaptureFile writes a capture file of the metadata with a capture every second from start.
+func writeTestCaptureFile(t *testing.T, dir string, md *v1.DataCaptureMetadata, start time.Time, items ...*v1.SensorData) {
+	t.Helper()
+	test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+	f, err := NewCaptureFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	for i, item := range items {
+		at := timestamppb.New(start.Add(time.Duration(i) * time.Second))
+		item.Metadata = &v1.SensorMetadata{TimeRequested: at, TimeReceived: at}
+		test.That(t, f.WriteNext(item), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+}
+
+func tabularTestCapture(t *testing.T, readings map[string]interface{}) *v1.SensorData {
+	t.Helper()
+	payload, err := structpb.NewStruct(map[string]interface{}{"readings": readings})
+	test.That(t, err, test.ShouldBeNil)
+	return &v1.SensorData{Data: &v1.SensorData_Struct{Struct: payload}}
+}
+
+func TestCaptureIndex(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sensorMD := &v1.DataCaptureMetadata{
+		ComponentType: "rdk:component:sensor",
+		ComponentName: "power",
+		MethodName:    "Readings",
+		Type:          v1.DataType_DATA_TYPE_TABULAR_SENSOR,
+		Tags:          []string{"lab"},
+	}
+	cameraMD := &v1.DataCaptureMetadata{
+		ComponentType: "rdk:component:camera",
+		ComponentName: "cam",
+		MethodName:    "ReadImage",
+		Type:          v1.DataType_DATA_TYPE_BINARY_SENSOR,
+		FileExtension: ".jpeg",
+	}
+	writeTestCaptureFile(t, filepath.Join(dir, "sensor", "power", "Readings"), sensorMD, start,
+		tabularTestCapture(t, map[string]interface{}{"watts": 1}),
+		tabularTestCapture(t, map[string]interface{}{"watts": 2}),
+		tabularTestCapture(t, map[string]interface{}{"watts": 3}),
+	)
+	writeTestCaptureFile(t, filepath.Join(dir, "camera", "cam", "ReadImage"), cameraMD, start.Add(500*time.Millisecond),
+		&v1.SensorData{Data: &v1.SensorData_Binary{Binary: []byte("image1")}},
+		&v1.SensorData{Data: &v1.SensorData_Binary{Binary: []byte("image2")}},
+	)
+	// files which are not capture files are not indexed
+	test.That(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0o600), test.ShouldBeNil)
+
+	idx, err := NewCaptureIndex(dir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(idx.Entries()), test.ShouldEqual, 2)
+
+	names := func(rows []CaptureRow) []string {
+		var names []string
+		for _, row := range rows {
+			names = append(names, row.Metadata.GetComponentName())
+		}
+		return names
+	}
+
+	rows, err := idx.Query(CaptureQuery{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, names(rows), test.ShouldResemble, []string{"power", "cam", "power", "cam", "power"})
+
+	rows, err = idx.Query(CaptureQuery{ResourceName: "power", Start: start.Add(time.Second)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(rows), test.ShouldEqual, 2)
+	test.That(t, rows[0].SensorData.GetStruct().AsMap(), test.ShouldResemble,
+		map[string]interface{}{"readings": map[string]interface{}{"watts": 2.0}})
+
+	rows, err = idx.Query(CaptureQuery{End: start.Add(time.Second), Limit: 1})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, names(rows), test.ShouldResemble, []string{"power"})
+
+	rows, err = idx.Query(CaptureQuery{Tags: []string{"field", "lab"}, Method: "Readings"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(rows), test.ShouldEqual, 3)
+	rows, err = idx.Query(CaptureQuery{Tags: []string{"field"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rows, test.ShouldBeEmpty)
+
+	rows, err = QueryCaptureDir(dir, CaptureQuery{Method: "ReadImage"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(rows), test.ShouldEqual, 2)
+	m := rows[1].ToMap(true)
+	test.That(t, m["component_name"], test.ShouldEqual, "cam")
+	test.That(t, m["time_requested"], test.ShouldEqual, "2024-05-01T12:00:01.5Z")
+	test.That(t, m["binary_size"], test.ShouldEqual, 6)
+	test.That(t, m["file_extension"], test.ShouldEqual, ".jpeg")
+	test.That(t, m["binary"], test.ShouldEqual, base64.StdEncoding.EncodeToString([]byte("image2")))
+	test.That(t, rows[1].ToMap(false), test.ShouldNotContainKey, "binary")
+
+	rows, err = QueryCaptureDir(dir, CaptureQuery{Method: "ReadImage", ExcludeBinary: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(rows), test.ShouldEqual, 2)
+	test.That(t, rows[1].SensorData.GetBinary(), test.ShouldBeEmpty)
+	m = rows[1].ToMap(true)
+	test.That(t, m["binary_size"], test.ShouldEqual, 6)
+	test.That(t, m, test.ShouldNotContainKey, "binary")
+
+	_, err = NewCaptureIndex(filepath.Join(dir, "missing"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.uber.org/multierr"
+	v1 "go.viam.com/api/app/datasync/v1"
+)
+
+// TriggerBuffer is a CaptureBufferedWriter which holds the captures of the last PreTrigger in memory rather than writing
+// them to its target. When triggered it writes those captures to its target, followed by every capture made within
+// PostTrigger of the trigger. Captures which are not around a trigger are discarded.
+type TriggerBuffer struct {
+	target      CaptureBufferedWriter
+	clock       clock.Clock
+	preTrigger  time.Duration
+	postTrigger time.Duration
+
+	mu sync.Mutex
+	// the captures of the last preTrigger, oldest first
+	pending []pendingCapture
+	// captures are written straight to the target until recordUntil
+	recordUntil time.Time
+}
+
+type pendingCapture struct {
+	capturedAt time.Time
+	item       *v1.SensorData
+	mimeType   string
+}
+
+// NewTriggerBuffer returns a TriggerBuffer which writes the captures around each trigger to target.
+func NewTriggerBuffer(target CaptureBufferedWriter, preTrigger, postTrigger time.Duration, clk clock.Clock) *TriggerBuffer {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &TriggerBuffer{
+		target:      target,
+		clock:       clk,
+		preTrigger:  preTrigger,
+		postTrigger: postTrigger,
+	}
+}
+
+// WriteBinary writes the item to the target if it was captured within PostTrigger of a trigger, and otherwise holds it
+// until it is older than PreTrigger.
+func (b *TriggerBuffer) WriteBinary(item *v1.SensorData, mimeType string) error {
+	if !IsBinary(item) {
+		return errInvalidBinarySensorData
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.recordingLocked() {
+		return b.target.WriteBinary(item, mimeType)
+	}
+	b.holdLocked(item, mimeType)
+	return nil
+}
+
+// WriteTabular writes the item to the target if it was captured within PostTrigger of a trigger, and otherwise holds it
+// until it is older than PreTrigger.
+func (b *TriggerBuffer) WriteTabular(item *v1.SensorData) error {
+	if IsBinary(item) {
+		return errInvalidTabularSensorData
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.recordingLocked() {
+		return b.target.WriteTabular(item)
+	}
+	b.holdLocked(item, "")
+	return nil
+}
+
+func (b *TriggerBuffer) recordingLocked() bool {
+	return b.clock.Now().Before(b.recordUntil)
+}
+
+// holdLocked appends the item to the pending captures and drops those which are older than preTrigger.
+func (b *TriggerBuffer) holdLocked(item *v1.SensorData, mimeType string) {
+	now := b.clock.Now()
+	b.pending = append(b.pending, pendingCapture{capturedAt: now, item: item, mimeType: mimeType})
+	expired := 0
+	for expired < len(b.pending) && now.Sub(b.pending[expired].capturedAt) > b.preTrigger {
+		// release the capture, which may be a large image, to the garbage collector
+		b.pending[expired] = pendingCapture{}
+		expired++
+	}
+	b.pending = b.pending[expired:]
+}
+
+// Trigger writes the captures of the last PreTrigger to the target, as well as every capture made within PostTrigger
+// from now. Triggering while the captures of a previous trigger are still being written extends the post trigger
+// window.
+func (b *TriggerBuffer) Trigger() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	var err error
+	for _, capture := range b.pending {
+		if now.Sub(capture.capturedAt) > b.preTrigger {
+			continue
+		}
+		if IsBinary(capture.item) {
+			err = multierr.Combine(err, b.target.WriteBinary(capture.item, capture.mimeType))
+		} else {
+			err = multierr.Combine(err, b.target.WriteTabular(capture.item))
+		}
+	}
+	b.pending = nil
+	if recordUntil := now.Add(b.postTrigger); recordUntil.After(b.recordUntil) {
+		b.recordUntil = recordUntil
+	}
+	return err
+}
+
+// Flush flushes the captures which have been written to the target. The captures which are held in memory remain
+// there until a trigger or until they are older than PreTrigger.
+func (b *TriggerBuffer) Flush() error {
+	return b.target.Flush()
+}
+
+// Path returns the path of the target.
+func (b *TriggerBuffer) Path() string {
+	return b.target.Path()
+}
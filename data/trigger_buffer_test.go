@@ -0,0 +1,93 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+)
+
+// recordingBuffer is a CaptureBufferedWriter which keeps everything written to it.
+type recordingBuffer struct {
+	written []*v1.SensorData
+	flushes int
+}
+
+func (b *recordingBuffer) WriteBinary(item *v1.SensorData, mimeType string) error {
+	b.written = append(b.written, item)
+	return nil
+}
+
+func (b *recordingBuffer) WriteTabular(item *v1.SensorData) error {
+	b.written = append(b.written, item)
+	return nil
+}
+
+func (b *recordingBuffer) Flush() error {
+	b.flushes++
+	return nil
+}
+
+func (b *recordingBuffer) Path() string {
+	return "recording"
+}
+
+func TestTriggerBuffer(t *testing.T) {
+	target := &recordingBuffer{}
+	clk := clock.NewMock()
+	buf := NewTriggerBuffer(target, 2*time.Second, time.Second, clk)
+
+	// one capture every half second
+	capture := func(n int) []*v1.SensorData {
+		var items []*v1.SensorData
+		for i := 0; i < n; i++ {
+			item := &v1.SensorData{Metadata: &v1.SensorMetadata{}, Data: &v1.SensorData_Binary{Binary: []byte{byte(i)}}}
+			test.That(t, buf.WriteBinary(item, "image/jpeg"), test.ShouldBeNil)
+			items = append(items, item)
+			clk.Add(500 * time.Millisecond)
+		}
+		return items
+	}
+
+	// nothing is written until a trigger
+	items := capture(10)
+	test.That(t, target.written, test.ShouldBeEmpty)
+	test.That(t, buf.Flush(), test.ShouldBeNil)
+	test.That(t, target.flushes, test.ShouldEqual, 1)
+	test.That(t, buf.Path(), test.ShouldEqual, "recording")
+
+	// a trigger writes the captures of the last 2 seconds, and then those of the next second
+	test.That(t, buf.Trigger(), test.ShouldBeNil)
+	test.That(t, target.written, test.ShouldResemble, items[6:])
+	post := capture(4)
+	test.That(t, target.written, test.ShouldResemble, append(items[6:], post[:2]...))
+
+	// which are only discarded again once the post trigger window is over
+	capture(4)
+	test.That(t, len(target.written), test.ShouldEqual, 6)
+
+	t.Run("triggering again extends the post trigger window", func(t *testing.T) {
+		target.written = nil
+		test.That(t, buf.Trigger(), test.ShouldBeNil)
+		test.That(t, len(target.written), test.ShouldEqual, 4)
+		clk.Add(900 * time.Millisecond)
+		test.That(t, buf.Trigger(), test.ShouldBeNil)
+		capture(3)
+		test.That(t, len(target.written), test.ShouldEqual, 6)
+		capture(1)
+		test.That(t, len(target.written), test.ShouldEqual, 6)
+	})
+
+	t.Run("tabular captures are held too", func(t *testing.T) {
+		target.written = nil
+		clk.Add(5 * time.Second)
+		tabular := &v1.SensorData{Metadata: &v1.SensorMetadata{}, Data: &v1.SensorData_Struct{}}
+		test.That(t, buf.WriteTabular(tabular), test.ShouldBeNil)
+		test.That(t, buf.WriteTabular(binarySensorData), test.ShouldBeError, errInvalidTabularSensorData)
+		test.That(t, buf.WriteBinary(tabular, "image/jpeg"), test.ShouldBeError, errInvalidBinarySensorData)
+		test.That(t, buf.Trigger(), test.ShouldBeNil)
+		test.That(t, target.written, test.ShouldResemble, []*v1.SensorData{tabular})
+	})
+}
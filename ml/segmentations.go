@@ -0,0 +1,292 @@
+package ml
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/data"
+)
+
+const (
+	instanceDetectionsName = "detections"
+	instancePrototypesName = "prototypes"
+	// defaultInstanceConfidence is the minimum confidence of instances kept when none is given, as the outputs of
+	// prototype mask models have a candidate for every anchor, most of which are not objects.
+	defaultInstanceConfidence = 0.25
+	// instanceIoUThreshold is the overlap above which the less confident of two instances of the same label is
+	// suppressed.
+	instanceIoUThreshold = 0.45
+	maxInstances         = 300
+)
+
+// Instance is a detected object with a mask of the pixels of the object.
+type Instance struct {
+	BoundingBox data.BoundingBox
+	// Mask is the mask of the object in an image of the size given to FormatInstanceSegmentationOutputs, with
+	// the bounds of the bounding box. The pixels of the object have an alpha of 255.
+	Mask *image.Alpha
+}
+
+// HasInstanceSegmentationOutputs returns whether the output tensors of a model are those of an instance
+// segmentation model with prototype masks, which FormatInstanceSegmentationOutputs can decode.
+func HasInstanceSegmentationOutputs(outNameMap *sync.Map, outMap Tensors) bool {
+	_, _, err := findInstanceSegmentationTensorNames(outMap, outNameMap)
+	return err == nil
+}
+
+// FormatInstanceSegmentationOutputs decodes the output tensors of an instance segmentation model with prototype
+// masks, such as YOLO-seg, into instances with masks in an image of size origW by origH.
+//
+// Such models have a detections tensor, of shape (1, 4+nc+nm, n) or (1, n, 4+nc+nm), with the center, width
+// and height of the box, the score of each of nc classes, and nm mask coefficients for each of n candidates; and
+// a prototypes tensor of nm prototype masks, of shape (1, nm, mh, mw) or (1, mh, mw, nm). The mask of an
+// instance is its coefficients applied to the prototypes, within its box. Boxes may be in pixels of the input of
+// size inW by inH, or proportional to it. Candidates below minConfidence are dropped, or below 0.25 if it is not
+// positive, and overlapping candidates of the same class are suppressed.
+func FormatInstanceSegmentationOutputs(outNameMap *sync.Map, outMap Tensors, inW, inH, origW, origH int,
+	minConfidence float64, labels []string,
+) ([]Instance, error) {
+	detectionsName, prototypesName, err := findInstanceSegmentationTensorNames(outMap, outNameMap)
+	if err != nil {
+		return nil, err
+	}
+	protoShape := outMap[prototypesName].Shape()
+	protos, err := ConvertToFloat64Slice(outMap[prototypesName].Data())
+	if err != nil {
+		return nil, err
+	}
+	nm, mh, mw := protoShape[1], protoShape[2], protoShape[3]
+	protoAt := func(k, y, x int) float64 { return protos[(k*mh+y)*mw+x] }
+	if protoShape[3] < protoShape[1] { // channels last
+		mh, mw, nm = protoShape[1], protoShape[2], protoShape[3]
+		protoAt = func(k, y, x int) float64 { return protos[(y*mw+x)*nm+k] }
+	}
+
+	if len(protos) != nm*mh*mw {
+		return nil, errors.Errorf("prototypes tensor of shape %v has %d values", protoShape, len(protos))
+	}
+
+	detShape := outMap[detectionsName].Shape()
+	dets, err := ConvertToFloat64Slice(outMap[detectionsName].Data())
+	if err != nil {
+		return nil, err
+	}
+	if len(dets) != detShape[1]*detShape[2] {
+		return nil, errors.Errorf("detections tensor of shape %v has %d values", detShape, len(dets))
+	}
+	// the candidates are usually many more than their attributes
+	n, rowLen := detShape[1], detShape[2]
+	at := func(i, k int) float64 { return dets[i*rowLen+k] }
+	if detShape[1] < detShape[2] {
+		rowLen, n = detShape[1], detShape[2]
+		at = func(i, k int) float64 { return dets[k*n+i] }
+	}
+	nc := rowLen - 4 - nm
+	if nc < 1 {
+		return nil, errors.Errorf(
+			"detections tensor of shape %v has no room for class scores alongside %d mask coefficients", detShape, nm)
+	}
+	if minConfidence <= 0 {
+		minConfidence = defaultInstanceConfidence
+	}
+
+	type candidate struct {
+		index, class           int
+		score                  float64
+		xmin, ymin, xmax, ymax float64
+	}
+	var candidates []candidate
+	proportional := true
+	for i := 0; i < n; i++ {
+		class, score := 0, math.Inf(-1)
+		for k := 0; k < nc; k++ {
+			if s := at(i, 4+k); s > score {
+				class, score = k, s
+			}
+		}
+		if score < minConfidence {
+			continue
+		}
+		cx, cy, w, h := at(i, 0), at(i, 1), at(i, 2), at(i, 3)
+		c := candidate{i, class, score, cx - w/2, cy - h/2, cx + w/2, cy + h/2}
+		// like detection boxes, boxes in pixels will not all be within a pixel of the upper left corner
+		if c.xmax > 1.5 || c.ymax > 1.5 {
+			proportional = false
+		}
+		candidates = append(candidates, c)
+	}
+	for i := range candidates {
+		c := &candidates[i]
+		if !proportional {
+			c.xmin, c.xmax = c.xmin/float64(inW), c.xmax/float64(inW)
+			c.ymin, c.ymax = c.ymin/float64(inH), c.ymax/float64(inH)
+		}
+		c.xmin, c.ymin = math.Max(c.xmin, 0), math.Max(c.ymin, 0)
+		c.xmax, c.ymax = math.Min(c.xmax, 1), math.Min(c.ymax, 1)
+	}
+
+	// non-maximum suppression, from the most confident candidate down
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	iou := func(a, b candidate) float64 {
+		w := math.Min(a.xmax, b.xmax) - math.Max(a.xmin, b.xmin)
+		h := math.Min(a.ymax, b.ymax) - math.Max(a.ymin, b.ymin)
+		if w <= 0 || h <= 0 {
+			return 0
+		}
+		inter := w * h
+		union := (a.xmax-a.xmin)*(a.ymax-a.ymin) + (b.xmax-b.xmin)*(b.ymax-b.ymin) - inter
+		return inter / union
+	}
+	var kept []candidate
+	for _, c := range candidates {
+		suppressed := false
+		for _, k := range kept {
+			if k.class == c.class && iou(k, c) > instanceIoUThreshold {
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			kept = append(kept, c)
+			if len(kept) == maxInstances {
+				break
+			}
+		}
+	}
+
+	instances := make([]Instance, 0, len(kept))
+	for _, c := range kept {
+		label := strconv.Itoa(c.class)
+		if labels != nil {
+			if c.class >= len(labels) {
+				return nil, errors.Errorf("cannot access label number %v from label file with %v labels", c.class, len(labels))
+			}
+			label = labels[c.class]
+		}
+		coefficients := make([]float64, nm)
+		for k := range coefficients {
+			coefficients[k] = at(c.index, 4+nc+k)
+		}
+		box := image.Rect(
+			int(math.Floor(c.xmin*float64(origW))), int(math.Floor(c.ymin*float64(origH))),
+			int(math.Ceil(c.xmax*float64(origW))), int(math.Ceil(c.ymax*float64(origH))),
+		)
+		score := c.score
+		instances = append(instances, Instance{
+			BoundingBox: data.BoundingBox{
+				Confidence:     &score,
+				Label:          label,
+				XMinNormalized: c.xmin,
+				YMinNormalized: c.ymin,
+				XMaxNormalized: c.xmax,
+				YMaxNormalized: c.ymax,
+			},
+			Mask: instanceMask(box, origW, origH, mw, mh, coefficients, protoAt),
+		})
+	}
+	return instances, nil
+}
+
+// instanceMask applies the coefficients of an instance to the prototype masks, within the box in an image of
+// size w by h. The prototypes are interpolated to the size of the image, and a pixel is in the mask if the
+// sigmoid of its value is more than a half, so if its value is positive.
+func instanceMask(box image.Rectangle, w, h, mw, mh int, coefficients []float64,
+	protoAt func(k, y, x int) float64,
+) *image.Alpha {
+	mask := image.NewAlpha(box)
+	if box.Empty() {
+		return mask
+	}
+	// the prototype cells that the box is interpolated from
+	toProto := func(v float64, size, protoSize int) float64 { return (v+0.5)*float64(protoSize)/float64(size) - 0.5 }
+	clampCell := func(v, protoSize int) int { return min(max(v, 0), protoSize-1) }
+	px0 := clampCell(int(math.Floor(toProto(float64(box.Min.X), w, mw))), mw)
+	py0 := clampCell(int(math.Floor(toProto(float64(box.Min.Y), h, mh))), mh)
+	px1 := clampCell(int(math.Floor(toProto(float64(box.Max.X-1), w, mw)))+1, mw)
+	py1 := clampCell(int(math.Floor(toProto(float64(box.Max.Y-1), h, mh)))+1, mh)
+	cols := px1 - px0 + 1
+	values := make([]float64, (py1-py0+1)*cols)
+	for y := py0; y <= py1; y++ {
+		for x := px0; x <= px1; x++ {
+			var v float64
+			for k, c := range coefficients {
+				v += c * protoAt(k, y, x)
+			}
+			values[(y-py0)*cols+x-px0] = v
+		}
+	}
+	valueAt := func(x, y int) float64 {
+		return values[(clampCell(y, mh)-py0)*cols+clampCell(x, mw)-px0]
+	}
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		v := toProto(float64(y), h, mh)
+		y0 := int(math.Floor(v))
+		fy := v - float64(y0)
+		for x := box.Min.X; x < box.Max.X; x++ {
+			u := toProto(float64(x), w, mw)
+			x0 := int(math.Floor(u))
+			fx := u - float64(x0)
+			value := (1-fy)*((1-fx)*valueAt(x0, y0)+fx*valueAt(x0+1, y0)) + fy*((1-fx)*valueAt(x0, y0+1)+fx*valueAt(x0+1, y0+1))
+			if value > 0 {
+				mask.SetAlpha(x, y, color.Alpha{A: 255})
+			}
+		}
+	}
+	return mask
+}
+
+// findInstanceSegmentationTensorNames finds the detections and prototypes tensors of an instance segmentation
+// model, from the name map if they were remapped, or otherwise as the only 3 and 4 dimensional outputs. It
+// caches the results.
+func findInstanceSegmentationTensorNames(outMap Tensors, nameMap *sync.Map) (string, string, error) {
+	detectionsName, prototypesName := "", ""
+	if name, ok := nameMap.Load(instanceDetectionsName); ok {
+		if detectionsName, ok = name.(string); !ok {
+			return "", "", errors.Errorf("name map was not storing string, but a type %T", name)
+		}
+	}
+	if name, ok := nameMap.Load(instancePrototypesName); ok {
+		if prototypesName, ok = name.(string); !ok {
+			return "", "", errors.Errorf("name map was not storing string, but a type %T", name)
+		}
+	}
+	if detectionsName == "" || prototypesName == "" {
+		var threeDims, fourDims []string
+		for _, name := range TensorNames(outMap) {
+			switch outMap[name].Dims() {
+			case 3:
+				threeDims = append(threeDims, name)
+			case 4:
+				fourDims = append(fourDims, name)
+			}
+		}
+		if detectionsName == "" && len(threeDims) == 1 {
+			detectionsName = threeDims[0]
+		}
+		if prototypesName == "" && len(fourDims) == 1 {
+			prototypesName = fourDims[0]
+		}
+	}
+	detections, okDetections := outMap[detectionsName]
+	prototypes, okPrototypes := outMap[prototypesName]
+	if !okDetections || !okPrototypes {
+		return "", "", errors.Errorf("could not find a %q and a %q output tensor among [%s]",
+			instanceDetectionsName, instancePrototypesName, strings.Join(TensorNames(outMap), ", "))
+	}
+	if detections.Dims() != 3 || detections.Shape()[0] != 1 {
+		return "", "", errors.Errorf("detections tensor must have shape (1, a, b), got %v", detections.Shape())
+	}
+	if prototypes.Dims() != 4 || prototypes.Shape()[0] != 1 {
+		return "", "", errors.Errorf("prototypes tensor must have shape (1, a, b, c), got %v", prototypes.Shape())
+	}
+	nameMap.Store(instanceDetectionsName, detectionsName)
+	nameMap.Store(instancePrototypesName, prototypesName)
+	return detectionsName, prototypesName, nil
+}
@@ -0,0 +1,103 @@
+package armplanning
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan/ik"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// analyticSolutions returns the closed form IK solutions of the segment, when its goal is the pose of a single frame
+// moved by 6 joints that ik.AnalyticIK can solve, such as those of a UR arm. Otherwise, it returns nil.
+func (psc *planSegmentContext) analyticSolutions(logger logging.Logger) [][]float64 {
+	if len(psc.origGoal) != 1 {
+		return nil
+	}
+	var frameName string
+	var goal *referenceframe.PoseInFrame
+	for name, pif := range psc.origGoal {
+		frameName, goal = name, pif
+	}
+
+	// the joints of the moving frames, with all others held at the start
+	moving, _ := psc.motionChains.framesFilteredByMovingAndNonmoving()
+	start := psc.start.GetLinearizedInputs()
+	allLimits := psc.pc.lis.GetLimits()
+	var joints []int
+	var limits []referenceframe.Limit
+	offset := 0
+	for _, name := range psc.pc.lis.FrameNamesInOrder() {
+		dof := len(psc.pc.fs.Frame(name).DoF())
+		if slices.Contains(moving, name) {
+			for i := range dof {
+				joints = append(joints, offset+i)
+				limits = append(limits, allLimits[offset+i])
+			}
+		}
+		offset += dof
+	}
+
+	configuration := func(jointPositions []float64) []float64 {
+		inputs := slices.Clone(start)
+		for i, joint := range joints {
+			inputs[joint] = jointPositions[i]
+		}
+		return inputs
+	}
+	fk := func(jointPositions []float64) (spatialmath.Pose, error) {
+		inputs, err := psc.pc.lis.FloatsToInputs(configuration(jointPositions))
+		if err != nil {
+			return nil, err
+		}
+		tf, err := psc.pc.fs.Transform(inputs, referenceframe.NewPoseInFrame(frameName, spatialmath.NewZeroPose()), goal.Parent())
+		if err != nil {
+			return nil, err
+		}
+		return tf.(*referenceframe.PoseInFrame).Pose(), nil
+	}
+
+	solver, err := ik.CreateAnalyticSolver(fk, limits)
+	if err != nil {
+		if !errors.Is(err, ik.ErrNotAnalyticChain) {
+			logger.Debugf("cannot solve analytically: %v", err)
+		}
+		return nil
+	}
+
+	seed := make([]float64, len(joints))
+	for i, joint := range joints {
+		seed[i] = start[joint]
+	}
+	var solutions [][]float64
+	for _, jointPositions := range solver.Solutions(goal.Pose(), seed) {
+		solutions = append(solutions, configuration(jointPositions))
+	}
+	logger.Debugf("%d analytic solutions for %s wrist", len(solutions), solver.Wrist())
+	return solutions
+}
+
+// solveAnalytically processes the analytic solutions of the segment, and returns whether any of them meet the
+// constraints. Those which don't are added as seeds for the numeric solver.
+func (sss *solutionSolvingState) solveAnalytically(ctx context.Context, minFunc ik.CostFunc) bool {
+	solutions := sss.psc.analyticSolutions(sss.logger)
+	for _, solution := range solutions {
+		sss.totalIkAttempts.Add(1)
+		score := minFunc(ctx, solution)
+		if score > sss.psc.pc.planOpts.GoalThreshold {
+			continue
+		}
+		sss.process(ctx, &ik.Solution{Configuration: solution, Score: score, Exact: true, Meta: "analytic"})
+	}
+	if len(sss.solutions) > 0 {
+		return true
+	}
+	for _, solution := range solutions {
+		sss.linearSeeds = append(sss.linearSeeds, solution)
+		sss.seedLimits = append(sss.seedLimits, ik.ComputeAdjustLimits(solution, sss.seedLimits[0], .25))
+	}
+	return false
+}
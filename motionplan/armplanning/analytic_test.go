@@ -0,0 +1,81 @@
+package armplanning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestAnalyticSolutions(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	// newSegment returns the segment moving the gripper on the end of the arm to where it is at the goal configuration.
+	newSegment := func(t *testing.T, model string, goalConfiguration []referenceframe.Input) *planSegmentContext {
+		t.Helper()
+		m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/"+model+".json"), "")
+		test.That(t, err, test.ShouldBeNil)
+		fs := referenceframe.NewEmptyFrameSystem("")
+		test.That(t, fs.AddFrame(m, fs.World()), test.ShouldBeNil)
+		gripper, err := referenceframe.NewStaticFrame("gripper", spatialmath.NewPoseFromPoint(r3.Vector{Z: 100}))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fs.AddFrame(gripper, m), test.ShouldBeNil)
+
+		goalInputs := referenceframe.FrameSystemInputs{m.Name(): goalConfiguration}.ToLinearInputs()
+		goalPose, err := fs.Transform(goalInputs, referenceframe.NewZeroPoseInFrame("gripper"), referenceframe.World)
+		test.That(t, err, test.ShouldBeNil)
+		goal := referenceframe.FrameSystemPoses{"gripper": goalPose.(*referenceframe.PoseInFrame)}
+
+		start := referenceframe.FrameSystemInputs{m.Name(): make([]referenceframe.Input, len(m.DoF()))}
+		request := &PlanRequest{
+			FrameSystem:    fs,
+			Goals:          []*PlanState{NewPlanState(goal, nil)},
+			StartState:     NewPlanState(nil, start),
+			PlannerOptions: NewBasicPlannerOptions(),
+			Constraints:    &motionplan.Constraints{},
+		}
+		pc, err := newPlanContext(ctx, logger, request, &PlanMeta{})
+		test.That(t, err, test.ShouldBeNil)
+		psc, err := newPlanSegmentContext(ctx, pc, start.ToLinearInputs(), goal)
+		test.That(t, err, test.ShouldBeNil)
+		return psc
+	}
+
+	t.Run("ur5e", func(t *testing.T) {
+		goalConfiguration := []referenceframe.Input{0.3, -1.2, 1.1, -0.4, 0.9, 0.2}
+		psc := newSegment(t, "ur5e", goalConfiguration)
+
+		solutions := psc.analyticSolutions(logger)
+		test.That(t, len(solutions), test.ShouldBeBetweenOrEqual, 2, 8)
+		found := false
+		for _, solution := range solutions {
+			found = found || referenceframe.InputsL2Distance(solution, goalConfiguration) < 1e-6
+		}
+		test.That(t, found, test.ShouldBeTrue)
+
+		// solving doesn't fall back to the numeric solver, so always finds the same solutions
+		nodes, err := getSolutions(ctx, psc, logger)
+		test.That(t, err, test.ShouldBeNil)
+		again, err := getSolutions(ctx, newSegment(t, "ur5e", goalConfiguration), logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(again), test.ShouldEqual, len(nodes))
+		minFunc := psc.pc.linearizeFSmetric(psc.pc.planOpts.getGoalMetric(psc.goal))
+		for i, n := range nodes {
+			test.That(t, n.inputs.GetLinearizedInputs(), test.ShouldResemble, again[i].inputs.GetLinearizedInputs())
+			test.That(t, minFunc(ctx, n.inputs.GetLinearizedInputs()), test.ShouldBeLessThan, 1e-6)
+		}
+	})
+
+	t.Run("xarm6", func(t *testing.T) {
+		psc := newSegment(t, "xarm6", []referenceframe.Input{0.3, -0.2, -0.5, 0.4, 0.9, 0.2})
+		test.That(t, psc.analyticSolutions(logger), test.ShouldBeNil)
+	})
+}
@@ -73,6 +73,20 @@ func (pc *planContext) linearizeFSmetric(metric motionplan.StateFSMetric) ik.Cos
 	}
 }
 
+func (pc *planContext) linearizeFSErrors(errs func(*motionplan.StateFS) []float64) ik.ErrorFunc {
+	return func(ctx context.Context, linearizedInputs []float64) []float64 {
+		conf, err := pc.lis.FloatsToInputs(linearizedInputs)
+		if err != nil {
+			return []float64{math.Inf(1)}
+		}
+
+		return errs(&motionplan.StateFS{
+			Configuration: conf,
+			FS:            pc.fs,
+		})
+	}
+}
+
 type planSegmentContext struct {
 	pc *planContext
 
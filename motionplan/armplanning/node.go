@@ -439,6 +439,12 @@ func getSolutions(ctx context.Context, psc *planSegmentContext, logger logging.L
 
 	// Spawn the IK solver to generate solutions until done
 	minFunc := psc.pc.linearizeFSmetric(psc.pc.planOpts.getGoalMetric(psc.goal))
+	errorFunc := psc.pc.linearizeFSErrors(psc.pc.planOpts.getGoalErrors(psc.goal))
+
+	// Arms with closed form IK don't need the numeric solver, unless none of their solutions meet the constraints.
+	if solvingState.solveAnalytically(ctx, minFunc) {
+		return solvingState.sortedSolutions(nil)
+	}
 
 	ctxWithCancel, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -471,8 +477,8 @@ func getSolutions(ctx context.Context, psc *planSegmentContext, logger logging.L
 	utils.PanicCapturingGo(func() {
 		// This channel close doubles as signaling that the goroutine has exited.
 		defer close(solutionGen)
-		nSol, m, err := solver.Solve(ctxWithCancel, solutionGen, &solvingState.totalIkAttempts,
-			solvingState.linearSeeds, solvingState.seedLimits, minFunc, psc.pc.randseed.Int())
+		nSol, m, err := solver.SolveErrors(ctxWithCancel, solutionGen, &solvingState.totalIkAttempts,
+			solvingState.linearSeeds, solvingState.seedLimits, minFunc, errorFunc, psc.pc.randseed.Int())
 		if err == nil {
 			solvingState.logger.Debugf("Solver stopped, no errors. Solutions: %v IK Meta: %v", nSol, m)
 		} else {
@@ -529,16 +535,20 @@ solutionLoop:
 		return nil, solvingState.failures
 	}
 
-	sort.Slice(solvingState.solutions, func(i, j int) bool {
-		return solvingState.solutions[i].cost < solvingState.solutions[j].cost
+	return solvingState.sortedSolutions(solveMeta)
+}
+
+// sortedSolutions returns the solutions found, from lowest to highest cost.
+func (sss *solutionSolvingState) sortedSolutions(solveMeta []ik.SeedSolveMetaData) ([]*node, error) {
+	sort.Slice(sss.solutions, func(i, j int) bool {
+		return sss.solutions[i].cost < sss.solutions[j].cost
 	})
 
-	err = solvingState.debugSeedInfoForWinner(solvingState.solutions[0].inputs, solveMeta)
-	if err != nil {
+	if err := sss.debugSeedInfoForWinner(sss.solutions[0].inputs, solveMeta); err != nil {
 		return nil, err
 	}
 
-	return solvingState.solutions, nil
+	return sss.solutions, nil
 }
 
 // neutralBias computes a small cost penalty for rotational joints that are far from the center of their range.
@@ -565,7 +575,7 @@ func (sss *solutionSolvingState) debugSeedInfoForWinner(winner *referenceframe.L
 	var builder strings.Builder
 	fmt.Fprintf(&builder, "\n")
 
-	inValid := make([]bool, len(solveMeta))
+	inValid := make([]bool, len(sss.linearSeeds))
 
 	for _, frameName := range sss.psc.pc.fs.FrameNames() {
 		f := sss.psc.pc.fs.Frame(frameName)
@@ -194,6 +194,34 @@ func BenchmarkGoalMetric(b *testing.B) {
 	}
 }
 
+func TestGoalErrors(t *testing.T) {
+	armModel, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "xarm6")
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("")
+	test.That(t, fs.AddFrame(armModel, fs.World()), test.ShouldBeNil)
+	goals := referenceframe.FrameSystemPoses{"xarm6": referenceframe.NewPoseInFrame("world", spatialmath.NewPose(
+		r3.Vector{X: 300, Y: 100, Z: 200}, &spatialmath.OrientationVectorDegrees{OX: 1, Theta: 30},
+	))}
+
+	// the sum of the squares of the errors is the goal metric
+	for _, metricType := range []motionplan.GoalMetricType{motionplan.SquaredNorm, motionplan.PositionOnly} {
+		options := &PlannerOptions{GoalMetricType: metricType}
+		metricFn, errorsFn := options.getGoalMetric(goals), options.getGoalErrors(goals)
+		for _, inputs := range [][]referenceframe.Input{{0, 0, 0, 0, 0, 0}, {-1.335, -1.334, -1.339, -1.338, -1.337, -1.336}} {
+			inps := referenceframe.NewLinearInputs()
+			inps.Put("xarm6", inputs)
+			state := &motionplan.StateFS{Configuration: inps, FS: fs}
+			errs := errorsFn(state)
+			test.That(t, errs, test.ShouldHaveLength, 6)
+			sum := 0.
+			for _, e := range errs {
+				sum += e * e
+			}
+			test.That(t, sum, test.ShouldAlmostEqual, metricFn(state), 1e-6)
+		}
+	}
+}
+
 // Not exactly an `armplanning` benchmark, but it's an important part of calling the scoring
 // metric. This can help isolate if scoring is slow because armplan scores are slow, or if its
 // because framesystem transformations are slow.
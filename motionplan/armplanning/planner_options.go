@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"sort"
 	"time"
 
 	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/utils"
 )
 
@@ -175,6 +177,38 @@ func (p *PlannerOptions) getGoalMetric(goals referenceframe.FrameSystemPoses) mo
 	}
 }
 
+// getGoalErrors returns the errors of the goal metric for solvers which drive errors to zero: for each goal, in the
+// order of their frame names, the difference of the points followed by the scaled axis-angle rotation between the
+// orientations. The sum of their squares is the goal metric.
+func (p *PlannerOptions) getGoalErrors(goals referenceframe.FrameSystemPoses) func(*motionplan.StateFS) []float64 {
+	orientScale := 100.0
+	if p.GoalMetricType == motionplan.PositionOnly {
+		orientScale = 0
+	}
+	frames := make([]string, 0, len(goals))
+	for frame := range goals {
+		frames = append(frames, frame)
+	}
+	sort.Strings(frames)
+
+	return func(state *motionplan.StateFS) []float64 {
+		errs := make([]float64, 0, 6*len(frames))
+		for _, frame := range frames {
+			goal := goals[frame]
+			dq, err := state.FS.TransformToDQ(state.Configuration, frame, goal.Parent())
+			if err != nil {
+				panic(fmt.Errorf("frame: %v goal parent: %s", frame, goal.Parent()))
+			}
+			point := dq.Point().Sub(goal.Pose().Point())
+			rotation := spatialmath.QuatToR3AA(spatialmath.OrientationBetween(
+				goal.Pose().Orientation(), dq.Orientation(),
+			).Quaternion()).Mul(orientScale)
+			errs = append(errs, point.X, point.Y, point.Z, rotation.X, rotation.Y, rotation.Z)
+		}
+		return errs
+	}
+}
+
 // SetMaxSolutions sets the maximum number of IK solutions to generate for the planner.
 func (p *PlannerOptions) SetMaxSolutions(maxSolutions int) {
 	p.MaxSolutions = maxSolutions
@@ -0,0 +1,477 @@
+package ik
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// analyticDoF is the number of joints of the chains that can be solved analytically.
+	analyticDoF = 6
+	// analyticGeometryEpsilon is the tolerance, in mm and radians, within which joint axes are considered parallel or
+	// intersecting.
+	analyticGeometryEpsilon = 1e-6
+	// analyticSolutionEpsilon is the tolerance, in mm and radians, within which a solution must reach
+	// the goal.
+	analyticSolutionEpsilon = 1e-5
+	// analyticJointStep is the largest joint motion used to measure the axis of each joint.
+	analyticJointStep = 1.0
+)
+
+// ErrNotAnalyticChain is returned when a kinematic chain does not have a closed form IK solution.
+var ErrNotAnalyticChain = errors.New("kinematic chain has no analytic IK solution")
+
+// FKFunc returns the pose of the end of a kinematic chain for the given joint positions.
+type FKFunc func([]float64) (spatialmath.Pose, error)
+
+// AnalyticWrist is the family of wrist for which an AnalyticIK solves.
+type AnalyticWrist string
+
+const (
+	// SphericalWrist arms have the axes of their last three joints intersect in a single point, and the axes of their
+	// second and third joints parallel, like the PUMA and most industrial arms.
+	SphericalWrist AnalyticWrist = "spherical"
+	// OffsetWrist arms have the axes of their second, third and fourth joints parallel, and the axes of their last two
+	// joints intersecting, like the Universal Robots arms.
+	OffsetWrist AnalyticWrist = "offset"
+)
+
+// AnalyticIK solves the IK of 6 DoF serial chains of revolute joints in closed form. The joint axes are measured from
+// the forward kinematics of the chain, as its product of exponentials, and the chain is solved by reducing it to
+// Paden-Kahan subproblems, which is possible for the families of arms listed in AnalyticWrist. This returns up to 8
+// branches of solutions for every goal, with no iteration and the same result every time.
+type AnalyticIK struct {
+	fk     FKFunc
+	limits []referenceframe.Limit
+	wrist  AnalyticWrist
+
+	// reference is the configuration at which the axes and home are measured.
+	reference []float64
+	home      spatialmath.Pose
+	axes      [analyticDoF]screwAxis
+
+	// normal is the direction of the parallel axes.
+	normal r3.Vector
+	// center is where the axes of the spherical wrist intersect, or where the axes of the last two joints of an offset
+	// wrist intersect.
+	center r3.Vector
+}
+
+// CreateAnalyticSolver creates an AnalyticIK for the kinematic chain with the given forward kinematics and joint limits.
+// It returns an error wrapping ErrNotAnalyticChain if the chain is not of a family it can solve.
+func CreateAnalyticSolver(fk FKFunc, limits []referenceframe.Limit) (*AnalyticIK, error) {
+	if len(limits) != analyticDoF {
+		return nil, fmt.Errorf("%w: has %d joints, not %d", ErrNotAnalyticChain, len(limits), analyticDoF)
+	}
+	ik := &AnalyticIK{fk: fk, limits: limits, reference: make([]float64, analyticDoF)}
+	for i, limit := range limits {
+		ik.reference[i] = math.Min(math.Max(0, limit.Min), limit.Max)
+	}
+	homePose, err := fk(ik.reference)
+	if err != nil {
+		return nil, err
+	}
+	ik.home = homePose
+	homeInverse := spatialmath.PoseInverse(homePose)
+
+	// with the other joints at their reference, moving a joint moves the end as a rotation about its axis
+	for i, limit := range limits {
+		step := math.Min(analyticJointStep, limit.Max-ik.reference[i])
+		if down := math.Min(analyticJointStep, ik.reference[i]-limit.Min); down > step {
+			step = -down
+		}
+		if math.Abs(step) < 0.1 {
+			return nil, fmt.Errorf("%w: joint %d has too small a range", ErrNotAnalyticChain, i)
+		}
+		inputs := append([]float64{}, ik.reference...)
+		inputs[i] += step
+		pose, err := fk(inputs)
+		if err != nil {
+			return nil, err
+		}
+		axis, ok := screwAxisFromMotion(spatialmath.Compose(pose, homeInverse), step)
+		if !ok {
+			return nil, fmt.Errorf("%w: joint %d is not revolute", ErrNotAnalyticChain, i)
+		}
+		ik.axes[i] = axis
+	}
+
+	if err := ik.classify(); err != nil {
+		return nil, err
+	}
+
+	// the product of exponentials only describes serial chains, so check it against the forward kinematics
+	for _, fraction := range [][]float64{{.2, .7, .4, .9, .1, .6}, {.8, .3, .6, .2, .5, .9}} {
+		inputs := make([]float64, analyticDoF)
+		for i, limit := range limits {
+			lower, _, jointRange := limit.GoodLimits()
+			inputs[i] = lower + fraction[i]*jointRange
+		}
+		pose, err := fk(inputs)
+		if err != nil {
+			return nil, err
+		}
+		if !posesAlmostEqual(pose, ik.forward(inputs), analyticSolutionEpsilon) {
+			return nil, fmt.Errorf("%w: not a serial chain of revolute joints", ErrNotAnalyticChain)
+		}
+	}
+	return ik, nil
+}
+
+// CreateAnalyticSolverForFrame creates an AnalyticIK for the given frame, such as an arm model.
+func CreateAnalyticSolverForFrame(frame referenceframe.Frame) (*AnalyticIK, error) {
+	return CreateAnalyticSolver(frame.Transform, frame.DoF())
+}
+
+// Wrist returns the family of wrist of the chain.
+func (ik *AnalyticIK) Wrist() AnalyticWrist {
+	return ik.wrist
+}
+
+// Solutions returns every configuration within the joint limits that reaches the goal exactly, one for each branch of
+// the solution. Joints which can reach the same angle more than once within their limits are given the position
+// closest to the seed, which defaults to the middle of the limits if nil.
+func (ik *AnalyticIK) Solutions(goal spatialmath.Pose, seed []float64) [][]float64 {
+	if seed == nil {
+		seed = ik.reference
+	}
+	g := spatialmath.Compose(goal, spatialmath.PoseInverse(ik.home))
+	var branches [][analyticDoF]float64
+	if ik.wrist == SphericalWrist {
+		branches = ik.solveSphericalWrist(g)
+	} else {
+		branches = ik.solveOffsetWrist(g)
+	}
+
+	var solutions [][]float64
+branchLoop:
+	for _, branch := range branches {
+		solution := make([]float64, analyticDoF)
+		for i, angle := range branch {
+			position, ok := wrapToLimit(ik.reference[i]+angle, seed[i], ik.limits[i])
+			if !ok {
+				continue branchLoop
+			}
+			solution[i] = position
+		}
+		for _, other := range solutions {
+			if configurationsAlmostEqual(solution, other) {
+				continue branchLoop
+			}
+		}
+		pose, err := ik.fk(solution)
+		if err != nil || !posesAlmostEqual(pose, goal, analyticSolutionEpsilon) {
+			continue
+		}
+		solutions = append(solutions, solution)
+	}
+	return solutions
+}
+
+// classify determines the family of wrist from the axes.
+func (ik *AnalyticIK) classify() error {
+	axes := ik.axes
+	ik.normal = axes[1].direction
+	if !parallel(axes[2].direction, ik.normal) {
+		return fmt.Errorf("%w: the axes of joints 1 and 2 are not parallel", ErrNotAnalyticChain)
+	}
+	if parallel(axes[0].direction, ik.normal) {
+		return fmt.Errorf("%w: the axes of joints 0 and 1 are parallel", ErrNotAnalyticChain)
+	}
+	if axes[2].distance(axes[1].point) < analyticGeometryEpsilon {
+		return fmt.Errorf("%w: the axes of joints 1 and 2 are the same", ErrNotAnalyticChain)
+	}
+
+	if center, ok := intersection(axes[3], axes[4]); ok && axes[5].distance(center) < analyticGeometryEpsilon &&
+		!parallel(axes[4].direction, axes[5].direction) && axes[2].distance(center) > analyticGeometryEpsilon {
+		ik.wrist = SphericalWrist
+		ik.center = center
+		return nil
+	}
+
+	center, ok := intersection(axes[4], axes[5])
+	if ok && parallel(axes[3].direction, ik.normal) && !parallel(axes[4].direction, ik.normal) &&
+		axes[2].distance(axes[3].point) > analyticGeometryEpsilon {
+		ik.wrist = OffsetWrist
+		ik.center = center
+		return nil
+	}
+	return fmt.Errorf("%w: the wrist is neither spherical nor offset", ErrNotAnalyticChain)
+}
+
+// forward returns the pose of the chain from its product of exponentials.
+func (ik *AnalyticIK) forward(inputs []float64) spatialmath.Pose {
+	result := ik.home
+	for i := analyticDoF - 1; i >= 0; i-- {
+		result = spatialmath.Compose(ik.axes[i].exp(inputs[i]-ik.reference[i]), result)
+	}
+	return result
+}
+
+// solveSphericalWrist returns the joint angles, relative to the reference, for which e1 e2 ... e6 = g. The first three
+// joints place the wrist center, and the last three orient the wrist about it.
+func (ik *AnalyticIK) solveSphericalWrist(g spatialmath.Pose) [][analyticDoF]float64 {
+	axes := ik.axes
+	var branches [][analyticDoF]float64
+	p := transformPoint(g, ik.center)
+	// joints 1 and 2 move the wrist center in the plane normal to their axes, so joint 0 has to bring it to that plane
+	for _, back := range projectionAngles(axes[0], p, ik.normal, ik.normal.Dot(ik.center)) {
+		q0 := -back
+		inPlane := transformPoint(axes[0].exp(back), p)
+		reach := inPlane.Sub(axes[1].point).Norm()
+		for _, q2 := range distanceAngles(axes[2], ik.center, axes[1].point, reach) {
+			q1, ok := rotationAngle(axes[1], transformPoint(axes[2].exp(q2), ik.center), inPlane)
+			if !ok {
+				continue
+			}
+			wrist := spatialmath.Compose(spatialmath.Compose(spatialmath.Compose(axes[2].exp(-q2), axes[1].exp(-q1)), axes[0].exp(-q0)), g)
+			// a point on the last axis is only moved by joints 3 and 4
+			onLast := ik.center.Add(axes[5].direction)
+			for _, q34 := range twoAxisAngles(axes[3], axes[4], ik.center, onLast, transformPoint(wrist, onLast)) {
+				offLast := ik.center.Add(perpendicular(axes[5].direction))
+				remaining := spatialmath.Compose(spatialmath.Compose(axes[4].exp(-q34[1]), axes[3].exp(-q34[0])), wrist)
+				q5, ok := rotationAngle(axes[5], offLast, transformPoint(remaining, offLast))
+				if !ok {
+					continue
+				}
+				branches = append(branches, [analyticDoF]float64{q0, q1, q2, q34[0], q34[1], q5})
+			}
+		}
+	}
+	return branches
+}
+
+// solveOffsetWrist returns the joint angles, relative to the reference, for which e1 e2 ... e6 = g. The joints with
+// parallel axes don't change the component of the last axis along them, which determines joint 4, after which the
+// orientation determines joint 5 and the position the parallel joints.
+func (ik *AnalyticIK) solveOffsetWrist(g spatialmath.Pose) [][analyticDoF]float64 {
+	axes := ik.axes
+	var branches [][analyticDoF]float64
+	p := transformPoint(g, ik.center)
+	origin5 := screwAxis{direction: axes[4].direction}
+	origin6 := screwAxis{direction: axes[5].direction}
+	for _, back := range projectionAngles(axes[0], p, ik.normal, ik.normal.Dot(ik.center)) {
+		q0 := -back
+		// a = e2 e3 e4 e5 e6
+		a := spatialmath.Compose(axes[0].exp(back), g)
+		lastAxis := rotatePoint(a.Orientation(), axes[5].direction)
+		for _, q4 := range projectionAngles(origin5, axes[5].direction, ik.normal, ik.normal.Dot(lastAxis)) {
+			// the parallel joints don't move the normal, so e6^-1 e5^-1 normal = a^-1 normal
+			unmoved := rotatePoint(spatialmath.OrientationInverse(a.Orientation()), ik.normal)
+			q5, ok := rotationAngle(origin6, unmoved, transformPoint(origin5.exp(-q4), ik.normal))
+			if !ok {
+				// at the wrist singularity joints 3 and 5 are aligned, and joint 3 can make up for joint 5
+				q5 = 0
+			}
+			// parallel = e2 e3 e4
+			parallelJoints := spatialmath.Compose(spatialmath.Compose(a, axes[5].exp(-q5)), axes[4].exp(-q4))
+			elbow := axes[3].point
+			target := transformPoint(parallelJoints, elbow)
+			reach := target.Sub(axes[1].point).Norm()
+			for _, q2 := range distanceAngles(axes[2], elbow, axes[1].point, reach) {
+				q1, ok := rotationAngle(axes[1], transformPoint(axes[2].exp(q2), elbow), target)
+				if !ok {
+					continue
+				}
+				remaining := spatialmath.Compose(spatialmath.Compose(axes[2].exp(-q2), axes[1].exp(-q1)), parallelJoints)
+				offAxis := elbow.Add(perpendicular(axes[3].direction))
+				q3, ok := rotationAngle(axes[3], offAxis, transformPoint(remaining, offAxis))
+				if !ok {
+					continue
+				}
+				branches = append(branches, [analyticDoF]float64{q0, q1, q2, q3, q4, q5})
+			}
+		}
+	}
+	return branches
+}
+
+// wrapToLimit returns the position within the limit equivalent to angle modulo 2pi which is closest to seed.
+func wrapToLimit(angle, seed float64, limit referenceframe.Limit) (float64, bool) {
+	best, found := 0., false
+	nearest := angle + 2*math.Pi*math.Round((seed-angle)/(2*math.Pi))
+	for _, position := range []float64{nearest - 2*math.Pi, nearest, nearest + 2*math.Pi} {
+		if position < limit.Min-analyticGeometryEpsilon || position > limit.Max+analyticGeometryEpsilon {
+			continue
+		}
+		position = math.Min(math.Max(position, limit.Min), limit.Max)
+		if !found || math.Abs(position-seed) < math.Abs(best-seed) {
+			best, found = position, true
+		}
+	}
+	return best, found
+}
+
+func configurationsAlmostEqual(a, b []float64) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > analyticGeometryEpsilon {
+			return false
+		}
+	}
+	return true
+}
+
+// screwAxis is the axis of a revolute joint.
+type screwAxis struct {
+	direction r3.Vector
+	point     r3.Vector
+}
+
+// screwAxisFromMotion returns the axis of a rotation by angle, which must be less than a half turn, if motion is one.
+func screwAxisFromMotion(motion spatialmath.Pose, angle float64) (screwAxis, bool) {
+	// the rotation vector of the motion is the axis scaled by the angle
+	direction := motion.Orientation().AxisAngles().ToR3().Mul(1 / angle)
+	if math.Abs(direction.Norm()-1) > analyticGeometryEpsilon {
+		return screwAxis{}, false
+	}
+	direction = direction.Normalize()
+	t := motion.Point()
+	if math.Abs(direction.Dot(t)) > analyticGeometryEpsilon*(1+t.Norm()) {
+		return screwAxis{}, false
+	}
+	// a rotation by angle about an axis through p, normal to the axis, translates by t = p - R p
+	point := t.Add(direction.Cross(t).Mul(1 / math.Tan(angle/2))).Mul(0.5)
+	return screwAxis{direction: direction, point: point}, true
+}
+
+// exp returns the motion of rotating about the axis by angle.
+func (s screwAxis) exp(angle float64) spatialmath.Pose {
+	rotation := &spatialmath.R4AA{Theta: angle, RX: s.direction.X, RY: s.direction.Y, RZ: s.direction.Z}
+	return spatialmath.NewPose(s.point.Sub(rotatePoint(rotation, s.point)), rotation)
+}
+
+// distance returns the distance of x from the axis.
+func (s screwAxis) distance(x r3.Vector) float64 {
+	return x.Sub(s.point).Cross(s.direction).Norm()
+}
+
+func parallel(a, b r3.Vector) bool {
+	return a.Cross(b).Norm() < analyticGeometryEpsilon
+}
+
+// intersection returns where two axes intersect, if they do and aren't parallel.
+func intersection(a, b screwAxis) (r3.Vector, bool) {
+	if parallel(a.direction, b.direction) {
+		return r3.Vector{}, false
+	}
+	w := a.point.Sub(b.point)
+	cos := a.direction.Dot(b.direction)
+	d, e := a.direction.Dot(w), b.direction.Dot(w)
+	denominator := 1 - cos*cos
+	onA := a.point.Add(a.direction.Mul((cos*e - d) / denominator))
+	onB := b.point.Add(b.direction.Mul((e - cos*d) / denominator))
+	if onA.Sub(onB).Norm() > analyticGeometryEpsilon {
+		return r3.Vector{}, false
+	}
+	return onA.Add(onB).Mul(0.5), true
+}
+
+// perpendicular returns a unit vector perpendicular to v.
+func perpendicular(v r3.Vector) r3.Vector {
+	return v.Cross(v.Ortho()).Normalize()
+}
+
+// rotationAngle solves Paden-Kahan subproblem 1, returning the angle about the axis which rotates x to y, or false if x
+// is on the axis.
+func rotationAngle(s screwAxis, x, y r3.Vector) (float64, bool) {
+	u, v := x.Sub(s.point), y.Sub(s.point)
+	u = u.Sub(s.direction.Mul(s.direction.Dot(u)))
+	v = v.Sub(s.direction.Mul(s.direction.Dot(v)))
+	if u.Norm() < analyticGeometryEpsilon || v.Norm() < analyticGeometryEpsilon {
+		return 0, false
+	}
+	return math.Atan2(s.direction.Dot(u.Cross(v)), u.Dot(v)), true
+}
+
+// distanceAngles solves Paden-Kahan subproblem 3, returning the angles about the axis which rotate x to distance from y.
+func distanceAngles(s screwAxis, x, y r3.Vector, distance float64) []float64 {
+	u, v := x.Sub(s.point), y.Sub(s.point)
+	along := s.direction.Dot(u.Sub(v))
+	u = u.Sub(s.direction.Mul(s.direction.Dot(u)))
+	v = v.Sub(s.direction.Mul(s.direction.Dot(v)))
+	radii := u.Norm() * v.Norm()
+	if radii < analyticGeometryEpsilon {
+		return nil
+	}
+	angle := math.Atan2(s.direction.Dot(u.Cross(v)), u.Dot(v))
+	cos := (u.Norm2() + v.Norm2() - (distance*distance - along*along)) / (2 * radii)
+	return angleOffsets(angle, cos)
+}
+
+// projectionAngles returns the angles about the axis which rotate x to where its component along n is target.
+func projectionAngles(s screwAxis, x, n r3.Vector, target float64) []float64 {
+	u := x.Sub(s.point)
+	along := s.direction.Mul(s.direction.Dot(u))
+	// n . (R u) = n . along + cos n . (u - along) + sin n . (direction x u)
+	a, b := n.Dot(u.Sub(along)), n.Dot(s.direction.Cross(u))
+	amplitude := math.Hypot(a, b)
+	if amplitude < analyticGeometryEpsilon {
+		return nil
+	}
+	return angleOffsets(math.Atan2(b, a), (target-n.Dot(s.point)-n.Dot(along))/amplitude)
+}
+
+// twoAxisAngles solves Paden-Kahan subproblem 2, returning the angles about the axes a and b, which intersect at
+// center, such that rotating x about b and then about a moves it to y.
+func twoAxisAngles(a, b screwAxis, center, x, y r3.Vector) [][2]float64 {
+	u, v := x.Sub(center), y.Sub(center)
+	cos := a.direction.Dot(b.direction)
+	denominator := cos*cos - 1
+	alpha := (cos*b.direction.Dot(u) - a.direction.Dot(v)) / denominator
+	beta := (cos*a.direction.Dot(v) - b.direction.Dot(u)) / denominator
+	normal := a.direction.Cross(b.direction)
+	gammaSquared := (u.Norm2() - alpha*alpha - beta*beta - 2*alpha*beta*cos) / normal.Norm2()
+	if gammaSquared < -analyticGeometryEpsilon {
+		return nil
+	}
+	gamma := math.Sqrt(math.Max(0, gammaSquared))
+	var angles [][2]float64
+	for _, sign := range []float64{1, -1} {
+		z := center.Add(a.direction.Mul(alpha)).Add(b.direction.Mul(beta)).Add(normal.Mul(sign * gamma))
+		angleB, okB := rotationAngle(b, x, z)
+		angleA, okA := rotationAngle(a, z, y)
+		if !okA || !okB {
+			continue
+		}
+		angles = append(angles, [2]float64{angleA, angleB})
+		if gamma < analyticGeometryEpsilon {
+			break
+		}
+	}
+	return angles
+}
+
+// angleOffsets returns the angles offset from angle by the arccosine of cos, tolerating rounding past +-1.
+func angleOffsets(angle, cos float64) []float64 {
+	if math.Abs(cos) > 1+analyticGeometryEpsilon {
+		return nil
+	}
+	offset := math.Acos(math.Min(math.Max(cos, -1), 1))
+	if offset < analyticGeometryEpsilon {
+		return []float64{angle}
+	}
+	return []float64{angle + offset, angle - offset}
+}
+
+// transformPoint returns where the motion of pose takes the point x.
+func transformPoint(pose spatialmath.Pose, x r3.Vector) r3.Vector {
+	return spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(x)).Point()
+}
+
+// rotatePoint returns where the orientation rotates the point x.
+func rotatePoint(o spatialmath.Orientation, x r3.Vector) r3.Vector {
+	return transformPoint(spatialmath.NewPoseFromOrientation(o), x)
+}
+
+// posesAlmostEqual returns whether the poses are within epsilon mm and epsilon radians of each other.
+func posesAlmostEqual(a, b spatialmath.Pose, epsilon float64) bool {
+	// the orientations are compared by the square of the angle between them
+	return spatialmath.PoseAlmostCoincidentEps(a, b, epsilon) &&
+		spatialmath.OrientationAlmostEqualEps(a.Orientation(), b.Orientation(), epsilon*epsilon)
+}
@@ -0,0 +1,59 @@
+package ik
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestAnalyticIK(t *testing.T) {
+	for name, wrist := range map[string]AnalyticWrist{"lite6": SphericalWrist, "ur5e": OffsetWrist, "ur20": OffsetWrist} {
+		t.Run(name, func(t *testing.T) {
+			m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/"+name+".json"), "")
+			test.That(t, err, test.ShouldBeNil)
+			ik, err := CreateAnalyticSolverForFrame(m)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, ik.Wrist(), test.ShouldEqual, wrist)
+
+			randSeed := rand.New(rand.NewSource(1)) //nolint: gosec
+			for range 100 {
+				configuration := referenceframe.GenerateRandomConfiguration(m, randSeed)
+				goal, err := m.Transform(configuration)
+				test.That(t, err, test.ShouldBeNil)
+
+				solutions := ik.Solutions(goal, configuration)
+				test.That(t, len(solutions), test.ShouldBeBetweenOrEqual, 1, 8)
+				found := false
+				for _, solution := range solutions {
+					pose, err := m.Transform(solution)
+					test.That(t, err, test.ShouldBeNil)
+					test.That(t, spatialmath.PoseAlmostEqualEps(pose, goal, 1e-4), test.ShouldBeTrue)
+					found = found || configurationsAlmostEqual(solution, configuration)
+				}
+				// every branch is returned, including the one the goal came from
+				test.That(t, found, test.ShouldBeTrue)
+				test.That(t, ik.Solutions(goal, configuration), test.ShouldResemble, solutions)
+			}
+
+			// out of reach
+			test.That(t, ik.Solutions(spatialmath.NewPoseFromPoint(r3.Vector{X: 1e5}), nil), test.ShouldBeEmpty)
+		})
+	}
+
+	for name, reason := range map[string]string{"xarm6": "neither spherical nor offset", "xarm7": "7 joints", "dofbot": "5 joints"} {
+		t.Run(name, func(t *testing.T) {
+			m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/"+name+".json"), "")
+			test.That(t, err, test.ShouldBeNil)
+			_, err = CreateAnalyticSolverForFrame(m)
+			test.That(t, errors.Is(err, ErrNotAnalyticChain), test.ShouldBeTrue)
+			test.That(t, err.Error(), test.ShouldContainSubstring, reason)
+		})
+	}
+}
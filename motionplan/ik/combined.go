@@ -15,13 +15,13 @@ import (
 
 // CombinedIK defines the fields necessary to run a combined solver.
 type CombinedIK struct {
-	solvers []*NloptIK
+	solvers []Solver
 	logger  logging.Logger
 }
 
-// CreateCombinedIKSolver creates a combined parallel IK solver that operates on a frame with a number of nlopt solvers equal to the
-// nCPU passed in. Each will be given a different random seed. When asked to solve, all solvers will be run in parallel
-// and the first valid found solution will be returned.
+// CreateCombinedIKSolver creates a combined parallel IK solver that operates on a frame with a number of solvers equal to the
+// nCPU passed in, created with CreateSolver. Each will be given a different random seed. When asked to solve, all solvers will be
+// run in parallel and the first valid found solution will be returned.
 func CreateCombinedIKSolver(
 	logger logging.Logger,
 	nCPU int,
@@ -33,11 +33,11 @@ func CreateCombinedIKSolver(
 	}
 
 	for i := 1; i <= nCPU; i++ {
-		nloptSolver, err := CreateNloptSolver(logger, -1, true, true, maxTime)
+		solver, err := CreateSolver(logger, -1, true, true, maxTime)
 		if err != nil {
 			return nil, err
 		}
-		ik.solvers = append(ik.solvers, nloptSolver)
+		ik.solvers = append(ik.solvers, solver)
 	}
 	return ik, nil
 }
@@ -51,6 +51,20 @@ func (ik *CombinedIK) Solve(ctx context.Context,
 	limits [][]referenceframe.Limit,
 	costFunc CostFunc,
 	rseed int,
+) (int, []SeedSolveMetaData, error) {
+	return ik.SolveErrors(ctx, retChan, totalAttempts, seeds, limits, costFunc, nil, rseed)
+}
+
+// SolveErrors is Solve, except that child solvers which drive errors to zero, such as a DLSIK, solve for the errors of
+// errorFunc rather than for the cost, if errorFunc isn't nil. The sum of the squares of the errors must be the cost.
+func (ik *CombinedIK) SolveErrors(ctx context.Context,
+	retChan chan<- *Solution,
+	totalAttempts *atomic.Int32,
+	seeds [][]float64,
+	limits [][]referenceframe.Limit,
+	costFunc CostFunc,
+	errorFunc ErrorFunc,
+	rseed int,
 ) (int, []SeedSolveMetaData, error) {
 	var activeSolvers sync.WaitGroup
 	defer activeSolvers.Wait()
@@ -69,7 +83,14 @@ func (ik *CombinedIK) Solve(ctx context.Context,
 		utils.PanicCapturingGo(func() {
 			defer activeSolvers.Done()
 
-			n, m, err := thisSolver.Solve(ctx, retChan, totalAttempts, seeds, limits, costFunc, myseed)
+			var n int
+			var m []SeedSolveMetaData
+			var err error
+			if errorSolver, ok := thisSolver.(ErrorSolver); ok && errorFunc != nil {
+				n, m, err = errorSolver.SolveErrors(ctx, retChan, totalAttempts, seeds, limits, errorFunc, myseed)
+			} else {
+				n, m, err = thisSolver.Solve(ctx, retChan, totalAttempts, seeds, limits, costFunc, myseed)
+			}
 
 			solveResultLock.Lock()
 			defer solveResultLock.Unlock()
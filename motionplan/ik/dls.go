@@ -0,0 +1,309 @@
+package ik
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+)
+
+const (
+	// dlsDefaultMaxIter is the number of error evaluations used when none is given, matching nlopt.
+	dlsDefaultMaxIter = 5000
+	// dlsStepsPerAttempt bounds the damped steps taken from each seed before restarting from a random one.
+	dlsStepsPerAttempt = 200
+	// dlsDiffStep is the finite difference step used to estimate the Jacobian of the errors.
+	dlsDiffStep = 1e-6
+	// dlsInitialDamping is the squared damping of the first step from each seed, relative to the scale of JJᵀ.
+	dlsInitialDamping = 1e-3
+	// dlsMaxDamping is the squared damping beyond which steps are too small to reduce the errors, ending the attempt.
+	dlsMaxDamping = 1e10
+	// dlsMinImprovement is the relative improvement of the cost below which an attempt has converged.
+	dlsMinImprovement = 1e-12
+)
+
+// DLSIK is a pure Go solver, which can solve IK problems on builds without nlopt. From each seed it takes damped least
+// squares steps Δq = Jᵀ(JJᵀ+λ²I)⁻¹(-e) on the errors e of an ErrorFunc, such as the pose error of NewPoseErrorFunc,
+// where J is the Jacobian of the errors, estimated by finite differences. The damping λ² shrinks after every step
+// which reduces the cost and grows until one does. Joints at their limits which the step pushes past them are held in
+// place, and steps are clamped to the limits. Once the steps from a seed stop reducing the cost, it restarts from a
+// random seed.
+//
+// Solve minimizes a cost function rather than errors, which it treats as the single error √cost.
+type DLSIK struct {
+	maxIterations int
+	logger        logging.Logger
+
+	// If exact is false, then the solver will emit partial solutions where it was not able to meet the goal criteria
+	// but still was able to improve upon the seed.
+	exact bool
+
+	maxTime time.Duration
+}
+
+// CreateDLSSolver creates a DLSIK. Like CreateNloptSolver, iter is the number of error evaluations after which to stop
+// restarting from random seeds, which defaults to 5000 if less than 1, and solving continues until maxTime if iter is
+// at least 10.
+func CreateDLSSolver(logger logging.Logger, iter int, exact bool, maxTime time.Duration) (*DLSIK, error) {
+	if iter < 1 {
+		iter = dlsDefaultMaxIter
+	}
+	return &DLSIK{maxIterations: iter, logger: logger, exact: exact, maxTime: maxTime}, nil
+}
+
+// Solve runs the solver on the single error √cost of the cost function and sends any solutions found to the given
+// channel.
+func (ik *DLSIK) Solve(ctx context.Context,
+	solutionChan chan<- *Solution,
+	totalAttempts *atomic.Int32,
+	seeds [][]float64,
+	limits [][]referenceframe.Limit,
+	minFunc CostFunc,
+	rseed int,
+) (int, []SeedSolveMetaData, error) {
+	var errorFunc ErrorFunc
+	if minFunc != nil {
+		errorFunc = func(ctx context.Context, inputs []float64) []float64 {
+			return []float64{math.Sqrt(math.Max(minFunc(ctx, inputs), 0))}
+		}
+	}
+	return ik.SolveErrors(ctx, solutionChan, totalAttempts, seeds, limits, errorFunc, rseed)
+}
+
+// SolveErrors runs the solver on the errors of the error function and sends any solutions found to the given channel.
+// The score of each solution is its cost, the sum of the squares of its errors.
+func (ik *DLSIK) SolveErrors(ctx context.Context,
+	solutionChan chan<- *Solution,
+	totalAttempts *atomic.Int32,
+	seeds [][]float64,
+	limits [][]referenceframe.Limit,
+	errorFunc ErrorFunc,
+	rseed int,
+) (int, []SeedSolveMetaData, error) {
+	if len(seeds) == 0 {
+		return 0, nil, fmt.Errorf("no seeds")
+	}
+	if len(seeds) != len(limits) {
+		return 0, nil, fmt.Errorf("need matching limits (%d) and seeds (%d) arrays", len(limits), len(seeds))
+	}
+
+	randSeed := rand.New(rand.NewSource(int64(rseed))) //nolint: gosec
+	seedStates := make([]*dlsSeedState, 0, len(seeds))
+	for i, s := range seeds {
+		lowerBound, upperBound := limitsToArrays(limits[i])
+		if len(lowerBound) == 0 {
+			return 0, nil, errBadDLSBounds
+		}
+		seedStates = append(seedStates, &dlsSeedState{
+			seed:       s,
+			lowerBound: lowerBound,
+			upperBound: upperBound,
+			meta:       fmt.Sprintf("s:%d", i),
+		})
+	}
+	meta := make([]SeedSolveMetaData, len(seeds))
+
+	solutionsFound := 0
+	seedNumber := rseed // start randomly in the list
+	iterations := 0
+
+	itStart := time.Now()
+	for (iterations < ik.maxIterations || (ik.maxIterations >= 10 && time.Since(itStart) < ik.maxTime)) && ctx.Err() == nil {
+		iterations++
+
+		seedNumberRanged := seedNumber % len(seedStates)
+		ss := seedStates[seedNumberRanged]
+		meta[seedNumberRanged].Attempts++
+		if totalAttempts != nil {
+			totalAttempts.Add(1)
+		}
+
+		solution, result := ss.descend(ctx, errorFunc, &iterations)
+		ik.logger.Debugf("seed (%d) %v\n\t result: %0.2f res: %v",
+			seedNumberRanged, logging.FloatArrayFormat{"", ss.seed}, result, logging.FloatArrayFormat{"", solution})
+
+		if result < defaultGoalThreshold || (!ik.exact && !math.IsInf(result, 1)) {
+			meta[seedNumberRanged].Valid++
+			select {
+			case <-ctx.Done():
+			case solutionChan <- &Solution{
+				Configuration: solution,
+				Score:         result,
+				Exact:         result < defaultGoalThreshold,
+				Meta:          ss.meta,
+			}:
+				solutionsFound++
+			}
+		}
+		ss.seed = generateRandomPositions(randSeed, ss.lowerBound, ss.upperBound)
+
+		seedNumber++
+	}
+
+	return solutionsFound, meta, nil
+}
+
+var errBadDLSBounds = fmt.Errorf("cannot solve without joint limits. Are you trying to move a static frame?")
+
+type dlsSeedState struct {
+	seed                   []float64
+	lowerBound, upperBound []float64
+	meta                   string
+}
+
+// descend takes damped least squares steps from the seed until the cost meets the goal or stops improving, and
+// returns the best configuration and its cost. Every evaluation of the errors is counted in iterations.
+func (ss *dlsSeedState) descend(ctx context.Context, errorFunc ErrorFunc, iterations *int) ([]float64, float64) {
+	evaluate := func(inputs []float64) ([]float64, float64) {
+		*iterations++
+		e := errorFunc(ctx, inputs)
+		return e, floats.Dot(e, e)
+	}
+	n := len(ss.seed)
+	q := make([]float64, n)
+	for i, v := range ss.seed {
+		q[i] = clampToLimit(v, ss.lowerBound[i], ss.upperBound[i])
+	}
+	e, f := evaluate(q)
+	if math.IsInf(f, 1) || math.IsNaN(f) {
+		return q, math.Inf(1)
+	}
+
+	damping := dlsInitialDamping
+	next := make([]float64, n)
+	for step := 0; step < dlsStepsPerAttempt && f >= defaultGoalThreshold && ctx.Err() == nil; step++ {
+		jacobian, free := ss.jacobian(q, e, evaluate)
+		if len(free) == 0 {
+			break
+		}
+
+		// Solve for Δq = Jᵀ(JJᵀ + damping * scale * I)⁻¹(-e) on the free joints, increasing the damping until the step
+		// reduces the cost. As the damping grows, the step shrinks towards the steepest descent direction.
+		var jjt mat.SymDense
+		jjt.SymOuterK(1, jacobian)
+		scale := 1.0
+		for i := range len(e) {
+			scale = math.Max(scale, jjt.At(i, i))
+		}
+		improved := false
+		var nextE []float64
+		var nextF float64
+		for damping < dlsMaxDamping && ctx.Err() == nil {
+			d, ok := dampedStep(jacobian, &jjt, e, damping*scale)
+			if !ok {
+				damping *= 10
+				continue
+			}
+			copy(next, q)
+			for k, i := range free {
+				next[i] = clampToLimit(q[i]+d[k], ss.lowerBound[i], ss.upperBound[i])
+			}
+			nextE, nextF = evaluate(next)
+			if nextF < f {
+				improved = true
+				damping = math.Max(damping/10, 1e-12)
+				break
+			}
+			damping *= 10
+		}
+		if !improved {
+			break
+		}
+		converged := f-nextF < dlsMinImprovement*math.Max(1, f)
+		copy(q, next)
+		e, f = nextE, nextF
+		if converged {
+			break
+		}
+	}
+	return q, f
+}
+
+// jacobian estimates the Jacobian of the errors at q, whose errors are e, and returns the columns of the joints which
+// are free to move along with those joints. Joints at a limit which reducing the cost pushes past it are not free.
+// Finite differences step towards the inside of the limits, so that the errors are never evaluated out of bounds.
+func (ss *dlsSeedState) jacobian(
+	q, e []float64, evaluate func([]float64) ([]float64, float64),
+) (*mat.Dense, []int) {
+	m, n := len(e), len(q)
+	h := dlsDiffStep
+	columns := mat.NewDense(m, n, nil)
+	var stepped []int
+	x := append([]float64{}, q...)
+	for i := range n {
+		s := 1.0
+		switch {
+		case q[i]+h <= ss.upperBound[i]:
+		case q[i]-h >= ss.lowerBound[i]:
+			s = -1
+		default:
+			// the range of the joint is too small to step in
+			continue
+		}
+		x[i] = q[i] + s*h
+		ei, _ := evaluate(x)
+		x[i] = q[i]
+		if len(ei) != m {
+			// errors which can't be evaluated, such as from failed transforms, can't be stepped with
+			return nil, nil
+		}
+		for k := range m {
+			columns.Set(k, i, (ei[k]-e[k])/(s*h))
+		}
+		stepped = append(stepped, i)
+	}
+
+	// the gradient of the cost is 2Jᵀe, which a joint at a limit must not point away from
+	var free []int
+	for _, i := range stepped {
+		g := floats.Dot(mat.Col(nil, i, columns), e)
+		if math.IsInf(g, 0) || math.IsNaN(g) {
+			return nil, nil
+		}
+		if (q[i] <= ss.lowerBound[i] && g > 0) || (q[i] >= ss.upperBound[i] && g < 0) {
+			continue
+		}
+		free = append(free, i)
+	}
+	if len(free) == 0 {
+		return nil, nil
+	}
+	jacobian := mat.NewDense(m, len(free), nil)
+	for k, i := range free {
+		jacobian.SetCol(k, mat.Col(nil, i, columns))
+	}
+	return jacobian, free
+}
+
+// dampedStep returns Jᵀ(JJᵀ + damping*I)⁻¹(-e), and false if the damped JJᵀ is not positive definite.
+func dampedStep(jacobian *mat.Dense, jjt *mat.SymDense, e []float64, damping float64) ([]float64, bool) {
+	m := len(e)
+	a := mat.NewSymDense(m, nil)
+	a.CopySym(jjt)
+	for i := range m {
+		a.SetSym(i, i, a.At(i, i)+damping)
+	}
+	var chol mat.Cholesky
+	if !chol.Factorize(a) {
+		return nil, false
+	}
+	y := mat.NewVecDense(m, nil)
+	if err := chol.SolveVecTo(y, mat.NewVecDense(m, floats.ScaleTo(make([]float64, m), -1, e))); err != nil {
+		return nil, false
+	}
+	var d mat.VecDense
+	d.MulVec(jacobian.T(), y)
+	return d.RawVector().Data, true
+}
+
+func clampToLimit(v, lo, hi float64) float64 {
+	return math.Min(math.Max(v, lo), hi)
+}
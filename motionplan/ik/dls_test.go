@@ -0,0 +1,185 @@
+package ik
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestCreateDLSSolver(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	for _, name := range []string{"xarm6", "ur5e"} {
+		t.Run(name, func(t *testing.T) {
+			m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/"+name+".json"), "")
+			test.That(t, err, test.ShouldBeNil)
+
+			// a goal reachable from a configuration away from the seed
+			randSeed := rand.New(rand.NewSource(1)) //nolint: gosec
+			lower, upper := limitsToArrays(m.DoF())
+			goal, err := m.Transform(generateRandomPositions(randSeed, lower, upper))
+			test.That(t, err, test.ShouldBeNil)
+			seed := make([]float64, len(m.DoF()))
+			metric := motionplan.NewSquaredNormMetric(goal)
+			solveFunc := NewMetricMinFunc(metric, m, logger)
+
+			ik, err := CreateDLSSolver(logger, -1, true, time.Second)
+			test.That(t, err, test.ShouldBeNil)
+			var totalAttempts atomic.Int32
+			solutions, meta, err := DoSolve(context.Background(), ik, &totalAttempts, solveFunc,
+				[][]float64{seed}, [][]referenceframe.Limit{m.DoF()})
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, totalAttempts.Load(), test.ShouldBeGreaterThan, 0)
+			test.That(t, meta[0].Valid, test.ShouldEqual, len(solutions))
+			for _, solution := range solutions {
+				for i, limit := range m.DoF() {
+					test.That(t, solution[i], test.ShouldBeBetweenOrEqual, limit.Min, limit.Max)
+				}
+				pose, err := m.Transform(solution)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, metric(pose), test.ShouldBeLessThan, defaultGoalThreshold)
+			}
+		})
+	}
+
+	t.Run("pose errors", func(t *testing.T) {
+		m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm7.json"), "")
+		test.That(t, err, test.ShouldBeNil)
+		randSeed := rand.New(rand.NewSource(2)) //nolint: gosec
+		lower, upper := limitsToArrays(m.DoF())
+		goal, err := m.Transform(generateRandomPositions(randSeed, lower, upper))
+		test.That(t, err, test.ShouldBeNil)
+		metric := motionplan.NewSquaredNormMetric(goal)
+		errorFunc := NewPoseErrorFunc(goal, 100, m, logger)
+
+		// the sum of the squares of the pose errors is the squared norm metric
+		seed := generateRandomPositions(randSeed, lower, upper)
+		pose, err := m.Transform(seed)
+		test.That(t, err, test.ShouldBeNil)
+		cost := 0.
+		for _, e := range errorFunc(context.Background(), seed) {
+			cost += e * e
+		}
+		test.That(t, cost, test.ShouldAlmostEqual, metric(pose), 1e-6)
+
+		ik, err := CreateDLSSolver(logger, -1, true, time.Second)
+		test.That(t, err, test.ShouldBeNil)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		solutions := make(chan *Solution)
+		go func() {
+			defer close(solutions)
+			//nolint: errcheck
+			ik.SolveErrors(ctx, solutions, nil, [][]float64{seed}, [][]referenceframe.Limit{m.DoF()}, errorFunc, 1)
+		}()
+		solution, ok := <-solutions
+		test.That(t, ok, test.ShouldBeTrue)
+		cancel()
+		for range solutions {
+		}
+		pose, err = m.Transform(solution.Configuration)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, solution.Score, test.ShouldAlmostEqual, metric(pose))
+		test.That(t, solution.Score, test.ShouldBeLessThan, defaultGoalThreshold)
+	})
+
+	t.Run("not exact", func(t *testing.T) {
+		m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "")
+		test.That(t, err, test.ShouldBeNil)
+		// out of reach, so the closest the solver gets is returned
+		solveFunc := NewMetricMinFunc(motionplan.NewSquaredNormMetric(spatialmath.NewPoseFromPoint(r3.Vector{X: 5000})), m, logger)
+
+		ik, err := CreateDLSSolver(logger, 1, false, time.Second)
+		test.That(t, err, test.ShouldBeNil)
+		_, _, err = DoSolve(context.Background(), ik, nil, solveFunc,
+			[][]float64{make([]float64, len(m.DoF()))}, [][]referenceframe.Limit{m.DoF()})
+		test.That(t, err, test.ShouldBeNil)
+
+		ik, err = CreateDLSSolver(logger, 1, true, time.Second)
+		test.That(t, err, test.ShouldBeNil)
+		_, _, err = DoSolve(context.Background(), ik, nil, solveFunc,
+			[][]float64{make([]float64, len(m.DoF()))}, [][]referenceframe.Limit{m.DoF()})
+		test.That(t, err, test.ShouldBeError, "unable to solve for position")
+	})
+
+	t.Run("bad seeds", func(t *testing.T) {
+		ik, err := CreateDLSSolver(logger, -1, true, time.Second)
+		test.That(t, err, test.ShouldBeNil)
+		_, _, err = ik.Solve(context.Background(), nil, nil, nil, nil, nil, 0)
+		test.That(t, err, test.ShouldBeError, "no seeds")
+		_, _, err = ik.Solve(context.Background(), nil, nil, [][]float64{{}}, [][]referenceframe.Limit{{}}, nil, 0)
+		test.That(t, err, test.ShouldBeError, errBadDLSBounds)
+	})
+}
+
+// BenchmarkIK compares the solvers on random reachable goals for each of the fake arm kinematics, with solvers that
+// drive errors to zero solving for the pose errors and others for the squared norm metric. Besides the time per solve,
+// it reports the fraction of goals solved within the time limit.
+func BenchmarkIK(b *testing.B) {
+	logger := logging.NewBlankLogger("ik")
+	solvers := map[string]func() (Solver, error){
+		"nlopt": func() (Solver, error) { return CreateNloptSolver(logger, -1, true, true, time.Second) },
+		"dls":   func() (Solver, error) { return CreateDLSSolver(logger, -1, true, time.Second) },
+	}
+
+	for _, name := range []string{"dofbot", "fake", "lite6", "ur20", "ur5e", "xarm6", "xarm7"} {
+		m, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/"+name+".json"), "")
+		test.That(b, err, test.ShouldBeNil)
+		lower, upper := limitsToArrays(m.DoF())
+
+		for _, solverName := range []string{"nlopt", "dls"} {
+			b.Run(fmt.Sprintf("%s/%s", name, solverName), func(b *testing.B) {
+				solver, err := solvers[solverName]()
+				if err != nil {
+					b.Skip(err)
+				}
+				randSeed := rand.New(rand.NewSource(1)) //nolint: gosec
+				solved := 0
+				b.ResetTimer()
+				for range b.N {
+					b.StopTimer()
+					goal, err := m.Transform(generateRandomPositions(randSeed, lower, upper))
+					test.That(b, err, test.ShouldBeNil)
+					seed := generateRandomPositions(randSeed, lower, upper)
+					solveFunc := NewMetricMinFunc(motionplan.NewSquaredNormMetric(goal), m, logger)
+					errorFunc := NewPoseErrorFunc(goal, 100, m, logger)
+					ctx, cancel := context.WithCancel(context.Background())
+					solutions := make(chan *Solution)
+					b.StartTimer()
+
+					go func() {
+						defer close(solutions)
+						limits := [][]referenceframe.Limit{m.DoF()}
+						if errorSolver, ok := solver.(ErrorSolver); ok {
+							//nolint: errcheck
+							errorSolver.SolveErrors(ctx, solutions, nil, [][]float64{seed}, limits, errorFunc, 1)
+							return
+						}
+						//nolint: errcheck
+						solver.Solve(ctx, solutions, nil, [][]float64{seed}, limits, solveFunc, 1)
+					}()
+					// the first solution is enough, after which the solver is cancelled
+					if _, ok := <-solutions; ok {
+						solved++
+					}
+					cancel()
+					for range solutions {
+					}
+				}
+				b.ReportMetric(float64(solved)/float64(b.N), "solved/op")
+			})
+		}
+	}
+}
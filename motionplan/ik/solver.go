@@ -8,6 +8,7 @@ import (
 	"math"
 	"math/rand"
 	"sync/atomic"
+	"time"
 
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
@@ -28,6 +29,10 @@ const (
 // CostFunc is the function to minimize.
 type CostFunc func(context.Context, []float64) float64
 
+// ErrorFunc returns the errors of a configuration, such as the difference between the pose of a frame and its goal,
+// which a DLSIK drives to zero. The cost of a configuration is the sum of the squares of its errors.
+type ErrorFunc func(context.Context, []float64) []float64
+
 // SeedSolveMetaData meta data about how a seed did
 type SeedSolveMetaData struct {
 	Attempts int
@@ -45,6 +50,15 @@ type Solver interface {
 		minFunc CostFunc, rseed int) (int, []SeedSolveMetaData, error)
 }
 
+// ErrorSolver is a Solver which can also drive the errors of an ErrorFunc to zero, rather than minimizing a cost.
+type ErrorSolver interface {
+	Solver
+	// SolveErrors is Solve for the errors of errorFunc, whose cost is the sum of their squares.
+	SolveErrors(ctx context.Context, solutions chan<- *Solution, totalAttempts *atomic.Int32,
+		seeds [][]float64, limits [][]referenceframe.Limit,
+		errorFunc ErrorFunc, rseed int) (int, []SeedSolveMetaData, error)
+}
+
 // Solution is the struct returned from an IK solver. It contains the solution configuration, the score of the solution, and a flag
 // indicating whether that configuration and score met the solution criteria requested by the caller.
 type Solution struct {
@@ -54,6 +68,15 @@ type Solution struct {
 	Meta          string
 }
 
+// CreateSolver creates an IK solver with the given parameters, see CreateNloptSolver. Builds without cgo, which can't
+// solve with nlopt, get a DLSIK instead.
+func CreateSolver(logger logging.Logger, iter int, exact, useRelTol bool, maxTime time.Duration) (Solver, error) {
+	if !nloptSupported {
+		return CreateDLSSolver(logger, iter, exact, maxTime)
+	}
+	return CreateNloptSolver(logger, iter, exact, useRelTol, maxTime)
+}
+
 // generateRandomPositions generates a random set of positions within the limits of this solver.
 func generateRandomPositions(randSeed *rand.Rand, lowerBound, upperBound []float64) []float64 {
 	pos := make([]float64, len(lowerBound))
@@ -194,3 +217,22 @@ func NewMetricMinFunc(metricFunc func(spatialmath.Pose) float64, frame reference
 		return metricFunc(currentPose)
 	}
 }
+
+// NewPoseErrorFunc returns the errors of the pose of the frame from the goal: the difference of their points followed
+// by the axis-angle rotation between their orientations, scaled by orientationScale. The sum of their squares is the
+// weighted squared norm distance between the poses, which is what motionplan.NewSquaredNormMetric measures with an
+// orientationScale of 100. Configurations which the frame can't transform have infinite errors.
+func NewPoseErrorFunc(goal spatialmath.Pose, orientationScale float64, frame referenceframe.Frame, logger logging.Logger) ErrorFunc {
+	return func(ctx context.Context, inputs []float64) []float64 {
+		currentPose, err := frame.Transform(inputs)
+		if err != nil {
+			logger.Debugf("Transform error in pose error: %v", err)
+			return []float64{math.Inf(1)}
+		}
+		point := currentPose.Point().Sub(goal.Point())
+		rotation := spatialmath.QuatToR3AA(
+			spatialmath.OrientationBetween(goal.Orientation(), currentPose.Orientation()).Quaternion(),
+		).Mul(orientationScale)
+		return []float64{point.X, point.Y, point.Z, rotation.X, rotation.Y, rotation.Z}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !windows && !no_cgo
+
+package ik
+
+// nloptSupported is whether this build can solve with nlopt.
+const nloptSupported = true
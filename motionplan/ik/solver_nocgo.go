@@ -13,6 +13,9 @@ import (
 	"go.viam.com/rdk/referenceframe"
 )
 
+// nloptSupported is whether this build can solve with nlopt.
+const nloptSupported = false
+
 // CreateNloptSolver is not supported on no_cgo builds.
 func CreateNloptSolver(
 	logger logging.Logger,
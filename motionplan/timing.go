@@ -0,0 +1,479 @@
+package motionplan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+// the number of bisections used to find the peak velocity of a segment too short to reach its velocity limit.
+const peakVelocityBisections = 100
+
+// InputLimit describes how quickly a single input may change. Units are those of the input (radians or mm) per second for
+// MaxVelocity, per second squared for MaxAcceleration and per second cubed for MaxJerk. A MaxJerk of zero leaves jerk
+// unlimited, so that acceleration changes instantly.
+//
+// BlendTolerance is how far, in units of the input, the input may stray from the planned path so that the motion keeps
+// moving past a step where the path turns. With a BlendTolerance of zero the motion only keeps moving past steps where
+// the path goes straight on, and comes to rest at every other step.
+type InputLimit struct {
+	MaxVelocity     float64 `json:"max_velocity" mapstructure:"max_velocity"`
+	MaxAcceleration float64 `json:"max_acceleration" mapstructure:"max_acceleration"`
+	MaxJerk         float64 `json:"max_jerk,omitempty" mapstructure:"max_jerk"`
+	BlendTolerance  float64 `json:"blend_tolerance,omitempty" mapstructure:"blend_tolerance"`
+}
+
+// FrameSystemInputLimits maps a frame's name to the limits of each of its inputs.
+type FrameSystemInputLimits map[string][]InputLimit
+
+// TimedInputs are the inputs of a single frame, and how quickly they are changing, at a point in time along a
+// TimedTrajectory. Velocities are in units of the input per second.
+type TimedInputs struct {
+	Time       time.Duration
+	Inputs     []referenceframe.Input
+	Velocities []float64
+}
+
+// TimedTrajectory is a Trajectory which has been parameterized by time such that every input respects its velocity,
+// acceleration and jerk limits while all frames move in a synchronized manner. Every input follows the same profile
+// along each segment, so the motion stays on the straight line between steps in input space, which is the path the
+// planner checked for collisions and constraints.
+//
+// The motion does not stop at the steps in between. Around each of them it blends from the velocity of the segment
+// before to that of the segment after, with a jerk limited change of velocity centered on the step. Where the path goes
+// straight on the blend stays on it, and where the path turns the blend cuts the corner by no more than the
+// BlendTolerance of each input, slowing down as much as needed to do so. Inputs without a tolerance come to rest at
+// steps where the path turns.
+type TimedTrajectory struct {
+	frames    []string
+	dofs      map[string]int
+	steps     Trajectory
+	durations []time.Duration
+	// pieces of constant jerk for each input, in the order of frames and then their inputs.
+	pieces [][]timingPiece
+}
+
+// timingPiece is a period of constant jerk of a single input.
+type timingPiece struct {
+	start, end   float64 // seconds
+	position     float64 // at start
+	velocity     float64 // at start
+	acceleration float64 // at start
+	jerk         float64
+}
+
+func (p timingPiece) at(t float64) (position, velocity float64) {
+	dt := t - p.start
+	position = p.position + p.velocity*dt + p.acceleration*dt*dt/2 + p.jerk*dt*dt*dt/6
+	return position, p.velocity + p.acceleration*dt + p.jerk*dt*dt/2
+}
+
+// TimeParameterize assigns times to each step of the trajectory such that every frame which moves respects the given
+// limits. Consecutive duplicate steps are removed.
+func TimeParameterize(traj Trajectory, limits FrameSystemInputLimits) (*TimedTrajectory, error) {
+	if len(traj) == 0 {
+		return nil, errors.New("cannot time parameterize an empty trajectory")
+	}
+	frames, dofs, err := trajectoryFrames(traj)
+	if err != nil {
+		return nil, err
+	}
+
+	// flatten the inputs and limits of every frame which has any
+	vias := make([][]float64, 0, len(traj))
+	steps := Trajectory{}
+	for _, step := range traj {
+		flat := make([]float64, 0, len(frames))
+		for _, frame := range frames {
+			flat = append(flat, step[frame]...)
+		}
+		if len(vias) > 0 && slices.Equal(vias[len(vias)-1], flat) {
+			continue
+		}
+		vias = append(vias, flat)
+		steps = append(steps, step)
+	}
+	flatLimits := make([]InputLimit, 0, len(vias[0]))
+	for _, frame := range frames {
+		frameLimits, ok := limits[frame]
+		if !ok {
+			return nil, fmt.Errorf("no input limits provided for frame %s", frame)
+		}
+		if len(frameLimits) != dofs[frame] {
+			return nil, fmt.Errorf("frame %s has %d inputs but %d input limits were provided", frame, dofs[frame], len(frameLimits))
+		}
+		for i, limit := range frameLimits {
+			if limit.MaxVelocity <= 0 || limit.MaxAcceleration <= 0 || limit.MaxJerk < 0 || limit.BlendTolerance < 0 {
+				return nil, fmt.Errorf(
+					"input %d of frame %s must have positive velocity and acceleration limits and a non-negative jerk limit "+
+						"and blend tolerance, got %+v", i, frame, limit)
+			}
+		}
+		flatLimits = append(flatLimits, frameLimits...)
+	}
+
+	tt := &TimedTrajectory{
+		frames:    frames,
+		dofs:      dofs,
+		steps:     steps,
+		durations: make([]time.Duration, len(vias)),
+		pieces:    make([][]timingPiece, len(flatLimits)),
+	}
+	segments := make([]pathLimits, len(vias)-1)
+	for k := range segments {
+		segments[k] = segmentLimits(vias[k], vias[k+1], flatLimits)
+	}
+	// blends[k] is the blend past step k, which is left at rest for the first and last steps
+	blends := make([]blend, len(vias))
+	for k := 1; k < len(vias)-1; k++ {
+		blends[k] = planBlend(vias[k-1], vias[k], vias[k+1], segments[k-1], segments[k], flatLimits)
+	}
+	profiles := fitBlends(segments, blends)
+
+	elapsed := 0.
+	for k := 1; k < len(vias); k++ {
+		// the profile covers the segment between the blends at either end
+		offset := blends[k-1].speedOut * blends[k-1].halfTime
+		for i := range flatLimits {
+			delta := vias[k][i] - vias[k-1][i]
+			for _, piece := range profiles[k-1] {
+				tt.pieces[i] = append(tt.pieces[i], timingPiece{
+					start:        elapsed + piece.start,
+					end:          elapsed + piece.end,
+					position:     vias[k-1][i] + delta*(offset+piece.position),
+					velocity:     delta * piece.velocity,
+					acceleration: delta * piece.acceleration,
+					jerk:         delta * piece.jerk,
+				})
+			}
+		}
+		if len(profiles[k-1]) > 0 {
+			elapsed += profiles[k-1][len(profiles[k-1])-1].end
+		}
+		if k == len(vias)-1 {
+			tt.durations[k] = time.Duration(elapsed * float64(time.Second))
+			break
+		}
+
+		// every input changes velocity with the same duration, centered on the step
+		b := blends[k]
+		if b.halfTime > 0 {
+			for i := range flatLimits {
+				velIn := b.speedIn * (vias[k][i] - vias[k-1][i])
+				velOut := b.speedOut * (vias[k+1][i] - vias[k][i])
+				jerk := (velOut - velIn) / (b.halfTime * b.halfTime)
+				first := timingPiece{
+					start: elapsed, end: elapsed + b.halfTime, position: vias[k][i] - velIn*b.halfTime, velocity: velIn, jerk: jerk,
+				}
+				position, velocity := first.at(first.end)
+				tt.pieces[i] = append(tt.pieces[i], first, timingPiece{
+					start: first.end, end: first.end + b.halfTime,
+					position: position, velocity: velocity, acceleration: jerk * b.halfTime, jerk: -jerk,
+				})
+			}
+		}
+		tt.durations[k] = time.Duration((elapsed + b.halfTime) * float64(time.Second))
+		elapsed += 2 * b.halfTime
+	}
+	// correct for any accumulated floating point error such that the trajectory ends exactly at the final position
+	for i := range flatLimits {
+		tt.pieces[i] = append(tt.pieces[i], timingPiece{start: elapsed, end: elapsed, position: vias[len(vias)-1][i]})
+	}
+	return tt, nil
+}
+
+// trajectoryFrames returns the sorted names of the frames which have inputs in the trajectory, and how many inputs each
+// has. Every step of the trajectory must have the same inputs.
+func trajectoryFrames(traj Trajectory) ([]string, map[string]int, error) {
+	dofs := map[string]int{}
+	for frame, inputs := range traj[0] {
+		if len(inputs) > 0 {
+			dofs[frame] = len(inputs)
+		}
+	}
+	for i, step := range traj {
+		for frame, dof := range dofs {
+			if len(step[frame]) != dof {
+				return nil, nil, fmt.Errorf("step %d of the trajectory has %d inputs for frame %s, expected %d", i, len(step[frame]), frame, dof)
+			}
+		}
+		// a frame which only moves later would otherwise be silently left behind
+		for frame, inputs := range step {
+			if _, ok := dofs[frame]; !ok && len(inputs) > 0 {
+				return nil, nil, fmt.Errorf("step %d of the trajectory has inputs for frame %s, which the first step does not have", i, frame)
+			}
+		}
+	}
+	frames := make([]string, 0, len(dofs))
+	for frame := range dofs {
+		frames = append(frames, frame)
+	}
+	slices.Sort(frames)
+	return frames, dofs, nil
+}
+
+// pathLimits are the limits of the fraction of a segment covered over time, such that every input moving along the
+// segment respects its limits.
+type pathLimits struct {
+	maxVel, maxAcc, maxJerk float64
+}
+
+// segmentLimits returns the limits along the segment between two distinct steps. Every input follows the same profile
+// scaled by how far it moves, so the limits of the profile are the tightest limits of any input relative to its distance.
+func segmentLimits(from, to []float64, limits []InputLimit) pathLimits {
+	lim := pathLimits{maxVel: math.Inf(1), maxAcc: math.Inf(1), maxJerk: math.Inf(1)}
+	for i, limit := range limits {
+		distance := math.Abs(to[i] - from[i])
+		if distance == 0 {
+			continue
+		}
+		lim.maxVel = math.Min(lim.maxVel, limit.MaxVelocity/distance)
+		lim.maxAcc = math.Min(lim.maxAcc, limit.MaxAcceleration/distance)
+		if limit.MaxJerk > 0 {
+			lim.maxJerk = math.Min(lim.maxJerk, limit.MaxJerk/distance)
+		}
+	}
+	return lim
+}
+
+// blend is how the motion passes a step: at speedIn along the segment before, as a fraction of it per second, and at
+// speedOut along the segment after. The change between them takes halfTime either side of the step, during which the
+// motion covers halfTime at speedIn of the segment before and halfTime at speedOut of the segment after. A blend
+// with no speed comes to rest at the step.
+type blend struct {
+	speedIn, speedOut, halfTime float64
+}
+
+// planBlend returns the fastest blend past the step via, between the segments from prev and to next, which respects the
+// acceleration and jerk limits of every input and strays from the path by no more than their blend tolerances.
+//
+// During a blend the acceleration of each input ramps up and back down at a constant jerk, so an input changing
+// velocity by dv over the 2*halfTime of the blend has a peak acceleration of dv/halfTime, a jerk of dv/halfTime^2, and is
+// dv*halfTime/6 away from where it would have been on the segment before, or after, at the step.
+func planBlend(prev, via, next []float64, in, out pathLimits, limits []InputLimit) blend {
+	// the velocity change of each input when passing the step at the velocity limits of both segments
+	changes := make([]float64, len(via))
+	parallel := true
+	var dot, norm float64
+	for i := range via {
+		dot += (via[i] - prev[i]) * (next[i] - via[i])
+		norm += (via[i] - prev[i]) * (via[i] - prev[i])
+	}
+	for i := range via {
+		changes[i] = math.Abs(out.maxVel*(next[i]-via[i]) - in.maxVel*(via[i]-prev[i]))
+		// the path goes straight on when the segment after is a positive multiple of the segment before
+		if dot <= 0 || math.Abs((next[i]-via[i])-dot/norm*(via[i]-prev[i])) > 1e-9*(math.Abs(next[i]-via[i])+1) {
+			parallel = false
+		}
+	}
+
+	// the fraction of the velocity limits the blend may reach is the smallest of accRate*halfTime, limited by
+	// acceleration, jerkRate*halfTime^2, limited by jerk, and tolerance/halfTime, limited by straying from the path
+	accRate, jerkRate, tolerance := math.Inf(1), math.Inf(1), math.Inf(1)
+	for i, change := range changes {
+		if change == 0 {
+			continue
+		}
+		accRate = math.Min(accRate, limits[i].MaxAcceleration/change)
+		if limits[i].MaxJerk > 0 {
+			jerkRate = math.Min(jerkRate, limits[i].MaxJerk/change)
+		}
+		if !parallel {
+			tolerance = math.Min(tolerance, 6*limits[i].BlendTolerance/change)
+		}
+	}
+	if math.IsInf(accRate, 1) {
+		// every input keeps its velocity, so there is nothing to blend
+		return blend{speedIn: in.maxVel, speedOut: out.maxVel}
+	}
+	if tolerance == 0 {
+		return blend{}
+	}
+
+	// the shortest blend which reaches the velocity limits, if it stays within the tolerance
+	halfTime := math.Max(1/accRate, math.Sqrt(1/jerkRate))
+	fraction := 1.
+	if tolerance/halfTime < 1 {
+		// otherwise the fastest blend is where the rising acceleration and jerk bounds meet the falling tolerance bound
+		halfTime = math.Max(math.Sqrt(tolerance/accRate), math.Cbrt(tolerance/jerkRate))
+		fraction = tolerance / halfTime
+	}
+	return blend{speedIn: fraction * in.maxVel, speedOut: fraction * out.maxVel, halfTime: halfTime}
+}
+
+// minBlendFraction is the fraction of its speed below which a blend is brought to rest rather than slowed further.
+const minBlendFraction = 1e-6
+
+// fitBlends slows down blends until every segment can change from the speed of the blend at its start to that of the
+// blend at its end in the part of the segment the blends leave, and returns the profile of each segment. As slowing a
+// blend changes both segments next to it, this repeats until every segment fits.
+func fitBlends(segments []pathLimits, blends []blend) [][]timingPiece {
+	profiles := make([][]timingPiece, len(segments))
+	for fitted := false; !fitted; {
+		fitted = true
+		for k, lim := range segments {
+			start, end := blends[k], blends[k+1]
+			distance := 1 - start.speedOut*start.halfTime - end.speedIn*end.halfTime
+			profile, ok := segmentProfile(lim, start.speedOut, end.speedIn, distance)
+			if ok {
+				profiles[k] = profile
+				continue
+			}
+			fitted = false
+			for _, idx := range []int{k, k + 1} {
+				b := &blends[idx]
+				if b.speedIn < minBlendFraction*segments[max(idx-1, 0)].maxVel {
+					*b = blend{}
+				} else {
+					b.speedIn, b.speedOut = b.speedIn/2, b.speedOut/2
+				}
+			}
+		}
+	}
+	return profiles
+}
+
+// segmentProfile returns the fastest profile covering the distance, as a fraction of a segment, which starts at the
+// speed from and ends at the speed to with no acceleration at either end, as the pieces of the fraction covered over
+// time. It returns false if the distance is too short to change between the speeds.
+func segmentProfile(lim pathLimits, from, to, distance float64) ([]timingPiece, bool) {
+	// change returns the durations of the jerk and constant acceleration phases which change the speed by the given
+	// amount, and the acceleration reached.
+	change := func(speed float64) (jerkTime, accTime, acc float64) {
+		if math.IsInf(lim.maxJerk, 1) {
+			return 0, speed / lim.maxAcc, lim.maxAcc
+		}
+		if speed*lim.maxJerk >= lim.maxAcc*lim.maxAcc {
+			return lim.maxAcc / lim.maxJerk, speed/lim.maxAcc - lim.maxAcc/lim.maxJerk, lim.maxAcc
+		}
+		jerkTime = math.Sqrt(speed / lim.maxJerk)
+		return jerkTime, 0, lim.maxJerk * jerkTime
+	}
+	// a change of speed is symmetric, so it covers the average of the speeds times its duration
+	covered := func(a, b float64) float64 {
+		jerkTime, accTime, _ := change(math.Abs(b - a))
+		return (a + b) / 2 * (2*jerkTime + accTime)
+	}
+	distanceAt := func(peak float64) float64 {
+		return covered(from, peak) + covered(peak, to)
+	}
+
+	lowest := math.Max(from, to)
+	if distance <= 0 || distanceAt(lowest) > distance*(1+1e-12) {
+		return nil, false
+	}
+	peak, cruise := lim.maxVel, 0.
+	if d := distanceAt(lim.maxVel); d <= distance {
+		cruise = (distance - d) / lim.maxVel
+	} else {
+		lo, hi := lowest, lim.maxVel
+		for range peakVelocityBisections {
+			if mid := (lo + hi) / 2; distanceAt(mid) > distance {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		peak = lo
+	}
+
+	pieces := []timingPiece{}
+	t, position, speed := 0., 0., from
+	addPiece := func(duration, acceleration, jerk float64) {
+		if duration <= 0 {
+			return
+		}
+		piece := timingPiece{start: t, end: t + duration, position: position, velocity: speed, acceleration: acceleration, jerk: jerk}
+		pieces = append(pieces, piece)
+		t = piece.end
+		position, speed = piece.at(t)
+	}
+	addChange := func(sign float64, jerkTime, accTime, acc float64) {
+		jerk := 0.
+		if jerkTime > 0 {
+			jerk = acc / jerkTime
+		}
+		addPiece(jerkTime, 0, sign*jerk)
+		addPiece(accTime, sign*acc, 0)
+		addPiece(jerkTime, sign*acc, -sign*jerk)
+	}
+	jerkTime, accTime, acc := change(peak - from)
+	addChange(1, jerkTime, accTime, acc)
+	addPiece(cruise, 0, 0)
+	jerkTime, accTime, acc = change(peak - to)
+	addChange(-1, jerkTime, accTime, acc)
+	return pieces, true
+}
+
+// Duration returns how long it takes to follow the trajectory.
+func (tt *TimedTrajectory) Duration() time.Duration {
+	return tt.durations[len(tt.durations)-1]
+}
+
+// Frames returns the names of the frames which move along the trajectory.
+func (tt *TimedTrajectory) Frames() []string {
+	return slices.Clone(tt.frames)
+}
+
+// Steps returns the steps of the trajectory, without any consecutive duplicates, and the time at which each is reached,
+// or at which the motion blends past it.
+func (tt *TimedTrajectory) Steps() (Trajectory, []time.Duration) {
+	return tt.steps, slices.Clone(tt.durations)
+}
+
+// At returns the inputs of every frame, and how quickly they are changing, at the given time along the trajectory.
+func (tt *TimedTrajectory) At(t time.Duration) (referenceframe.FrameSystemInputs, referenceframe.FrameSystemInputs) {
+	seconds := math.Max(t.Seconds(), 0)
+	atEnd := t >= tt.Duration()
+	inputs := referenceframe.FrameSystemInputs{}
+	velocities := referenceframe.FrameSystemInputs{}
+	idx := 0
+	for _, frame := range tt.frames {
+		frameInputs := make([]referenceframe.Input, tt.dofs[frame])
+		frameVelocities := make([]referenceframe.Input, tt.dofs[frame])
+		for j := range frameInputs {
+			frameInputs[j], frameVelocities[j] = evaluatePieces(tt.pieces[idx], seconds, atEnd)
+			idx++
+		}
+		inputs[frame] = frameInputs
+		velocities[frame] = frameVelocities
+	}
+	return inputs, velocities
+}
+
+func evaluatePieces(pieces []timingPiece, t float64, atEnd bool) (float64, float64) {
+	if !atEnd {
+		for _, piece := range pieces {
+			if t < piece.end {
+				return piece.at(t)
+			}
+		}
+	}
+	// at the end of the trajectory the input is at rest at its final position
+	return pieces[len(pieces)-1].position, 0
+}
+
+// Sample returns the inputs of the given frame every interval along the trajectory, always including the start and end.
+func (tt *TimedTrajectory) Sample(frame string, interval time.Duration) ([]TimedInputs, error) {
+	if _, ok := tt.dofs[frame]; !ok {
+		return nil, fmt.Errorf("frame %s does not move along the trajectory", frame)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("sample interval must be positive, got %v", interval)
+	}
+	samples := []TimedInputs{}
+	for t := time.Duration(0); ; t += interval {
+		if t > tt.Duration() {
+			t = tt.Duration()
+		}
+		inputs, velocities := tt.At(t)
+		samples = append(samples, TimedInputs{Time: t, Inputs: inputs[frame], Velocities: velocities[frame]})
+		if t == tt.Duration() {
+			return samples, nil
+		}
+	}
+}
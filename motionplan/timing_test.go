@@ -0,0 +1,290 @@
+package motionplan
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+func TestTimeParameterize(t *testing.T) {
+	limits := FrameSystemInputLimits{
+		"arm":    {{MaxVelocity: 1, MaxAcceleration: 2}, {MaxVelocity: 0.5, MaxAcceleration: 1}},
+		"gantry": {{MaxVelocity: 100, MaxAcceleration: 200}},
+	}
+	traj := Trajectory{
+		{"arm": {0, 0}, "gantry": {0}, "static": {}},
+		{"arm": {1, 0.2}, "gantry": {50}, "static": {}},
+		{"arm": {1, 0.2}, "gantry": {50}, "static": {}},
+		{"arm": {0.5, 1}, "gantry": {-20}, "static": {}},
+		{"arm": {2, 1}, "gantry": {0}, "static": {}},
+	}
+
+	t.Run("respects limits", func(t *testing.T) {
+		tt, err := TimeParameterize(traj, limits)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, tt.Frames(), test.ShouldResemble, []string{"arm", "gantry"})
+
+		steps, times := tt.Steps()
+		// the duplicate step is removed
+		test.That(t, len(steps), test.ShouldEqual, 4)
+		test.That(t, len(times), test.ShouldEqual, 4)
+		test.That(t, times[0], test.ShouldEqual, time.Duration(0))
+		test.That(t, times[3], test.ShouldEqual, tt.Duration())
+		// the slowest input must travel at least 2.3 units at 1 unit/sec
+		test.That(t, tt.Duration(), test.ShouldBeGreaterThan, 2300*time.Millisecond)
+
+		start, startVel := tt.At(0)
+		test.That(t, start, test.ShouldResemble, referenceframe.FrameSystemInputs{"arm": {0, 0}, "gantry": {0}})
+		test.That(t, startVel, test.ShouldResemble, referenceframe.FrameSystemInputs{"arm": {0, 0}, "gantry": {0}})
+		end, endVel := tt.At(tt.Duration())
+		test.That(t, end, test.ShouldResemble, referenceframe.FrameSystemInputs{"arm": {2, 1}, "gantry": {0}})
+		test.That(t, endVel, test.ShouldResemble, referenceframe.FrameSystemInputs{"arm": {0, 0}, "gantry": {0}})
+
+		const dt = time.Millisecond
+		_, prevVel := tt.At(0)
+		for ts := dt; ts <= tt.Duration(); ts += dt {
+			_, vel := tt.At(ts)
+			for frame, frameLimits := range limits {
+				for i, limit := range frameLimits {
+					test.That(t, math.Abs(vel[frame][i]), test.ShouldBeLessThanOrEqualTo, limit.MaxVelocity*(1+1e-6))
+					accel := math.Abs(vel[frame][i]-prevVel[frame][i]) / dt.Seconds()
+					test.That(t, accel, test.ShouldBeLessThanOrEqualTo, limit.MaxAcceleration*(1+1e-6))
+				}
+			}
+			prevVel = vel
+		}
+	})
+
+	t.Run("stays on the planned path", func(t *testing.T) {
+		tt, err := TimeParameterize(traj, limits)
+		test.That(t, err, test.ShouldBeNil)
+		steps, times := tt.Steps()
+		for k := 1; k < len(steps); k++ {
+			// without blend tolerances every step, which are all corners, is reached exactly, at rest
+			inputs, vel := tt.At(times[k])
+			for _, frame := range tt.Frames() {
+				for i := range inputs[frame] {
+					test.That(t, inputs[frame][i], test.ShouldAlmostEqual, steps[k][frame][i], 1e-6)
+					test.That(t, math.Abs(vel[frame][i]), test.ShouldBeLessThan, 1e-6)
+				}
+			}
+			// and in between every input has covered the same fraction of the segment
+			for f := 0.1; f < 1; f += 0.1 {
+				inputs, _ := tt.At(times[k-1] + time.Duration(f*float64(times[k]-times[k-1])))
+				fraction := -1.
+				for _, frame := range tt.Frames() {
+					for i := range inputs[frame] {
+						from, to := steps[k-1][frame][i], steps[k][frame][i]
+						if from == to {
+							test.That(t, inputs[frame][i], test.ShouldAlmostEqual, from, 1e-9)
+							continue
+						}
+						covered := (inputs[frame][i] - from) / (to - from)
+						if fraction < 0 {
+							fraction = covered
+						}
+						test.That(t, covered, test.ShouldAlmostEqual, fraction, 1e-6)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("respects jerk limits", func(t *testing.T) {
+		jerkLimits := FrameSystemInputLimits{
+			"arm":    {{MaxVelocity: 1, MaxAcceleration: 2, MaxJerk: 5}, {MaxVelocity: 0.5, MaxAcceleration: 1, MaxJerk: 3}},
+			"gantry": {{MaxVelocity: 100, MaxAcceleration: 200, MaxJerk: 1000}},
+		}
+		unlimited, err := TimeParameterize(traj, limits)
+		test.That(t, err, test.ShouldBeNil)
+		tt, err := TimeParameterize(traj, jerkLimits)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, tt.Duration(), test.ShouldBeGreaterThan, unlimited.Duration())
+
+		const dt = time.Millisecond
+		_, prevVel := tt.At(0)
+		prevAccel := map[string][]float64{"arm": {0, 0}, "gantry": {0}}
+		for ts := dt; ts <= tt.Duration(); ts += dt {
+			_, vel := tt.At(ts)
+			for frame, frameLimits := range jerkLimits {
+				for i, limit := range frameLimits {
+					test.That(t, math.Abs(vel[frame][i]), test.ShouldBeLessThanOrEqualTo, limit.MaxVelocity*(1+1e-6))
+					accel := (vel[frame][i] - prevVel[frame][i]) / dt.Seconds()
+					test.That(t, math.Abs(accel), test.ShouldBeLessThanOrEqualTo, limit.MaxAcceleration*(1+1e-6))
+					// the finite difference of a finite difference picks up some error from the sampling
+					jerk := (accel - prevAccel[frame][i]) / dt.Seconds()
+					test.That(t, math.Abs(jerk), test.ShouldBeLessThanOrEqualTo, limit.MaxJerk*1.01)
+					prevAccel[frame][i] = accel
+				}
+			}
+			prevVel = vel
+		}
+
+		_, err = TimeParameterize(traj, FrameSystemInputLimits{
+			"arm": jerkLimits["arm"], "gantry": {{MaxVelocity: 100, MaxAcceleration: 200, MaxJerk: -1}},
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("blends past steps", func(t *testing.T) {
+		blendLimits := FrameSystemInputLimits{
+			"arm": {
+				{MaxVelocity: 1, MaxAcceleration: 2, MaxJerk: 20, BlendTolerance: 0.05},
+				{MaxVelocity: 0.5, MaxAcceleration: 1, MaxJerk: 10, BlendTolerance: 0.05},
+			},
+			"gantry": {{MaxVelocity: 100, MaxAcceleration: 200, MaxJerk: 2000, BlendTolerance: 2}},
+		}
+		stopping := FrameSystemInputLimits{}
+		for frame, frameLimits := range blendLimits {
+			for _, limit := range frameLimits {
+				limit.BlendTolerance = 0
+				stopping[frame] = append(stopping[frame], limit)
+			}
+		}
+		stopped, err := TimeParameterize(traj, stopping)
+		test.That(t, err, test.ShouldBeNil)
+		tt, err := TimeParameterize(traj, blendLimits)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, tt.Duration(), test.ShouldBeLessThan, stopped.Duration())
+
+		steps, times := tt.Steps()
+		for k := 1; k < len(steps)-1; k++ {
+			// the motion keeps moving past every step in between, close to it
+			inputs, vel := tt.At(times[k])
+			speed := 0.
+			for frame, frameLimits := range blendLimits {
+				for i, limit := range frameLimits {
+					test.That(t, math.Abs(inputs[frame][i]-steps[k][frame][i]), test.ShouldBeLessThanOrEqualTo, limit.BlendTolerance*(1+1e-6))
+					speed += math.Abs(vel[frame][i]) / limit.MaxVelocity
+				}
+			}
+			test.That(t, speed, test.ShouldBeGreaterThan, 0.1)
+		}
+
+		// nearest returns how far the inputs are from the closest point of the path, relative to the tolerances
+		nearest := func(inputs referenceframe.FrameSystemInputs) float64 {
+			best := math.Inf(1)
+			for k := 1; k < len(steps); k++ {
+				for f := 0.; f <= 1; f += 0.001 {
+					farthest := 0.
+					for frame, frameLimits := range blendLimits {
+						for i, limit := range frameLimits {
+							on := steps[k-1][frame][i] + f*(steps[k][frame][i]-steps[k-1][frame][i])
+							farthest = math.Max(farthest, math.Abs(inputs[frame][i]-on)/limit.BlendTolerance)
+						}
+					}
+					best = math.Min(best, farthest)
+				}
+			}
+			return best
+		}
+
+		const dt = time.Millisecond
+		_, prevVel := tt.At(0)
+		prevAccel := map[string][]float64{"arm": {0, 0}, "gantry": {0}}
+		for ts := dt; ts <= tt.Duration(); ts += dt {
+			inputs, vel := tt.At(ts)
+			if ts%(10*dt) == 0 {
+				test.That(t, nearest(inputs), test.ShouldBeLessThanOrEqualTo, 1.01)
+			}
+			for frame, frameLimits := range blendLimits {
+				for i, limit := range frameLimits {
+					test.That(t, math.Abs(vel[frame][i]), test.ShouldBeLessThanOrEqualTo, limit.MaxVelocity*(1+1e-6))
+					accel := (vel[frame][i] - prevVel[frame][i]) / dt.Seconds()
+					test.That(t, math.Abs(accel), test.ShouldBeLessThanOrEqualTo, limit.MaxAcceleration*(1+1e-6))
+					jerk := (accel - prevAccel[frame][i]) / dt.Seconds()
+					test.That(t, math.Abs(jerk), test.ShouldBeLessThanOrEqualTo, limit.MaxJerk*1.01)
+					prevAccel[frame][i] = accel
+				}
+			}
+			prevVel = vel
+		}
+		end, endVel := tt.At(tt.Duration())
+		test.That(t, end, test.ShouldResemble, referenceframe.FrameSystemInputs{"arm": {2, 1}, "gantry": {0}})
+		test.That(t, endVel, test.ShouldResemble, referenceframe.FrameSystemInputs{"arm": {0, 0}, "gantry": {0}})
+
+		_, err = TimeParameterize(traj, FrameSystemInputLimits{
+			"arm": blendLimits["arm"], "gantry": {{MaxVelocity: 100, MaxAcceleration: 200, BlendTolerance: -1}},
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("keeps moving where the path goes straight on", func(t *testing.T) {
+		// without any tolerance, the motion still does not stop at a step on a straight line
+		straight, err := TimeParameterize(Trajectory{{"gantry": {0}}, {"gantry": {30}}, {"gantry": {100}}}, limits)
+		test.That(t, err, test.ShouldBeNil)
+		single, err := TimeParameterize(Trajectory{{"gantry": {0}}, {"gantry": {100}}}, limits)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, straight.Duration().Seconds(), test.ShouldAlmostEqual, single.Duration().Seconds(), 1e-6)
+
+		_, times := straight.Steps()
+		inputs, vel := straight.At(times[1])
+		test.That(t, inputs["gantry"][0], test.ShouldAlmostEqual, 30, 1e-6)
+		test.That(t, vel["gantry"][0], test.ShouldAlmostEqual, 100, 1e-6)
+
+		// turning back is a corner, so the motion comes to rest
+		back, err := TimeParameterize(Trajectory{{"gantry": {0}}, {"gantry": {30}}, {"gantry": {0}}}, limits)
+		test.That(t, err, test.ShouldBeNil)
+		_, times = back.Steps()
+		inputs, vel = back.At(times[1])
+		test.That(t, inputs["gantry"][0], test.ShouldAlmostEqual, 30, 1e-6)
+		test.That(t, vel["gantry"][0], test.ShouldAlmostEqual, 0, 1e-6)
+	})
+
+	t.Run("samples", func(t *testing.T) {
+		tt, err := TimeParameterize(traj, limits)
+		test.That(t, err, test.ShouldBeNil)
+		samples, err := tt.Sample("gantry", 100*time.Millisecond)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, samples[0].Time, test.ShouldEqual, time.Duration(0))
+		test.That(t, samples[len(samples)-1].Time, test.ShouldEqual, tt.Duration())
+		test.That(t, samples[len(samples)-1].Inputs, test.ShouldResemble, []referenceframe.Input{0})
+		for i := 1; i < len(samples); i++ {
+			test.That(t, samples[i].Time, test.ShouldBeGreaterThan, samples[i-1].Time)
+		}
+
+		_, err = tt.Sample("static", time.Millisecond)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = tt.Sample("gantry", 0)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("single segment", func(t *testing.T) {
+		tt, err := TimeParameterize(Trajectory{{"gantry": {0}}, {"gantry": {100}}}, limits)
+		test.That(t, err, test.ShouldBeNil)
+		// accelerating to 100mm/s at 200mm/s^2 takes 0.5s and covers 25mm, as does decelerating, so the fastest move
+		// spends 0.5s travelling the remaining 50mm for a total of 1.5s
+		test.That(t, tt.Duration().Seconds(), test.ShouldBeGreaterThanOrEqualTo, 1.5)
+		test.That(t, tt.Duration().Seconds(), test.ShouldBeLessThan, 1.52)
+		mid, vel := tt.At(tt.Duration() / 2)
+		test.That(t, mid["gantry"][0], test.ShouldAlmostEqual, 50, 1e-6)
+		test.That(t, vel["gantry"][0], test.ShouldBeGreaterThan, 0)
+	})
+
+	t.Run("single step", func(t *testing.T) {
+		tt, err := TimeParameterize(Trajectory{{"gantry": {5}}, {"gantry": {5}}}, limits)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, tt.Duration(), test.ShouldEqual, time.Duration(0))
+		inputs, _ := tt.At(time.Second)
+		test.That(t, inputs["gantry"], test.ShouldResemble, []referenceframe.Input{5})
+	})
+
+	t.Run("fails on invalid input", func(t *testing.T) {
+		_, err := TimeParameterize(Trajectory{}, limits)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = TimeParameterize(Trajectory{{"other": {0}}, {"other": {1}}}, limits)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = TimeParameterize(Trajectory{{"gantry": {0}}, {"gantry": {}}}, limits)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = TimeParameterize(Trajectory{{"gantry": {0}, "arm": {}}, {"gantry": {1}, "arm": {0, 1}}}, limits)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = TimeParameterize(traj, FrameSystemInputLimits{"arm": limits["arm"][:1], "gantry": limits["gantry"]})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = TimeParameterize(traj, FrameSystemInputLimits{"arm": limits["arm"], "gantry": {{MaxVelocity: 1}}})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
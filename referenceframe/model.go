@@ -2,18 +2,22 @@ package referenceframe
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"math"
 	"math/rand"
-	"strings"
+	"os"
+	"path/filepath"
 
 	"github.com/golang/geo/r3"
 	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/arm/v1"
+	"go.viam.com/utils"
 	"gonum.org/v1/gonum/num/dualquat"
 	"gonum.org/v1/gonum/num/quat"
 
+	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/spatialmath"
 )
 
@@ -118,15 +122,70 @@ type mimicMapping struct {
 	valueOffset     float64
 }
 
-// KinematicModelFromFile returns a model frame from a file that defines the kinematics.
+// KinematicModelFromFile returns a model frame from a file that defines the kinematics. Files with the .xml extension
+// may hold URDF, MJCF or SDFormat, distinguished by their root element. Elements of MJCF and SDFormat files which have
+// no equivalent in a Model are left out.
 func KinematicModelFromFile(modelPath, name string) (Model, error) {
-	switch {
-	case strings.HasSuffix(modelPath, ".urdf"):
+	return KinematicModelFromFileWithLogger(modelPath, name, nil)
+}
+
+// KinematicModelFromFileWithLogger is KinematicModelFromFile, which also logs the elements of MJCF and SDFormat files
+// that are left out as a warning unless the logger is nil.
+func KinematicModelFromFileWithLogger(modelPath, name string, logger logging.Logger) (Model, error) {
+	format := filepath.Ext(modelPath)
+	if format == ".xml" {
+		root, err := xmlRootElement(modelPath)
+		if err != nil {
+			return nil, err
+		}
+		format = map[string]string{"robot": ".urdf", "mujoco": ".mjcf", "sdf": ".sdf"}[root]
+		if format == "" {
+			return nil, fmt.Errorf("xml file with root element %q is not URDF, MJCF or SDFormat", root)
+		}
+	}
+
+	switch format {
+	case ".urdf":
 		return ParseModelXMLFile(modelPath, name, nil)
-	case strings.HasSuffix(modelPath, ".json"):
+	case ".json":
 		return ParseModelJSONFile(modelPath, name)
+	case ".mjcf", ".sdf":
+		parse := ParseModelMJCFFile
+		if format == ".sdf" {
+			parse = ParseModelSDFFile
+		}
+		model, unsupported, err := parse(modelPath, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(unsupported) > 0 && logger != nil {
+			logger.Warnw("Elements of the model file have no equivalent in the model and were left out",
+				"file", modelPath, "elements", unsupported)
+		}
+		return model, nil
 	default:
-		return nil, errors.New("only files with .json and .urdf file extensions are supported")
+		return nil, errors.New("only files with .json, .urdf, .mjcf, .sdf and .xml file extensions are supported")
+	}
+}
+
+// xmlRootElement returns the name of the root element of the given XML file.
+func xmlRootElement(filename string) (string, error) {
+	//nolint:gosec
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer utils.UncheckedErrorFunc(f.Close)
+
+	decoder := xml.NewDecoder(f)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to find the root element of %s: %w", filename, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
 	}
 }
 
@@ -0,0 +1,258 @@
+package referenceframe
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	commonpb "go.viam.com/api/common/v1"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// xmlElement is a generic XML element, used for formats whose elements are resolved against others, such as MJCF
+// default classes, and to report elements which are not imported.
+type xmlElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Text     string       `xml:",chardata"`
+	Children []xmlElement `xml:",any"`
+}
+
+// attr returns the value of the named attribute, or "" if the element doesn't have it.
+func (e *xmlElement) attr(name string) string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// describe returns the type of the element, followed by its name if it has one.
+func (e *xmlElement) describe() string {
+	if name := e.attr("name"); name != "" {
+		return fmt.Sprintf("%s %q", e.XMLName.Local, name)
+	}
+	return e.XMLName.Local
+}
+
+// child returns the first child element with the given name, or nil if there is none.
+func (e *xmlElement) child(name string) *xmlElement {
+	for i := range e.Children {
+		if e.Children[i].XMLName.Local == name {
+			return &e.Children[i]
+		}
+	}
+	return nil
+}
+
+// childText returns the trimmed text of the first child element with the given name, or "" if there is none.
+func (e *xmlElement) childText(name string) string {
+	if c := e.child(name); c != nil {
+		return strings.TrimSpace(c.Text)
+	}
+	return ""
+}
+
+// importedLink is a rigid body of a model description in a format other than our own, such as MJCF or SDFormat.
+type importedLink struct {
+	name string
+	// geometry is the collision geometry of the link, in the link frame
+	geometry spatialmath.Geometry
+	// pose is the pose of the link in the model frame, used only if no joint has the link as its child
+	pose spatialmath.Pose
+}
+
+// importedJoint connects two links of an imported model description. Its ID, Type, Axis, limits and Mimic are
+// already in our units, while its Parent is resolved when converting to a ModelConfigJSON.
+type importedJoint struct {
+	JointConfig
+	parent, child string
+	// pose is the pose of the joint frame in the parent link frame, and childPose the pose of the child link frame in the
+	// joint frame, both when the joint is at zero
+	pose, childPose spatialmath.Pose
+}
+
+// importedModel is the common representation of model descriptions in formats other than our own, from which the
+// ModelConfigJSON is built.
+type importedModel struct {
+	name   string
+	links  []*importedLink
+	joints []*importedJoint
+	// output names the frame whose pose the model returns, if not its only end effector
+	output string
+	// unsupported lists the elements of the description which were not imported
+	unsupported []string
+}
+
+// skip records an element of the description which was not imported.
+func (m *importedModel) skip(format string, args ...interface{}) {
+	m.unsupported = append(m.unsupported, fmt.Sprintf(format, args...))
+}
+
+// toModelConfig converts the imported model into an equivalent ModelConfigJSON. Each link becomes a LinkConfig holding
+// its geometry. As with URDF, the origin of the single joint of a link is held by the link, while links with several
+// joints have a LinkConfig for each joint origin. Fixed joints become links, unless unnamed, in which case their child
+// link is attached directly to the parent.
+func (m *importedModel) toModelConfig(file *ModelFile) (*ModelConfigJSON, error) {
+	cfg := &ModelConfigJSON{
+		Name:         m.name,
+		KinParamType: "SVA",
+		OriginalFile: file,
+	}
+	if m.output != "" {
+		cfg.OutputFrames = []string{m.output}
+	}
+	linksByName := map[string]*importedLink{}
+	for _, link := range m.links {
+		linksByName[link.name] = link
+	}
+	childJoints := map[string][]*importedJoint{}
+	parentJoint := map[string]*importedJoint{}
+	for _, joint := range m.joints {
+		if _, ok := linksByName[joint.parent]; !ok && joint.parent != World {
+			return nil, NewFrameNotInListOfTransformsError(joint.parent)
+		}
+		if _, ok := linksByName[joint.child]; !ok {
+			return nil, NewFrameNotInListOfTransformsError(joint.child)
+		}
+		if _, ok := parentJoint[joint.child]; ok {
+			return nil, fmt.Errorf("link %q is the child of more than one joint", joint.child)
+		}
+		childJoints[joint.parent] = append(childJoints[joint.parent], joint)
+		parentJoint[joint.child] = joint
+	}
+
+	var addLink func(link *importedLink, parent string, inPose spatialmath.Pose) error
+	// addJoints adds the joints of the given parent frame, whose origin is already held by that frame if absorbed.
+	addJoints := func(parent string, joints []*importedJoint, absorbed bool) error {
+		for _, joint := range joints {
+			origin := joint.pose
+			if absorbed {
+				origin = spatialmath.NewZeroPose()
+			}
+			child := linksByName[joint.child]
+			switch {
+			case joint.Type == FixedJoint && joint.ID == "":
+				if err := addLink(child, parent, spatialmath.Compose(origin, joint.childPose)); err != nil {
+					return err
+				}
+				continue
+			case joint.Type == FixedJoint:
+				linkCfg, err := newImportedLinkConfig(joint.ID, parent, origin)
+				if err != nil {
+					return err
+				}
+				cfg.Links = append(cfg.Links, *linkCfg)
+			default:
+				jointCfg := joint.JointConfig
+				jointCfg.Parent = parent
+				if !spatialmath.PoseAlmostEqual(origin, spatialmath.NewZeroPose()) {
+					originCfg, err := newImportedLinkConfig(joint.ID+"_origin", parent, origin)
+					if err != nil {
+						return err
+					}
+					cfg.Links = append(cfg.Links, *originCfg)
+					jointCfg.Parent = originCfg.ID
+				}
+				cfg.Joints = append(cfg.Joints, jointCfg)
+			}
+			if err := addLink(child, joint.ID, joint.childPose); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	addLink = func(link *importedLink, parent string, inPose spatialmath.Pose) error {
+		joints := childJoints[link.name]
+		outPose := inPose
+		if len(joints) == 1 {
+			outPose = spatialmath.Compose(inPose, joints[0].pose)
+		}
+		linkCfg, err := newImportedLinkConfig(link.name, parent, outPose)
+		if err != nil {
+			return err
+		}
+		if link.geometry != nil {
+			geometry := link.geometry.Transform(inPose)
+			geometry.SetLabel("")
+			if linkCfg.Geometry, err = spatialmath.NewGeometryConfig(geometry); err != nil {
+				return err
+			}
+		}
+		cfg.Links = append(cfg.Links, *linkCfg)
+		return addJoints(link.name, joints, len(joints) == 1)
+	}
+
+	for _, link := range m.links {
+		if _, ok := parentJoint[link.name]; ok {
+			continue
+		}
+		pose := link.pose
+		if pose == nil {
+			pose = spatialmath.NewZeroPose()
+		}
+		if err := addLink(link, World, pose); err != nil {
+			return nil, err
+		}
+	}
+	if err := addJoints(World, childJoints[World], false); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func newImportedLinkConfig(id, parent string, pose spatialmath.Pose) (*LinkConfig, error) {
+	orientation, err := spatialmath.NewOrientationConfig(pose.Orientation())
+	if err != nil {
+		return nil, err
+	}
+	return &LinkConfig{
+		ID:          id,
+		Translation: pose.Point(),
+		Orientation: orientation,
+		Parent:      parent,
+	}, nil
+}
+
+// loadMeshFile reads the mesh at meshPath, relative to dir unless absolute, into a proto Mesh.
+func loadMeshFile(dir, meshPath string) (*commonpb.Mesh, error) {
+	absolutePath := meshPath
+	if !filepath.IsAbs(meshPath) {
+		absolutePath = filepath.Join(dir, meshPath)
+	}
+
+	//nolint:gosec
+	meshBytes, err := os.ReadFile(absolutePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mesh file %s: %w", absolutePath, err)
+	}
+
+	var contentType string
+	switch {
+	case strings.HasSuffix(strings.ToLower(meshPath), ".ply"):
+		contentType = "ply"
+	case strings.HasSuffix(strings.ToLower(meshPath), ".stl"):
+		contentType = "stl"
+	default:
+		return nil, fmt.Errorf("unsupported mesh file type (only .ply and .stl supported): %s", meshPath)
+	}
+	return &commonpb.Mesh{Mesh: meshBytes, ContentType: contentType}, nil
+}
+
+// importedMesh returns the mesh at meshPath from meshMap, posed at origin.
+func importedMesh(origin spatialmath.Pose, meshPath string, meshMap map[string]*commonpb.Mesh) (spatialmath.Geometry, error) {
+	protoMesh, ok := meshMap[meshPath]
+	if !ok {
+		return nil, fmt.Errorf("mesh file not found in mesh map: %s", meshPath)
+	}
+	mesh, err := spatialmath.NewMeshFromProto(origin, protoMesh, "")
+	if err != nil {
+		return nil, err
+	}
+	mesh.SetOriginalFilePath(meshPath)
+	return mesh, nil
+}
@@ -0,0 +1,679 @@
+package referenceframe
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	"gonum.org/v1/gonum/num/quat"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// mjcfMainClass is the name of the MJCF default class used by elements which don't name one.
+const mjcfMainClass = "main"
+
+// mjcfIgnored lists the MJCF elements which only affect simulation or rendering, so are left out of the report of
+// elements which were not imported.
+var mjcfIgnored = map[string]bool{
+	"option": true, "size": true, "visual": true, "statistic": true, "custom": true, "keyframe": true,
+	"inertial": true, "camera": true, "light": true,
+}
+
+// mjcfParser holds the state needed to import an MJCF (MuJoCo XML) model description.
+type mjcfParser struct {
+	*importedModel
+	meshMap map[string]*commonpb.Mesh
+
+	// compiler settings
+	radians    bool
+	eulerSeq   string
+	meshDir    string
+	autoLimits bool
+
+	// defaults holds the attributes of each element type for each default class
+	defaults map[string]map[string]map[string]string
+	// meshes holds the paths of the mesh assets by name
+	meshes map[string]string
+	// bodies counts the bodies, to name those without names
+	bodies int
+	// sites holds the sites of the bodies, as fixed joints to links of their own
+	sites []*importedJoint
+}
+
+// UnmarshalModelMJCF will transfer the given MJCF (MuJoCo XML) data into an equivalent ModelConfig, returning the
+// elements of the MJCF which have no equivalent and were not imported, such as actuators, ball joints and cylinders.
+// Collision geometry is taken from the first geom of each body which takes part in collisions.
+// The meshMap parameter provides mesh proto messages keyed by file path, joined with the meshdir of the compiler.
+func UnmarshalModelMJCF(
+	xmlData []byte,
+	modelName string,
+	meshMap map[string]*commonpb.Mesh,
+) (*ModelConfigJSON, []string, error) {
+	root := &xmlElement{}
+	if err := xml.Unmarshal(xmlData, root); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to convert MJCF data to equivalent XML elements")
+	}
+	if root.XMLName.Local != "mujoco" {
+		return nil, nil, errors.Errorf("MJCF root element must be mujoco, not %s", root.XMLName.Local)
+	}
+
+	if modelName == "" {
+		modelName = root.attr("model")
+	}
+	p := &mjcfParser{
+		importedModel: &importedModel{name: modelName},
+		meshMap:       meshMap,
+		eulerSeq:      "xyz",
+		autoLimits:    true,
+		defaults:      map[string]map[string]map[string]string{},
+		meshes:        map[string]string{},
+	}
+	if err := p.parse(root); err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := p.toModelConfig(&ModelFile{Bytes: xmlData, Extension: "mjcf"})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, p.unsupported, nil
+}
+
+// ParseModelMJCFFile will read a given file and parse the contained MJCF data into an equivalent Model, returning the
+// elements of the MJCF which were not imported. It loads the STL and PLY meshes referenced in the MJCF from the local
+// filesystem.
+func ParseModelMJCFFile(filename, modelName string) (Model, []string, error) {
+	//nolint:gosec
+	xmlData, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read MJCF file")
+	}
+
+	meshMap, err := buildMeshMapFromMJCF(xmlData, filepath.Dir(filename))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to build mesh map")
+	}
+
+	mc, unsupported, err := UnmarshalModelMJCF(xmlData, modelName, meshMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	model, err := mc.ParseConfig(modelName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return model, unsupported, nil
+}
+
+// buildMeshMapFromMJCF loads the STL and PLY mesh assets of the MJCF from disk, relative to the MJCF file's directory.
+// Meshes in other formats are left out, and reported as not imported when used.
+func buildMeshMapFromMJCF(xmlData []byte, mjcfDir string) (map[string]*commonpb.Mesh, error) {
+	root := &xmlElement{}
+	if err := xml.Unmarshal(xmlData, root); err != nil {
+		return nil, errors.Wrap(err, "failed to parse MJCF for mesh extraction")
+	}
+	p := &mjcfParser{importedModel: &importedModel{}, meshes: map[string]string{}}
+	for i := range root.Children {
+		switch element := &root.Children[i]; element.XMLName.Local {
+		case "compiler":
+			if err := p.parseCompiler(element); err != nil {
+				return nil, err
+			}
+		case "asset":
+			p.parseAssets(element)
+		}
+	}
+
+	meshMap := map[string]*commonpb.Mesh{}
+	for _, meshPath := range p.meshes {
+		ext := strings.ToLower(filepath.Ext(meshPath))
+		if _, ok := meshMap[meshPath]; ok || (ext != ".stl" && ext != ".ply") {
+			continue
+		}
+		protoMesh, err := loadMeshFile(mjcfDir, meshPath)
+		if err != nil {
+			return nil, err
+		}
+		meshMap[meshPath] = protoMesh
+	}
+	return meshMap, nil
+}
+
+func (p *mjcfParser) parse(root *xmlElement) error {
+	// the compiler settings, defaults and assets apply to the bodies wherever they appear
+	for i := range root.Children {
+		switch element := &root.Children[i]; element.XMLName.Local {
+		case "compiler":
+			if err := p.parseCompiler(element); err != nil {
+				return err
+			}
+		case "default":
+			p.parseDefaults(element, mjcfMainClass, nil)
+		}
+	}
+	for i := range root.Children {
+		if element := &root.Children[i]; element.XMLName.Local == "asset" {
+			p.parseAssets(element)
+		}
+	}
+
+	for i := range root.Children {
+		element := &root.Children[i]
+		switch name := element.XMLName.Local; name {
+		case "compiler", "default", "asset", "equality":
+		case "worldbody":
+			if err := p.parseWorldBody(element); err != nil {
+				return err
+			}
+		default:
+			if !mjcfIgnored[name] {
+				p.skip("%s", name)
+			}
+		}
+	}
+	for i := range root.Children {
+		if element := &root.Children[i]; element.XMLName.Local == "equality" {
+			if err := p.parseEquality(element); err != nil {
+				return err
+			}
+		}
+	}
+
+	// A single site, such as the attachment site of an arm or the pinch site of a gripper, is the output frame.
+	if len(p.sites) == 1 {
+		p.links = append(p.links, &importedLink{name: p.sites[0].child})
+		p.joints = append(p.joints, p.sites[0])
+		p.output = p.sites[0].child
+	} else {
+		for _, site := range p.sites {
+			p.skip("site %q in body %q: only a single site is imported, as the output frame", site.child, site.parent)
+		}
+	}
+	return nil
+}
+
+func (p *mjcfParser) parseCompiler(compiler *xmlElement) error {
+	if coordinate := compiler.attr("coordinate"); coordinate != "" && coordinate != "local" {
+		return errors.Errorf("unsupported MJCF compiler coordinate %q", coordinate)
+	}
+	switch angle := compiler.attr("angle"); angle {
+	case "radian":
+		p.radians = true
+	case "degree", "":
+		p.radians = false
+	default:
+		return errors.Errorf("unsupported MJCF compiler angle %q", angle)
+	}
+	if eulerSeq := compiler.attr("eulerseq"); eulerSeq != "" {
+		p.eulerSeq = eulerSeq
+	}
+	if meshDir := compiler.attr("meshdir"); meshDir != "" {
+		p.meshDir = meshDir
+	} else if assetDir := compiler.attr("assetdir"); assetDir != "" && p.meshDir == "" {
+		p.meshDir = assetDir
+	}
+	if autoLimits := compiler.attr("autolimits"); autoLimits != "" {
+		p.autoLimits = autoLimits == "true"
+	}
+	return nil
+}
+
+// parseDefaults records the attributes given by a default class for each element type, including those inherited from
+// its parent class.
+func (p *mjcfParser) parseDefaults(element *xmlElement, class string, inherited map[string]map[string]string) {
+	if name := element.attr("class"); name != "" {
+		class = name
+	}
+	attributes := map[string]map[string]string{}
+	for elementType, attrs := range inherited {
+		attributes[elementType] = map[string]string{}
+		for name, value := range attrs {
+			attributes[elementType][name] = value
+		}
+	}
+	for _, child := range element.Children {
+		if child.XMLName.Local == "default" {
+			continue
+		}
+		if attributes[child.XMLName.Local] == nil {
+			attributes[child.XMLName.Local] = map[string]string{}
+		}
+		for _, a := range child.Attrs {
+			attributes[child.XMLName.Local][a.Name.Local] = a.Value
+		}
+	}
+	p.defaults[class] = attributes
+
+	for i := range element.Children {
+		if child := &element.Children[i]; child.XMLName.Local == "default" {
+			p.parseDefaults(child, class, attributes)
+		}
+	}
+}
+
+func (p *mjcfParser) parseAssets(asset *xmlElement) {
+	for i := range asset.Children {
+		mesh := &asset.Children[i]
+		if mesh.XMLName.Local != "mesh" {
+			continue
+		}
+		file := p.attr(mesh, mjcfMainClass, "file")
+		name := mesh.attr("name")
+		if name == "" {
+			name = strings.TrimSuffix(path.Base(file), path.Ext(file))
+		}
+		if scale := p.attr(mesh, mjcfMainClass, "scale"); scale != "" {
+			if s := spaceDelimitedStringToFloatSlice(scale); len(s) != 3 || s[0] != 1 || s[1] != 1 || s[2] != 1 {
+				p.skip("mesh %q: scaled meshes are not supported", name)
+				continue
+			}
+		}
+		if !path.IsAbs(file) {
+			file = path.Join(p.meshDir, file)
+		}
+		p.meshes[name] = file
+	}
+}
+
+// attr returns the named attribute of the element, falling back to the value given by the element's default class.
+func (p *mjcfParser) attr(element *xmlElement, class, name string) string {
+	if value := element.attr(name); value != "" {
+		return value
+	}
+	if elementClass := element.attr("class"); elementClass != "" {
+		class = elementClass
+	}
+	return p.defaults[class][element.XMLName.Local][name]
+}
+
+func (p *mjcfParser) parseWorldBody(worldBody *xmlElement) error {
+	for i := range worldBody.Children {
+		element := &worldBody.Children[i]
+		switch name := element.XMLName.Local; name {
+		case "body":
+			if err := p.parseBody(element, World, mjcfMainClass); err != nil {
+				return err
+			}
+		default:
+			if !mjcfIgnored[name] {
+				p.skip("%s in worldbody", element.describe())
+			}
+		}
+	}
+	return nil
+}
+
+// parseBody imports a body as a link, attached to its parent by each of its joints in turn.
+func (p *mjcfParser) parseBody(body *xmlElement, parent, class string) error {
+	p.bodies++
+	name := body.attr("name")
+	if name == "" {
+		name = fmt.Sprintf("body%d", p.bodies)
+	}
+	if childClass := body.attr("childclass"); childClass != "" {
+		class = childClass
+	}
+	bodyPose, err := p.pose(func(attr string) string { return body.attr(attr) })
+	if err != nil {
+		return errors.Wrapf(err, "body %q", name)
+	}
+
+	var joints []*importedJoint
+	link := &importedLink{name: name}
+	geoms := 0
+	for i := range body.Children {
+		element := &body.Children[i]
+		switch elementType := element.XMLName.Local; elementType {
+		case "joint":
+			joint, err := p.parseJoint(element, class, name, len(joints))
+			if err != nil {
+				return err
+			}
+			if joint != nil {
+				joints = append(joints, joint)
+			}
+		case "freejoint":
+			p.skip("freejoint in body %q: free joints are not supported, and are imported as fixed", name)
+		case "geom":
+			geoms++
+			geomName := element.describe()
+			if element.attr("name") == "" {
+				geomName = fmt.Sprintf("geom %d", geoms)
+			}
+			geometry, err := p.parseGeom(element, class, name, geomName)
+			if err != nil {
+				return err
+			}
+			switch {
+			case geometry == nil:
+			case link.geometry != nil:
+				p.skip("%s of body %q: only the first collision geom of a body is imported", geomName, name)
+			default:
+				link.geometry = geometry
+			}
+		case "site":
+			sitePose, err := p.pose(func(attr string) string { return p.attr(element, class, attr) })
+			if err != nil {
+				return errors.Wrapf(err, "site %q of body %q", element.attr("name"), name)
+			}
+			siteName := element.attr("name")
+			if siteName == "" {
+				siteName = fmt.Sprintf("%s_site%d", name, len(p.sites))
+			}
+			p.sites = append(p.sites, &importedJoint{
+				JointConfig: JointConfig{Type: FixedJoint},
+				parent:      name,
+				child:       siteName,
+				pose:        sitePose,
+				childPose:   spatialmath.NewZeroPose(),
+			})
+		case "body":
+		default:
+			if !mjcfIgnored[elementType] {
+				p.skip("%s in body %q", element.describe(), name)
+			}
+		}
+	}
+	p.links = append(p.links, link)
+
+	// The joints of a body move it in turn, each about an axis through its position in the body frame. Bodies without
+	// joints are welded to their parent.
+	if len(joints) == 0 {
+		if parent == World {
+			link.pose = bodyPose
+		} else {
+			p.joints = append(p.joints, &importedJoint{
+				JointConfig: JointConfig{Type: FixedJoint},
+				parent:      parent,
+				child:       name,
+				pose:        bodyPose,
+				childPose:   spatialmath.NewZeroPose(),
+			})
+		}
+	}
+	for i, joint := range joints {
+		joint.parent = parent
+		if i == 0 {
+			joint.pose = spatialmath.Compose(bodyPose, joint.pose)
+		}
+		if i == len(joints)-1 {
+			joint.child = name
+		} else {
+			joint.child = joint.ID + "_link"
+			p.links = append(p.links, &importedLink{name: joint.child})
+		}
+		parent = joint.child
+		p.joints = append(p.joints, joint)
+	}
+
+	for i := range body.Children {
+		if child := &body.Children[i]; child.XMLName.Local == "body" {
+			if err := p.parseBody(child, name, class); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseJoint returns the joint in our units, with its pose the position of the joint in the body frame. It returns nil
+// for joints which are not supported.
+func (p *mjcfParser) parseJoint(joint *xmlElement, class, body string, index int) (*importedJoint, error) {
+	name := joint.attr("name")
+	if name == "" {
+		name = fmt.Sprintf("%s_joint%d", body, index)
+	}
+	attr := func(attr string) string { return p.attr(joint, class, attr) }
+
+	position := spaceDelimitedStringToVector(attr("pos"), r3.Vector{}).Mul(1000)
+	axis := spaceDelimitedStringToVector(attr("axis"), r3.Vector{Z: 1})
+	imported := &importedJoint{
+		JointConfig: JointConfig{
+			ID:   name,
+			Axis: spatialmath.AxisConfig{X: axis.X, Y: axis.Y, Z: axis.Z},
+		},
+		pose:      spatialmath.NewPoseFromPoint(position),
+		childPose: spatialmath.NewPoseFromPoint(position.Mul(-1)),
+	}
+	if ref, err := stringToFloat(attr("ref"), 0); err != nil || ref != 0 {
+		p.skip("joint %q: joint reference positions are not supported", name)
+	}
+
+	lower, upper := math.Inf(-1), math.Inf(1)
+	switch limited := attr("limited"); {
+	case limited == "true", limited != "false" && p.autoLimits && attr("range") != "":
+		limits := spaceDelimitedStringToFloatSlice(attr("range"))
+		if len(limits) != 2 {
+			return nil, errors.Errorf("joint %q has invalid range %q", name, attr("range"))
+		}
+		lower, upper = limits[0], limits[1]
+	}
+
+	switch jointType := attr("type"); jointType {
+	case "hinge", "":
+		imported.Type = RevoluteJoint
+		if p.radians {
+			lower, upper = utils.RadToDeg(lower), utils.RadToDeg(upper)
+		}
+	case "slide":
+		imported.Type = PrismaticJoint
+		lower, upper = utils.MetersToMM(lower), utils.MetersToMM(upper)
+	default:
+		p.skip("joint %q: %s joints are not supported, and are imported as fixed", name, jointType)
+		return nil, nil
+	}
+	imported.Min, imported.Max = lower, upper
+	return imported, nil
+}
+
+// parseGeom returns the geometry of the described geom in the body frame, or nil if it doesn't take part in collisions or isn't
+// supported.
+func (p *mjcfParser) parseGeom(geom *xmlElement, class, body, name string) (spatialmath.Geometry, error) {
+	attr := func(attr string) string { return p.attr(geom, class, attr) }
+	if attr("contype") == "0" && attr("conaffinity") == "0" {
+		return nil, nil
+	}
+
+	pose, err := p.pose(attr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s of body %q", name, body)
+	}
+	size := spaceDelimitedStringToFloatSlice(attr("size"))
+	for i := range size {
+		size[i] = utils.MetersToMM(size[i])
+	}
+	geomType := attr("type")
+	if geomType == "" {
+		geomType = "sphere"
+		if attr("mesh") != "" {
+			geomType = "mesh"
+		}
+	}
+	if fromTo := spaceDelimitedStringToFloatSlice(attr("fromto")); len(fromTo) == 6 && geomType == "capsule" {
+		from := r3.Vector{X: fromTo[0], Y: fromTo[1], Z: fromTo[2]}.Mul(1000)
+		to := r3.Vector{X: fromTo[3], Y: fromTo[4], Z: fromTo[5]}.Mul(1000)
+		pose = spatialmath.NewPose(from.Add(to).Mul(0.5), zAxisOrientation(to.Sub(from)))
+		size = append(size[:min(len(size), 1)], to.Sub(from).Norm()/2)
+	}
+
+	switch geomType {
+	case "sphere":
+		if len(size) < 1 {
+			return nil, errors.Errorf("%s of body %q is missing its radius", name, body)
+		}
+		return spatialmath.NewSphere(pose, size[0], "")
+	case "capsule":
+		if len(size) < 2 {
+			return nil, errors.Errorf("%s of body %q is missing its radius and half length", name, body)
+		}
+		return spatialmath.NewCapsule(pose, size[0], 2*(size[0]+size[1]), "")
+	case "box":
+		if len(size) < 3 {
+			return nil, errors.Errorf("%s of body %q is missing its half sizes", name, body)
+		}
+		return spatialmath.NewBox(pose, r3.Vector{X: 2 * size[0], Y: 2 * size[1], Z: 2 * size[2]}, "")
+	case "mesh":
+		meshPath, ok := p.meshes[attr("mesh")]
+		if _, loaded := p.meshMap[meshPath]; !ok || !loaded {
+			p.skip("%s of body %q: mesh %q is not available", name, body, attr("mesh"))
+			return nil, nil
+		}
+		return importedMesh(pose, meshPath, p.meshMap)
+	default:
+		p.skip("%s of body %q: %s geoms are not supported", name, body, geomType)
+		return nil, nil
+	}
+}
+
+// parseEquality imports joint equality constraints as mimic joints.
+func (p *mjcfParser) parseEquality(equality *xmlElement) error {
+	jointsByName := map[string]*importedJoint{}
+	for _, joint := range p.joints {
+		jointsByName[joint.ID] = joint
+	}
+	for i := range equality.Children {
+		constraint := &equality.Children[i]
+		if constraint.XMLName.Local != "joint" {
+			p.skip("equality %s", constraint.describe())
+			continue
+		}
+		follower, ok := jointsByName[constraint.attr("joint1")]
+		leader, leaderOK := jointsByName[constraint.attr("joint2")]
+		if !ok || !leaderOK || follower.Type == FixedJoint {
+			p.skip("joint equality of %q and %q: both joints must be imported", constraint.attr("joint1"), constraint.attr("joint2"))
+			continue
+		}
+		coefficients := []float64{0, 1, 0, 0, 0}
+		if polycoef := p.attr(constraint, mjcfMainClass, "polycoef"); polycoef != "" {
+			coefficients = append(spaceDelimitedStringToFloatSlice(polycoef), 0, 0, 0, 0, 0)[:5]
+		}
+		if coefficients[1] == 0 || coefficients[2] != 0 || coefficients[3] != 0 || coefficients[4] != 0 {
+			p.skip("joint equality of %q and %q: only linear couplings are supported", follower.ID, leader.ID)
+			continue
+		}
+
+		// the coefficients are in the MuJoCo units of radians and meters
+		scale := func(joint *importedJoint) float64 {
+			if joint.Type == PrismaticJoint {
+				return 1000
+			}
+			return 1
+		}
+		follower.Mimic = &MimicConfig{
+			Joint:           leader.ID,
+			ValueMultiplier: coefficients[1] * scale(follower) / scale(leader),
+			ValueOffset:     coefficients[0] * scale(follower),
+		}
+		// the limits of the leader govern the range of a mimic joint
+		follower.Min, follower.Max = 0, 0
+	}
+	return nil
+}
+
+// pose returns the pose given by the pos attribute and any of the orientation attributes of an element.
+func (p *mjcfParser) pose(attr func(string) string) (spatialmath.Pose, error) {
+	position := spaceDelimitedStringToVector(attr("pos"), r3.Vector{}).Mul(1000)
+	var orientation spatialmath.Orientation = spatialmath.NewZeroOrientation()
+	switch {
+	case attr("quat") != "":
+		q := spaceDelimitedStringToFloatSlice(attr("quat"))
+		if len(q) != 4 {
+			return nil, errors.Errorf("invalid quat %q", attr("quat"))
+		}
+		orientation = (*spatialmath.Quaternion)(&quat.Number{Real: q[0], Imag: q[1], Jmag: q[2], Kmag: q[3]})
+		normalized := spatialmath.Normalize(orientation.Quaternion())
+		orientation = (*spatialmath.Quaternion)(&normalized)
+	case attr("axisangle") != "":
+		aa := spaceDelimitedStringToFloatSlice(attr("axisangle"))
+		if len(aa) != 4 {
+			return nil, errors.Errorf("invalid axisangle %q", attr("axisangle"))
+		}
+		orientation = &spatialmath.R4AA{Theta: p.angle(aa[3]), RX: aa[0], RY: aa[1], RZ: aa[2]}
+	case attr("euler") != "":
+		angles := spaceDelimitedStringToFloatSlice(attr("euler"))
+		if len(angles) != 3 || len(p.eulerSeq) != 3 {
+			return nil, errors.Errorf("invalid euler %q with sequence %q", attr("euler"), p.eulerSeq)
+		}
+		q := quat.Number{Real: 1}
+		for i, axisName := range p.eulerSeq {
+			axis := map[rune]r3.Vector{'x': {X: 1}, 'y': {Y: 1}, 'z': {Z: 1}}[unicode.ToLower(axisName)]
+			if axis.Norm() == 0 {
+				return nil, errors.Errorf("invalid euler sequence %q", p.eulerSeq)
+			}
+			rotation := (&spatialmath.R4AA{Theta: p.angle(angles[i]), RX: axis.X, RY: axis.Y, RZ: axis.Z}).ToQuat()
+			// lower case axes rotate with the frame, while upper case axes are fixed
+			if unicode.IsLower(axisName) {
+				q = quat.Mul(q, rotation)
+			} else {
+				q = quat.Mul(rotation, q)
+			}
+		}
+		orientation = (*spatialmath.Quaternion)(&q)
+	case attr("xyaxes") != "":
+		axes := spaceDelimitedStringToFloatSlice(attr("xyaxes"))
+		if len(axes) != 6 {
+			return nil, errors.Errorf("invalid xyaxes %q", attr("xyaxes"))
+		}
+		x := r3.Vector{X: axes[0], Y: axes[1], Z: axes[2]}.Normalize()
+		y := r3.Vector{X: axes[3], Y: axes[4], Z: axes[5]}
+		y = y.Sub(x.Mul(x.Dot(y))).Normalize()
+		z := x.Cross(y)
+		rotation, err := spatialmath.NewRotationMatrix([]float64{x.X, y.X, z.X, x.Y, y.Y, z.Y, x.Z, y.Z, z.Z})
+		if err != nil {
+			return nil, err
+		}
+		orientation = rotation
+	case attr("zaxis") != "":
+		orientation = zAxisOrientation(spaceDelimitedStringToVector(attr("zaxis"), r3.Vector{Z: 1}))
+	}
+	return spatialmath.NewPose(position, orientation), nil
+}
+
+// angle returns the given MJCF angle in radians.
+func (p *mjcfParser) angle(angle float64) float64 {
+	if p.radians {
+		return angle
+	}
+	return utils.DegToRad(angle)
+}
+
+// zAxisOrientation returns the smallest rotation taking the z axis to the given direction.
+func zAxisOrientation(direction r3.Vector) spatialmath.Orientation {
+	direction = direction.Normalize()
+	axis := r3.Vector{Z: 1}.Cross(direction)
+	if axis.Norm() < 1e-9 {
+		if direction.Z < 0 {
+			return &spatialmath.R4AA{Theta: math.Pi, RX: 1}
+		}
+		return spatialmath.NewZeroOrientation()
+	}
+	return &spatialmath.R4AA{Theta: math.Atan2(axis.Norm(), direction.Z), RX: axis.X, RY: axis.Y, RZ: axis.Z}
+}
+
+// spaceDelimitedStringToVector parses a space delimited vector, returning the default if it isn't one.
+func spaceDelimitedStringToVector(s string, defaultVector r3.Vector) r3.Vector {
+	v := spaceDelimitedStringToFloatSlice(s)
+	if len(v) != 3 {
+		return defaultVector
+	}
+	return r3.Vector{X: v[0], Y: v[1], Z: v[2]}
+}
+
+// stringToFloat parses a float, returning the default if empty.
+func stringToFloat(s string, defaultValue float64) (float64, error) {
+	if s == "" {
+		return defaultValue, nil
+	}
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
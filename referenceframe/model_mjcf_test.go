@@ -0,0 +1,143 @@
+package referenceframe
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestParseMJCFFile(t *testing.T) {
+	model, unsupported, err := ParseModelMJCFFile(utils.ResolveFile("referenceframe/testfiles/mjcf_arm.xml"), "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, model.Name(), test.ShouldEqual, "mjcf_arm")
+	test.That(t, unsupported, test.ShouldResemble, []string{
+		`geom "floor" in worldbody`,
+		`geom "bumper" of body "link2": only the first collision geom of a body is imported`,
+		`geom 1 of body "right_finger": cylinder geoms are not supported`,
+		"actuator",
+		"equality weld",
+	})
+
+	// right_joint mimics left_joint, so only joint1, joint2 and left_joint are inputs
+	test.That(t, model.DoF(), test.ShouldResemble, []Limit{{-3, 3}, {0, 100}, {0, 40}})
+	test.That(t, model.ModelConfig().OutputFrames, test.ShouldResemble, []string{"tcp"})
+
+	// link2 is pitched so that the slide of joint2 and the tcp point along x
+	pose, err := model.Transform([]Input{0, 0, 0})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(pose.Point(), r3.Vector{X: 100, Z: 350}, 1e-6), test.ShouldBeTrue)
+	pose, err = model.Transform([]Input{math.Pi / 2, 50, 0})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(pose.Point(), r3.Vector{Y: 150, Z: 350}, 1e-6), test.ShouldBeTrue)
+
+	// the base mesh, link1 capsule, link2 box and left_finger sphere, without the visual box or the right finger cylinder
+	geometries, err := model.Geometries([]Input{0, 0, 10})
+	test.That(t, err, test.ShouldBeNil)
+	byLabel := map[string]spatialmath.Geometry{}
+	for _, geometry := range geometries.Geometries() {
+		byLabel[geometry.Label()] = geometry
+	}
+	test.That(t, len(byLabel), test.ShouldEqual, 4)
+	test.That(t, byLabel["mjcf_arm:base"].Pose().Point(), test.ShouldResemble, r3.Vector{Z: 50})
+	capsule, err := spatialmath.NewGeometryConfig(byLabel["mjcf_arm:link1"])
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, capsule.Type, test.ShouldEqual, spatialmath.CapsuleType)
+	test.That(t, capsule.R, test.ShouldAlmostEqual, 30)
+	test.That(t, capsule.L, test.ShouldAlmostEqual, 260)
+	test.That(t, spatialmath.R3VectorAlmostEqual(byLabel["mjcf_arm:link1"].Pose().Point(), r3.Vector{Z: 250}, 1e-6), test.ShouldBeTrue)
+	test.That(t, spatialmath.R3VectorAlmostEqual(byLabel["mjcf_arm:link2"].Pose().Point(), r3.Vector{X: 50, Z: 350}, 1e-6),
+		test.ShouldBeTrue)
+	test.That(t, spatialmath.R3VectorAlmostEqual(byLabel["mjcf_arm:left_finger"].Pose().Point(), r3.Vector{X: 100, Y: 30, Z: 350}, 1e-6),
+		test.ShouldBeTrue)
+
+	// .xml files are recognized as MJCF by their root element, and the elements left out are logged
+	logger, logs := logging.NewObservedTestLogger(t)
+	model, err = KinematicModelFromFileWithLogger(utils.ResolveFile("referenceframe/testfiles/mjcf_arm.xml"), "foo", logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, model.Name(), test.ShouldEqual, "foo")
+	test.That(t, model.ModelConfig().OriginalFile.Extension, test.ShouldEqual, "mjcf")
+	warnings := logs.FilterMessageSnippet("left out").All()
+	test.That(t, warnings, test.ShouldHaveLength, 1)
+	test.That(t, warnings[0].ContextMap()["elements"], test.ShouldContain, "actuator")
+}
+
+func TestUnmarshalMJCF(t *testing.T) {
+	t.Run("defaults and orientations", func(t *testing.T) {
+		// degrees by default, with joint axes and ranges from the main and child classes
+		mjcf := `<mujoco model="defaults">
+			<default>
+				<joint axis="0 1 0" range="-90 90"/>
+				<default class="wrist">
+					<joint axis="1 0 0"/>
+				</default>
+			</default>
+			<worldbody>
+				<body name="a" axisangle="0 0 1 90">
+					<joint name="ja"/>
+					<body name="b" pos="0 0 0.1" childclass="wrist">
+						<joint name="jb" pos="0 0 0.1" range="-45 45"/>
+						<joint name="jc" type="ball"/>
+					</body>
+				</body>
+			</worldbody>
+		</mujoco>`
+		cfg, unsupported, err := UnmarshalModelMJCF([]byte(mjcf), "", nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, unsupported, test.ShouldResemble, []string{`joint "jc": ball joints are not supported, and are imported as fixed`})
+		model, err := cfg.ParseConfig("")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, model.DoF(), test.ShouldResemble, []Limit{{-math.Pi / 2, math.Pi / 2}, {-math.Pi / 4, math.Pi / 4}})
+		for _, joint := range cfg.Joints {
+			if joint.ID == "jb" {
+				test.That(t, joint.Axis, test.ShouldResemble, spatialmath.AxisConfig{X: 1})
+			}
+		}
+
+		// jb rotates b about an axis through its position 100mm above b, which is along y as a is yawed by 90 degrees
+		pose, err := model.Transform([]Input{0, 0.5})
+		test.That(t, err, test.ShouldBeNil)
+		expected := r3.Vector{X: -100 * math.Sin(0.5), Z: 200 - 100*math.Cos(0.5)}
+		test.That(t, spatialmath.R3VectorAlmostEqual(pose.Point(), expected, 1e-6), test.ShouldBeTrue)
+		// likewise, the y axis of ja is along -x
+		pose, err = model.Transform([]Input{math.Pi / 2, 0})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, spatialmath.R3VectorAlmostEqual(pose.Point(), r3.Vector{Y: 100}, 1e-6), test.ShouldBeTrue)
+	})
+
+	t.Run("polynomial coupling", func(t *testing.T) {
+		mjcf := `<mujoco>
+			<worldbody>
+				<body name="a">
+					<joint name="ja" range="-1 1"/>
+					<body name="b">
+						<joint name="jb" range="-1 1"/>
+					</body>
+				</body>
+			</worldbody>
+			<equality>
+				<joint joint1="jb" joint2="ja" polycoef="0 1 0.5 0 0"/>
+			</equality>
+		</mujoco>`
+		cfg, unsupported, err := UnmarshalModelMJCF([]byte(mjcf), "coupled", nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cfg.Name, test.ShouldEqual, "coupled")
+		test.That(t, unsupported, test.ShouldResemble, []string{`joint equality of "jb" and "ja": only linear couplings are supported`})
+		for _, joint := range cfg.Joints {
+			test.That(t, joint.Mimic, test.ShouldBeNil)
+		}
+	})
+
+	t.Run("not MJCF", func(t *testing.T) {
+		xmlData, err := os.ReadFile(utils.ResolveFile("referenceframe/testfiles/ur5e.urdf"))
+		test.That(t, err, test.ShouldBeNil)
+		_, _, err = UnmarshalModelMJCF(xmlData, "", nil)
+		test.That(t, err, test.ShouldBeError, "MJCF root element must be mujoco, not robot")
+	})
+}
@@ -0,0 +1,555 @@
+package referenceframe
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	"gonum.org/v1/gonum/num/quat"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	// sdfModelFrame is the name of the implicit frame of an SDFormat model.
+	sdfModelFrame = "__model__"
+	// sdfUnlimited is the magnitude of the default SDFormat joint limits, at and beyond which a joint has no limit.
+	sdfUnlimited = 1e16
+)
+
+// sdfIgnored lists the SDFormat model elements which only affect simulation, so are left out of the report of elements
+// which were not imported.
+var sdfIgnored = map[string]bool{
+	"pose": true, "static": true, "self_collide": true, "allow_auto_disable": true, "enable_wind": true,
+}
+
+// sdfParser holds the state needed to import a model from an SDFormat description.
+type sdfParser struct {
+	*importedModel
+	meshMap map[string]*commonpb.Mesh
+
+	// frames holds the elements which poses may be relative to, and poses their resolved poses in the model frame
+	frames    map[string]*xmlElement
+	poses     map[string]spatialmath.Pose
+	resolving map[string]bool
+}
+
+// UnmarshalModelSDF will transfer the first model of the given SDFormat data into an equivalent ModelConfig, returning
+// the elements of the SDFormat which have no equivalent and were not imported, such as plugins, ball joints and
+// cylinders. Collision geometry is taken from the first collision of each link.
+// The meshMap parameter provides mesh proto messages keyed by mesh URI, with any model://, package:// or file:// prefix
+// removed as by normalizeSDFMeshPath.
+func UnmarshalModelSDF(
+	xmlData []byte,
+	modelName string,
+	meshMap map[string]*commonpb.Mesh,
+) (*ModelConfigJSON, []string, error) {
+	p := &sdfParser{
+		importedModel: &importedModel{},
+		meshMap:       meshMap,
+		frames:        map[string]*xmlElement{},
+		poses:         map[string]spatialmath.Pose{},
+		resolving:     map[string]bool{},
+	}
+	model, err := p.findModel(xmlData)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.name = modelName
+	if p.name == "" {
+		p.name = model.attr("name")
+	}
+	if err := p.parse(model); err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := p.toModelConfig(&ModelFile{Bytes: xmlData, Extension: "sdf"})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, p.unsupported, nil
+}
+
+// ParseModelSDFFile will read a given file and parse the contained SDFormat data into an equivalent Model, returning
+// the elements of the SDFormat which were not imported. It loads the STL and PLY meshes referenced in the SDFormat from
+// the local filesystem.
+func ParseModelSDFFile(filename, modelName string) (Model, []string, error) {
+	//nolint:gosec
+	xmlData, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read SDFormat file")
+	}
+
+	meshMap, err := buildMeshMapFromSDF(xmlData, filepath.Dir(filename))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to build mesh map")
+	}
+
+	mc, unsupported, err := UnmarshalModelSDF(xmlData, modelName, meshMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	model, err := mc.ParseConfig(modelName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return model, unsupported, nil
+}
+
+// buildMeshMapFromSDF loads the STL and PLY meshes of the collisions of the SDFormat model from disk, relative to the
+// SDFormat file's directory. Meshes in other formats or at remote URIs are left out, and reported as not imported.
+func buildMeshMapFromSDF(xmlData []byte, sdfDir string) (map[string]*commonpb.Mesh, error) {
+	p := &sdfParser{importedModel: &importedModel{}}
+	model, err := p.findModel(xmlData)
+	if err != nil {
+		return nil, err
+	}
+
+	meshMap := map[string]*commonpb.Mesh{}
+	for _, link := range model.Children {
+		if link.XMLName.Local != "link" {
+			continue
+		}
+		for _, collision := range link.Children {
+			geometry := collision.child("geometry")
+			if collision.XMLName.Local != "collision" || geometry == nil || geometry.child("mesh") == nil {
+				continue
+			}
+			uri := geometry.child("mesh").childText("uri")
+			meshPath := normalizeSDFMeshPath(uri)
+			ext := strings.ToLower(filepath.Ext(meshPath))
+			if _, ok := meshMap[meshPath]; ok || strings.Contains(meshPath, "://") || (ext != ".stl" && ext != ".ply") {
+				continue
+			}
+			protoMesh, err := loadMeshFile(sdfDir, meshPath)
+			if err != nil {
+				return nil, fmt.Errorf("%w (referenced as %s)", err, uri)
+			}
+			meshMap[meshPath] = protoMesh
+		}
+	}
+	return meshMap, nil
+}
+
+// normalizeSDFMeshPath converts an SDFormat mesh URI to a relative path.
+// For example: "model://ur5e/meshes/base.stl" -> "meshes/base.stl".
+func normalizeSDFMeshPath(uri string) string {
+	switch {
+	case strings.HasPrefix(uri, "file://"):
+		return strings.TrimPrefix(uri, "file://")
+	case strings.HasPrefix(uri, "model://"):
+		uri = strings.TrimPrefix(uri, "model://")
+		if idx := strings.Index(uri, "/"); idx != -1 {
+			uri = uri[idx+1:]
+		}
+		return uri
+	default:
+		return normalizeURDFMeshPath(uri)
+	}
+}
+
+// findModel returns the model element of the SDFormat data, which is either the first model of the sdf element or that
+// of its world.
+func (p *sdfParser) findModel(xmlData []byte) (*xmlElement, error) {
+	root := &xmlElement{}
+	if err := xml.Unmarshal(xmlData, root); err != nil {
+		return nil, errors.Wrap(err, "failed to convert SDFormat data to equivalent XML elements")
+	}
+	if root.XMLName.Local != "sdf" {
+		return nil, errors.Errorf("SDFormat root element must be sdf, not %s", root.XMLName.Local)
+	}
+	parent := root
+	if world := root.child("world"); world != nil && root.child("model") == nil {
+		parent = world
+	}
+
+	var model *xmlElement
+	for i := range parent.Children {
+		if element := &parent.Children[i]; element.XMLName.Local == "model" {
+			if model != nil {
+				p.skip("%s: only the first model is imported", element.describe())
+				continue
+			}
+			model = element
+		}
+	}
+	if model == nil {
+		return nil, errors.New("SDFormat data has no model")
+	}
+	return model, nil
+}
+
+func (p *sdfParser) parse(model *xmlElement) error {
+	for i := range model.Children {
+		element := &model.Children[i]
+		switch name := element.XMLName.Local; name {
+		case "link", "joint", "frame":
+			if _, ok := p.frames[element.attr("name")]; ok || element.attr("name") == "" {
+				return errors.Errorf("SDFormat %s must have a unique name, not %q", name, element.attr("name"))
+			}
+			p.frames[element.attr("name")] = element
+		default:
+			if !sdfIgnored[name] {
+				p.skip("%s", element.describe())
+			}
+		}
+	}
+
+	var outputs []*xmlElement
+	for i := range model.Children {
+		element := &model.Children[i]
+		var err error
+		switch element.XMLName.Local {
+		case "link":
+			err = p.parseLink(element)
+		case "joint":
+			err = p.parseJoint(element)
+		case "frame":
+			if attachedTo := p.frames[element.attr("attached_to")]; attachedTo != nil && attachedTo.XMLName.Local == "link" {
+				outputs = append(outputs, element)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// A single frame attached to a link, such as the tool frame of an arm or gripper, is the output frame.
+	if len(outputs) == 1 {
+		frame := outputs[0]
+		pose, err := p.relativePose(frame.attr("attached_to"), frame.attr("name"))
+		if err != nil {
+			return err
+		}
+		p.links = append(p.links, &importedLink{name: frame.attr("name")})
+		p.joints = append(p.joints, &importedJoint{
+			JointConfig: JointConfig{Type: FixedJoint},
+			parent:      frame.attr("attached_to"),
+			child:       frame.attr("name"),
+			pose:        pose,
+			childPose:   spatialmath.NewZeroPose(),
+		})
+		p.output = frame.attr("name")
+	} else {
+		for _, frame := range outputs {
+			p.skip("%s: only a single frame attached to a link is imported, as the output frame", frame.describe())
+		}
+	}
+	return nil
+}
+
+func (p *sdfParser) parseLink(link *xmlElement) error {
+	name := link.attr("name")
+	pose, err := p.modelPose(name)
+	if err != nil {
+		return err
+	}
+	imported := &importedLink{name: name, pose: pose}
+	p.links = append(p.links, imported)
+
+	for i := range link.Children {
+		collision := &link.Children[i]
+		if collision.XMLName.Local != "collision" {
+			continue
+		}
+		geometry, err := p.parseCollision(collision, name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case geometry == nil:
+		case imported.geometry != nil:
+			p.skip("%s of link %q: only the first collision of a link is imported", collision.describe(), name)
+		default:
+			imported.geometry = geometry
+		}
+	}
+	return nil
+}
+
+// parseCollision returns the geometry of a collision in the link frame, or nil if it isn't supported.
+func (p *sdfParser) parseCollision(collision *xmlElement, link string) (spatialmath.Geometry, error) {
+	origin, relativeTo, err := sdfPose(collision)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s of link %q", collision.describe(), link)
+	}
+	if relativeTo != "" {
+		if origin, err = p.relativePose(link, relativeTo, origin); err != nil {
+			return nil, err
+		}
+	}
+
+	geometry := collision.child("geometry")
+	if geometry == nil || len(geometry.Children) == 0 {
+		return nil, errors.Errorf("%s of link %q has no geometry", collision.describe(), link)
+	}
+	shape := &geometry.Children[0]
+	switch shape.XMLName.Local {
+	case "box":
+		size := spaceDelimitedStringToFloatSlice(shape.childText("size"))
+		if len(size) != 3 {
+			return nil, errors.Errorf("%s of link %q has invalid box size", collision.describe(), link)
+		}
+		dims := r3.Vector{X: size[0], Y: size[1], Z: size[2]}.Mul(1000)
+		return spatialmath.NewBox(origin, dims, "")
+	case "sphere":
+		radius, err := strconv.ParseFloat(shape.childText("radius"), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s of link %q has invalid sphere radius", collision.describe(), link)
+		}
+		return spatialmath.NewSphere(origin, utils.MetersToMM(radius), "")
+	case "capsule":
+		radius, err := strconv.ParseFloat(shape.childText("radius"), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s of link %q has invalid capsule radius", collision.describe(), link)
+		}
+		length, err := strconv.ParseFloat(shape.childText("length"), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s of link %q has invalid capsule length", collision.describe(), link)
+		}
+		// the length of an SDFormat capsule excludes its caps
+		return spatialmath.NewCapsule(origin, utils.MetersToMM(radius), utils.MetersToMM(length+2*radius), "")
+	case "mesh":
+		if scale := spaceDelimitedStringToFloatSlice(shape.childText("scale")); len(scale) == 3 &&
+			(scale[0] != 1 || scale[1] != 1 || scale[2] != 1) {
+			p.skip("%s of link %q: scaled meshes are not supported", collision.describe(), link)
+			return nil, nil
+		}
+		meshPath := normalizeSDFMeshPath(shape.childText("uri"))
+		if _, ok := p.meshMap[meshPath]; !ok {
+			p.skip("%s of link %q: mesh %q is not available", collision.describe(), link, shape.childText("uri"))
+			return nil, nil
+		}
+		return importedMesh(origin, meshPath, p.meshMap)
+	default:
+		p.skip("%s of link %q: %s geometries are not supported", collision.describe(), link, shape.XMLName.Local)
+		return nil, nil
+	}
+}
+
+func (p *sdfParser) parseJoint(joint *xmlElement) error {
+	name := joint.attr("name")
+	parent, child := joint.childText("parent"), joint.childText("child")
+	if parent == "" || child == "" {
+		return errors.Errorf("joint %q must have a parent and a child", name)
+	}
+
+	jointPose, err := p.modelPose(name)
+	if err != nil {
+		return err
+	}
+	parentPose := spatialmath.NewZeroPose()
+	if parent != World {
+		if parentPose, err = p.modelPose(parent); err != nil {
+			return err
+		}
+	}
+	childPose, err := p.modelPose(child)
+	if err != nil {
+		return err
+	}
+	imported := &importedJoint{
+		JointConfig: JointConfig{ID: name, Type: FixedJoint},
+		parent:      parent,
+		child:       child,
+		pose:        spatialmath.PoseBetween(parentPose, jointPose),
+		childPose:   spatialmath.PoseBetween(jointPose, childPose),
+	}
+	p.joints = append(p.joints, imported)
+
+	jointType := joint.attr("type")
+	switch jointType {
+	case FixedJoint:
+		return nil
+	case RevoluteJoint, ContinuousJoint, PrismaticJoint:
+	default:
+		p.skip("joint %q: %s joints are not supported, and are imported as fixed", name, jointType)
+		return nil
+	}
+	if joint.child("axis2") != nil {
+		p.skip("axis2 of joint %q", name)
+	}
+	axisElement := joint.child("axis")
+	if axisElement == nil {
+		return errors.Errorf("joint %q has no axis", name)
+	}
+
+	// the axis is expressed in the joint frame by default
+	axis := spaceDelimitedStringToVector(axisElement.childText("xyz"), r3.Vector{Z: 1})
+	expressedIn := ""
+	if xyz := axisElement.child("xyz"); xyz != nil {
+		expressedIn = xyz.attr("expressed_in")
+	}
+	if axisElement.childText("use_parent_model_frame") == "true" {
+		expressedIn = sdfModelFrame
+	}
+	if expressedIn != "" {
+		rotation, err := p.relativePose(name, expressedIn)
+		if err != nil {
+			return err
+		}
+		axis = spatialmath.Compose(spatialmath.NewPoseFromOrientation(rotation.Orientation()), spatialmath.NewPoseFromPoint(axis)).Point()
+	}
+	imported.Axis = spatialmath.AxisConfig{X: axis.X, Y: axis.Y, Z: axis.Z}
+
+	lower, upper := math.Inf(-1), math.Inf(1)
+	if limit := axisElement.child("limit"); limit != nil && jointType != ContinuousJoint {
+		if value, err := strconv.ParseFloat(limit.childText("lower"), 64); err == nil && value > -sdfUnlimited {
+			lower = value
+		}
+		if value, err := strconv.ParseFloat(limit.childText("upper"), 64); err == nil && value < sdfUnlimited {
+			upper = value
+		}
+	}
+	if jointType == PrismaticJoint {
+		imported.Type = PrismaticJoint
+		imported.Min, imported.Max = utils.MetersToMM(lower), utils.MetersToMM(upper)
+	} else {
+		imported.Type = RevoluteJoint
+		imported.Min, imported.Max = utils.RadToDeg(lower), utils.RadToDeg(upper)
+	}
+
+	if mimic := axisElement.child("mimic"); mimic != nil {
+		return p.parseMimic(imported, mimic)
+	}
+	return nil
+}
+
+// parseMimic imports the mimic element of a joint axis, available since SDFormat 1.11.
+func (p *sdfParser) parseMimic(follower *importedJoint, mimic *xmlElement) error {
+	leader := p.frames[mimic.attr("joint")]
+	if leader == nil || leader.XMLName.Local != "joint" {
+		return fmt.Errorf("%w: joint %q references source %q", ErrMimicSourceNotFound, follower.ID, mimic.attr("joint"))
+	}
+	value := func(name string, defaultValue float64) (float64, error) {
+		v, err := stringToFloat(mimic.childText(name), defaultValue)
+		return v, errors.Wrapf(err, "mimic of joint %q has invalid %s", follower.ID, name)
+	}
+	multiplier, err := value("multiplier", 1)
+	if err != nil {
+		return err
+	}
+	offset, err := value("offset", 0)
+	if err != nil {
+		return err
+	}
+	reference, err := value("reference", 0)
+	if err != nil {
+		return err
+	}
+
+	// follower = multiplier * (leader - reference) + offset, in the SDFormat units of radians and meters
+	followerScale, leaderScale := 1., 1.
+	if follower.Type == PrismaticJoint {
+		followerScale = 1000
+	}
+	if leader.attr("type") == PrismaticJoint {
+		leaderScale = 1000
+	}
+	follower.Mimic = &MimicConfig{
+		Joint:           leader.attr("name"),
+		ValueMultiplier: multiplier * followerScale / leaderScale,
+		ValueOffset:     (offset - multiplier*reference) * followerScale,
+	}
+	// the limits of the leader govern the range of a mimic joint
+	follower.Min, follower.Max = 0, 0
+	return nil
+}
+
+// modelPose returns the pose of the named link, joint or frame in the model frame.
+func (p *sdfParser) modelPose(name string) (spatialmath.Pose, error) {
+	if name == "" || name == sdfModelFrame || name == World {
+		return spatialmath.NewZeroPose(), nil
+	}
+	if pose, ok := p.poses[name]; ok {
+		return pose, nil
+	}
+	element, ok := p.frames[name]
+	if !ok {
+		return nil, NewFrameNotInListOfTransformsError(name)
+	}
+	if p.resolving[name] {
+		return nil, errors.Wrapf(ErrCircularReference, "resolving the pose of %q", name)
+	}
+	p.resolving[name] = true
+	defer delete(p.resolving, name)
+
+	pose, relativeTo, err := sdfPose(element)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", element.describe())
+	}
+	if relativeTo == "" {
+		switch element.XMLName.Local {
+		case "joint":
+			relativeTo = element.childText("child")
+		case "frame":
+			relativeTo = element.attr("attached_to")
+		}
+	}
+	reference, err := p.modelPose(relativeTo)
+	if err != nil {
+		return nil, err
+	}
+	p.poses[name] = spatialmath.Compose(reference, pose)
+	return p.poses[name], nil
+}
+
+// relativePose returns the pose of the named frame, composed with any given poses, in the frame of the named base.
+func (p *sdfParser) relativePose(base, name string, poses ...spatialmath.Pose) (spatialmath.Pose, error) {
+	basePose, err := p.modelPose(base)
+	if err != nil {
+		return nil, err
+	}
+	pose, err := p.modelPose(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, next := range poses {
+		pose = spatialmath.Compose(pose, next)
+	}
+	return spatialmath.PoseBetween(basePose, pose), nil
+}
+
+// sdfPose returns the pose given by the pose element of an element, in millimeters, and the frame it is relative to.
+func sdfPose(element *xmlElement) (spatialmath.Pose, string, error) {
+	poseElement := element.child("pose")
+	if poseElement == nil {
+		return spatialmath.NewZeroPose(), "", nil
+	}
+	values := spaceDelimitedStringToFloatSlice(poseElement.Text)
+	if len(values) == 0 {
+		return spatialmath.NewZeroPose(), poseElement.attr("relative_to"), nil
+	}
+	point := func() r3.Vector { return r3.Vector{X: values[0], Y: values[1], Z: values[2]}.Mul(1000) }
+
+	switch format := poseElement.attr("rotation_format"); format {
+	case "euler_rpy", "":
+		if len(values) != 6 {
+			return nil, "", errors.Errorf("invalid pose %q", poseElement.Text)
+		}
+		rpy := values[3:]
+		if poseElement.attr("degrees") == "true" {
+			for i := range rpy {
+				rpy[i] = utils.DegToRad(rpy[i])
+			}
+		}
+		orientation := &spatialmath.EulerAngles{Roll: rpy[0], Pitch: rpy[1], Yaw: rpy[2]}
+		return spatialmath.NewPose(point(), orientation), poseElement.attr("relative_to"), nil
+	case "quat_xyzw":
+		if len(values) != 7 {
+			return nil, "", errors.Errorf("invalid pose %q", poseElement.Text)
+		}
+		q := spatialmath.Normalize(quat.Number{Real: values[6], Imag: values[3], Jmag: values[4], Kmag: values[5]})
+		return spatialmath.NewPose(point(), (*spatialmath.Quaternion)(&q)), poseElement.attr("relative_to"), nil
+	default:
+		return nil, "", errors.Errorf("unsupported pose rotation_format %q", format)
+	}
+}
@@ -0,0 +1,132 @@
+package referenceframe
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestParseSDFFile(t *testing.T) {
+	model, unsupported, err := ParseModelSDFFile(utils.ResolveFile("referenceframe/testfiles/sdf_arm.sdf"), "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, model.Name(), test.ShouldEqual, "sdf_arm")
+	test.That(t, unsupported, test.ShouldResemble, []string{
+		`plugin "controller"`,
+		`collision "upper_arm_bumper" of link "upper_arm": only the first collision of a link is imported`,
+		`collision "forearm_collision" of link "forearm": cylinder geometries are not supported`,
+	})
+
+	// right_joint mimics left_joint, and the elbow has no limits
+	test.That(t, model.DoF(), test.ShouldResemble, []Limit{{-3, 3}, {math.Inf(-1), math.Inf(1)}, {0, 40}})
+	test.That(t, model.ModelConfig().OutputFrames, test.ShouldResemble, []string{"tcp"})
+	for _, joint := range model.ModelConfig().Joints {
+		switch joint.ID {
+		case "elbow":
+			// the elbow axis is vertical in the model frame, which is along -x in the pitched joint frame
+			axis := r3.Vector{X: joint.Axis.X, Y: joint.Axis.Y, Z: joint.Axis.Z}
+			test.That(t, spatialmath.R3VectorAlmostEqual(axis, r3.Vector{X: -1}, 1e-9), test.ShouldBeTrue)
+		case "right_joint":
+			test.That(t, joint.Mimic, test.ShouldResemble, &MimicConfig{Joint: "left_joint", ValueMultiplier: 1})
+		}
+	}
+
+	// the forearm is pitched so that the tcp points along x, and the elbow swings it about z
+	pose, err := model.Transform([]Input{0, 0, 0})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(pose.Point(), r3.Vector{X: 100, Z: 400}, 1e-6), test.ShouldBeTrue)
+	pose, err = model.Transform([]Input{0, math.Pi / 2, 0})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(pose.Point(), r3.Vector{Y: 100, Z: 400}, 1e-6), test.ShouldBeTrue)
+
+	geometries, err := model.Geometries([]Input{0, 0, 10})
+	test.That(t, err, test.ShouldBeNil)
+	byLabel := map[string]spatialmath.Geometry{}
+	for _, geometry := range geometries.Geometries() {
+		byLabel[geometry.Label()] = geometry
+	}
+	test.That(t, len(byLabel), test.ShouldEqual, 3)
+	test.That(t, spatialmath.R3VectorAlmostEqual(byLabel["sdf_arm:base"].Pose().Point(), r3.Vector{Z: 50}, 1e-6), test.ShouldBeTrue)
+	capsule, err := spatialmath.NewGeometryConfig(byLabel["sdf_arm:upper_arm"])
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, capsule.Type, test.ShouldEqual, spatialmath.CapsuleType)
+	test.That(t, capsule.L, test.ShouldAlmostEqual, 300)
+	test.That(t, spatialmath.R3VectorAlmostEqual(byLabel["sdf_arm:upper_arm"].Pose().Point(), r3.Vector{Z: 250}, 1e-6), test.ShouldBeTrue)
+	test.That(t, spatialmath.R3VectorAlmostEqual(byLabel["sdf_arm:left_finger"].Pose().Point(), r3.Vector{X: 100, Y: 30, Z: 400}, 1e-6),
+		test.ShouldBeTrue)
+
+	model, err = KinematicModelFromFile(utils.ResolveFile("referenceframe/testfiles/sdf_arm.sdf"), "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, model.ModelConfig().OriginalFile.Extension, test.ShouldEqual, "sdf")
+}
+
+func TestUnmarshalSDF(t *testing.T) {
+	t.Run("world model", func(t *testing.T) {
+		// links are posed in the model frame, and joints in their child link frames
+		sdf := `<sdf version="1.7">
+			<world name="default">
+				<model name="gantry">
+					<link name="rail"/>
+					<link name="carriage"><pose>0.5 0 0 0 0 1.5707963267948966</pose></link>
+					<joint name="slide" type="prismatic">
+						<parent>rail</parent>
+						<child>carriage</child>
+						<axis><xyz>1 0 0</xyz><limit><lower>-0.1</lower><upper>0.2</upper></limit></axis>
+					</joint>
+					<joint name="pivot" type="universal">
+						<parent>rail</parent>
+						<child>carriage</child>
+					</joint>
+				</model>
+				<model name="other"/>
+			</world>
+		</sdf>`
+		cfg, unsupported, err := UnmarshalModelSDF([]byte(sdf), "", nil)
+		test.That(t, err, test.ShouldBeError, `link "carriage" is the child of more than one joint`)
+		test.That(t, cfg, test.ShouldBeNil)
+		test.That(t, unsupported, test.ShouldBeNil)
+
+		sdf = `<sdf version="1.7">
+			<world name="default">
+				<model name="gantry">
+					<link name="rail"/>
+					<link name="carriage"><pose>0.5 0 0 0 0 1.5707963267948966</pose></link>
+					<joint name="slide" type="prismatic">
+						<parent>rail</parent>
+						<child>carriage</child>
+						<axis><xyz>1 0 0</xyz><limit><lower>-0.1</lower><upper>0.2</upper></limit></axis>
+					</joint>
+				</model>
+				<model name="other"/>
+			</world>
+		</sdf>`
+		cfg, unsupported, err = UnmarshalModelSDF([]byte(sdf), "", nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, unsupported, test.ShouldResemble, []string{`model "other": only the first model is imported`})
+		model, err := cfg.ParseConfig("")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, model.Name(), test.ShouldEqual, "gantry")
+		test.That(t, model.DoF(), test.ShouldResemble, []Limit{{-100, 200}})
+
+		// the axis is in the yawed frame of the carriage, so the carriage slides along y
+		pose, err := model.Transform([]Input{100})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, spatialmath.R3VectorAlmostEqual(pose.Point(), r3.Vector{X: 500, Y: 100}, 1e-6), test.ShouldBeTrue)
+	})
+
+	t.Run("circular poses", func(t *testing.T) {
+		sdf := `<sdf version="1.8">
+			<model name="loop">
+				<link name="a"><pose relative_to="b"/></link>
+				<link name="b"><pose relative_to="a"/></link>
+			</model>
+		</sdf>`
+		_, _, err := UnmarshalModelSDF([]byte(sdf), "", nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, ErrCircularReference.Error())
+	})
+}
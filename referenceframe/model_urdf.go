@@ -6,7 +6,6 @@ import (
 	"math"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/golang/geo/r3"
 	"github.com/pkg/errors"
@@ -292,35 +291,11 @@ func buildMeshMapFromURDF(xmlData []byte, urdfDir string) (map[string]*commonpb.
 				continue
 			}
 
-			// Resolve path relative to URDF directory
-			var absolutePath string
-			if filepath.IsAbs(meshPath) {
-				absolutePath = meshPath
-			} else {
-				absolutePath = filepath.Join(urdfDir, meshPath)
-			}
-
-			// Load mesh file bytes
-			//nolint:gosec
-			meshBytes, err := os.ReadFile(absolutePath)
+			protoMesh, err := loadMeshFile(urdfDir, meshPath)
 			if err != nil {
-				return nil, fmt.Errorf("failed to load mesh file %s (referenced as %s): %w", absolutePath, originalPath, err)
-			}
-
-			// Determine mesh content type from file extension
-			var contentType string
-			if strings.HasSuffix(strings.ToLower(meshPath), ".ply") {
-				contentType = "ply"
-			} else if strings.HasSuffix(strings.ToLower(meshPath), ".stl") {
-				contentType = "stl"
-			} else {
-				return nil, fmt.Errorf("unsupported mesh file type (only .ply and .stl supported): %s", meshPath)
-			}
-
-			meshMap[meshPath] = &commonpb.Mesh{
-				Mesh:        meshBytes,
-				ContentType: contentType,
+				return nil, fmt.Errorf("%w (referenced as %s)", err, originalPath)
 			}
+			meshMap[meshPath] = protoMesh
 		}
 	}
 
@@ -5,12 +5,14 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/pkg/errors"
 	"go.viam.com/utils/trace"
 
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
@@ -42,6 +44,94 @@ type InputEnabled interface {
 	GoToInputs(context.Context, ...[]referenceframe.Input) error
 }
 
+// TimedInputEnabled is implemented by InputEnabled resources which can follow inputs at specific times, such as arms
+// which stream setpoints to their controllers. This allows smooth motion which is synchronized with other resources
+// rather than stopping at each set of inputs. The times of the inputs are relative to when the call is made.
+type TimedInputEnabled interface {
+	InputEnabled
+	GoToTimedInputs(ctx context.Context, inputs []motionplan.TimedInputs) error
+}
+
+// TimedInputsExtraKey is the key of the extra under which the motion service passes the timed inputs of a motion to an
+// arm which does not implement TimedInputEnabled, when it moves the arm through the inputs with
+// MoveThroughJointPositions. An arm which reads them with TimedInputsFromExtra can follow the motion as planned, while
+// any other arm moves through the inputs on its own timing.
+const TimedInputsExtraKey = "timed_inputs"
+
+// TimedInputsToExtra encodes timed inputs as a value of an extra, which can also be sent to remote resources. Times are
+// in seconds.
+func TimedInputsToExtra(inputs []motionplan.TimedInputs) []interface{} {
+	floats := func(values []float64) []interface{} {
+		encoded := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			encoded = append(encoded, v)
+		}
+		return encoded
+	}
+	encoded := make([]interface{}, 0, len(inputs))
+	for _, timed := range inputs {
+		encoded = append(encoded, map[string]interface{}{
+			"time_s":     timed.Time.Seconds(),
+			"inputs":     floats(timed.Inputs),
+			"velocities": floats(timed.Velocities),
+		})
+	}
+	return encoded
+}
+
+// TimedInputsFromExtra decodes the timed inputs in the extra under TimedInputsExtraKey, returning false if there are
+// none.
+func TimedInputsFromExtra(extra map[string]interface{}) ([]motionplan.TimedInputs, bool, error) {
+	val, ok := extra[TimedInputsExtraKey]
+	if !ok || val == nil {
+		return nil, false, nil
+	}
+	encoded, ok := val.([]interface{})
+	if !ok {
+		return nil, false, errors.Errorf("%s should be a list, got %T", TimedInputsExtraKey, val)
+	}
+	floats := func(val interface{}) ([]float64, error) {
+		list, ok := val.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected a list of numbers, got %T", val)
+		}
+		values := make([]float64, 0, len(list))
+		for _, v := range list {
+			f, ok := v.(float64)
+			if !ok {
+				return nil, errors.Errorf("expected a number, got %T", v)
+			}
+			values = append(values, f)
+		}
+		return values, nil
+	}
+	inputs := make([]motionplan.TimedInputs, 0, len(encoded))
+	for i, e := range encoded {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, false, errors.Errorf("timed inputs %d should be a map, got %T", i, e)
+		}
+		seconds, ok := m["time_s"].(float64)
+		if !ok {
+			return nil, false, errors.Errorf("timed inputs %d has no time_s", i)
+		}
+		values, err := floats(m["inputs"])
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "inputs of timed inputs %d", i)
+		}
+		velocities, err := floats(m["velocities"])
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "velocities of timed inputs %d", i)
+		}
+		inputs = append(inputs, motionplan.TimedInputs{
+			Time:       time.Duration(seconds * float64(time.Second)),
+			Inputs:     values,
+			Velocities: velocities,
+		})
+	}
+	return inputs, true, nil
+}
+
 // Service is an interface that wraps a RobotFrameSystem in a Resource.
 type Service interface {
 	resource.Resource
@@ -4,16 +4,20 @@ import (
 	"context"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/golang/geo/r3"
 	"github.com/pkg/errors"
 	"go.viam.com/test"
+	"go.viam.com/utils/protoutils"
 
 	_ "go.viam.com/rdk/components/arm/fake"
 	_ "go.viam.com/rdk/components/gripper/fake"
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/robot/framesystem"
 	robotimpl "go.viam.com/rdk/robot/impl"
 	"go.viam.com/rdk/spatialmath"
 	rdkutils "go.viam.com/rdk/utils"
@@ -272,3 +276,26 @@ func TestNewFrameSystemFromBadConfig(t *testing.T) {
 		test.That(t, fs, test.ShouldBeNil)
 	})
 }
+
+func TestTimedInputsExtra(t *testing.T) {
+	inputs := []motionplan.TimedInputs{
+		{Time: 0, Inputs: []referenceframe.Input{0, 1}, Velocities: []float64{0, 0}},
+		{Time: 250 * time.Millisecond, Inputs: []referenceframe.Input{0.5, 1.5}, Velocities: []float64{1, -2}},
+	}
+	extra := map[string]interface{}{framesystem.TimedInputsExtraKey: framesystem.TimedInputsToExtra(inputs)}
+	// extras sent to remote resources go through a protobuf struct
+	pbExtra, err := protoutils.StructToStructPb(extra)
+	test.That(t, err, test.ShouldBeNil)
+	for _, e := range []map[string]interface{}{extra, pbExtra.AsMap()} {
+		decoded, ok, err := framesystem.TimedInputsFromExtra(e)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, decoded, test.ShouldResemble, inputs)
+	}
+
+	_, ok, err := framesystem.TimedInputsFromExtra(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeFalse)
+	_, _, err = framesystem.TimedInputsFromExtra(map[string]interface{}{framesystem.TimedInputsExtraKey: "now"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -1770,6 +1770,234 @@ func TestJobManagerServices(t *testing.T) {
 	})
 }
 
+func TestJobManagerRetriesAndTimeouts(t *testing.T) {
+	t.Parallel()
+	logger := logging.NewTestLogger(t)
+
+	var flakyCalls, hangingCalls, overlappingCalls atomic.Int32
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	injectSensor := inject.NewSensor("sensor")
+	injectSensor.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		switch cmd["command"] {
+		case "flaky":
+			// every run fails twice before succeeding
+			if flakyCalls.Add(1)%3 != 0 {
+				return nil, errors.New("flaky failure")
+			}
+			return map[string]interface{}{"ok": true}, nil
+		case "hang":
+			// ignores the context, like a misbehaving module would
+			hangingCalls.Add(1)
+		case "overlap":
+			overlappingCalls.Add(1)
+		}
+		<-unblock
+		return nil, nil
+	}
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			return injectSensor, nil
+		}})
+
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{
+				Model: model,
+				Name:  "sensor",
+				API:   sensor.API,
+			},
+		},
+		Jobs: []config.JobConfig{
+			{
+				config.JobConfigData{
+					Name:         "flaky",
+					Schedule:     "300ms",
+					Resource:     "sensor",
+					Method:       "DoCommand",
+					Command:      map[string]any{"command": "flaky"},
+					MaxRetries:   2,
+					RetryBackoff: "10ms",
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:     "hanging",
+					Schedule: "200ms",
+					Resource: "sensor",
+					Method:   "DoCommand",
+					Command:  map[string]any{"command": "hang"},
+					Timeout:  "100ms",
+				},
+			},
+			{
+				config.JobConfigData{
+					Name:          "overlapping",
+					Schedule:      "100ms",
+					Resource:      "sensor",
+					Method:        "DoCommand",
+					Command:       map[string]any{"command": "overlap"},
+					OverlapPolicy: config.JobOverlapPolicyAllow,
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	lr := setupLocalRobot(t, ctx, cfg, logger)
+
+	testutils.WaitForAssertionWithSleep(t, 100*time.Millisecond, 50, func(tb testing.TB) {
+		tb.Helper()
+		ms, err := lr.MachineStatus(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+
+		flaky := ms.JobStatuses["flaky"]
+		test.That(tb, len(flaky.Runs), test.ShouldBeGreaterThanOrEqualTo, 1)
+		if len(flaky.Runs) == 0 {
+			return
+		}
+		test.That(tb, flaky.Runs[0].Attempts, test.ShouldEqual, 3)
+		test.That(tb, flaky.Runs[0].Error, test.ShouldBeEmpty)
+		test.That(tb, flaky.Runs[0].Response, test.ShouldEqual, `{"ok":true}`)
+		test.That(tb, flaky.Runs[0].End, test.ShouldHappenAfter, flaky.Runs[0].Start)
+		test.That(tb, len(flaky.RecentFailedRuns), test.ShouldEqual, 0)
+
+		// a run which never returns times out rather than blocking every later run
+		hanging := ms.JobStatuses["hanging"]
+		test.That(tb, len(hanging.Runs), test.ShouldBeGreaterThanOrEqualTo, 2)
+		if len(hanging.Runs) == 0 {
+			return
+		}
+		test.That(tb, hanging.Runs[0].Error, test.ShouldEqual, "job timed out after 100ms")
+		test.That(tb, hangingCalls.Load(), test.ShouldBeGreaterThanOrEqualTo, 2)
+
+		// runs of the overlapping job start even though none have finished
+		test.That(tb, overlappingCalls.Load(), test.ShouldBeGreaterThanOrEqualTo, 2)
+		test.That(tb, len(ms.JobStatuses["overlapping"].Runs), test.ShouldEqual, 0)
+	})
+}
+
+func TestJobManagerChainingAndConditions(t *testing.T) {
+	t.Parallel()
+	logger := logging.NewTestLogger(t)
+
+	var sourceFails atomic.Bool
+	var batteryLevel atomic.Int32
+	batteryLevel.Store(50)
+	var onSuccessCalls, onFailureCalls, lowBatteryCalls, loopCalls atomic.Int32
+
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	injectSensor := inject.NewSensor("sensor")
+	injectSensor.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		switch cmd["command"] {
+		case "source":
+			if sourceFails.Load() {
+				return nil, errors.New("source failure")
+			}
+		case "on_success":
+			onSuccessCalls.Add(1)
+		case "on_failure":
+			onFailureCalls.Add(1)
+		case "low_battery":
+			lowBatteryCalls.Add(1)
+		case "loop_a", "loop_b":
+			loopCalls.Add(1)
+		}
+		return map[string]interface{}{}, nil
+	}
+	injectSensor.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"battery": map[string]interface{}{"level": batteryLevel.Load()}}, nil
+	}
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			return injectSensor, nil
+		}})
+
+	doCommandJob := func(name string, jc config.JobConfigData) config.JobConfig {
+		jc.Name = name
+		jc.Resource = "sensor"
+		jc.Method = "DoCommand"
+		jc.Command = map[string]any{"command": name}
+		return config.JobConfig{jc}
+	}
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{
+				Model: model,
+				Name:  "sensor",
+				API:   sensor.API,
+			},
+		},
+		Jobs: []config.JobConfig{
+			doCommandJob("source", config.JobConfigData{Schedule: "100ms"}),
+			doCommandJob("on_success", config.JobConfigData{After: []config.JobDependency{{Job: "source"}}}),
+			doCommandJob("on_failure", config.JobConfigData{
+				After: []config.JobDependency{{Job: "source", On: config.JobTriggerFailure}},
+			}),
+			// jobs which run after each other in a cycle are never triggered
+			doCommandJob("loop_a", config.JobConfigData{After: []config.JobDependency{{Job: "source"}, {Job: "loop_b"}}}),
+			doCommandJob("loop_b", config.JobConfigData{After: []config.JobDependency{{Job: "loop_a"}}}),
+			doCommandJob("low_battery", config.JobConfigData{
+				Schedule:  "50ms",
+				Condition: &config.JobCondition{Resource: "sensor", Field: "battery.level", Operator: "<", Value: 20, Crossing: true},
+			}),
+		},
+	}
+	ctx := context.Background()
+	lr := setupLocalRobot(t, ctx, cfg, logger)
+
+	// only the jobs which run after the source succeeds are triggered
+	testutils.WaitForAssertionWithSleep(t, 100*time.Millisecond, 50, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, onSuccessCalls.Load(), test.ShouldBeGreaterThanOrEqualTo, 2)
+		ms, err := lr.MachineStatus(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, len(ms.JobStatuses["on_success"].Runs), test.ShouldBeGreaterThanOrEqualTo, 2)
+	})
+	test.That(t, onFailureCalls.Load(), test.ShouldEqual, 0)
+	test.That(t, loopCalls.Load(), test.ShouldEqual, 0)
+	// the condition is checked on every scheduled run, but is never met
+	test.That(t, lowBatteryCalls.Load(), test.ShouldEqual, 0)
+
+	sourceFails.Store(true)
+	testutils.WaitForAssertionWithSleep(t, 100*time.Millisecond, 50, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, onFailureCalls.Load(), test.ShouldBeGreaterThanOrEqualTo, 2)
+	})
+
+	// a crossing condition only runs the job once per crossing of the threshold
+	batteryLevel.Store(10)
+	testutils.WaitForAssertionWithSleep(t, 100*time.Millisecond, 50, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, lowBatteryCalls.Load(), test.ShouldEqual, 1)
+	})
+	time.Sleep(300 * time.Millisecond)
+	test.That(t, lowBatteryCalls.Load(), test.ShouldEqual, 1)
+
+	batteryLevel.Store(50)
+	time.Sleep(200 * time.Millisecond)
+	batteryLevel.Store(10)
+	testutils.WaitForAssertionWithSleep(t, 100*time.Millisecond, 50, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, lowBatteryCalls.Load(), test.ShouldEqual, 2)
+	})
+}
+
 func TestJobManagerErrors(t *testing.T) {
 	t.Parallel()
 	logger := logging.NewTestLogger(t)
@@ -1982,10 +1982,16 @@ func (r *localRobot) MachineStatus(ctx context.Context) (robot.MachineStatus, er
 				result.JobStatuses = make(map[string]robot.JobStatus)
 			}
 			for jobName, jobHistory := range r.jobManager.JobHistories.Range {
-				result.JobStatuses[jobName] = robot.JobStatus{
+				runs := jobHistory.Runs()
+				jobStatus := robot.JobStatus{
 					RecentSuccessfulRuns: jobHistory.Successes(),
 					RecentFailedRuns:     jobHistory.Failures(),
+					Runs:                 make([]robot.JobRun, 0, len(runs)),
 				}
+				for _, run := range runs {
+					jobStatus.Runs = append(jobStatus.Runs, robot.JobRun(run))
+				}
+				result.JobStatuses[jobName] = jobStatus
 			}
 		}
 	}
@@ -7,6 +7,8 @@ import (
 	"container/ring"
 	"context"
 	"encoding/json"
+	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -38,15 +40,27 @@ const (
 	// the job manager will be looking for.
 	componentServiceIndex int = 2
 	historyLength         int = 10
+	// runHistoryLength is how many run records are kept for each job.
+	runHistoryLength int = 50
+	// responseSummaryLength is the longest summary of a job's response which is recorded for a run.
+	responseSummaryLength int = 256
+	// maxRetryBackoff caps the exponentially growing wait between retries of a failed job.
+	maxRetryBackoff = time.Minute
+	// dependentJobInterval is the schedule of jobs which only run after other jobs, which is long enough that they are
+	// never run by the scheduler on its own.
+	dependentJobInterval = 100 * 365 * 24 * time.Hour
 )
 
 // JobManager keeps track of the currently scheduled jobs and updates the schedule with
 // respect to the "jobs" part of the config.
 type JobManager struct {
-	scheduler     gocron.Scheduler
-	logger        logging.Logger
-	getResource   func(resource string) (resource.Resource, error)
+	scheduler   gocron.Scheduler
+	logger      logging.Logger
+	getResource func(resource string) (resource.Resource, error)
+	// jobsMu guards namesToJobIDs and jobConfigs, which running jobs look up to trigger their dependents.
+	jobsMu        sync.Mutex
 	namesToJobIDs map[string]uuid.UUID
+	jobConfigs    map[string]config.JobConfig
 	ctx           context.Context
 	conn          rpc.ClientConn
 	isClosed      bool
@@ -62,6 +76,32 @@ type JobHistory struct {
 	successTimes   *ring.Ring
 	failureTimesMu sync.Mutex
 	failureTimes   *ring.Ring
+	runsMu         sync.Mutex
+	runs           *ring.Ring
+}
+
+// JobRun records a single run of a job, including all of its retries.
+type JobRun struct {
+	Start    time.Time
+	End      time.Time
+	Attempts int
+	// Error is the error of the last attempt, or empty if the run succeeded.
+	Error string
+	// Response is a JSON summary of the response of the successful attempt, truncated to responseSummaryLength.
+	Response string
+}
+
+// Duration returns how long the run took, including the time spent waiting between retries.
+func (r JobRun) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+func newJobHistory() *JobHistory {
+	return &JobHistory{
+		successTimes: ring.New(historyLength),
+		failureTimes: ring.New(historyLength),
+		runs:         ring.New(runHistoryLength),
+	}
 }
 
 // Successes returns timestamps of the last historyLength number successfully completed jobs.
@@ -108,6 +148,28 @@ func (jh *JobHistory) AddFailure(time time.Time) {
 	jh.failureTimes = jh.failureTimes.Next()
 }
 
+// Runs returns the records of the last runHistoryLength runs, from oldest to newest.
+func (jh *JobHistory) Runs() []JobRun {
+	runs := make([]JobRun, 0, runHistoryLength)
+	jh.runsMu.Lock()
+	defer jh.runsMu.Unlock()
+	for i := 0; i < runHistoryLength; i++ {
+		if jh.runs.Value != nil {
+			runs = append(runs, jh.runs.Value.(JobRun))
+		}
+		jh.runs = jh.runs.Next()
+	}
+	return runs
+}
+
+// AddRun adds a run record to runs, overwriting the earliest entry if it is full.
+func (jh *JobHistory) AddRun(run JobRun) {
+	jh.runsMu.Lock()
+	defer jh.runsMu.Unlock()
+	jh.runs.Value = run
+	jh.runs = jh.runs.Next()
+}
+
 // New sets up the context and grpcConn that is used in scheduled jobs. The actual
 // scheduler is initialized and automatically started. Any jobs added to the config will
 // then immediately get scheduled according to their "Schedule" field.
@@ -143,6 +205,7 @@ func New(
 		scheduler:     scheduler,
 		getResource:   getResource,
 		namesToJobIDs: make(map[string]uuid.UUID),
+		jobConfigs:    make(map[string]config.JobConfig),
 		ctx:           robotContext,
 		conn:          conn,
 	}
@@ -211,29 +274,30 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 	// deduplication for job loggers.
 	jobLogger.NeverDeduplicate()
 
-	// using jm.ctx so we interrupt only if JM is shutting down. When changing schedule, let existing jobs complete instead of interrupting.
-	jobFunc := func(_ context.Context) error {
+	// ctx is derived from jm.ctx so we interrupt only if JM is shutting down or the attempt times out. When changing
+	// schedule, let existing jobs complete instead of interrupting.
+	jobFunc := func(ctx context.Context) (map[string]any, error) {
 		res, err := jm.getResource(jc.Resource)
 		if err != nil {
 			jobLogger.CWarnw(jm.ctx, "Could not get resource", "error", err.Error())
-			return err
+			return nil, err
 		}
 		if jc.Method == "DoCommand" {
 			jobLogger.CDebugw(jm.ctx, "Job triggered", "name", jc.Name)
 			// unlike below InvokeRPC, if DoCommand panics there is no recover
-			response, err := res.DoCommand(jm.ctx, jc.Command)
+			response, err := res.DoCommand(ctx, jc.Command)
 			if err != nil {
 				jobLogger.CWarnw(jm.ctx, "Job failed", "error", err.Error())
-				return err
+				return nil, err
 			}
 			jobLogger.CDebugw(jm.ctx, "Job succeeded", "name", jc.Name, "response", response)
-			return nil
+			return response, nil
 		}
 
 		descSource, grpcService, grpcMethod, err := jm.createDescriptorSourceAndgRPCMethod(res, jc.Method)
 		if err != nil {
 			jobLogger.CWarnw(jm.ctx, "grpc setup failed", "error", err)
-			return err
+			return nil, err
 		}
 
 		gRPCArgument := resource.GetResourceNameOverride(grpcService, grpcMethod)
@@ -243,7 +307,7 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 		argumentBytes, err := json.Marshal(argumentMap)
 		if err != nil {
 			jobLogger.CWarnw(jm.ctx, "could not serialize gRPC method arguments", "error", err.Error())
-			return err
+			return nil, err
 		}
 		options := grpcurl.FormatOptions{
 			EmitJSONDefaultFields: true,
@@ -257,7 +321,7 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 			options)
 		if err != nil {
 			jobLogger.CWarnw(jm.ctx, "could not create parser and formatter for grpc requests", "error", err.Error())
-			return err
+			return nil, err
 		}
 
 		buffer := bytes.NewBuffer(make([]byte, 0))
@@ -268,24 +332,132 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 		}
 		jobLogger.CDebugw(jm.ctx, "Job triggered", "name", jc.Name)
 		grpcMethodCombined := grpcService + "." + grpcMethod
-		err = grpcurl.InvokeRPC(jm.ctx, descSource, jm.conn, grpcMethodCombined, nil, h, rf.Next)
+		err = grpcurl.InvokeRPC(ctx, descSource, jm.conn, grpcMethodCombined, nil, h, rf.Next)
 		if err != nil {
 			jobLogger.CWarnw(jm.ctx, "Job failed", "name", jc.Name, "error", err.Error())
-			return err
+			return nil, err
 		} else if h.Status != nil && h.Status.Err() != nil {
 			// if job panics, it seems to be captured here.
 			jobLogger.CWarnw(jm.ctx, "Job failed", "name", jc.Name, "error", h.Status.Err())
-			return h.Status.Err()
+			return nil, h.Status.Err()
 		}
 		response := map[string]any{}
 		err = json.Unmarshal(buffer.Bytes(), &response)
 		if err != nil {
 			jobLogger.CWarnw(jm.ctx, "Unmarshalling grpc response failed with error", "name", jc.Name,
 				"error", err.Error())
-			return err
+			return nil, err
 		}
 		jobLogger.CDebugw(jm.ctx, "Job succeeded", "name", jc.Name, "response", response)
-		return nil
+		return response, nil
+	}
+
+	// attemptFunc runs the job once, giving up on it if it does not finish within the timeout. A job which ignores the
+	// cancellation of its context is left to finish in the background so that it no longer blocks its schedule.
+	timeout := jc.TimeoutDuration()
+	attemptFunc := func() (map[string]any, error) {
+		if timeout == 0 {
+			return jobFunc(jm.ctx)
+		}
+		attemptCtx, cancel := context.WithTimeout(jm.ctx, timeout)
+		defer cancel()
+		type attemptResult struct {
+			response map[string]any
+			err      error
+		}
+		resultCh := make(chan attemptResult, 1)
+		go func() {
+			response, err := jobFunc(attemptCtx)
+			resultCh <- attemptResult{response, err}
+		}()
+		select {
+		case result := <-resultCh:
+			return result.response, result.err
+		case <-attemptCtx.Done():
+			if jm.ctx.Err() != nil {
+				return nil, jm.ctx.Err()
+			}
+			jobLogger.CWarnw(jm.ctx, "Job timed out", "name", jc.Name, "timeout", jc.Timeout)
+			return nil, errors.Errorf("job timed out after %v", timeout)
+		}
+	}
+
+	// conditionFunc returns whether the condition of the job is met. A crossing condition is only met when the
+	// comparison was not true the previous time the condition was checked.
+	var conditionMu sync.Mutex
+	var conditionWasMet bool
+	conditionFunc := func() (bool, error) {
+		met, err := jm.evaluateCondition(jc.Condition)
+		if err != nil {
+			return false, err
+		}
+		conditionMu.Lock()
+		defer conditionMu.Unlock()
+		crossed := met && !conditionWasMet
+		conditionWasMet = met
+		if jc.Condition.Crossing {
+			return crossed, nil
+		}
+		return met, nil
+	}
+
+	// retryFunc attempts the job until it succeeds or runs out of retries, backing off exponentially between attempts.
+	retryFunc := func(ctx context.Context, run *JobRun) (map[string]any, error) {
+		backoff := jc.RetryBackoffDuration()
+		for {
+			run.Attempts++
+			response, err := attemptFunc()
+			if err == nil || run.Attempts > jc.MaxRetries {
+				return response, err
+			}
+			jobLogger.CInfow(jm.ctx, "Retrying job", "name", jc.Name, "attempt", run.Attempts, "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return nil, err
+			case <-jm.ctx.Done():
+				return nil, err
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, maxRetryBackoff)
+		}
+	}
+
+	// runFunc runs the job if its condition is met, records the run and triggers the jobs which depend on it.
+	runFunc := func(ctx context.Context) error {
+		run := JobRun{Start: time.Now()}
+		var response map[string]any
+		var err error
+		if jc.Condition != nil {
+			var met bool
+			if met, err = conditionFunc(); err != nil {
+				// a condition which cannot be checked fails the run without attempting the job
+				jobLogger.CWarnw(jm.ctx, "Could not check the condition of the job", "name", jc.Name, "error", err.Error())
+			} else if !met {
+				jobLogger.CDebugw(jm.ctx, "Job skipped since its condition is not met", "name", jc.Name)
+				return nil
+			}
+		}
+		if err == nil {
+			response, err = retryFunc(ctx, &run)
+		}
+
+		run.End = time.Now()
+		if err != nil {
+			run.Error = err.Error()
+		} else {
+			run.Response = summarizeResponse(response)
+		}
+		if jh, ok := jm.JobHistories.Load(jc.Name); ok {
+			if err != nil {
+				// this includes captured panics (from InvokeRPC).
+				jh.AddFailure(run.End)
+			} else {
+				jh.AddSuccess(run.End)
+			}
+			jh.AddRun(run)
+		}
+		jm.triggerDependents(jc.Name, err == nil)
+		return err
 	}
 
 	return func(ctx context.Context) error {
@@ -300,16 +472,7 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 				return err
 			default:
 			}
-			err = jobFunc(ctx)
-			now := time.Now()
-			if jh, ok := jm.JobHistories.Load(jc.Name); ok {
-				if err != nil {
-					// this includes captured panics (from InvokeRPC).
-					jh.AddFailure(now)
-				} else {
-					jh.AddSuccess(now)
-				}
-			}
+			err = runFunc(ctx)
 			if !continuous {
 				return err
 			}
@@ -317,8 +480,25 @@ func (jm *JobManager) createJobFunction(jc config.JobConfig, continuous bool) fu
 	}
 }
 
+// summarizeResponse returns the response as JSON, truncated to responseSummaryLength.
+func summarizeResponse(response map[string]any) string {
+	if len(response) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Sprintf("%v", response)
+	}
+	if len(data) <= responseSummaryLength {
+		return string(data)
+	}
+	return strings.ToValidUTF8(string(data[:responseSummaryLength]), "") + "..."
+}
+
 // removeJob removes the job from the scheduler and clears the internal map entry.
 func (jm *JobManager) removeJob(name string, verbose bool) {
+	jm.jobsMu.Lock()
+	defer jm.jobsMu.Unlock()
 	jobID := jm.namesToJobIDs[name]
 	if verbose {
 		jm.logger.CInfow(jm.ctx, "Removing job", "name", name)
@@ -328,6 +508,19 @@ func (jm *JobManager) removeJob(name string, verbose bool) {
 		jm.logger.CWarnw(jm.ctx, "Removing the job failed", "error", err.Error())
 	}
 	delete(jm.namesToJobIDs, name)
+	delete(jm.jobConfigs, name)
+}
+
+// overlapOptions returns the scheduler options which implement the overlap policy of a job.
+func overlapOptions(policy config.JobOverlapPolicy) []gocron.JobOption {
+	switch policy {
+	case config.JobOverlapPolicySkip:
+		return []gocron.JobOption{gocron.WithSingletonMode(gocron.LimitModeReschedule)}
+	case config.JobOverlapPolicyQueue:
+		return []gocron.JobOption{gocron.WithSingletonMode(gocron.LimitModeWait)}
+	default:
+		return nil
+	}
 }
 
 // scheduleJob validates the job config and attempts to put a new job on the scheduler
@@ -341,7 +534,15 @@ func (jm *JobManager) scheduleJob(jc config.JobConfig, verbose bool) {
 	var continuous bool
 	var jobDefinition gocron.JobDefinition
 	var jobOptions []gocron.JobOption
-	if strings.ToLower(jc.Schedule) == "continuous" {
+	if jc.Schedule == "" {
+		// the job only runs when triggered by the jobs it depends on.
+		jobDefinition = gocron.DurationJob(dependentJobInterval)
+		overlapPolicy := config.JobOverlapPolicyQueue
+		if jc.OverlapPolicy != "" {
+			overlapPolicy = jc.OverlapPolicy
+		}
+		jobOptions = append(jobOptions, overlapOptions(overlapPolicy)...)
+	} else if strings.ToLower(jc.Schedule) == "continuous" {
 		continuous = true
 		// used with WithIntervalFromCompletion: if job unexpectedly exits, try to restart later.
 		// since we capture panics, this is largely unused, but helps reduce scheduler overhead.
@@ -381,15 +582,26 @@ func (jm *JobManager) scheduleJob(jc config.JobConfig, verbose bool) {
 
 		// It is also important to note that DURATION jobs start relative to when they were
 		// queued on the job scheduler, while CRON jobs are tied to the physical clock.
+
+		// The overlap_policy of a job overrides this default behavior: "skip" reschedules like
+		// a CRON job, "queue" waits like a DURATION job and "allow" runs concurrently.
 		t, err := time.ParseDuration(jc.Schedule)
 		if err != nil {
 			// TODO(RSDK-12757): exit if cron job is also invalid. Currently it's stored as an invalid string and validated at NewJob call.
 			withSeconds := len(strings.Split(jc.Schedule, " ")) >= 6
 			jobDefinition = gocron.CronJob(jc.Schedule, withSeconds)
-			jobOptions = append(jobOptions, gocron.WithSingletonMode(gocron.LimitModeReschedule))
+			overlapPolicy := config.JobOverlapPolicySkip
+			if jc.OverlapPolicy != "" {
+				overlapPolicy = jc.OverlapPolicy
+			}
+			jobOptions = append(jobOptions, overlapOptions(overlapPolicy)...)
 		} else {
 			jobDefinition = gocron.DurationJob(t)
-			jobOptions = append(jobOptions, gocron.WithSingletonMode(gocron.LimitModeWait))
+			overlapPolicy := config.JobOverlapPolicyQueue
+			if jc.OverlapPolicy != "" {
+				overlapPolicy = jc.OverlapPolicy
+			}
+			jobOptions = append(jobOptions, overlapOptions(overlapPolicy)...)
 		}
 	}
 
@@ -400,10 +612,7 @@ func (jm *JobManager) scheduleJob(jc config.JobConfig, verbose bool) {
 	jobLogger := jm.logger.Sublogger(jc.Name)
 
 	if _, ok := jm.JobHistories.Load(jc.Name); !ok {
-		jm.JobHistories.Store(jc.Name, &JobHistory{
-			successTimes: ring.New(historyLength),
-			failureTimes: ring.New(historyLength),
-		})
+		jm.JobHistories.Store(jc.Name, newJobHistory())
 		jm.NumJobHistories.Add(1)
 	}
 
@@ -423,7 +632,69 @@ func (jm *JobManager) scheduleJob(jc config.JobConfig, verbose bool) {
 		jobLogger.CInfow(jm.ctx, "Job created", "name", jc.Name)
 	}
 
+	jm.jobsMu.Lock()
+	defer jm.jobsMu.Unlock()
 	jm.namesToJobIDs[jc.Name] = jobID
+	jm.jobConfigs[jc.Name] = jc
+}
+
+// triggerDependents runs every job which runs after the named job finishes with the given outcome. Jobs which run
+// after each other in a cycle are never triggered, as they would trigger each other forever.
+func (jm *JobManager) triggerDependents(name string, succeeded bool) {
+	jm.jobsMu.Lock()
+	jobs := make([]config.JobConfig, 0, len(jm.jobConfigs))
+	for _, jc := range jm.jobConfigs {
+		jobs = append(jobs, jc)
+	}
+	inCycles := config.JobsInDependencyCycles(jobs)
+	dependents := map[uuid.UUID]string{}
+	for dependentName, dependent := range jm.jobConfigs {
+		if slices.Contains(inCycles, dependentName) {
+			continue
+		}
+		for _, dep := range dependent.After {
+			if dep.Job == name && dep.Triggers(succeeded) {
+				dependents[jm.namesToJobIDs[dependentName]] = dependentName
+				break
+			}
+		}
+	}
+	jm.jobsMu.Unlock()
+	if len(dependents) == 0 {
+		return
+	}
+
+	for _, j := range jm.scheduler.Jobs() {
+		dependentName, ok := dependents[j.ID()]
+		if !ok {
+			continue
+		}
+		jm.logger.CDebugw(jm.ctx, "Triggering dependent job", "name", dependentName, "after", name)
+		if err := j.RunNow(); err != nil {
+			jm.logger.CWarnw(jm.ctx, "Could not trigger dependent job", "name", dependentName, "after", name, "error", err.Error())
+		}
+	}
+}
+
+// evaluateCondition returns whether the current reading of the condition's resource satisfies the comparison.
+func (jm *JobManager) evaluateCondition(cond *config.JobCondition) (bool, error) {
+	res, err := jm.getResource(cond.Resource)
+	if err != nil {
+		return false, err
+	}
+	sensor, ok := res.(resource.Sensor)
+	if !ok {
+		return false, errors.Errorf("resource %s does not provide readings", cond.Resource)
+	}
+	readings, err := sensor.Readings(jm.ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	reading, err := rutils.ReadingValue(readings, cond.Field)
+	if err != nil {
+		return false, err
+	}
+	return cond.Compare(reading), nil
 }
 
 // UpdateJobs is called when the "jobs" part of the config gets updated. It updates
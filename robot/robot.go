@@ -358,6 +358,21 @@ type MachineStatus struct {
 type JobStatus struct {
 	RecentSuccessfulRuns []time.Time
 	RecentFailedRuns     []time.Time
+	// Runs are the records of the most recent runs of the job, from oldest to newest. They are only reported by
+	// machines running in the same process, as they are not part of the machine status API. Remote clients can get
+	// them from a jobs generic service, see services/generic/jobs.
+	Runs []JobRun
+}
+
+// JobRun records a single run of a JobManager job, including all of its retries.
+type JobRun struct {
+	Start    time.Time
+	End      time.Time
+	Attempts int
+	// Error is the error of the last attempt, or empty if the run succeeded.
+	Error string
+	// Response is a truncated JSON summary of the response of the successful attempt.
+	Response string
 }
 
 // VersionResponse encapsulates the version info of the robot.
@@ -25,6 +25,7 @@ import (
 	"google.golang.org/grpc"
 
 	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/internal/cloud"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
@@ -90,6 +91,9 @@ type builtIn struct {
 	diskSummaryTracker *diskSummaryTracker
 
 	captureControlPoller *goutils.StoppableWorkers
+	triggerPoller        *goutils.StoppableWorkers
+	// captureDir is the capture directory which the query command reads
+	captureDir string
 }
 
 // New returns a new builtin data manager service for the given robot.
@@ -137,6 +141,7 @@ func (b *builtIn) Close(ctx context.Context) error {
 	defer b.logger.Info("Close END")
 
 	b.stopCaptureControlPoller()
+	b.stopTriggerPoller()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.diskSummaryTracker.close()
@@ -159,6 +164,18 @@ func (b *builtIn) Sync(ctx context.Context, extra map[string]interface{}) error
 	return b.sync.Sync(ctx, extra)
 }
 
+// DoCommand supports the "trigger" command, which triggers collectors in trigger mode, and the "query" command, which
+// queries the captures in the capture directory.
+func (b *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if raw, ok := cmd[triggerCommand]; ok {
+		return b.doTrigger(raw)
+	}
+	if raw, ok := cmd[queryCommand]; ok {
+		return b.doQuery(raw)
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
 // Reconfigure updates the data manager service when the config has changed.
 // At time of writing Reconfigure only returns an error in one of the following unrecoverable error cases:
 //  1. There is some static (aka compile time) error which we currently are only able to detected at runtime:
@@ -197,6 +214,13 @@ func (b *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies, c
 	}
 
 	captureConfig := c.captureConfig(b.logger)
+	if captureConfig.EncryptionKey, err = c.CaptureEncryption.captureKey(); err != nil {
+		return err
+	}
+	if captureConfig.EncryptionKey != nil {
+		// register the key so that sync and queries decrypt the files it encrypted
+		data.RegisterCaptureKey(captureConfig.EncryptionKey)
+	}
 	collectorConfigsByResource, err := lookupCollectorConfigsByResource(deps, conf, captureConfig.CaptureDir, b.logger)
 	if err != nil {
 		// If this error occurs it's a resource graph error
@@ -210,8 +234,10 @@ func (b *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies, c
 	syncConfig := c.syncConfig(syncSensor, syncSensorEnabled, b.logger)
 
 	controlSensor, controlSensorKey := captureControlSensorFromDeps(c.CaptureControlSensor, deps, b.logger)
+	triggers := captureTriggersFromDeps(collectorConfigsByResource, deps, b.logger)
 
 	b.stopCaptureControlPoller()
+	b.stopTriggerPoller()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -224,10 +250,14 @@ func (b *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies, c
 	b.diskSummaryTracker.reconfigure(syncConfig.SyncPaths(), syncConfig.SyncIntervalMins, shouldSync)
 	b.capture.Reconfigure(ctx, collectorConfigsByResource, captureConfig)
 	b.sync.Reconfigure(ctx, syncConfig, cloudConnSvc)
+	b.captureDir = captureConfig.CaptureDir
 
 	if controlSensor != nil && !captureConfig.CaptureDisabled {
 		b.startCaptureControlPoller(controlSensor, controlSensorKey)
 	}
+	if len(triggers) > 0 && !captureConfig.CaptureDisabled {
+		b.startTriggerPoller(triggers)
+	}
 
 	return nil
 }
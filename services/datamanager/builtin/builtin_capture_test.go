@@ -2,7 +2,9 @@ package builtin
 
 import (
 	"context"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -348,3 +350,47 @@ func waitForCaptureFilesToExceedNFiles(captureDir string, n int, logger logging.
 		}
 	}
 }
+
+func TestCaptureEncryption(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	captureDir := t.TempDir()
+	keyFile := filepath.Join(t.TempDir(), "capture.key")
+	test.That(t, os.WriteFile(keyFile, []byte(strings.Repeat("ab", 32)), 0o600), test.ShouldBeNil)
+
+	r := setupRobot(nil, map[resource.Name]resource.Resource{
+		arm.Named("arm1"): &inject.Arm{
+			EndPositionFunc: func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+				return spatialmath.NewPoseFromPoint(r3.Vector{X: 1, Y: 2, Z: 3}), nil
+			},
+		},
+	})
+	config, deps := setupConfig(t, r, enabledTabularCollectorConfigPath)
+	c := config.ConvertedAttributes.(*Config)
+	c.CaptureDir = captureDir
+	c.ScheduledSyncDisabled = true
+	c.CaptureEncryption = &CaptureEncryptionConfig{KeyFile: keyFile}
+
+	b, err := New(ctx, deps, config, datasync.NoOpCloudClientConstructor, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() { test.That(t, b.Close(ctx), test.ShouldBeNil) }()
+	time.Sleep(100 * time.Millisecond)
+	b.(*builtIn).capture.FlushCollectors()
+
+	paths := getAllFilePaths(captureDir)
+	test.That(t, paths, test.ShouldNotBeEmpty)
+	//nolint:gosec
+	f, err := os.Open(paths[0])
+	test.That(t, err, test.ShouldBeNil)
+	defer func() { test.That(t, f.Close(), test.ShouldBeNil) }()
+	captureFile, err := data.ReadCaptureFile(f)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, captureFile.Encrypted(), test.ShouldBeTrue)
+	// the key was registered by the data manager, so the captures decrypt
+	sensorData, err := data.SensorDataFromCaptureFile(captureFile)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sensorData, test.ShouldNotBeEmpty)
+
+	c.CaptureEncryption = &CaptureEncryptionConfig{KeyFile: filepath.Join(t.TempDir(), "missing.key")}
+	test.That(t, b.Reconfigure(ctx, deps, config), test.ShouldNotBeNil)
+}
@@ -7,11 +7,13 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/benbjohnson/clock"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/multierr"
 	goutils "go.viam.com/utils"
 
 	"go.viam.com/rdk/data"
@@ -62,8 +64,10 @@ type Capture struct {
 	captureDir string
 	// maxCaptureFileSize is only stored on Capture so that we can detect when it changes
 	maxCaptureFileSize int64
-	mongoMU            sync.Mutex
-	mongo              captureMongo
+	// encryptionKey is only stored on Capture so that we can detect when it changes
+	encryptionKey data.CaptureKey
+	mongoMU       sync.Mutex
+	mongo         captureMongo
 
 	// defaultCollectorConfigs are the default as specified in the machine config.
 	// These are stored in order to be compared to any capture override readings.
@@ -90,6 +94,8 @@ type collectorAndConfig struct {
 	Resource  resource.Resource
 	Collector data.Collector
 	Config    datamanager.DataCaptureConfig
+	// Trigger is only set for collectors in trigger mode.
+	Trigger *data.TriggerBuffer
 }
 
 // Identifier for a particular collector: component name, component model, component type,
@@ -230,6 +236,10 @@ func (c *Capture) Reconfigure(
 		c.logger.Infof("maximum_capture_file_size_bytes old: %d, new: %d", c.maxCaptureFileSize, config.MaximumCaptureFileSizeBytes)
 	}
 
+	if captureKeyID(c.encryptionKey) != captureKeyID(config.EncryptionKey) {
+		c.logger.Infof("capture encryption key old: %q, new: %q", captureKeyID(c.encryptionKey), captureKeyID(config.EncryptionKey))
+	}
+
 	collection := c.mongoReconfigure(ctx, config.MongoConfig)
 	newCollectors := c.newCollectors(collectorConfigsByResource, config, collection)
 	// If a component/method has been removed from the config, close the collector.
@@ -245,6 +255,7 @@ func (c *Capture) Reconfigure(
 	c.defaultCollectorConfigs = collectorConfigsByResource
 	c.captureDir = config.CaptureDir
 	c.maxCaptureFileSize = config.MaximumCaptureFileSizeBytes
+	c.encryptionKey = config.EncryptionKey
 }
 
 // Close closes the capture manager.
@@ -282,10 +293,12 @@ func (c *Capture) initializeOrUpdateCollector(
 	collection *mongo.Collection,
 ) (*collectorAndConfig, error) {
 	maxFileSizeChanged := c.maxCaptureFileSize != config.MaximumCaptureFileSizeBytes
+	encryptionKeyChanged := captureKeyID(c.encryptionKey) != captureKeyID(config.EncryptionKey)
 	if storedCollectorAndConfig, ok := c.collectors[md]; ok {
 		if storedCollectorAndConfig.Config.Equals(&collectorConfig) &&
 			res == storedCollectorAndConfig.Resource &&
-			!maxFileSizeChanged {
+			!maxFileSizeChanged &&
+			!encryptionKeyChanged {
 			// If the attributes have not changed, do nothing and leave the existing collector.
 			return c.collectors[md], nil
 		}
@@ -301,6 +314,14 @@ func (c *Capture) initializeOrUpdateCollector(
 		return nil, errors.Errorf("capture_buffer_size can't be less than 0, current value: %d", collectorConfig.CaptureBufferSize)
 	}
 
+	if err := collectorConfig.Trigger.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := collectorConfig.Deadband.Validate(); err != nil {
+		return nil, err
+	}
+
 	metadataKey := generateMetadataKey(md.MethodMetadata.API.String(), md.MethodMetadata.MethodName)
 	if additionalParamKey, ok := metadataToAdditionalParamFields[metadataKey]; ok {
 		if _, ok := collectorConfig.AdditionalParams[additionalParamKey]; !ok {
@@ -309,7 +330,7 @@ func (c *Capture) initializeOrUpdateCollector(
 		}
 	}
 
-	return c.buildCollector(res, md, collectorConfig, config.MaximumCaptureFileSizeBytes, collection)
+	return c.buildCollector(res, md, collectorConfig, config.MaximumCaptureFileSizeBytes, config.EncryptionKey, collection)
 }
 
 // buildCollector constructs and starts a new collector, assuming the base config was already validated.
@@ -319,6 +340,7 @@ func (c *Capture) buildCollector(
 	md collectorMetadata,
 	collectorConfig datamanager.DataCaptureConfig,
 	maxCaptureFileSize int64,
+	encryptionKey data.CaptureKey,
 	collection *mongo.Collection,
 ) (*collectorAndConfig, error) {
 	// TODO(DATA-451): validate method params
@@ -350,6 +372,18 @@ func (c *Capture) buildCollector(
 	// Parameters to initialize collector.
 	queueSize := defaultIfZeroVal(collectorConfig.CaptureQueueSize, defaultCaptureQueueSize)
 	bufferSize := defaultIfZeroVal(collectorConfig.CaptureBufferSize, defaultCaptureBufferSize)
+	var target data.CaptureBufferedWriter = data.NewEncryptedCaptureBuffer(targetDir, captureMetadata, maxCaptureFileSize, encryptionKey)
+	if dc := collectorConfig.Deadband; dc != nil {
+		// Drop the tabular captures which did not change since the last one which was written.
+		defaultDeadband := data.Deadband{Absolute: dc.Absolute, Relative: dc.Relative}
+		target = data.NewDeadbandBuffer(target, deadbandFields(dc), defaultDeadband, secondsToDuration(dc.MaxSilenceSecs))
+	}
+	var trigger *data.TriggerBuffer
+	if tc := collectorConfig.Trigger; tc != nil {
+		// Keep the captures in memory until a trigger.
+		trigger = data.NewTriggerBuffer(target, secondsToDuration(tc.PreTriggerSecs), secondsToDuration(tc.PostTriggerSecs), c.clk)
+		target = trigger
+	}
 	collector, err := collectorConstructor(res, data.CollectorParams{
 		MongoCollection: collection,
 		DataType:        dataType,
@@ -358,7 +392,7 @@ func (c *Capture) buildCollector(
 		MethodName:      collectorConfig.Method,
 		Interval:        data.GetDurationFromHz(collectorConfig.CaptureFrequencyHz),
 		MethodParams:    methodParams,
-		Target:          data.NewCaptureBuffer(targetDir, captureMetadata, maxCaptureFileSize),
+		Target:          target,
 		// Set queue size to defaultCaptureQueueSize if it was not set in the config.
 		QueueSize:  queueSize,
 		BufferSize: bufferSize,
@@ -374,7 +408,45 @@ func (c *Capture) buildCollector(
 		md, collectorConfigDescription(collectorConfig, targetDir, maxCaptureFileSize, queueSize, bufferSize))
 	collector.Collect()
 
-	return &collectorAndConfig{res, collector, collectorConfig}, nil
+	return &collectorAndConfig{Resource: res, Collector: collector, Config: collectorConfig, Trigger: trigger}, nil
+}
+
+// Trigger triggers the collectors in trigger mode of the resource with the given short name and of the given method,
+// or of every resource or method when either is empty. It returns the number of collectors which were triggered.
+func (c *Capture) Trigger(resourceName, method string) (int, error) {
+	var triggers []*data.TriggerBuffer
+	c.collectorsMu.Lock()
+	for _, collectorAndConfig := range c.collectors {
+		if collectorAndConfig.Trigger == nil {
+			continue
+		}
+		if resourceName != "" && collectorAndConfig.Config.Name.ShortName() != resourceName {
+			continue
+		}
+		if method != "" && collectorAndConfig.Config.Method != method {
+			continue
+		}
+		triggers = append(triggers, collectorAndConfig.Trigger)
+	}
+	c.collectorsMu.Unlock()
+
+	var err error
+	for _, trigger := range triggers {
+		err = multierr.Combine(err, trigger.Trigger())
+	}
+	return len(triggers), err
+}
+
+func secondsToDuration(secs float64) time.Duration {
+	return time.Duration(secs * float64(time.Second))
+}
+
+func deadbandFields(dc *datamanager.CaptureDeadbandConfig) map[string]data.Deadband {
+	fields := make(map[string]data.Deadband, len(dc.Fields))
+	for path, field := range dc.Fields {
+		fields[path] = data.Deadband(field)
+	}
+	return fields
 }
 
 func collectorConfigDescription(
@@ -391,6 +463,14 @@ func collectorConfigDescription(
 	)
 }
 
+// captureKeyID returns the ID of the capture encryption key, or "" if capture files are not encrypted.
+func captureKeyID(key data.CaptureKey) string {
+	if key == nil {
+		return ""
+	}
+	return key.ID()
+}
+
 func targetDir(captureDir string, collectorConfig datamanager.DataCaptureConfig) string {
 	return data.CaptureFilePathWithReplacedReservedChars(
 		filepath.Join(captureDir, collectorConfig.Name.API.String(),
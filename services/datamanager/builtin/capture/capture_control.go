@@ -61,7 +61,7 @@ func (c *Capture) SetCaptureConfigs(ctx context.Context, captureConfigReadings m
 
 			// Rebuild collectors to reflect override changes.
 			c.logCaptureConfigChange(key, existing, effectiveCfg)
-			coll, err := c.buildCollector(res, md, effectiveCfg, c.maxCaptureFileSize, c.mongo.collection)
+			coll, err := c.buildCollector(res, md, effectiveCfg, c.maxCaptureFileSize, c.encryptionKey, c.mongo.collection)
 			if err != nil {
 				c.logger.Warnw("failed to build collector", "error", err, "key", key)
 				continue
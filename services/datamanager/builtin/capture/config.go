@@ -1,5 +1,7 @@
 package capture
 
+import "go.viam.com/rdk/data"
+
 // MongoConfig is the optional data capture mongo config.
 type MongoConfig struct {
 	URI        string `json:"uri"`
@@ -24,6 +26,8 @@ type Config struct {
 	// (.prog) files should be allowed to grow to before they are convered into .capture
 	// files
 	MaximumCaptureFileSizeBytes int64
+	// EncryptionKey when set encrypts the captures of new capture files with data keys wrapped by it
+	EncryptionKey data.CaptureKey
 
 	MongoConfig *MongoConfig
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/internal/cloud"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/services/datamanager/builtin/capture"
@@ -50,6 +51,44 @@ type CaptureControlSensorConfig struct {
 	Key string `json:"key"`
 }
 
+// CaptureEncryptionConfig configures the encryption of capture files at rest. Either KeyFile, or PublicKeyFile and or
+// PrivateKeyFile must be set. Each capture file is encrypted with its own data key, which is wrapped by the symmetric
+// key in KeyFile, or by the RSA public key in PublicKeyFile. Files wrapped by a public key can only be decrypted, to
+// sync them for example, with PrivateKeyFile, so a public key on its own requires sync to be disabled.
+type CaptureEncryptionConfig struct {
+	// KeyFile holds a 32 byte AES-256 key, either raw, or hex or base64 encoded.
+	KeyFile string `json:"key_file,omitempty"`
+	// PublicKeyFile and PrivateKeyFile hold a PEM encoded RSA key pair.
+	PublicKeyFile  string `json:"public_key_file,omitempty"`
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+}
+
+// Validate returns an error if the config sets neither or both of a key file and a key pair.
+func (c *CaptureEncryptionConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	keyPair := c.PublicKeyFile != "" || c.PrivateKeyFile != ""
+	if c.KeyFile == "" && !keyPair {
+		return errors.New("capture_encryption requires a key_file, or a public_key_file or private_key_file")
+	}
+	if c.KeyFile != "" && keyPair {
+		return errors.New("capture_encryption can't set both a key_file and a public_key_file or private_key_file")
+	}
+	return nil
+}
+
+// captureKey loads the key of the config, which is nil if capture files are not encrypted.
+func (c *CaptureEncryptionConfig) captureKey() (data.CaptureKey, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if c.KeyFile != "" {
+		return data.LoadCaptureKeyFile(c.KeyFile)
+	}
+	return data.LoadCaptureKeyPair(c.PublicKeyFile, c.PrivateKeyFile)
+}
+
 // Config describes how to configure the service.
 // See sync.Config and capture.Config for docs on what each field does
 // to both sync & capture respectively.
@@ -65,6 +104,8 @@ type Config struct {
 	MaximumCaptureFileSizeBytes int64   `json:"maximum_capture_file_size_bytes"`
 	DiskUsageDeletionThreshold  float64 `json:"disk_usage_deletion_threshold"`
 	CaptureDirDeletionThreshold float64 `json:"capture_dir_deletion_threshold"`
+	// RetentionPolicy when set replaces DeleteEveryNthWhenDiskFull.
+	RetentionPolicy *datasync.RetentionPolicy `json:"retention_policy,omitempty"`
 	// Sync
 	AdditionalSyncPaths    []string `json:"additional_sync_paths"`
 	FileLastModifiedMillis int      `json:"file_last_modified_millis"`
@@ -72,9 +113,16 @@ type Config struct {
 	ScheduledSyncDisabled  bool     `json:"sync_disabled"`
 	SelectiveSyncerName    string   `json:"selective_syncer_name"`
 	SyncIntervalMins       float64  `json:"sync_interval_mins"`
+	// SyncWindows, SyncPriority and MaximumUploadBytesPerSec control when, in which order and how fast files are
+	// uploaded.
+	SyncWindows              []datasync.SyncWindow `json:"sync_windows,omitempty"`
+	SyncPriority             []string              `json:"sync_priority,omitempty"`
+	MaximumUploadBytesPerSec int64                 `json:"maximum_upload_bytes_per_sec,omitempty"`
 	// CaptureControlSensor when set specifies a sensor to poll for dynamic
 	// capture configurations.
 	CaptureControlSensor *CaptureControlSensorConfig `json:"capture_control_sensor,omitempty"`
+	// CaptureEncryption when set encrypts new capture files at rest.
+	CaptureEncryption *CaptureEncryptionConfig `json:"capture_encryption,omitempty"`
 }
 
 // Validate returns components which will be depended upon weakly due to the above matcher.
@@ -100,6 +148,28 @@ func (c *Config) Validate(path string) ([]string, []string, error) {
 	if c.CaptureDirDeletionThreshold < 0 {
 		return nil, nil, errors.New("capture_dir_deletion_threshold can't be negative")
 	}
+	if err := c.RetentionPolicy.Validate(); err != nil {
+		return nil, nil, err
+	}
+	for _, window := range c.SyncWindows {
+		if err := window.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := datasync.ValidateSyncPriority(c.SyncPriority); err != nil {
+		return nil, nil, err
+	}
+	if c.MaximumUploadBytesPerSec < 0 {
+		return nil, nil, errors.New("maximum_upload_bytes_per_sec can't be negative")
+	}
+	if err := c.CaptureEncryption.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if c.CaptureEncryption != nil && c.CaptureEncryption.KeyFile == "" && c.CaptureEncryption.PrivateKeyFile == "" &&
+		!c.ScheduledSyncDisabled {
+		return nil, nil, errors.New("capture_encryption with only a public_key_file can't decrypt capture files to sync them, " +
+			"set a private_key_file or sync_disabled")
+	}
 	return []string{cloud.InternalServiceName.String()}, nil, nil
 }
 
@@ -179,6 +249,7 @@ func (c *Config) syncConfig(syncSensor sensor.Sensor, syncSensorEnabled bool, lo
 		CaptureDir:                  c.getCaptureDir(logger),
 		CaptureDisabled:             c.CaptureDisabled,
 		DeleteEveryNthWhenDiskFull:  c.DeleteEveryNthWhenDiskFull,
+		RetentionPolicy:             c.RetentionPolicy,
 		DiskUsageDeletionThreshold:  c.DiskUsageDeletionThreshold,
 		CaptureDirDeletionThreshold: c.CaptureDirDeletionThreshold,
 		FileLastModifiedMillis:      c.FileLastModifiedMillis,
@@ -186,6 +257,9 @@ func (c *Config) syncConfig(syncSensor sensor.Sensor, syncSensorEnabled bool, lo
 		ScheduledSyncDisabled:       c.ScheduledSyncDisabled,
 		SelectiveSyncerName:         c.SelectiveSyncerName,
 		SyncIntervalMins:            syncIntervalMins,
+		SyncWindows:                 c.SyncWindows,
+		SyncPriority:                c.SyncPriority,
+		MaximumUploadBytesPerSec:    c.MaximumUploadBytesPerSec,
 		SelectiveSyncSensor:         syncSensor,
 		SelectiveSyncSensorEnabled:  syncSensorEnabled,
 	}
@@ -84,6 +84,55 @@ func TestConfig(t *testing.T) {
 				config: Config{CaptureDirDeletionThreshold: -1},
 				err:    errors.New("capture_dir_deletion_threshold can't be negative"),
 			},
+			{
+				name:   "returns an error if the RetentionPolicy is invalid",
+				config: Config{RetentionPolicy: &sync.RetentionPolicy{Strategy: "random"}},
+				err:    errors.New(`unknown retention strategy "random", must be "keep_newest" or "thin_uniformly"`),
+			},
+			{
+				name:   "returns an error if a SyncWindow is invalid",
+				config: Config{SyncWindows: []sync.SyncWindow{{Start: "22:00", End: "6am"}}},
+				err:    errors.New(`invalid sync window time "6am", must be formatted as HH:MM`),
+			},
+			{
+				name:   "returns an error if the SyncPriority has an unknown file type",
+				config: Config{SyncPriority: []string{"images"}},
+				err:    errors.New(`unknown file type "images", must be one of tabular, binary, arbitrary`),
+			},
+			{
+				name:   "returns an error if MaximumUploadBytesPerSec is negative",
+				config: Config{MaximumUploadBytesPerSec: -1},
+				err:    errors.New("maximum_upload_bytes_per_sec can't be negative"),
+			},
+			{
+				name:   "returns an error if CaptureEncryption has no key",
+				config: Config{CaptureEncryption: &CaptureEncryptionConfig{}},
+				err:    errors.New("capture_encryption requires a key_file, or a public_key_file or private_key_file"),
+			},
+			{
+				name:   "returns an error if CaptureEncryption has both a key file and a key pair",
+				config: Config{CaptureEncryption: &CaptureEncryptionConfig{KeyFile: "capture.key", PublicKeyFile: "capture.pub"}},
+				err:    errors.New("capture_encryption can't set both a key_file and a public_key_file or private_key_file"),
+			},
+			{
+				name:   "returns an error if CaptureEncryption has only a public key and sync is enabled",
+				config: Config{CaptureEncryption: &CaptureEncryptionConfig{PublicKeyFile: "capture.pub"}},
+				err: errors.New("capture_encryption with only a public_key_file can't decrypt capture files to sync them, " +
+					"set a private_key_file or sync_disabled"),
+			},
+			{
+				name: "returns no error if CaptureEncryption has only a public key and sync is disabled",
+				config: Config{
+					CaptureEncryption:     &CaptureEncryptionConfig{PublicKeyFile: "capture.pub"},
+					ScheduledSyncDisabled: true,
+				},
+				deps: []string{cloud.InternalServiceName.String()},
+			},
+			{
+				name:   "returns no error if CaptureEncryption has a key pair",
+				config: Config{CaptureEncryption: &CaptureEncryptionConfig{PublicKeyFile: "capture.pub", PrivateKeyFile: "capture.pem"}},
+				deps:   []string{cloud.InternalServiceName.String()},
+			},
 		}
 
 		for _, tc := range tcs {
@@ -0,0 +1,108 @@
+package builtin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.viam.com/rdk/data"
+)
+
+// queryCommand is the DoCommand which queries the captures in the capture directory. Its value may filter the
+// captures by "resource_name", "method", "tags" and the RFC3339 timestamps "start" and "end", and limit their number
+// with "limit", which is defaultQueryLimit unless given and at most maxQueryLimit. Binary captures are only included
+// base64 encoded when "include_binary" is true.
+const queryCommand = "query"
+
+const (
+	defaultQueryLimit = 100
+	maxQueryLimit     = 10000
+)
+
+// doQuery flushes the collectors and returns {"rows": [...]} with the captures which match the query.
+func (b *builtIn) doQuery(raw interface{}) (map[string]interface{}, error) {
+	var args map[string]interface{}
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		args = v
+	case bool, nil:
+	default:
+		return nil, fmt.Errorf("query must be an object, got %T", raw)
+	}
+	query, includeBinary, err := parseCaptureQuery(args)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	captureDir := b.captureDir
+	b.mu.Unlock()
+	b.capture.FlushCollectors()
+
+	captures, err := data.QueryCaptureDir(captureDir, query)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]interface{}, 0, len(captures))
+	for _, capture := range captures {
+		rows = append(rows, capture.ToMap(includeBinary))
+	}
+	return map[string]interface{}{"rows": rows}, nil
+}
+
+func parseCaptureQuery(args map[string]interface{}) (data.CaptureQuery, bool, error) {
+	var query data.CaptureQuery
+	var ok bool
+	if query.ResourceName, ok = stringArg(args, "resource_name"); !ok {
+		return query, false, errors.New("query resource_name must be a string")
+	}
+	if query.Method, ok = stringArg(args, "method"); !ok {
+		return query, false, errors.New("query method must be a string")
+	}
+	for key, t := range map[string]*time.Time{"start": &query.Start, "end": &query.End} {
+		s, ok := stringArg(args, key)
+		if !ok {
+			return query, false, fmt.Errorf("query %s must be an RFC3339 timestamp", key)
+		}
+		if s == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return query, false, fmt.Errorf("query %s must be an RFC3339 timestamp: %w", key, err)
+		}
+		*t = parsed
+	}
+	if raw, ok := args["tags"]; ok {
+		tags, ok := raw.([]interface{})
+		if !ok {
+			return query, false, errors.New("query tags must be a list of strings")
+		}
+		for _, tag := range tags {
+			s, ok := tag.(string)
+			if !ok {
+				return query, false, errors.New("query tags must be a list of strings")
+			}
+			query.Tags = append(query.Tags, s)
+		}
+	}
+	switch limit := args["limit"].(type) {
+	case nil:
+		query.Limit = defaultQueryLimit
+	case float64:
+		query.Limit = int(limit)
+	case int:
+		query.Limit = limit
+	default:
+		return query, false, errors.New("query limit must be a number")
+	}
+	if query.Limit < 1 || query.Limit > maxQueryLimit {
+		return query, false, fmt.Errorf("query limit must be between 1 and %d", maxQueryLimit)
+	}
+	includeBinary, ok := args["include_binary"].(bool)
+	if _, present := args["include_binary"]; present && !ok {
+		return query, false, errors.New("query include_binary must be a boolean")
+	}
+	query.ExcludeBinary = !includeBinary
+	return query, includeBinary, nil
+}
@@ -0,0 +1,81 @@
+package builtin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	datasync "go.viam.com/rdk/services/datamanager/builtin/sync"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestQueryCommand(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	captureDir := t.TempDir()
+
+	r := setupRobot(nil, map[resource.Name]resource.Resource{
+		arm.Named("arm1"): &inject.Arm{
+			EndPositionFunc: func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+				return spatialmath.NewPoseFromPoint(r3.Vector{X: 1, Y: 2, Z: 3}), nil
+			},
+		},
+	})
+	config, deps := setupConfig(t, r, enabledTabularCollectorConfigPath)
+	c := config.ConvertedAttributes.(*Config)
+	c.CaptureDir = captureDir
+	c.ScheduledSyncDisabled = true
+
+	start := time.Now()
+	b, err := New(ctx, deps, config, datasync.NoOpCloudClientConstructor, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() { test.That(t, b.Close(ctx), test.ShouldBeNil) }()
+	time.Sleep(200 * time.Millisecond)
+
+	// the query flushes the collectors, so captures which are still buffered are included
+	resp, err := b.DoCommand(ctx, map[string]interface{}{"query": map[string]interface{}{
+		"resource_name": "arm1",
+		"method":        "EndPosition",
+		"start":         start.Format(time.RFC3339),
+		"limit":         5.0,
+	}})
+	test.That(t, err, test.ShouldBeNil)
+	rows := resp["rows"].([]interface{})
+	test.That(t, len(rows), test.ShouldEqual, 5)
+	row := rows[0].(map[string]interface{})
+	test.That(t, row["component_name"], test.ShouldEqual, "arm1")
+	test.That(t, row["method_name"], test.ShouldEqual, "EndPosition")
+	pose := row["data"].(map[string]interface{})["pose"].(map[string]interface{})
+	test.That(t, pose["x"], test.ShouldEqual, 1.0)
+
+	resp, err = b.DoCommand(ctx, map[string]interface{}{"query": map[string]interface{}{"resource_name": "arm2"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["rows"], test.ShouldBeEmpty)
+
+	resp, err = b.DoCommand(ctx, map[string]interface{}{"query": map[string]interface{}{"end": start.Add(-time.Hour).Format(time.RFC3339)}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["rows"], test.ShouldBeEmpty)
+
+	_, err = b.DoCommand(ctx, map[string]interface{}{"query": map[string]interface{}{"start": "yesterday"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = b.DoCommand(ctx, map[string]interface{}{"query": map[string]interface{}{"tags": "a"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = b.DoCommand(ctx, map[string]interface{}{"query": map[string]interface{}{"limit": "5"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = b.DoCommand(ctx, map[string]interface{}{"query": map[string]interface{}{"limit": 0.0}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = b.DoCommand(ctx, map[string]interface{}{"query": map[string]interface{}{"limit": float64(maxQueryLimit + 1)}})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	query, _, err := parseCaptureQuery(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, query.Limit, test.ShouldEqual, defaultQueryLimit)
+	test.That(t, query.ExcludeBinary, test.ShouldBeTrue)
+}
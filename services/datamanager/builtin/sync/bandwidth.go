@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"context"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// uploadLimit returns the rate limit of a maximum upload bandwidth, where zero is unlimited.
+func uploadLimit(maxBytesPerSec int64) rate.Limit {
+	if maxBytesPerSec <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(maxBytesPerSec)
+}
+
+// uploadBurst returns the burst of a maximum upload bandwidth, which is a second worth of bytes.
+func uploadBurst(maxBytesPerSec int64) int {
+	if maxBytesPerSec <= 0 {
+		return 0
+	}
+	return int(maxBytesPerSec)
+}
+
+// waitForBandwidth blocks until the limiter allows n bytes to be uploaded, or the context is done.
+func waitForBandwidth(ctx context.Context, limiter *rate.Limiter, n int) error {
+	if limiter.Limit() == rate.Inf {
+		return nil
+	}
+	// WaitN fails for more than the burst at once, so requests larger than a second worth of bytes wait in parts
+	for n > 0 {
+		wait := min(n, limiter.Burst())
+		if err := limiter.WaitN(ctx, wait); err != nil {
+			return err
+		}
+		n -= wait
+	}
+	return nil
+}
+
+// rateLimitedClient is a DataSyncServiceClient which limits the bandwidth of the uploads of all sync workers.
+type rateLimitedClient struct {
+	v1.DataSyncServiceClient
+	limiter *rate.Limiter
+}
+
+func (c rateLimitedClient) DataCaptureUpload(
+	ctx context.Context,
+	in *v1.DataCaptureUploadRequest,
+	opts ...grpc.CallOption,
+) (*v1.DataCaptureUploadResponse, error) {
+	if err := waitForBandwidth(ctx, c.limiter, proto.Size(in)); err != nil {
+		return nil, err
+	}
+	return c.DataSyncServiceClient.DataCaptureUpload(ctx, in, opts...)
+}
+
+func (c rateLimitedClient) FileUpload(ctx context.Context, opts ...grpc.CallOption) (v1.DataSyncService_FileUploadClient, error) {
+	stream, err := c.DataSyncServiceClient.FileUpload(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return rateLimitedFileUploadClient{DataSyncService_FileUploadClient: stream, limiter: c.limiter}, nil
+}
+
+func (c rateLimitedClient) StreamingDataCaptureUpload(
+	ctx context.Context,
+	opts ...grpc.CallOption,
+) (v1.DataSyncService_StreamingDataCaptureUploadClient, error) {
+	stream, err := c.DataSyncServiceClient.StreamingDataCaptureUpload(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return rateLimitedStreamingDataCaptureUploadClient{DataSyncService_StreamingDataCaptureUploadClient: stream, limiter: c.limiter}, nil
+}
+
+type rateLimitedFileUploadClient struct {
+	v1.DataSyncService_FileUploadClient
+	limiter *rate.Limiter
+}
+
+func (c rateLimitedFileUploadClient) Send(req *v1.FileUploadRequest) error {
+	if err := waitForBandwidth(c.Context(), c.limiter, proto.Size(req)); err != nil {
+		return err
+	}
+	return c.DataSyncService_FileUploadClient.Send(req)
+}
+
+type rateLimitedStreamingDataCaptureUploadClient struct {
+	v1.DataSyncService_StreamingDataCaptureUploadClient
+	limiter *rate.Limiter
+}
+
+func (c rateLimitedStreamingDataCaptureUploadClient) Send(req *v1.StreamingDataCaptureUploadRequest) error {
+	if err := waitForBandwidth(c.Context(), c.limiter, proto.Size(req)); err != nil {
+		return err
+	}
+	return c.DataSyncService_StreamingDataCaptureUploadClient.Send(req)
+}
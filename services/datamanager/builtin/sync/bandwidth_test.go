@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRateLimitedClient(t *testing.T) {
+	var sent int
+	client := rateLimitedClient{
+		DataSyncServiceClient: &MockDataSyncServiceClient{
+			T: t,
+			DataCaptureUploadFunc: func(
+				ctx context.Context,
+				in *v1.DataCaptureUploadRequest,
+				opts ...grpc.CallOption,
+			) (*v1.DataCaptureUploadResponse, error) {
+				sent += proto.Size(in)
+				return &v1.DataCaptureUploadResponse{}, nil
+			},
+			FileUploadFunc: func(ctx context.Context, opts ...grpc.CallOption) (v1.DataSyncService_FileUploadClient, error) {
+				return &ClientStreamingMock[*v1.FileUploadRequest, *v1.FileUploadResponse]{
+					T: t,
+					SendFunc: func(req *v1.FileUploadRequest) error {
+						sent += proto.Size(req)
+						return nil
+					},
+				}, nil
+			},
+		},
+		limiter: rate.NewLimiter(rate.Inf, 0),
+	}
+	ctx := context.Background()
+	request := &v1.DataCaptureUploadRequest{
+		SensorContents: []*v1.SensorData{{Data: &v1.SensorData_Binary{Binary: make([]byte, 2000)}}},
+	}
+
+	// unlimited
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		_, err := client.DataCaptureUpload(ctx, request)
+		test.That(t, err, test.ShouldBeNil)
+	}
+	test.That(t, time.Since(start), test.ShouldBeLessThan, 100*time.Millisecond)
+
+	// 10 kB per second across unary and streaming uploads
+	const maxBytesPerSec = 10000
+	client.limiter.SetLimit(uploadLimit(maxBytesPerSec))
+	client.limiter.SetBurst(uploadBurst(maxBytesPerSec))
+	sent = 0
+	start = time.Now()
+	stream, err := client.FileUpload(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	for i := 0; i < 5; i++ {
+		_, err := client.DataCaptureUpload(ctx, request)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, stream.Send(&v1.FileUploadRequest{
+			UploadPacket: &v1.FileUploadRequest_FileContents{FileContents: &v1.FileData{Data: make([]byte, 2000)}},
+		}), test.ShouldBeNil)
+	}
+	elapsed := time.Since(start)
+	expected := time.Duration(float64(sent) / maxBytesPerSec * float64(time.Second))
+	test.That(t, elapsed, test.ShouldBeGreaterThan, expected-200*time.Millisecond)
+	test.That(t, elapsed, test.ShouldBeLessThan, expected+500*time.Millisecond)
+
+	// requests larger than the burst wait in parts
+	client.limiter.SetBurst(uploadBurst(100))
+	client.limiter.SetLimit(uploadLimit(100000))
+	start = time.Now()
+	_, err = client.DataCaptureUpload(ctx, request)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeLessThan, time.Second)
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.DataCaptureUpload(cancelledCtx, request)
+	test.That(t, err, test.ShouldNotBeNil)
+}
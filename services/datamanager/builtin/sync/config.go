@@ -57,8 +57,11 @@ type Config struct {
 	//
 	// The intent is to prevent data capture from filling up the
 	// disk if the robot is unable to sync data for a long period
-	// of time. Defaults to 5.
+	// of time. Defaults to 5. Ignored when RetentionPolicy is set.
 	DeleteEveryNthWhenDiskFull int
+	// RetentionPolicy, when set, decides which capture files are deleted when the disk is full instead of
+	// DeleteEveryNthWhenDiskFull, and limits the age and size of the capture files of collectors.
+	RetentionPolicy *RetentionPolicy
 	// DiskUsageDeletionThreshold defines the threshold at which file deletion might occur.
 	// If disk usage is at or above this threshold, AND the capture directory makes up at least CaptureDirToFSThreshold of the disk usage,
 	// then file deletion will occur based on the DeleteEveryNthWhenDiskFull parameter. If disk usage is at or above the disk usage threshold,
@@ -85,6 +88,15 @@ type Config struct {
 	// SyncIntervalMins defines interval in minutes that scheduled sync should run. Ignored if
 	// ScheduledSyncDisabled is true
 	SyncIntervalMins float64
+	// SyncWindows, when set, restricts scheduled sync to the times within any of the windows, and optionally to
+	// some types of files within each window. Manual syncs ignore the windows.
+	SyncWindows []SyncWindow
+	// SyncPriority defines the order in which the types of files are uploaded, like tabular before binary.
+	// Types which are not listed are uploaded last. Defaults to the order of the files in the sync paths.
+	SyncPriority []string
+	// MaximumUploadBytesPerSec limits the bandwidth of the uploads of all sync workers together.
+	// Defaults to 0, which is unlimited.
+	MaximumUploadBytesPerSec int64
 	// Tags defines the tags which should be applied to arbitrary files at sync time
 	Tags []string
 	// SelectiveSyncSensorEnabled when set to true, indicates that SelectiveSyncerName was non empty string
@@ -112,6 +124,7 @@ func (c Config) Equal(o Config) bool {
 		c.CaptureDir == o.CaptureDir &&
 		c.CaptureDisabled == o.CaptureDisabled &&
 		c.DeleteEveryNthWhenDiskFull == o.DeleteEveryNthWhenDiskFull &&
+		reflect.DeepEqual(c.RetentionPolicy, o.RetentionPolicy) &&
 		c.DiskUsageDeletionThreshold == o.DiskUsageDeletionThreshold &&
 		c.CaptureDirDeletionThreshold == o.CaptureDirDeletionThreshold &&
 		c.FileLastModifiedMillis == o.FileLastModifiedMillis &&
@@ -119,6 +132,9 @@ func (c Config) Equal(o Config) bool {
 		c.ScheduledSyncDisabled == o.ScheduledSyncDisabled &&
 		c.SelectiveSyncerName == o.SelectiveSyncerName &&
 		c.SyncIntervalMins == o.SyncIntervalMins &&
+		reflect.DeepEqual(c.SyncWindows, o.SyncWindows) &&
+		reflect.DeepEqual(c.SyncPriority, o.SyncPriority) &&
+		c.MaximumUploadBytesPerSec == o.MaximumUploadBytesPerSec &&
 		reflect.DeepEqual(c.Tags, o.Tags) &&
 		c.SelectiveSyncSensorEnabled == o.SelectiveSyncSensorEnabled &&
 		c.SelectiveSyncSensor == o.SelectiveSyncSensor
@@ -148,6 +164,10 @@ func (c *Config) logDiff(o Config, logger logging.Logger) {
 			c.DeleteEveryNthWhenDiskFull, o.DeleteEveryNthWhenDiskFull)
 	}
 
+	if !reflect.DeepEqual(c.RetentionPolicy, o.RetentionPolicy) {
+		logger.Infof("retention_policy: old: %+v, new: %+v", c.RetentionPolicy, o.RetentionPolicy)
+	}
+
 	if c.FileLastModifiedMillis != o.FileLastModifiedMillis {
 		logger.Infof("file_last_modified_millis: old: %d, new: %d", c.FileLastModifiedMillis, o.FileLastModifiedMillis)
 	}
@@ -168,6 +188,18 @@ func (c *Config) logDiff(o Config, logger logging.Logger) {
 		logger.Infof("sync_interval_mins: old: %f, new: %f", c.SyncIntervalMins, o.SyncIntervalMins)
 	}
 
+	if !reflect.DeepEqual(c.SyncWindows, o.SyncWindows) {
+		logger.Infof("sync_windows: old: %+v, new: %+v", c.SyncWindows, o.SyncWindows)
+	}
+
+	if !reflect.DeepEqual(c.SyncPriority, o.SyncPriority) {
+		logger.Infof("sync_priority: old: %s, new: %s", strings.Join(c.SyncPriority, " "), strings.Join(o.SyncPriority, " "))
+	}
+
+	if c.MaximumUploadBytesPerSec != o.MaximumUploadBytesPerSec {
+		logger.Infof("maximum_upload_bytes_per_sec: old: %d, new: %d", c.MaximumUploadBytesPerSec, o.MaximumUploadBytesPerSec)
+	}
+
 	if !reflect.DeepEqual(c.Tags, o.Tags) {
 		logger.Infof("tags: old: %s, new: %s", strings.Join(c.Tags, " "), strings.Join(o.Tags, " "))
 	}
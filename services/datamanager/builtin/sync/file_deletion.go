@@ -25,6 +25,7 @@ func deleteExcessFilesOnSchedule(
 	fileTracker *fileTracker,
 	captureDir string,
 	deleteEveryNth int,
+	retentionPolicy *RetentionPolicy,
 	diskUsageThreshold float64,
 	captureDirThreshold float64,
 	clock clock.Clock,
@@ -37,6 +38,10 @@ func deleteExcessFilesOnSchedule(
 	}
 	t := clock.Ticker(CheckDeleteExcessFilesInterval)
 	defer t.Stop()
+	var retentionRules *retentionRuleCache
+	if retentionPolicy != nil {
+		retentionRules = newRetentionRuleCache()
+	}
 	for {
 		if err := ctx.Err(); err != nil {
 			return
@@ -47,7 +52,8 @@ func deleteExcessFilesOnSchedule(
 			return
 		case <-t.C:
 			maybeDeleteExcessFiles(
-				ctx, fileTracker, captureDir, deleteEveryNth, diskUsageThreshold, captureDirThreshold, clock, logger, deletedFileCount,
+				ctx, fileTracker, captureDir, deleteEveryNth, retentionPolicy, retentionRules, diskUsageThreshold, captureDirThreshold, clock,
+				logger, deletedFileCount,
 			)
 		}
 	}
@@ -58,6 +64,8 @@ func maybeDeleteExcessFiles(
 	fileTracker *fileTracker,
 	captureDir string,
 	deleteEveryNth int,
+	retentionPolicy *RetentionPolicy,
+	retentionRules *retentionRuleCache,
 	diskUsageThreshold float64,
 	captureDirThreshold float64,
 	clock clock.Clock,
@@ -75,15 +83,30 @@ func maybeDeleteExcessFiles(
 		logger.Error("captureDir partition has size zero")
 		return
 	}
-	count, err := deleteExcessFiles(
-		ctx,
-		fileTracker,
-		usage,
-		captureDir,
-		deleteEveryNth,
-		diskUsageThreshold,
-		captureDirThreshold,
-		logger)
+	var count int
+	if retentionPolicy != nil {
+		count, err = enforceRetentionPolicy(
+			ctx,
+			fileTracker,
+			usage,
+			captureDir,
+			retentionPolicy,
+			retentionRules,
+			diskUsageThreshold,
+			captureDirThreshold,
+			clock.Now(),
+			logger)
+	} else {
+		count, err = deleteExcessFiles(
+			ctx,
+			fileTracker,
+			usage,
+			captureDir,
+			deleteEveryNth,
+			diskUsageThreshold,
+			captureDirThreshold,
+			logger)
+	}
 
 	duration := clock.Since(start)
 
@@ -0,0 +1,444 @@
+package sync
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils/diskusage"
+)
+
+// RetentionStrategy decides which of the capture files of the same priority are deleted first.
+type RetentionStrategy string
+
+const (
+	// RetentionStrategyKeepNewest deletes the oldest files first.
+	RetentionStrategyKeepNewest RetentionStrategy = "keep_newest"
+	// RetentionStrategyThinUniformly deletes every other file, then every other one of the remaining files and so on,
+	// such that the remaining files stay spread evenly over time.
+	RetentionStrategyThinUniformly RetentionStrategy = "thin_uniformly"
+)
+
+// RetentionPolicy replaces deleting every DeleteEveryNthWhenDiskFull capture file when the disk is full. When the disk
+// is full it deletes the capture files with the lowest priority first, in the order of the Strategy, until the disk
+// usage is back below DiskUsageDeletionThreshold. Regardless of the disk usage it also deletes the capture files
+// which exceed the maximum age or the maximum bytes of their collector.
+// Capture files which are being uploaded are never deleted.
+type RetentionPolicy struct {
+	// Strategy defaults to RetentionStrategyKeepNewest.
+	Strategy RetentionStrategy `json:"strategy,omitempty"`
+	// Rules apply to the capture files of the collectors which they match. The first matching rule applies. Files which
+	// match no rule have a priority of zero and are kept regardless of their age and size.
+	Rules []RetentionRule `json:"rules,omitempty"`
+}
+
+// RetentionRule matches the capture files of collectors by their resource name, method and tags, where any which
+// are left empty match every collector.
+type RetentionRule struct {
+	ResourceName string `json:"resource_name,omitempty"`
+	Method       string `json:"method,omitempty"`
+	// Tags matches the capture files with any of the tags.
+	Tags []string `json:"tags,omitempty"`
+	// Priority orders deletion when the disk is full. Files with a lower priority are deleted first.
+	Priority int `json:"priority,omitempty"`
+	// MaxAgeHours, when set, deletes the capture files which were last written more than that long ago.
+	MaxAgeHours float64 `json:"max_age_hours,omitempty"`
+	// MaxBytes, when set, limits the total size of the capture files of each matching collector.
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+}
+
+// Validate returns an error if the retention policy is invalid.
+func (rp *RetentionPolicy) Validate() error {
+	if rp == nil {
+		return nil
+	}
+	switch rp.Strategy {
+	case "", RetentionStrategyKeepNewest, RetentionStrategyThinUniformly:
+	default:
+		return errors.Errorf("unknown retention strategy %q, must be %q or %q",
+			rp.Strategy, RetentionStrategyKeepNewest, RetentionStrategyThinUniformly)
+	}
+	for i, rule := range rp.Rules {
+		if rule.MaxAgeHours < 0 {
+			return errors.Errorf("retention rule %d max_age_hours can't be negative", i)
+		}
+		if rule.MaxBytes < 0 {
+			return errors.Errorf("retention rule %d max_bytes can't be negative", i)
+		}
+	}
+	return nil
+}
+
+func (rp *RetentionPolicy) strategy() RetentionStrategy {
+	if rp.Strategy == "" {
+		return RetentionStrategyKeepNewest
+	}
+	return rp.Strategy
+}
+
+// ruleFor returns the first rule which matches the capture file metadata, or nil if there is none.
+func (rp *RetentionPolicy) ruleFor(md *v1.DataCaptureMetadata) *RetentionRule {
+	for i := range rp.Rules {
+		rule := &rp.Rules[i]
+		if rule.ResourceName != "" && rule.ResourceName != md.GetComponentName() {
+			continue
+		}
+		if rule.Method != "" && rule.Method != md.GetMethodName() {
+			continue
+		}
+		if len(rule.Tags) > 0 && !slices.ContainsFunc(rule.Tags, func(tag string) bool {
+			return slices.Contains(md.GetTags(), tag)
+		}) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// hasLimits returns whether any rule limits the age or size of capture files, which applies regardless of the disk
+// usage.
+func (rp *RetentionPolicy) hasLimits() bool {
+	return slices.ContainsFunc(rp.Rules, func(rule RetentionRule) bool {
+		return rule.MaxAgeHours > 0 || rule.MaxBytes > 0
+	})
+}
+
+// retentionRuleCache remembers the retention rule which applies to each completed capture file, such that the
+// metadata of a file is read the first time it is listed rather than on every check. Completed capture files are
+// never written to again, and the cache is dropped along with the policy when the config changes.
+type retentionRuleCache struct {
+	rules map[string]*RetentionRule
+}
+
+func newRetentionRuleCache() *retentionRuleCache {
+	return &retentionRuleCache{rules: map[string]*RetentionRule{}}
+}
+
+// retentionFile is a completed capture file along with the retention rule which applies to it.
+type retentionFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+	// collector identifies the collector which wrote the file
+	collector string
+	// rule is nil if no rule applies
+	rule *RetentionRule
+}
+
+func (rf retentionFile) priority() int {
+	if rf.rule == nil {
+		return 0
+	}
+	return rf.rule.Priority
+}
+
+// enforceRetentionPolicy deletes the capture files which exceed the limits of their rules and, if the disk is full,
+// as many of the remaining capture files in retention order as it takes to get below the disk usage threshold. It
+// returns the number of deleted files.
+func enforceRetentionPolicy(
+	ctx context.Context,
+	fileTracker *fileTracker,
+	usage diskusage.DiskUsage,
+	captureDir string,
+	policy *RetentionPolicy,
+	cache *retentionRuleCache,
+	diskUsageThreshold float64,
+	captureDirToFSThreshold float64,
+	now time.Time,
+	logger logging.Logger,
+) (int, error) {
+	// without limits nothing is deleted until the disk is full, so there is no need to list the capture files
+	if !policy.hasLimits() && 1.0-usage.AvailablePercent() < diskUsageThreshold {
+		return 0, nil
+	}
+	files, err := listRetentionFiles(ctx, captureDir, policy, cache, logger)
+	if err != nil {
+		return 0, err
+	}
+
+	files, deletedCount, freed, err := enforceRetentionLimits(ctx, fileTracker, files, policy.strategy(), now, logger)
+	if err != nil {
+		return deletedCount, err
+	}
+
+	usage.AvailableBytes = min(usage.AvailableBytes+uint64(freed), usage.SizeBytes)
+	shouldDelete, err := shouldDeleteBasedOnDiskUsage(ctx, usage, captureDir, diskUsageThreshold, captureDirToFSThreshold, logger)
+	if err != nil {
+		return deletedCount, errors.Wrap(err, "error checking file system stats")
+	}
+	if !shouldDelete {
+		return deletedCount, nil
+	}
+
+	// Free one byte more than the excess so that the disk usage drops below the threshold, rather than to it.
+	usedBytes := int64(usage.SizeBytes - usage.AvailableBytes)
+	bytesToFree := usedBytes - int64(diskUsageThreshold*float64(usage.SizeBytes)) + 1
+	logger.Warnf("current disk usage of the data capture directory exceeds threshold (%f), deleting %s of capture files by retention policy",
+		captureDirToFSThreshold, data.FormatBytesI64(bytesToFree))
+	deleted, err := deleteInOrder(ctx, fileTracker, retentionOrder(files, policy.strategy()), bytesToFree, logger)
+	return deletedCount + len(deleted), err
+}
+
+// listRetentionFiles returns the completed capture files in the capture directory. It only reads the metadata of the
+// files which are not in the cache, and drops the files which no longer exist from the cache.
+func listRetentionFiles(
+	ctx context.Context,
+	captureDir string,
+	policy *RetentionPolicy,
+	cache *retentionRuleCache,
+	logger logging.Logger,
+) ([]retentionFile, error) {
+	var files []retentionFile
+	listed := map[string]*RetentionRule{}
+	err := filepath.WalkDir(captureDir, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			// the file may have been uploaded, or been renamed from .prog to .capture, since the walk began
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != data.CompletedCaptureFileExt {
+			return nil
+		}
+		fileInfo, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		rule, cached := cache.rules[path]
+		// without rules no metadata is needed to know that none applies
+		if !cached && len(policy.Rules) > 0 {
+			md, err := readCaptureFileMetadata(path)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				return nil
+			case err != nil:
+				// the file is not cached, so that its metadata is read again on the next check
+				logger.Debugw("unable to read capture file metadata, applying no retention rule", "file", path, "error", err)
+			default:
+				rule = policy.ruleFor(md)
+				listed[path] = rule
+			}
+		} else {
+			listed[path] = rule
+		}
+		files = append(files, retentionFile{
+			path:      path,
+			size:      fileInfo.Size(),
+			modTime:   fileInfo.ModTime(),
+			collector: filepath.Dir(path),
+			rule:      rule,
+		})
+		return nil
+	})
+	if err == nil {
+		cache.rules = listed
+	}
+	return files, err
+}
+
+func readCaptureFileMetadata(path string) (*v1.DataCaptureMetadata, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer f.Close()
+	captureFile, err := data.ReadCaptureFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return captureFile.ReadMetadata(), nil
+}
+
+// enforceRetentionLimits deletes the files which are older than the maximum age of their rule, and then the files of
+// each collector beyond the maximum bytes of its rule in the order of the strategy. It returns the files which remain,
+// along with the number of deleted files and bytes.
+func enforceRetentionLimits(
+	ctx context.Context,
+	fileTracker *fileTracker,
+	files []retentionFile,
+	strategy RetentionStrategy,
+	now time.Time,
+	logger logging.Logger,
+) ([]retentionFile, int, int64, error) {
+	var expired []retentionFile
+	byCollector := map[string][]retentionFile{}
+	for _, file := range files {
+		if file.rule != nil && file.rule.MaxAgeHours > 0 &&
+			now.Sub(file.modTime) > time.Duration(file.rule.MaxAgeHours*float64(time.Hour)) {
+			expired = append(expired, file)
+			continue
+		}
+		byCollector[file.collector] = append(byCollector[file.collector], file)
+	}
+
+	var freed int64
+	deletedCount := 0
+	// files which could not be deleted because they are being uploaded remain
+	var remaining []retentionFile
+	for _, file := range expired {
+		deleted, err := deleteCaptureFile(fileTracker, file.path, logger)
+		if err != nil {
+			return nil, deletedCount, freed, err
+		}
+		if !deleted {
+			remaining = append(remaining, file)
+			continue
+		}
+		deletedCount++
+		freed += file.size
+	}
+
+	for _, collectorFiles := range byCollector {
+		if ctx.Err() != nil {
+			return nil, deletedCount, freed, ctx.Err()
+		}
+		// every file of a collector matches the same rule unless its tags have changed, in which case the rule of
+		// the newest file applies
+		newest := slices.MaxFunc(collectorFiles, func(a, b retentionFile) int { return a.modTime.Compare(b.modTime) })
+		if newest.rule == nil || newest.rule.MaxBytes == 0 {
+			remaining = append(remaining, collectorFiles...)
+			continue
+		}
+		var total int64
+		for _, file := range collectorFiles {
+			total += file.size
+		}
+		if total <= newest.rule.MaxBytes {
+			remaining = append(remaining, collectorFiles...)
+			continue
+		}
+		deleted, err := deleteInOrder(
+			ctx, fileTracker, retentionOrder(collectorFiles, strategy), total-newest.rule.MaxBytes, logger)
+		deletedCount += len(deleted)
+		for _, file := range collectorFiles {
+			if deleted[file.path] {
+				freed += file.size
+			} else {
+				remaining = append(remaining, file)
+			}
+		}
+		if err != nil {
+			return nil, deletedCount, freed, err
+		}
+	}
+	return remaining, deletedCount, freed, nil
+}
+
+// retentionOrder sorts the files in the order in which they are deleted: by ascending priority and then by the
+// strategy.
+func retentionOrder(files []retentionFile, strategy RetentionStrategy) []retentionFile {
+	sorted := slices.Clone(files)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].priority() != sorted[j].priority() {
+			return sorted[i].priority() < sorted[j].priority()
+		}
+		return sorted[i].modTime.Before(sorted[j].modTime)
+	})
+	if strategy != RetentionStrategyThinUniformly {
+		return sorted
+	}
+
+	ordered := make([]retentionFile, 0, len(sorted))
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].priority() == sorted[start].priority() {
+			end++
+		}
+		for _, i := range thinningOrder(end - start) {
+			ordered = append(ordered, sorted[start+i])
+		}
+		start = end
+	}
+	return ordered
+}
+
+// thinningOrder returns the order in which to delete n files, sorted from oldest to newest, such that the files which
+// remain after any number of deletions are spread evenly over time. It deletes every other file, then every other one
+// of the remaining files and so on, always keeping the newest file until last.
+func thinningOrder(n int) []int {
+	order := make([]int, 0, n)
+	remaining := make([]int, n)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	for len(remaining) > 1 {
+		kept := make([]int, 0, (len(remaining)+1)/2)
+		for i, file := range remaining {
+			if (len(remaining)-1-i)%2 == 1 {
+				order = append(order, file)
+			} else {
+				kept = append(kept, file)
+			}
+		}
+		remaining = kept
+	}
+	return append(order, remaining...)
+}
+
+// deleteInOrder deletes the files in order until at least bytesToFree bytes have been freed. It returns the paths of
+// the deleted files.
+func deleteInOrder(
+	ctx context.Context,
+	fileTracker *fileTracker,
+	files []retentionFile,
+	bytesToFree int64,
+	logger logging.Logger,
+) (map[string]bool, error) {
+	deleted := map[string]bool{}
+	var freed int64
+	for _, file := range files {
+		if freed >= bytesToFree {
+			break
+		}
+		if ctx.Err() != nil {
+			return deleted, ctx.Err()
+		}
+		ok, err := deleteCaptureFile(fileTracker, file.path, logger)
+		if err != nil {
+			return deleted, err
+		}
+		if ok {
+			deleted[file.path] = true
+			freed += file.size
+		}
+	}
+	return deleted, nil
+}
+
+// deleteCaptureFile deletes the capture file unless it is being uploaded, in which case it returns false.
+func deleteCaptureFile(fileTracker *fileTracker, path string, logger logging.Logger) (bool, error) {
+	if !fileTracker.markInProgress(path) {
+		logger.Debugw("Tried to mark file as in progress but lock already held", "file", filepath.Base(path))
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil {
+		fileTracker.unmarkInProgress(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			// the file has been uploaded since it was listed
+			return false, nil
+		}
+		logger.Warnw("error deleting file", "error", err)
+		return false, err
+	}
+	logger.Infof("successfully deleted %s", filepath.Base(path))
+	return true, nil
+}
@@ -0,0 +1,238 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils/diskusage"
+)
+
+func TestRetentionPolicyRules(t *testing.T) {
+	policy := &RetentionPolicy{Rules: []RetentionRule{
+		{ResourceName: "cam", Method: "GetImages", Priority: 10},
+		{Tags: []string{"incident", "review"}, Priority: 100},
+		{Method: "EndPosition", MaxAgeHours: 1},
+	}}
+	test.That(t, policy.Validate(), test.ShouldBeNil)
+
+	for _, tc := range []struct {
+		name string
+		md   *v1.DataCaptureMetadata
+		rule *RetentionRule
+	}{
+		{
+			name: "resource name and method",
+			md:   &v1.DataCaptureMetadata{ComponentName: "cam", MethodName: "GetImages", Tags: []string{"review"}},
+			rule: &policy.Rules[0],
+		},
+		{
+			name: "any tag",
+			md:   &v1.DataCaptureMetadata{ComponentName: "cam", MethodName: "NextPointCloud", Tags: []string{"a", "review"}},
+			rule: &policy.Rules[1],
+		},
+		{
+			name: "method only",
+			md:   &v1.DataCaptureMetadata{ComponentName: "arm", MethodName: "EndPosition"},
+			rule: &policy.Rules[2],
+		},
+		{
+			name: "no rule",
+			md:   &v1.DataCaptureMetadata{ComponentName: "arm", MethodName: "JointPositions"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			test.That(t, policy.ruleFor(tc.md), test.ShouldEqual, tc.rule)
+		})
+	}
+
+	test.That(t, (&RetentionPolicy{Strategy: "random"}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&RetentionPolicy{Rules: []RetentionRule{{MaxAgeHours: -1}}}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&RetentionPolicy{Rules: []RetentionRule{{MaxBytes: -1}}}).Validate(), test.ShouldNotBeNil)
+}
+
+func TestThinningOrder(t *testing.T) {
+	test.That(t, thinningOrder(0), test.ShouldBeEmpty)
+	test.That(t, thinningOrder(1), test.ShouldResemble, []int{0})
+	test.That(t, thinningOrder(5), test.ShouldResemble, []int{1, 3, 2, 0, 4})
+	test.That(t, thinningOrder(8), test.ShouldResemble, []int{0, 2, 4, 6, 1, 5, 3, 7})
+}
+
+func TestEnforceRetentionPolicy(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	now := time.Now()
+	// writeCaptureFiles writes n capture files of a collector, one a minute until the last one a minute ago.
+	writeCaptureFiles := func(t *testing.T, dir, name string, tags []string, n int) []string {
+		t.Helper()
+		collectorDir := filepath.Join(dir, name)
+		test.That(t, os.MkdirAll(collectorDir, 0o700), test.ShouldBeNil)
+		var paths []string
+		for i := 0; i < n; i++ {
+			path := filepath.Join(collectorDir, fmt.Sprintf("%03d.capture", i))
+			//nolint:gosec
+			f, err := os.Create(path)
+			test.That(t, err, test.ShouldBeNil)
+			_, err = pbutil.WriteDelimited(f, &v1.DataCaptureMetadata{ComponentName: name, MethodName: "Method", Tags: tags})
+			test.That(t, err, test.ShouldBeNil)
+			_, err = pbutil.WriteDelimited(f, binarySensorData(make([]byte, 1000)))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, f.Close(), test.ShouldBeNil)
+			modTime := now.Add(time.Duration(i-n) * time.Minute)
+			test.That(t, os.Chtimes(path, modTime, modTime), test.ShouldBeNil)
+			paths = append(paths, path)
+		}
+		return paths
+	}
+	fileSize := func(t *testing.T, path string) int64 {
+		t.Helper()
+		info, err := os.Stat(path)
+		test.That(t, err, test.ShouldBeNil)
+		return info.Size()
+	}
+	exists := func(paths ...string) []bool {
+		var existing []bool
+		for _, path := range paths {
+			_, err := os.Stat(path)
+			existing = append(existing, err == nil)
+		}
+		return existing
+	}
+	// usageToFree returns the disk usage at which the retention policy needs to free the given bytes to drop below
+	// a disk usage threshold of a half.
+	usageToFree := func(bytes int64) diskusage.DiskUsage {
+		const size = 1 << 30
+		return diskusage.DiskUsage{SizeBytes: size, AvailableBytes: uint64(size/2 - bytes + 1)}
+	}
+	notFull := diskusage.DiskUsage{SizeBytes: 1 << 30, AvailableBytes: 1 << 30}
+	enforce := func(t *testing.T, dir string, policy *RetentionPolicy, usage diskusage.DiskUsage, ft *fileTracker) int {
+		t.Helper()
+		count, err := enforceRetentionPolicy(
+			context.Background(), ft, usage, dir, policy, newRetentionRuleCache(), 0.5, math.SmallestNonzeroFloat64, now, logger)
+		test.That(t, err, test.ShouldBeNil)
+		return count
+	}
+
+	t.Run("limits apply regardless of disk usage", func(t *testing.T) {
+		dir := t.TempDir()
+		arm := writeCaptureFiles(t, dir, "arm", nil, 90)
+		sensor := writeCaptureFiles(t, dir, "sensor", nil, 3)
+		cam := writeCaptureFiles(t, dir, "cam", nil, 90)
+		policy := &RetentionPolicy{Rules: []RetentionRule{
+			{ResourceName: "arm", MaxAgeHours: 1},
+			{ResourceName: "sensor", MaxBytes: 2 * fileSize(t, sensor[0])},
+		}}
+		// a file which is being uploaded is never deleted
+		ft := newFileTracker()
+		ft.markInProgress(arm[0])
+
+		test.That(t, enforce(t, dir, policy, notFull, ft), test.ShouldEqual, 29+1)
+		test.That(t, exists(arm[0], arm[1], arm[29], arm[30], arm[89]), test.ShouldResemble, []bool{true, false, false, true, true})
+		test.That(t, exists(sensor...), test.ShouldResemble, []bool{false, true, true})
+		test.That(t, exists(cam[0]), test.ShouldResemble, []bool{true})
+	})
+
+	t.Run("the lowest priority is deleted first when the disk is full", func(t *testing.T) {
+		dir := t.TempDir()
+		unmatched := writeCaptureFiles(t, dir, "other", nil, 3)
+		cam := writeCaptureFiles(t, dir, "cam", nil, 3)
+		incident := writeCaptureFiles(t, dir, "cam2", []string{"incident"}, 1)
+		size := fileSize(t, cam[0])
+		policy := &RetentionPolicy{Rules: []RetentionRule{
+			{Tags: []string{"incident"}, Priority: 100},
+			{ResourceName: "cam", Priority: 10},
+		}}
+
+		ft := newFileTracker()
+		ft.markInProgress(unmatched[1])
+		test.That(t, enforce(t, dir, policy, usageToFree(3*size), ft), test.ShouldEqual, 3)
+		test.That(t, exists(unmatched...), test.ShouldResemble, []bool{false, true, false})
+		test.That(t, exists(cam...), test.ShouldResemble, []bool{false, true, true})
+		test.That(t, exists(incident...), test.ShouldResemble, []bool{true})
+
+		test.That(t, enforce(t, dir, policy, notFull, ft), test.ShouldEqual, 0)
+	})
+
+	t.Run("thinning uniformly keeps files spread over time", func(t *testing.T) {
+		dir := t.TempDir()
+		files := writeCaptureFiles(t, dir, "other", nil, 8)
+		size := fileSize(t, files[0])
+		thinning := &RetentionPolicy{Strategy: RetentionStrategyThinUniformly}
+
+		test.That(t, enforce(t, dir, thinning, usageToFree(3*size+size/2), newFileTracker()), test.ShouldEqual, 4)
+		test.That(t, exists(files...), test.ShouldResemble, []bool{false, true, false, true, false, true, false, true})
+	})
+}
+
+func TestRetentionRuleCache(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	dir := t.TempDir()
+	collectorDir := filepath.Join(dir, "cam")
+	test.That(t, os.MkdirAll(collectorDir, 0o700), test.ShouldBeNil)
+	path := filepath.Join(collectorDir, "000.capture")
+	writeCaptureFile := func(t *testing.T, md *v1.DataCaptureMetadata) {
+		t.Helper()
+		//nolint:gosec
+		f, err := os.Create(path)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = pbutil.WriteDelimited(f, md)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.Close(), test.ShouldBeNil)
+	}
+	writeCaptureFile(t, &v1.DataCaptureMetadata{ComponentName: "cam", MethodName: "GetImages"})
+	policy := &RetentionPolicy{Rules: []RetentionRule{{ResourceName: "cam", MaxAgeHours: 1}}}
+	notFull := diskusage.DiskUsage{SizeBytes: 1 << 30, AvailableBytes: 1 << 30}
+
+	t.Run("the metadata of a file is read once", func(t *testing.T) {
+		cache := newRetentionRuleCache()
+		files, err := listRetentionFiles(context.Background(), dir, policy, cache, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, files, test.ShouldHaveLength, 1)
+		test.That(t, files[0].rule, test.ShouldEqual, &policy.Rules[0])
+
+		// the rule of the file no longer matches its metadata, which is not read again
+		writeCaptureFile(t, &v1.DataCaptureMetadata{ComponentName: "arm", MethodName: "EndPosition"})
+		files, err = listRetentionFiles(context.Background(), dir, policy, cache, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, files, test.ShouldHaveLength, 1)
+		test.That(t, files[0].rule, test.ShouldEqual, &policy.Rules[0])
+
+		// files which no longer exist are dropped from the cache
+		test.That(t, os.Remove(path), test.ShouldBeNil)
+		files, err = listRetentionFiles(context.Background(), dir, policy, cache, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, files, test.ShouldBeEmpty)
+		test.That(t, cache.rules, test.ShouldBeEmpty)
+	})
+
+	t.Run("files are not listed when no rule has limits and the disk is not full", func(t *testing.T) {
+		writeCaptureFile(t, &v1.DataCaptureMetadata{ComponentName: "cam", MethodName: "GetImages"})
+		cache := newRetentionRuleCache()
+		priorities := &RetentionPolicy{Rules: []RetentionRule{{ResourceName: "cam", Priority: 10}}}
+		count, err := enforceRetentionPolicy(
+			context.Background(), newFileTracker(), notFull, dir, priorities, cache, 0.5, math.SmallestNonzeroFloat64,
+			time.Now(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, count, test.ShouldEqual, 0)
+		test.That(t, cache.rules, test.ShouldBeEmpty)
+
+		count, err = enforceRetentionPolicy(
+			context.Background(), newFileTracker(), notFull, dir, policy, cache, 0.5, math.SmallestNonzeroFloat64,
+			time.Now(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, count, test.ShouldEqual, 0)
+		test.That(t, cache.rules, test.ShouldHaveLength, 1)
+	})
+}
+
+func binarySensorData(payload []byte) *v1.SensorData {
+	return &v1.SensorData{Metadata: &v1.SensorMetadata{}, Data: &v1.SensorData_Binary{Binary: payload}}
+}
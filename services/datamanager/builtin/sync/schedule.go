@@ -0,0 +1,234 @@
+package sync
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+
+	"go.viam.com/rdk/data"
+)
+
+// The types of files which sync uploads.
+const (
+	// FileTypeTabular is the type of the capture files of tabular data, like sensor readings.
+	FileTypeTabular = "tabular"
+	// FileTypeBinary is the type of the capture files of binary data, like images.
+	FileTypeBinary = "binary"
+	// FileTypeArbitrary is the type of the files which are not capture files, like those in AdditionalSyncPaths.
+	FileTypeArbitrary = "arbitrary"
+)
+
+var fileTypes = []string{FileTypeTabular, FileTypeBinary, FileTypeArbitrary}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// SyncWindow is a time of day, in the local time of the machine, during which scheduled sync may upload files.
+type SyncWindow struct {
+	// Days are the days of the week on which the window starts, like "mon" or "sat". Defaults to every day.
+	Days []string `json:"days,omitempty"`
+	// Start and End are the times of day of the window, like "22:00" and "06:00". A window whose end is not after its
+	// start runs past midnight.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// FileTypes are the types of files which are uploaded during the window. Defaults to every type.
+	FileTypes []string `json:"file_types,omitempty"`
+}
+
+// Validate returns an error if the sync window is invalid.
+func (w SyncWindow) Validate() error {
+	for _, day := range w.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return errors.Errorf("unknown sync window day %q, must be one of sun, mon, tue, wed, thu, fri or sat", day)
+		}
+	}
+	if _, err := parseTimeOfDay(w.Start); err != nil {
+		return err
+	}
+	if _, err := parseTimeOfDay(w.End); err != nil {
+		return err
+	}
+	return validateFileTypes(w.FileTypes)
+}
+
+// ValidateSyncPriority returns an error if the sync priority contains an unknown file type.
+func ValidateSyncPriority(priority []string) error {
+	return validateFileTypes(priority)
+}
+
+func validateFileTypes(types []string) error {
+	for _, fileType := range types {
+		if !slices.Contains(fileTypes, fileType) {
+			return errors.Errorf("unknown file type %q, must be one of %s", fileType, strings.Join(fileTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// parseTimeOfDay returns the time since midnight of a time of day formatted as "15:04".
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, errors.Errorf("invalid sync window time %q, must be formatted as HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// contains returns whether t is within the window, assuming the window is valid.
+func (w SyncWindow) contains(t time.Time) bool {
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return false
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	sinceMidnight := t.Sub(midnight)
+	if start < end {
+		return w.startsOn(t.Weekday()) && sinceMidnight >= start && sinceMidnight < end
+	}
+	// the window runs past midnight, so it either started today or yesterday
+	startedToday := w.startsOn(t.Weekday()) && sinceMidnight >= start
+	startedYesterday := w.startsOn((t.Weekday()+6)%7) && sinceMidnight < end
+	return startedToday || startedYesterday
+}
+
+func (w SyncWindow) startsOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if weekdays[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedFileTypes returns whether scheduled sync may upload files at t and, if so, the types of files it may upload,
+// where nil allows every type. Sync is always allowed when there are no windows.
+func allowedFileTypes(windows []SyncWindow, t time.Time) (map[string]bool, bool) {
+	if len(windows) == 0 {
+		return nil, true
+	}
+	var allowed map[string]bool
+	open := false
+	for _, w := range windows {
+		if !w.contains(t) {
+			continue
+		}
+		if len(w.FileTypes) == 0 {
+			return nil, true
+		}
+		open = true
+		if allowed == nil {
+			allowed = map[string]bool{}
+		}
+		for _, fileType := range w.FileTypes {
+			allowed[fileType] = true
+		}
+	}
+	return allowed, open
+}
+
+// fileTypeCache remembers the file type of each completed capture file, such that the metadata of a file is read the
+// first time it is prioritized rather than on every scheduled sync. Completed capture files are never written to
+// again.
+type fileTypeCache struct {
+	mu    sync.Mutex
+	types map[string]string
+}
+
+func newFileTypeCache() *fileTypeCache {
+	return &fileTypeCache{types: map[string]string{}}
+}
+
+// fileType returns the file type of the file at path, reading it from the cache if it was read before. Capture files
+// whose metadata can't be read are tabular, so that sync moves them to the failed directory as soon as possible, and
+// are not cached.
+func (c *fileTypeCache) fileType(path string) string {
+	if filepath.Ext(path) != data.CompletedCaptureFileExt {
+		return FileTypeArbitrary
+	}
+	c.mu.Lock()
+	fileType, ok := c.types[path]
+	c.mu.Unlock()
+	if ok {
+		return fileType
+	}
+	md, err := readCaptureFileMetadata(path)
+	if err != nil {
+		return FileTypeTabular
+	}
+	fileType = FileTypeTabular
+	if md.GetType() == v1.DataType_DATA_TYPE_BINARY_SENSOR {
+		fileType = FileTypeBinary
+	}
+	c.mu.Lock()
+	c.types[path] = fileType
+	c.mu.Unlock()
+	return fileType
+}
+
+// retain drops the files which are not in paths, such as those which were uploaded, from the cache.
+func (c *fileTypeCache) retain(paths []string) {
+	listed := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		listed[path] = true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path := range c.types {
+		if !listed[path] {
+			delete(c.types, path)
+		}
+	}
+}
+
+// prioritizeFiles filters the files to the allowed types, where nil allows every type, and stably sorts them by the
+// position of their type in priority. Types which are not in priority come last. The file types are read through the
+// cache.
+func prioritizeFiles(paths []string, priority []string, allowed map[string]bool, cache *fileTypeCache) []string {
+	if len(priority) == 0 && allowed == nil {
+		return paths
+	}
+	rank := func(fileType string) int {
+		if i := slices.Index(priority, fileType); i >= 0 {
+			return i
+		}
+		return len(priority)
+	}
+	type rankedFile struct {
+		path string
+		rank int
+	}
+	ranked := make([]rankedFile, 0, len(paths))
+	for _, path := range paths {
+		fileType := cache.fileType(path)
+		if allowed != nil && !allowed[fileType] {
+			continue
+		}
+		ranked = append(ranked, rankedFile{path: path, rank: rank(fileType)})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].rank < ranked[j].rank })
+	prioritized := make([]string, 0, len(ranked))
+	for _, file := range ranked {
+		prioritized = append(prioritized, file.path)
+	}
+	return prioritized
+}
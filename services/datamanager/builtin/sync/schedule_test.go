@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestSyncWindows(t *testing.T) {
+	// 2024-01-06 is a saturday
+	at := func(day int, clock string) time.Time {
+		tod, err := parseTimeOfDay(clock)
+		test.That(t, err, test.ShouldBeNil)
+		return time.Date(2024, 1, day, 0, 0, 0, 0, time.Local).Add(tod)
+	}
+	workdays := SyncWindow{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:30"}
+	nights := SyncWindow{Days: []string{"Fri"}, Start: "22:00", End: "06:00", FileTypes: []string{FileTypeBinary}}
+	test.That(t, workdays.Validate(), test.ShouldBeNil)
+	test.That(t, nights.Validate(), test.ShouldBeNil)
+
+	test.That(t, workdays.contains(at(5, "09:00")), test.ShouldBeTrue)
+	test.That(t, workdays.contains(at(5, "17:29")), test.ShouldBeTrue)
+	test.That(t, workdays.contains(at(5, "17:30")), test.ShouldBeFalse)
+	test.That(t, workdays.contains(at(5, "08:59")), test.ShouldBeFalse)
+	test.That(t, workdays.contains(at(6, "12:00")), test.ShouldBeFalse)
+
+	// the friday night window runs into saturday morning
+	test.That(t, nights.contains(at(5, "23:00")), test.ShouldBeTrue)
+	test.That(t, nights.contains(at(6, "05:59")), test.ShouldBeTrue)
+	test.That(t, nights.contains(at(6, "06:00")), test.ShouldBeFalse)
+	test.That(t, nights.contains(at(5, "05:00")), test.ShouldBeFalse)
+	test.That(t, nights.contains(at(6, "23:00")), test.ShouldBeFalse)
+
+	allowed, open := allowedFileTypes(nil, at(6, "12:00"))
+	test.That(t, open, test.ShouldBeTrue)
+	test.That(t, allowed, test.ShouldBeNil)
+	windows := []SyncWindow{workdays, nights, {Start: "00:00", End: "23:59", FileTypes: []string{FileTypeTabular}}}
+	allowed, open = allowedFileTypes(windows, at(5, "12:00"))
+	test.That(t, open, test.ShouldBeTrue)
+	test.That(t, allowed, test.ShouldBeNil)
+	allowed, open = allowedFileTypes(windows, at(5, "23:00"))
+	test.That(t, open, test.ShouldBeTrue)
+	test.That(t, allowed, test.ShouldResemble, map[string]bool{FileTypeBinary: true, FileTypeTabular: true})
+	allowed, open = allowedFileTypes(windows, at(6, "12:00"))
+	test.That(t, open, test.ShouldBeTrue)
+	test.That(t, allowed, test.ShouldResemble, map[string]bool{FileTypeTabular: true})
+	_, open = allowedFileTypes(windows[:2], at(6, "12:00"))
+	test.That(t, open, test.ShouldBeFalse)
+
+	test.That(t, SyncWindow{Days: []string{"someday"}, Start: "09:00", End: "10:00"}.Validate(), test.ShouldNotBeNil)
+	test.That(t, SyncWindow{Start: "9am", End: "10:00"}.Validate(), test.ShouldNotBeNil)
+	test.That(t, SyncWindow{Start: "09:00", End: "24:00"}.Validate(), test.ShouldNotBeNil)
+	test.That(t, SyncWindow{Start: "09:00", End: "10:00", FileTypes: []string{"images"}}.Validate(), test.ShouldNotBeNil)
+	test.That(t, ValidateSyncPriority([]string{FileTypeTabular, "images"}), test.ShouldNotBeNil)
+}
+
+func TestPrioritizeFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, md *v1.DataCaptureMetadata) string {
+		path := filepath.Join(dir, name)
+		//nolint:gosec
+		f, err := os.Create(path)
+		test.That(t, err, test.ShouldBeNil)
+		if md != nil {
+			_, err = pbutil.WriteDelimited(f, md)
+			test.That(t, err, test.ShouldBeNil)
+		}
+		test.That(t, f.Close(), test.ShouldBeNil)
+		return path
+	}
+	image1 := writeFile("image1.capture", &v1.DataCaptureMetadata{Type: v1.DataType_DATA_TYPE_BINARY_SENSOR})
+	arbitrary := writeFile("notes.txt", nil)
+	readings1 := writeFile("readings1.capture", &v1.DataCaptureMetadata{Type: v1.DataType_DATA_TYPE_TABULAR_SENSOR})
+	image2 := writeFile("image2.capture", &v1.DataCaptureMetadata{Type: v1.DataType_DATA_TYPE_BINARY_SENSOR})
+	readings2 := writeFile("readings2.capture", &v1.DataCaptureMetadata{Type: v1.DataType_DATA_TYPE_TABULAR_SENSOR})
+	paths := []string{image1, arbitrary, readings1, image2, readings2}
+
+	cache := newFileTypeCache()
+	test.That(t, prioritizeFiles(paths, nil, nil, cache), test.ShouldResemble, paths)
+	test.That(t, prioritizeFiles(paths, []string{FileTypeTabular}, nil, cache), test.ShouldResemble,
+		[]string{readings1, readings2, image1, arbitrary, image2})
+	test.That(t, prioritizeFiles(paths, []string{FileTypeArbitrary, FileTypeTabular, FileTypeBinary}, nil, cache), test.ShouldResemble,
+		[]string{arbitrary, readings1, readings2, image1, image2})
+	test.That(t, prioritizeFiles(paths, nil, map[string]bool{FileTypeBinary: true}, cache), test.ShouldResemble,
+		[]string{image1, image2})
+
+	// the types of the capture files are cached, so they are not read again
+	test.That(t, cache.types, test.ShouldHaveLength, 4)
+	test.That(t, os.Remove(image1), test.ShouldBeNil)
+	test.That(t, cache.fileType(image1), test.ShouldEqual, FileTypeBinary)
+	cache.retain([]string{readings1, image2})
+	test.That(t, cache.types, test.ShouldHaveLength, 2)
+	test.That(t, cache.fileType(image1), test.ShouldEqual, FileTypeTabular)
+}
+
+// steppingClock moves forward by step every time the time is read.
+type steppingClock struct {
+	*clock.Mock
+	step time.Duration
+}
+
+func (c steppingClock) Now() time.Time {
+	c.Add(c.step)
+	return c.Mock.Now()
+}
+
+func TestSyncWindowClosesWhileSending(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.capture", "b.capture", "c.capture"} {
+		test.That(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600), test.ShouldBeNil)
+	}
+	// the window closes at 17:30, on the third time the clock is read
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 1, 5, 17, 29, 0, 0, time.Local))
+	s := New(nil, func() {}, steppingClock{Mock: mockClock, step: 20 * time.Second}, logging.NewTestLogger(t))
+	windows := []SyncWindow{{Start: "09:00", End: "17:30"}}
+
+	var sent []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for path := range s.filesToSync {
+			sent = append(sent, path)
+		}
+	}()
+	err := s.walkDirsAndSendFilesToSync(context.Background(), Config{CaptureDir: dir}, windows)
+	test.That(t, err, test.ShouldBeNil)
+	close(s.filesToSync)
+	<-done
+	test.That(t, sent, test.ShouldResemble, []string{filepath.Join(dir, "a.capture")})
+}
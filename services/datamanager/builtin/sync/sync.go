@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
@@ -19,6 +20,7 @@ import (
 	v1 "go.viam.com/api/app/datasync/v1"
 	goutils "go.viam.com/utils"
 	"go.viam.com/utils/rpc"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 
@@ -102,11 +104,14 @@ type Sync struct {
 	workersWg         sync.WaitGroup
 	flushCollectors   func()
 	fileTracker       *fileTracker
+	fileTypes         *fileTypeCache
 	filesToSync       chan string
 	clientConstructor func(cc grpc.ClientConnInterface) v1.DataSyncServiceClient
 	clock             clock.Clock
 	uploadStats       *uploadStats
 	deletedFileCount  atomic.Int64
+	// uploadLimiter limits the bandwidth of the uploads of all workers to MaximumUploadBytesPerSec
+	uploadLimiter *rate.Limiter
 
 	configMu sync.Mutex
 	config   Config
@@ -140,12 +145,14 @@ func New(
 		clientConstructor:   clientConstructor,
 		logger:              logger,
 		fileTracker:         newFileTracker(),
+		fileTypes:           newFileTypeCache(),
 		filesToSync:         make(chan string),
 		flushCollectors:     flushCollectors,
 		Scheduler:           goutils.NewBackgroundStoppableWorkers(),
 		cloudConn:           cloudConn{ready: make(chan struct{})},
 		FileDeletingWorkers: goutils.NewBackgroundStoppableWorkers(),
 		uploadStats:         &uploadStats,
+		uploadLimiter:       rate.NewLimiter(rate.Inf, 0),
 	}
 	return &s
 }
@@ -188,6 +195,8 @@ func (s *Sync) Reconfigure(_ context.Context, config Config, cloudConnSvc cloud.
 	s.configMu.Lock()
 	s.config = config
 	s.configMu.Unlock()
+	s.uploadLimiter.SetLimit(uploadLimit(config.MaximumUploadBytesPerSec))
+	s.uploadLimiter.SetBurst(uploadBurst(config.MaximumUploadBytesPerSec))
 	// reset config context
 	s.configCtx, s.configCancelFunc = context.WithCancel(context.Background())
 
@@ -218,6 +227,7 @@ func (s *Sync) Reconfigure(_ context.Context, config Config, cloudConnSvc cloud.
 				s.fileTracker,
 				config.CaptureDir,
 				config.DeleteEveryNthWhenDiskFull,
+				config.RetentionPolicy,
 				config.DiskUsageDeletionThreshold,
 				config.CaptureDirDeletionThreshold,
 				s.clock,
@@ -286,7 +296,7 @@ func (s *Sync) Sync(ctx context.Context, _ map[string]interface{}) error {
 	s.configMu.Lock()
 	config := s.config
 	s.configMu.Unlock()
-	return s.walkDirsAndSendFilesToSync(ctx, config)
+	return s.walkDirsAndSendFilesToSync(ctx, config, nil)
 }
 
 type cloudConn struct {
@@ -357,7 +367,7 @@ func (s *Sync) runCloudConnManager(
 			return
 		}
 		s.cloudConn.conn = checker
-		s.cloudConn.client = s.clientConstructor(conn)
+		s.cloudConn.client = rateLimitedClient{DataSyncServiceClient: s.clientConstructor(conn), limiter: s.uploadLimiter}
 		s.logger.Info("cloud connection ready")
 		close(s.cloudConn.ready)
 		// now that we have a connection ...
@@ -457,6 +467,14 @@ func (s *Sync) syncDataCaptureFile(f *os.File, captureDir string, logger logging
 		s.uploadStats.tabular.uploadFailedFileCount.Add(1)
 		return
 	}
+	if err := captureFile.CanDecrypt(); err != nil {
+		// leave the file in place, so that it is synced once its key is configured
+		logger.Errorw("unable to sync encrypted capture file", "error", err)
+		if err := f.Close(); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to close file %s", f.Name()).Error())
+		}
+		return
+	}
 	isBinary := captureFile.ReadMetadata().GetType() == v1.DataType_DATA_TYPE_BINARY_SENSOR
 
 	// Include counter for binary sensor data because larger binary data files are uploaded via our streaming API, so updating
@@ -661,8 +679,12 @@ func (s *Sync) runScheduler(ctx context.Context, tkr *clock.Ticker, config Confi
 				s.logger.Info("data manager: NOT syncing data to the cloud as it's selective sync sensor is not ready to sync")
 				continue
 			}
+			if _, inWindow := allowedFileTypes(config.SyncWindows, s.clock.Now()); !inWindow {
+				s.logger.Debug("data manager: NOT syncing data to the cloud as it is outside of the sync windows")
+				continue
+			}
 
-			if err := s.walkDirsAndSendFilesToSync(ctx, config); err != nil && !errors.Is(err, context.Canceled) {
+			if err := s.walkDirsAndSendFilesToSync(ctx, config, config.SyncWindows); err != nil && !errors.Is(err, context.Canceled) {
 				goutils.UncheckedError(err)
 			}
 		}
@@ -670,10 +692,14 @@ func (s *Sync) runScheduler(ctx context.Context, tkr *clock.Ticker, config Confi
 }
 
 // returns early with an error if either ctx is cancelled or if the reconfigure is called
-// while walkDirsAndSendFilesToSync.
-func (s *Sync) walkDirsAndSendFilesToSync(ctx context.Context, config Config) error {
+// while walkDirsAndSendFilesToSync. Files are sent to sync in the order of config.SyncPriority. When there are sync
+// windows, only the files of the types which the windows allow are sent, and sending stops once the windows close.
+// Without a priority or windows, files are sent as they are walked rather than once every directory was walked.
+func (s *Sync) walkDirsAndSendFilesToSync(ctx context.Context, config Config, windows []SyncWindow) error {
 	s.flushCollectors()
+	prioritized := len(config.SyncPriority) > 0 || len(windows) > 0
 	var errs []error
+	var paths []string
 	for _, dir := range config.SyncPaths() {
 		s.logger.Debugf("syncing from: %s", dir)
 		loggedDirPaths := map[string]bool{}
@@ -714,12 +740,36 @@ func (s *Sync) walkDirsAndSendFilesToSync(ctx context.Context, config Config) er
 					loggedDirPaths[dirPath] = true
 					s.logger.Debugf("syncing from subdirectory: %s", dirPath)
 				}
-				s.sendToSync(ctx, path)
+				if !prioritized {
+					s.sendToSync(ctx, path)
+					return nil
+				}
+				paths = append(paths, path)
 			}
 			return nil
 		})
 		errs = append(errs, err)
 	}
+	if !prioritized {
+		errs = append(errs, ctx.Err(), s.configCtx.Err())
+		return multierr.Combine(errs...)
+	}
+	s.fileTypes.retain(paths)
+	allowedTypes, inWindow := allowedFileTypes(windows, s.clock.Now())
+	if inWindow {
+		for _, path := range prioritizeFiles(paths, config.SyncPriority, allowedTypes, s.fileTypes) {
+			// sending waits for a free worker, during which the windows may close or allow other types of files
+			nowAllowed, open := allowedFileTypes(windows, s.clock.Now())
+			if !open {
+				s.logger.Debug("data manager: stopped syncing data to the cloud as the sync windows closed")
+				break
+			}
+			if !maps.Equal(nowAllowed, allowedTypes) && nowAllowed != nil && !nowAllowed[s.fileTypes.fileType(path)] {
+				continue
+			}
+			s.sendToSync(ctx, path)
+		}
+	}
 	errs = append(errs, ctx.Err(), s.configCtx.Err())
 	return multierr.Combine(errs...)
 }
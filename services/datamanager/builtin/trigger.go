@@ -0,0 +1,199 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager"
+	"go.viam.com/rdk/services/datamanager/builtin/capture"
+	"go.viam.com/rdk/services/vision"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// triggerCommand is the DoCommand which triggers collectors in trigger mode. Its value may limit the collectors
+// triggered to those of a "resource_name" and "method".
+const triggerCommand = "trigger"
+
+// captureTrigger is a sensor or vision trigger of a collector in trigger mode.
+type captureTrigger struct {
+	resourceName string
+	method       string
+	// fired returns whether the trigger fires.
+	fired func(ctx context.Context) (bool, error)
+}
+
+// captureTriggersFromDeps resolves the sensor and vision triggers of the collector configs from dependencies.
+// Triggers which cannot be resolved are logged and skipped.
+func captureTriggersFromDeps(
+	collectorConfigsByResource capture.CollectorConfigsByResource,
+	deps resource.Dependencies,
+	logger logging.Logger,
+) []captureTrigger {
+	var triggers []captureTrigger
+	for _, cfgs := range collectorConfigsByResource {
+		for _, cfg := range cfgs {
+			if cfg.Trigger == nil || cfg.Disabled {
+				continue
+			}
+			key := capture.DataCaptureConfigKey(cfg.Name.ShortName(), cfg.Method)
+			if st := cfg.Trigger.Sensor; st != nil {
+				s, err := sensor.FromProvider(deps, st.Name)
+				if err != nil {
+					logger.Errorw("unable to initialize trigger sensor; collector will only be triggered by DoCommand",
+						"collector", key, "error", err.Error())
+				} else {
+					triggers = append(triggers, captureTrigger{
+						resourceName: cfg.Name.ShortName(),
+						method:       cfg.Method,
+						fired:        sensorTriggerFunc(s, *st),
+					})
+				}
+			}
+			if vt := cfg.Trigger.Vision; vt != nil {
+				svc, err := vision.FromProvider(deps, vt.Name)
+				if err != nil {
+					logger.Errorw("unable to initialize trigger vision service; collector will only be triggered by DoCommand",
+						"collector", key, "error", err.Error())
+				} else {
+					triggers = append(triggers, captureTrigger{
+						resourceName: cfg.Name.ShortName(),
+						method:       cfg.Method,
+						fired:        visionTriggerFunc(svc, *vt),
+					})
+				}
+			}
+		}
+	}
+	return triggers
+}
+
+func sensorTriggerFunc(s sensor.Sensor, st datamanager.SensorTrigger) func(ctx context.Context) (bool, error) {
+	return func(ctx context.Context) (bool, error) {
+		readings, err := s.Readings(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		reading, err := rutils.ReadingValue(readings, st.Field)
+		if err != nil {
+			return false, err
+		}
+		return st.Compare(reading)
+	}
+}
+
+func visionTriggerFunc(svc vision.Service, vt datamanager.VisionTrigger) func(ctx context.Context) (bool, error) {
+	return func(ctx context.Context) (bool, error) {
+		detections, err := svc.DetectionsFromCamera(ctx, vt.Camera, nil)
+		if err != nil {
+			return false, err
+		}
+		for _, detection := range detections {
+			if detection.Score() < vt.MinConfidence {
+				continue
+			}
+			if len(vt.Labels) == 0 || slices.Contains(vt.Labels, detection.Label()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+func (b *builtIn) startTriggerPoller(triggers []captureTrigger) {
+	if b.triggerPoller != nil {
+		b.logger.Warn("trigger poller already running")
+		return
+	}
+
+	b.triggerPoller = goutils.NewBackgroundStoppableWorkers(
+		func(ctx context.Context) {
+			b.runTriggerPoller(ctx, triggers)
+		},
+	)
+}
+
+// stopTriggerPoller should be called before other calls to acquire b.mu, like stopCaptureControlPoller.
+func (b *builtIn) stopTriggerPoller() {
+	b.mu.Lock()
+	oldPoller := b.triggerPoller
+	b.triggerPoller = nil
+	b.mu.Unlock()
+	if oldPoller != nil {
+		oldPoller.Stop()
+	}
+}
+
+// runTriggerPoller checks the sensor and vision triggers at 10 Hz, and triggers their collectors whenever they fire.
+// A trigger which keeps firing keeps extending the post trigger window of its collector.
+func (b *builtIn) runTriggerPoller(ctx context.Context, triggers []captureTrigger) {
+	ticker := time.NewTicker(capturePollFrequency)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, trigger := range triggers {
+			fired, err := trigger.fired(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			key := capture.DataCaptureConfigKey(trigger.resourceName, trigger.method)
+			if err != nil {
+				b.logger.Warnw("failed to check capture trigger", "collector", key, "error", err.Error())
+				continue
+			}
+			if !fired {
+				continue
+			}
+			b.logger.Debugw("capture trigger fired", "collector", key)
+			if _, err := b.capture.Trigger(trigger.resourceName, trigger.method); err != nil {
+				b.logger.Warnw("failed to write triggered captures", "collector", key, "error", err.Error())
+			}
+		}
+	}
+}
+
+// doTrigger triggers collectors in trigger mode with {"trigger": {"resource_name": ..., "method": ...}}, where
+// leaving out the resource name or method triggers the collectors of every resource or method.
+func (b *builtIn) doTrigger(raw interface{}) (map[string]interface{}, error) {
+	var resourceName, method string
+	var ok bool
+	switch args := raw.(type) {
+	case map[string]interface{}:
+		if resourceName, ok = stringArg(args, "resource_name"); !ok {
+			return nil, errors.New("trigger resource_name must be a string")
+		}
+		if method, ok = stringArg(args, "method"); !ok {
+			return nil, errors.New("trigger method must be a string")
+		}
+	case bool, nil:
+	default:
+		return nil, fmt.Errorf("trigger must be an object, got %T", raw)
+	}
+	triggered, err := b.capture.Trigger(resourceName, method)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"triggered": triggered}, nil
+}
+
+// stringArg returns the string at the key of the args, which may be missing.
+func stringArg(args map[string]interface{}, key string) (string, bool) {
+	raw, ok := args[key]
+	if !ok {
+		return "", true
+	}
+	s, ok := raw.(string)
+	return s, ok
+}
@@ -0,0 +1,117 @@
+package builtin
+
+import (
+	"context"
+	"image"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/datamanager"
+	datasync "go.viam.com/rdk/services/datamanager/builtin/sync"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+func TestCaptureTriggers(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	captureDir := t.TempDir()
+
+	var temperature, personScore atomic.Value
+	temperature.Store(20.0)
+	personScore.Store(0.3)
+	thermometer := inject.NewSensor("thermometer")
+	thermometer.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"temperature": map[string]interface{}{"celsius": temperature.Load()}}, nil
+	}
+	detector := inject.NewVisionService("detector")
+	detector.DetectionsFromCameraFunc = func(
+		ctx context.Context, cameraName string, extra map[string]interface{},
+	) ([]objectdetection.Detection, error) {
+		box := image.Rect(0, 0, 10, 10)
+		return []objectdetection.Detection{
+			objectdetection.NewDetectionWithoutImgBounds(box, 0.9, "cat"),
+			objectdetection.NewDetectionWithoutImgBounds(box, personScore.Load().(float64), "person"),
+		}, nil
+	}
+
+	r := setupRobot(nil, map[resource.Name]resource.Resource{
+		arm.Named("arm1"): &inject.Arm{
+			EndPositionFunc: func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+				return spatialmath.NewZeroPose(), nil
+			},
+		},
+	})
+	config, deps := setupConfig(t, r, enabledTabularCollectorConfigPath)
+	deps[sensor.Named("thermometer")] = thermometer
+	deps[vision.Named("detector")] = detector
+	assocConfig := config.AssociatedAttributes[arm.Named("arm1")].(*datamanager.AssociatedConfig)
+	assocConfig.CaptureMethods[0].Trigger = &datamanager.CaptureTriggerConfig{
+		PreTriggerSecs:  0.5,
+		PostTriggerSecs: 0.2,
+		Sensor:          &datamanager.SensorTrigger{Name: "thermometer", Field: "temperature.celsius", Operator: ">", Value: 50},
+		Vision:          &datamanager.VisionTrigger{Name: "detector", Camera: "cam", Labels: []string{"person"}, MinConfidence: 0.5},
+	}
+	c := config.ConvertedAttributes.(*Config)
+	c.CaptureDir = captureDir
+	c.ScheduledSyncDisabled = true
+	// MaximumCaptureFileSizeBytes is set to 1 so that each reading becomes its own capture file
+	c.MaximumCaptureFileSizeBytes = 1
+
+	b, err := New(ctx, deps, config, datasync.NoOpCloudClientConstructor, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() { test.That(t, b.Close(ctx), test.ShouldBeNil) }()
+
+	// waitForTriggeredCaptures waits for the post trigger window to pass and returns the number of capture files,
+	// after checking that no more are written.
+	waitForTriggeredCaptures := func() int {
+		t.Helper()
+		time.Sleep(500 * time.Millisecond)
+		n := len(getAllFileInfos(captureDir))
+		time.Sleep(300 * time.Millisecond)
+		test.That(t, len(getAllFileInfos(captureDir)), test.ShouldEqual, n)
+		return n
+	}
+
+	// nothing is written until a trigger
+	test.That(t, waitForTriggeredCaptures(), test.ShouldEqual, 0)
+
+	resp, err := b.DoCommand(ctx, map[string]interface{}{"trigger": map[string]interface{}{"resource_name": "arm2"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"triggered": 0})
+	test.That(t, waitForTriggeredCaptures(), test.ShouldEqual, 0)
+
+	resp, err = b.DoCommand(ctx, map[string]interface{}{"trigger": map[string]interface{}{"resource_name": "arm1", "method": "EndPosition"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"triggered": 1})
+	// the captures of the half second before the trigger and the fifth of a second after it, at 100 Hz
+	triggered := waitForTriggeredCaptures()
+	test.That(t, triggered, test.ShouldBeBetween, 20, 80)
+
+	temperature.Store(60.0)
+	waitForCaptureFilesToExceedNFiles(captureDir, triggered, logger)
+	temperature.Store(20.0)
+	triggered = waitForTriggeredCaptures()
+
+	personScore.Store(0.8)
+	waitForCaptureFilesToExceedNFiles(captureDir, triggered, logger)
+	personScore.Store(0.3)
+	waitForTriggeredCaptures()
+
+	_, err = b.DoCommand(ctx, map[string]interface{}{"other": true})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+	_, err = b.DoCommand(ctx, map[string]interface{}{"trigger": map[string]interface{}{"method": 1}})
+	test.That(t, err, test.ShouldNotBeNil)
+	resp, err = b.DoCommand(ctx, map[string]interface{}{"trigger": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"triggered": 1})
+}
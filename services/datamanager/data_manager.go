@@ -7,6 +7,8 @@ package datamanager
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"image"
 	"reflect"
 	"slices"
@@ -169,6 +171,10 @@ type DataCaptureConfig struct {
 	Disabled           bool                   `json:"disabled"`
 	Tags               []string               `json:"tags,omitempty"`
 	CaptureDirectory   string                 `json:"capture_directory"`
+	// Trigger when set only writes the captures around each trigger to disk.
+	Trigger *CaptureTriggerConfig `json:"trigger,omitempty"`
+	// Deadband when set only writes the tabular captures which changed since the last written one.
+	Deadband *CaptureDeadbandConfig `json:"deadband,omitempty"`
 }
 
 // Equals checks if one capture config is equal to another.
@@ -181,7 +187,115 @@ func (c *DataCaptureConfig) Equals(other *DataCaptureConfig) bool {
 		c.Disabled == other.Disabled &&
 		slices.Compare(c.Tags, other.Tags) == 0 &&
 		reflect.DeepEqual(c.AdditionalParams, other.AdditionalParams) &&
-		c.CaptureDirectory == other.CaptureDirectory
+		c.CaptureDirectory == other.CaptureDirectory &&
+		reflect.DeepEqual(c.Trigger, other.Trigger) &&
+		reflect.DeepEqual(c.Deadband, other.Deadband)
+}
+
+// CaptureTriggerConfig puts a collector in trigger mode, in which it keeps its captures of the last PreTriggerSecs in
+// memory and only writes them to disk, along with its captures of the following PostTriggerSecs, when triggered.
+// Collectors in trigger mode can always be triggered with the "trigger" DoCommand of the data manager, and also
+// whenever the optional sensor or vision trigger fires.
+type CaptureTriggerConfig struct {
+	PreTriggerSecs  float64        `json:"pre_trigger_secs"`
+	PostTriggerSecs float64        `json:"post_trigger_secs"`
+	Sensor          *SensorTrigger `json:"sensor,omitempty"`
+	Vision          *VisionTrigger `json:"vision,omitempty"`
+}
+
+// SensorTrigger fires while a field of the readings of a sensor compares to a value.
+type SensorTrigger struct {
+	Name string `json:"name"`
+	// Field is the key of the reading to compare. Nested readings are separated by dots, like "position.x".
+	Field string `json:"field"`
+	// Operator is one of ">", ">=", "<", "<=", "==" or "!=".
+	Operator string  `json:"operator"`
+	Value    float64 `json:"value"`
+}
+
+// VisionTrigger fires while a vision service detects one of the labels in the images of a camera.
+type VisionTrigger struct {
+	Name   string `json:"name"`
+	Camera string `json:"camera"`
+	// Labels are the labels which fire the trigger. When empty any detection fires the trigger.
+	Labels        []string `json:"labels,omitempty"`
+	MinConfidence float64  `json:"min_confidence,omitempty"`
+}
+
+// Validate returns an error if the trigger config is invalid.
+func (tc *CaptureTriggerConfig) Validate() error {
+	if tc == nil {
+		return nil
+	}
+	if tc.PreTriggerSecs < 0 {
+		return fmt.Errorf("trigger pre_trigger_secs can't be negative, current value: %f", tc.PreTriggerSecs)
+	}
+	if tc.PostTriggerSecs < 0 {
+		return fmt.Errorf("trigger post_trigger_secs can't be negative, current value: %f", tc.PostTriggerSecs)
+	}
+	if tc.PreTriggerSecs == 0 && tc.PostTriggerSecs == 0 {
+		return errors.New("trigger must set pre_trigger_secs or post_trigger_secs")
+	}
+	if tc.Sensor != nil {
+		if tc.Sensor.Name == "" || tc.Sensor.Field == "" {
+			return errors.New("trigger sensor must set name and field")
+		}
+		if _, err := tc.Sensor.Compare(0); err != nil {
+			return err
+		}
+	}
+	if tc.Vision != nil && (tc.Vision.Name == "" || tc.Vision.Camera == "") {
+		return errors.New("trigger vision must set name and camera")
+	}
+	return nil
+}
+
+// Compare returns whether the reading satisfies the comparison of the trigger.
+func (st *SensorTrigger) Compare(reading float64) (bool, error) {
+	met, err := utils.CompareReading(reading, st.Operator, st.Value)
+	if err != nil {
+		return false, fmt.Errorf("unknown trigger sensor operator %q", st.Operator)
+	}
+	return met, nil
+}
+
+// CaptureDeadbandConfig compresses tabular captures by dropping those in which no field changed since the last written
+// capture. A numeric field changes when it moves by more than the larger of its absolute and relative deadband, the
+// latter being a fraction of its last written value. Any other field changes whenever it differs. MaxSilenceSecs, when
+// set, writes a capture as a heartbeat once that long has passed since the last written one.
+type CaptureDeadbandConfig struct {
+	// Absolute and Relative are the deadband of the fields which are not in Fields. Both zero only drops duplicates.
+	Absolute float64 `json:"absolute,omitempty"`
+	Relative float64 `json:"relative,omitempty"`
+	// Fields are the deadbands of fields by their path in the captured data, with nested keys and list indexes
+	// separated by dots, like "readings.power" or "pose.x".
+	Fields         map[string]DeadbandField `json:"fields,omitempty"`
+	MaxSilenceSecs float64                  `json:"max_silence_secs,omitempty"`
+}
+
+// DeadbandField is the deadband of a field of a capture.
+type DeadbandField struct {
+	Absolute float64 `json:"absolute,omitempty"`
+	Relative float64 `json:"relative,omitempty"`
+}
+
+// Validate returns an error if the deadband config is invalid.
+func (dc *CaptureDeadbandConfig) Validate() error {
+	if dc == nil {
+		return nil
+	}
+	if dc.Absolute < 0 || dc.Relative < 0 {
+		return errors.New("deadband absolute and relative can't be negative")
+	}
+	for path, field := range dc.Fields {
+		if field.Absolute < 0 || field.Relative < 0 {
+			return fmt.Errorf("deadband absolute and relative of field %q can't be negative", path)
+		}
+	}
+	if dc.MaxSilenceSecs < 0 {
+		return fmt.Errorf("deadband max_silence_secs can't be negative, current value: %f", dc.MaxSilenceSecs)
+	}
+	return nil
 }
 
 // ShouldSyncKey is a special key we use within a modular sensor to pass a boolean
@@ -165,6 +165,26 @@ func TestDataCaptureConfig(t *testing.T) {
 			},
 			equal: false,
 		},
+		{
+			name: "different Triggers are not equal",
+			a: &DataCaptureConfig{
+				Trigger: &CaptureTriggerConfig{PreTriggerSecs: 5},
+			},
+			b: &DataCaptureConfig{
+				Trigger: &CaptureTriggerConfig{PreTriggerSecs: 10},
+			},
+			equal: false,
+		},
+		{
+			name: "different Deadbands are not equal",
+			a: &DataCaptureConfig{
+				Deadband: &CaptureDeadbandConfig{Fields: map[string]DeadbandField{"readings.power": {Absolute: 1}}},
+			},
+			b: &DataCaptureConfig{
+				Deadband: &CaptureDeadbandConfig{Fields: map[string]DeadbandField{"readings.power": {Absolute: 2}}},
+			},
+			equal: false,
+		},
 	}
 
 	for _, tc := range tcs {
@@ -174,3 +194,79 @@ func TestDataCaptureConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestCaptureTriggerConfigValidate(t *testing.T) {
+	var noTrigger *CaptureTriggerConfig
+	test.That(t, noTrigger.Validate(), test.ShouldBeNil)
+
+	valid := &CaptureTriggerConfig{
+		PreTriggerSecs:  10,
+		PostTriggerSecs: 5,
+		Sensor:          &SensorTrigger{Name: "thermometer", Field: "temperature.celsius", Operator: ">=", Value: 80},
+		Vision:          &VisionTrigger{Name: "detector", Camera: "cam", Labels: []string{"person"}},
+	}
+	test.That(t, valid.Validate(), test.ShouldBeNil)
+
+	for _, tc := range []struct {
+		name   string
+		config CaptureTriggerConfig
+		err    string
+	}{
+		{"negative pre trigger window", CaptureTriggerConfig{PreTriggerSecs: -1}, "pre_trigger_secs"},
+		{"negative post trigger window", CaptureTriggerConfig{PostTriggerSecs: -1}, "post_trigger_secs"},
+		{"no window", CaptureTriggerConfig{}, "must set"},
+		{
+			"sensor without field",
+			CaptureTriggerConfig{PreTriggerSecs: 1, Sensor: &SensorTrigger{Name: "thermometer", Operator: ">"}},
+			"name and field",
+		},
+		{
+			"unknown operator",
+			CaptureTriggerConfig{PreTriggerSecs: 1, Sensor: &SensorTrigger{Name: "thermometer", Field: "temp", Operator: "~"}},
+			"operator",
+		},
+		{"vision without camera", CaptureTriggerConfig{PreTriggerSecs: 1, Vision: &VisionTrigger{Name: "detector"}}, "camera"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		})
+	}
+
+	sensorTrigger := SensorTrigger{Operator: "<", Value: 3}
+	met, err := sensorTrigger.Compare(2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, met, test.ShouldBeTrue)
+	met, err = sensorTrigger.Compare(3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, met, test.ShouldBeFalse)
+}
+
+func TestCaptureDeadbandConfigValidate(t *testing.T) {
+	var noDeadband *CaptureDeadbandConfig
+	test.That(t, noDeadband.Validate(), test.ShouldBeNil)
+
+	valid := &CaptureDeadbandConfig{
+		Relative:       0.01,
+		Fields:         map[string]DeadbandField{"readings.power": {Absolute: 0.5}},
+		MaxSilenceSecs: 60,
+	}
+	test.That(t, valid.Validate(), test.ShouldBeNil)
+
+	for _, tc := range []struct {
+		name   string
+		config CaptureDeadbandConfig
+		err    string
+	}{
+		{"negative deadband", CaptureDeadbandConfig{Absolute: -1}, "can't be negative"},
+		{"negative field deadband", CaptureDeadbandConfig{Fields: map[string]DeadbandField{"pose.x": {Relative: -1}}}, "pose.x"},
+		{"negative max silence", CaptureDeadbandConfig{MaxSilenceSecs: -1}, "max_silence_secs"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Package handeye implements a generic service for calibrating the pose of a camera relative to an arm, from
+// the poses of a target seen by a pose tracker at different positions of the arm.
+//
+// The calibration is driven by DoCommand:
+//
+//	{"add_sample": true}  records the current end position of the arm and pose of the target
+//	{"get_samples": true} returns the recorded samples, which can be solved offline by the viam CLI
+//	{"clear": true}       removes the recorded samples
+//	{"solve": true}       returns the frame of the camera, the pose of the target and the residuals
+package handeye
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/posetracker"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/vision/calibration"
+)
+
+// Model is the model of the hand-eye calibration service.
+var Model = resource.DefaultModelFamily.WithModel("hand_eye_calibration")
+
+const (
+	addSampleCommand  = "add_sample"
+	getSamplesCommand = "get_samples"
+	clearCommand      = "clear"
+	solveCommand      = "solve"
+)
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newHandEye,
+	})
+}
+
+// Config specifies the arm and pose tracker to calibrate with, and where the camera is mounted.
+type Config struct {
+	ArmName         string `json:"arm_name"`
+	PoseTrackerName string `json:"pose_tracker_name"`
+	// TargetName is the name of the body of the pose tracker to use as the target.
+	TargetName string `json:"target_name"`
+	// Mode is eye_in_hand for a camera on the arm, or eye_to_hand for a camera fixed relative to the arm.
+	Mode calibration.HandEyeMode `json:"mode"`
+	// CameraFrame is the ID of the solved frame. It defaults to the frame the pose tracker reports poses in.
+	CameraFrame string `json:"camera_frame,omitempty"`
+	// Parent is the parent of the solved frame. It defaults to the arm for eye_in_hand, and to the world for
+	// eye_to_hand, in which case the base of the arm should be at the origin of the world.
+	Parent string `json:"parent,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and returns the arm and pose tracker as dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.ArmName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "arm_name")
+	}
+	if conf.PoseTrackerName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "pose_tracker_name")
+	}
+	if conf.TargetName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "target_name")
+	}
+	if conf.Mode != calibration.EyeInHand && conf.Mode != calibration.EyeToHand {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.Errorf("mode must be %q or %q", calibration.EyeInHand, calibration.EyeToHand))
+	}
+	return []string{conf.ArmName, conf.PoseTrackerName}, nil, nil
+}
+
+type handEye struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	logger  logging.Logger
+	arm     arm.Arm
+	tracker posetracker.PoseTracker
+	conf    *Config
+
+	mu          sync.Mutex
+	samples     []calibration.Sample
+	cameraFrame string
+}
+
+func newHandEye(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	a, err := arm.FromProvider(deps, newConf.ArmName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find arm %q", newConf.ArmName)
+	}
+	tracker, err := posetracker.FromProvider(deps, newConf.PoseTrackerName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find pose tracker %q", newConf.PoseTrackerName)
+	}
+	return &handEye{
+		Named:       conf.ResourceName().AsNamed(),
+		logger:      logger,
+		arm:         a,
+		tracker:     tracker,
+		conf:        newConf,
+		cameraFrame: newConf.CameraFrame,
+	}, nil
+}
+
+// DoCommand runs the commands described in the package documentation.
+func (he *handEye) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch {
+	case cmd[addSampleCommand] != nil:
+		return he.addSample(ctx)
+	case cmd[getSamplesCommand] != nil:
+		he.mu.Lock()
+		defer he.mu.Unlock()
+		samples, err := toJSONValue(he.samples)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"samples": samples}, nil
+	case cmd[clearCommand] != nil:
+		he.mu.Lock()
+		defer he.mu.Unlock()
+		he.samples = nil
+		return map[string]interface{}{}, nil
+	case cmd[solveCommand] != nil:
+		return he.solve()
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+func (he *handEye) addSample(ctx context.Context) (map[string]interface{}, error) {
+	// the target is seen after the arm position is read, so the arm must be still while sampling
+	armPose, err := he.arm.EndPosition(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get the end position of arm %q", he.conf.ArmName)
+	}
+	poses, err := he.tracker.Poses(ctx, []string{he.conf.TargetName}, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get poses from pose tracker %q", he.conf.PoseTrackerName)
+	}
+	target, ok := poses[he.conf.TargetName]
+	if !ok {
+		return nil, errors.Errorf("pose tracker %q cannot see target %q", he.conf.PoseTrackerName, he.conf.TargetName)
+	}
+
+	he.mu.Lock()
+	defer he.mu.Unlock()
+	if he.cameraFrame == "" {
+		he.cameraFrame = target.Parent()
+	}
+	sample := calibration.Sample{ArmPose: armPose, TargetPose: target.Pose()}
+	he.samples = append(he.samples, sample)
+	sampleValue, err := toJSONValue(sample)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"samples": len(he.samples), "sample": sampleValue}, nil
+}
+
+func (he *handEye) solve() (map[string]interface{}, error) {
+	he.mu.Lock()
+	defer he.mu.Unlock()
+	cal, err := calibration.SolveHandEye(he.conf.Mode, he.samples)
+	if err != nil {
+		return nil, err
+	}
+	parent := he.conf.Parent
+	if parent == "" {
+		parent = referenceframe.World
+		if he.conf.Mode == calibration.EyeInHand {
+			parent = he.conf.ArmName
+		}
+	}
+	link, err := cal.LinkConfig(he.cameraFrame, parent)
+	if err != nil {
+		return nil, err
+	}
+	return response(cal, link)
+}
+
+// response returns the frame of the camera, the pose of the target and the residuals of a calibration.
+func response(cal *calibration.HandEyeCalibration, link *referenceframe.LinkConfig) (map[string]interface{}, error) {
+	frame, err := toJSONValue(link)
+	if err != nil {
+		return nil, err
+	}
+	target, err := toJSONValue(spatialmath.PoseToProtobuf(cal.Target))
+	if err != nil {
+		return nil, err
+	}
+	residuals, err := toJSONValue(cal.Residuals)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"frame": frame, "target": target, "residuals": residuals}, nil
+}
+
+// toJSONValue converts a value to the maps, slices and primitives it is marshaled to as JSON.
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,122 @@
+package handeye
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/posetracker"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/calibration"
+)
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{PoseTrackerName: "tags", TargetName: "tag36h11_0", Mode: calibration.EyeInHand}
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "arm_name"))
+
+	conf.ArmName = "arm"
+	conf.Mode = "eye_on_table"
+	_, _, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "eye_in_hand")
+
+	conf.Mode = calibration.EyeToHand
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"arm", "tags"})
+}
+
+func TestHandEye(t *testing.T) {
+	ctx := context.Background()
+	camera := spatialmath.NewPose(r3.Vector{X: 50, Y: -20, Z: 80}, &spatialmath.OrientationVectorDegrees{OY: 1, Theta: 90})
+	target := spatialmath.NewPose(r3.Vector{X: 600, Y: 100}, &spatialmath.OrientationVectorDegrees{OZ: -1, Theta: 10})
+	armPoses := []spatialmath.Pose{
+		spatialmath.NewPose(r3.Vector{X: 300, Z: 400}, &spatialmath.OrientationVectorDegrees{OX: 1}),
+		spatialmath.NewPose(r3.Vector{X: 320, Y: 40, Z: 380}, &spatialmath.OrientationVectorDegrees{OX: 1, OY: 0.2}),
+		spatialmath.NewPose(r3.Vector{X: 280, Y: -30, Z: 420}, &spatialmath.OrientationVectorDegrees{OX: 1, OZ: 0.3, Theta: 20}),
+		spatialmath.NewPose(r3.Vector{X: 310, Y: 10, Z: 390}, &spatialmath.OrientationVectorDegrees{OX: 0.8, OY: -0.2, OZ: -0.3}),
+	}
+
+	current, visible := 0, true
+	fakeArm := inject.NewArm("arm")
+	fakeArm.EndPositionFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+		return armPoses[current], nil
+	}
+	tracker := inject.NewPoseTracker("tags")
+	tracker.PosesFunc = func(
+		ctx context.Context, bodyNames []string, extra map[string]interface{},
+	) (referenceframe.FrameSystemPoses, error) {
+		test.That(t, bodyNames, test.ShouldResemble, []string{"tag36h11_0"})
+		if !visible {
+			return referenceframe.FrameSystemPoses{}, nil
+		}
+		// the target is fixed, and the camera is on the end of the arm
+		seen := spatialmath.PoseBetween(spatialmath.Compose(armPoses[current], camera), target)
+		return referenceframe.FrameSystemPoses{"tag36h11_0": referenceframe.NewPoseInFrame("cam", seen)}, nil
+	}
+	deps := resource.Dependencies{arm.Named("arm"): fakeArm, posetracker.Named("tags"): tracker}
+	conf := resource.Config{
+		Name: "calibration",
+		API:  generic.API,
+		ConvertedAttributes: &Config{
+			ArmName: "arm", PoseTrackerName: "tags", TargetName: "tag36h11_0", Mode: calibration.EyeInHand,
+		},
+	}
+	he, err := newHandEye(ctx, deps, conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = he.DoCommand(ctx, map[string]interface{}{"solve": true})
+	test.That(t, err, test.ShouldNotBeNil)
+	for current = range armPoses {
+		resp, err := he.DoCommand(ctx, map[string]interface{}{"add_sample": true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["samples"], test.ShouldEqual, current+1)
+	}
+	visible = false
+	_, err = he.DoCommand(ctx, map[string]interface{}{"add_sample": true})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot see target")
+
+	resp, err := he.DoCommand(ctx, map[string]interface{}{"get_samples": true})
+	test.That(t, err, test.ShouldBeNil)
+	data, err := json.Marshal(resp["samples"])
+	test.That(t, err, test.ShouldBeNil)
+	var samples []calibration.Sample
+	test.That(t, json.Unmarshal(data, &samples), test.ShouldBeNil)
+	test.That(t, samples, test.ShouldHaveLength, len(armPoses))
+	test.That(t, spatialmath.PoseAlmostEqual(samples[2].ArmPose, armPoses[2]), test.ShouldBeTrue)
+
+	resp, err = he.DoCommand(ctx, map[string]interface{}{"solve": true})
+	test.That(t, err, test.ShouldBeNil)
+	data, err = json.Marshal(resp["frame"])
+	test.That(t, err, test.ShouldBeNil)
+	var link referenceframe.LinkConfig
+	test.That(t, json.Unmarshal(data, &link), test.ShouldBeNil)
+	test.That(t, link.ID, test.ShouldEqual, "cam")
+	test.That(t, link.Parent, test.ShouldEqual, "arm")
+	pose, err := link.Pose()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqualEps(pose, camera, 1e-3), test.ShouldBeTrue)
+	residuals, ok := resp["residuals"].(map[string]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, residuals["translation_max_mm"], test.ShouldAlmostEqual, 0, 1e-3)
+
+	_, err = he.DoCommand(ctx, map[string]interface{}{"clear": true})
+	test.That(t, err, test.ShouldBeNil)
+	resp, err = he.DoCommand(ctx, map[string]interface{}{"get_samples": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["samples"], test.ShouldBeNil)
+
+	_, err = he.DoCommand(ctx, map[string]interface{}{"calibrate": true})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+}
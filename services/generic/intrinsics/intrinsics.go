@@ -0,0 +1,153 @@
+// Package intrinsics implements a generic service for calibrating the intrinsics and distortion of a camera, from
+// images of a calibration board taken by it.
+//
+// The calibration is driven by DoCommand:
+//
+//	{"add_image": true} finds the board in the current image from the camera, and keeps the corners found
+//	{"clear": true}     removes the kept corners
+//	{"solve": true}     returns the intrinsic_parameters and distortion_parameters of the camera and the
+//	                    reprojection error, which can be copied into the config of the camera
+//
+// A charuco board must use markers from the original ArUco dictionary (OpenCV's DICT_ARUCO_ORIGINAL), which is
+// the default for its "dictionary". Boards from other dictionaries, such as DICT_4X4_50, fail validation.
+package intrinsics
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/vision/calibration"
+)
+
+// Model is the model of the intrinsic calibration service.
+var Model = resource.DefaultModelFamily.WithModel("intrinsic_calibration")
+
+const (
+	addImageCommand = "add_image"
+	clearCommand    = "clear"
+	solveCommand    = "solve"
+)
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newIntrinsics,
+	})
+}
+
+// Config specifies the camera to calibrate and the board in its images.
+type Config struct {
+	CameraName string             `json:"camera_name"`
+	Board      *calibration.Board `json:"board"`
+}
+
+// Validate ensures all parts of the config are valid, and returns the camera as a dependency.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.CameraName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "camera_name")
+	}
+	if conf.Board == nil {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	if err := conf.Board.Validate(); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+	return []string{conf.CameraName}, nil, nil
+}
+
+type intrinsics struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	logger logging.Logger
+	cam    camera.Camera
+	conf   *Config
+
+	mu            sync.Mutex
+	views         []*calibration.View
+	width, height int
+}
+
+func newIntrinsics(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	cam, err := camera.FromProvider(deps, newConf.CameraName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find camera %q", newConf.CameraName)
+	}
+	return &intrinsics{
+		Named:  conf.ResourceName().AsNamed(),
+		logger: logger,
+		cam:    cam,
+		conf:   newConf,
+	}, nil
+}
+
+// DoCommand runs the commands described in the package documentation.
+func (in *intrinsics) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch {
+	case cmd[addImageCommand] != nil:
+		return in.addImage(ctx)
+	case cmd[clearCommand] != nil:
+		in.mu.Lock()
+		defer in.mu.Unlock()
+		in.views = nil
+		return map[string]interface{}{}, nil
+	case cmd[solveCommand] != nil:
+		return in.solve()
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+}
+
+func (in *intrinsics) addImage(ctx context.Context) (map[string]interface{}, error) {
+	img, err := camera.DecodeImageFromCamera(ctx, in.cam, nil, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get an image from camera %q", in.conf.CameraName)
+	}
+	view, err := in.conf.Board.FindCorners(img)
+	if err != nil {
+		return nil, err
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if len(in.views) > 0 && (width != in.width || height != in.height) {
+		return nil, errors.Errorf("image is %dx%d, but the images before it are %dx%d", width, height, in.width, in.height)
+	}
+	in.width, in.height = width, height
+	in.views = append(in.views, view)
+	return map[string]interface{}{"images": len(in.views), "corners": len(view.IDs)}, nil
+}
+
+func (in *intrinsics) solve() (map[string]interface{}, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	cal, err := calibration.CalibrateIntrinsics(in.conf.Board, in.views, in.width, in.height)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(cal)
+	if err != nil {
+		return nil, err
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
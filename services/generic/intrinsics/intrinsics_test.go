@@ -0,0 +1,127 @@
+package intrinsics
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/calibration"
+)
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{Board: &calibration.Board{Type: calibration.Checkerboard, Cols: 7, Rows: 5, SquareSize: 30}}
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "camera_name"))
+
+	conf.CameraName = "cam"
+	conf.Board.Cols = 1
+	_, _, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.Board.Cols = 7
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam"})
+}
+
+func TestIntrinsics(t *testing.T) {
+	ctx := context.Background()
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 480, Height: 360, Fx: 400, Fy: 400, Ppx: 242, Ppy: 178}
+	board := &calibration.Board{Type: calibration.Checkerboard, Cols: 7, Rows: 5, SquareSize: 30}
+	const squarePixels = 60
+	texture, err := calibration.RenderBoard(board, squarePixels)
+	test.That(t, err, test.ShouldBeNil)
+
+	// ray trace the board, which is a plane at z = 0 in its own frame, centered in front of the camera
+	render := func(o spatialmath.Orientation) image.Image {
+		center := spatialmath.NewPoseFromPoint(r3.Vector{X: -3.5 * board.SquareSize, Y: -2.5 * board.SquareSize})
+		inv := spatialmath.PoseInverse(spatialmath.Compose(spatialmath.NewPose(r3.Vector{Z: 250}, o), center))
+		img := image.NewGray(image.Rect(0, 0, intrinsics.Width, intrinsics.Height))
+		for y := 0; y < intrinsics.Height; y++ {
+			for x := 0; x < intrinsics.Width; x++ {
+				sum := 0
+				for _, d := range [][2]float64{{0.25, 0.25}, {0.75, 0.25}, {0.25, 0.75}, {0.75, 0.75}} {
+					dir := r3.Vector{X: (float64(x) + d[0] - intrinsics.Ppx) / intrinsics.Fx, Y: (float64(y) + d[1] - intrinsics.Ppy) / intrinsics.Fy, Z: 1}
+					origin := inv.Point()
+					ray := spatialmath.Compose(inv, spatialmath.NewPoseFromPoint(dir)).Point().Sub(origin)
+					hit := origin.Add(ray.Mul(-origin.Z / ray.Z))
+					pt := image.Pt(int((hit.X/board.SquareSize+1)*squarePixels), int((hit.Y/board.SquareSize+1)*squarePixels))
+					if pt.In(texture.Bounds()) {
+						sum += int(texture.GrayAt(pt.X, pt.Y).Y)
+					} else {
+						sum += 128
+					}
+				}
+				img.SetGray(x, y, color.Gray{Y: uint8(sum / 4)})
+			}
+		}
+		return img
+	}
+	images := []image.Image{
+		render(&spatialmath.OrientationVectorDegrees{OZ: 1}),
+		render(&spatialmath.OrientationVectorDegrees{OX: 0.6, OZ: 1, Theta: 10}),
+		render(&spatialmath.OrientationVectorDegrees{OY: -0.7, OZ: 1, Theta: -5}),
+		render(&spatialmath.OrientationVectorDegrees{OX: -0.4, OY: 0.5, OZ: 1, Theta: 30}),
+		image.NewGray(image.Rect(0, 0, intrinsics.Width, intrinsics.Height)),
+	}
+
+	current := 0
+	cam := inject.NewCamera("cam")
+	cam.ImagesFunc = func(
+		ctx context.Context, filterSourceNames []string, extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		namedImg, err := camera.NamedImageFromImage(images[current], "color", utils.MimeTypePNG, data.Annotations{})
+		return []camera.NamedImage{namedImg}, resource.ResponseMetadata{}, err
+	}
+	deps := resource.Dependencies{camera.Named("cam"): cam}
+	conf := resource.Config{
+		Name:                "calibration",
+		API:                 generic.API,
+		ConvertedAttributes: &Config{CameraName: "cam", Board: board},
+	}
+	in, err := newIntrinsics(ctx, deps, conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = in.DoCommand(ctx, map[string]interface{}{"solve": true})
+	test.That(t, err, test.ShouldNotBeNil)
+	for current = 0; current < 4; current++ {
+		resp, err := in.DoCommand(ctx, map[string]interface{}{"add_image": true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["images"], test.ShouldEqual, current+1)
+		test.That(t, resp["corners"], test.ShouldEqual, 24)
+	}
+	// the board is not in the last image
+	_, err = in.DoCommand(ctx, map[string]interface{}{"add_image": true})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	resp, err := in.DoCommand(ctx, map[string]interface{}{"solve": true})
+	test.That(t, err, test.ShouldBeNil)
+	params, ok := resp["intrinsic_parameters"].(map[string]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, params["width_px"], test.ShouldEqual, 480)
+	test.That(t, params["fx"], test.ShouldAlmostEqual, intrinsics.Fx, 0.02*intrinsics.Fx)
+	test.That(t, params["ppy"], test.ShouldAlmostEqual, intrinsics.Ppy, 5)
+	test.That(t, resp, test.ShouldContainKey, "distortion_parameters")
+	test.That(t, resp["reprojection_error_px"], test.ShouldBeLessThan, 0.5)
+
+	_, err = in.DoCommand(ctx, map[string]interface{}{"clear": true})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = in.DoCommand(ctx, map[string]interface{}{"solve": true})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = in.DoCommand(ctx, map[string]interface{}{"calibrate": true})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+}
@@ -0,0 +1,168 @@
+// Package jobs implements a generic service which reports the runs of the jobs of its machine, including the error,
+// attempts and response of each, which the machine status API does not carry.
+//
+// The runs are returned by DoCommand:
+//
+//	{"get_job_statuses": true} returns the statuses of every job under "job_statuses", which JobStatusesFromResponse
+//	                           decodes
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/utils"
+)
+
+// Model is the model of the jobs service.
+var Model = resource.DefaultModelFamily.WithModel("jobs")
+
+const (
+	// GetJobStatusesCommand is the key of the DoCommand which returns the statuses of the jobs of the machine.
+	GetJobStatusesCommand = "get_job_statuses"
+	jobStatusesKey        = "job_statuses"
+)
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, resource.NoNativeConfig]{
+		DeprecatedRobotConstructor: func(
+			ctx context.Context, r any, conf resource.Config, logger logging.Logger,
+		) (resource.Resource, error) {
+			actualR, err := utils.AssertType[robot.Robot](r)
+			if err != nil {
+				return nil, err
+			}
+			return newJobs(conf.ResourceName(), actualR, logger), nil
+		},
+	})
+}
+
+type jobs struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	logger logging.Logger
+	robot  robot.Robot
+}
+
+func newJobs(name resource.Name, r robot.Robot, logger logging.Logger) resource.Resource {
+	return &jobs{Named: name.AsNamed(), logger: logger, robot: r}
+}
+
+// DoCommand runs the commands described in the package documentation.
+func (j *jobs) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd[GetJobStatusesCommand] == nil {
+		return nil, resource.ErrDoUnimplemented
+	}
+	status, err := j.robot.MachineStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{jobStatusesKey: jobStatusesToMap(status.JobStatuses)}, nil
+}
+
+// jobStatusesToMap returns the statuses in a form which can be returned from DoCommand, with times formatted as
+// RFC 3339.
+func jobStatusesToMap(statuses map[string]robot.JobStatus) map[string]interface{} {
+	formatTimes := func(times []time.Time) []interface{} {
+		formatted := make([]interface{}, 0, len(times))
+		for _, t := range times {
+			formatted = append(formatted, t.Format(time.RFC3339Nano))
+		}
+		return formatted
+	}
+	m := make(map[string]interface{}, len(statuses))
+	for name, status := range statuses {
+		runs := make([]interface{}, 0, len(status.Runs))
+		for _, run := range status.Runs {
+			runs = append(runs, map[string]interface{}{
+				"start":    run.Start.Format(time.RFC3339Nano),
+				"end":      run.End.Format(time.RFC3339Nano),
+				"attempts": run.Attempts,
+				"error":    run.Error,
+				"response": run.Response,
+			})
+		}
+		m[name] = map[string]interface{}{
+			"recent_successful_runs": formatTimes(status.RecentSuccessfulRuns),
+			"recent_failed_runs":     formatTimes(status.RecentFailedRuns),
+			"runs":                   runs,
+		}
+	}
+	return m
+}
+
+// JobStatusesFromResponse decodes the statuses of the jobs returned by the get_job_statuses DoCommand.
+func JobStatusesFromResponse(resp map[string]interface{}) (map[string]robot.JobStatus, error) {
+	statuses, ok := resp[jobStatusesKey].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("response has no %s", jobStatusesKey)
+	}
+	parseTime := func(v interface{}) (time.Time, error) {
+		s, ok := v.(string)
+		if !ok {
+			return time.Time{}, errors.Errorf("expected a time, got %T", v)
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	parseTimes := func(v interface{}) ([]time.Time, error) {
+		list, _ := v.([]interface{})
+		times := make([]time.Time, 0, len(list))
+		for _, item := range list {
+			t, err := parseTime(item)
+			if err != nil {
+				return nil, err
+			}
+			times = append(times, t)
+		}
+		return times, nil
+	}
+
+	result := make(map[string]robot.JobStatus, len(statuses))
+	for name, v := range statuses {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("status of job %q is a %T rather than a map", name, v)
+		}
+		var status robot.JobStatus
+		var err error
+		if status.RecentSuccessfulRuns, err = parseTimes(m["recent_successful_runs"]); err != nil {
+			return nil, errors.Wrapf(err, "job %q", name)
+		}
+		if status.RecentFailedRuns, err = parseTimes(m["recent_failed_runs"]); err != nil {
+			return nil, errors.Wrapf(err, "job %q", name)
+		}
+		runs, _ := m["runs"].([]interface{})
+		for _, r := range runs {
+			runMap, ok := r.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("run of job %q is a %T rather than a map", name, r)
+			}
+			var run robot.JobRun
+			if run.Start, err = parseTime(runMap["start"]); err != nil {
+				return nil, errors.Wrapf(err, "job %q", name)
+			}
+			if run.End, err = parseTime(runMap["end"]); err != nil {
+				return nil, errors.Wrapf(err, "job %q", name)
+			}
+			// numbers are float64 once they have been through a protobuf struct
+			switch attempts := runMap["attempts"].(type) {
+			case float64:
+				run.Attempts = int(attempts)
+			case int:
+				run.Attempts = attempts
+			}
+			run.Error, _ = runMap["error"].(string)
+			run.Response, _ = runMap["response"].(string)
+			status.Runs = append(status.Runs, run)
+		}
+		result[name] = status
+	}
+	return result, nil
+}
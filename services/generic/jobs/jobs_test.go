@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestGetJobStatuses(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 4, 500, time.UTC)
+	statuses := map[string]robot.JobStatus{
+		"flaky": {
+			RecentSuccessfulRuns: []time.Time{start.Add(time.Second)},
+			RecentFailedRuns:     []time.Time{start.Add(time.Minute)},
+			Runs: []robot.JobRun{
+				{Start: start, End: start.Add(time.Second), Attempts: 1, Response: `{"ok":true}`},
+				{Start: start.Add(55 * time.Second), End: start.Add(time.Minute), Attempts: 3, Error: "sensor not responding"},
+			},
+		},
+		"idle": {RecentSuccessfulRuns: []time.Time{}, RecentFailedRuns: []time.Time{}},
+	}
+	r := &inject.Robot{
+		MachineStatusFunc: func(ctx context.Context) (robot.MachineStatus, error) {
+			return robot.MachineStatus{JobStatuses: statuses}, nil
+		},
+	}
+	svc := newJobs(generic.Named("jobs"), r, logging.NewTestLogger(t))
+
+	_, err := svc.DoCommand(context.Background(), map[string]interface{}{"other": true})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+
+	resp, err := svc.DoCommand(context.Background(), map[string]interface{}{GetJobStatusesCommand: true})
+	test.That(t, err, test.ShouldBeNil)
+	// the response is decoded the same after going over the wire as a protobuf struct
+	wire, err := structpb.NewStruct(resp)
+	test.That(t, err, test.ShouldBeNil)
+	decoded, err := JobStatusesFromResponse(wire.AsMap())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, decoded, test.ShouldResemble, statuses)
+
+	_, err = JobStatusesFromResponse(map[string]interface{}{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -5,4 +5,7 @@ import (
 	// register generic.
 	_ "go.viam.com/rdk/services/generic"
 	_ "go.viam.com/rdk/services/generic/fake"
+	_ "go.viam.com/rdk/services/generic/handeye"
+	_ "go.viam.com/rdk/services/generic/intrinsics"
+	_ "go.viam.com/rdk/services/generic/jobs"
 )
@@ -15,14 +15,17 @@ import (
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
 	pb "go.viam.com/api/service/motion/v1"
+	goutils "go.viam.com/utils"
 	"go.viam.com/utils/trace"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/motionplan"
@@ -57,6 +60,7 @@ const (
 	DoPlan              = "plan"
 	DoExecute           = "execute"
 	DoExecuteCheckStart = "executeCheckStart"
+	DoExecuteLimits     = "executeLimits"
 
 	DoTeleopStart  = "teleop_start"
 	DoTeleopMove   = "teleop_move"
@@ -65,16 +69,18 @@ const (
 )
 
 const (
-	builtinOpLabel                     = "motion-service"
-	maxTravelDistanceMM                = 5e6 // this is equivalent to 5km
-	lookAheadDistanceMM        float64 = 5e6
-	defaultSmoothIter                  = 30
-	defaultAngularDegsPerSec           = 60.
-	defaultLinearMPerSec               = 0.3
-	defaultSlamPlanDeviationM          = 1.
-	defaultGlobePlanDeviationM         = 2.6
-	defaultCollisionBuffer             = 150. // mm
-	defaultExecuteEpsilon              = 0.01 // rad or mm
+	builtinOpLabel                       = "motion-service"
+	maxTravelDistanceMM                  = 5e6 // this is equivalent to 5km
+	lookAheadDistanceMM          float64 = 5e6
+	defaultSmoothIter                    = 30
+	defaultAngularDegsPerSec             = 60.
+	defaultLinearMPerSec                 = 0.3
+	defaultSlamPlanDeviationM            = 1.
+	defaultGlobePlanDeviationM           = 2.6
+	defaultPollingFreqHz                 = 1.   // only an explicit frequency of 0 disables polling
+	defaultCollisionBuffer               = 150. // mm
+	defaultExecuteEpsilon                = 0.01 // rad or mm
+	timedExecutionSampleInterval         = 10 * time.Millisecond
 )
 
 // inputEnabledActuator is an actuator that interacts with the frame system.
@@ -144,6 +150,9 @@ type builtIn struct {
 	// Teleop pipeline. Protected by teleopMu (separate from mu to simplify lock ordering).
 	teleopMu       sync.RWMutex
 	teleopPipeline *teleopPipeline
+
+	// Every MoveOnGlobe and MoveOnMap execution which has not yet been evicted.
+	executions *executionStore
 }
 
 // NewBuiltIn returns a new move and grab service for the given robot.
@@ -154,9 +163,11 @@ func NewBuiltIn(
 		Named:                   conf.ResourceName().AsNamed(),
 		logger:                  logger,
 		configuredDefaultExtras: make(map[string]any),
+		executions:              newExecutionStore(defaultExecutionTTL, defaultExecutionTTLCheckInterval, logger),
 	}
 
 	if err := ms.Reconfigure(ctx, deps, conf); err != nil {
+		ms.executions.close()
 		return nil, err
 	}
 	return ms, nil
@@ -176,6 +187,9 @@ func (ms *builtIn) Reconfigure(
 	}
 	ms.teleopMu.Unlock()
 
+	// Executions hold references to dependencies which may be replaced.
+	ms.executions.stopAll()
+
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 	config, err := resource.NativeConfig[*Config](conf)
@@ -226,6 +240,7 @@ func (ms *builtIn) Close(ctx context.Context) error {
 	}
 	ms.teleopMu.Unlock()
 
+	ms.executions.close()
 	return nil
 }
 
@@ -235,20 +250,48 @@ func (ms *builtIn) Move(ctx context.Context, req motion.MoveReq) (bool, error) {
 	operation.CancelOtherWithLabel(ctx, builtinOpLabel)
 
 	ms.applyDefaultExtras(req.Extra)
+	limits, err := inputLimitsFromRequest(req.Extra["input_limits"])
+	if err != nil {
+		return false, err
+	}
 	plan, err := ms.plan(ctx, req, ms.logger)
 	if err != nil {
 		return false, err
 	}
-	err = ms.execute(ctx, plan.Trajectory(), math.MaxFloat64)
+	if limits != nil {
+		err = ms.executeTimed(ctx, plan.Trajectory(), limits, math.MaxFloat64)
+	} else {
+		err = ms.execute(ctx, plan.Trajectory(), math.MaxFloat64)
+	}
 	return err == nil, err
 }
 
+// MoveOnMap plans and begins moving a base to a destination on a SLAM map, returning once the initial plan has been
+// generated. Execution continues in the background, replanning as needed, and can be observed through PlanHistory.
 func (ms *builtIn) MoveOnMap(ctx context.Context, req motion.MoveOnMapReq) (motion.ExecutionID, error) {
-	return uuid.Nil, fmt.Errorf("MoveOnMap not supported by builtin")
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	ms.logger.CDebugf(ctx, "MoveOnMap called with %s", req)
+
+	mr, err := ms.newMoveOnMapRequest(ctx, req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return ms.startExecution(ctx, mr)
 }
 
+// MoveOnGlobe plans and begins moving a base to a destination on the globe, returning once the initial plan has been
+// generated. Execution continues in the background, replanning as needed, and can be observed through PlanHistory.
 func (ms *builtIn) MoveOnGlobe(ctx context.Context, req motion.MoveOnGlobeReq) (motion.ExecutionID, error) {
-	return uuid.Nil, fmt.Errorf("MoveOnGlobeReqe not supported by builtin")
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	ms.logger.CDebugf(ctx, "MoveOnGlobe called with %s", req)
+
+	mr, err := ms.newMoveOnGlobeRequest(ctx, req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return ms.startExecution(ctx, mr)
 }
 
 // GetPose is deprecated.
@@ -269,21 +312,40 @@ func (ms *builtIn) StopPlan(
 	ctx context.Context,
 	req motion.StopPlanReq,
 ) error {
-	return fmt.Errorf("StopPlan not supported by builtin")
+	return ms.executions.stopComponent(req.ComponentName)
 }
 
 func (ms *builtIn) ListPlanStatuses(
 	ctx context.Context,
 	req motion.ListPlanStatusesReq,
 ) ([]motion.PlanStatusWithID, error) {
-	return nil, fmt.Errorf("ListPlanStatuses not supported by builtin")
+	return ms.executions.listPlanStatuses(req.OnlyActivePlans), nil
 }
 
 func (ms *builtIn) PlanHistory(
 	ctx context.Context,
 	req motion.PlanHistoryReq,
 ) ([]motion.PlanWithStatus, error) {
-	return nil, fmt.Errorf("PlanHistory not supported by builtin")
+	return ms.executions.planHistory(req)
+}
+
+// startExecution generates the initial plan of the move request and then executes it in the background, replacing any
+// execution which was already in progress for the same component.
+func (ms *builtIn) startExecution(ctx context.Context, mr *moveRequest) (motion.ExecutionID, error) {
+	plan, err := mr.plan(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	execCtx, cancel := context.WithCancel(context.Background())
+	exec := newExecution(uuid.New(), mr.componentName, cancel)
+	exec.addPlan(mr.planWithMetadata(exec.id, plan), nil)
+	ms.executions.add(exec)
+	go func() {
+		defer close(exec.done)
+		mr.run(execCtx, exec, plan)
+	}()
+	return exec.id, nil
 }
 
 // DoCommand supports two commands which are specified through the command map
@@ -294,6 +356,7 @@ func (ms *builtIn) PlanHistory(
 //   - DoExecute takes a Trajectory and executes it
 //     required key: DoExecute
 //     input value: a motionplan.Trajectory
+//     optional key: DoExecuteLimits, a motionplan.FrameSystemInputLimits used to time parameterize the Trajectory
 //     output value: a bool
 func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
 	// Handle teleop commands first (they manage their own locking).
@@ -377,7 +440,16 @@ func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (m
 
 			resp[DoExecuteCheckStart] = "resource at starting location"
 		}
-		if err := ms.execute(ctx, trajectory, epsilon); err != nil {
+		limits, err := inputLimitsFromRequest(cmd[DoExecuteLimits])
+		if err != nil {
+			return nil, err
+		}
+		if limits != nil {
+			err = ms.executeTimed(ctx, trajectory, limits, epsilon)
+		} else {
+			err = ms.execute(ctx, trajectory, epsilon)
+		}
+		if err != nil {
 			return nil, err
 		}
 		resp[DoExecute] = true
@@ -601,6 +673,10 @@ func (ms *builtIn) planTeleop(
 }
 
 func (ms *builtIn) execute(ctx context.Context, trajectory motionplan.Trajectory, epsilon float64) error {
+	if err := ms.checkTrajectoryStart(ctx, trajectory, epsilon); err != nil {
+		return err
+	}
+
 	// Batch GoToInputs calls if possible; components may want to blend between inputs
 	combinedSteps := []map[string][][]referenceframe.Input{}
 	currStep := map[string][][]referenceframe.Input{}
@@ -610,23 +686,6 @@ func (ms *builtIn) execute(ctx context.Context, trajectory motionplan.Trajectory
 				if len(inputs) == 0 {
 					continue
 				}
-
-				r, ok := ms.components[name]
-				if !ok {
-					return fmt.Errorf("plan had step for resource %s but the motion service is not aware of a component of that name", name)
-				}
-				ie, err := utils.AssertType[framesystem.InputEnabled](r)
-				if err != nil {
-					return err
-				}
-				curr, err := ie.CurrentInputs(ctx)
-				if err != nil {
-					return err
-				}
-				if referenceframe.InputsLinfDistance(curr, inputs) > epsilon {
-					return fmt.Errorf("component %v is not within %v of the current position. Expected inputs %v current inputs %v",
-						name, epsilon, inputs, curr)
-				}
 				currStep[name] = append(currStep[name], inputs)
 			}
 			continue
@@ -702,6 +761,142 @@ func (ms *builtIn) execute(ctx context.Context, trajectory motionplan.Trajectory
 	return nil
 }
 
+// checkTrajectoryStart ensures every component in the first step of the trajectory is within epsilon of its inputs.
+func (ms *builtIn) checkTrajectoryStart(ctx context.Context, trajectory motionplan.Trajectory, epsilon float64) error {
+	if len(trajectory) == 0 {
+		return nil
+	}
+	for name, inputs := range trajectory[0] {
+		if len(inputs) == 0 {
+			continue
+		}
+
+		r, ok := ms.components[name]
+		if !ok {
+			return fmt.Errorf("plan had step for resource %s but the motion service is not aware of a component of that name", name)
+		}
+		ie, err := utils.AssertType[framesystem.InputEnabled](r)
+		if err != nil {
+			return err
+		}
+		curr, err := ie.CurrentInputs(ctx)
+		if err != nil {
+			return err
+		}
+		if referenceframe.InputsLinfDistance(curr, inputs) > epsilon {
+			return fmt.Errorf("component %v is not within %v of the current position. Expected inputs %v current inputs %v",
+				name, epsilon, inputs, curr)
+		}
+	}
+	return nil
+}
+
+// executeTimed time parameterizes the trajectory according to the given limits and moves every component along it
+// simultaneously. Components which implement framesystem.TimedInputEnabled follow the timed trajectory exactly. Other
+// arms are moved through the steps of the trajectory with MoveThroughJointPositions, with the velocity and acceleration
+// limits as move options and samples of the timed trajectory in the extra under framesystem.TimedInputsExtraKey. Only
+// arms which read that extra follow the timed trajectory, any other arm moves through the steps on its own timing,
+// without the jerk limits, and so out of step with other components. Any other component falls back to GoToInputs,
+// which also ignores the timing.
+func (ms *builtIn) executeTimed(
+	ctx context.Context,
+	trajectory motionplan.Trajectory,
+	limits motionplan.FrameSystemInputLimits,
+	epsilon float64,
+) error {
+	if err := ms.checkTrajectoryStart(ctx, trajectory, epsilon); err != nil {
+		return err
+	}
+	timed, err := motionplan.TimeParameterize(trajectory, limits)
+	if err != nil {
+		return err
+	}
+
+	// resolve every frame before moving anything, so that a missing component cannot leave others moving
+	components := make(map[string]resource.Resource, len(timed.Frames()))
+	for _, name := range timed.Frames() {
+		r, ok := ms.components[name]
+		if !ok {
+			return fmt.Errorf("plan had step for resource %s but it was not found in the motion", name)
+		}
+		components[name] = r
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var errs error
+	for name, r := range components {
+		wg.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer wg.Done()
+			if err := ms.followTimedTrajectory(ctx, name, r, timed, limits[name]); err != nil {
+				// stop every other component as the motion can no longer be synchronized
+				cancel()
+				if actuator, ok := r.(inputEnabledActuator); ok {
+					if stopErr := actuator.Stop(context.WithoutCancel(ctx), nil); stopErr != nil {
+						err = errors.Wrap(err, stopErr.Error())
+					}
+				}
+				errMu.Lock()
+				errs = multierr.Combine(errs, err)
+				errMu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return errs
+}
+
+func (ms *builtIn) followTimedTrajectory(
+	ctx context.Context,
+	name string,
+	r resource.Resource,
+	timed *motionplan.TimedTrajectory,
+	limits []motionplan.InputLimit,
+) error {
+	samples, err := timed.Sample(name, timedExecutionSampleInterval)
+	if err != nil {
+		return err
+	}
+	if tie, ok := r.(framesystem.TimedInputEnabled); ok {
+		return tie.GoToTimedInputs(ctx, samples)
+	}
+
+	steps, _ := timed.Steps()
+	positions := make([][]referenceframe.Input, 0, len(steps)-1)
+	for _, step := range steps[1:] {
+		positions = append(positions, step[name])
+	}
+	if a, ok := r.(arm.Arm); ok {
+		opts := &arm.MoveOptions{}
+		for _, limit := range limits {
+			opts.MaxVelRadsJoints = append(opts.MaxVelRadsJoints, limit.MaxVelocity)
+			opts.MaxAccRadsJoints = append(opts.MaxAccRadsJoints, limit.MaxAcceleration)
+		}
+		extra := map[string]interface{}{framesystem.TimedInputsExtraKey: framesystem.TimedInputsToExtra(samples)}
+		return a.MoveThroughJointPositions(ctx, positions, opts, extra)
+	}
+	ie, err := utils.AssertType[framesystem.InputEnabled](r)
+	if err != nil {
+		return err
+	}
+	return ie.GoToInputs(ctx, positions...)
+}
+
+// inputLimitsFromRequest decodes the input limits of a timed execution, returning nil if there are none.
+func inputLimitsFromRequest(val interface{}) (motionplan.FrameSystemInputLimits, error) {
+	if val == nil {
+		return nil, nil
+	}
+	var limits motionplan.FrameSystemInputLimits
+	if err := mapstructure.Decode(val, &limits); err != nil {
+		return nil, errors.Wrap(err, "could not decode input limits")
+	}
+	return limits, nil
+}
+
 // applyDefaultExtras iterates through the list of default extras configured on the builtIn motion service and adds them to the
 // given map of extras if the key does not already exist.
 func (ms *builtIn) applyDefaultExtras(extras map[string]any) {
@@ -331,6 +331,49 @@ func TestDoCommand(t *testing.T) {
 		// the client will need to decode the response still
 		test.That(t, resp, test.ShouldBeTrue)
 	})
+	t.Run("DoExecuteLimits", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/moving_arm.json")
+		defer teardown()
+
+		trajectory := motionplan.Trajectory{
+			{"pieceArm": {0, 0, 0, 0, 0, 0}},
+			{"pieceArm": {0.1, 0.2, 0, 0, 0, 0}},
+			{"pieceArm": {0.2, 0.1, 0.1, 0, 0, 0}},
+		}
+		limit := map[string]interface{}{"max_velocity": 1., "max_acceleration": 2.}
+		limits := map[string]interface{}{"pieceArm": []interface{}{limit, limit, limit, limit, limit, limit}}
+
+		respMap, err := doOverWire(ms, map[string]interface{}{DoExecute: trajectory, DoExecuteLimits: limits})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, respMap[DoExecute], test.ShouldBeTrue)
+
+		r, ok := ms.(*builtIn).components["pieceArm"]
+		test.That(t, ok, test.ShouldBeTrue)
+		inputs, err := r.(framesystem.InputEnabled).CurrentInputs(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, inputs, test.ShouldResemble, trajectory[2]["pieceArm"])
+
+		// limits are required for every component which moves
+		_, err = doOverWire(ms, map[string]interface{}{
+			DoExecute:       trajectory,
+			DoExecuteLimits: map[string]interface{}{"other": []interface{}{limit}},
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+
+		// no component moves if any of them is not found
+		withMissing := motionplan.Trajectory{
+			{"pieceArm": trajectory[2]["pieceArm"], "zzz": {}},
+			{"pieceArm": {0, 0, 0, 0, 0, 0}, "zzz": {1}},
+		}
+		limits["zzz"] = []interface{}{limit}
+		_, err = doOverWire(ms, map[string]interface{}{DoExecute: withMissing, DoExecuteLimits: limits})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "zzz")
+		inputs, err = r.(framesystem.InputEnabled).CurrentInputs(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, inputs, test.ShouldResemble, trajectory[2]["pieceArm"])
+	})
+
 	t.Run("DoExecuteCheckStart", func(t *testing.T) {
 		// generate a separate trajectory plan first. that way this state will not be affected by future executions
 		trajectory, err := testDoPlan(moveReq)
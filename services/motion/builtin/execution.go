@@ -0,0 +1,104 @@
+package builtin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/services/motion"
+)
+
+// execution tracks a single MoveOnGlobe or MoveOnMap call from its initial plan through every replan until it reaches a
+// terminal state.
+type execution struct {
+	id            motion.ExecutionID
+	componentName string
+	cancelFn      context.CancelFunc
+	done          chan struct{}
+
+	mu sync.RWMutex
+	// history is sorted from most recent plan to least recent, and each plan's StatusHistory is sorted from most recent
+	// status to least recent, matching what is returned by PlanHistory.
+	history []motion.PlanWithStatus
+}
+
+func newExecution(id motion.ExecutionID, componentName string, cancelFn context.CancelFunc) *execution {
+	return &execution{
+		id:            id,
+		componentName: componentName,
+		cancelFn:      cancelFn,
+		done:          make(chan struct{}),
+	}
+}
+
+// addPlan records a new in progress plan. If a plan was already in progress it is marked as failed with the given reason,
+// as it has been replaced by a replan.
+func (e *execution) addPlan(plan motion.PlanWithMetadata, reason *string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	if len(e.history) > 0 && e.history[0].StatusHistory[0].State == motion.PlanStateInProgress {
+		e.history[0].StatusHistory = append(
+			[]motion.PlanStatus{{State: motion.PlanStateFailed, Timestamp: now, Reason: reason}},
+			e.history[0].StatusHistory...,
+		)
+	}
+	e.history = append([]motion.PlanWithStatus{{
+		Plan:          plan,
+		StatusHistory: []motion.PlanStatus{{State: motion.PlanStateInProgress, Timestamp: now}},
+	}}, e.history...)
+}
+
+// setState transitions the most recent plan to the given state. This is a no-op if the plan has already terminated.
+func (e *execution) setState(state motion.PlanState, reason *string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.history) == 0 {
+		return
+	}
+	if _, terminal := motion.TerminalStateSet[e.history[0].StatusHistory[0].State]; terminal {
+		return
+	}
+	e.history[0].StatusHistory = append(
+		[]motion.PlanStatus{{State: state, Timestamp: time.Now(), Reason: reason}},
+		e.history[0].StatusHistory...,
+	)
+}
+
+// planHistory returns a copy of the plans of the execution, optionally only including the most recent one.
+func (e *execution) planHistory(lastPlanOnly bool) []motion.PlanWithStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	history := e.history
+	if lastPlanOnly && len(history) > 0 {
+		history = history[:1]
+	}
+	historyCopy := make([]motion.PlanWithStatus, 0, len(history))
+	for _, pws := range history {
+		historyCopy = append(historyCopy, motion.PlanWithStatus{
+			Plan:          pws.Plan,
+			StatusHistory: append([]motion.PlanStatus{}, pws.StatusHistory...),
+		})
+	}
+	return historyCopy
+}
+
+// stop cancels the execution and blocks until it has exited.
+func (e *execution) stop() {
+	e.cancelFn()
+	<-e.done
+}
+
+// terminatedAt returns the time at which the execution reached a terminal state, and whether it has.
+func (e *execution) terminatedAt() (time.Time, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if len(e.history) == 0 {
+		return time.Time{}, false
+	}
+	current := e.history[0].StatusHistory[0]
+	if _, terminal := motion.TerminalStateSet[current.State]; !terminal {
+		return time.Time{}, false
+	}
+	return current.Timestamp, true
+}
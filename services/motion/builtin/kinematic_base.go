@@ -0,0 +1,165 @@
+package builtin
+
+import (
+	"context"
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// kinematicBase wraps a base and a localizer such that the base can be planned for and moved through the frame system.
+// The kinematics of the base are represented as a 2DOF (x, y) model, and movement between inputs is done by first
+// spinning the base to face the next waypoint and then driving straight to it. Input units are millimeters.
+type kinematicBase struct {
+	base.Base
+	localizer         motion.Localizer
+	model             referenceframe.Model
+	linearMPerSec     float64
+	angularDegsPerSec float64
+	logger            logging.Logger
+}
+
+// newKinematicBase creates a kinematicBase for the given base which is able to move within the given limits.
+// The collision geometry of the base is taken from its configured frame, falling back to a sphere the width of the base.
+func newKinematicBase(
+	ctx context.Context,
+	b base.Base,
+	localizer motion.Localizer,
+	limits []referenceframe.Limit,
+	linearMPerSec, angularDegsPerSec float64,
+	logger logging.Logger,
+) (*kinematicBase, error) {
+	geometry, err := baseCollisionGeometry(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	model, err := referenceframe.New2DMobileModelFrame(b.Name().ShortName(), limits, geometry)
+	if err != nil {
+		return nil, err
+	}
+	return &kinematicBase{
+		Base:              b,
+		localizer:         localizer,
+		model:             model,
+		linearMPerSec:     linearMPerSec,
+		angularDegsPerSec: angularDegsPerSec,
+		logger:            logger,
+	}, nil
+}
+
+func baseCollisionGeometry(ctx context.Context, b base.Base) (spatialmath.Geometry, error) {
+	geometries, err := b.Geometries(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(geometries) > 0 {
+		// the model only supports a single geometry, so use one which encompasses all of them
+		if len(geometries) == 1 {
+			return geometries[0].Transform(spatialmath.NewZeroPose()), nil
+		}
+		return boundingSphereOfGeometries(geometries, b.Name().ShortName())
+	}
+	props, err := b.Properties(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	radiusMM := 0.5 * props.WidthMeters * 1e3
+	if radiusMM <= 0 {
+		radiusMM = defaultCollisionBuffer
+	}
+	return spatialmath.NewSphere(spatialmath.NewZeroPose(), radiusMM, b.Name().ShortName())
+}
+
+func boundingSphereOfGeometries(geometries []spatialmath.Geometry, label string) (spatialmath.Geometry, error) {
+	radius := 0.
+	for _, g := range geometries {
+		sphere, err := spatialmath.BoundingSphere(g)
+		if err != nil {
+			return nil, err
+		}
+		radius = math.Max(radius, sphere.ToProtobuf().GetSphere().GetRadiusMm())
+	}
+	return spatialmath.NewSphere(spatialmath.NewZeroPose(), radius, label)
+}
+
+// Kinematics returns the 2DOF model of the base.
+func (kb *kinematicBase) Kinematics(ctx context.Context) (referenceframe.Model, error) {
+	return kb.model, nil
+}
+
+// CurrentInputs returns the x and y position of the base as reported by its localizer.
+func (kb *kinematicBase) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	pif, err := kb.CurrentPosition(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pt := pif.Pose().Point()
+	return []referenceframe.Input{pt.X, pt.Y}, nil
+}
+
+// CurrentPosition returns the pose of the base as reported by its localizer.
+func (kb *kinematicBase) CurrentPosition(ctx context.Context) (*referenceframe.PoseInFrame, error) {
+	return kb.localizer.CurrentPosition(ctx)
+}
+
+// GoToInputs drives the base through each of the given (x, y) inputs in order.
+func (kb *kinematicBase) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	for _, inputs := range inputSteps {
+		if len(inputs) != 2 {
+			return referenceframe.NewIncorrectDoFError(len(inputs), 2)
+		}
+		if err := kb.goToPoint(ctx, r3.Vector{X: inputs[0], Y: inputs[1]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (kb *kinematicBase) goToPoint(ctx context.Context, dst r3.Vector) error {
+	pif, err := kb.CurrentPosition(ctx)
+	if err != nil {
+		return err
+	}
+	delta := dst.Sub(pif.Pose().Point())
+	delta.Z = 0
+	distance := delta.Norm()
+	if distance < defaultExecuteEpsilon {
+		return nil
+	}
+
+	// the base drives along its +Y axis, so a heading of 0 points along +Y and increases counterclockwise
+	desiredHeading := utils.RadToDeg(math.Atan2(-delta.X, delta.Y))
+	if err := kb.spinTo(ctx, pif.Pose(), desiredHeading); err != nil {
+		return err
+	}
+	kb.logger.CDebugf(ctx, "driving %0.2fmm to %v", distance, dst)
+	return kb.MoveStraight(ctx, int(math.Round(distance)), kb.linearMPerSec*1e3, nil)
+}
+
+// spinTo spins the base in place from its current pose such that it ends at the given right handed heading, in degrees.
+func (kb *kinematicBase) spinTo(ctx context.Context, current spatialmath.Pose, headingDegs float64) error {
+	angle := headingDegs - current.Orientation().OrientationVectorDegrees().Theta
+	// take the shortest way around
+	angle = math.Mod(angle+540, 360) - 180
+	if math.Abs(angle) < defaultExecuteEpsilon {
+		return nil
+	}
+	kb.logger.CDebugf(ctx, "spinning %0.2f degrees", angle)
+	return kb.Spin(ctx, angle, kb.angularDegsPerSec, nil)
+}
+
+// spinToHeading spins the base in place to the given right handed heading, in degrees.
+func (kb *kinematicBase) spinToHeading(ctx context.Context, headingDegs float64) error {
+	pif, err := kb.CurrentPosition(ctx)
+	if err != nil {
+		return err
+	}
+	return kb.spinTo(ctx, pif.Pose(), headingDegs)
+}
@@ -0,0 +1,844 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/motionplan/armplanning"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// the minimum amount of space around the start and goal which the base is allowed to plan through.
+	minPlanningMarginMM = 1e3
+	// how far apart the points along a path are which get checked for collisions with newly detected obstacles.
+	pathCollisionCheckStepMM = 50.
+	// how long to wait for a base to stop once an execution has ended.
+	stopBaseTimeout = 5 * time.Second
+	// points of a SLAM map with a probability below this value are not considered obstacles.
+	slamConfidenceThreshold = 50
+)
+
+// obstacleDetector pairs a vision service with the camera it should detect obstacles from.
+type obstacleDetector struct {
+	vision     vision.Service
+	cameraName string
+}
+
+// replanRequest is sent by the execution monitors when the executing plan should be abandoned.
+// If plan is non-nil it should be executed next, otherwise a new plan will be generated.
+type replanRequest struct {
+	reason string
+	plan   motionplan.Plan
+}
+
+// moveRequest contains everything necessary to plan and execute the motion of a base to a goal, replanning as needed.
+type moveRequest struct {
+	componentName     string
+	kb                *kinematicBase
+	frameSys          *referenceframe.FrameSystem
+	fsService         framesystem.Service
+	goal              r3.Vector
+	goalHeading       float64 // right handed, in degrees. NaN if the final heading does not matter
+	obstacles         []spatialmath.Geometry
+	boundingRegions   []spatialmath.Geometry
+	obstacleDetectors []obstacleDetector
+	planDeviationMM   float64
+	positionPollingHz float64
+	obstaclePollingHz float64
+	replanCostFactor  float64
+	maxReplans        int
+	extra             map[string]interface{}
+	anchor            *spatialmath.GeoPose
+	logger            logging.Logger
+
+	transientMu        sync.Mutex
+	transientObstacles []spatialmath.Geometry
+}
+
+// newMoveOnGlobeRequest validates a MoveOnGlobeReq and converts it into a moveRequest whose coordinates are relative to
+// the position of the movement sensor at the time of the request.
+func (ms *builtIn) newMoveOnGlobeRequest(ctx context.Context, req motion.MoveOnGlobeReq) (*moveRequest, error) {
+	if req.Destination == nil {
+		return nil, errors.New("destination cannot be nil")
+	}
+	if math.IsNaN(req.Destination.Lat()) || math.IsNaN(req.Destination.Lng()) {
+		return nil, errors.New("destination may not contain NaN")
+	}
+	if !math.IsNaN(req.Heading) && (req.Heading < 0 || req.Heading > 360) {
+		return nil, fmt.Errorf("heading must be in the range [0, 360], got %v", req.Heading)
+	}
+
+	movementSensor, ok := ms.movementSensors[req.MovementSensorName]
+	if !ok {
+		return nil, resource.DependencyNotFoundError(movementsensor.Named(req.MovementSensorName))
+	}
+	b, err := ms.baseFromName(req.ComponentName)
+	if err != nil {
+		return nil, err
+	}
+
+	origin, _, err := movementSensor.Position(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	calibration, err := ms.movementSensorToBase(ctx, movementSensor, b)
+	if err != nil {
+		return nil, err
+	}
+	localizer := motion.TwoDLocalizer(motion.NewMovementSensorLocalizer(movementSensor, origin, calibration))
+
+	goal := spatialmath.GeoPointToPoint(req.Destination, origin)
+	if goal.Norm() > maxTravelDistanceMM {
+		return nil, fmt.Errorf("cannot move more than %d kilometers", int(maxTravelDistanceMM*1e-6))
+	}
+	goalHeading := math.NaN()
+	if !math.IsNaN(req.Heading) {
+		// compass headings are left handed
+		goalHeading = math.Mod(360-req.Heading, 360)
+	}
+
+	return ms.newMoveRequest(
+		ctx,
+		req.ComponentName,
+		b,
+		localizer,
+		goal,
+		goalHeading,
+		spatialmath.GeoGeometriesToGeometries(req.Obstacles, origin),
+		spatialmath.GeoGeometriesToGeometries(req.BoundingRegions, origin),
+		req.MotionCfg,
+		defaultGlobePlanDeviationM,
+		req.Extra,
+		spatialmath.NewGeoPose(origin, 0),
+	)
+}
+
+// newMoveOnMapRequest validates a MoveOnMapReq and converts it into a moveRequest in the frame of the SLAM map. The map
+// itself is used as an obstacle, and the base is not allowed to leave its bounds.
+func (ms *builtIn) newMoveOnMapRequest(ctx context.Context, req motion.MoveOnMapReq) (*moveRequest, error) {
+	if req.Destination == nil {
+		return nil, errors.New("destination cannot be nil")
+	}
+	slamSvc, ok := ms.slamServices[req.SlamName]
+	if !ok {
+		return nil, resource.DependencyNotFoundError(slam.Named(req.SlamName))
+	}
+	b, err := ms.baseFromName(req.ComponentName)
+	if err != nil {
+		return nil, err
+	}
+
+	octree, err := slamMapOctree(ctx, slamSvc)
+	if err != nil {
+		return nil, err
+	}
+	meta := octree.MetaData()
+	goal := req.Destination.Point()
+	if goal.X < meta.MinX || goal.X > meta.MaxX || goal.Y < meta.MinY || goal.Y > meta.MaxY {
+		return nil, fmt.Errorf("destination %v is outside of the bounds of the SLAM map", goal)
+	}
+	mapBounds, err := spatialmath.NewBox(
+		spatialmath.NewPoseFromPoint(r3.Vector{X: (meta.MinX + meta.MaxX) / 2, Y: (meta.MinY + meta.MaxY) / 2}),
+		r3.Vector{X: meta.MaxX - meta.MinX, Y: meta.MaxY - meta.MinY, Z: 1},
+		req.SlamName+"_bounds",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// headings are compared against the localizer, which adjusts SLAM poses such that the base drives along +Y
+	goalHeading := spatialmath.Compose(req.Destination, motion.SLAMOrientationAdjustment).Orientation().OrientationVectorDegrees().Theta
+	if profile, ok := req.Extra["motion_profile"]; ok && profile == "position_only" {
+		goalHeading = math.NaN()
+	}
+
+	return ms.newMoveRequest(
+		ctx,
+		req.ComponentName,
+		b,
+		motion.TwoDLocalizer(motion.NewSLAMLocalizer(slamSvc)),
+		goal,
+		goalHeading,
+		append([]spatialmath.Geometry{octree}, req.Obstacles...),
+		[]spatialmath.Geometry{mapBounds},
+		req.MotionCfg,
+		defaultSlamPlanDeviationM,
+		req.Extra,
+		nil,
+	)
+}
+
+// slamMapOctree returns the current map of the SLAM service as an octree which can be used as an obstacle.
+func slamMapOctree(ctx context.Context, slamSvc slam.Service) (*pointcloud.BasicOctree, error) {
+	data, err := slam.PointCloudMapFull(ctx, slamSvc, false)
+	if err != nil {
+		return nil, err
+	}
+	pc, err := pointcloud.ReadPCD(bytes.NewReader(data), "")
+	if err != nil {
+		return nil, err
+	}
+	if pc.Size() == 0 {
+		return nil, errors.New("cannot move on an empty SLAM map")
+	}
+	octree, err := pointcloud.ToBasicOctree(pc, slamConfidenceThreshold)
+	if err != nil {
+		return nil, err
+	}
+	octree.SetLabel(slamSvc.Name().ShortName())
+	return octree, nil
+}
+
+// newMoveRequest builds a moveRequest for a base localized by the given localizer. The goal, obstacles and bounding regions
+// must all be expressed in the frame of the localizer.
+func (ms *builtIn) newMoveRequest(
+	ctx context.Context,
+	componentName string,
+	b base.Base,
+	localizer motion.Localizer,
+	goal r3.Vector,
+	goalHeading float64,
+	obstacles []spatialmath.Geometry,
+	boundingRegions []spatialmath.Geometry,
+	motionCfg *motion.MotionConfiguration,
+	defaultPlanDeviationM float64,
+	extra map[string]interface{},
+	anchor *spatialmath.GeoPose,
+) (*moveRequest, error) {
+	if motionCfg == nil {
+		motionCfg = &motion.MotionConfiguration{}
+	}
+	if err := validateMotionConfiguration(motionCfg); err != nil {
+		return nil, err
+	}
+	linearMPerSec := defaultLinearMPerSec
+	if motionCfg.LinearMPerSec != 0 {
+		linearMPerSec = motionCfg.LinearMPerSec
+	}
+	angularDegsPerSec := defaultAngularDegsPerSec
+	if motionCfg.AngularDegsPerSec != 0 {
+		angularDegsPerSec = motionCfg.AngularDegsPerSec
+	}
+	planDeviationMM := 1e3 * defaultPlanDeviationM
+	if motionCfg.PlanDeviationMM != 0 {
+		planDeviationMM = motionCfg.PlanDeviationMM
+	}
+	positionPollingHz, obstaclePollingHz := defaultPollingFreqHz, defaultPollingFreqHz
+	if motionCfg.PositionPollingFreqHz != nil {
+		positionPollingHz = *motionCfg.PositionPollingFreqHz
+	}
+	if motionCfg.ObstaclePollingFreqHz != nil {
+		obstaclePollingHz = *motionCfg.ObstaclePollingFreqHz
+	}
+
+	detectors := make([]obstacleDetector, 0, len(motionCfg.ObstacleDetectors))
+	for _, od := range motionCfg.ObstacleDetectors {
+		visionSvc, ok := ms.visionServices[od.VisionServiceName]
+		if !ok {
+			return nil, resource.DependencyNotFoundError(vision.Named(od.VisionServiceName))
+		}
+		detectors = append(detectors, obstacleDetector{vision: visionSvc, cameraName: od.CameraName})
+	}
+
+	replanCostFactor, err := floatFromExtra(extra, "replan_cost_factor", 0)
+	if err != nil {
+		return nil, err
+	}
+	if replanCostFactor < 0 {
+		return nil, errors.New("replan_cost_factor must be non-negative")
+	}
+	maxReplans, err := floatFromExtra(extra, "max_replans", -1)
+	if err != nil {
+		return nil, err
+	}
+
+	startPose, err := localizer.CurrentPosition(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start := startPose.Pose().Point()
+	if distance := goal.Sub(start).Norm(); distance <= planDeviationMM {
+		return nil, fmt.Errorf("base is already %0.2fmm from the goal, which is within the plan deviation of %0.2fmm",
+			distance, planDeviationMM)
+	}
+
+	limits, err := planningLimits(start, goal, boundingRegions)
+	if err != nil {
+		return nil, err
+	}
+	kb, err := newKinematicBase(ctx, b, localizer, limits, linearMPerSec, angularDegsPerSec, ms.logger)
+	if err != nil {
+		return nil, err
+	}
+	frameSys := referenceframe.NewEmptyFrameSystem("base-planning")
+	if err := frameSys.AddFrame(kb.model, frameSys.World()); err != nil {
+		return nil, err
+	}
+
+	return &moveRequest{
+		componentName:     componentName,
+		kb:                kb,
+		frameSys:          frameSys,
+		fsService:         ms.fsService,
+		goal:              r3.Vector{X: goal.X, Y: goal.Y},
+		goalHeading:       goalHeading,
+		obstacles:         labelGeometries(obstacles, "obstacle"),
+		boundingRegions:   boundingRegions,
+		obstacleDetectors: detectors,
+		planDeviationMM:   planDeviationMM,
+		positionPollingHz: positionPollingHz,
+		obstaclePollingHz: obstaclePollingHz,
+		replanCostFactor:  replanCostFactor,
+		maxReplans:        int(maxReplans),
+		extra:             extra,
+		anchor:            anchor,
+		logger:            ms.logger,
+	}, nil
+}
+
+func validateMotionConfiguration(motionCfg *motion.MotionConfiguration) error {
+	if motionCfg.PlanDeviationMM < 0 {
+		return errors.New("PlanDeviationMM may not be negative")
+	}
+	if motionCfg.LinearMPerSec < 0 {
+		return errors.New("LinearMPerSec may not be negative")
+	}
+	if motionCfg.AngularDegsPerSec < 0 {
+		return errors.New("AngularDegsPerSec may not be negative")
+	}
+	if motionCfg.PositionPollingFreqHz != nil && *motionCfg.PositionPollingFreqHz < 0 {
+		return errors.New("PositionPollingFreqHz may not be negative")
+	}
+	if motionCfg.ObstaclePollingFreqHz != nil && *motionCfg.ObstaclePollingFreqHz < 0 {
+		return errors.New("ObstaclePollingFreqHz may not be negative")
+	}
+	return nil
+}
+
+func floatFromExtra(extra map[string]interface{}, key string, defaultValue float64) (float64, error) {
+	val, ok := extra[key]
+	if !ok || val == nil {
+		return defaultValue, nil
+	}
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("could not interpret extra %s of type %T as a number", key, val)
+	}
+}
+
+// baseFromName returns the base component with the given name.
+func (ms *builtIn) baseFromName(name string) (base.Base, error) {
+	component, ok := ms.components[name]
+	if !ok {
+		return nil, resource.DependencyNotFoundError(base.Named(name))
+	}
+	b, ok := component.(base.Base)
+	if !ok {
+		return nil, fmt.Errorf("cannot move component %s of type %T, only bases are supported", name, component)
+	}
+	return b, nil
+}
+
+// movementSensorToBase returns the pose of the base in the frame of the movement sensor. If the frame system does not
+// relate the two, they are assumed to be coincident.
+func (ms *builtIn) movementSensorToBase(
+	ctx context.Context,
+	movementSensor movementsensor.MovementSensor,
+	b base.Base,
+) (spatialmath.Pose, error) {
+	baseOrigin := referenceframe.NewPoseInFrame(b.Name().ShortName(), spatialmath.NewZeroPose())
+	pif, err := ms.fsService.TransformPose(ctx, baseOrigin, movementSensor.Name().ShortName(), nil)
+	if err != nil {
+		ms.logger.CDebugf(ctx, "assuming movement sensor %s is coincident with base %s due to err: %v",
+			movementSensor.Name().ShortName(), b.Name().ShortName(), err)
+		return spatialmath.NewZeroPose(), nil
+	}
+	return pif.Pose(), nil
+}
+
+// planningLimits returns the x and y limits the base may plan within. If bounding regions are given the base is limited
+// to the space they cover, otherwise the base may travel as far from the start and goal as they are from each other.
+func planningLimits(start, goal r3.Vector, boundingRegions []spatialmath.Geometry) ([]referenceframe.Limit, error) {
+	if len(boundingRegions) > 0 {
+		limits := []referenceframe.Limit{
+			{Min: math.Inf(1), Max: math.Inf(-1)},
+			{Min: math.Inf(1), Max: math.Inf(-1)},
+		}
+		for _, region := range boundingRegions {
+			// bound the region about its own center, so that regions away from the origin are not inflated
+			center := region.Pose().Point()
+			sphere, err := spatialmath.BoundingSphere(region.Transform(spatialmath.PoseInverse(region.Pose())))
+			if err != nil {
+				return nil, err
+			}
+			extent := sphere.ToProtobuf().GetSphere().GetRadiusMm()
+			limits[0] = referenceframe.Limit{Min: math.Min(limits[0].Min, center.X-extent), Max: math.Max(limits[0].Max, center.X+extent)}
+			limits[1] = referenceframe.Limit{Min: math.Min(limits[1].Min, center.Y-extent), Max: math.Max(limits[1].Max, center.Y+extent)}
+		}
+		return limits, nil
+	}
+	margin := math.Max(goal.Sub(start).Norm(), minPlanningMarginMM)
+	return []referenceframe.Limit{
+		{Min: math.Min(start.X, goal.X) - margin, Max: math.Max(start.X, goal.X) + margin},
+		{Min: math.Min(start.Y, goal.Y) - margin, Max: math.Max(start.Y, goal.Y) + margin},
+	}, nil
+}
+
+// labelGeometries gives each unlabeled geometry a unique label. Unlabeled geometries are copied rather than modified.
+func labelGeometries(geometries []spatialmath.Geometry, prefix string) []spatialmath.Geometry {
+	labeled := make([]spatialmath.Geometry, 0, len(geometries))
+	for i, g := range geometries {
+		if g.Label() == "" {
+			g = g.Transform(spatialmath.NewZeroPose())
+			g.SetLabel(prefix + "_" + strconv.Itoa(i))
+		}
+		labeled = append(labeled, g)
+	}
+	return labeled
+}
+
+// plan generates a plan from the current position of the base to the goal which avoids both the static obstacles of the
+// request and any obstacles which have been detected while executing.
+func (mr *moveRequest) plan(ctx context.Context) (motionplan.Plan, error) {
+	inputs, err := mr.kb.CurrentInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	obstacles, err := mr.planningObstacles(inputs)
+	if err != nil {
+		return nil, err
+	}
+	worldState, err := referenceframe.NewWorldState(
+		[]*referenceframe.GeometriesInFrame{referenceframe.NewGeometriesInFrame(referenceframe.World, obstacles)},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	planOpts, err := armplanning.NewPlannerOptionsFromExtra(mr.extra)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := mr.extra["collision_buffer_mm"]; !ok {
+		planOpts.CollisionBufferMM = defaultCollisionBuffer
+	}
+
+	name := mr.kb.Name().ShortName()
+	plan, _, err := armplanning.PlanMotion(ctx, mr.logger, &armplanning.PlanRequest{
+		FrameSystem: mr.frameSys,
+		Goals: []*armplanning.PlanState{
+			armplanning.NewPlanState(nil, referenceframe.FrameSystemInputs{name: {mr.goal.X, mr.goal.Y}}),
+		},
+		StartState:     armplanning.NewPlanState(nil, referenceframe.FrameSystemInputs{name: inputs}),
+		WorldState:     worldState,
+		PlannerOptions: planOpts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := mr.checkBoundingRegions(plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planningObstacles returns all obstacles which should be planned around. Detected obstacles the base is currently
+// overlapping are excluded so that the base is able to plan its way out of them.
+func (mr *moveRequest) planningObstacles(inputs []referenceframe.Input) ([]spatialmath.Geometry, error) {
+	obstacles := append([]spatialmath.Geometry{}, mr.obstacles...)
+	transient := mr.currentTransientObstacles()
+	if len(transient) == 0 {
+		return obstacles, nil
+	}
+	baseGeometries, err := mr.kb.model.Geometries(inputs)
+	if err != nil {
+		return nil, err
+	}
+	for _, obstacle := range transient {
+		collides, err := collidesWithAny(obstacle, baseGeometries.Geometries())
+		if err != nil {
+			return nil, err
+		}
+		if !collides {
+			obstacles = append(obstacles, obstacle)
+		}
+	}
+	return obstacles, nil
+}
+
+func collidesWithAny(g spatialmath.Geometry, others []spatialmath.Geometry) (bool, error) {
+	for _, other := range others {
+		collides, _, err := g.CollidesWith(other, 0)
+		if err != nil {
+			return false, err
+		}
+		if collides {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkBoundingRegions ensures that the base never leaves the bounding regions of the request while following the plan.
+func (mr *moveRequest) checkBoundingRegions(plan motionplan.Plan) error {
+	if len(mr.boundingRegions) == 0 {
+		return nil
+	}
+	waypoints, err := mr.waypoints(plan)
+	if err != nil {
+		return err
+	}
+	for i := 1; i < len(waypoints); i++ {
+		for _, pt := range interpolateSegment(waypoints[i-1], waypoints[i], pathCollisionCheckStepMM) {
+			inside, err := collidesWithAny(spatialmath.NewPoint(pt, ""), mr.boundingRegions)
+			if err != nil {
+				return err
+			}
+			if !inside {
+				return fmt.Errorf("plan would leave the bounding regions at %v", pt)
+			}
+		}
+	}
+	return nil
+}
+
+// waypoints returns the (x, y) positions the base drives through when following the plan.
+func (mr *moveRequest) waypoints(plan motionplan.Plan) ([]r3.Vector, error) {
+	inputs, err := plan.Trajectory().GetFrameInputs(mr.kb.Name().ShortName())
+	if err != nil {
+		return nil, err
+	}
+	waypoints := make([]r3.Vector, 0, len(inputs))
+	for _, input := range inputs {
+		if len(input) != 2 {
+			return nil, referenceframe.NewIncorrectDoFError(len(input), 2)
+		}
+		waypoints = append(waypoints, r3.Vector{X: input[0], Y: input[1]})
+	}
+	return waypoints, nil
+}
+
+// interpolateSegment returns points along the segment from start to end, inclusive, spaced at most step apart.
+func interpolateSegment(start, end r3.Vector, step float64) []r3.Vector {
+	delta := end.Sub(start)
+	n := int(math.Ceil(delta.Norm() / step))
+	pts := make([]r3.Vector, 0, n+1)
+	pts = append(pts, start)
+	for i := 1; i <= n; i++ {
+		pts = append(pts, start.Add(delta.Mul(float64(i)/float64(n))))
+	}
+	return pts
+}
+
+// pathLength returns the length of the path from start through each waypoint.
+func pathLength(start r3.Vector, waypoints []r3.Vector) float64 {
+	length := 0.
+	for _, wp := range waypoints {
+		length += wp.Sub(start).Norm()
+		start = wp
+	}
+	return length
+}
+
+func (mr *moveRequest) planWithMetadata(executionID motion.ExecutionID, plan motionplan.Plan) motion.PlanWithMetadata {
+	return motion.PlanWithMetadata{
+		ID:            uuid.New(),
+		ComponentName: mr.componentName,
+		ExecutionID:   executionID,
+		Plan:          plan,
+		AnchorGeoPose: mr.anchor,
+	}
+}
+
+// run executes the plan, replanning whenever a monitor determines the plan is no longer valid, until the goal is reached,
+// an error occurs or the context is cancelled. The terminal state of the execution is recorded before returning.
+func (mr *moveRequest) run(ctx context.Context, exec *execution, plan motionplan.Plan) {
+	replans := 0
+	for {
+		replan, err := mr.executePlan(ctx, plan)
+		switch {
+		case ctx.Err() != nil:
+			mr.stopBase()
+			exec.setState(motion.PlanStateStopped, nil)
+			return
+		case err != nil:
+			mr.stopBase()
+			reason := err.Error()
+			exec.setState(motion.PlanStateFailed, &reason)
+			return
+		case replan == nil:
+			exec.setState(motion.PlanStateSucceeded, nil)
+			return
+		}
+
+		if mr.maxReplans >= 0 && replans >= mr.maxReplans {
+			mr.stopBase()
+			reason := fmt.Sprintf("exceeded maximum number of replans: %d, last replan reason: %s", mr.maxReplans, replan.reason)
+			exec.setState(motion.PlanStateFailed, &reason)
+			return
+		}
+		replans++
+		mr.logger.CInfof(ctx, "replanning execution %s: %s", exec.id, replan.reason)
+
+		plan = replan.plan
+		if plan == nil {
+			plan, err = mr.plan(ctx)
+			if err != nil {
+				mr.stopBase()
+				if ctx.Err() != nil {
+					exec.setState(motion.PlanStateStopped, nil)
+					return
+				}
+				reason := fmt.Sprintf("failed to replan after %s: %s", replan.reason, err)
+				exec.setState(motion.PlanStateFailed, &reason)
+				return
+			}
+		}
+		exec.addPlan(mr.planWithMetadata(exec.id, plan), &replan.reason)
+	}
+}
+
+// executePlan drives the base along the plan while monitoring its position and surroundings. It returns a non-nil
+// replanRequest if the plan was abandoned, or nil if the base reached the end of the plan.
+func (mr *moveRequest) executePlan(ctx context.Context, plan motionplan.Plan) (*replanRequest, error) {
+	waypoints, err := mr.waypoints(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	// target is the index of the waypoint the base is currently driving towards
+	var target atomic.Int64
+	target.Store(1)
+	replanCh := make(chan *replanRequest, 1)
+	requestReplan := func(replan *replanRequest) {
+		select {
+		case replanCh <- replan:
+		default:
+		}
+	}
+	execErrCh := make(chan error, 1)
+
+	workers := goutils.NewStoppableWorkers(ctx)
+	defer workers.Stop()
+	workers.Add(func(ctx context.Context) {
+		execErrCh <- mr.followWaypoints(ctx, waypoints, &target)
+	})
+	if mr.positionPollingHz > 0 {
+		workers.Add(func(ctx context.Context) {
+			mr.pollAtFrequency(ctx, mr.positionPollingHz, func(ctx context.Context) error {
+				replan, err := mr.checkPosition(ctx, waypoints, int(target.Load()))
+				if replan != nil {
+					requestReplan(replan)
+				}
+				return err
+			})
+		})
+	}
+	if mr.obstaclePollingHz > 0 && len(mr.obstacleDetectors) > 0 {
+		workers.Add(func(ctx context.Context) {
+			mr.pollAtFrequency(ctx, mr.obstaclePollingHz, func(ctx context.Context) error {
+				replan, err := mr.checkObstacles(ctx, waypoints, int(target.Load()))
+				if replan != nil {
+					requestReplan(replan)
+				}
+				return err
+			})
+		})
+	}
+
+	select {
+	case replan := <-replanCh:
+		workers.Stop()
+		if err := mr.kb.Stop(ctx, nil); err != nil {
+			return nil, err
+		}
+		return replan, nil
+	case err := <-execErrCh:
+		return nil, err
+	}
+}
+
+// pollAtFrequency calls fn at the given frequency until the context is done. Errors are logged rather than ending the
+// execution, as a transient sensor failure should not abandon an otherwise valid plan.
+func (mr *moveRequest) pollAtFrequency(ctx context.Context, hz float64, fn func(context.Context) error) {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / hz))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil && ctx.Err() == nil {
+				mr.logger.CWarnf(ctx, "error while monitoring execution: %v", err)
+			}
+		}
+	}
+}
+
+// followWaypoints drives the base through each waypoint, starting from the one after the start of the plan, and then spins
+// to the goal heading if one was requested.
+func (mr *moveRequest) followWaypoints(ctx context.Context, waypoints []r3.Vector, target *atomic.Int64) error {
+	for i := 1; i < len(waypoints); i++ {
+		target.Store(int64(i))
+		if err := mr.kb.GoToInputs(ctx, []referenceframe.Input{waypoints[i].X, waypoints[i].Y}); err != nil {
+			return err
+		}
+	}
+	if !math.IsNaN(mr.goalHeading) {
+		return mr.kb.spinToHeading(ctx, mr.goalHeading)
+	}
+	return nil
+}
+
+// checkPosition determines whether the base has strayed too far from the segment of the plan it is following, or if the
+// replan cost factor allows it, whether a sufficiently cheaper plan from the current position exists.
+func (mr *moveRequest) checkPosition(ctx context.Context, waypoints []r3.Vector, target int) (*replanRequest, error) {
+	if target < 1 || target >= len(waypoints) {
+		return nil, nil
+	}
+	pif, err := mr.kb.CurrentPosition(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := pif.Pose().Point()
+	current.Z = 0
+
+	deviation := spatialmath.DistToLineSegment(waypoints[target-1], waypoints[target], current)
+	if deviation > mr.planDeviationMM {
+		return &replanRequest{
+			reason: fmt.Sprintf("plan deviation of %0.2fmm exceeded the allowed %0.2fmm", deviation, mr.planDeviationMM),
+		}, nil
+	}
+
+	if mr.replanCostFactor <= 0 {
+		return nil, nil
+	}
+	candidate, err := mr.plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candidateWaypoints, err := mr.waypoints(candidate)
+	if err != nil {
+		return nil, err
+	}
+	remainingCost := pathLength(current, waypoints[target:])
+	candidateCost := pathLength(current, candidateWaypoints)
+	if candidateCost*(1+mr.replanCostFactor) < remainingCost {
+		return &replanRequest{
+			reason: fmt.Sprintf("found a plan with cost %0.2f, which is cheaper than the remaining cost %0.2f with replan cost factor %0.2f",
+				candidateCost, remainingCost, mr.replanCostFactor),
+			plan: candidate,
+		}, nil
+	}
+	return nil, nil
+}
+
+// checkObstacles detects obstacles with each of the configured obstacle detectors and determines whether any of them
+// obstruct the remainder of the plan.
+func (mr *moveRequest) checkObstacles(ctx context.Context, waypoints []r3.Vector, target int) (*replanRequest, error) {
+	pif, err := mr.kb.CurrentPosition(ctx)
+	if err != nil {
+		return nil, err
+	}
+	detected, err := mr.detectObstacles(ctx, pif.Pose())
+	if err != nil {
+		return nil, err
+	}
+	mr.transientMu.Lock()
+	mr.transientObstacles = detected
+	mr.transientMu.Unlock()
+	if len(detected) == 0 || target >= len(waypoints) {
+		return nil, nil
+	}
+
+	current := pif.Pose().Point()
+	current.Z = 0
+	remaining := append([]r3.Vector{current}, waypoints[target:]...)
+	for i := 1; i < len(remaining); i++ {
+		for _, pt := range interpolateSegment(remaining[i-1], remaining[i], pathCollisionCheckStepMM) {
+			baseGeometries, err := mr.kb.model.Geometries([]referenceframe.Input{pt.X, pt.Y})
+			if err != nil {
+				return nil, err
+			}
+			for _, obstacle := range detected {
+				collides, err := collidesWithAny(obstacle, baseGeometries.Geometries())
+				if err != nil {
+					return nil, err
+				}
+				if collides {
+					return &replanRequest{reason: fmt.Sprintf("detected obstacle %s obstructs the plan at %v", obstacle.Label(), pt)}, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// detectObstacles returns the obstacles currently seen by the obstacle detectors in the frame of the localizer.
+func (mr *moveRequest) detectObstacles(ctx context.Context, current spatialmath.Pose) ([]spatialmath.Geometry, error) {
+	baseName := mr.kb.Name().ShortName()
+	detected := []spatialmath.Geometry{}
+	for _, od := range mr.obstacleDetectors {
+		objects, err := od.vision.GetObjectPointClouds(ctx, od.cameraName, nil)
+		if err != nil {
+			return nil, err
+		}
+		cameraOrigin := referenceframe.NewPoseInFrame(od.cameraName, spatialmath.NewZeroPose())
+		cameraToBase, err := mr.fsService.TransformPose(ctx, cameraOrigin, baseName, nil)
+		if err != nil {
+			mr.logger.CDebugf(ctx, "assuming camera %s is coincident with base %s due to err: %v", od.cameraName, baseName, err)
+			cameraToBase = referenceframe.NewPoseInFrame(baseName, spatialmath.NewZeroPose())
+		}
+		cameraToWorld := spatialmath.Compose(current, cameraToBase.Pose())
+		for _, object := range objects {
+			if object.Geometry == nil {
+				continue
+			}
+			detected = append(detected, object.Geometry.Transform(cameraToWorld))
+		}
+	}
+	return labelGeometries(detected, "transient_obstacle"), nil
+}
+
+func (mr *moveRequest) currentTransientObstacles() []spatialmath.Geometry {
+	mr.transientMu.Lock()
+	defer mr.transientMu.Unlock()
+	return mr.transientObstacles
+}
+
+// stopBase stops the base once an execution has ended. A fresh context is used as the execution's context may have been
+// cancelled.
+func (mr *moveRequest) stopBase() {
+	ctx, cancel := context.WithTimeout(context.Background(), stopBaseTimeout)
+	defer cancel()
+	if err := mr.kb.Stop(ctx, nil); err != nil {
+		mr.logger.CWarnf(ctx, "failed to stop base %s: %v", mr.componentName, err)
+	}
+}
@@ -0,0 +1,415 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// the fake movement sensor always reports this position.
+var fakeGPSOrigin = geo.NewPoint(40.7, -73.98)
+
+func pollUntilTerminal(ctx context.Context, t *testing.T, ms motion.Service, componentName string) []motion.PlanWithStatus {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	for {
+		ph, err := ms.PlanHistory(ctx, motion.PlanHistoryReq{ComponentName: componentName})
+		test.That(t, err, test.ShouldBeNil)
+		if _, terminal := motion.TerminalStateSet[ph[0].StatusHistory[0].State]; terminal {
+			return ph
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for execution to terminate")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestMoveOnGlobe(t *testing.T) {
+	ctx := context.Background()
+	// the fake base does not move, so position polling is disabled to avoid replanning due to plan deviation.
+	noPolling := 0.
+	motionCfg := &motion.MotionConfiguration{PositionPollingFreqHz: &noPolling, ObstaclePollingFreqHz: &noPolling}
+	dst := geo.NewPoint(fakeGPSOrigin.Lat(), fakeGPSOrigin.Lng()+1e-4)
+
+	t.Run("succeeds", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/gps_base.json")
+		defer teardown()
+
+		executionID, err := ms.MoveOnGlobe(ctx, motion.MoveOnGlobeReq{
+			ComponentName:      "test-base",
+			Destination:        dst,
+			Heading:            math.NaN(),
+			MovementSensorName: "test-gps",
+			MotionCfg:          motionCfg,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, executionID, test.ShouldNotEqual, uuid.Nil)
+
+		ph := pollUntilTerminal(ctx, t, ms, "test-base")
+		test.That(t, len(ph), test.ShouldEqual, 1)
+		test.That(t, ph[0].StatusHistory[0].State, test.ShouldEqual, motion.PlanStateSucceeded)
+		test.That(t, ph[0].StatusHistory[1].State, test.ShouldEqual, motion.PlanStateInProgress)
+		test.That(t, ph[0].Plan.ExecutionID, test.ShouldEqual, executionID)
+		test.That(t, ph[0].Plan.ComponentName, test.ShouldEqual, "test-base")
+		test.That(t, ph[0].Plan.AnchorGeoPose.Location(), test.ShouldResemble, fakeGPSOrigin)
+
+		// the final waypoint of the plan is the destination relative to the starting position
+		inputs, err := ph[0].Plan.Trajectory().GetFrameInputs("test-base")
+		test.That(t, err, test.ShouldBeNil)
+		goal := spatialmath.GeoPointToPoint(dst, fakeGPSOrigin)
+		last := inputs[len(inputs)-1]
+		test.That(t, last[0], test.ShouldAlmostEqual, goal.X, 1e-3)
+		test.That(t, last[1], test.ShouldAlmostEqual, goal.Y, 1e-3)
+
+		statuses, err := ms.ListPlanStatuses(ctx, motion.ListPlanStatusesReq{})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(statuses), test.ShouldEqual, 1)
+		test.That(t, statuses[0].PlanID, test.ShouldEqual, ph[0].Plan.ID)
+		test.That(t, statuses[0].Status.State, test.ShouldEqual, motion.PlanStateSucceeded)
+		statuses, err = ms.ListPlanStatuses(ctx, motion.ListPlanStatusesReq{OnlyActivePlans: true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, statuses, test.ShouldBeEmpty)
+
+		_, err = ms.PlanHistory(ctx, motion.PlanHistoryReq{ComponentName: "test-base", ExecutionID: uuid.New()})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, ms.StopPlan(ctx, motion.StopPlanReq{ComponentName: "test-base"}), test.ShouldBeNil)
+	})
+
+	t.Run("avoids obstacles", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/gps_base.json")
+		defer teardown()
+
+		box, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{X: 2000, Y: 2000, Z: 10}, "box")
+		test.That(t, err, test.ShouldBeNil)
+		midpoint := geo.NewPoint(fakeGPSOrigin.Lat(), fakeGPSOrigin.Lng()+5e-5)
+		obstacle := spatialmath.NewGeoGeometry(midpoint, []spatialmath.Geometry{box})
+
+		_, err = ms.MoveOnGlobe(ctx, motion.MoveOnGlobeReq{
+			ComponentName:      "test-base",
+			Destination:        dst,
+			Heading:            90,
+			MovementSensorName: "test-gps",
+			Obstacles:          []*spatialmath.GeoGeometry{obstacle},
+			MotionCfg:          motionCfg,
+		})
+		test.That(t, err, test.ShouldBeNil)
+
+		ph := pollUntilTerminal(ctx, t, ms, "test-base")
+		test.That(t, ph[0].StatusHistory[0].State, test.ShouldEqual, motion.PlanStateSucceeded)
+		inputs, err := ph[0].Plan.Trajectory().GetFrameInputs("test-base")
+		test.That(t, err, test.ShouldBeNil)
+		boxInPlan := spatialmath.GeoGeometriesToGeometries([]*spatialmath.GeoGeometry{obstacle}, fakeGPSOrigin)[0]
+		for i := 1; i < len(inputs); i++ {
+			start := r3.Vector{X: inputs[i-1][0], Y: inputs[i-1][1]}
+			end := r3.Vector{X: inputs[i][0], Y: inputs[i][1]}
+			for _, pt := range interpolateSegment(start, end, 10) {
+				inside, _, err := boxInPlan.CollidesWith(spatialmath.NewPoint(pt, ""), 0)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, inside, test.ShouldBeFalse)
+			}
+		}
+	})
+
+	t.Run("fails when the destination is outside of the bounding regions", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/gps_base.json")
+		defer teardown()
+
+		box, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{X: 2000, Y: 2000, Z: 10}, "region")
+		test.That(t, err, test.ShouldBeNil)
+		region := spatialmath.NewGeoGeometry(fakeGPSOrigin, []spatialmath.Geometry{box})
+
+		_, err = ms.MoveOnGlobe(ctx, motion.MoveOnGlobeReq{
+			ComponentName:      "test-base",
+			Destination:        dst,
+			Heading:            math.NaN(),
+			MovementSensorName: "test-gps",
+			BoundingRegions:    []*spatialmath.GeoGeometry{region},
+			MotionCfg:          motionCfg,
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("polls unless disabled", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/gps_base.json")
+		defer teardown()
+
+		req := motion.MoveOnGlobeReq{
+			ComponentName:      "test-base",
+			Destination:        dst,
+			Heading:            math.NaN(),
+			MovementSensorName: "test-gps",
+		}
+		mr, err := ms.(*builtIn).newMoveOnGlobeRequest(ctx, req)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mr.positionPollingHz, test.ShouldEqual, defaultPollingFreqHz)
+		test.That(t, mr.obstaclePollingHz, test.ShouldEqual, defaultPollingFreqHz)
+
+		req.MotionCfg = motionCfg
+		mr, err = ms.(*builtIn).newMoveOnGlobeRequest(ctx, req)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mr.positionPollingHz, test.ShouldEqual, 0)
+		test.That(t, mr.obstaclePollingHz, test.ShouldEqual, 0)
+	})
+
+	t.Run("fails on invalid requests", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/gps_base.json")
+		defer teardown()
+
+		valid := motion.MoveOnGlobeReq{
+			ComponentName:      "test-base",
+			Destination:        dst,
+			Heading:            math.NaN(),
+			MovementSensorName: "test-gps",
+			MotionCfg:          motionCfg,
+		}
+		for _, tc := range []struct {
+			name   string
+			modify func(req *motion.MoveOnGlobeReq)
+			errMsg string
+		}{
+			{"nil destination", func(req *motion.MoveOnGlobeReq) { req.Destination = nil }, "destination cannot be nil"},
+			{"invalid heading", func(req *motion.MoveOnGlobeReq) { req.Heading = 400 }, "heading must be in the range"},
+			{"missing movement sensor", func(req *motion.MoveOnGlobeReq) { req.MovementSensorName = "missing" }, "missing"},
+			{"component is not a base", func(req *motion.MoveOnGlobeReq) { req.ComponentName = "fake-left" }, "only bases are supported"},
+			{"already at the destination", func(req *motion.MoveOnGlobeReq) { req.Destination = fakeGPSOrigin }, "already"},
+			{"destination too far away", func(req *motion.MoveOnGlobeReq) { req.Destination = geo.NewPoint(0, 0) }, "cannot move more than"},
+			{
+				"negative plan deviation",
+				func(req *motion.MoveOnGlobeReq) { req.MotionCfg = &motion.MotionConfiguration{PlanDeviationMM: -1} },
+				"PlanDeviationMM may not be negative",
+			},
+			{
+				"missing vision service",
+				func(req *motion.MoveOnGlobeReq) {
+					req.MotionCfg = &motion.MotionConfiguration{
+						ObstacleDetectors: []motion.ObstacleDetectorName{{VisionServiceName: "missing", CameraName: "cam"}},
+					}
+				},
+				"missing",
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				req := valid
+				tc.modify(&req)
+				_, err := ms.MoveOnGlobe(ctx, req)
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, tc.errMsg)
+			})
+		}
+
+		_, err := ms.PlanHistory(ctx, motion.PlanHistoryReq{ComponentName: "test-base"})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, ms.StopPlan(ctx, motion.StopPlanReq{ComponentName: "test-base"}), test.ShouldNotBeNil)
+	})
+}
+
+func TestExecutionHistory(t *testing.T) {
+	exec := newExecution(uuid.New(), "base", func() {})
+	exec.addPlan(motion.PlanWithMetadata{ID: uuid.New(), ExecutionID: exec.id}, nil)
+	reason := "obstacle detected"
+	exec.addPlan(motion.PlanWithMetadata{ID: uuid.New(), ExecutionID: exec.id}, &reason)
+	exec.setState(motion.PlanStateSucceeded, nil)
+	// terminal states are final
+	exec.setState(motion.PlanStateStopped, nil)
+
+	ph := exec.planHistory(false)
+	test.That(t, len(ph), test.ShouldEqual, 2)
+	test.That(t, len(ph[0].StatusHistory), test.ShouldEqual, 2)
+	test.That(t, ph[0].StatusHistory[0].State, test.ShouldEqual, motion.PlanStateSucceeded)
+	test.That(t, len(ph[1].StatusHistory), test.ShouldEqual, 2)
+	test.That(t, ph[1].StatusHistory[0].State, test.ShouldEqual, motion.PlanStateFailed)
+	test.That(t, *ph[1].StatusHistory[0].Reason, test.ShouldEqual, reason)
+
+	last := exec.planHistory(true)
+	test.That(t, len(last), test.ShouldEqual, 1)
+	test.That(t, last[0].Plan.ID, test.ShouldEqual, ph[0].Plan.ID)
+}
+
+func TestPlanningLimits(t *testing.T) {
+	limits, err := planningLimits(r3.Vector{}, r3.Vector{X: 3000, Y: -4000}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, limits, test.ShouldResemble, []referenceframe.Limit{{Min: -5000, Max: 8000}, {Min: -9000, Max: 5000}})
+
+	sphere, err := spatialmath.NewSphere(spatialmath.NewPoseFromPoint(r3.Vector{X: 100}), 50, "")
+	test.That(t, err, test.ShouldBeNil)
+	limits, err = planningLimits(r3.Vector{X: 60}, r3.Vector{X: 120}, []spatialmath.Geometry{sphere})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, limits, test.ShouldResemble, []referenceframe.Limit{{Min: 50, Max: 150}, {Min: -50, Max: 50}})
+
+	// the limits cover every region about its own center
+	box, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: 1000, Y: 2000}), r3.Vector{X: 60, Y: 80, Z: 0}, "")
+	test.That(t, err, test.ShouldBeNil)
+	limits, err = planningLimits(r3.Vector{X: 60}, r3.Vector{X: 1000, Y: 2000}, []spatialmath.Geometry{sphere, box})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, limits, test.ShouldResemble, []referenceframe.Limit{{Min: 50, Max: 1050}, {Min: -50, Max: 2050}})
+}
+
+// injectSLAM replaces the SLAM service of the motion service with one which localizes the base at the given pose on a
+// map containing the given points.
+func injectSLAM(t *testing.T, svc motion.Service, slamName string, position spatialmath.Pose, points []r3.Vector) {
+	t.Helper()
+	pc := pointcloud.NewBasicPointCloud(len(points))
+	for _, pt := range points {
+		test.That(t, pc.Set(pt, pointcloud.NewBasicData()), test.ShouldBeNil)
+	}
+	var buf bytes.Buffer
+	test.That(t, pointcloud.ToPCD(pc, &buf, pointcloud.PCDBinary), test.ShouldBeNil)
+
+	slamSvc := inject.NewSLAMService(slamName)
+	slamSvc.PositionFunc = func(ctx context.Context) (spatialmath.Pose, error) {
+		return position, nil
+	}
+	slamSvc.PointCloudMapFunc = func(ctx context.Context, returnEditedMap bool) (func() ([]byte, error), error) {
+		sent := false
+		return func() ([]byte, error) {
+			if sent {
+				return nil, io.EOF
+			}
+			sent = true
+			return buf.Bytes(), nil
+		}, nil
+	}
+
+	ms, ok := svc.(*builtIn)
+	test.That(t, ok, test.ShouldBeTrue)
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.slamServices[slamName] = slamSvc
+}
+
+func TestMoveOnMap(t *testing.T) {
+	ctx := context.Background()
+	noPolling := 0.
+	motionCfg := &motion.MotionConfiguration{PositionPollingFreqHz: &noPolling, ObstaclePollingFreqHz: &noPolling}
+
+	// the corners of the map, and a wall between the base and its destination
+	points := []r3.Vector{{X: -5000, Y: -5000}, {X: 5000, Y: 5000}}
+	for y := -1000.; y <= 1000; y += 10 {
+		points = append(points, r3.Vector{X: 2000, Y: y})
+	}
+	dst := spatialmath.NewPoseFromPoint(r3.Vector{X: 4000})
+
+	t.Run("succeeds around the map", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/fake_wheeled_base.json")
+		defer teardown()
+		injectSLAM(t, ms, "test_slam", spatialmath.NewZeroPose(), points)
+
+		executionID, err := ms.MoveOnMap(ctx, motion.MoveOnMapReq{
+			ComponentName: "test_base",
+			Destination:   dst,
+			SlamName:      "test_slam",
+			MotionCfg:     motionCfg,
+		})
+		test.That(t, err, test.ShouldBeNil)
+
+		ph := pollUntilTerminal(ctx, t, ms, "test_base")
+		test.That(t, ph[0].StatusHistory[0].State, test.ShouldEqual, motion.PlanStateSucceeded)
+		test.That(t, ph[0].Plan.ExecutionID, test.ShouldEqual, executionID)
+		test.That(t, ph[0].Plan.AnchorGeoPose, test.ShouldBeNil)
+
+		inputs, err := ph[0].Plan.Trajectory().GetFrameInputs("test_base")
+		test.That(t, err, test.ShouldBeNil)
+		last := inputs[len(inputs)-1]
+		test.That(t, last[0], test.ShouldAlmostEqual, dst.Point().X, 1e-3)
+		test.That(t, last[1], test.ShouldAlmostEqual, dst.Point().Y, 1e-3)
+		// the wall spans y in [-1000, 1000] at x = 2000, so the base must pass around one of its ends
+		for i := 1; i < len(inputs); i++ {
+			start := r3.Vector{X: inputs[i-1][0], Y: inputs[i-1][1]}
+			end := r3.Vector{X: inputs[i][0], Y: inputs[i][1]}
+			for _, pt := range interpolateSegment(start, end, 10) {
+				if math.Abs(pt.X-2000) < 1 {
+					test.That(t, math.Abs(pt.Y), test.ShouldBeGreaterThan, 1000)
+				}
+			}
+		}
+	})
+
+	t.Run("succeeds on a map away from the origin", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/fake_wheeled_base.json")
+		defer teardown()
+		start := spatialmath.NewPoseFromPoint(r3.Vector{X: 11000, Y: 11000})
+		injectSLAM(t, ms, "test_slam", start, []r3.Vector{{X: 10000, Y: 10000}, {X: 16000, Y: 16000}})
+		req := motion.MoveOnMapReq{
+			ComponentName: "test_base",
+			Destination:   spatialmath.NewPoseFromPoint(r3.Vector{X: 15000, Y: 15000}),
+			SlamName:      "test_slam",
+			MotionCfg:     motionCfg,
+		}
+
+		// the base may plan anywhere on the map
+		mr, err := ms.(*builtIn).newMoveOnMapRequest(ctx, req)
+		test.That(t, err, test.ShouldBeNil)
+		limits := mr.kb.model.DoF()
+		for _, limit := range limits[:2] {
+			test.That(t, limit.Min, test.ShouldBeLessThanOrEqualTo, 10000)
+			test.That(t, limit.Max, test.ShouldBeGreaterThanOrEqualTo, 16000)
+		}
+
+		_, err = ms.MoveOnMap(ctx, req)
+		test.That(t, err, test.ShouldBeNil)
+		ph := pollUntilTerminal(ctx, t, ms, "test_base")
+		test.That(t, ph[0].StatusHistory[0].State, test.ShouldEqual, motion.PlanStateSucceeded)
+		inputs, err := ph[0].Plan.Trajectory().GetFrameInputs("test_base")
+		test.That(t, err, test.ShouldBeNil)
+		last := inputs[len(inputs)-1]
+		test.That(t, last[0], test.ShouldAlmostEqual, 15000, 1e-3)
+		test.That(t, last[1], test.ShouldAlmostEqual, 15000, 1e-3)
+	})
+
+	t.Run("fails on invalid requests", func(t *testing.T) {
+		ms, teardown := setupMotionServiceFromConfig(t, "../data/fake_wheeled_base.json")
+		defer teardown()
+		injectSLAM(t, ms, "test_slam", spatialmath.NewZeroPose(), points)
+
+		valid := motion.MoveOnMapReq{
+			ComponentName: "test_base",
+			Destination:   dst,
+			SlamName:      "test_slam",
+			MotionCfg:     motionCfg,
+		}
+		for _, tc := range []struct {
+			name   string
+			modify func(req *motion.MoveOnMapReq)
+			errMsg string
+		}{
+			{"nil destination", func(req *motion.MoveOnMapReq) { req.Destination = nil }, "destination cannot be nil"},
+			{"missing slam service", func(req *motion.MoveOnMapReq) { req.SlamName = "missing" }, "missing"},
+			{"component is not a base", func(req *motion.MoveOnMapReq) { req.ComponentName = "fake-left" }, "only bases are supported"},
+			{
+				"destination outside of the map",
+				func(req *motion.MoveOnMapReq) { req.Destination = spatialmath.NewPoseFromPoint(r3.Vector{X: 6000}) },
+				"outside of the bounds",
+			},
+			{
+				"already at the destination",
+				func(req *motion.MoveOnMapReq) { req.Destination = spatialmath.NewPoseFromPoint(r3.Vector{X: 10}) },
+				"already",
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				req := valid
+				tc.modify(&req)
+				_, err := ms.MoveOnMap(ctx, req)
+				test.That(t, err, test.ShouldNotBeNil)
+				test.That(t, err.Error(), test.ShouldContainSubstring, tc.errMsg)
+			})
+		}
+	})
+}
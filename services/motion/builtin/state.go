@@ -0,0 +1,159 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/motion"
+)
+
+const (
+	// executions which have not changed state for this long are forgotten, as documented by motion.Service.
+	defaultExecutionTTL = 24 * time.Hour
+	// how often to check for executions which have outlived the TTL.
+	defaultExecutionTTLCheckInterval = time.Minute
+)
+
+// executionStore tracks every MoveOnGlobe and MoveOnMap execution of the motion service, such that their plans and
+// statuses can be queried until they have been terminated for longer than the TTL.
+type executionStore struct {
+	ttl    time.Duration
+	logger logging.Logger
+
+	mu sync.RWMutex
+	// sorted from least recently started to most recently started
+	executions []*execution
+	ttlWorker  *goutils.StoppableWorkers
+}
+
+// newExecutionStore returns an executionStore which evicts terminated executions older than ttl every checkInterval.
+func newExecutionStore(ttl, checkInterval time.Duration, logger logging.Logger) *executionStore {
+	s := &executionStore{ttl: ttl, logger: logger}
+	s.ttlWorker = goutils.NewStoppableWorkerWithTicker(checkInterval, func(ctx context.Context) {
+		s.purge(time.Now())
+	})
+	return s
+}
+
+// add records a new execution. Any execution of the same component which is still in progress is stopped before this
+// returns, as a component can only be moved by a single execution at a time. The previous execution is stopped without
+// holding the lock, as it may query the store while it winds down.
+func (s *executionStore) add(exec *execution) {
+	s.mu.Lock()
+	prev := s.latestLocked(exec.componentName)
+	s.executions = append(s.executions, exec)
+	s.mu.Unlock()
+	if prev != nil {
+		prev.stop()
+	}
+}
+
+func (s *executionStore) latestLocked(componentName string) *execution {
+	for i := len(s.executions) - 1; i >= 0; i-- {
+		if s.executions[i].componentName == componentName {
+			return s.executions[i]
+		}
+	}
+	return nil
+}
+
+// stopComponent stops the most recent execution of the component. This is a no-op if the execution has already
+// terminated.
+func (s *executionStore) stopComponent(componentName string) error {
+	s.mu.RLock()
+	exec := s.latestLocked(componentName)
+	s.mu.RUnlock()
+	if exec == nil {
+		return fmt.Errorf("no executions of component %s", componentName)
+	}
+	exec.stop()
+	return nil
+}
+
+// stopAll stops every execution which is still in progress.
+func (s *executionStore) stopAll() {
+	s.mu.RLock()
+	executions := append([]*execution{}, s.executions...)
+	s.mu.RUnlock()
+	for _, exec := range executions {
+		exec.stop()
+	}
+}
+
+// planHistory returns the plans of the given execution of the component, or the most recent one if executionID is nil.
+func (s *executionStore) planHistory(req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var exec *execution
+	if req.ExecutionID == uuid.Nil {
+		exec = s.latestLocked(req.ComponentName)
+	} else {
+		for _, e := range s.executions {
+			if e.id == req.ExecutionID && e.componentName == req.ComponentName {
+				exec = e
+				break
+			}
+		}
+	}
+	if exec == nil {
+		if req.ExecutionID != uuid.Nil {
+			return nil, fmt.Errorf("no plan history for execution %s of component %s", req.ExecutionID, req.ComponentName)
+		}
+		return nil, fmt.Errorf("no plan history for component %s", req.ComponentName)
+	}
+	return exec.planHistory(req.LastPlanOnly), nil
+}
+
+// listPlanStatuses returns the current status of every plan of every execution, optionally only including the plans
+// which are in progress.
+func (s *executionStore) listPlanStatuses(onlyActivePlans bool) []motion.PlanStatusWithID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	statuses := []motion.PlanStatusWithID{}
+	for _, exec := range s.executions {
+		for _, pws := range exec.planHistory(onlyActivePlans) {
+			status := pws.StatusHistory[0]
+			if onlyActivePlans && status.State != motion.PlanStateInProgress {
+				continue
+			}
+			statuses = append(statuses, motion.PlanStatusWithID{
+				PlanID:        pws.Plan.ID,
+				ComponentName: pws.Plan.ComponentName,
+				ExecutionID:   pws.Plan.ExecutionID,
+				Status:        status,
+			})
+		}
+	}
+	return statuses
+}
+
+// purge forgets every execution which terminated more than the TTL before now.
+func (s *executionStore) purge(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.executions[:0]
+	for _, exec := range s.executions {
+		if terminatedAt, terminated := exec.terminatedAt(); terminated && now.Sub(terminatedAt) > s.ttl {
+			s.logger.Debugf("evicting execution %s of component %s which terminated at %v", exec.id, exec.componentName, terminatedAt)
+			continue
+		}
+		kept = append(kept, exec)
+	}
+	// clear the tail so evicted executions can be garbage collected
+	for i := len(kept); i < len(s.executions); i++ {
+		s.executions[i] = nil
+	}
+	s.executions = kept
+}
+
+// close stops every execution and the eviction of old executions.
+func (s *executionStore) close() {
+	s.ttlWorker.Stop()
+	s.stopAll()
+}
@@ -0,0 +1,151 @@
+package builtin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/motion"
+)
+
+// newTestExecution returns an execution with a single in progress plan whose run loop exits once stopped, marking the
+// plan as stopped.
+func newTestExecution(componentName string) *execution {
+	stopCh := make(chan struct{})
+	exec := newExecution(uuid.New(), componentName, func() {
+		select {
+		case <-stopCh:
+		default:
+			close(stopCh)
+		}
+	})
+	exec.addPlan(motion.PlanWithMetadata{ID: uuid.New(), ComponentName: componentName, ExecutionID: exec.id}, nil)
+	go func() {
+		defer close(exec.done)
+		<-stopCh
+		exec.setState(motion.PlanStateStopped, nil)
+	}()
+	return exec
+}
+
+func TestExecutionStore(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	t.Run("tracks executions by component", func(t *testing.T) {
+		s := newExecutionStore(defaultExecutionTTL, time.Hour, logger)
+		defer s.close()
+
+		_, err := s.planHistory(motion.PlanHistoryReq{ComponentName: "base"})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, s.stopComponent("base"), test.ShouldNotBeNil)
+		test.That(t, s.listPlanStatuses(false), test.ShouldBeEmpty)
+
+		first := newTestExecution("base")
+		s.add(first)
+		other := newTestExecution("other")
+		s.add(other)
+		test.That(t, len(s.listPlanStatuses(true)), test.ShouldEqual, 2)
+
+		// starting a new execution of a component stops the previous one
+		second := newTestExecution("base")
+		s.add(second)
+		ph, err := s.planHistory(motion.PlanHistoryReq{ComponentName: "base", ExecutionID: first.id})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ph[0].StatusHistory[0].State, test.ShouldEqual, motion.PlanStateStopped)
+
+		ph, err = s.planHistory(motion.PlanHistoryReq{ComponentName: "base"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ph[0].Plan.ExecutionID, test.ShouldEqual, second.id)
+		test.That(t, ph[0].StatusHistory[0].State, test.ShouldEqual, motion.PlanStateInProgress)
+
+		// an execution id must belong to the component
+		_, err = s.planHistory(motion.PlanHistoryReq{ComponentName: "other", ExecutionID: first.id})
+		test.That(t, err, test.ShouldNotBeNil)
+
+		active := s.listPlanStatuses(true)
+		test.That(t, len(active), test.ShouldEqual, 2)
+		test.That(t, len(s.listPlanStatuses(false)), test.ShouldEqual, 3)
+
+		test.That(t, s.stopComponent("base"), test.ShouldBeNil)
+		// stopping a terminated execution is a no-op
+		test.That(t, s.stopComponent("base"), test.ShouldBeNil)
+		active = s.listPlanStatuses(true)
+		test.That(t, len(active), test.ShouldEqual, 1)
+		test.That(t, active[0].ExecutionID, test.ShouldEqual, other.id)
+		test.That(t, active[0].ComponentName, test.ShouldEqual, "other")
+	})
+
+	t.Run("stops executions which query the store while stopping", func(t *testing.T) {
+		s := newExecutionStore(defaultExecutionTTL, time.Hour, logger)
+		defer s.close()
+
+		// the run loop of the first execution lists the plan statuses before it exits
+		ctx, cancel := context.WithCancel(context.Background())
+		first := newExecution(uuid.New(), "base", cancel)
+		first.addPlan(motion.PlanWithMetadata{ID: uuid.New(), ComponentName: "base", ExecutionID: first.id}, nil)
+		go func() {
+			defer close(first.done)
+			<-ctx.Done()
+			s.listPlanStatuses(true)
+			first.setState(motion.PlanStateStopped, nil)
+		}()
+		s.add(first)
+
+		added := make(chan struct{})
+		go func() {
+			defer close(added)
+			s.add(newTestExecution("base"))
+		}()
+		select {
+		case <-added:
+		case <-time.After(5 * time.Second):
+			t.Fatal("adding an execution deadlocked stopping the previous one")
+		}
+		active := s.listPlanStatuses(true)
+		test.That(t, len(active), test.ShouldEqual, 1)
+		test.That(t, active[0].ExecutionID, test.ShouldNotEqual, first.id)
+		s.stopAll()
+		test.That(t, s.listPlanStatuses(true), test.ShouldBeEmpty)
+	})
+
+	t.Run("evicts executions which terminated before the ttl", func(t *testing.T) {
+		s := newExecutionStore(time.Hour, time.Hour, logger)
+		defer s.close()
+
+		stopped := newTestExecution("base")
+		s.add(stopped)
+		active := newTestExecution("other")
+		s.add(active)
+		test.That(t, s.stopComponent("base"), test.ShouldBeNil)
+
+		s.purge(time.Now())
+		test.That(t, len(s.listPlanStatuses(false)), test.ShouldEqual, 2)
+
+		// in progress executions are never evicted
+		s.purge(time.Now().Add(2 * time.Hour))
+		statuses := s.listPlanStatuses(false)
+		test.That(t, len(statuses), test.ShouldEqual, 1)
+		test.That(t, statuses[0].ExecutionID, test.ShouldEqual, active.id)
+		_, err := s.planHistory(motion.PlanHistoryReq{ComponentName: "base"})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("evicts executions in the background", func(t *testing.T) {
+		s := newExecutionStore(time.Millisecond, 5*time.Millisecond, logger)
+		defer s.close()
+
+		s.add(newTestExecution("base"))
+		test.That(t, s.stopComponent("base"), test.ShouldBeNil)
+		deadline := time.Now().Add(5 * time.Second)
+		for len(s.listPlanStatuses(false)) > 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("execution was not evicted")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+}
@@ -45,6 +45,7 @@ var (
 	errNegativeObstaclePollingFrequencyHz = errors.New("obstacle_polling_frequency_hz must be non-negative if set")
 	errNegativePlanDeviationM             = errors.New("plan_deviation_m must be non-negative if set")
 	errNegativeReplanCostFactor           = errors.New("replan_cost_factor must be non-negative if set")
+	errNegativeExploreRadiusM             = errors.New("explore_radius_m must be non-negative if set")
 	errObstacleGeomWithTranslation        = errors.New("obstacle " + geomWithTranslation)
 	errBoundingRegionsGeomWithTranslation = errors.New("bounding region " + geomWithTranslation)
 	errObstacleGeomParse                  = errors.New("obstacle unable to be converted from geometry config")
@@ -108,6 +109,7 @@ type Config struct {
 	ObstaclePollingFrequencyHz float64                          `json:"obstacle_polling_frequency_hz,omitempty"`
 	PlanDeviationM             float64                          `json:"plan_deviation_m,omitempty"`
 	ReplanCostFactor           float64                          `json:"replan_cost_factor,omitempty"`
+	ExploreRadiusM             float64                          `json:"explore_radius_m,omitempty"`
 	LogFilePath                string                           `json:"log_file_path"`
 }
 
@@ -181,6 +183,9 @@ func (conf *Config) Validate(path string) ([]string, []string, error) {
 	if conf.ReplanCostFactor < 0 {
 		return nil, nil, errNegativeReplanCostFactor
 	}
+	if conf.ExploreRadiusM < 0 {
+		return nil, nil, errNegativeExploreRadiusM
+	}
 
 	// Ensure obstacles have no translation
 	for _, obs := range conf.Obstacles {
@@ -241,6 +246,7 @@ type builtIn struct {
 
 	motionCfg        *motion.MotionConfiguration
 	replanCostFactor float64
+	exploreRadiusM   float64
 
 	logger                    logging.Logger
 	wholeServiceCancelFunc    func()
@@ -297,6 +303,10 @@ func (svc *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies,
 	if svcConfig.ReplanCostFactor != 0 {
 		replanCostFactor = svcConfig.ReplanCostFactor
 	}
+	exploreRadiusM := defaultExploreRadiusM
+	if svcConfig.ExploreRadiusM != 0 {
+		exploreRadiusM = svcConfig.ExploreRadiusM
+	}
 
 	motionServiceName := resource.DefaultServiceName
 	if svcConfig.MotionServiceName != "" {
@@ -367,7 +377,7 @@ func (svc *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies,
 
 	// Reconfigure the store if necessary
 	if svc.storeType != string(storeCfg.Type) {
-		newStore, err := navigation.NewStoreFromConfig(ctx, svcConfig.Store)
+		newStore, err := navigation.NewStoreFromConfigForService(ctx, svc.Name(), svcConfig.Store)
 		if err != nil {
 			return err
 		}
@@ -394,6 +404,7 @@ func (svc *builtIn) Reconfigure(ctx context.Context, deps resource.Dependencies,
 	svc.obstacles = newObstacles
 	svc.boundingRegions = newBoundingRegions
 	svc.replanCostFactor = replanCostFactor
+	svc.exploreRadiusM = exploreRadiusM
 	svc.visionServicesByName = visionServicesByName
 	svc.motionCfg = &motion.MotionConfiguration{
 		ObstacleDetectors:     obstacleDetectorNamePairs,
@@ -440,10 +451,12 @@ func (svc *builtIn) SetMode(ctx context.Context, mode navigation.Mode, extra map
 	}
 
 	switch svc.mode {
-	case navigation.ModeManual, navigation.ModeExplore:
+	case navigation.ModeManual:
 		// do nothing
 	case navigation.ModeWaypoint:
 		svc.startWaypointMode(cancelCtx, extra)
+	case navigation.ModeExplore:
+		svc.startExploreMode(cancelCtx, extra)
 	}
 
 	return nil
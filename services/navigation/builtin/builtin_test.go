@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
 	"go.uber.org/atomic"
+	commonpb "go.viam.com/api/common/v1"
 	"go.viam.com/test"
 	"go.viam.com/utils"
 
@@ -180,6 +181,16 @@ func TestValidateConfig(t *testing.T) {
 			numDeps:     0,
 			expectedErr: errNegativeReplanCostFactor,
 		},
+		{
+			description: "invalid config negative explore_radius_m",
+			cfg: Config{
+				BaseName:           "base",
+				MovementSensorName: "localizer",
+				ExploreRadiusM:     -1,
+			},
+			numDeps:     0,
+			expectedErr: errNegativeExploreRadiusM,
+		},
 	}
 
 	for _, tt := range cases {
@@ -662,6 +673,10 @@ func setupStartWaypointExplore(ctx context.Context, t *testing.T, logger logging
 	test.That(t, err, test.ShouldBeNil)
 
 	injectMovementSensor := inject.NewMovementSensor("test_movement")
+	// explore mode can't localize, so it never calls MoveOnGlobe and the history of waypoint mode is preserved
+	injectMovementSensor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return nil, 0, errors.New("no position")
+	}
 	visionService := inject.NewVisionService("vision")
 	camera := inject.NewCamera("camera")
 	config := resource.Config{
@@ -689,8 +704,7 @@ func setupStartWaypointExplore(ctx context.Context, t *testing.T, logger logging
 		injectMovementSensor.Name(): injectMovementSensor,
 		visionService.Name():        visionService,
 		camera.Name():               camera,
-		// to placate the explore struct to not panic
-		fsSvc.Name(): fsSvc,
+		fsSvc.Name():                fsSvc,
 	}
 	ns, err := NewBuiltIn(ctx, deps, config, logger)
 	test.That(t, err, test.ShouldBeNil)
@@ -1662,6 +1676,172 @@ func TestStartWaypoint(t *testing.T) {
 	})
 }
 
+func TestExplore(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	t.Run("goals are chosen within the bounding regions when there is a GPS map", func(t *testing.T) {
+		injectBase := inject.NewBase("test_base")
+		injectMovementSensor := inject.NewMovementSensor("test_movement")
+		location := geo.NewPoint(40, -74)
+		injectMovementSensor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+			return location, 0, nil
+		}
+
+		var mu sync.Mutex
+		var mogrs []motion.MoveOnGlobeReq
+		reachedThreeGoals := make(chan struct{})
+		injectMS := injectmotion.NewMotionService("test_motion")
+		injectMS.MoveOnGlobeFunc = func(ctx context.Context, req motion.MoveOnGlobeReq) (motion.ExecutionID, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			mogrs = append(mogrs, req)
+			if len(mogrs) == 3 {
+				close(reachedThreeGoals)
+			}
+			return uuid.New(), nil
+		}
+		injectMS.PlanHistoryFunc = func(ctx context.Context, req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+			return []motion.PlanWithStatus{{StatusHistory: []motion.PlanStatus{{State: motion.PlanStateSucceeded}}}}, nil
+		}
+		injectMS.StopPlanFunc = func(ctx context.Context, req motion.StopPlanReq) error {
+			return nil
+		}
+
+		regionLocation := geo.NewPoint(40.0001, -74)
+		config := resource.Config{
+			ConvertedAttributes: &Config{
+				Store:              navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+				BaseName:           "test_base",
+				MovementSensorName: "test_movement",
+				MotionServiceName:  "test_motion",
+				BoundingRegions: []*spatialmath.GeoGeometryConfig{
+					{
+						Location:   &commonpb.GeoPoint{Latitude: regionLocation.Lat(), Longitude: regionLocation.Lng()},
+						Geometries: []*spatialmath.GeometryConfig{{Type: spatialmath.BoxType, X: 20000, Y: 10000, Z: 1000}},
+					},
+				},
+			},
+		}
+		deps := resource.Dependencies{
+			injectMS.Name():             injectMS,
+			injectBase.Name():           injectBase,
+			injectMovementSensor.Name(): injectMovementSensor,
+		}
+		ns, err := NewBuiltIn(ctx, deps, config, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer func() {
+			test.That(t, ns.Close(ctx), test.ShouldBeNil)
+		}()
+
+		test.That(t, ns.SetMode(ctx, navigation.ModeExplore, nil), test.ShouldBeNil)
+		select {
+		case <-reachedThreeGoals:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for explore mode to choose goals")
+		}
+		test.That(t, ns.SetMode(ctx, navigation.ModeManual, nil), test.ShouldBeNil)
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, req := range mogrs {
+			test.That(t, req.ComponentName, test.ShouldEqual, "test_base")
+			test.That(t, req.MovementSensorName, test.ShouldEqual, "test_movement")
+			test.That(t, req.Extra["motion_profile"], test.ShouldEqual, "position_only")
+			test.That(t, len(req.BoundingRegions), test.ShouldEqual, 1)
+			inRegion := spatialmath.GeoPointToPoint(req.Destination, regionLocation)
+			test.That(t, math.Abs(inRegion.X), test.ShouldBeLessThanOrEqualTo, 10001)
+			test.That(t, math.Abs(inRegion.Y), test.ShouldBeLessThanOrEqualTo, 5001)
+		}
+		// goals are not chosen again once explore mode is exited
+		numGoals := len(mogrs)
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		test.That(t, len(mogrs), test.ShouldEqual, numGoals)
+	})
+
+	t.Run("the base steps around detected obstacles when there is no map", func(t *testing.T) {
+		injectBase := inject.NewBase("test_base")
+		injectBase.GeometriesFunc = func(ctx context.Context) ([]spatialmath.Geometry, error) {
+			sphere, err := spatialmath.NewSphere(spatialmath.NewZeroPose(), 100, "")
+			return []spatialmath.Geometry{sphere}, err
+		}
+		var mu sync.Mutex
+		var spins []float64
+		var moves []int
+		reachedThreeSteps := make(chan struct{})
+		injectBase.SpinFunc = func(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+			mu.Lock()
+			defer mu.Unlock()
+			spins = append(spins, angleDeg)
+			return nil
+		}
+		injectBase.MoveStraightFunc = func(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+			mu.Lock()
+			defer mu.Unlock()
+			moves = append(moves, distanceMm)
+			if len(moves) == 3 {
+				close(reachedThreeSteps)
+			}
+			return nil
+		}
+
+		// a wall a meter in front of the camera, which is assumed to be looking forward from the center of the base
+		visionService := inject.NewVisionService("vision")
+		visionService.GetObjectPointCloudsFunc = func(ctx context.Context, cameraName string, extra map[string]interface{},
+		) ([]*viz.Object, error) {
+			wall, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{Z: 1000}), r3.Vector{X: 400, Y: 400, Z: 10}, "wall")
+			if err != nil {
+				return nil, err
+			}
+			obj, err := viz.NewObjectWithLabel(pointcloud.NewBasicEmpty(), "wall", wall.ToProtobuf())
+			return []*viz.Object{obj}, err
+		}
+		camera := inject.NewCamera("camera")
+		injectMS := injectmotion.NewMotionService("test_motion")
+
+		config := resource.Config{
+			ConvertedAttributes: &Config{
+				Store:             navigation.StoreConfig{Type: navigation.StoreTypeMemory},
+				BaseName:          "test_base",
+				MapType:           "None",
+				MotionServiceName: "test_motion",
+				ExploreRadiusM:    1.5,
+				ObstacleDetectors: []*ObstacleDetectorNameConfig{{VisionServiceName: "vision", CameraName: "camera"}},
+			},
+		}
+		deps := resource.Dependencies{
+			injectMS.Name():      injectMS,
+			injectBase.Name():    injectBase,
+			visionService.Name(): visionService,
+			camera.Name():        camera,
+		}
+		ns, err := NewBuiltIn(ctx, deps, config, logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer func() {
+			test.That(t, ns.Close(ctx), test.ShouldBeNil)
+		}()
+
+		test.That(t, ns.SetMode(ctx, navigation.ModeExplore, nil), test.ShouldBeNil)
+		select {
+		case <-reachedThreeSteps:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for explore mode to move the base")
+		}
+		test.That(t, ns.SetMode(ctx, navigation.ModeManual, nil), test.ShouldBeNil)
+
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(t, len(spins), test.ShouldBeGreaterThanOrEqualTo, 3)
+		for i, spin := range spins {
+			// the base and the wall are both 200mm either side of straight ahead
+			test.That(t, math.Abs(spin), test.ShouldBeGreaterThan, 15)
+			test.That(t, moves[i], test.ShouldBeBetweenOrEqual, 1000, 1500)
+		}
+	})
+}
+
 func TestValidateGeometry(t *testing.T) {
 	cfg := Config{
 		BaseName:           "base",
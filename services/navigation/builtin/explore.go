@@ -0,0 +1,356 @@
+package builtin
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/navigation"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	// how far from where explore mode was started goals may be chosen when no bounding regions are configured.
+	defaultExploreRadiusM = 10.
+
+	// goals closer than this to the current position are not worth driving to.
+	minExploreGoalDistanceM = 1.
+
+	// the longest distance the base drives blindly in a single step when there is no map.
+	maxExploreStepM = 2.
+
+	// the number of valid goals which are compared against each other when choosing the next goal.
+	exploreCandidateGoals = 20
+
+	// the number of samples drawn per candidate goal before giving up.
+	exploreSamplesPerCandidate = 50
+
+	// how far goals must be from static obstacles.
+	exploreObstacleClearanceMM = 500.
+
+	// the radius assumed for a base which does not report any geometries.
+	defaultExploreBaseRadiusMM = 300.
+
+	// the height of the volume swept by the base when checking for obstacles without a map, such that obstacles at any
+	// height in front of the camera are avoided.
+	exploreSweptVolumeHeightMM = 1e5
+
+	// how long to wait before choosing a new goal after an error.
+	exploreRetryInterval = time.Second
+)
+
+// explorer holds the state of a single run of explore mode.
+type explorer struct {
+	rng     *rand.Rand
+	radiusM float64
+
+	// the location at which exploring with a GPS map started, which all goals are chosen relative to.
+	origin *geo.Point
+	// every location the base has been observed at, in mm relative to origin.
+	visited []r3.Vector
+}
+
+func newExplorer(radiusM float64) *explorer {
+	//nolint:gosec
+	return &explorer{rng: rand.New(rand.NewSource(time.Now().UnixNano())), radiusM: radiusM}
+}
+
+// startExploreMode drives the base towards an endless series of randomly chosen goals until ctx is cancelled.
+// With a GPS map, goals are chosen within the bounding regions (or within the explore radius if there are none) and
+// away from where the base has already been, and MoveOnGlobe is relied upon to avoid obstacles. Without a map, the base
+// takes random steps in directions which are not blocked by the obstacles seen by the obstacle detectors.
+func (svc *builtIn) startExploreMode(ctx context.Context, extra map[string]interface{}) {
+	if extra == nil {
+		extra = map[string]interface{}{}
+	}
+	extra["motion_profile"] = "position_only"
+
+	e := newExplorer(svc.exploreRadiusM)
+	svc.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+
+			var err error
+			if svc.mapType == navigation.GPSMap {
+				err = svc.exploreGlobeStep(ctx, e, extra)
+			} else {
+				err = svc.exploreStep(ctx, e)
+			}
+			if err != nil && ctx.Err() == nil {
+				svc.logger.CWarnf(ctx, "choosing a new exploration goal since exploring errored out: %s", err)
+				utils.SelectContextOrWait(ctx, exploreRetryInterval)
+			}
+		}
+	}, svc.activeBackgroundWorkers.Done)
+}
+
+// exploreGlobeStep chooses the next goal from the current location of the movement sensor and drives to it.
+func (svc *builtIn) exploreGlobeStep(ctx context.Context, e *explorer, extra map[string]interface{}) error {
+	loc, _, err := svc.movementSensor.Position(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if e.origin == nil {
+		e.origin = loc
+	}
+	e.visited = append(e.visited, spatialmath.GeoPointToPoint(loc, e.origin))
+
+	goal, err := e.nextGeoGoal(svc.obstacles, svc.boundingRegions)
+	if err != nil {
+		return err
+	}
+	svc.logger.CInfof(ctx, "exploring towards: %+v", *goal)
+	if err := svc.exploreTo(ctx, goal, extra); err != nil {
+		return err
+	}
+	svc.logger.CInfof(ctx, "reached exploration goal: %+v", *goal)
+	return nil
+}
+
+// nextGeoGoal returns the sampled goal which is furthest from every location visited so far.
+func (e *explorer) nextGeoGoal(obstacles, boundingRegions []*spatialmath.GeoGeometry) (*geo.Point, error) {
+	obstacleGeoms := spatialmath.GeoGeometriesToGeometries(obstacles, e.origin)
+	regionGeoms := spatialmath.GeoGeometriesToGeometries(boundingRegions, e.origin)
+	current := e.visited[len(e.visited)-1]
+
+	var best r3.Vector
+	bestScore := -1.
+	candidates := 0
+	for i := 0; i < exploreCandidateGoals*exploreSamplesPerCandidate && candidates < exploreCandidateGoals; i++ {
+		candidate, err := e.sample(regionGeoms)
+		if err != nil {
+			return nil, err
+		}
+		if candidate.Sub(current).Norm() < 1e3*minExploreGoalDistanceM {
+			continue
+		}
+		valid, err := validExploreGoal(candidate, obstacleGeoms, regionGeoms)
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			continue
+		}
+		candidates++
+
+		score := math.Inf(1)
+		for _, v := range e.visited {
+			score = math.Min(score, candidate.Sub(v).Norm())
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	if bestScore < 0 {
+		return nil, errors.New("unable to find an exploration goal which is within the bounding regions and clear of obstacles")
+	}
+	return spatialmath.PoseToGeoPose(spatialmath.NewGeoPose(e.origin, 0), spatialmath.NewPoseFromPoint(best)).Location(), nil
+}
+
+// sample returns a point drawn uniformly from a disc around a random bounding region, or around the origin if there
+// are no bounding regions.
+func (e *explorer) sample(regionGeoms []spatialmath.Geometry) (r3.Vector, error) {
+	center := r3.Vector{}
+	radius := 1e3 * e.radiusM
+	if len(regionGeoms) > 0 {
+		region := regionGeoms[e.rng.Intn(len(regionGeoms))]
+		sphere, err := spatialmath.BoundingSphere(region.Transform(spatialmath.PoseInverse(region.Pose())))
+		if err != nil {
+			return r3.Vector{}, err
+		}
+		center = region.Pose().Point()
+		radius = sphere.ToProtobuf().GetSphere().GetRadiusMm()
+	}
+	r := radius * math.Sqrt(e.rng.Float64())
+	theta := 2 * math.Pi * e.rng.Float64()
+	return r3.Vector{X: center.X + r*math.Cos(theta), Y: center.Y + r*math.Sin(theta)}, nil
+}
+
+// validExploreGoal returns whether the goal lies within a bounding region, if there are any, and clear of obstacles.
+func validExploreGoal(goal r3.Vector, obstacleGeoms, regionGeoms []spatialmath.Geometry) (bool, error) {
+	pt := spatialmath.NewPoint(goal, "")
+	for _, obstacle := range obstacleGeoms {
+		collides, _, err := obstacle.CollidesWith(pt, exploreObstacleClearanceMM)
+		if err != nil {
+			return false, err
+		}
+		if collides {
+			return false, nil
+		}
+	}
+	if len(regionGeoms) == 0 {
+		return true, nil
+	}
+	for _, region := range regionGeoms {
+		inside, _, err := region.CollidesWith(pt, 0)
+		if err != nil {
+			return false, err
+		}
+		if inside {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// exploreTo moves the base to the goal with MoveOnGlobe, returning once the execution has terminated.
+func (svc *builtIn) exploreTo(ctx context.Context, goal *geo.Point, extra map[string]interface{}) error {
+	req := motion.MoveOnGlobeReq{
+		ComponentName:      svc.base.Name().Name,
+		Destination:        goal,
+		Heading:            math.NaN(),
+		MovementSensorName: svc.movementSensor.Name().Name,
+		Obstacles:          svc.obstacles,
+		MotionCfg:          svc.motionCfg,
+		BoundingRegions:    svc.boundingRegions,
+		Extra:              extra,
+	}
+	executionID, err := svc.motionService.MoveOnGlobe(ctx, req)
+	if err != nil {
+		return err
+	}
+	// is a NoOp if the execution has already terminated
+	defer func() {
+		timeoutCtx, timeoutCancelFn := context.WithTimeout(context.Background(), time.Second*5)
+		defer timeoutCancelFn()
+		if err := svc.motionService.StopPlan(timeoutCtx, motion.StopPlanReq{ComponentName: req.ComponentName}); err != nil {
+			svc.logger.CErrorf(ctx, "hit error trying to stop plan %s", err)
+		}
+	}()
+
+	return motion.PollHistoryUntilSuccessOrError(ctx, svc.motionService, planHistoryPollFrequency,
+		motion.PlanHistoryReq{
+			ComponentName: req.ComponentName,
+			ExecutionID:   executionID,
+			LastPlanOnly:  true,
+		},
+	)
+}
+
+// exploreStep spins the base to a random direction which is not blocked by any detected obstacle and drives a random
+// distance in that direction. Without a map there is no way of knowing where the base is, so bounding regions and
+// static obstacles cannot be respected.
+func (svc *builtIn) exploreStep(ctx context.Context, e *explorer) error {
+	obstacles, err := svc.obstaclesInBaseFrame(ctx)
+	if err != nil {
+		return err
+	}
+	radius, err := svc.baseRadius(ctx)
+	if err != nil {
+		return err
+	}
+
+	maxStepM := math.Max(minExploreGoalDistanceM, math.Min(maxExploreStepM, e.radiusM))
+	for i := 0; i < exploreCandidateGoals; i++ {
+		angleDeg := 360*e.rng.Float64() - 180
+		distanceM := minExploreGoalDistanceM + (maxStepM-minExploreGoalDistanceM)*e.rng.Float64()
+		blocked, err := stepBlocked(angleDeg, 1e3*distanceM, radius, obstacles)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			continue
+		}
+
+		svc.logger.CInfof(ctx, "exploring %.2fm after spinning %.1f degrees", distanceM, angleDeg)
+		if err := svc.base.Spin(ctx, angleDeg, svc.motionCfg.AngularDegsPerSec, nil); err != nil {
+			return err
+		}
+		return svc.base.MoveStraight(ctx, int(1e3*distanceM), 1e3*svc.motionCfg.LinearMPerSec, nil)
+	}
+	return errors.New("every sampled exploration direction is blocked by an obstacle")
+}
+
+// stepBlocked returns whether the volume swept by a base of the given radius, spinning by angleDeg and then driving
+// distanceMM, collides with any of the obstacles, which are expressed in the frame of the base.
+func stepBlocked(angleDeg, distanceMM, radius float64, obstacles []spatialmath.Geometry) (bool, error) {
+	angle := angleDeg * math.Pi / 180
+	// the base drives along its +Y axis and a positive spin is counterclockwise
+	mid := r3.Vector{X: -math.Sin(angle), Y: math.Cos(angle)}.Mul(distanceMM / 2)
+	swept, err := spatialmath.NewBox(
+		spatialmath.NewPose(mid, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: angleDeg}),
+		r3.Vector{X: 2 * radius, Y: distanceMM + 2*radius, Z: exploreSweptVolumeHeightMM},
+		"",
+	)
+	if err != nil {
+		return false, err
+	}
+	for _, obstacle := range obstacles {
+		collides, _, err := swept.CollidesWith(obstacle, 0)
+		if err != nil {
+			return false, err
+		}
+		if collides {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// obstaclesInBaseFrame returns every obstacle detected by the obstacle detectors, expressed in the frame of the base.
+// Cameras which are not in the frame system are assumed to be at the center of the base looking forward.
+func (svc *builtIn) obstaclesInBaseFrame(ctx context.Context) ([]spatialmath.Geometry, error) {
+	baseName := svc.base.Name().ShortName()
+	var obstacles []spatialmath.Geometry
+	for _, detector := range svc.motionCfg.ObstacleDetectors {
+		visSvc, ok := svc.visionServicesByName[detector.VisionServiceName]
+		if !ok {
+			return nil, errors.Errorf("vision service with name: %s not found", detector.VisionServiceName)
+		}
+		detections, err := visSvc.GetObjectPointClouds(ctx, detector.CameraName, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// the camera's +Z axis points forward and its +Y axis points down
+		cameraInBase := spatialmath.NewPoseFromOrientation(&spatialmath.R4AA{Theta: -math.Pi / 2, RX: 1})
+		if svc.fsService != nil {
+			cameraOrigin := referenceframe.NewPoseInFrame(detector.CameraName, spatialmath.NewZeroPose())
+			pif, err := svc.fsService.TransformPose(ctx, cameraOrigin, baseName, nil)
+			if err == nil {
+				cameraInBase = pif.Pose()
+			} else {
+				svc.logger.CDebugf(ctx, "we assume the camera named: %s is at the center of the base named: %s due to err: %v",
+					detector.CameraName, baseName, err.Error())
+			}
+		}
+		for _, detection := range detections {
+			if detection.Geometry == nil {
+				continue
+			}
+			obstacles = append(obstacles, detection.Geometry.Transform(cameraInBase))
+		}
+	}
+	return obstacles, nil
+}
+
+// baseRadius returns the radius of the smallest circle about the center of the base which contains its geometries.
+func (svc *builtIn) baseRadius(ctx context.Context) (float64, error) {
+	geoms, err := svc.base.Geometries(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	radius := 0.
+	for _, g := range geoms {
+		sphere, err := spatialmath.BoundingSphere(g)
+		if err != nil {
+			return 0, err
+		}
+		radius = math.Max(radius, sphere.ToProtobuf().GetSphere().GetRadiusMm())
+	}
+	if radius == 0 {
+		return defaultExploreBaseRadiusMM, nil
+	}
+	return radius, nil
+}
@@ -0,0 +1,12 @@
+// export_file_store_test.go adds functionality to the package that we only want to use and expose during testing.
+package navigation
+
+import "testing"
+
+// SetDefaultFileNavStoreDir sets the directory of the default paths of file stores until the test ends.
+func SetDefaultFileNavStoreDir(tb testing.TB, dir string) {
+	tb.Helper()
+	prev := defaultFileNavStoreDir
+	defaultFileNavStoreDir = dir
+	tb.Cleanup(func() { defaultFileNavStoreDir = prev })
+}
@@ -0,0 +1,247 @@
+package navigation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go.viam.com/rdk/resource"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// defaultFileNavStoreDir holds a directory per navigation service, where its FileNavigationStore persists waypoints
+// when no path is configured.
+var defaultFileNavStoreDir = filepath.Join(rutils.ViamDotDir, "navigation")
+
+// fileNavStoreContents is the on disk representation of a FileNavigationStore.
+type fileNavStoreContents struct {
+	Waypoints []fileWaypoint `json:"waypoints"`
+}
+
+type fileWaypoint struct {
+	ID      primitive.ObjectID `json:"id"`
+	Visited bool               `json:"visited"`
+	Order   int                `json:"order"`
+	Lat     float64            `json:"latitude"`
+	Long    float64            `json:"longitude"`
+}
+
+// NewFileNavigationStore returns a FileNavigationStore holding the waypoints previously persisted to the "path" in the
+// config, or to a file of the named navigation service in the viam home directory if no path is given.
+func NewFileNavigationStore(name resource.Name, config map[string]interface{}) (*FileNavigationStore, error) {
+	path, ok := config["path"].(string)
+	if !ok || path == "" {
+		if name.Name == "" {
+			return nil, errors.New("a file navigation store needs a path when it is not for a named navigation service")
+		}
+		path = filepath.Join(defaultFileNavStoreDir, name.Name, "waypoints.json")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	store := &FileNavigationStore{path: path}
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return store, nil
+	case err != nil:
+		return nil, err
+	}
+	var contents fileNavStoreContents
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, errors.Wrapf(err, "navigation store file %q is corrupt", path)
+	}
+	for _, wp := range contents.Waypoints {
+		store.waypoints = append(store.waypoints, Waypoint(wp))
+	}
+	sortWaypoints(store.waypoints)
+	return store, nil
+}
+
+// FileNavigationStore holds the waypoints for the navigation service in memory and persists every change to a file.
+// Changes are written to a temporary file which then atomically replaces the previous one, so the file always holds
+// either the waypoints before or after a change, even if power is lost while writing.
+type FileNavigationStore struct {
+	path string
+
+	mu sync.RWMutex
+	// sorted in the order in which they are navigated to
+	waypoints []Waypoint
+}
+
+// sortWaypoints sorts the waypoints in the same order as the MongoDBNavigationStore: by descending order and then by
+// ascending ID, which is the order in which the waypoints were added.
+func sortWaypoints(wps []Waypoint) {
+	sort.SliceStable(wps, func(i, j int) bool {
+		if wps[i].Order != wps[j].Order {
+			return wps[i].Order > wps[j].Order
+		}
+		return bytes.Compare(wps[i].ID[:], wps[j].ID[:]) < 0
+	})
+}
+
+// persistLocked replaces the file with wps, and only updates the waypoints held in memory once that has succeeded.
+func (store *FileNavigationStore) persistLocked(wps []Waypoint) error {
+	contents := fileNavStoreContents{Waypoints: make([]fileWaypoint, 0, len(wps))}
+	for _, wp := range wps {
+		contents.Waypoints = append(contents.Waypoints, fileWaypoint(wp))
+	}
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		// a no-op once the temporary file has been renamed
+		//nolint:errcheck,gosec
+		os.Remove(tmpPath)
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		//nolint:errcheck,gosec
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		//nolint:errcheck,gosec
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, store.path); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(store.path)); err != nil {
+		return err
+	}
+
+	store.waypoints = wps
+	return nil
+}
+
+// syncDir flushes the directory entries of dir, such that a rename within it survives power loss.
+func syncDir(dir string) error {
+	//nolint:gosec
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer d.Close()
+	return d.Sync()
+}
+
+// Waypoints returns a copy of all of the unvisited waypoints in the FileNavigationStore.
+func (store *FileNavigationStore) Waypoints(ctx context.Context) ([]Waypoint, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	wps := make([]Waypoint, 0, len(store.waypoints))
+	for _, wp := range store.waypoints {
+		if wp.Visited {
+			continue
+		}
+		wps = append(wps, wp)
+	}
+	return wps, nil
+}
+
+// AddWaypoint adds a waypoint to the FileNavigationStore.
+func (store *FileNavigationStore) AddWaypoint(ctx context.Context, point *geo.Point) (Waypoint, error) {
+	if ctx.Err() != nil {
+		return Waypoint{}, ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	newPoint := Waypoint{
+		ID:   primitive.NewObjectID(),
+		Lat:  point.Lat(),
+		Long: point.Lng(),
+	}
+	wps := append(append(make([]Waypoint, 0, len(store.waypoints)+1), store.waypoints...), newPoint)
+	sortWaypoints(wps)
+	if err := store.persistLocked(wps); err != nil {
+		return Waypoint{}, err
+	}
+	return newPoint, nil
+}
+
+// RemoveWaypoint removes a waypoint from the FileNavigationStore.
+func (store *FileNavigationStore) RemoveWaypoint(ctx context.Context, id primitive.ObjectID) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	wps := make([]Waypoint, 0, len(store.waypoints))
+	for _, wp := range store.waypoints {
+		if wp.ID == id {
+			continue
+		}
+		wps = append(wps, wp)
+	}
+	if len(wps) == len(store.waypoints) {
+		return nil
+	}
+	return store.persistLocked(wps)
+}
+
+// NextWaypoint gets the next waypoint that has not been visited.
+func (store *FileNavigationStore) NextWaypoint(ctx context.Context) (Waypoint, error) {
+	if ctx.Err() != nil {
+		return Waypoint{}, ctx.Err()
+	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	for _, wp := range store.waypoints {
+		if !wp.Visited {
+			return wp, nil
+		}
+	}
+	return Waypoint{}, errNoMoreWaypoints
+}
+
+// WaypointVisited sets that a waypoint has been visited.
+func (store *FileNavigationStore) WaypointVisited(ctx context.Context, id primitive.ObjectID) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	wps := make([]Waypoint, 0, len(store.waypoints))
+	changed := false
+	for _, wp := range store.waypoints {
+		if wp.ID == id && !wp.Visited {
+			wp.Visited = true
+			changed = true
+		}
+		wps = append(wps, wp)
+	}
+	if !changed {
+		return nil
+	}
+	return store.persistLocked(wps)
+}
+
+// Close does nothing, as every change has already been persisted.
+func (store *FileNavigationStore) Close(ctx context.Context) error {
+	return nil
+}
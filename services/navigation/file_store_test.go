@@ -0,0 +1,115 @@
+package navigation_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/services/navigation"
+)
+
+func TestFileNavigationStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "waypoints.json")
+	cfg := navigation.StoreConfig{Type: navigation.StoreTypeFile, Config: map[string]interface{}{"path": path}}
+	test.That(t, cfg.Validate(""), test.ShouldBeNil)
+
+	store, err := navigation.NewStoreFromConfig(ctx, cfg)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = store.NextWaypoint(ctx)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	points := []*geo.Point{geo.NewPoint(1, 2), geo.NewPoint(3, 4), geo.NewPoint(5, 6)}
+	added := make([]navigation.Waypoint, 0, len(points))
+	for _, pt := range points {
+		wp, err := store.AddWaypoint(ctx, pt)
+		test.That(t, err, test.ShouldBeNil)
+		added = append(added, wp)
+	}
+	test.That(t, store.WaypointVisited(ctx, added[0].ID), test.ShouldBeNil)
+	test.That(t, store.RemoveWaypoint(ctx, added[1].ID), test.ShouldBeNil)
+	test.That(t, store.Close(ctx), test.ShouldBeNil)
+
+	t.Run("waypoints survive reopening", func(t *testing.T) {
+		reopened, err := navigation.NewStoreFromConfig(ctx, cfg)
+		test.That(t, err, test.ShouldBeNil)
+		wps, err := reopened.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{added[2]})
+		next, err := reopened.NextWaypoint(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next, test.ShouldResemble, added[2])
+	})
+
+	t.Run("waypoints are ordered by when they were added", func(t *testing.T) {
+		ordered, err := navigation.NewStoreFromConfig(ctx, navigation.StoreConfig{
+			Type:   navigation.StoreTypeFile,
+			Config: map[string]interface{}{"path": filepath.Join(t.TempDir(), "waypoints.json")},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		for _, pt := range points {
+			_, err := ordered.AddWaypoint(ctx, pt)
+			test.That(t, err, test.ShouldBeNil)
+		}
+		wps, err := ordered.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(wps), test.ShouldEqual, len(points))
+		for i, wp := range wps {
+			test.That(t, wp.ToPoint(), test.ShouldResemble, points[i])
+		}
+	})
+
+	t.Run("a partially written change is ignored", func(t *testing.T) {
+		// a temporary file left behind by losing power part way through persisting a change
+		partial := filepath.Join(filepath.Dir(path), "waypoints.json.123.tmp")
+		test.That(t, os.WriteFile(partial, []byte(`{"waypoints":[{"id"`), 0o600), test.ShouldBeNil)
+		reopened, err := navigation.NewStoreFromConfig(ctx, cfg)
+		test.That(t, err, test.ShouldBeNil)
+		wps, err := reopened.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{added[2]})
+	})
+
+	t.Run("a corrupt file errors", func(t *testing.T) {
+		corrupt := filepath.Join(t.TempDir(), "waypoints.json")
+		test.That(t, os.WriteFile(corrupt, []byte("not json"), 0o600), test.ShouldBeNil)
+		_, err := navigation.NewStoreFromConfig(ctx, navigation.StoreConfig{
+			Type:   navigation.StoreTypeFile,
+			Config: map[string]interface{}{"path": corrupt},
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestFileNavigationStoreDefaultPath(t *testing.T) {
+	ctx := context.Background()
+	navigation.SetDefaultFileNavStoreDir(t, t.TempDir())
+	cfg := navigation.StoreConfig{Type: navigation.StoreTypeFile}
+
+	// without a navigation service, there is no default path
+	_, err := navigation.NewStoreFromConfig(ctx, cfg)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// each navigation service keeps its own waypoints
+	first, err := navigation.NewStoreFromConfigForService(ctx, navigation.Named("first"), cfg)
+	test.That(t, err, test.ShouldBeNil)
+	second, err := navigation.NewStoreFromConfigForService(ctx, navigation.Named("second"), cfg)
+	test.That(t, err, test.ShouldBeNil)
+	wp, err := first.AddWaypoint(ctx, geo.NewPoint(1, 2))
+	test.That(t, err, test.ShouldBeNil)
+	_, err = second.AddWaypoint(ctx, geo.NewPoint(3, 4))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, first.Close(ctx), test.ShouldBeNil)
+	test.That(t, second.Close(ctx), test.ShouldBeNil)
+
+	reopened, err := navigation.NewStoreFromConfigForService(ctx, navigation.Named("first"), cfg)
+	test.That(t, err, test.ShouldBeNil)
+	wps, err := reopened.Waypoints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{wp})
+}
@@ -15,6 +15,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.uber.org/multierr"
 	mongoutils "go.viam.com/utils/mongo"
+
+	"go.viam.com/rdk/resource"
 )
 
 var errNoMoreWaypoints = errors.New("no more waypoints")
@@ -38,6 +40,8 @@ const (
 	StoreTypeMemory = "memory"
 	// StoreTypeMongoDB is the constant for the mongodb store type.
 	StoreTypeMongoDB = "mongodb"
+	// StoreTypeFile is the constant for the file store type.
+	StoreTypeFile = "file"
 )
 
 // StoreConfig describes how to configure data storage.
@@ -49,20 +53,29 @@ type StoreConfig struct {
 // Validate ensures all parts of the config are valid.
 func (config *StoreConfig) Validate(path string) error {
 	switch config.Type {
-	case StoreTypeMemory, StoreTypeMongoDB, StoreTypeUnset:
+	case StoreTypeMemory, StoreTypeMongoDB, StoreTypeFile, StoreTypeUnset:
 	default:
 		return errors.Errorf("unknown store type %q", config.Type)
 	}
 	return nil
 }
 
-// NewStoreFromConfig builds a NavStore from the provided StoreConfig and returns it.
+// NewStoreFromConfig builds a NavStore from the provided StoreConfig and returns it. A file store built this way
+// must be given a path in its config.
 func NewStoreFromConfig(ctx context.Context, conf StoreConfig) (NavStore, error) {
+	return NewStoreFromConfigForService(ctx, resource.Name{}, conf)
+}
+
+// NewStoreFromConfigForService builds a NavStore for the named navigation service from the provided StoreConfig and
+// returns it. A file store without a path in its config persists to a file of the service in the viam home directory.
+func NewStoreFromConfigForService(ctx context.Context, name resource.Name, conf StoreConfig) (NavStore, error) {
 	switch conf.Type {
 	case StoreTypeMemory, StoreTypeUnset:
 		return NewMemoryNavigationStore(), nil
 	case StoreTypeMongoDB:
 		return NewMongoDBNavigationStore(ctx, conf.Config)
+	case StoreTypeFile:
+		return NewFileNavigationStore(name, conf.Config)
 	default:
 		return nil, errors.Errorf("unknown store type %q", conf.Type)
 	}
@@ -0,0 +1,90 @@
+// Package fiducial implements a vision service that detects AprilTag and ArUco fiducial markers, labeling each
+// detection with the family and ID of the marker.
+package fiducial
+
+import (
+	"context"
+	"image"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/vision/fiducial"
+	objdet "go.viam.com/rdk/vision/objectdetection"
+)
+
+// Model is the model of the fiducial marker vision service.
+var Model = resource.DefaultModelFamily.WithModel("fiducial")
+
+func init() {
+	resource.RegisterService(vision.API, Model, resource.Registration[vision.Service, *Config]{
+		Constructor: newFiducialDetector,
+	})
+}
+
+// Config specifies the marker families to detect. All built in and loaded families are detected if none are
+// given. FamilyFiles are files of families to load, such as the tag36h11.c source of the AprilTag library or a
+// dictionary written by OpenCV, as read by fiducial.LoadFamily. Of the built in tag36h11 family, only tags 0 to
+// 30 are detected, so the whole family must be loaded to detect tags with higher IDs.
+type Config struct {
+	DefaultCamera string   `json:"camera_name,omitempty"`
+	Families      []string `json:"families,omitempty"`
+	FamilyFiles   []string `json:"family_files,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and returns the camera as a dependency.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if _, err := fiducial.ConfiguredFamilies(conf.Families, conf.FamilyFiles); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+	if conf.DefaultCamera != "" {
+		return []string{conf.DefaultCamera}, nil, nil
+	}
+	return nil, nil, nil
+}
+
+func newFiducialDetector(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (vision.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::vision::newFiducialDetector")
+	defer span.End()
+
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	families, err := fiducial.ConfiguredFamilies(newConf.Families, newConf.FamilyFiles)
+	if err != nil {
+		return nil, err
+	}
+	if newConf.DefaultCamera != "" {
+		if _, err := camera.FromProvider(deps, newConf.DefaultCamera); err != nil {
+			return nil, errors.Errorf("could not find camera %q", newConf.DefaultCamera)
+		}
+	}
+	return vision.NewService(conf.ResourceName(), deps, logger, nil, nil, NewDetector(families), nil, newConf.DefaultCamera)
+}
+
+// NewDetector returns a detector of markers of the given families. Each detection is labeled with the name of
+// the marker, such as tag36h11_3, and scored lower the more cells of the marker had to be corrected.
+func NewDetector(families []*fiducial.Family) objdet.Detector {
+	return func(ctx context.Context, img image.Image) ([]objdet.Detection, error) {
+		if img == nil {
+			return nil, errors.New("no image to detect markers in")
+		}
+		markers := fiducial.Detect(img, families)
+		detections := make([]objdet.Detection, 0, len(markers))
+		for _, marker := range markers {
+			score := 1 - float64(marker.Hamming)/float64(marker.Family.MaxCorrection+1)
+			detections = append(detections, objdet.NewDetection(img.Bounds(), marker.BoundingBox(), score, marker.Name()))
+		}
+		return detections, nil
+	}
+}
@@ -0,0 +1,72 @@
+package fiducial
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+func TestConfigValidate(t *testing.T) {
+	deps, _, err := (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	deps, _, err = (&Config{DefaultCamera: "cam", Families: []string{"tag36h11"}}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam"})
+
+	_, _, err = (&Config{Families: []string{"tag16h5"}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "tag16h5")
+
+	_, _, err = (&Config{FamilyFiles: []string{filepath.Join(t.TempDir(), "tag36h11.c")}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "tag36h11.c")
+}
+
+func TestFiducialDetector(t *testing.T) {
+	ctx := context.Background()
+	img := image.NewGray(image.Rect(0, 0, 320, 160))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 180}), image.Point{}, draw.Src)
+	tag, err := fiducial.Render(fiducial.AprilTag36h11, 4, 10)
+	test.That(t, err, test.ShouldBeNil)
+	draw.Draw(img, tag.Bounds().Add(image.Pt(20, 20)), tag, image.Point{}, draw.Src)
+	aruco, err := fiducial.Render(fiducial.ArucoOriginal, 77, 12)
+	test.That(t, err, test.ShouldBeNil)
+	draw.Draw(img, aruco.Bounds().Add(image.Pt(180, 30)), aruco, image.Point{}, draw.Src)
+
+	conf := resource.Config{Name: "tags", API: vision.API, ConvertedAttributes: &Config{}}
+	srv, err := newFiducialDetector(ctx, nil, conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	detections, err := srv.Detections(ctx, img, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, detections, test.ShouldHaveLength, 2)
+	test.That(t, detections[0].Label(), test.ShouldEqual, "aruco_original_77")
+	test.That(t, detections[0].Score(), test.ShouldEqual, 1)
+	test.That(t, *detections[0].BoundingBox(), test.ShouldResemble, image.Rect(192, 42, 276, 126))
+	test.That(t, detections[1].Label(), test.ShouldEqual, "tag36h11_4")
+	test.That(t, *detections[1].BoundingBox(), test.ShouldResemble, image.Rect(30, 30, 110, 110))
+
+	conf.ConvertedAttributes = &Config{Families: []string{"tag36h11"}}
+	srv, err = newFiducialDetector(ctx, nil, conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	detections, err = srv.Detections(ctx, img, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, detections, test.ShouldHaveLength, 1)
+	test.That(t, detections[0].Label(), test.ShouldEqual, "tag36h11_4")
+
+	props, err := srv.GetProperties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.DetectionSupported, test.ShouldBeTrue)
+	test.That(t, props.ClassificationSupported, test.ShouldBeFalse)
+}
@@ -3,6 +3,7 @@ package mlvision
 import (
 	"context"
 	"image"
+	"math"
 	"sync"
 
 	"github.com/nfnt/resize"
@@ -110,11 +111,21 @@ func attemptToBuildDetector(mlm mlmodel.Service,
 		if err != nil {
 			return nil, err
 		}
-		boundingBoxes, err := ml.FormatDetectionOutputs(outNameMap, outMap, resizeW, resizeH, boxOrder, labels)
-		if err != nil {
-			return nil, err
+		var detections []objectdetection.Detection
+		if ml.HasInstanceSegmentationOutputs(outNameMap, outMap) {
+			instances, err := ml.FormatInstanceSegmentationOutputs(
+				outNameMap, outMap, resizeW, resizeH, origW, origH, minInstanceConfidence(params), labels)
+			if err != nil {
+				return nil, err
+			}
+			detections = convertInstancesToDetections(instances, origW, origH)
+		} else {
+			boundingBoxes, err := ml.FormatDetectionOutputs(outNameMap, outMap, resizeW, resizeH, boxOrder, labels)
+			if err != nil {
+				return nil, err
+			}
+			detections = convertBoundingBoxesToDetections(boundingBoxes, origW, origH)
 		}
-		detections := convertBoundingBoxesToDetections(boundingBoxes, origW, origH)
 		if postprocessor != nil {
 			detections = postprocessor(detections)
 		}
@@ -164,3 +175,31 @@ func convertBoundingBoxesToDetections(boundingBoxes []data.BoundingBox, origW, o
 	}
 	return detections
 }
+
+// minInstanceConfidence returns the lowest confidence that the config could keep an instance at, below which the
+// candidates of instance segmentation models are dropped before they are decoded.
+func minInstanceConfidence(params *MLModelConfig) float64 {
+	if len(params.LabelConfidenceMap) == 0 {
+		return params.DefaultConfidence
+	}
+	minConf := math.Inf(1)
+	for _, conf := range params.LabelConfidenceMap {
+		minConf = math.Min(minConf, conf)
+	}
+	return minConf
+}
+
+// convertInstancesToDetections converts instances to detections with masks, in an image of size origW by origH.
+func convertInstancesToDetections(instances []ml.Instance, origW, origH int) []objectdetection.Detection {
+	detections := make([]objectdetection.Detection, 0, len(instances))
+	for _, instance := range instances {
+		bbox := instance.BoundingBox
+		rect := image.Rect(
+			int(bbox.XMinNormalized*float64(origW-1)), int(bbox.YMinNormalized*float64(origH-1)),
+			int(bbox.XMaxNormalized*float64(origW-1)), int(bbox.YMaxNormalized*float64(origH-1)),
+		)
+		detections = append(detections, objectdetection.NewMaskedDetection(
+			image.Rect(0, 0, origW, origH), rect, instance.Mask, *bbox.Confidence, bbox.Label))
+	}
+	return detections
+}
@@ -181,7 +181,7 @@ func registerMLModelVisionService(
 		}
 	}
 
-	segmenter3DFunc, err := attemptToBuild3DSegmenter(mlm, inNameMap, outNameMap)
+	segmenter3DFunc, err := attemptToBuild3DSegmenter(mlm, inNameMap, outNameMap, detectorFunc)
 	errList = append(errList, err)
 	if err != nil {
 		logger.CDebugw(ctx, "unable to use ml model as 3D segmenter", "model", params.ModelName, "error", err)
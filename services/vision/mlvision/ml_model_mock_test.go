@@ -710,3 +710,50 @@ func mockMobileNetImageNetModel(name, labelLoc string) mlmodel.Service {
 	}
 	return mobileImageMock
 }
+
+func mockYOLOSegModel(name string) mlmodel.Service {
+	// an instance segmentation model with prototype masks, with the outputs of a yolov8-seg model exported to tflite
+	mock := inject.NewMLModelService(name)
+	md := mlmodel.MLMetadata{
+		Inputs: []mlmodel.TensorInfo{{Name: "images", DataType: "float32", Shape: []int{1, 64, 64, 3}}},
+		Outputs: []mlmodel.TensorInfo{
+			{Name: "output0", DataType: "float32", Shape: []int{1, 8, 10}},
+			{Name: "output1", DataType: "float32", Shape: []int{1, 2, 16, 16}},
+		},
+	}
+	mock.MetadataFunc = func(ctx context.Context) (mlmodel.MLMetadata, error) {
+		return md, nil
+	}
+
+	// each of the 10 candidates has a box in pixels, scores for 2 classes and 2 mask coefficients
+	candidates := [][]float32{
+		{32, 32, 32, 32, 0.9, 0.1, 1, 0},
+		{16, 48, 16, 16, 0.2, 0.8, 0, 1},
+		// suppressed by the first candidate
+		{33, 32, 32, 32, 0.7, 0.1, 1, 0},
+	}
+	detections := make([]float32, 8*10)
+	for i, c := range candidates {
+		for k, v := range c {
+			detections[k*10+i] = v
+		}
+	}
+	// the first prototype is the left half of the image, and the second is all of it
+	prototypes := make([]float32, 2*16*16)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			prototypes[y*16+x] = -1
+			if x < 8 {
+				prototypes[y*16+x] = 1
+			}
+			prototypes[16*16+y*16+x] = 1
+		}
+	}
+	outputInfer := ml.Tensors{}
+	outputInfer["output0"] = tensor.New(tensor.WithShape(1, 8, 10), tensor.WithBacking(detections))
+	outputInfer["output1"] = tensor.New(tensor.WithShape(1, 2, 16, 16), tensor.WithBacking(prototypes))
+	mock.InferFunc = func(ctx context.Context, tensors ml.Tensors) (ml.Tensors, error) {
+		return outputInfer, nil
+	}
+	return mock
+}
@@ -2,20 +2,27 @@ package mlvision
 
 import (
 	"context"
+	"image"
 	"math"
 	"sync"
 	"testing"
 
+	"github.com/golang/geo/r3"
 	"go.viam.com/test"
 	"go.viam.com/utils/artifact"
 
 	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/ml"
+	pc "go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
 	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
 	"go.viam.com/rdk/vision/classification"
+	"go.viam.com/rdk/vision/objectdetection"
 )
 
 func BenchmarkAddMLVisionModel(b *testing.B) {
@@ -388,6 +395,92 @@ func TestMoreMLDetectors(t *testing.T) {
 	test.That(t, gotDetections[2].Label(), test.ShouldResemble, "Carrot")
 }
 
+func TestMLInstanceSegmenter(t *testing.T) {
+	ctx := context.Background()
+	out := mockYOLOSegModel("yolo_seg")
+	modelName := out.Name()
+	cameraName := camera.Named("test")
+
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 128, Height: 128, Fx: 128, Fy: 128, Ppx: 64, Ppy: 64}
+	cloud := pc.NewBasicEmpty()
+	for y := 0; y < intrinsics.Height; y++ {
+		for x := 0; x < intrinsics.Width; x++ {
+			pt := r3.Vector{X: float64(x) - intrinsics.Ppx, Y: float64(y) - intrinsics.Ppy, Z: intrinsics.Fx}
+			test.That(t, cloud.Set(pt, nil), test.ShouldBeNil)
+		}
+	}
+	cam := inject.NewCamera(cameraName.Name)
+	cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		return camera.Properties{SupportsPCD: true, IntrinsicParams: intrinsics}, nil
+	}
+	cam.ImagesFunc = func(
+		ctx context.Context, filterSourceNames []string, extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		img := image.NewRGBA(image.Rect(0, 0, intrinsics.Width, intrinsics.Height))
+		namedImg, err := camera.NamedImageFromImage(img, "color", utils.MimeTypePNG, data.Annotations{})
+		return []camera.NamedImage{namedImg}, resource.ResponseMetadata{}, err
+	}
+	cam.NextPointCloudFunc = func(ctx context.Context, extra map[string]interface{}) (pc.PointCloud, error) {
+		return cloud, nil
+	}
+
+	r := inject.Robot{}
+	r.LoggerFunc = func() logging.Logger {
+		return nil
+	}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		switch name {
+		case modelName:
+			return out, nil
+		case cameraName:
+			return cam, nil
+		default:
+			return nil, resource.NewNotFoundError(name)
+		}
+	}
+	modelCfg := MLModelConfig{ModelName: modelName.Name, DefaultConfidence: 0.5}
+	service, err := registerMLModelVisionService(ctx, modelName, &modelCfg, &r, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	props, err := service.GetProperties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.DetectionSupported, test.ShouldBeTrue)
+	test.That(t, props.ObjectPCDsSupported, test.ShouldBeTrue)
+
+	// the image is twice the size of the input of the model
+	dets, err := service.DetectionsFromCamera(ctx, cameraName.Name, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldHaveLength, 2)
+	test.That(t, dets[0].Label(), test.ShouldEqual, "0")
+	test.That(t, dets[0].Score(), test.ShouldAlmostEqual, 0.9, 1e-6)
+	for i, v := range []float64{0.25, 0.25, 0.75, 0.75} {
+		test.That(t, dets[0].NormalizedBoundingBox()[i], test.ShouldAlmostEqual, v, 0.01)
+	}
+	masked, ok := dets[0].(objectdetection.MaskedDetection)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, masked.Mask().Rect, test.ShouldResemble, image.Rect(32, 32, 96, 96))
+	test.That(t, objectdetection.InDetection(dets[0], image.Pt(63, 64)), test.ShouldBeTrue)
+	test.That(t, objectdetection.InDetection(dets[0], image.Pt(64, 64)), test.ShouldBeFalse)
+	test.That(t, dets[1].Label(), test.ShouldEqual, "1")
+	test.That(t, objectdetection.InDetection(dets[1], image.Pt(20, 90)), test.ShouldBeTrue)
+
+	// the objects are the points within the masks
+	objects, err := service.GetObjectPointClouds(ctx, cameraName.Name, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, objects, test.ShouldHaveLength, 2)
+	test.That(t, objects[0].Geometry.Label(), test.ShouldEqual, "0")
+	test.That(t, objects[0].Size(), test.ShouldEqual, 32*64)
+	test.That(t, objects[1].Size(), test.ShouldEqual, 32*32)
+
+	// a detector without masks is not a 3D segmenter
+	inNameMap := &sync.Map{}
+	outNameMap := &sync.Map{}
+	fake := mockSuperFakeModel("test_me")
+	detector, err := attemptToBuildDetector(fake, inNameMap, outNameMap, &MLModelConfig{})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = attemptToBuild3DSegmenter(fake, inNameMap, outNameMap, detector)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
 func TestMoreMLClassifiers(t *testing.T) {
 	// Test that mobileNet classifier gives expected output on the redpanda image
 	ctx := context.Background()
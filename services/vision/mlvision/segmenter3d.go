@@ -1,14 +1,49 @@
 package mlvision
 
 import (
+	"context"
 	"errors"
 	"sync"
 
 	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/vision/objectdetection"
 	"go.viam.com/rdk/vision/segmentation"
 )
 
-// TODO: RSDK-2665, build 3D segmenter from ML models.
-func attemptToBuild3DSegmenter(mlm mlmodel.Service, inNameMap, outNameMap *sync.Map) (segmentation.Segmenter, error) {
-	return nil, errors.New("cannot use model as a 3D segmenter: vision 3D segmenters from ML models are currently not supported")
+const prototypesOutputName = "prototypes"
+
+// attemptToBuild3DSegmenter builds a 3D segmenter from a working detector of a model with prototype mask outputs,
+// whose detections have masks that the points of the camera's point cloud can be projected into.
+// TODO: RSDK-2665, build 3D segmenters from ML models with other kinds of outputs.
+func attemptToBuild3DSegmenter(mlm mlmodel.Service, inNameMap, outNameMap *sync.Map,
+	detector objectdetection.Detector,
+) (segmentation.Segmenter, error) {
+	if detector == nil || !hasMaskOutputs(mlm, outNameMap) {
+		return nil, errors.New("cannot use model as a 3D segmenter: vision 3D segmenters from ML models are " +
+			"only supported for detectors with prototype mask outputs")
+	}
+	return segmentation.DetectionSegmenter(detector, 0, 0, 0)
+}
+
+// hasMaskOutputs returns whether the model has a prototype masks output, either remapped to that name or as the
+// only 4 dimensional output alongside the only 3 dimensional one, as instance segmentation models such as
+// YOLO-seg have.
+func hasMaskOutputs(mlm mlmodel.Service, outNameMap *sync.Map) bool {
+	if _, ok := outNameMap.Load(prototypesOutputName); ok {
+		return true
+	}
+	md, err := mlm.Metadata(context.Background())
+	if err != nil {
+		return false
+	}
+	threeDims, fourDims := 0, 0
+	for _, tensor := range md.Outputs {
+		switch len(tensor.Shape) {
+		case 3:
+			threeDims++
+		case 4:
+			fourDims++
+		}
+	}
+	return threeDims == 1 && fourDims == 1
 }
@@ -0,0 +1,350 @@
+// Package objecttracker wraps a detector from another vision service with a multi-object tracker, so that
+// detections of the same object keep the same track ID from frame to frame.
+//
+// The detections are *objectdetection.TrackedDetection values, which carry the track ID, the velocity of the center of
+// the box in pixels per second, the age of the track and its number of hits. Detections sent over the API only have a
+// label, so the track ID is appended to the class name of their labels, as in "person_3", and the rest of the track is
+// only sent by CaptureAllFromCamera, in its extra under "tracks", and by the get_tracks command.
+package objecttracker
+
+import (
+	"context"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	viz "go.viam.com/rdk/vision"
+	"go.viam.com/rdk/vision/classification"
+	objdet "go.viam.com/rdk/vision/objectdetection"
+	"go.viam.com/rdk/vision/viscapture"
+)
+
+// Model is the model of the object tracker vision service.
+var Model = resource.DefaultModelFamily.WithModel("object_tracker")
+
+const (
+	getTracksCommand = "get_tracks"
+	resetCommand     = "reset"
+)
+
+func init() {
+	resource.RegisterService(vision.API, Model, resource.Registration[vision.Service, *Config]{
+		Constructor: newObjectTracker,
+	})
+}
+
+// Config specifies the detector to track the detections of, and how detections are associated with tracks.
+type Config struct {
+	DetectorName  string  `json:"detector_name"`
+	DefaultCamera string  `json:"camera_name"`
+	IoUThreshold  float64 `json:"iou_threshold,omitempty"`
+	// MaxAge is the number of consecutive frames a track may go undetected before it is removed.
+	MaxAge *int `json:"max_age,omitempty"`
+	// MinHits is the number of consecutive frames an object must be detected before its track is reported.
+	MinHits int `json:"min_hits,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and returns the detector and camera as dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.DetectorName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "detector_name")
+	}
+	if conf.IoUThreshold < 0 || conf.IoUThreshold > 1 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("iou_threshold must be between 0 and 1"))
+	}
+	if conf.MaxAge != nil && *conf.MaxAge < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("max_age cannot be negative"))
+	}
+	if conf.MinHits < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("min_hits cannot be negative"))
+	}
+	deps := []string{conf.DetectorName}
+	if conf.DefaultCamera != "" {
+		deps = append(deps, conf.DefaultCamera)
+	}
+	return deps, nil, nil
+}
+
+func (conf *Config) trackerConfig() objdet.TrackerConfig {
+	cfg := objdet.DefaultTrackerConfig()
+	if conf.IoUThreshold != 0 {
+		cfg.IoUThreshold = conf.IoUThreshold
+	}
+	if conf.MaxAge != nil {
+		cfg.MaxAge = *conf.MaxAge
+	}
+	if conf.MinHits != 0 {
+		cfg.MinHits = conf.MinHits
+	}
+	return cfg
+}
+
+// objectTracker keeps one tracker per camera, as well as one for the images passed to Detections.
+type objectTracker struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	logger        logging.Logger
+	deps          resource.Dependencies
+	detector      vision.Service
+	defaultCamera string
+	trackerConfig objdet.TrackerConfig
+
+	mu       sync.Mutex
+	trackers map[string]*objdet.Tracker
+}
+
+func newObjectTracker(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (vision.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::vision::newObjectTracker")
+	defer span.End()
+
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	detector, err := vision.FromProvider(deps, newConf.DetectorName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find detector %q", newConf.DetectorName)
+	}
+	if newConf.DefaultCamera != "" {
+		if _, err := camera.FromProvider(deps, newConf.DefaultCamera); err != nil {
+			return nil, errors.Errorf("could not find camera %q", newConf.DefaultCamera)
+		}
+	}
+	return &objectTracker{
+		Named:         conf.ResourceName().AsNamed(),
+		logger:        logger,
+		deps:          deps,
+		detector:      detector,
+		defaultCamera: newConf.DefaultCamera,
+		trackerConfig: newConf.trackerConfig(),
+		trackers:      map[string]*objdet.Tracker{},
+	}, nil
+}
+
+// track updates the tracker of the given camera with the detections of a frame.
+func (ot *objectTracker) track(cameraName string, detections []objdet.Detection, at time.Time) []*objdet.TrackedDetection {
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	tracker, ok := ot.trackers[cameraName]
+	if !ok {
+		tracker = objdet.NewTracker(ot.trackerConfig)
+		ot.trackers[cameraName] = tracker
+	}
+	return tracker.Update(detections, at)
+}
+
+func toDetections(tracked []*objdet.TrackedDetection) []objdet.Detection {
+	dets := make([]objdet.Detection, 0, len(tracked))
+	for _, det := range tracked {
+		dets = append(dets, det)
+	}
+	return dets
+}
+
+// image returns the next image from the named camera, or the default camera, and the time it was captured.
+func (ot *objectTracker) image(ctx context.Context, cameraName string, extra map[string]interface{}) (
+	string, image.Image, time.Time, error,
+) {
+	if cameraName == "" && ot.defaultCamera == "" {
+		return "", nil, time.Time{}, errors.New("no camera name provided and no default camera found")
+	} else if cameraName == "" {
+		cameraName = ot.defaultCamera
+	}
+	cam, err := camera.FromProvider(ot.deps, cameraName)
+	if err != nil {
+		return "", nil, time.Time{}, errors.Wrapf(err, "could not find camera named %s", cameraName)
+	}
+	namedImages, metadata, err := cam.Images(ctx, nil, extra)
+	if err != nil {
+		return "", nil, time.Time{}, errors.Wrapf(err, "could not get image from %s", cameraName)
+	}
+	if len(namedImages) == 0 {
+		return "", nil, time.Time{}, errors.Errorf("no images returned from camera %s", cameraName)
+	}
+	img, err := namedImages[0].Image(ctx)
+	if err != nil {
+		return "", nil, time.Time{}, errors.Wrapf(err, "could not decode image from %s", cameraName)
+	}
+	capturedAt := metadata.CapturedAt
+	if capturedAt.IsZero() {
+		capturedAt = time.Now()
+	}
+	return cameraName, img, capturedAt, nil
+}
+
+// Detections returns the tracked detections of the given image. Images passed to Detections are tracked
+// as one stream, separately from the cameras.
+func (ot *objectTracker) Detections(
+	ctx context.Context,
+	img image.Image,
+	extra map[string]interface{},
+) ([]objdet.Detection, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::Detections::"+ot.Name().String())
+	defer span.End()
+
+	detections, err := ot.detector.Detections(ctx, img, extra)
+	if err != nil {
+		return nil, err
+	}
+	return toDetections(ot.track("", detections, time.Now())), nil
+}
+
+// DetectionsFromCamera returns the tracked detections of the next image from the given camera. Over the API, only the
+// track IDs in their labels reach clients, which get the velocities and ages of the tracks from CaptureAllFromCamera.
+func (ot *objectTracker) DetectionsFromCamera(
+	ctx context.Context,
+	cameraName string,
+	extra map[string]interface{},
+) ([]objdet.Detection, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::DetectionsFromCamera::"+ot.Name().String())
+	defer span.End()
+
+	cameraName, img, capturedAt, err := ot.image(ctx, cameraName, extra)
+	if err != nil {
+		return nil, err
+	}
+	detections, err := ot.detector.Detections(ctx, img, extra)
+	if err != nil {
+		return nil, err
+	}
+	return toDetections(ot.track(cameraName, detections, capturedAt)), nil
+}
+
+func (ot *objectTracker) Classifications(
+	ctx context.Context,
+	img image.Image,
+	n int,
+	extra map[string]interface{},
+) (classification.Classifications, error) {
+	return nil, errors.Errorf("vision model %q does not implement a Classifier", ot.Name())
+}
+
+func (ot *objectTracker) ClassificationsFromCamera(
+	ctx context.Context,
+	cameraName string,
+	n int,
+	extra map[string]interface{},
+) (classification.Classifications, error) {
+	return nil, errors.Errorf("vision model %q does not implement a Classifier", ot.Name())
+}
+
+func (ot *objectTracker) GetObjectPointClouds(
+	ctx context.Context,
+	cameraName string,
+	extra map[string]interface{},
+) ([]*viz.Object, error) {
+	return nil, errors.Errorf("vision model %q does not implement a 3D segmenter", ot.Name())
+}
+
+func (ot *objectTracker) GetProperties(ctx context.Context, extra map[string]interface{}) (*vision.Properties, error) {
+	return &vision.Properties{DetectionSupported: true}, nil
+}
+
+// CaptureAllFromCamera returns the next image from the given camera and its tracked detections. The extra has the
+// track of each detection under "tracks", in the same order as the detections, as returned by get_tracks.
+func (ot *objectTracker) CaptureAllFromCamera(
+	ctx context.Context,
+	cameraName string,
+	opt viscapture.CaptureOptions,
+	extra map[string]interface{},
+) (viscapture.VisCapture, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::CaptureAllFromCamera::"+ot.Name().String())
+	defer span.End()
+
+	cameraName, img, capturedAt, err := ot.image(ctx, cameraName, extra)
+	if err != nil {
+		return viscapture.VisCapture{}, err
+	}
+	var detections []objdet.Detection
+	var captureExtra map[string]interface{}
+	if opt.ReturnDetections {
+		found, err := ot.detector.Detections(ctx, img, extra)
+		if err != nil {
+			return viscapture.VisCapture{}, err
+		}
+		tracked := ot.track(cameraName, found, capturedAt)
+		detections = toDetections(tracked)
+		tracks := make([]interface{}, 0, len(tracked))
+		for _, det := range tracked {
+			tracks = append(tracks, trackToMap(det))
+		}
+		captureExtra = map[string]interface{}{"tracks": tracks}
+	}
+	if opt.ReturnClassifications {
+		ot.logger.Debugf("classifications requested in CaptureAll but vision model %q does not implement a Classifier", ot.Name())
+	}
+	if opt.ReturnObject {
+		ot.logger.Debugf("object point cloud requested in CaptureAll but vision model %q does not implement a 3D Segmenter", ot.Name())
+	}
+	if !opt.ReturnImage {
+		img = nil
+	}
+	return viscapture.VisCapture{
+		Image:      img,
+		Detections: detections,
+		Extra:      captureExtra,
+	}, nil
+}
+
+// DoCommand supports the following commands:
+//
+//	{"get_tracks": "<camera name>"}: returns every confirmed track of the camera, including those that
+//	  were not detected in the last frame. An empty camera name is the default camera.
+//	{"reset": true}: removes all tracks of all cameras.
+func (ot *objectTracker) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cameraName, ok := cmd[getTracksCommand]; ok {
+		name, ok := cameraName.(string)
+		if !ok {
+			return nil, errors.Errorf("%s must be a camera name, not %T", getTracksCommand, cameraName)
+		}
+		if name == "" {
+			name = ot.defaultCamera
+		}
+		ot.mu.Lock()
+		defer ot.mu.Unlock()
+		tracks := []interface{}{}
+		if tracker, ok := ot.trackers[name]; ok {
+			for _, det := range tracker.Tracks() {
+				tracks = append(tracks, trackToMap(det))
+			}
+		}
+		return map[string]interface{}{"tracks": tracks}, nil
+	}
+	if _, ok := cmd[resetCommand]; ok {
+		ot.mu.Lock()
+		defer ot.mu.Unlock()
+		ot.trackers = map[string]*objdet.Tracker{}
+		return map[string]interface{}{}, nil
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+func trackToMap(det *objdet.TrackedDetection) map[string]interface{} {
+	box := det.BoundingBox()
+	return map[string]interface{}{
+		"track_id":   det.TrackID,
+		"class_name": det.ClassLabel(),
+		"confidence": det.Score(),
+		"x_min":      box.Min.X,
+		"y_min":      box.Min.Y,
+		"x_max":      box.Max.X,
+		"y_max":      box.Max.Y,
+		"velocity_x": det.Velocity.X,
+		"velocity_y": det.Velocity.Y,
+		"age_ms":     det.Age.Milliseconds(),
+		"hits":       det.Hits,
+	}
+}
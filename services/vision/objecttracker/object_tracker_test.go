@@ -0,0 +1,157 @@
+package objecttracker
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/protoutils"
+
+	"go.viam.com/rdk/components/camera"
+	fakecamera "go.viam.com/rdk/components/camera/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	fakevision "go.viam.com/rdk/services/vision/fake"
+	"go.viam.com/rdk/testutils/inject"
+	objdet "go.viam.com/rdk/vision/objectdetection"
+	"go.viam.com/rdk/vision/viscapture"
+)
+
+func newDeps(t *testing.T) resource.Dependencies {
+	t.Helper()
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	camConf := resource.Config{
+		Name:                "cam",
+		API:                 camera.API,
+		ConvertedAttributes: &fakecamera.Config{Width: 200, Height: 100},
+	}
+	cam, err := fakecamera.NewCamera(ctx, nil, camConf, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	reg, ok := resource.LookupRegistration(vision.API, fakevision.Model)
+	test.That(t, ok, test.ShouldBeTrue)
+	r := &inject.Robot{}
+	r.LoggerFunc = func() logging.Logger { return logger }
+	detector, err := reg.DeprecatedRobotConstructor(ctx, r, resource.Config{Name: "detector", API: vision.API}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	return resource.Dependencies{
+		camera.Named("cam"):      cam,
+		vision.Named("detector"): detector,
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{}
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "detector_name"))
+
+	conf = &Config{DetectorName: "detector", IoUThreshold: 1.5}
+	_, _, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "iou_threshold")
+
+	maxAge := -1
+	conf = &Config{DetectorName: "detector", MaxAge: &maxAge}
+	_, _, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_age")
+
+	maxAge = 0
+	conf = &Config{DetectorName: "detector", DefaultCamera: "cam", MaxAge: &maxAge, MinHits: 2}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"detector", "cam"})
+	test.That(t, conf.trackerConfig().MaxAge, test.ShouldEqual, 0)
+	test.That(t, conf.trackerConfig().MinHits, test.ShouldEqual, 2)
+	test.That(t, conf.trackerConfig().IoUThreshold, test.ShouldEqual, 0.3)
+}
+
+func TestObjectTracker(t *testing.T) {
+	ctx := context.Background()
+	deps := newDeps(t)
+	conf := resource.Config{
+		Name:                "tracker",
+		API:                 vision.API,
+		ConvertedAttributes: &Config{DetectorName: "detector", DefaultCamera: "cam", MinHits: 2},
+	}
+	srv, err := newObjectTracker(ctx, deps, conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	props, err := srv.GetProperties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props, test.ShouldResemble, &vision.Properties{DetectionSupported: true})
+
+	// the fake detector always finds the same object, so it keeps its track
+	dets, err := srv.DetectionsFromCamera(ctx, "", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldBeEmpty)
+	for i := 0; i < 3; i++ {
+		dets, err = srv.DetectionsFromCamera(ctx, "cam", nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, dets, test.ShouldHaveLength, 1)
+		test.That(t, dets[0].Label(), test.ShouldEqual, "a_detection_1")
+		test.That(t, dets[0].BoundingBox(), test.ShouldResemble, &image.Rectangle{image.Point{50, 25}, image.Point{150, 75}})
+	}
+	tracked, ok := dets[0].(*objdet.TrackedDetection)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, tracked.TrackID, test.ShouldEqual, 1)
+	test.That(t, tracked.Hits, test.ShouldEqual, 4)
+
+	capt, err := srv.CaptureAllFromCamera(ctx, "", viscapture.CaptureOptions{ReturnImage: true, ReturnDetections: true}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, capt.Image.Bounds(), test.ShouldResemble, image.Rect(0, 0, 200, 100))
+	test.That(t, capt.Detections, test.ShouldHaveLength, 1)
+	test.That(t, capt.Detections[0].Label(), test.ShouldEqual, "a_detection_1")
+	// the tracks of the detections are in the extra, which is sent to clients as a struct
+	captTracks, ok := capt.Extra["tracks"].([]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, captTracks, test.ShouldHaveLength, 1)
+	captTrack, ok := captTracks[0].(map[string]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, captTrack["track_id"], test.ShouldEqual, 1)
+	test.That(t, captTrack["hits"], test.ShouldEqual, 5)
+	test.That(t, captTrack["velocity_x"], test.ShouldAlmostEqual, 0)
+	test.That(t, captTrack, test.ShouldContainKey, "age_ms")
+	_, err = protoutils.StructToStructPb(capt.Extra)
+	test.That(t, err, test.ShouldBeNil)
+
+	resp, err := srv.DoCommand(ctx, map[string]interface{}{"get_tracks": ""})
+	test.That(t, err, test.ShouldBeNil)
+	tracks, ok := resp["tracks"].([]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, tracks, test.ShouldHaveLength, 1)
+	track, ok := tracks[0].(map[string]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, track["track_id"], test.ShouldEqual, 1)
+	test.That(t, track["class_name"], test.ShouldEqual, "a_detection")
+	test.That(t, track["hits"], test.ShouldEqual, 5)
+	test.That(t, track["velocity_x"], test.ShouldAlmostEqual, 0)
+
+	// images passed directly are tracked separately from the camera
+	dets, err = srv.Detections(ctx, image.NewRGBA(image.Rect(0, 0, 200, 100)), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldBeEmpty)
+
+	_, err = srv.DoCommand(ctx, map[string]interface{}{"reset": true})
+	test.That(t, err, test.ShouldBeNil)
+	dets, err = srv.DetectionsFromCamera(ctx, "", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldBeEmpty)
+	dets, err = srv.DetectionsFromCamera(ctx, "", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dets, test.ShouldHaveLength, 1)
+	test.That(t, dets[0].Label(), test.ShouldEqual, "a_detection_1")
+
+	_, err = srv.DetectionsFromCamera(ctx, "other", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = srv.Classifications(ctx, nil, 1, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = srv.DoCommand(ctx, map[string]interface{}{"get_tracks": 1})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, srv.Close(ctx), test.ShouldBeNil)
+}
@@ -6,5 +6,7 @@ import (
 	_ "go.viam.com/rdk/services/vision"
 	_ "go.viam.com/rdk/services/vision/colordetector"
 	_ "go.viam.com/rdk/services/vision/fake"
+	_ "go.viam.com/rdk/services/vision/fiducial"
 	_ "go.viam.com/rdk/services/vision/mlvision"
+	_ "go.viam.com/rdk/services/vision/objecttracker"
 )
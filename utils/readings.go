@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ComparisonOperators are the operators CompareReading accepts.
+var ComparisonOperators = []string{">", ">=", "<", "<=", "==", "!="}
+
+// ReadingValue returns the number in the readings at the field, where nested readings are separated by dots. A bool
+// reading is 1 when true and 0 when false.
+func ReadingValue(readings map[string]interface{}, field string) (float64, error) {
+	var value interface{} = readings
+	for _, key := range strings.Split(field, ".") {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return 0, errors.Errorf("reading %q is not nested", field)
+		}
+		if value, ok = nested[key]; !ok {
+			return 0, errors.Errorf("no reading %q", field)
+		}
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, errors.Errorf("reading %q is a %T rather than a number", field, value)
+	}
+}
+
+// FlattenReadings returns the leaf values of the readings by their path, with nested keys and list indexes separated
+// by dots, like "position.x" or "joints.0".
+func FlattenReadings(readings map[string]interface{}) map[string]interface{} {
+	leaves := map[string]interface{}{}
+	for key, value := range readings {
+		flattenReading(key, value, leaves)
+	}
+	return leaves
+}
+
+func flattenReading(path string, value interface{}, leaves map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flattenReading(path+"."+key, child, leaves)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenReading(path+"."+strconv.Itoa(i), child, leaves)
+		}
+	default:
+		leaves[path] = v
+	}
+}
+
+// CompareReading returns whether the reading compared to the value with the operator, one of ComparisonOperators, is
+// true.
+func CompareReading(reading float64, operator string, value float64) (bool, error) {
+	switch operator {
+	case ">":
+		return reading > value, nil
+	case ">=":
+		return reading >= value, nil
+	case "<":
+		return reading < value, nil
+	case "<=":
+		return reading <= value, nil
+	case "==":
+		return reading == value, nil
+	case "!=":
+		return reading != value, nil
+	default:
+		return false, errors.Errorf("unknown operator %q", operator)
+	}
+}
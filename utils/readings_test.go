@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestReadingValue(t *testing.T) {
+	readings := map[string]interface{}{
+		"temp":     21.5,
+		"count":    uint64(3),
+		"moving":   true,
+		"position": map[string]interface{}{"x": int32(-4)},
+		"name":     "probe",
+	}
+	for field, expected := range map[string]float64{"temp": 21.5, "count": 3, "moving": 1, "position.x": -4} {
+		value, err := ReadingValue(readings, field)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, value, test.ShouldEqual, expected)
+	}
+
+	_, err := ReadingValue(readings, "humidity")
+	test.That(t, err, test.ShouldBeError, `no reading "humidity"`)
+	_, err = ReadingValue(readings, "temp.x")
+	test.That(t, err, test.ShouldBeError, `reading "temp.x" is not nested`)
+	_, err = ReadingValue(readings, "name")
+	test.That(t, err, test.ShouldBeError, `reading "name" is a string rather than a number`)
+}
+
+func TestCompareReading(t *testing.T) {
+	for operator, expected := range map[string]bool{">": false, ">=": true, "<": false, "<=": true, "==": true, "!=": false} {
+		met, err := CompareReading(3, operator, 3)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, met, test.ShouldEqual, expected)
+	}
+	_, err := CompareReading(3, "~", 3)
+	test.That(t, err, test.ShouldBeError, `unknown operator "~"`)
+}
+
+func TestFlattenReadings(t *testing.T) {
+	readings := map[string]interface{}{
+		"temp":     21.5,
+		"position": map[string]interface{}{"x": 1.0, "y": map[string]interface{}{"z": "up"}},
+		"joints":   []interface{}{0.5, true},
+		"empty":    map[string]interface{}{},
+	}
+	test.That(t, FlattenReadings(readings), test.ShouldResemble, map[string]interface{}{
+		"temp":         21.5,
+		"position.x":   1.0,
+		"position.y.z": "up",
+		"joints.0":     0.5,
+		"joints.1":     true,
+	})
+}
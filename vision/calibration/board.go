@@ -0,0 +1,604 @@
+package calibration
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/vision/fiducial"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+// BoardType is the pattern of a calibration board.
+type BoardType string
+
+const (
+	// Checkerboard is a grid of alternating black and white squares, which must be entirely in view.
+	Checkerboard BoardType = "checkerboard"
+	// Charuco is a checkerboard with an ArUco marker in each white square, so that corners can be found when only
+	// part of the board is in view. The markers are numbered from 0 in the order of the white squares, row by row
+	// from the top left.
+	Charuco BoardType = "charuco"
+)
+
+// DefaultCharucoDictionary is the dictionary of the markers of a Charuco board which does not name one. It is
+// OpenCV's DICT_ARUCO_ORIGINAL.
+const DefaultCharucoDictionary = "aruco_original"
+
+// charucoDictionaries are the built in marker dictionaries Charuco boards can use, by name. Boards from OpenCV's
+// other predefined dictionaries, such as DICT_4X4_50, DICT_5X5_100 or DICT_6X6_250, name the dictionary file
+// written by OpenCV instead.
+var charucoDictionaries = map[string]*fiducial.Family{
+	DefaultCharucoDictionary: fiducial.ArucoOriginal,
+}
+
+// CharucoDictionaries returns the names of the marker dictionaries Charuco boards can use.
+func CharucoDictionaries() []string {
+	names := make([]string, 0, len(charucoDictionaries))
+	for name := range charucoDictionaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// minViewCorners is the fewest corners of a board that must be found in an image to use it for calibration.
+const minViewCorners = 6
+
+// Board is a calibration board. The square at the top left of the board is black.
+type Board struct {
+	Type BoardType `json:"type"`
+	// Cols and Rows are the number of squares across and down the board, so the board has (Cols-1)*(Rows-1)
+	// inner corners.
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+	// SquareSize is the length of the sides of the squares, in mm.
+	SquareSize float64 `json:"square_size_mm"`
+	// MarkerSize is the length of the sides of the black borders of the markers of a Charuco board, in mm.
+	MarkerSize float64 `json:"marker_size_mm,omitempty"`
+	// Dictionary is the name of the dictionary of the markers of a Charuco board, DefaultCharucoDictionary if
+	// neither it nor DictionaryFile is given. Only the dictionaries in CharucoDictionaries are built in.
+	Dictionary string `json:"dictionary,omitempty"`
+	// DictionaryFile is the path of a dictionary of the markers of a Charuco board, such as DICT_4X4_50, written
+	// by OpenCV, as read by fiducial.LoadFamily. It is used instead of Dictionary.
+	DictionaryFile string `json:"dictionary_file,omitempty"`
+}
+
+// markerFamily returns the family of the markers of a Charuco board.
+func (b *Board) markerFamily() (*fiducial.Family, error) {
+	if b.DictionaryFile != "" {
+		if b.Dictionary != "" {
+			return nil, errors.New("a charuco board cannot have both a dictionary and a dictionary file")
+		}
+		return fiducial.LoadFamily(b.DictionaryFile)
+	}
+	name := b.Dictionary
+	if name == "" {
+		name = DefaultCharucoDictionary
+	}
+	family, ok := charucoDictionaries[name]
+	if !ok {
+		return nil, errors.Errorf("unsupported charuco dictionary %q, must be one of %v", b.Dictionary, CharucoDictionaries())
+	}
+	return family, nil
+}
+
+// Validate returns an error if the board cannot be used for calibration.
+func (b *Board) Validate() error {
+	if b.Cols < 3 || b.Rows < 3 {
+		return errors.Errorf("a board must have at least 3 squares on each side, got %dx%d", b.Cols, b.Rows)
+	}
+	if b.SquareSize <= 0 {
+		return errors.New("square size must be positive")
+	}
+	switch b.Type {
+	case Checkerboard:
+	case Charuco:
+		if b.MarkerSize <= 0 || b.MarkerSize >= b.SquareSize {
+			return errors.New("marker size must be positive and smaller than the square size")
+		}
+		family, err := b.markerFamily()
+		if err != nil {
+			return err
+		}
+		if n := b.Cols * b.Rows / 2; n > len(family.Codes) {
+			return errors.Errorf("a charuco board can have at most %d white squares, got %d", len(family.Codes), n)
+		}
+	default:
+		return errors.Errorf("unknown board type %q, must be %q or %q", b.Type, Checkerboard, Charuco)
+	}
+	return nil
+}
+
+// numCorners returns the number of inner corners of the board.
+func (b *Board) numCorners() int {
+	return (b.Cols - 1) * (b.Rows - 1)
+}
+
+// ObjectPoint returns the position of an inner corner on the board in mm, with x to the right and y down from
+// the top left corner of the board. Inner corners are numbered row by row from the top left.
+func (b *Board) ObjectPoint(id int) r3.Vector {
+	return r3.Vector{X: float64(id%(b.Cols-1)+1) * b.SquareSize, Y: float64(id/(b.Cols-1)+1) * b.SquareSize}
+}
+
+// isBlack returns whether the square in the column and row is black.
+func isBlack(col, row int) bool {
+	return (col+row)%2 == 0
+}
+
+// markerSquare returns the column and row of the white square with the marker with the given ID.
+func (b *Board) markerSquare(id int) (int, int) {
+	// each row has Cols/2 white squares, with one more on odd rows if Cols is odd
+	for row := 0; row < b.Rows; row++ {
+		perRow := b.Cols / 2
+		if b.Cols%2 == 1 && row%2 == 1 {
+			perRow++
+		}
+		if id < perRow {
+			col := 2*id + 1
+			if row%2 == 1 {
+				col = 2 * id
+			}
+			return col, row
+		}
+		id -= perRow
+	}
+	return -1, -1
+}
+
+// RenderBoard draws the board with squares of squareSize pixels and a white margin of one square around it, such
+// that it can be printed.
+func RenderBoard(b *Board, squareSize int) (*image.Gray, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	if squareSize < 1 {
+		return nil, errors.New("square size must be at least one pixel")
+	}
+	img := image.NewGray(image.Rect(0, 0, (b.Cols+2)*squareSize, (b.Rows+2)*squareSize))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 255}), image.Point{}, draw.Src)
+	for row := 0; row < b.Rows; row++ {
+		for col := 0; col < b.Cols; col++ {
+			if isBlack(col, row) {
+				square := image.Rect((col+1)*squareSize, (row+1)*squareSize, (col+2)*squareSize, (row+2)*squareSize)
+				draw.Draw(img, square, image.NewUniform(color.Gray{Y: 0}), image.Point{}, draw.Src)
+			}
+		}
+	}
+	if b.Type != Charuco {
+		return img, nil
+	}
+	family, err := b.markerFamily()
+	if err != nil {
+		return nil, err
+	}
+	// the marker image has a one cell margin around its border, which is trimmed
+	markerCells := family.Size + 2
+	markerSize := float64(squareSize) * b.MarkerSize / b.SquareSize
+	cellSize := int(markerSize / float64(markerCells))
+	if cellSize < 1 {
+		return nil, errors.New("square size is too small to draw the markers")
+	}
+	for id := 0; id < b.Cols*b.Rows/2; id++ {
+		marker, err := fiducial.Render(family, id, cellSize)
+		if err != nil {
+			return nil, err
+		}
+		col, row := b.markerSquare(id)
+		if col < 0 {
+			break
+		}
+		size := markerCells * cellSize
+		offset := (squareSize - size) / 2
+		at := image.Pt((col+1)*squareSize+offset, (row+1)*squareSize+offset)
+		draw.Draw(img, image.Rectangle{at, at.Add(image.Pt(size, size))}, marker, image.Pt(cellSize, cellSize), draw.Src)
+	}
+	return img, nil
+}
+
+// View is the inner corners of a board found in an image.
+type View struct {
+	// IDs are the inner corners found, as numbered by Board.ObjectPoint.
+	IDs []int `json:"ids"`
+	// Corners are the positions of the inner corners in the image, in pixels.
+	Corners []r2.Point `json:"corners"`
+}
+
+// FindCorners finds the inner corners of the board in the image, to sub-pixel accuracy.
+func (b *Board) FindCorners(img image.Image) (*View, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Bounds(), img, bounds.Min, draw.Src)
+
+	var view *View
+	var radius int
+	var err error
+	if b.Type == Checkerboard {
+		view, radius, err = b.findCheckerboardCorners(gray)
+	} else {
+		view, radius, err = b.findCharucoCorners(gray)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range view.Corners {
+		view.Corners[i] = refineCorner(gray, c, radius)
+	}
+	return view, nil
+}
+
+// findCheckerboardCorners finds the black squares of the board, and the inner corners where their corners meet.
+// It returns the corners along with a search radius for refining them.
+func (b *Board) findCheckerboardCorners(gray *image.Gray) (*View, int, error) {
+	threshold := otsuThreshold(gray)
+	dark := make([]bool, len(gray.Pix))
+	for i, v := range gray.Pix {
+		dark[i] = int(v) < threshold
+	}
+	// diagonally adjacent black squares touch at their corners, so they are separated by eroding them, by as few
+	// pixels as it takes
+	w := gray.Rect.Dx()
+	var lastErr error
+	for erosion := 1; erosion <= 3; erosion++ {
+		dark = erode(dark, w)
+		clusters, _ := objectdetection.ConnectedComponents(gray, func(_ image.Image, pt image.Point) bool {
+			return dark[pt.Y*w+pt.X]
+		})
+		var quads [][4]r2.Point
+		for _, cluster := range clusters {
+			if quad, ok := fiducial.FitQuad(cluster); ok {
+				quads = append(quads, quad)
+			}
+		}
+		view, radius, err := b.gridFromQuads(quads, func(pt r2.Point) bool {
+			x, y := int(pt.X), int(pt.Y)
+			return x >= 0 && y >= 0 && x < w && y < len(dark)/w && dark[y*w+x]
+		})
+		if err == nil {
+			return view, radius, nil
+		}
+		lastErr = err
+	}
+	return nil, 0, errors.Wrap(lastErr, "could not find the checkerboard")
+}
+
+type gridCorner struct {
+	pt         r2.Point
+	neighbors  []int
+	assigned   bool
+	col, row   int
+	colAxis    r2.Point
+	rowAxis    r2.Point
+	squareSide float64
+}
+
+// gridFromQuads links the corners of black squares that meet into inner corners, and numbers the inner corners
+// by walking the grid they form. isDark reports whether a point in the image is in a black square.
+func (b *Board) gridFromQuads(quads [][4]r2.Point, isDark func(r2.Point) bool) (*View, int, error) {
+	side := func(q [4]r2.Point) float64 {
+		s := math.Inf(1)
+		for i := range q {
+			s = math.Min(s, q[i].Sub(q[(i+1)%4]).Norm())
+		}
+		return s
+	}
+	// each corner of a square is linked to the nearest corner of another square, if they are nearest to each other
+	type ref struct{ quad, corner int }
+	nearest := func(from ref) (ref, float64) {
+		best, bestDist := ref{-1, -1}, math.Inf(1)
+		for qi, q := range quads {
+			if qi == from.quad {
+				continue
+			}
+			for ci, c := range q {
+				if d := c.Sub(quads[from.quad][from.corner]).Norm(); d < bestDist {
+					best, bestDist = ref{qi, ci}, d
+				}
+			}
+		}
+		return best, bestDist
+	}
+	cornerOf := map[ref]int{}
+	var corners []*gridCorner
+	for qi, q := range quads {
+		for ci := range q {
+			from := ref{qi, ci}
+			if _, ok := cornerOf[from]; ok {
+				continue
+			}
+			to, dist := nearest(from)
+			if to.quad < 0 || dist > 0.5*math.Min(side(q), side(quads[to.quad])) {
+				continue
+			}
+			if back, _ := nearest(to); back != from {
+				continue
+			}
+			cornerOf[from], cornerOf[to] = len(corners), len(corners)
+			corners = append(corners, &gridCorner{
+				pt:         q[ci].Add(quads[to.quad][to.corner]).Mul(0.5),
+				squareSide: math.Min(side(q), side(quads[to.quad])),
+			})
+		}
+	}
+	if len(corners) != b.numCorners() {
+		return nil, 0, errors.Errorf("found %d inner corners, expected %d", len(corners), b.numCorners())
+	}
+	// the sides of the squares between inner corners link the corners one square apart
+	for qi := range quads {
+		for ci := 0; ci < 4; ci++ {
+			from, okFrom := cornerOf[ref{qi, ci}]
+			to, okTo := cornerOf[ref{qi, (ci + 1) % 4}]
+			if okFrom && okTo {
+				corners[from].neighbors = append(corners[from].neighbors, to)
+				corners[to].neighbors = append(corners[to].neighbors, from)
+			}
+		}
+	}
+
+	// walk the grid from a corner with neighbors in every direction, tracking the local directions of the columns
+	// and rows, which change across the image with perspective and distortion
+	start := -1
+	for i, c := range corners {
+		if len(c.neighbors) == 4 || (start < 0 && len(c.neighbors) >= 2) {
+			start = i
+		}
+	}
+	if start < 0 {
+		return nil, 0, errors.New("inner corners are not connected")
+	}
+	first := corners[start]
+	first.assigned = true
+	first.colAxis = corners[first.neighbors[0]].pt.Sub(first.pt)
+	first.rowAxis = first.colAxis.Ortho()
+	queue := []int{start}
+	minRadius := math.Inf(1)
+	for len(queue) > 0 {
+		c := corners[queue[0]]
+		queue = queue[1:]
+		minRadius = math.Min(minRadius, c.squareSide)
+		for _, ni := range c.neighbors {
+			n := corners[ni]
+			d := n.pt.Sub(c.pt)
+			col, row := c.col, c.row
+			colAxis, rowAxis := c.colAxis, c.rowAxis
+			alongCol := d.Dot(c.colAxis) / c.colAxis.Norm()
+			alongRow := d.Dot(c.rowAxis) / c.rowAxis.Norm()
+			if math.Abs(alongCol) > math.Abs(alongRow) {
+				if alongCol > 0 {
+					col++
+					colAxis = d
+				} else {
+					col--
+					colAxis = d.Mul(-1)
+				}
+			} else {
+				if alongRow > 0 {
+					row++
+					rowAxis = d
+				} else {
+					row--
+					rowAxis = d.Mul(-1)
+				}
+			}
+			if n.assigned {
+				if n.col != col || n.row != row {
+					return nil, 0, errors.New("inner corners do not form a grid")
+				}
+				continue
+			}
+			n.assigned, n.col, n.row, n.colAxis, n.rowAxis = true, col, row, colAxis, rowAxis
+			queue = append(queue, ni)
+		}
+	}
+
+	minCol, minRow, maxCol, maxRow := math.MaxInt, math.MaxInt, math.MinInt, math.MinInt
+	for _, c := range corners {
+		if !c.assigned {
+			return nil, 0, errors.New("inner corners are not connected")
+		}
+		minCol, minRow = min(minCol, c.col), min(minRow, c.row)
+		maxCol, maxRow = max(maxCol, c.col), max(maxRow, c.row)
+	}
+	cols, rows := maxCol-minCol+1, maxRow-minRow+1
+	// the walk may have started along the rows of the board rather than the columns, which turns the grid by a
+	// quarter turn
+	turned := cols != b.Cols-1
+	if turned {
+		cols, rows = rows, cols
+	}
+	if cols != b.Cols-1 || rows != b.Rows-1 {
+		return nil, 0, errors.Errorf("inner corners form a %dx%d grid, expected %dx%d", cols, rows, b.Cols-1, b.Rows-1)
+	}
+	view := &View{}
+	for _, c := range corners {
+		col, row := c.col-minCol, c.row-minRow
+		if turned {
+			col, row = c.row-minRow, maxCol-c.col
+		}
+		view.IDs = append(view.IDs, row*(b.Cols-1)+col)
+		view.Corners = append(view.Corners, c.pt)
+	}
+	view.sort()
+	// the grid may also have been walked from the opposite corner of the board. The square between the first two
+	// corners on the diagonal is black, which tells which corner is which unless the board looks the same when
+	// turned by a half turn.
+	if center := view.Corners[0].Add(view.Corners[b.Cols]).Mul(0.5); !isDark(center) {
+		for i, id := range view.IDs {
+			view.IDs[i] = b.numCorners() - 1 - id
+		}
+		view.sort()
+	}
+	return view, refineRadius(minRadius), nil
+}
+
+// findCharucoCorners finds the markers of the board, and predicts the inner corners next to each marker from
+// where the marker is.
+func (b *Board) findCharucoCorners(gray *image.Gray) (*View, int, error) {
+	family, err := b.markerFamily()
+	if err != nil {
+		return nil, 0, err
+	}
+	markers := fiducial.Detect(gray, []*fiducial.Family{family})
+	// the homography from the board to the image around each white square with a marker in it
+	squares := map[image.Point]*mat.Dense{}
+	minSide := math.Inf(1)
+	for _, marker := range markers {
+		col, row := b.markerSquare(marker.ID)
+		if col < 0 {
+			continue
+		}
+		offset := (b.SquareSize - b.MarkerSize) / 2
+		x0, y0 := float64(col)*b.SquareSize+offset, float64(row)*b.SquareSize+offset
+		x1, y1 := x0+b.MarkerSize, y0+b.MarkerSize
+		h, err := estimateHomography([]r2.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}, marker.Corners[:])
+		if err != nil {
+			continue
+		}
+		squares[image.Pt(col, row)] = h
+		for i := range marker.Corners {
+			minSide = math.Min(minSide, marker.Corners[i].Sub(marker.Corners[(i+1)%4]).Norm()*b.SquareSize/b.MarkerSize)
+		}
+	}
+
+	view := &View{}
+	for id := 0; id < b.numCorners(); id++ {
+		col, row := id%(b.Cols-1), id/(b.Cols-1)
+		obj := b.ObjectPoint(id)
+		var predicted []r2.Point
+		// each inner corner is a corner of the four squares around it, two of which are white
+		for _, sq := range []image.Point{{col, row}, {col + 1, row}, {col, row + 1}, {col + 1, row + 1}} {
+			if h, ok := squares[sq]; ok {
+				predicted = append(predicted, applyHomography(h, r2.Point{X: obj.X, Y: obj.Y}))
+			}
+		}
+		if len(predicted) == 0 {
+			continue
+		}
+		var mean r2.Point
+		for _, p := range predicted {
+			mean = mean.Add(p)
+		}
+		mean = mean.Mul(1 / float64(len(predicted)))
+		// markers on either side of a corner that disagree about where it is were not decoded correctly
+		if len(predicted) == 2 && predicted[0].Sub(predicted[1]).Norm() > 0.25*minSide {
+			continue
+		}
+		view.IDs = append(view.IDs, id)
+		view.Corners = append(view.Corners, mean)
+	}
+	if len(view.IDs) < minViewCorners {
+		return nil, 0, errors.Errorf("found %d inner corners of the charuco board, need at least %d", len(view.IDs), minViewCorners)
+	}
+	// the window to refine the corners in must not reach the markers in the squares around them
+	margin := minSide * (b.SquareSize - b.MarkerSize) / (2 * b.SquareSize)
+	return view, int(math.Max(2, math.Min(float64(refineRadius(minSide)), margin-1))), nil
+}
+
+func (v *View) sort() {
+	order := make([]int, len(v.IDs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return v.IDs[order[i]] < v.IDs[order[j]] })
+	ids, corners := make([]int, len(order)), make([]r2.Point, len(order))
+	for i, o := range order {
+		ids[i], corners[i] = v.IDs[o], v.Corners[o]
+	}
+	v.IDs, v.Corners = ids, corners
+}
+
+// refineRadius returns the radius of the window to refine corners in, which must stay within the squares around
+// each corner.
+func refineRadius(squareSide float64) int {
+	return int(math.Max(2, math.Min(10, squareSide/4)))
+}
+
+// refineCorner moves a corner to sub-pixel accuracy, as the point where the gradients of the pixels around it
+// are all perpendicular to the direction to it.
+func refineCorner(gray *image.Gray, corner r2.Point, radius int) r2.Point {
+	w, h := gray.Rect.Dx(), gray.Rect.Dy()
+	at := func(x, y int) float64 {
+		return float64(gray.Pix[y*gray.Stride+x])
+	}
+	for iter := 0; iter < 20; iter++ {
+		cx, cy := int(math.Round(corner.X-0.5)), int(math.Round(corner.Y-0.5))
+		var a11, a12, a22, b1, b2 float64
+		for y := cy - radius; y <= cy+radius; y++ {
+			for x := cx - radius; x <= cx+radius; x++ {
+				if x < 1 || y < 1 || x >= w-1 || y >= h-1 {
+					continue
+				}
+				gx := (at(x+1, y) - at(x-1, y)) / 2
+				gy := (at(x, y+1) - at(x, y-1)) / 2
+				px, py := float64(x)+0.5, float64(y)+0.5
+				a11 += gx * gx
+				a12 += gx * gy
+				a22 += gy * gy
+				b1 += gx*gx*px + gx*gy*py
+				b2 += gx*gy*px + gy*gy*py
+			}
+		}
+		det := a11*a22 - a12*a12
+		if math.Abs(det) < 1e-9 {
+			return corner
+		}
+		next := r2.Point{X: (a22*b1 - a12*b2) / det, Y: (a11*b2 - a12*b1) / det}
+		moved := next.Sub(corner).Norm()
+		corner = next
+		if moved < 0.01 {
+			break
+		}
+	}
+	return corner
+}
+
+// otsuThreshold returns the intensity that best separates the pixels of the image into dark and light.
+func otsuThreshold(gray *image.Gray) int {
+	var hist [256]float64
+	for _, v := range gray.Pix {
+		hist[v]++
+	}
+	total := float64(len(gray.Pix))
+	var sum float64
+	for i, n := range hist {
+		sum += float64(i) * n
+	}
+	var sumDark, countDark, best float64
+	threshold := 128
+	for i, n := range hist {
+		countDark += n
+		if countDark == 0 || countDark == total {
+			continue
+		}
+		sumDark += float64(i) * n
+		meanDark, meanLight := sumDark/countDark, (sum-sumDark)/(total-countDark)
+		between := countDark * (total - countDark) * (meanDark - meanLight) * (meanDark - meanLight)
+		if between > best {
+			best, threshold = between, i+1
+		}
+	}
+	return threshold
+}
+
+// erode shrinks the set pixels of a mask of the given width by one pixel.
+func erode(mask []bool, w int) []bool {
+	h := len(mask) / w
+	out := make([]bool, len(mask))
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			out[i] = mask[i] && mask[i-1] && mask[i+1] && mask[i-w] && mask[i+w]
+		}
+	}
+	return out
+}
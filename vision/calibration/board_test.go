@@ -0,0 +1,120 @@
+package calibration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+// project returns where the test camera sees an inner corner of the board, posed as in renderView.
+func project(b *Board, rotation, center r3.Vector, id int) r2.Point {
+	translation := center.Sub(rotate(rotation, r3.Vector{X: float64(b.Cols) * b.SquareSize / 2, Y: float64(b.Rows) * b.SquareSize / 2}))
+	pt := rotate(rotation, b.ObjectPoint(id)).Add(translation)
+	x, y := testDistortion.Transform(pt.X/pt.Z, pt.Y/pt.Z)
+	return r2.Point{X: testIntrinsics.Fx*x + testIntrinsics.Ppx, Y: testIntrinsics.Fy*y + testIntrinsics.Ppy}
+}
+
+func TestBoardValidate(t *testing.T) {
+	test.That(t, (&Board{Type: Checkerboard, Cols: 9, Rows: 7, SquareSize: 25}).Validate(), test.ShouldBeNil)
+	test.That(t, (&Board{Type: Charuco, Cols: 7, Rows: 5, SquareSize: 40, MarkerSize: 28}).Validate(), test.ShouldBeNil)
+	test.That(t, (&Board{Type: Checkerboard, Cols: 2, Rows: 7, SquareSize: 25}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&Board{Type: Checkerboard, Cols: 9, Rows: 7}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&Board{Type: Charuco, Cols: 7, Rows: 5, SquareSize: 40, MarkerSize: 40}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&Board{Type: Charuco, Cols: 50, Rows: 50, SquareSize: 40, MarkerSize: 28}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&Board{Type: "circles", Cols: 9, Rows: 7, SquareSize: 25}).Validate(), test.ShouldNotBeNil)
+
+	test.That(t, (&Board{Type: Charuco, Cols: 7, Rows: 5, SquareSize: 40, MarkerSize: 28, Dictionary: DefaultCharucoDictionary}).Validate(),
+		test.ShouldBeNil)
+	err := (&Board{Type: Charuco, Cols: 7, Rows: 5, SquareSize: 40, MarkerSize: 28, Dictionary: "DICT_4X4_50"}).Validate()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unsupported charuco dictionary \"DICT_4X4_50\"")
+
+	dictionary := writeDictionary(t, 4)
+	test.That(t, (&Board{Type: Charuco, Cols: 3, Rows: 3, SquareSize: 40, MarkerSize: 28, DictionaryFile: dictionary}).Validate(),
+		test.ShouldBeNil)
+	test.That(t, (&Board{Type: Charuco, Cols: 7, Rows: 5, SquareSize: 40, MarkerSize: 28, DictionaryFile: dictionary}).Validate(),
+		test.ShouldNotBeNil)
+	test.That(t, (&Board{
+		Type: Charuco, Cols: 3, Rows: 3, SquareSize: 40, MarkerSize: 28, Dictionary: DefaultCharucoDictionary, DictionaryFile: dictionary,
+	}).Validate(), test.ShouldNotBeNil)
+	test.That(t, (&Board{
+		Type: Charuco, Cols: 3, Rows: 3, SquareSize: 40, MarkerSize: 28, DictionaryFile: filepath.Join(t.TempDir(), "DICT_4X4_50.yml"),
+	}).Validate(), test.ShouldNotBeNil)
+}
+
+// writeDictionary writes a dictionary of n markers in the format of OpenCV, with the markers of the original
+// ArUco dictionary in reverse order, and returns its path.
+func writeDictionary(t *testing.T, n int) string {
+	t.Helper()
+	contents := fmt.Sprintf("%%YAML:1.0\n---\nnmarkers: %d\nmarkersize: 5\nmaxCorrectionBits: 0\n", n)
+	for id := 0; id < n; id++ {
+		contents += fmt.Sprintf("marker_%d: \"%025b\"\n", id, fiducial.ArucoOriginal.Codes[len(fiducial.ArucoOriginal.Codes)-1-id])
+	}
+	path := filepath.Join(t.TempDir(), "DICT_TEST.yml")
+	test.That(t, os.WriteFile(path, []byte(contents), 0o600), test.ShouldBeNil)
+	return path
+}
+
+func TestRenderBoard(t *testing.T) {
+	b := &Board{Type: Charuco, Cols: 5, Rows: 4, SquareSize: 40, MarkerSize: 30}
+	img, err := RenderBoard(b, 60)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Bounds().Dx(), test.ShouldEqual, 7*60)
+	test.That(t, img.Bounds().Dy(), test.ShouldEqual, 6*60)
+	// the margin is white and the top left square is black
+	test.That(t, img.GrayAt(30, 30).Y, test.ShouldEqual, 255)
+	test.That(t, img.GrayAt(61, 61).Y, test.ShouldEqual, 0)
+
+	markers := fiducial.Detect(img, []*fiducial.Family{fiducial.ArucoOriginal})
+	test.That(t, markers, test.ShouldHaveLength, b.Cols*b.Rows/2)
+	for _, marker := range markers {
+		col, row := b.markerSquare(marker.ID)
+		test.That(t, isBlack(col, row), test.ShouldBeFalse)
+		center := marker.BoundingBox().Min.Add(marker.BoundingBox().Max).Div(2)
+		test.That(t, center.X/60, test.ShouldEqual, col+1)
+		test.That(t, center.Y/60, test.ShouldEqual, row+1)
+	}
+
+	_, err = RenderBoard(b, 3)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = RenderBoard(&Board{Type: Checkerboard, Cols: 9, Rows: 7}, 60)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestFindCorners(t *testing.T) {
+	for _, tc := range []struct {
+		board    *Board
+		rotation r3.Vector
+		center   r3.Vector
+		corners  int
+	}{
+		// the board is upside down, which can be told apart from the right way up since it has an odd number of
+		// rows and an even number of columns
+		{&Board{Type: Checkerboard, Cols: 8, Rows: 7, SquareSize: 25}, r3.Vector{X: 0.2, Z: 3}, r3.Vector{Z: 400}, 42},
+		{&Board{Type: Checkerboard, Cols: 8, Rows: 7, SquareSize: 25}, r3.Vector{Y: 0.3, Z: 1.4}, r3.Vector{Z: 400}, 42},
+		{&Board{Type: Charuco, Cols: 7, Rows: 5, SquareSize: 40, MarkerSize: 28}, r3.Vector{Y: 0.3, Z: -2}, r3.Vector{Z: 400}, 24},
+		{
+			&Board{Type: Charuco, Cols: 7, Rows: 5, SquareSize: 40, MarkerSize: 28, DictionaryFile: writeDictionary(t, 17)},
+			r3.Vector{Y: 0.3, Z: -2}, r3.Vector{Z: 400}, 24,
+		},
+	} {
+		view, err := tc.board.FindCorners(renderView(t, tc.board, tc.rotation, tc.center))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, view.IDs, test.ShouldHaveLength, tc.corners)
+		for i, id := range view.IDs {
+			test.That(t, view.Corners[i].Sub(project(tc.board, tc.rotation, tc.center, id)).Norm(), test.ShouldBeLessThan, 0.5)
+		}
+	}
+
+	// a checkerboard must be entirely in view
+	b := &Board{Type: Checkerboard, Cols: 9, Rows: 7, SquareSize: 25}
+	_, err := b.FindCorners(renderView(t, b, r3.Vector{}, r3.Vector{X: 150, Z: 300}))
+	test.That(t, err, test.ShouldNotBeNil)
+}
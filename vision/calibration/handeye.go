@@ -0,0 +1,318 @@
+// Package calibration solves for the intrinsics and poses of cameras from observations of calibration targets.
+package calibration
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// HandEyeMode is where the camera is mounted relative to the arm.
+type HandEyeMode string
+
+const (
+	// EyeInHand is a camera mounted on the end effector of the arm, looking at a target fixed relative to the
+	// base of the arm.
+	EyeInHand HandEyeMode = "eye_in_hand"
+	// EyeToHand is a camera fixed relative to the base of the arm, looking at a target mounted on the end
+	// effector of the arm.
+	EyeToHand HandEyeMode = "eye_to_hand"
+)
+
+// MinHandEyeSamples is the fewest samples a hand-eye calibration can be solved from. The arm must rotate about
+// at least two different axes between them.
+const MinHandEyeSamples = 3
+
+// Sample is an observation of the calibration target by the camera at one position of the arm.
+type Sample struct {
+	// ArmPose is the pose of the end effector relative to the base of the arm, as returned by EndPosition.
+	ArmPose spatialmath.Pose
+	// TargetPose is the pose of the target relative to the camera.
+	TargetPose spatialmath.Pose
+}
+
+type sampleJSON struct {
+	ArmPose    *commonpb.Pose `json:"arm_pose"`
+	TargetPose *commonpb.Pose `json:"target_pose"`
+}
+
+// MarshalJSON marshals the poses of a sample as x, y, z, o_x, o_y, o_z and theta in degrees.
+func (s Sample) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sampleJSON{
+		ArmPose:    spatialmath.PoseToProtobuf(s.ArmPose),
+		TargetPose: spatialmath.PoseToProtobuf(s.TargetPose),
+	})
+}
+
+// UnmarshalJSON unmarshals a sample marshaled by MarshalJSON.
+func (s *Sample) UnmarshalJSON(data []byte) error {
+	var sj sampleJSON
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+	if sj.ArmPose == nil || sj.TargetPose == nil {
+		return errors.New("a sample must have both an arm_pose and a target_pose")
+	}
+	s.ArmPose = spatialmath.NewPoseFromProtobuf(sj.ArmPose)
+	s.TargetPose = spatialmath.NewPoseFromProtobuf(sj.TargetPose)
+	return nil
+}
+
+// Residuals measure how far each sample is from agreeing with a calibration. For each sample, the pose that
+// should be the same in every sample is computed from the calibration, and compared to the mean of that pose.
+type Residuals struct {
+	// Translation is the distance of each sample from the mean, in mm.
+	Translation []float64 `json:"translation_mm"`
+	// Rotation is the angle of each sample from the mean, in degrees.
+	Rotation       []float64 `json:"rotation_deg"`
+	TranslationRMS float64   `json:"translation_rms_mm"`
+	TranslationMax float64   `json:"translation_max_mm"`
+	RotationRMS    float64   `json:"rotation_rms_deg"`
+	RotationMax    float64   `json:"rotation_max_deg"`
+}
+
+// HandEyeCalibration is the solution to a hand-eye calibration.
+type HandEyeCalibration struct {
+	Mode HandEyeMode
+	// Camera is the pose of the camera relative to the end effector for EyeInHand, or relative to the base of
+	// the arm for EyeToHand.
+	Camera spatialmath.Pose
+	// Target is the pose of the target relative to the base of the arm for EyeInHand, or relative to the end
+	// effector for EyeToHand.
+	Target    spatialmath.Pose
+	Residuals *Residuals
+}
+
+// SolveHandEye finds the pose of a camera relative to an arm from samples of the arm at different positions.
+// For EyeInHand, the target must not move relative to the base of the arm between samples, and for EyeToHand,
+// the camera must not.
+func SolveHandEye(mode HandEyeMode, samples []Sample) (*HandEyeCalibration, error) {
+	if len(samples) < MinHandEyeSamples {
+		return nil, errors.Errorf("need at least %d samples to calibrate, have %d", MinHandEyeSamples, len(samples))
+	}
+	a := make([]spatialmath.Pose, 0, len(samples))
+	b := make([]spatialmath.Pose, 0, len(samples))
+	for _, s := range samples {
+		a = append(a, s.ArmPose)
+		switch mode {
+		case EyeInHand:
+			// arm * camera * target = base to target, so arm * camera = base to target * target^-1
+			b = append(b, spatialmath.PoseInverse(s.TargetPose))
+		case EyeToHand:
+			// arm * end effector to target = base to camera * target
+			b = append(b, s.TargetPose)
+		default:
+			return nil, errors.Errorf("unknown hand-eye mode %q, must be %q or %q", mode, EyeInHand, EyeToHand)
+		}
+	}
+	x, y, err := SolveAXYB(a, b)
+	if err != nil {
+		return nil, err
+	}
+	cal := &HandEyeCalibration{Mode: mode, Residuals: residualsAXYB(a, b, x, y)}
+	if mode == EyeInHand {
+		cal.Camera, cal.Target = x, y
+	} else {
+		cal.Camera, cal.Target = y, x
+	}
+	return cal, nil
+}
+
+// LinkConfig returns the frame of the camera with the given ID. For EyeInHand, parent should be the name of the
+// arm, and for EyeToHand, the name of the frame the base of the arm is at the origin of.
+func (cal *HandEyeCalibration) LinkConfig(id, parent string) (*referenceframe.LinkConfig, error) {
+	orientation, err := spatialmath.NewOrientationConfig(cal.Camera.Orientation())
+	if err != nil {
+		return nil, err
+	}
+	return &referenceframe.LinkConfig{
+		ID:          id,
+		Translation: cal.Camera.Point(),
+		Orientation: orientation,
+		Parent:      parent,
+	}, nil
+}
+
+// SolveAXXB finds the X that best satisfies A_i * X = X * B_i for every pair of poses, where A_i and B_i are the
+// same motion seen from two frames rigidly attached to each other. The rotation is solved with the method of
+// Park and Martin, and then the translation by linear least squares.
+func SolveAXXB(a, b []spatialmath.Pose) (spatialmath.Pose, error) {
+	if len(a) != len(b) {
+		return nil, errors.Errorf("have %d A poses but %d B poses", len(a), len(b))
+	}
+	if len(a) < 2 {
+		return nil, errors.New("need at least two motions to solve AX=XB")
+	}
+
+	// the rotation axes of the motions are related by R_A_i axis = R_X R_B_i axis
+	m := mat.NewDense(3, 3, nil)
+	for i := range a {
+		alpha, beta := rotationVector(a[i].Orientation()), rotationVector(b[i].Orientation())
+		m.Add(m, mat.NewDense(3, 3, []float64{
+			alpha.X * beta.X, alpha.X * beta.Y, alpha.X * beta.Z,
+			alpha.Y * beta.X, alpha.Y * beta.Y, alpha.Y * beta.Z,
+			alpha.Z * beta.X, alpha.Z * beta.Y, alpha.Z * beta.Z,
+		}))
+	}
+	rx, values, err := nearestRotation(m)
+	if err != nil {
+		return nil, err
+	}
+	if values[1] < 1e-6*values[0] {
+		return nil, errors.New("the arm must rotate about at least two different axes between samples")
+	}
+
+	// R_A_i t_X + t_A_i = R_X t_B_i + t_X, so (R_A_i - I) t_X = R_X t_B_i - t_A_i
+	lhs := mat.NewDense(3*len(a), 3, nil)
+	rhs := mat.NewVecDense(3*len(a), nil)
+	for i := range a {
+		ra := rotationMatrix(a[i].Orientation())
+		tb := mat.NewVecDense(3, []float64{b[i].Point().X, b[i].Point().Y, b[i].Point().Z})
+		var rtb mat.VecDense
+		rtb.MulVec(rx, tb)
+		ta := a[i].Point()
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				v := ra.At(row, col)
+				if row == col {
+					v--
+				}
+				lhs.Set(3*i+row, col, v)
+			}
+		}
+		rhs.SetVec(3*i, rtb.AtVec(0)-ta.X)
+		rhs.SetVec(3*i+1, rtb.AtVec(1)-ta.Y)
+		rhs.SetVec(3*i+2, rtb.AtVec(2)-ta.Z)
+	}
+	var tx mat.VecDense
+	if err := tx.SolveVec(lhs, rhs); err != nil {
+		return nil, errors.Wrap(err, "could not solve for the translation of X")
+	}
+	orientation, err := orientationFromMatrix(rx)
+	if err != nil {
+		return nil, err
+	}
+	return spatialmath.NewPose(r3.Vector{X: tx.AtVec(0), Y: tx.AtVec(1), Z: tx.AtVec(2)}, orientation), nil
+}
+
+// SolveAXYB finds the X and Y that best satisfy A_i * X = Y * B_i for every pair of poses. X is found from the
+// relative motions between every two samples, which satisfy AX=XB, and Y is then the mean of A_i * X * B_i^-1.
+func SolveAXYB(a, b []spatialmath.Pose) (spatialmath.Pose, spatialmath.Pose, error) {
+	if len(a) != len(b) {
+		return nil, nil, errors.Errorf("have %d A poses but %d B poses", len(a), len(b))
+	}
+	var motionsA, motionsB []spatialmath.Pose
+	for i := range a {
+		for j := i + 1; j < len(a); j++ {
+			// A_j^-1 A_i X = A_j^-1 Y B_i = X B_j^-1 B_i
+			motionsA = append(motionsA, spatialmath.PoseBetween(a[j], a[i]))
+			motionsB = append(motionsB, spatialmath.PoseBetween(b[j], b[i]))
+		}
+	}
+	x, err := SolveAXXB(motionsA, motionsB)
+	if err != nil {
+		return nil, nil, err
+	}
+	ys := make([]spatialmath.Pose, 0, len(a))
+	for i := range a {
+		ys = append(ys, spatialmath.Compose(spatialmath.Compose(a[i], x), spatialmath.PoseInverse(b[i])))
+	}
+	y, err := meanPose(ys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, y, nil
+}
+
+// residualsAXYB compares A_i * X * B_i^-1 for each sample to Y.
+func residualsAXYB(a, b []spatialmath.Pose, x, y spatialmath.Pose) *Residuals {
+	res := &Residuals{}
+	var sumTranslation, sumRotation float64
+	for i := range a {
+		yi := spatialmath.Compose(spatialmath.Compose(a[i], x), spatialmath.PoseInverse(b[i]))
+		diff := spatialmath.PoseBetween(y, yi)
+		translation := diff.Point().Norm()
+		rotation := utils.RadToDeg(math.Abs(diff.Orientation().AxisAngles().Theta))
+		res.Translation = append(res.Translation, translation)
+		res.Rotation = append(res.Rotation, rotation)
+		sumTranslation += translation * translation
+		sumRotation += rotation * rotation
+		res.TranslationMax = math.Max(res.TranslationMax, translation)
+		res.RotationMax = math.Max(res.RotationMax, rotation)
+	}
+	res.TranslationRMS = math.Sqrt(sumTranslation / float64(len(a)))
+	res.RotationRMS = math.Sqrt(sumRotation / float64(len(a)))
+	return res
+}
+
+// meanPose returns the pose with the mean translation of the poses, and the rotation nearest to the mean of
+// their rotation matrices.
+func meanPose(poses []spatialmath.Pose) (spatialmath.Pose, error) {
+	var translation r3.Vector
+	m := mat.NewDense(3, 3, nil)
+	for _, p := range poses {
+		translation = translation.Add(p.Point())
+		m.Add(m, rotationMatrix(p.Orientation()))
+	}
+	r, _, err := nearestRotation(m)
+	if err != nil {
+		return nil, err
+	}
+	orientation, err := orientationFromMatrix(r)
+	if err != nil {
+		return nil, err
+	}
+	return spatialmath.NewPose(translation.Mul(1/float64(len(poses))), orientation), nil
+}
+
+// nearestRotation returns the rotation R that maximizes trace(R^T M), along with the singular values of M.
+func nearestRotation(m mat.Matrix) (*mat.Dense, []float64, error) {
+	var svd mat.SVD
+	if !svd.Factorize(m, mat.SVDFull) {
+		return nil, nil, errors.New("could not factorize matrix")
+	}
+	var u, v, r mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	r.Mul(&u, v.T())
+	if mat.Det(&r) < 0 {
+		// flip the axis of the smallest singular value, so that R is a rotation rather than a reflection
+		u.Set(0, 2, -u.At(0, 2))
+		u.Set(1, 2, -u.At(1, 2))
+		u.Set(2, 2, -u.At(2, 2))
+		r.Mul(&u, v.T())
+	}
+	return &r, svd.Values(nil), nil
+}
+
+// rotationVector returns the axis of a rotation scaled by its angle in radians.
+func rotationVector(o spatialmath.Orientation) r3.Vector {
+	aa := o.AxisAngles()
+	return r3.Vector{X: aa.RX, Y: aa.RY, Z: aa.RZ}.Mul(aa.Theta)
+}
+
+// rotationMatrix returns the matrix that rotates column vectors by the orientation. A RotationMatrix holds the
+// transpose of this matrix.
+func rotationMatrix(o spatialmath.Orientation) *mat.Dense {
+	rm := o.RotationMatrix()
+	m := mat.NewDense(3, 3, nil)
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			m.Set(row, col, rm.At(col, row))
+		}
+	}
+	return m
+}
+
+// orientationFromMatrix is the inverse of rotationMatrix.
+func orientationFromMatrix(m mat.Matrix) (spatialmath.Orientation, error) {
+	return spatialmath.NewRotationMatrix(mat.DenseCopyOf(m.T()).RawMatrix().Data)
+}
@@ -0,0 +1,107 @@
+package calibration
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+func randomPose(rng *rand.Rand, translation, angle float64) spatialmath.Pose {
+	axis := r3.Vector{X: rng.NormFloat64(), Y: rng.NormFloat64(), Z: rng.NormFloat64()}.Normalize()
+	return spatialmath.NewPose(
+		r3.Vector{X: rng.NormFloat64(), Y: rng.NormFloat64(), Z: rng.NormFloat64()}.Mul(translation),
+		&spatialmath.R4AA{Theta: angle * (2*rng.Float64() - 1), RX: axis.X, RY: axis.Y, RZ: axis.Z},
+	)
+}
+
+// simulate returns samples of a camera and target for arm poses around a nominal pose, with noise added to the
+// observed target poses.
+func simulate(rng *rand.Rand, mode HandEyeMode, camera, target spatialmath.Pose, n int, noise float64) []Sample {
+	nominal := spatialmath.NewPose(r3.Vector{X: 400, Z: 300}, &spatialmath.OrientationVectorDegrees{OX: 1, Theta: 30})
+	samples := make([]Sample, 0, n)
+	for i := 0; i < n; i++ {
+		arm := spatialmath.Compose(nominal, randomPose(rng, 50, 0.5))
+		var observed spatialmath.Pose
+		if mode == EyeInHand {
+			// arm * camera * observed = target
+			observed = spatialmath.PoseBetween(spatialmath.Compose(arm, camera), target)
+		} else {
+			// camera * observed = arm * target
+			observed = spatialmath.PoseBetween(camera, spatialmath.Compose(arm, target))
+		}
+		if noise > 0 {
+			observed = spatialmath.Compose(observed, randomPose(rng, noise, noise/100))
+		}
+		samples = append(samples, Sample{ArmPose: arm, TargetPose: observed})
+	}
+	return samples
+}
+
+func TestSolveHandEye(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, mode := range []HandEyeMode{EyeInHand, EyeToHand} {
+		t.Run(string(mode), func(t *testing.T) {
+			camera := randomPose(rng, 100, 3)
+			target := randomPose(rng, 500, 3)
+
+			cal, err := SolveHandEye(mode, simulate(rng, mode, camera, target, 10, 0))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, cal.Mode, test.ShouldEqual, mode)
+			test.That(t, spatialmath.PoseAlmostEqualEps(cal.Camera, camera, 1e-6), test.ShouldBeTrue)
+			test.That(t, spatialmath.PoseAlmostEqualEps(cal.Target, target, 1e-6), test.ShouldBeTrue)
+			test.That(t, cal.Residuals.Translation, test.ShouldHaveLength, 10)
+			test.That(t, cal.Residuals.TranslationMax, test.ShouldBeLessThan, 1e-6)
+			test.That(t, cal.Residuals.RotationMax, test.ShouldBeLessThan, 1e-6)
+
+			cal, err = SolveHandEye(mode, simulate(rng, mode, camera, target, 20, 0.5))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, cal.Camera.Point().Sub(camera.Point()).Norm(), test.ShouldBeLessThan, 2)
+			test.That(t, cal.Residuals.TranslationRMS, test.ShouldBeGreaterThan, 0)
+			test.That(t, cal.Residuals.TranslationRMS, test.ShouldBeLessThanOrEqualTo, cal.Residuals.TranslationMax)
+			test.That(t, cal.Residuals.RotationRMS, test.ShouldBeGreaterThan, 0)
+
+			link, err := cal.LinkConfig("cam", "arm")
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, link.ID, test.ShouldEqual, "cam")
+			test.That(t, link.Parent, test.ShouldEqual, "arm")
+			pose, err := link.Pose()
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, spatialmath.PoseAlmostEqual(pose, cal.Camera), test.ShouldBeTrue)
+		})
+	}
+
+	t.Run("bad samples", func(t *testing.T) {
+		samples := simulate(rng, EyeInHand, randomPose(rng, 100, 3), randomPose(rng, 500, 3), 5, 0)
+		_, err := SolveHandEye(EyeInHand, samples[:2])
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = SolveHandEye("eye_on_table", samples)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		// rotating about a single axis leaves the calibration ambiguous
+		for i := range samples {
+			samples[i].ArmPose = spatialmath.NewPose(r3.Vector{X: float64(i)}, &spatialmath.R4AA{Theta: float64(i) / 5, RZ: 1})
+		}
+		_, err = SolveHandEye(EyeInHand, samples)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "two different axes")
+	})
+}
+
+func TestSampleJSON(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	sample := Sample{ArmPose: randomPose(rng, 100, 3), TargetPose: randomPose(rng, 100, 3)}
+	data, err := json.Marshal([]Sample{sample})
+	test.That(t, err, test.ShouldBeNil)
+	var samples []Sample
+	test.That(t, json.Unmarshal(data, &samples), test.ShouldBeNil)
+	test.That(t, samples, test.ShouldHaveLength, 1)
+	test.That(t, spatialmath.PoseAlmostEqual(samples[0].ArmPose, sample.ArmPose), test.ShouldBeTrue)
+	test.That(t, spatialmath.PoseAlmostEqual(samples[0].TargetPose, sample.TargetPose), test.ShouldBeTrue)
+
+	test.That(t, json.Unmarshal([]byte(`[{"arm_pose": {"x": 1}}]`), &samples), test.ShouldNotBeNil)
+}
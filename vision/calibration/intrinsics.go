@@ -0,0 +1,382 @@
+package calibration
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/rimage/transform"
+)
+
+// MinIntrinsicViews is the fewest views of a board that intrinsics can be solved from. The board must be seen at
+// different angles in them.
+const MinIntrinsicViews = 3
+
+// the number of intrinsic parameters solved for: fx, fy, ppx, ppy, and the five Brown-Conrady parameters
+const numIntrinsicParams = 9
+
+// IntrinsicCalibration is the solution to an intrinsic calibration, which marshals to the intrinsic_parameters
+// and distortion_parameters of a camera config.
+type IntrinsicCalibration struct {
+	Intrinsics *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters"`
+	Distortion *transform.BrownConrady            `json:"distortion_parameters"`
+	// ReprojectionError is the root mean square distance between the corners found and where the calibration
+	// projects them, in pixels.
+	ReprojectionError float64 `json:"reprojection_error_px"`
+	// ViewErrors are the reprojection errors of each view.
+	ViewErrors []float64 `json:"view_errors_px"`
+}
+
+// CalibrateIntrinsics solves for the intrinsics and distortion of a camera from views of a board in images of
+// the given size. An initial estimate is found from the homographies of the views, with the method of Zhang, and
+// then refined along with the poses of the board by minimizing the reprojection error.
+func CalibrateIntrinsics(board *Board, views []*View, width, height int) (*IntrinsicCalibration, error) {
+	if err := board.Validate(); err != nil {
+		return nil, err
+	}
+	if len(views) < MinIntrinsicViews {
+		return nil, errors.Errorf("need at least %d views of the board to calibrate, have %d", MinIntrinsicViews, len(views))
+	}
+	homographies := make([]*mat.Dense, 0, len(views))
+	for i, view := range views {
+		if len(view.IDs) < minViewCorners || len(view.IDs) != len(view.Corners) {
+			return nil, errors.Errorf("view %d must have at least %d corners, each with an ID", i, minViewCorners)
+		}
+		objects := make([]r2.Point, 0, len(view.IDs))
+		for _, id := range view.IDs {
+			if id < 0 || id >= board.numCorners() {
+				return nil, errors.Errorf("view %d has corner %d, but the board has %d corners", i, id, board.numCorners())
+			}
+			obj := board.ObjectPoint(id)
+			objects = append(objects, r2.Point{X: obj.X, Y: obj.Y})
+		}
+		h, err := estimateHomography(objects, view.Corners)
+		if err != nil {
+			return nil, errors.Wrapf(err, "view %d", i)
+		}
+		homographies = append(homographies, h)
+	}
+
+	fx, fy, ppx, ppy, ok := zhangIntrinsics(homographies)
+	if !ok {
+		// views that are nearly parallel to each other don't constrain the intrinsics, so start from a guess
+		fx, fy, ppx, ppy = float64(width), float64(width), float64(width)/2, float64(height)/2
+	}
+	params := []float64{fx, fy, ppx, ppy, 0, 0, 0, 0, 0}
+	for _, h := range homographies {
+		rotation, translation := poseFromHomography(h, fx, fy, ppx, ppy)
+		params = append(params, rotation.X, rotation.Y, rotation.Z, translation.X, translation.Y, translation.Z)
+	}
+
+	p := &intrinsicProblem{board: board, views: views}
+	params = levenbergMarquardt(p, params, 200)
+
+	residuals := p.residuals(params)
+	cal := &IntrinsicCalibration{
+		Intrinsics: &transform.PinholeCameraIntrinsics{
+			Width: width, Height: height, Fx: params[0], Fy: params[1], Ppx: params[2], Ppy: params[3],
+		},
+		Distortion: &transform.BrownConrady{
+			RadialK1: params[4], RadialK2: params[5], RadialK3: params[6], TangentialP1: params[7], TangentialP2: params[8],
+		},
+	}
+	if err := cal.Intrinsics.CheckValid(); err != nil {
+		return nil, errors.Wrap(err, "calibration did not converge")
+	}
+	var total float64
+	offset := 0
+	for _, view := range views {
+		var sum float64
+		for range view.IDs {
+			sum += residuals[offset]*residuals[offset] + residuals[offset+1]*residuals[offset+1]
+			offset += 2
+		}
+		total += sum
+		cal.ViewErrors = append(cal.ViewErrors, math.Sqrt(sum/float64(len(view.IDs))))
+	}
+	cal.ReprojectionError = math.Sqrt(total / float64(len(residuals)/2))
+	return cal, nil
+}
+
+// intrinsicProblem is the reprojection error of the corners of the views, as a function of the intrinsic
+// parameters followed by the rotation vector and translation of the board in each view.
+type intrinsicProblem struct {
+	board *Board
+	views []*View
+}
+
+func (p *intrinsicProblem) numResiduals() int {
+	n := 0
+	for _, view := range p.views {
+		n += 2 * len(view.IDs)
+	}
+	return n
+}
+
+// viewResiduals writes the residuals of one view to out.
+func (p *intrinsicProblem) viewResiduals(params []float64, view int, out []float64) {
+	fx, fy, ppx, ppy := params[0], params[1], params[2], params[3]
+	distortion := &transform.BrownConrady{
+		RadialK1: params[4], RadialK2: params[5], RadialK3: params[6], TangentialP1: params[7], TangentialP2: params[8],
+	}
+	pose := params[numIntrinsicParams+6*view : numIntrinsicParams+6*view+6]
+	rotation := r3.Vector{X: pose[0], Y: pose[1], Z: pose[2]}
+	translation := r3.Vector{X: pose[3], Y: pose[4], Z: pose[5]}
+	for i, id := range p.views[view].IDs {
+		pt := rotate(rotation, p.board.ObjectPoint(id)).Add(translation)
+		x, y := distortion.Transform(pt.X/pt.Z, pt.Y/pt.Z)
+		corner := p.views[view].Corners[i]
+		out[2*i] = fx*x + ppx - corner.X
+		out[2*i+1] = fy*y + ppy - corner.Y
+	}
+}
+
+func (p *intrinsicProblem) residuals(params []float64) []float64 {
+	out := make([]float64, p.numResiduals())
+	offset := 0
+	for v, view := range p.views {
+		p.viewResiduals(params, v, out[offset:offset+2*len(view.IDs)])
+		offset += 2 * len(view.IDs)
+	}
+	return out
+}
+
+// jacobian returns the derivatives of the residuals by central differences. The pose of each view only affects
+// the residuals of that view, so only those are recomputed for it.
+func (p *intrinsicProblem) jacobian(params []float64) *mat.Dense {
+	j := mat.NewDense(p.numResiduals(), len(params), nil)
+	perturbed := append([]float64{}, params...)
+	step := func(i int) float64 { return 1e-6 * math.Max(1, math.Abs(params[i])) }
+	for i := 0; i < numIntrinsicParams; i++ {
+		h := step(i)
+		perturbed[i] = params[i] + h
+		plus := p.residuals(perturbed)
+		perturbed[i] = params[i] - h
+		minus := p.residuals(perturbed)
+		perturbed[i] = params[i]
+		for r := range plus {
+			j.Set(r, i, (plus[r]-minus[r])/(2*h))
+		}
+	}
+	offset := 0
+	for v, view := range p.views {
+		n := 2 * len(view.IDs)
+		plus, minus := make([]float64, n), make([]float64, n)
+		for k := 0; k < 6; k++ {
+			i := numIntrinsicParams + 6*v + k
+			h := step(i)
+			perturbed[i] = params[i] + h
+			p.viewResiduals(perturbed, v, plus)
+			perturbed[i] = params[i] - h
+			p.viewResiduals(perturbed, v, minus)
+			perturbed[i] = params[i]
+			for r := 0; r < n; r++ {
+				j.Set(offset+r, i, (plus[r]-minus[r])/(2*h))
+			}
+		}
+		offset += n
+	}
+	return j
+}
+
+// levenbergMarquardt minimizes the sum of squares of the residuals of the problem, starting from params.
+func levenbergMarquardt(p *intrinsicProblem, params []float64, maxIterations int) []float64 {
+	sumSquares := func(r []float64) float64 {
+		s := 0.
+		for _, v := range r {
+			s += v * v
+		}
+		return s
+	}
+	n := len(params)
+	residuals := p.residuals(params)
+	cost := sumSquares(residuals)
+	lambda := 1e-3
+	for iter := 0; iter < maxIterations; iter++ {
+		j := p.jacobian(params)
+		var jtj mat.SymDense
+		jtj.SymOuterK(1, j.T())
+		var jtr mat.VecDense
+		jtr.MulVec(j.T(), mat.NewVecDense(len(residuals), residuals))
+
+		improved := false
+		for attempt := 0; attempt < 10; attempt++ {
+			damped := mat.NewSymDense(n, nil)
+			damped.CopySym(&jtj)
+			for i := 0; i < n; i++ {
+				damped.SetSym(i, i, jtj.At(i, i)*(1+lambda)+1e-12)
+			}
+			var chol mat.Cholesky
+			var delta mat.VecDense
+			if !chol.Factorize(damped) || chol.SolveVecTo(&delta, &jtr) != nil {
+				lambda *= 10
+				continue
+			}
+			next := make([]float64, n)
+			for i := range next {
+				next[i] = params[i] - delta.AtVec(i)
+			}
+			nextResiduals := p.residuals(next)
+			if nextCost := sumSquares(nextResiduals); nextCost < cost {
+				converged := (cost-nextCost)/cost < 1e-12
+				params, residuals, cost = next, nextResiduals, nextCost
+				lambda = math.Max(lambda/10, 1e-12)
+				improved = !converged
+				break
+			}
+			lambda *= 10
+		}
+		if !improved {
+			break
+		}
+	}
+	return params
+}
+
+// zhangIntrinsics solves for the intrinsics with no skew from the homographies of views of a plane.
+func zhangIntrinsics(homographies []*mat.Dense) (fx, fy, ppx, ppy float64, ok bool) {
+	// constraint(i, j) is the row of constraints on B = K^-T K^-1 from h_i^T B h_j, with B as its six unique elements
+	constraint := func(h *mat.Dense, i, j int) []float64 {
+		return []float64{
+			h.At(0, i) * h.At(0, j),
+			h.At(0, i)*h.At(1, j) + h.At(1, i)*h.At(0, j),
+			h.At(1, i) * h.At(1, j),
+			h.At(2, i)*h.At(0, j) + h.At(0, i)*h.At(2, j),
+			h.At(2, i)*h.At(1, j) + h.At(1, i)*h.At(2, j),
+			h.At(2, i) * h.At(2, j),
+		}
+	}
+	var rows []float64
+	for _, h := range homographies {
+		rows = append(rows, constraint(h, 0, 1)...)
+		v00, v11 := constraint(h, 0, 0), constraint(h, 1, 1)
+		for k := range v00 {
+			rows = append(rows, v00[k]-v11[k])
+		}
+	}
+	// no skew
+	rows = append(rows, 0, 1, 0, 0, 0, 0)
+	var svd mat.SVD
+	if !svd.Factorize(mat.NewDense(len(rows)/6, 6, rows), mat.SVDFull) {
+		return 0, 0, 0, 0, false
+	}
+	// b is the right singular vector of the smallest singular value
+	var v mat.Dense
+	svd.VTo(&v)
+	b11, b12, b22, b13, b23, b33 := v.At(0, 5), v.At(1, 5), v.At(2, 5), v.At(3, 5), v.At(4, 5), v.At(5, 5)
+	if b11 < 0 {
+		b11, b12, b22, b13, b23, b33 = -b11, -b12, -b22, -b13, -b23, -b33
+	}
+	ppy = (b12*b13 - b11*b23) / (b11*b22 - b12*b12)
+	lambda := b33 - (b13*b13+ppy*(b12*b13-b11*b23))/b11
+	fx = math.Sqrt(lambda / b11)
+	fy = math.Sqrt(lambda * b11 / (b11*b22 - b12*b12))
+	ppx = -b13 * fx * fx / lambda
+	for _, x := range []float64{fx, fy, ppx, ppy} {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return 0, 0, 0, 0, false
+		}
+	}
+	return fx, fy, ppx, ppy, fx > 0 && fy > 0
+}
+
+// poseFromHomography returns the rotation vector and translation of a plane in front of the camera, from the
+// homography from the plane to the image.
+func poseFromHomography(h *mat.Dense, fx, fy, ppx, ppy float64) (r3.Vector, r3.Vector) {
+	col := func(c int) r3.Vector {
+		return r3.Vector{X: (h.At(0, c) - ppx*h.At(2, c)) / fx, Y: (h.At(1, c) - ppy*h.At(2, c)) / fy, Z: h.At(2, c)}
+	}
+	x, y, t := col(0), col(1), col(2)
+	scale := 2 / (x.Norm() + y.Norm())
+	if t.Z < 0 {
+		scale = -scale
+	}
+	x, y, t = x.Mul(scale), y.Mul(scale), t.Mul(scale)
+	z := x.Cross(y)
+	m := mat.NewDense(3, 3, []float64{x.X, y.X, z.X, x.Y, y.Y, z.Y, x.Z, y.Z, z.Z})
+	rotation, _, err := nearestRotation(m)
+	if err != nil {
+		return r3.Vector{}, t
+	}
+	orientation, err := orientationFromMatrix(rotation)
+	if err != nil {
+		return r3.Vector{}, t
+	}
+	return rotationVector(orientation), t
+}
+
+// rotate rotates a point by a rotation vector, with the formula of Rodrigues.
+func rotate(rotation, pt r3.Vector) r3.Vector {
+	theta := rotation.Norm()
+	if theta < 1e-12 {
+		return pt.Add(rotation.Cross(pt))
+	}
+	k := rotation.Mul(1 / theta)
+	return pt.Mul(math.Cos(theta)).Add(k.Cross(pt).Mul(math.Sin(theta))).Add(k.Mul(k.Dot(pt) * (1 - math.Cos(theta))))
+}
+
+// estimateHomography returns the homography that best maps the source points to the destination points, by the
+// normalized direct linear transform.
+func estimateHomography(src, dst []r2.Point) (*mat.Dense, error) {
+	if len(src) < 4 || len(src) != len(dst) {
+		return nil, errors.New("need at least 4 pairs of points to estimate a homography")
+	}
+	srcNorm, dstNorm := normalization(src), normalization(dst)
+	rows := make([]float64, 0, 18*len(src))
+	for i := range src {
+		s, d := applyHomography(srcNorm, src[i]), applyHomography(dstNorm, dst[i])
+		rows = append(rows,
+			s.X, s.Y, 1, 0, 0, 0, -d.X*s.X, -d.X*s.Y, -d.X,
+			0, 0, 0, s.X, s.Y, 1, -d.Y*s.X, -d.Y*s.Y, -d.Y,
+		)
+	}
+	var svd mat.SVD
+	if !svd.Factorize(mat.NewDense(2*len(src), 9, rows), mat.SVDFull) {
+		return nil, errors.New("could not estimate homography")
+	}
+	values := svd.Values(nil)
+	if values[7] < 1e-9*values[0] {
+		return nil, errors.New("points are degenerate, such as all on a line")
+	}
+	var v mat.Dense
+	svd.VTo(&v)
+	hNorm := mat.NewDense(3, 3, nil)
+	for i := 0; i < 9; i++ {
+		hNorm.Set(i/3, i%3, v.At(i, 8))
+	}
+	// undo the normalization, H = dstNorm^-1 * hNorm * srcNorm
+	var dstInv, h mat.Dense
+	if err := dstInv.Inverse(dstNorm); err != nil {
+		return nil, err
+	}
+	h.Product(&dstInv, hNorm, srcNorm)
+	h.Scale(1/h.At(2, 2), &h)
+	return &h, nil
+}
+
+// normalization returns the similarity that moves the centroid of the points to the origin and their mean
+// distance from it to sqrt(2), as described in Multiple View Geometry by Hartley and Zisserman.
+func normalization(pts []r2.Point) *mat.Dense {
+	var centroid r2.Point
+	for _, p := range pts {
+		centroid = centroid.Add(p)
+	}
+	centroid = centroid.Mul(1 / float64(len(pts)))
+	var dist float64
+	for _, p := range pts {
+		dist += p.Sub(centroid).Norm()
+	}
+	scale := math.Sqrt2 * float64(len(pts)) / dist
+	return mat.NewDense(3, 3, []float64{scale, 0, -scale * centroid.X, 0, scale, -scale * centroid.Y, 0, 0, 1})
+}
+
+func applyHomography(h mat.Matrix, pt r2.Point) r2.Point {
+	w := h.At(2, 0)*pt.X + h.At(2, 1)*pt.Y + h.At(2, 2)
+	return r2.Point{
+		X: (h.At(0, 0)*pt.X + h.At(0, 1)*pt.Y + h.At(0, 2)) / w,
+		Y: (h.At(1, 0)*pt.X + h.At(1, 1)*pt.Y + h.At(1, 2)) / w,
+	}
+}
@@ -0,0 +1,124 @@
+package calibration
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/transform"
+)
+
+var (
+	testIntrinsics = &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 520, Fy: 515, Ppx: 318, Ppy: 243}
+	testDistortion = &transform.BrownConrady{RadialK1: -0.2, RadialK2: 0.05, TangentialP1: 0.001, TangentialP2: -0.0005}
+)
+
+// renderView ray traces the board through the test camera, with the center of the board at center in the frame
+// of the camera and the board turned by the rotation vector.
+func renderView(t *testing.T, b *Board, rotation, center r3.Vector) *image.Gray {
+	t.Helper()
+	const squarePixels = 40
+	texture, err := RenderBoard(b, squarePixels)
+	test.That(t, err, test.ShouldBeNil)
+	translation := center.Sub(rotate(rotation, r3.Vector{X: float64(b.Cols) * b.SquareSize / 2, Y: float64(b.Rows) * b.SquareSize / 2}))
+	origin := rotate(rotation.Mul(-1), translation.Mul(-1))
+	undistort := &transform.InverseBrownConrady{
+		RadialK1: testDistortion.RadialK1, RadialK2: testDistortion.RadialK2, RadialK3: testDistortion.RadialK3,
+		TangentialP1: testDistortion.TangentialP1, TangentialP2: testDistortion.TangentialP2,
+	}
+	sample := func(x, y float64) float64 {
+		xu, yu := undistort.Transform((x-testIntrinsics.Ppx)/testIntrinsics.Fx, (y-testIntrinsics.Ppy)/testIntrinsics.Fy)
+		ray := rotate(rotation.Mul(-1), r3.Vector{X: xu, Y: yu, Z: 1})
+		if ray.Z*origin.Z >= 0 {
+			return 128
+		}
+		hit := origin.Add(ray.Mul(-origin.Z / ray.Z))
+		pt := image.Pt(int(math.Floor((hit.X/b.SquareSize+1)*squarePixels)), int(math.Floor((hit.Y/b.SquareSize+1)*squarePixels)))
+		if !pt.In(texture.Bounds()) {
+			return 128
+		}
+		return float64(texture.GrayAt(pt.X, pt.Y).Y)
+	}
+	img := image.NewGray(image.Rect(0, 0, testIntrinsics.Width, testIntrinsics.Height))
+	for y := 0; y < testIntrinsics.Height; y++ {
+		for x := 0; x < testIntrinsics.Width; x++ {
+			var sum float64
+			for _, d := range [][2]float64{{0.25, 0.25}, {0.75, 0.25}, {0.25, 0.75}, {0.75, 0.75}} {
+				sum += sample(float64(x)+d[0], float64(y)+d[1])
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(sum / 4)})
+		}
+	}
+	return img
+}
+
+func TestCalibrateIntrinsics(t *testing.T) {
+	for _, tc := range []struct {
+		board *Board
+		poses [][2]r3.Vector
+	}{
+		{
+			&Board{Type: Checkerboard, Cols: 9, Rows: 7, SquareSize: 25},
+			[][2]r3.Vector{
+				{{}, {Z: 300}},
+				{{X: 0.4}, {X: 20, Y: -10, Z: 330}},
+				{{Y: -0.45}, {X: -15, Y: 5, Z: 320}},
+				{{X: -0.3, Y: 0.3, Z: 0.2}, {Y: 10, Z: 300}},
+				{{X: 0.25, Y: 0.35, Z: -0.3}, {X: 30, Y: 20, Z: 340}},
+				{{Y: 0.2, Z: 1.6}, {X: -10, Y: 0, Z: 310}},
+			},
+		},
+		{
+			&Board{Type: Charuco, Cols: 7, Rows: 5, SquareSize: 40, MarkerSize: 28},
+			[][2]r3.Vector{
+				{{}, {Z: 350}},
+				{{X: 0.4}, {X: 10, Y: -10, Z: 380}},
+				{{Y: -0.45}, {X: -10, Y: 10, Z: 370}},
+				{{X: -0.3, Y: 0.3, Z: 0.2}, {Y: 10, Z: 340}},
+				// only part of the board is in view
+				{{X: 0.2, Y: 0.25}, {X: 120, Y: 60, Z: 300}},
+			},
+		},
+	} {
+		t.Run(string(tc.board.Type), func(t *testing.T) {
+			var views []*View
+			for _, pose := range tc.poses {
+				view, err := tc.board.FindCorners(renderView(t, tc.board, pose[0], pose[1]))
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, len(view.IDs), test.ShouldBeGreaterThanOrEqualTo, minViewCorners)
+				if tc.board.Type == Checkerboard {
+					test.That(t, view.IDs, test.ShouldHaveLength, tc.board.numCorners())
+				}
+				views = append(views, view)
+			}
+			cal, err := CalibrateIntrinsics(tc.board, views, testIntrinsics.Width, testIntrinsics.Height)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, cal.Intrinsics.Width, test.ShouldEqual, testIntrinsics.Width)
+			test.That(t, cal.Intrinsics.Fx, test.ShouldAlmostEqual, testIntrinsics.Fx, 0.01*testIntrinsics.Fx)
+			test.That(t, cal.Intrinsics.Fy, test.ShouldAlmostEqual, testIntrinsics.Fy, 0.01*testIntrinsics.Fy)
+			test.That(t, cal.Intrinsics.Ppx, test.ShouldAlmostEqual, testIntrinsics.Ppx, 4)
+			test.That(t, cal.Intrinsics.Ppy, test.ShouldAlmostEqual, testIntrinsics.Ppy, 4)
+			test.That(t, cal.Distortion.RadialK1, test.ShouldAlmostEqual, testDistortion.RadialK1, 0.05)
+			test.That(t, cal.ReprojectionError, test.ShouldBeLessThan, 0.2)
+			test.That(t, cal.ViewErrors, test.ShouldHaveLength, len(tc.poses))
+		})
+	}
+
+	b := &Board{Type: Checkerboard, Cols: 9, Rows: 7, SquareSize: 25}
+	view := &View{IDs: []int{0, 1, 2, 8, 9, 10}}
+	for _, id := range view.IDs {
+		obj := b.ObjectPoint(id)
+		view.Corners = append(view.Corners, r2.Point{X: obj.X, Y: obj.Y})
+	}
+	_, err := CalibrateIntrinsics(b, []*View{view, view}, 640, 480)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = CalibrateIntrinsics(b, []*View{view, view, {IDs: []int{0, 1, 2}}}, 640, 480)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = CalibrateIntrinsics(&Board{Type: Checkerboard, Cols: 2, Rows: 7, SquareSize: 25}, []*View{view, view, view}, 640, 480)
+	test.That(t, err, test.ShouldNotBeNil)
+}
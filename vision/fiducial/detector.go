@@ -0,0 +1,306 @@
+package fiducial
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+const (
+	// the smallest area of a quad, in pixels, that is decoded as a marker
+	minQuadArea = 100.
+	// the least difference in intensity between the black border and the white background of a marker
+	minContrast = 20.
+	// how much darker than its neighborhood a pixel must be to be part of a marker border
+	thresholdOffset = 8
+)
+
+// Marker is a fiducial marker found in an image.
+type Marker struct {
+	Family *Family
+	ID     int
+	// Corners are the outer corners of the black border of the marker in image coordinates, clockwise from
+	// the top left corner of the marker.
+	Corners [4]r2.Point
+	// Hamming is the number of data cells that were corrected when decoding the marker.
+	Hamming int
+}
+
+// Name returns the name of the marker, which is the name of its family followed by its ID.
+func (m *Marker) Name() string {
+	return fmt.Sprintf("%s_%d", m.Family.Name, m.ID)
+}
+
+// BoundingBox returns the smallest rectangle of pixels that contains the marker.
+func (m *Marker) BoundingBox() image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range m.Corners {
+		minX, minY = math.Min(minX, c.X), math.Min(minY, c.Y)
+		maxX, maxY = math.Max(maxX, c.X), math.Max(maxY, c.Y)
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+}
+
+// Detect returns the markers of the given families found in the image. Markers are found by looking for
+// dark quadrilaterals on a lighter background, and decoding the grid of cells inside them.
+func Detect(img image.Image, families []*Family) []*Marker {
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Bounds(), img, bounds.Min, draw.Src)
+
+	dark := adaptiveThreshold(gray)
+	clusters, _ := objectdetection.ConnectedComponents(gray, func(_ image.Image, pt image.Point) bool {
+		return dark[pt.Y*gray.Rect.Dx()+pt.X]
+	})
+
+	markers := []*Marker{}
+	for _, cluster := range clusters {
+		if float64(len(cluster)) < minQuadArea/4 {
+			continue
+		}
+		quad, ok := FitQuad(cluster)
+		if !ok {
+			continue
+		}
+		for _, family := range families {
+			if marker, ok := decodeQuad(gray, quad, family); ok {
+				markers = append(markers, marker)
+				break
+			}
+		}
+	}
+	sort.SliceStable(markers, func(i, j int) bool {
+		if markers[i].Family.Name != markers[j].Family.Name {
+			return markers[i].Family.Name < markers[j].Family.Name
+		}
+		return markers[i].ID < markers[j].ID
+	})
+	return markers
+}
+
+// adaptiveThreshold returns whether each pixel is darker than the mean of its neighborhood. The neighborhood
+// is large enough that the whole border of a marker is dark, and the border of a marker is one component.
+func adaptiveThreshold(gray *image.Gray) []bool {
+	w, h := gray.Rect.Dx(), gray.Rect.Dy()
+	radius := int(math.Max(4, float64(min(w, h))/32))
+	// integral[(y+1)*(w+1)+(x+1)] is the sum of the pixels above and to the left of (x, y), inclusive
+	integral := make([]int64, (w+1)*(h+1))
+	for y := 0; y < h; y++ {
+		var row int64
+		for x := 0; x < w; x++ {
+			row += int64(gray.Pix[y*gray.Stride+x])
+			integral[(y+1)*(w+1)+x+1] = integral[y*(w+1)+x+1] + row
+		}
+	}
+	dark := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		y0, y1 := max(0, y-radius), min(h, y+radius+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := max(0, x-radius), min(w, x+radius+1)
+			sum := integral[y1*(w+1)+x1] - integral[y0*(w+1)+x1] - integral[y1*(w+1)+x0] + integral[y0*(w+1)+x0]
+			count := int64((x1 - x0) * (y1 - y0))
+			dark[y*w+x] = (int64(gray.Pix[y*gray.Stride+x])+thresholdOffset)*count < sum
+		}
+	}
+	return dark
+}
+
+// FitQuad fits a quadrilateral to the convex hull of a component of pixels, such as one found by
+// objectdetection.ConnectedComponents, and returns its corners clockwise. It fails if the component is too
+// small, or not close enough to a quadrilateral.
+func FitQuad(cluster []image.Point) ([4]r2.Point, bool) {
+	var quad [4]r2.Point
+	points := make([]r2.Point, 0, len(cluster))
+	for _, pt := range cluster {
+		points = append(points, r2.Point{X: float64(pt.X) + 0.5, Y: float64(pt.Y) + 0.5})
+	}
+	hull := convexHull(points)
+	hullArea := polygonArea(hull)
+	if len(hull) < 4 || hullArea < minQuadArea {
+		return quad, false
+	}
+	var centroid r2.Point
+	for _, pt := range hull {
+		centroid = centroid.Add(pt)
+	}
+	centroid = centroid.Mul(1 / float64(len(hull)))
+
+	// the first corner is the hull point farthest from the center, and the opposite corner is the farthest
+	// from it. The other two corners are the farthest from the diagonal between them, on either side, so
+	// that the corners are clockwise.
+	farthest := func(from r2.Point) r2.Point {
+		best := hull[0]
+		for _, pt := range hull {
+			if pt.Sub(from).Norm() > best.Sub(from).Norm() {
+				best = pt
+			}
+		}
+		return best
+	}
+	quad[0] = farthest(centroid)
+	quad[2] = farthest(quad[0])
+	diagonal := quad[2].Sub(quad[0])
+	left, right := 0., 0.
+	for _, pt := range hull {
+		side := diagonal.Cross(pt.Sub(quad[0]))
+		if side < right {
+			right, quad[1] = side, pt
+		}
+		if side > left {
+			left, quad[3] = side, pt
+		}
+	}
+	if right == 0 || left == 0 || polygonArea(quad[:]) < 0.9*hullArea {
+		return quad, false
+	}
+
+	// the hull passes through the centers of the outermost pixels, which are half a pixel inside the border
+	for i, corner := range quad {
+		outward := corner.Sub(centroid)
+		quad[i] = corner.Add(outward.Mul(math.Sqrt2 / 2 / outward.Norm()))
+	}
+	return quad, true
+}
+
+// decodeQuad reads the grid of cells inside a quad, and decodes it as a marker of the family.
+func decodeQuad(gray *image.Gray, quad [4]r2.Point, family *Family) (*Marker, bool) {
+	cells := float64(family.Size + 2)
+	square := []r2.Point{{X: 0, Y: 0}, {X: cells, Y: 0}, {X: cells, Y: cells}, {X: 0, Y: cells}}
+	h, err := transform.EstimateExactHomographyFrom8Points(square, quad[:], false)
+	if err != nil {
+		return nil, false
+	}
+	sample := func(x, y float64) (float64, bool) {
+		pt := h.Apply(r2.Point{X: x, Y: y})
+		px, py := int(math.Floor(pt.X)), int(math.Floor(pt.Y))
+		if px < 0 || py < 0 || px >= gray.Rect.Dx() || py >= gray.Rect.Dy() {
+			return 0, false
+		}
+		return float64(gray.Pix[py*gray.Stride+px]), true
+	}
+
+	// the border cells must be black, and the background around them white
+	n := family.Size + 2
+	var border, background []float64
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i == 0 || j == 0 || i == n-1 || j == n-1 {
+				if v, ok := sample(float64(j)+0.5, float64(i)+0.5); ok {
+					border = append(border, v)
+				}
+			}
+		}
+	}
+	for i := -1; i <= n; i++ {
+		for _, pt := range [][2]float64{{-0.5, float64(i) + 0.5}, {float64(n) + 0.5, float64(i) + 0.5}} {
+			for _, p := range []r2.Point{{X: pt[0], Y: pt[1]}, {X: pt[1], Y: pt[0]}} {
+				if v, ok := sample(p.X, p.Y); ok {
+					background = append(background, v)
+				}
+			}
+		}
+	}
+	if len(border) < 4*(n-1) || len(background) < 2*(n+2) {
+		return nil, false
+	}
+	black, white := mean(border), mean(background)
+	if white-black < minContrast {
+		return nil, false
+	}
+	threshold := (black + white) / 2
+	borderErrors := 0
+	for _, v := range border {
+		if v > threshold {
+			borderErrors++
+		}
+	}
+	if borderErrors > len(border)/8 {
+		return nil, false
+	}
+
+	var code uint64
+	for i := 1; i <= family.Size; i++ {
+		for j := 1; j <= family.Size; j++ {
+			v, ok := sample(float64(j)+0.5, float64(i)+0.5)
+			if !ok {
+				return nil, false
+			}
+			code <<= 1
+			if v > threshold {
+				code |= 1
+			}
+		}
+	}
+
+	// the grid is read starting from an arbitrary corner, so every rotation of it is tried
+	bestID, bestHamming, bestRotation := -1, family.MaxCorrection+1, 0
+	for rotation := 0; rotation < 4; rotation++ {
+		if id, hamming, ok := family.decode(code); ok && hamming < bestHamming {
+			bestID, bestHamming, bestRotation = id, hamming, rotation
+		}
+		code = family.rotate(code)
+	}
+	if bestID < 0 {
+		return nil, false
+	}
+	// the marker is the read grid rotated clockwise, so its top left corner is counterclockwise from the first
+	marker := &Marker{Family: family, ID: bestID, Hamming: bestHamming}
+	for i := range marker.Corners {
+		marker.Corners[i] = quad[(i-bestRotation+4)%4]
+	}
+	return marker, true
+}
+
+// convexHull returns the convex hull of the points clockwise, in image coordinates, using the monotone chain algorithm.
+func convexHull(points []r2.Point) []r2.Point {
+	sorted := append([]r2.Point{}, points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+	if len(sorted) < 3 {
+		return sorted
+	}
+	hull := make([]r2.Point, 0, 2*len(sorted))
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+		for _, pt := range sorted {
+			for len(hull) >= start+2 && hull[len(hull)-1].Sub(hull[len(hull)-2]).Cross(pt.Sub(hull[len(hull)-2])) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, pt)
+		}
+		hull = hull[:len(hull)-1]
+		for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+			sorted[i], sorted[j] = sorted[j], sorted[i]
+		}
+	}
+	return hull
+}
+
+// polygonArea returns the area of a polygon, which is positive if its vertices are clockwise in image coordinates.
+func polygonArea(polygon []r2.Point) float64 {
+	area := 0.
+	for i, pt := range polygon {
+		area += pt.Cross(polygon[(i+1)%len(polygon)])
+	}
+	return area / 2
+}
+
+func mean(values []float64) float64 {
+	sum := 0.
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
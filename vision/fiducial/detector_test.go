@@ -0,0 +1,155 @@
+package fiducial
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/transform"
+)
+
+// warp draws a rendered marker into a larger image, where the corners of the marker image are mapped to the
+// given points.
+func warp(t *testing.T, marker *image.Gray, bounds image.Rectangle, corners []r2.Point) *image.Gray {
+	t.Helper()
+	size := float64(marker.Rect.Dx())
+	square := []r2.Point{{X: 0, Y: 0}, {X: size, Y: 0}, {X: size, Y: size}, {X: 0, Y: size}}
+	h, err := transform.EstimateExactHomographyFrom8Points(square, corners, false)
+	test.That(t, err, test.ShouldBeNil)
+	inv, err := h.Inverse()
+	test.That(t, err, test.ShouldBeNil)
+
+	img := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pt := inv.Apply(r2.Point{X: float64(x) + 0.5, Y: float64(y) + 0.5})
+			value := color.Gray{Y: 200}
+			if pt.X >= 0 && pt.Y >= 0 && pt.X < size && pt.Y < size {
+				value = marker.GrayAt(int(pt.X), int(pt.Y))
+			}
+			img.SetGray(x, y, value)
+		}
+	}
+	return img
+}
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		family  *Family
+		id      int
+		corners []r2.Point
+	}{
+		{"upright apriltag", AprilTag36h11, 7, []r2.Point{{X: 100, Y: 50}, {X: 200, Y: 50}, {X: 200, Y: 150}, {X: 100, Y: 150}}},
+		{"rotated apriltag", AprilTag36h11, 24, []r2.Point{{X: 250, Y: 40}, {X: 250, Y: 180}, {X: 110, Y: 180}, {X: 110, Y: 40}}},
+		{"tilted apriltag", AprilTag36h11, 0, []r2.Point{{X: 130, Y: 30}, {X: 260, Y: 60}, {X: 240, Y: 200}, {X: 90, Y: 170}}},
+		{"aruco", ArucoOriginal, 1000, []r2.Point{{X: 200, Y: 150}, {X: 80, Y: 160}, {X: 70, Y: 40}, {X: 190, Y: 30}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rendered, err := Render(tc.family, tc.id, 10)
+			test.That(t, err, test.ShouldBeNil)
+			img := warp(t, rendered, image.Rect(0, 0, 320, 240), tc.corners)
+
+			// the black border is inset by one cell of the rendered marker from its corners
+			n := float64(tc.family.Size + 4)
+			inset := func(i int) r2.Point {
+				c, next, prev := tc.corners[i], tc.corners[(i+1)%4], tc.corners[(i+3)%4]
+				return c.Add(next.Sub(c).Mul(1 / n)).Add(prev.Sub(c).Mul(1 / n))
+			}
+
+			markers := Detect(img, []*Family{AprilTag36h11, ArucoOriginal})
+			test.That(t, markers, test.ShouldHaveLength, 1)
+			test.That(t, markers[0].Family, test.ShouldEqual, tc.family)
+			test.That(t, markers[0].ID, test.ShouldEqual, tc.id)
+			test.That(t, markers[0].Hamming, test.ShouldEqual, 0)
+			for i, corner := range markers[0].Corners {
+				// the corners of a projected square are not exactly inset by a cell, but close
+				test.That(t, corner.Sub(inset(i)).Norm(), test.ShouldBeLessThan, 3)
+			}
+		})
+	}
+
+	t.Run("only requested families", func(t *testing.T) {
+		rendered, err := Render(ArucoOriginal, 3, 10)
+		test.That(t, err, test.ShouldBeNil)
+		img := warp(t, rendered, image.Rect(0, 0, 200, 200), []r2.Point{{X: 50, Y: 50}, {X: 140, Y: 50}, {X: 140, Y: 140}, {X: 50, Y: 140}})
+		test.That(t, Detect(img, []*Family{AprilTag36h11}), test.ShouldBeEmpty)
+		markers := Detect(img, []*Family{ArucoOriginal})
+		test.That(t, markers, test.ShouldHaveLength, 1)
+		test.That(t, markers[0].Name(), test.ShouldEqual, "aruco_original_3")
+		test.That(t, markers[0].BoundingBox(), test.ShouldResemble, image.Rect(60, 60, 130, 130))
+	})
+
+	t.Run("corrected cells", func(t *testing.T) {
+		rendered, err := Render(AprilTag36h11, 5, 10)
+		test.That(t, err, test.ShouldBeNil)
+		// flip the cells at the top left and bottom right of the data
+		for _, cell := range []image.Point{{2, 2}, {7, 7}} {
+			for y := cell.Y * 10; y < (cell.Y+1)*10; y++ {
+				for x := cell.X * 10; x < (cell.X+1)*10; x++ {
+					rendered.SetGray(x, y, color.Gray{Y: 255 - rendered.GrayAt(x, y).Y})
+				}
+			}
+		}
+		img := warp(t, rendered, image.Rect(0, 0, 200, 200), []r2.Point{{X: 50, Y: 50}, {X: 150, Y: 50}, {X: 150, Y: 150}, {X: 50, Y: 150}})
+		markers := Detect(img, []*Family{AprilTag36h11})
+		test.That(t, markers, test.ShouldHaveLength, 1)
+		test.That(t, markers[0].ID, test.ShouldEqual, 5)
+		test.That(t, markers[0].Hamming, test.ShouldEqual, 2)
+	})
+
+	t.Run("blank image", func(t *testing.T) {
+		img := image.NewGray(image.Rect(0, 0, 100, 100))
+		test.That(t, Detect(img, []*Family{AprilTag36h11, ArucoOriginal}), test.ShouldBeEmpty)
+	})
+}
+
+func TestFamilies(t *testing.T) {
+	// the codes of a family, in any rotation, must be far enough apart that corrected codes are unambiguous
+	for _, family := range []*Family{AprilTag36h11} {
+		for i, a := range family.Codes {
+			rotated := a
+			for rotation := 0; rotation < 4; rotation++ {
+				for j, b := range family.Codes {
+					if i == j && rotation == 0 {
+						continue
+					}
+					test.That(t, bits.OnesCount64(rotated^b), test.ShouldBeGreaterThanOrEqualTo, 2*family.MaxCorrection+1)
+				}
+				rotated = family.rotate(rotated)
+			}
+		}
+	}
+
+	test.That(t, ArucoOriginal.Codes, test.ShouldHaveLength, 1024)
+	test.That(t, ArucoOriginal.Codes[0], test.ShouldEqual, uint64(0b10000_10000_10000_10000_10000))
+	test.That(t, ArucoOriginal.Codes[1023], test.ShouldEqual, uint64(0b01110_01110_01110_01110_01110))
+	test.That(t, ArucoOriginal.Codes[0b0110], test.ShouldEqual, uint64(0b10000_10000_10000_10111_01001))
+
+	// every tag36h11 code is a multiple of the generator step past the previous one, in the 36 bit code space
+	const step, codeMask = 982451653, 1<<36 - 1
+	for id := 1; id < len(AprilTag36h11.Codes); id++ {
+		prev, code := AprilTag36h11.Codes[id-1], AprilTag36h11.Codes[id]
+		found := false
+		for k := uint64(1); k < 1000 && !found; k++ {
+			found = (prev+k*step)&codeMask == code
+		}
+		test.That(t, found, test.ShouldBeTrue)
+	}
+
+	test.That(t, AprilTag36h11.rotate(AprilTag36h11.rotate(AprilTag36h11.rotate(AprilTag36h11.rotate(0xd5d628584)))),
+		test.ShouldEqual, uint64(0xd5d628584))
+
+	families, err := LookupFamilies(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, families, test.ShouldResemble, []*Family{ArucoOriginal, AprilTag36h11})
+	_, err = LookupFamilies([]string{"tag16h5"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = Render(AprilTag36h11, 31, 10)
+	test.That(t, err, test.ShouldNotBeNil)
+}
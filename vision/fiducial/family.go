@@ -0,0 +1,160 @@
+// Package fiducial detects square fiducial markers, such as AprilTags and ArUco markers, in images and
+// estimates their poses relative to the camera.
+package fiducial
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// A Family is a set of square markers that share a layout. Every marker is a grid of Size x Size data
+// cells surrounded by a one cell wide black border, and is placed on a white background. The data cells are
+// encoded into a code row by row from the top left cell, which is the most significant bit, with white cells
+// as ones.
+type Family struct {
+	Name string
+	Size int
+	// Codes holds the code of every marker, indexed by marker ID.
+	Codes []uint64
+	// MaxCorrection is the number of wrongly read data cells that are corrected when decoding a marker.
+	MaxCorrection int
+}
+
+// AprilTag36h11 is the AprilTag 36h11 family. Only the codes of tags 0 to 30 of the 587 in the family are
+// included, so tags with higher IDs are not detected unless the whole family is loaded with LoadFamily from the
+// tag36h11.c source of the AprilTag library, or from OpenCV's DICT_APRILTAG_36h11 dictionary. As the codes of
+// the family are at least 11 cells apart, those tags are not mistaken for included ones either.
+var AprilTag36h11 = &Family{
+	Name: "tag36h11",
+	Size: 6,
+	Codes: []uint64{
+		0xd5d628584, 0xd97f18b49, 0xdd280910e, 0xe479e9c98, 0xebcbca822, 0xf31dab3ac, 0x056a5d085, 0x10652e1d4,
+		0x22b1dfead, 0x265ad0472, 0x34fe91b86, 0x3ff962cd5, 0x43a25329a, 0x474b4385f, 0x4e9d243e9, 0x5246149ae,
+		0x5997f5538, 0x683bb6c4c, 0x6be4a7211, 0x7e3158eea, 0x81da494af, 0x858339a74, 0x8cd51a5fe, 0x9f21cc2d7,
+		0xa2cabc89c, 0xadc58d9eb, 0xb16e7dfb0, 0xb8c05eb3a, 0xd25ef139d, 0xd607e1962, 0xe4aba3076,
+	},
+	MaxCorrection: 2,
+}
+
+// ArucoOriginal is the original ArUco dictionary of 1024 markers. Every row of a marker encodes two bits of
+// its ID, most significant bits first, as one of four five cell words.
+var ArucoOriginal = &Family{
+	Name:  "aruco_original",
+	Size:  5,
+	Codes: arucoOriginalCodes(),
+}
+
+// Families are the marker families that can be detected, by name.
+var Families = map[string]*Family{
+	AprilTag36h11.Name: AprilTag36h11,
+	ArucoOriginal.Name: ArucoOriginal,
+}
+
+// FamilyNames returns the names of the families that can be detected.
+func FamilyNames() []string {
+	names := make([]string, 0, len(Families))
+	for name := range Families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupFamilies returns the families with the given names, or every family if no names are given.
+func LookupFamilies(names []string) ([]*Family, error) {
+	if len(names) == 0 {
+		names = FamilyNames()
+	}
+	families := make([]*Family, 0, len(names))
+	for _, name := range names {
+		family, ok := Families[name]
+		if !ok {
+			return nil, errors.Errorf("unknown marker family %q, must be one of %v", name, FamilyNames())
+		}
+		families = append(families, family)
+	}
+	return families, nil
+}
+
+// ConfiguredFamilies returns the families with the given names, from the families loaded from the given files
+// with LoadFamily or the built in ones. A loaded family takes the place of a built in family of the same name.
+// If no names are given, every built in and loaded family is returned.
+func ConfiguredFamilies(names, files []string) ([]*Family, error) {
+	loaded := make(map[string]*Family, len(files))
+	loadedNames := make([]string, 0, len(files))
+	for _, file := range files {
+		family, err := LoadFamily(file)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := loaded[family.Name]; ok {
+			return nil, errors.Errorf("marker family %q is loaded from more than one file", family.Name)
+		}
+		loaded[family.Name] = family
+		loadedNames = append(loadedNames, family.Name)
+	}
+	if len(names) == 0 {
+		for _, name := range FamilyNames() {
+			if _, ok := loaded[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		names = append(names, loadedNames...)
+	}
+	families := make([]*Family, 0, len(names))
+	for _, name := range names {
+		if family, ok := loaded[name]; ok {
+			families = append(families, family)
+			continue
+		}
+		family, ok := Families[name]
+		if !ok {
+			return nil, errors.Errorf("unknown marker family %q, must be one of %v or loaded from a file",
+				name, FamilyNames())
+		}
+		families = append(families, family)
+	}
+	return families, nil
+}
+
+func arucoOriginalCodes() []uint64 {
+	words := []uint64{0b10000, 0b10111, 0b01001, 0b01110}
+	codes := make([]uint64, 1024)
+	for id := range codes {
+		for row := 0; row < 5; row++ {
+			codes[id] = codes[id]<<5 | words[(id>>(2*(4-row)))&3]
+		}
+	}
+	return codes
+}
+
+// decode returns the ID of the marker with the code closest to the given one, and the number of cells that
+// differ, if it is within the correction of the family.
+func (f *Family) decode(code uint64) (int, int, bool) {
+	bestID, bestDistance := -1, f.MaxCorrection+1
+	for id, c := range f.Codes {
+		if d := bits.OnesCount64(c ^ code); d < bestDistance {
+			bestID, bestDistance = id, d
+		}
+	}
+	return bestID, bestDistance, bestID >= 0
+}
+
+// rotate returns the code of a grid of cells rotated by 90 degrees clockwise.
+func (f *Family) rotate(code uint64) uint64 {
+	n := f.Size
+	var rotated uint64
+	for row := 0; row < n; row++ {
+		for col := 0; col < n; col++ {
+			// the cell at (row, col) of the rotated grid was at (n-1-col, row)
+			rotated = rotated<<1 | f.bit(code, n-1-col, row)
+		}
+	}
+	return rotated
+}
+
+func (f *Family) bit(code uint64, row, col int) uint64 {
+	return (code >> (f.Size*f.Size - 1 - (row*f.Size + col))) & 1
+}
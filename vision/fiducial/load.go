@@ -0,0 +1,193 @@
+package fiducial
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxAprilTagCorrection is the most cells corrected when decoding a marker of a loaded AprilTag family, as in the
+// AprilTag library.
+const maxAprilTagCorrection = 2
+
+var (
+	aprilTagCodePattern  = regexp.MustCompile(`0x([0-9a-fA-F]+)UL`)
+	aprilTagFieldPattern = regexp.MustCompile(`tf->(\w+)\s*=\s*(?:strdup\(")?(\w+)`)
+	aprilTagBitPattern   = regexp.MustCompile(`tf->bit_([xy])\[(\d+)\]\s*=\s*(\d+)`)
+)
+
+// LoadFamily reads a marker family from a file, so that families, or the markers of a family, which are not
+// built in can be detected. Two formats are read:
+//   - the C source of a family from the AprilTag library, such as tag36h11.c. Families of markers whose data
+//     cells are not a square inside the black border, such as tagCircle21h7, are not supported.
+//   - a dictionary written by OpenCV's aruco Dictionary.writeDictionary, as YAML or JSON, such as DICT_4X4_50
+//     or DICT_APRILTAG_36h11. The family is named after the file, without its extension.
+func LoadFamily(path string) (*Family, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read marker family %s", path)
+	}
+	var family *Family
+	if strings.EqualFold(filepath.Ext(path), ".c") {
+		family, err = parseAprilTagSource(data)
+	} else {
+		family, err = parseOpenCVDictionary(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), data)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse marker family %s", path)
+	}
+	return family, nil
+}
+
+// parseAprilTagSource parses the C source of an AprilTag family. Older versions of the library list the codes
+// with the data cells row by row, and newer ones give the cell of each bit of the codes in bit_x and bit_y.
+func parseAprilTagSource(data []byte) (*Family, error) {
+	fields := map[string]string{}
+	for _, match := range aprilTagFieldPattern.FindAllSubmatch(data, -1) {
+		fields[string(match[1])] = string(match[2])
+	}
+	if fields["name"] == "" {
+		return nil, errors.New("no family name")
+	}
+	if fields["reversed_border"] == "true" {
+		return nil, errors.Errorf("family %s has a reversed border, which is not supported", fields["name"])
+	}
+	field := func(name string) (int, bool) {
+		value, err := strconv.Atoi(fields[name])
+		return value, err == nil
+	}
+	nbits, ok := field("nbits")
+	if !ok {
+		d, ok := field("d")
+		if !ok {
+			return nil, errors.New("no number of bits")
+		}
+		nbits = d * d
+	}
+	size := int(math.Round(math.Sqrt(float64(nbits))))
+	if size*size != nbits || nbits > 64 {
+		return nil, errors.Errorf("family %s has %d bits, which are not a square of data cells", fields["name"], nbits)
+	}
+
+	// cells is the position in the row by row layout of each bit of the codes, from the most significant
+	cells := make([]int, nbits)
+	for i := range cells {
+		cells[i] = i
+	}
+	if bitMatches := aprilTagBitPattern.FindAllSubmatch(data, -1); len(bitMatches) > 0 {
+		x, y := make([]int, nbits), make([]int, nbits)
+		seen := map[string]bool{}
+		for _, match := range bitMatches {
+			i, err := strconv.Atoi(string(match[2]))
+			if err != nil || i >= nbits {
+				return nil, errors.Errorf("bit %s of family %s is out of range", match[2], fields["name"])
+			}
+			value, err := strconv.Atoi(string(match[3]))
+			if err != nil {
+				return nil, err
+			}
+			if string(match[1]) == "x" {
+				x[i] = value
+			} else {
+				y[i] = value
+			}
+			seen[string(match[1])+string(match[2])] = true
+		}
+		if len(seen) != 2*nbits {
+			return nil, errors.Errorf("family %s does not give the cell of every bit", fields["name"])
+		}
+		minX, minY := x[0], y[0]
+		for i := range x {
+			minX, minY = min(minX, x[i]), min(minY, y[i])
+		}
+		used := make([]bool, nbits)
+		for i := range cells {
+			col, row := x[i]-minX, y[i]-minY
+			if col >= size || row >= size || used[row*size+col] {
+				return nil, errors.Errorf("the data cells of family %s are not a square", fields["name"])
+			}
+			cells[i] = row*size + col
+			used[cells[i]] = true
+		}
+	}
+
+	family := &Family{Name: fields["name"], Size: size}
+	if h, ok := field("h"); ok {
+		family.MaxCorrection = min(maxAprilTagCorrection, (h-1)/2)
+	}
+	for _, match := range aprilTagCodePattern.FindAllSubmatch(data, -1) {
+		code, err := strconv.ParseUint(string(match[1]), 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		var laidOut uint64
+		for i, cell := range cells {
+			laidOut |= (code >> (nbits - 1 - i) & 1) << (nbits - 1 - cell)
+		}
+		family.Codes = append(family.Codes, laidOut)
+	}
+	if len(family.Codes) == 0 {
+		return nil, errors.Errorf("family %s has no codes", family.Name)
+	}
+	if ncodes, ok := field("ncodes"); ok && ncodes != len(family.Codes) {
+		return nil, errors.Errorf("family %s should have %d codes, found %d", family.Name, ncodes, len(family.Codes))
+	}
+	return family, nil
+}
+
+// parseOpenCVDictionary parses a dictionary written by OpenCV, which gives the size of the markers, the number
+// of cells corrected and the data cells of each marker row by row, with white cells as ones.
+func parseOpenCVDictionary(name string, data []byte) (*Family, error) {
+	values := map[string]string{}
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		var raw map[string]interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		for key, value := range raw {
+			values[key] = fmt.Sprint(value)
+		}
+	} else {
+		for _, line := range strings.Split(trimmed, "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok || strings.HasPrefix(line, "%") {
+				continue
+			}
+			values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+
+	nmarkers, err := strconv.Atoi(values["nmarkers"])
+	if err != nil || nmarkers < 1 {
+		return nil, errors.New("no number of markers")
+	}
+	size, err := strconv.Atoi(values["markersize"])
+	if err != nil || size < 1 || size*size > 64 {
+		return nil, errors.Errorf("unsupported marker size %q", values["markersize"])
+	}
+	maxCorrection, err := strconv.Atoi(values["maxCorrectionBits"])
+	if err != nil {
+		return nil, errors.New("no number of corrected bits")
+	}
+	family := &Family{Name: name, Size: size, Codes: make([]uint64, nmarkers), MaxCorrection: maxCorrection}
+	for id := range family.Codes {
+		cells := values[fmt.Sprintf("marker_%d", id)]
+		if len(cells) != size*size {
+			return nil, errors.Errorf("marker %d should have %d cells, found %d", id, size*size, len(cells))
+		}
+		code, err := strconv.ParseUint(cells, 2, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marker %d", id)
+		}
+		family.Codes[id] = code
+	}
+	return family, nil
+}
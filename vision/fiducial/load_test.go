@@ -0,0 +1,129 @@
+package fiducial
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"go.viam.com/test"
+)
+
+func writeFamilyFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	test.That(t, os.WriteFile(path, []byte(contents), 0o600), test.ShouldBeNil)
+	return path
+}
+
+func TestLoadAprilTagFamily(t *testing.T) {
+	t.Run("row by row codes", func(t *testing.T) {
+		var source strings.Builder
+		source.WriteString("apriltag_family_t *tag36h11_create()\n{\n")
+		source.WriteString("   tf->name = strdup(\"tag36h11\");\n   tf->black_border = 1;\n   tf->d = 6;\n   tf->h = 11;\n")
+		fmt.Fprintf(&source, "   tf->ncodes = %d;\n", len(AprilTag36h11.Codes))
+		for id, code := range AprilTag36h11.Codes {
+			fmt.Fprintf(&source, "   tf->codes[%d] = 0x%016xUL;\n", id, code)
+		}
+		source.WriteString("   return tf;\n}\n")
+
+		family, err := LoadFamily(writeFamilyFile(t, "tag36h11.c", source.String()))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, family, test.ShouldResemble, AprilTag36h11)
+	})
+
+	t.Run("codes with bit positions", func(t *testing.T) {
+		// lay the bits out column by column inside a border at 1, as newer versions of the library may
+		var source strings.Builder
+		fmt.Fprintf(&source, "static uint64_t codedata[%d] = {\n", len(AprilTag36h11.Codes))
+		for _, code := range AprilTag36h11.Codes {
+			var transposed uint64
+			for i := 0; i < 36; i++ {
+				transposed = transposed<<1 | AprilTag36h11.bit(code, i%6, i/6)
+			}
+			fmt.Fprintf(&source, "   0x%016xUL,\n", transposed)
+		}
+		source.WriteString("};\n   tf->name = strdup(\"tag36h11\");\n   tf->h = 11;\n   tf->nbits = 36;\n")
+		for i := 0; i < 36; i++ {
+			fmt.Fprintf(&source, "   tf->bit_x[%d] = %d;\n   tf->bit_y[%d] = %d;\n", i, i/6+1, i, i%6+1)
+		}
+		source.WriteString("   tf->reversed_border = false;\n")
+
+		family, err := LoadFamily(writeFamilyFile(t, "tag36h11.c", source.String()))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, family, test.ShouldResemble, AprilTag36h11)
+
+		_, err = LoadFamily(writeFamilyFile(t, "tag36h11.c", strings.Replace(source.String(), "bit_x[35] = 6", "bit_x[35] = 7", 1)))
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = LoadFamily(writeFamilyFile(t, "tag36h11.c", strings.Replace(source.String(), "false", "true", 1)))
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	_, err := LoadFamily(filepath.Join(t.TempDir(), "missing.c"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestLoadOpenCVDictionary(t *testing.T) {
+	codes := []string{"1011010100110010", "0000111101010101", "1100011101100110"}
+	yaml := "%YAML:1.0\n---\nnmarkers: 3\nmarkersize: 4\nmaxCorrectionBits: 1\n"
+	json := `{"nmarkers": 3, "markersize": 4, "maxCorrectionBits": 1`
+	for id, code := range codes {
+		yaml += fmt.Sprintf("marker_%d: \"%s\"\n", id, code)
+		json += fmt.Sprintf(`, "marker_%d": "%s"`, id, code)
+	}
+	json += "}"
+
+	for _, path := range []string{
+		writeFamilyFile(t, "DICT_TEST.yml", yaml),
+		writeFamilyFile(t, "DICT_TEST.json", json),
+	} {
+		family, err := LoadFamily(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, family.Name, test.ShouldEqual, "DICT_TEST")
+		test.That(t, family.Size, test.ShouldEqual, 4)
+		test.That(t, family.MaxCorrection, test.ShouldEqual, 1)
+		test.That(t, family.Codes, test.ShouldResemble, []uint64{0b1011010100110010, 0b0000111101010101, 0b1100011101100110})
+
+		rendered, err := Render(family, 2, 10)
+		test.That(t, err, test.ShouldBeNil)
+		img := warp(t, rendered, image.Rect(0, 0, 200, 200), []r2.Point{{X: 140, Y: 50}, {X: 140, Y: 140}, {X: 50, Y: 140}, {X: 50, Y: 50}})
+		markers := Detect(img, []*Family{family})
+		test.That(t, markers, test.ShouldHaveLength, 1)
+		test.That(t, markers[0].Name(), test.ShouldEqual, "DICT_TEST_2")
+	}
+
+	_, err := LoadFamily(writeFamilyFile(t, "DICT_TEST.yml", strings.Replace(yaml, "nmarkers: 3", "nmarkers: 4", 1)))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestConfiguredFamilies(t *testing.T) {
+	families, err := ConfiguredFamilies(nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, families, test.ShouldResemble, []*Family{ArucoOriginal, AprilTag36h11})
+
+	yaml := "nmarkers: 1\nmarkersize: 4\nmaxCorrectionBits: 0\nmarker_0: \"1011010100110010\"\n"
+	dictionary := writeFamilyFile(t, "DICT_TEST.yml", yaml)
+	// a loaded family takes the place of the built in family of its name
+	replacement := writeFamilyFile(t, "aruco_original.yml", yaml)
+
+	families, err = ConfiguredFamilies(nil, []string{dictionary, replacement})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, families, test.ShouldHaveLength, 3)
+	test.That(t, families[0], test.ShouldEqual, AprilTag36h11)
+	test.That(t, families[1].Name, test.ShouldEqual, "DICT_TEST")
+	test.That(t, families[2].Name, test.ShouldEqual, "aruco_original")
+	test.That(t, families[2].Codes, test.ShouldHaveLength, 1)
+
+	families, err = ConfiguredFamilies([]string{"DICT_TEST", "tag36h11"}, []string{dictionary})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, families, test.ShouldHaveLength, 2)
+	test.That(t, families[1], test.ShouldEqual, AprilTag36h11)
+
+	_, err = ConfiguredFamilies([]string{"DICT_4X4_50"}, []string{dictionary})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = ConfiguredFamilies(nil, []string{dictionary, dictionary})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,71 @@
+package fiducial
+
+import (
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// EstimatePose returns the pose of a marker in the frame of the camera that took the image, with z pointing
+// out of the lens, x to the right and y down the image. The marker frame is at the center of the marker, with
+// x to the right and y down the marker as it is printed, and z into the marker, so a marker facing the camera
+// has no rotation. size is the length of the sides of the black border of the marker in mm. The corners of
+// the marker must be in an undistorted image.
+func EstimatePose(marker *Marker, size float64, intrinsics *transform.PinholeCameraIntrinsics) (spatialmath.Pose, error) {
+	if intrinsics == nil {
+		return nil, transform.NewNoIntrinsicsError("estimating the pose of a marker requires camera intrinsics")
+	}
+	if err := intrinsics.CheckValid(); err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		return nil, errors.New("marker size must be positive")
+	}
+	half := size / 2
+	object := []r2.Point{{X: -half, Y: -half}, {X: half, Y: -half}, {X: half, Y: half}, {X: -half, Y: half}}
+	normalized := make([]r2.Point, 0, 4)
+	for _, c := range marker.Corners {
+		normalized = append(normalized, r2.Point{
+			X: (c.X - intrinsics.Ppx) / intrinsics.Fx,
+			Y: (c.Y - intrinsics.Ppy) / intrinsics.Fy,
+		})
+	}
+	h, err := transform.EstimateExactHomographyFrom8Points(object, normalized, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not estimate the homography of marker %s", marker.Name())
+	}
+
+	// the homography from the marker plane to normalized image coordinates is [r1 r2 t] up to scale
+	col := func(c int) r3.Vector { return r3.Vector{X: h.At(0, c), Y: h.At(1, c), Z: h.At(2, c)} }
+	r1, r2, t := col(0), col(1), col(2)
+	scale := 2 / (r1.Norm() + r2.Norm())
+	if t.Z < 0 {
+		scale = -scale
+	}
+	r1, r2, t = r1.Mul(scale), r2.Mul(scale), t.Mul(scale)
+	r3 := r1.Cross(r2)
+
+	// the estimated rotation is not quite orthonormal, so use the closest rotation matrix to it
+	var svd mat.SVD
+	if !svd.Factorize(mat.NewDense(3, 3, []float64{
+		r1.X, r2.X, r3.X,
+		r1.Y, r2.Y, r3.Y,
+		r1.Z, r2.Z, r3.Z,
+	}), mat.SVDFull) {
+		return nil, errors.Errorf("could not estimate the rotation of marker %s", marker.Name())
+	}
+	var u, v, rotation mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	// the closest rotation is U*V^T, but a RotationMatrix holds the transpose of the rotation it applies to points
+	rotation.Mul(&v, u.T())
+	rm, err := spatialmath.NewRotationMatrix(rotation.RawMatrix().Data)
+	if err != nil {
+		return nil, err
+	}
+	return spatialmath.NewPose(t, rm), nil
+}
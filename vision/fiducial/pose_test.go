@@ -0,0 +1,61 @@
+package fiducial
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func TestEstimatePose(t *testing.T) {
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 600, Fy: 600, Ppx: 320, Ppy: 240}
+	const size = 100.
+	rendered, err := Render(AprilTag36h11, 11, 20)
+	test.That(t, err, test.ShouldBeNil)
+	// the black border of the rendered marker is inset by one cell
+	cells := float64(AprilTag36h11.Size + 4)
+	cellSize := size / (cells - 2)
+
+	for _, expected := range []spatialmath.Pose{
+		spatialmath.NewPoseFromPoint(r3.Vector{X: 20, Y: -10, Z: 500}),
+		spatialmath.NewPose(r3.Vector{X: -60, Y: 40, Z: 700}, &spatialmath.EulerAngles{Roll: 0.3, Pitch: -0.4, Yaw: 2}),
+	} {
+		// ray trace the marker, which is a plane at z = 0 in its own frame
+		img := image.NewGray(image.Rect(0, 0, intrinsics.Width, intrinsics.Height))
+		inv := spatialmath.PoseInverse(expected)
+		for y := 0; y < intrinsics.Height; y++ {
+			for x := 0; x < intrinsics.Width; x++ {
+				dir := r3.Vector{X: (float64(x) + 0.5 - intrinsics.Ppx) / intrinsics.Fx, Y: (float64(y) + 0.5 - intrinsics.Ppy) / intrinsics.Fy, Z: 1}
+				origin := inv.Point()
+				ray := spatialmath.Compose(inv, spatialmath.NewPoseFromPoint(dir)).Point().Sub(origin)
+				value := color.Gray{Y: 200}
+				if ray.Z != 0 {
+					hit := origin.Add(ray.Mul(-origin.Z / ray.Z))
+					col, row := int((hit.X+size/2)/cellSize+1), int((hit.Y+size/2)/cellSize+1)
+					if hit.X > -size/2-cellSize && hit.Y > -size/2-cellSize && col < int(cells) && row < int(cells) {
+						value = rendered.GrayAt(col*20+10, row*20+10)
+					}
+				}
+				img.SetGray(x, y, value)
+			}
+		}
+
+		markers := Detect(img, []*Family{AprilTag36h11})
+		test.That(t, markers, test.ShouldHaveLength, 1)
+		pose, err := EstimatePose(markers[0], size, intrinsics)
+		test.That(t, err, test.ShouldBeNil)
+		// the corners are found to within about a pixel, which is a few mm away from the camera
+		test.That(t, pose.Point().Sub(expected.Point()).Norm(), test.ShouldBeLessThan, 0.01*expected.Point().Z)
+		test.That(t, spatialmath.OrientationAlmostEqualEps(pose.Orientation(), expected.Orientation(), 0.05), test.ShouldBeTrue)
+	}
+
+	_, err = EstimatePose(&Marker{Family: AprilTag36h11}, size, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = EstimatePose(&Marker{Family: AprilTag36h11}, 0, intrinsics)
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,39 @@
+package fiducial
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/pkg/errors"
+)
+
+// Render draws the marker with the given ID, with cells of cellSize pixels and a one cell wide white margin
+// around its black border, such that it can be printed.
+func Render(family *Family, id, cellSize int) (*image.Gray, error) {
+	if id < 0 || id >= len(family.Codes) {
+		return nil, errors.Errorf("family %s has no marker with ID %d", family.Name, id)
+	}
+	if cellSize < 1 {
+		return nil, errors.New("cell size must be at least one pixel")
+	}
+	n := family.Size + 4
+	img := image.NewGray(image.Rect(0, 0, n*cellSize, n*cellSize))
+	for row := 0; row < n; row++ {
+		for col := 0; col < n; col++ {
+			value := color.Gray{Y: 255}
+			switch {
+			case row == 0 || col == 0 || row == n-1 || col == n-1:
+			case row == 1 || col == 1 || row == n-2 || col == n-2:
+				value.Y = 0
+			case family.bit(family.Codes[id], row-2, col-2) == 0:
+				value.Y = 0
+			}
+			for y := row * cellSize; y < (row+1)*cellSize; y++ {
+				for x := col * cellSize; x < (col+1)*cellSize; x++ {
+					img.SetGray(x, y, value)
+				}
+			}
+		}
+	}
+	return img, nil
+}
@@ -5,6 +5,7 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"math"
 
 	"github.com/fogleman/gg"
 	"github.com/pkg/errors"
@@ -12,8 +13,29 @@ import (
 	"go.viam.com/rdk/rimage"
 )
 
-// Overlay returns a color image with the bounding boxes overlaid on the original image.
+// DefaultMaskOpacity is the opacity of the color that Overlay fills the masks of detections with.
+const DefaultMaskOpacity = 0.4
+
+// maskColors are the colors the masks of detections are filled with, in turn, so that neighboring objects can
+// be told apart.
+var maskColors = []color.NRGBA{
+	{255, 0, 0, 255},
+	{0, 200, 0, 255},
+	{0, 100, 255, 255},
+	{255, 200, 0, 255},
+	{200, 0, 255, 255},
+	{0, 220, 220, 255},
+}
+
+// Overlay returns a color image with the bounding boxes overlaid on the original image, and the masks of
+// detections that have them filled with a translucent color.
 func Overlay(img image.Image, dets []Detection) (image.Image, error) {
+	return OverlayWithMaskOpacity(img, dets, DefaultMaskOpacity)
+}
+
+// OverlayWithMaskOpacity is Overlay, with the masks of detections filled with the given opacity, from 0 to
+// not fill them, to 1 to hide the image under them.
+func OverlayWithMaskOpacity(img image.Image, dets []Detection, opacity float64) (image.Image, error) {
 	bounds := img.Bounds()
 	boxOverlay := gg.NewContext(bounds.Dx(), bounds.Dy())
 	for _, det := range dets {
@@ -25,10 +47,39 @@ func Overlay(img image.Image, dets []Detection) (image.Image, error) {
 	overlayImg := boxOverlay.Image()
 	resultImg := image.NewNRGBA(bounds) // to keep the original image intact
 	draw.Draw(resultImg, bounds, img, image.Point{}, draw.Src)
+	if opacity > 0 {
+		maskIdx := 0
+		for _, det := range dets {
+			if md, ok := det.(MaskedDetection); ok && md.Mask() != nil {
+				fillMask(resultImg, md.Mask(), maskColors[maskIdx%len(maskColors)], math.Min(opacity, 1))
+				maskIdx++
+			}
+		}
+	}
 	draw.DrawMask(resultImg, bounds, overlayImg, image.Point{}, overlayImg, image.Point{}, draw.Over)
 	return resultImg, nil
 }
 
+// fillMask blends the color into the pixels of the image within the mask.
+func fillMask(img *image.NRGBA, mask *image.Alpha, c color.NRGBA, opacity float64) {
+	region := mask.Rect.Intersect(img.Rect)
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		for x := region.Min.X; x < region.Max.X; x++ {
+			a := opacity * float64(mask.AlphaAt(x, y).A) / 255
+			if a == 0 {
+				continue
+			}
+			orig := img.NRGBAAt(x, y)
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(float64(orig.R)*(1-a) + float64(c.R)*a),
+				G: uint8(float64(orig.G)*(1-a) + float64(c.G)*a),
+				B: uint8(float64(orig.B)*(1-a) + float64(c.B)*a),
+				A: orig.A,
+			})
+		}
+	}
+}
+
 // drawDetection overlays text of the image label and score in the upper left hand of the bounding box.
 func drawDetection(img *gg.Context, d Detection) {
 	red := &color.NRGBA{255, 0, 0, 255}
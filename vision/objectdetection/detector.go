@@ -51,6 +51,32 @@ func NewDetection(imageBounds, boundingBox image.Rectangle, score float64, label
 	return &detection2D{boundingBox, normBbox, score, label}
 }
 
+// MaskedDetection is a detection that also has a mask of the pixels of the object, as found by instance
+// segmentation.
+type MaskedDetection interface {
+	Detection
+	// Mask returns the mask of the object, in the coordinates of the image. The pixels of the object are those
+	// with a nonzero alpha, which are within the bounds of the mask.
+	Mask() *image.Alpha
+}
+
+// NewMaskedDetection creates a 2D detection with a mask of the pixels of the object.
+func NewMaskedDetection(imageBounds, boundingBox image.Rectangle, mask *image.Alpha, score float64, label string) MaskedDetection {
+	normBbox := NewNormalizedBoundingBox(imageBounds, boundingBox)
+	score = vision.NormalizeScore(score)
+	return &maskedDetection2D{detection2D{boundingBox, normBbox, score, label}, mask}
+}
+
+// InDetection returns whether a pixel is part of the detected object, which is within its mask if it has one,
+// or otherwise within its bounding box.
+func InDetection(d Detection, pt image.Point) bool {
+	if md, ok := d.(MaskedDetection); ok && md.Mask() != nil {
+		mask := md.Mask()
+		return pt.In(mask.Rect) && mask.AlphaAt(pt.X, pt.Y).A != 0
+	}
+	return pt.In(*d.BoundingBox())
+}
+
 // NewDetectionWithoutImgBounds creates a simple 2D detection.
 func NewDetectionWithoutImgBounds(boundingBox image.Rectangle, score float64, label string) Detection {
 	score = vision.NormalizeScore(score)
@@ -103,3 +129,14 @@ func (d *detection2D) Label() string {
 func (d *detection2D) String() string {
 	return fmt.Sprintf("Label: %s, Score: %.2f, Box: %v", d.label, d.score, d.boundingBox)
 }
+
+// maskedDetection2D is a 2D detection with a mask of the pixels of the object.
+type maskedDetection2D struct {
+	detection2D
+	mask *image.Alpha
+}
+
+// Mask returns the mask of the pixels of the detected object.
+func (d *maskedDetection2D) Mask() *image.Alpha {
+	return d.mask
+}
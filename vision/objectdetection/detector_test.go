@@ -2,6 +2,7 @@ package objectdetection
 
 import (
 	"image"
+	"image/color"
 	"testing"
 
 	"go.viam.com/test"
@@ -23,3 +24,45 @@ func TestNewDetector(t *testing.T) {
 	test.That(t, det2.Score(), test.ShouldEqual, 0.6)
 	test.That(t, *det2.BoundingBox(), test.ShouldResemble, image.Rect(0, 0, 30, 30))
 }
+
+func TestMaskedDetection(t *testing.T) {
+	// a triangle in the lower left of its bounding box
+	box := image.Rect(10, 20, 30, 40)
+	mask := image.NewAlpha(box)
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		for x := box.Min.X; x <= box.Min.X+y-box.Min.Y; x++ {
+			mask.SetAlpha(x, y, color.Alpha{A: 255})
+		}
+	}
+	det := NewMaskedDetection(image.Rect(0, 0, 100, 100), box, mask, 0.8, "A")
+	test.That(t, det.Label(), test.ShouldEqual, "A")
+	test.That(t, *det.BoundingBox(), test.ShouldResemble, box)
+	test.That(t, det.NormalizedBoundingBox(), test.ShouldResemble, []float64{0.1, 0.2, 0.3, 0.4})
+	test.That(t, det.Mask(), test.ShouldEqual, mask)
+
+	test.That(t, InDetection(det, image.Pt(11, 35)), test.ShouldBeTrue)
+	test.That(t, InDetection(det, image.Pt(28, 22)), test.ShouldBeFalse)
+	test.That(t, InDetection(det, image.Pt(50, 50)), test.ShouldBeFalse)
+	plain := NewDetection(image.Rect(0, 0, 100, 100), box, 0.8, "A")
+	test.That(t, InDetection(plain, image.Pt(28, 22)), test.ShouldBeTrue)
+
+	// tracking keeps the mask
+	tracked := &TrackedDetection{Detection: det, TrackID: 1}
+	test.That(t, InDetection(tracked, image.Pt(28, 22)), test.ShouldBeFalse)
+	test.That(t, (&TrackedDetection{Detection: plain}).Mask(), test.ShouldBeNil)
+
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	overlaid, err := Overlay(img, []Detection{det})
+	test.That(t, err, test.ShouldBeNil)
+	r, g, b, _ := overlaid.At(12, 35).RGBA()
+	test.That(t, r, test.ShouldBeGreaterThan, 0)
+	test.That(t, g, test.ShouldEqual, 0)
+	test.That(t, b, test.ShouldEqual, 0)
+	// outside the mask, but inside the bounding box
+	r, _, _, _ = overlaid.At(26, 24).RGBA()
+	test.That(t, r, test.ShouldEqual, 0)
+	overlaid, err = OverlayWithMaskOpacity(img, []Detection{det}, 0)
+	test.That(t, err, test.ShouldBeNil)
+	r, _, _, _ = overlaid.At(12, 35).RGBA()
+	test.That(t, r, test.ShouldEqual, 0)
+}
@@ -0,0 +1,335 @@
+package objectdetection
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/golang/geo/r2"
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	// DefaultTrackerIoUThreshold is the minimum intersection over union for a detection to be matched to a track.
+	DefaultTrackerIoUThreshold = 0.3
+	// DefaultTrackerMaxAge is the number of consecutive frames a track may go unmatched before it is removed.
+	DefaultTrackerMaxAge = 1
+	// DefaultTrackerMinHits is the number of consecutive frames a track must be matched before it is reported.
+	DefaultTrackerMinHits = 3
+)
+
+// TrackerConfig specifies how detections are associated with tracks and when tracks are reported and removed.
+type TrackerConfig struct {
+	IoUThreshold float64
+	MaxAge       int
+	MinHits      int
+}
+
+// DefaultTrackerConfig returns the tracker configuration used by SORT.
+func DefaultTrackerConfig() TrackerConfig {
+	return TrackerConfig{
+		IoUThreshold: DefaultTrackerIoUThreshold,
+		MaxAge:       DefaultTrackerMaxAge,
+		MinHits:      DefaultTrackerMinHits,
+	}
+}
+
+// TrackedDetection is a detection that has been associated with a track across frames.
+type TrackedDetection struct {
+	Detection
+	// TrackID is stable for as long as the object is tracked, and is never reused by the same tracker.
+	TrackID int
+	// Velocity is the estimated velocity of the center of the bounding box, in pixels per second.
+	Velocity r2.Point
+	// Age is the time since the track was first detected.
+	Age time.Duration
+	// Hits is the number of frames in which the track was matched to a detection.
+	Hits int
+}
+
+// Label returns the class label of the detection suffixed with its track ID, so that tracks can be told
+// apart by clients that only see labels. ClassLabel returns the class label alone.
+func (d *TrackedDetection) Label() string {
+	return fmt.Sprintf("%s_%d", d.Detection.Label(), d.TrackID)
+}
+
+// ClassLabel returns the class label of the underlying detection.
+func (d *TrackedDetection) ClassLabel() string {
+	return d.Detection.Label()
+}
+
+// Mask returns the mask of the underlying detection, or nil if it has none.
+func (d *TrackedDetection) Mask() *image.Alpha {
+	if md, ok := d.Detection.(MaskedDetection); ok {
+		return md.Mask()
+	}
+	return nil
+}
+
+// String turns the tracked detection into a string.
+func (d *TrackedDetection) String() string {
+	return fmt.Sprintf("Track: %d, Label: %s, Score: %.2f, Box: %v, Velocity: %v, Age: %v",
+		d.TrackID, d.ClassLabel(), d.Score(), *d.BoundingBox(), d.Velocity, d.Age)
+}
+
+// Tracker associates detections across frames in the manner of SORT: every track has a constant velocity
+// Kalman filter over its bounding box, and detections are matched to the predicted boxes by intersection
+// over union. Matching is greedy, from the highest overlap down, and only between detections of the same label.
+// A Tracker is not safe for concurrent use.
+type Tracker struct {
+	cfg        TrackerConfig
+	tracks     []*track
+	nextID     int
+	lastUpdate time.Time
+}
+
+// NewTracker returns a tracker with no tracks.
+func NewTracker(cfg TrackerConfig) *Tracker {
+	return &Tracker{cfg: cfg, nextID: 1}
+}
+
+// Update advances the tracks to the given time, matches them to the detections of that frame, and returns
+// the confirmed tracks that were matched, in the order of the detections.
+func (t *Tracker) Update(detections []Detection, at time.Time) []*TrackedDetection {
+	dt := 0.
+	if !t.lastUpdate.IsZero() {
+		dt = at.Sub(t.lastUpdate).Seconds()
+	}
+	t.lastUpdate = at
+	for _, tr := range t.tracks {
+		tr.predict(dt)
+	}
+
+	type pair struct {
+		track, detection int
+		iou              float64
+	}
+	pairs := []pair{}
+	for i, tr := range t.tracks {
+		predicted := tr.box()
+		for j, det := range detections {
+			if det.BoundingBox() == nil || det.Label() != tr.last.Label() {
+				continue
+			}
+			if iou := intersectionOverUnion(predicted, *det.BoundingBox()); iou >= t.cfg.IoUThreshold {
+				pairs = append(pairs, pair{i, j, iou})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].iou > pairs[b].iou })
+
+	trackOf := make([]*track, len(detections))
+	matched := make([]bool, len(t.tracks))
+	for _, p := range pairs {
+		if matched[p.track] || trackOf[p.detection] != nil {
+			continue
+		}
+		matched[p.track] = true
+		trackOf[p.detection] = t.tracks[p.track]
+		t.tracks[p.track].update(detections[p.detection])
+	}
+
+	live := t.tracks[:0]
+	for i, tr := range t.tracks {
+		if !matched[i] {
+			tr.misses++
+			tr.streak = 0
+			if tr.misses > t.cfg.MaxAge {
+				continue
+			}
+		}
+		live = append(live, tr)
+	}
+	for j, det := range detections {
+		if trackOf[j] != nil || det.BoundingBox() == nil {
+			continue
+		}
+		tr := newTrack(t.nextID, det, at)
+		t.nextID++
+		trackOf[j] = tr
+		live = append(live, tr)
+	}
+	t.tracks = live
+
+	tracked := []*TrackedDetection{}
+	for _, tr := range trackOf {
+		if tr == nil {
+			continue
+		}
+		if tr.streak >= t.cfg.MinHits {
+			tr.confirmed = true
+		}
+		if tr.confirmed {
+			tracked = append(tracked, tr.tracked(at))
+		}
+	}
+	return tracked
+}
+
+// Tracks returns every confirmed track that has not been removed, including those that were not matched in
+// the last frame, with the detection they were last matched to.
+func (t *Tracker) Tracks() []*TrackedDetection {
+	tracked := []*TrackedDetection{}
+	for _, tr := range t.tracks {
+		if tr.confirmed {
+			tracked = append(tracked, tr.tracked(t.lastUpdate))
+		}
+	}
+	return tracked
+}
+
+// track is the state of one tracked object. Its Kalman filter state is the center of the box, its area and
+// aspect ratio, and the rates of change of the center and area.
+type track struct {
+	id        int
+	last      Detection
+	firstSeen time.Time
+	hits      int
+	streak    int
+	misses    int
+	confirmed bool
+
+	x *mat.VecDense
+	p *mat.Dense
+}
+
+func newTrack(id int, det Detection, at time.Time) *track {
+	x := mat.NewVecDense(7, append(boxToMeasurement(*det.BoundingBox()), 0, 0, 0))
+	// the velocities are unknown, so they start out with a large uncertainty
+	p := mat.NewDiagDense(7, []float64{10, 10, 10, 10, 1e4, 1e4, 1e4})
+	return &track{
+		id:        id,
+		last:      det,
+		firstSeen: at,
+		hits:      1,
+		streak:    1,
+		x:         x,
+		p:         mat.DenseCopyOf(p),
+	}
+}
+
+// predict advances the filter by dt seconds.
+func (tr *track) predict(dt float64) {
+	// keep the predicted area from going negative
+	if tr.x.AtVec(2)+dt*tr.x.AtVec(6) <= 0 {
+		tr.x.SetVec(6, 0)
+	}
+	f := mat.NewDense(7, 7, nil)
+	for i := 0; i < 7; i++ {
+		f.Set(i, i, 1)
+	}
+	for i := 0; i < 3; i++ {
+		f.Set(i, i+4, dt)
+	}
+	var x mat.VecDense
+	x.MulVec(f, tr.x)
+	tr.x = &x
+
+	var fp, p mat.Dense
+	fp.Mul(f, tr.p)
+	p.Mul(&fp, f.T())
+	for i, q := range []float64{1, 1, 1, 0.01, 0.01, 0.01, 1e-4} {
+		p.Set(i, i, p.At(i, i)+q*math.Max(dt, 0))
+	}
+	tr.p = &p
+}
+
+// update corrects the filter with a matched detection.
+func (tr *track) update(det Detection) {
+	h := mat.NewDense(4, 7, nil)
+	for i := 0; i < 4; i++ {
+		h.Set(i, i, 1)
+	}
+	z := mat.NewVecDense(4, boxToMeasurement(*det.BoundingBox()))
+
+	var y mat.VecDense
+	y.MulVec(h, tr.x)
+	y.SubVec(z, &y)
+
+	var hp, s mat.Dense
+	hp.Mul(h, tr.p)
+	s.Mul(&hp, h.T())
+	for i, r := range []float64{1, 1, 10, 10} {
+		s.Set(i, i, s.At(i, i)+r)
+	}
+	var sInv mat.Dense
+	if err := sInv.Inverse(&s); err != nil {
+		// the innovation covariance is always positive definite, but keep the measurement if it is not
+		for i := 0; i < 4; i++ {
+			tr.x.SetVec(i, z.AtVec(i))
+		}
+	} else {
+		var pht, k mat.Dense
+		pht.Mul(tr.p, h.T())
+		k.Mul(&pht, &sInv)
+
+		var correction mat.VecDense
+		correction.MulVec(&k, &y)
+		tr.x.AddVec(tr.x, &correction)
+
+		var kh, khp, p mat.Dense
+		kh.Mul(&k, h)
+		khp.Mul(&kh, tr.p)
+		p.Sub(tr.p, &khp)
+		tr.p = &p
+	}
+
+	tr.last = det
+	tr.hits++
+	tr.streak++
+	tr.misses = 0
+}
+
+// box returns the bounding box of the current filter state.
+func (tr *track) box() image.Rectangle {
+	cx, cy, area, ratio := tr.x.AtVec(0), tr.x.AtVec(1), tr.x.AtVec(2), tr.x.AtVec(3)
+	if area <= 0 || ratio <= 0 {
+		return image.Rectangle{}
+	}
+	w := math.Sqrt(area * ratio)
+	h := area / w
+	return image.Rect(
+		int(math.Round(cx-w/2)), int(math.Round(cy-h/2)),
+		int(math.Round(cx+w/2)), int(math.Round(cy+h/2)),
+	)
+}
+
+func (tr *track) tracked(at time.Time) *TrackedDetection {
+	return &TrackedDetection{
+		Detection: tr.last,
+		TrackID:   tr.id,
+		Velocity:  r2.Point{X: tr.x.AtVec(4), Y: tr.x.AtVec(5)},
+		Age:       at.Sub(tr.firstSeen),
+		Hits:      tr.hits,
+	}
+}
+
+// boxToMeasurement converts a box to its center, area and aspect ratio.
+func boxToMeasurement(box image.Rectangle) []float64 {
+	w, h := float64(box.Dx()), float64(box.Dy())
+	ratio := 1.
+	if h > 0 {
+		ratio = w / h
+	}
+	return []float64{
+		float64(box.Min.X) + w/2,
+		float64(box.Min.Y) + h/2,
+		w * h,
+		ratio,
+	}
+}
+
+func intersectionOverUnion(a, b image.Rectangle) float64 {
+	intersection := a.Intersect(b)
+	if intersection.Empty() {
+		return 0
+	}
+	inter := float64(intersection.Dx() * intersection.Dy())
+	union := float64(a.Dx()*a.Dy()+b.Dx()*b.Dy()) - inter
+	if union <= 0 {
+		return 0
+	}
+	return inter / union
+}
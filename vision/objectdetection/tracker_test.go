@@ -0,0 +1,80 @@
+package objectdetection
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+func TestTracker(t *testing.T) {
+	bounds := image.Rect(0, 0, 640, 480)
+	start := time.Now()
+	frame := func(i int) time.Time { return start.Add(time.Duration(i) * 100 * time.Millisecond) }
+	// a person walking right at 10 pixels per frame, and a dog standing still
+	detect := func(i int) []Detection {
+		return []Detection{
+			NewDetection(bounds, image.Rect(300, 300, 360, 360), 0.8, "dog"),
+			NewDetection(bounds, image.Rect(10*i, 100, 10*i+50, 200), 0.9, "person"),
+		}
+	}
+
+	tracker := NewTracker(DefaultTrackerConfig())
+	for i := 0; i < DefaultTrackerMinHits-1; i++ {
+		test.That(t, tracker.Update(detect(i), frame(i)), test.ShouldBeEmpty)
+	}
+	var tracked []*TrackedDetection
+	for i := DefaultTrackerMinHits - 1; i < 20; i++ {
+		tracked = tracker.Update(detect(i), frame(i))
+		test.That(t, tracked, test.ShouldHaveLength, 2)
+		test.That(t, tracked[0].TrackID, test.ShouldEqual, 1)
+		test.That(t, tracked[1].TrackID, test.ShouldEqual, 2)
+	}
+	test.That(t, tracked[0].Label(), test.ShouldEqual, "dog_1")
+	test.That(t, tracked[0].ClassLabel(), test.ShouldEqual, "dog")
+	test.That(t, tracked[0].Velocity.Norm(), test.ShouldAlmostEqual, 0, 1)
+	test.That(t, tracked[1].Label(), test.ShouldEqual, "person_2")
+	test.That(t, tracked[1].Velocity.X, test.ShouldAlmostEqual, 100, 5)
+	test.That(t, tracked[1].Velocity.Y, test.ShouldAlmostEqual, 0, 1)
+	test.That(t, tracked[1].Age, test.ShouldEqual, 1900*time.Millisecond)
+	test.That(t, tracked[1].Hits, test.ShouldEqual, 20)
+	test.That(t, tracked[1].BoundingBox(), test.ShouldResemble, detect(19)[1].BoundingBox())
+
+	// the person is missed for one frame, which the tracker bridges, and the dog leaves
+	test.That(t, tracker.Update(nil, frame(20)), test.ShouldBeEmpty)
+	test.That(t, tracker.Tracks(), test.ShouldHaveLength, 2)
+	tracked = tracker.Update(detect(21)[1:], frame(21))
+	test.That(t, tracked, test.ShouldHaveLength, 1)
+	test.That(t, tracked[0].TrackID, test.ShouldEqual, 2)
+	test.That(t, tracker.Tracks(), test.ShouldHaveLength, 1)
+
+	// a dog that comes back later, or a detection of another label where the person is expected, is a new track
+	test.That(t, tracker.Update([]Detection{
+		NewDetection(bounds, image.Rect(300, 300, 360, 360), 0.8, "dog"),
+		NewDetection(bounds, image.Rect(220, 100, 270, 200), 0.9, "cat"),
+	}, frame(22)), test.ShouldBeEmpty)
+	tracks := tracker.Tracks()
+	test.That(t, tracks, test.ShouldHaveLength, 1)
+	test.That(t, tracks[0].TrackID, test.ShouldEqual, 2)
+	test.That(t, tracks[0].Hits, test.ShouldEqual, 21)
+
+	cfg := DefaultTrackerConfig()
+	cfg.MinHits = 1
+	tracker = NewTracker(cfg)
+	tracked = tracker.Update([]Detection{
+		NewDetection(bounds, image.Rect(300, 300, 360, 360), 0.8, "dog"),
+		NewDetection(bounds, image.Rect(300, 300, 360, 360), 0.8, "cat"),
+	}, frame(0))
+	test.That(t, tracked, test.ShouldHaveLength, 2)
+	test.That(t, tracked[0].TrackID, test.ShouldEqual, 1)
+	test.That(t, tracked[1].TrackID, test.ShouldEqual, 2)
+	test.That(t, tracked[1].Age, test.ShouldEqual, 0)
+}
+
+func TestIntersectionOverUnion(t *testing.T) {
+	test.That(t, intersectionOverUnion(image.Rect(0, 0, 10, 10), image.Rect(0, 0, 10, 10)), test.ShouldEqual, 1)
+	test.That(t, intersectionOverUnion(image.Rect(0, 0, 10, 10), image.Rect(5, 0, 15, 10)), test.ShouldAlmostEqual, 50./150)
+	test.That(t, intersectionOverUnion(image.Rect(0, 0, 10, 10), image.Rect(10, 10, 20, 20)), test.ShouldEqual, 0)
+	test.That(t, intersectionOverUnion(image.Rect(0, 0, 0, 0), image.Rect(0, 0, 0, 0)), test.ShouldEqual, 0)
+}
@@ -0,0 +1,123 @@
+package segmentation
+
+import (
+	"context"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	pc "go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/vision"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+// DetectionSegmenter returns a Segmenter that runs the detector on the image of the camera, and segments its
+// point cloud into an object per detection, of the points that project into the detection. The points of
+// detections with masks are those within the mask, which are tighter than those within the bounding box.
+// The camera must have intrinsic parameters. Detections scored below confidenceThresh are ignored, and if meanK
+// and sigma are positive, outliers are removed from each object by a statistical outlier filter.
+func DetectionSegmenter(detector objectdetection.Detector, meanK int, sigma, confidenceThresh float64) (Segmenter, error) {
+	if detector == nil {
+		return nil, errors.New("detector cannot be nil")
+	}
+	var filter func(in, out pc.PointCloud) error
+	if meanK > 0 && sigma > 0 {
+		var err error
+		filter, err = pc.StatisticalOutlierFilter(meanK, sigma)
+		if err != nil {
+			return nil, err
+		}
+	}
+	seg := func(ctx context.Context, src camera.Camera) ([]*vision.Object, error) {
+		props, err := src.Properties(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if props.IntrinsicParams == nil {
+			return nil, errors.New("camera must have intrinsic parameters to segment its point cloud by detections")
+		}
+		img, err := camera.DecodeImageFromCamera(ctx, src, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		cloud, err := src.NextPointCloud(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		dets, err := detector(ctx, img)
+		if err != nil {
+			return nil, err
+		}
+		kept := make([]objectdetection.Detection, 0, len(dets))
+		for _, d := range dets {
+			if d.Score() >= confidenceThresh {
+				kept = append(kept, d)
+			}
+		}
+		if len(kept) == 0 {
+			return []*vision.Object{}, nil
+		}
+
+		// the image may be scaled from the resolution of the intrinsics
+		bounds := img.Bounds()
+		scaleX := float64(bounds.Dx()) / float64(props.IntrinsicParams.Width)
+		scaleY := float64(bounds.Dy()) / float64(props.IntrinsicParams.Height)
+		clouds := make([]pc.PointCloud, len(kept))
+		for i := range clouds {
+			clouds[i] = pc.NewBasicEmpty()
+		}
+		var setErr error
+		cloud.Iterate(0, 0, func(p r3.Vector, d pc.Data) bool {
+			if p.Z <= 0 {
+				return true
+			}
+			px, py, err := props.PointToPixel(p)
+			if err != nil {
+				setErr = err
+				return false
+			}
+			pt := bounds.Min
+			pt.X += int(math.Floor(px * scaleX))
+			pt.Y += int(math.Floor(py * scaleY))
+			if !pt.In(bounds) {
+				return true
+			}
+			for i, det := range kept {
+				if objectdetection.InDetection(det, pt) {
+					if err := clouds[i].Set(p, d); err != nil {
+						setErr = err
+						return false
+					}
+				}
+			}
+			return true
+		})
+		if setErr != nil {
+			return nil, setErr
+		}
+
+		objects := make([]*vision.Object, 0, len(kept))
+		for i, det := range kept {
+			objCloud := clouds[i]
+			if filter != nil && objCloud.Size() > 0 {
+				filtered := pc.NewBasicEmpty()
+				if err := filter(objCloud, filtered); err != nil {
+					return nil, err
+				}
+				objCloud = filtered
+			}
+			if objCloud.Size() == 0 {
+				continue
+			}
+			obj, err := vision.NewObjectWithLabel(objCloud, det.Label(), nil)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, obj)
+		}
+		return objects, nil
+	}
+	return seg, nil
+}
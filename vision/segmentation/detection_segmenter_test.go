@@ -0,0 +1,94 @@
+package segmentation_test
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
+	pc "go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/objectdetection"
+	"go.viam.com/rdk/vision/segmentation"
+)
+
+func TestDetectionSegmenter(t *testing.T) {
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 100, Height: 100, Fx: 100, Fy: 100, Ppx: 50, Ppy: 50}
+	// a disc in front of a wall, with a point seen by each pixel
+	inDisc := func(x, y int) bool { return (x-50)*(x-50)+(y-50)*(y-50) < 15*15 }
+	discPoints := 0
+	cloud := pc.NewBasicEmpty()
+	for y := 0; y < intrinsics.Height; y++ {
+		for x := 0; x < intrinsics.Width; x++ {
+			z := 1000.
+			if inDisc(x, y) {
+				z = 500
+				discPoints++
+			}
+			pt := r3.Vector{X: (float64(x) - intrinsics.Ppx) * z / intrinsics.Fx, Y: (float64(y) - intrinsics.Ppy) * z / intrinsics.Fy, Z: z}
+			test.That(t, cloud.Set(pt, nil), test.ShouldBeNil)
+		}
+	}
+
+	cam := inject.NewCamera("cam")
+	cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		return camera.Properties{SupportsPCD: true, IntrinsicParams: intrinsics}, nil
+	}
+	cam.ImagesFunc = func(
+		ctx context.Context, filterSourceNames []string, extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		img := image.NewGray(image.Rect(0, 0, intrinsics.Width, intrinsics.Height))
+		namedImg, err := camera.NamedImageFromImage(img, "color", utils.MimeTypePNG, data.Annotations{})
+		return []camera.NamedImage{namedImg}, resource.ResponseMetadata{}, err
+	}
+	cam.NextPointCloudFunc = func(ctx context.Context, extra map[string]interface{}) (pc.PointCloud, error) {
+		return cloud, nil
+	}
+
+	box := image.Rect(35, 35, 65, 65)
+	mask := image.NewAlpha(box)
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		for x := box.Min.X; x < box.Max.X; x++ {
+			if inDisc(x, y) {
+				mask.SetAlpha(x, y, color.Alpha{A: 255})
+			}
+		}
+	}
+	detector := func(ctx context.Context, img image.Image) ([]objectdetection.Detection, error) {
+		return []objectdetection.Detection{
+			objectdetection.NewMaskedDetection(img.Bounds(), box, mask, 0.9, "masked"),
+			objectdetection.NewDetection(img.Bounds(), box, 0.8, "box"),
+			objectdetection.NewDetection(img.Bounds(), image.Rect(0, 0, 10, 10), 0.1, "unsure"),
+		}, nil
+	}
+
+	seg, err := segmentation.DetectionSegmenter(detector, 0, 0, 0.5)
+	test.That(t, err, test.ShouldBeNil)
+	objects, err := seg(context.Background(), cam)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, objects, test.ShouldHaveLength, 2)
+	// only the mask leaves out the wall around the disc
+	test.That(t, objects[0].Geometry.Label(), test.ShouldEqual, "masked")
+	test.That(t, objects[0].Size(), test.ShouldEqual, discPoints)
+	test.That(t, objects[0].MetaData().MaxZ, test.ShouldEqual, 500)
+	test.That(t, objects[1].Geometry.Label(), test.ShouldEqual, "box")
+	test.That(t, objects[1].Size(), test.ShouldEqual, box.Dx()*box.Dy())
+	test.That(t, objects[1].MetaData().MaxZ, test.ShouldEqual, 1000)
+
+	cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		return camera.Properties{SupportsPCD: true}, nil
+	}
+	_, err = seg(context.Background(), cam)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = segmentation.DetectionSegmenter(nil, 0, 0, 0.5)
+	test.That(t, err, test.ShouldNotBeNil)
+}